@@ -0,0 +1,90 @@
+// Command compactor compacts a SlateDB database in a process of its own, such that compactions do
+// not compete with the writer for CPU and bandwidth. The writer must be opened with
+// config.DBOptions.CompactorOptions set to nil. Starting a compactor fences the compactor of any
+// other process, and the compactor exits once it is fenced by another.
+//
+// Usage:
+//
+//	compactor -bucket <dir> -path <db path> [-poll-interval <duration>] [-max-concurrent <n>]
+//	          [-min-l0-ssts <n>] [-max-sorted-runs <n>]
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/thanos-io/objstore/providers/filesystem"
+
+	"github.com/slatedb/slatedb-go/slatedb"
+	"github.com/slatedb/slatedb-go/slatedb/config"
+)
+
+func main() {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	if err := run(ctx, os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, args []string) error {
+	defaults := config.DefaultCompactorOptions()
+	flags := flag.NewFlagSet("compactor", flag.ContinueOnError)
+	bucketDir := flags.String("bucket", "", "local directory containing the bucket")
+	path := flags.String("path", "", "path of the database within the bucket")
+	pollInterval := flags.Duration("poll-interval", defaults.PollInterval, "interval at which the manifest is polled")
+	maxConcurrent := flags.Int("max-concurrent", 1, "number of compactions executed at once")
+	minL0SSTs := flags.Int("min-l0-ssts", defaults.MinL0CompactionSSTs, "number of L0 SSTs which triggers a compaction")
+	maxSortedRuns := flags.Int("max-sorted-runs", 0, "number of sorted runs above which they are merged; zero never merges")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *bucketDir == "" || *path == "" {
+		return fmt.Errorf("-bucket and -path are required")
+	}
+
+	bucket, err := filesystem.NewBucket(*bucketDir)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = bucket.Close() }()
+
+	options := config.DefaultDBOptions()
+	options.CompactorOptions = defaults
+	options.CompactorOptions.PollInterval = *pollInterval
+	options.CompactorOptions.MaxConcurrentCompactions = *maxConcurrent
+	options.CompactorOptions.MinL0CompactionSSTs = *minL0SSTs
+	options.CompactorOptions.MaxSortedRuns = *maxSortedRuns
+	compactor, err := slatedb.OpenCompactor(ctx, *path, bucket, options)
+	if err != nil {
+		return err
+	}
+	slog.Info("compacting database", "path", *path)
+
+	// The compactor runs until it is interrupted, or is fenced by another compactor
+	ticker := time.NewTicker(*pollInterval)
+	defer ticker.Stop()
+	for err == nil {
+		select {
+		case <-ticker.C:
+			err = compactor.HealthCheck()
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
+	}
+	if errors.Is(err, context.Canceled) {
+		err = nil
+	}
+
+	closeCtx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	return errors.Join(err, compactor.Close(closeCtx))
+}
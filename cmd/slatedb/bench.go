@@ -0,0 +1,343 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/thanos-io/objstore"
+
+	"github.com/slatedb/slatedb-go/internal/compress"
+	"github.com/slatedb/slatedb-go/slatedb"
+	"github.com/slatedb/slatedb-go/slatedb/config"
+)
+
+// bench runs the given workloads in order against a database, and prints the throughput and
+// latency percentiles of each. The database is opened in the bucket in `-bucket`, or in an
+// in-memory bucket if no bucket is given, such that object stores and options can be compared.
+func bench(ctx context.Context, args []string, out io.Writer) error {
+	defaults := config.DefaultDBOptions()
+	flags := flag.NewFlagSet("bench", flag.ContinueOnError)
+	bucketFlag := addBucketFlags(flags, "", "local directory containing the bucket, an in-memory bucket if empty")
+	path := flags.String("path", "bench", "path of the database within the bucket")
+	workloads := flags.String("workloads", "fill,readrandom,readseq,mixed", "comma separated workloads to run in order; "+
+		"fill, readrandom, readseq or mixed")
+	num := flags.Int("num", 10_000, "number of keys written by fill, and of operations of readrandom and mixed")
+	keySize := flags.Int("key-size", 16, "size of each key in bytes")
+	valueSize := flags.Int("value-size", 100, "size of each value in bytes")
+	concurrency := flags.Int("concurrency", 4, "number of goroutines issuing operations")
+	readPercent := flags.Int("read-percent", 90, "percentage of the operations of mixed which are reads")
+	awaitDurable := flags.Bool("await-durable", false, "wait for each write to be durable")
+	flushInterval := flags.Duration("flush-interval", defaults.FlushInterval, "interval at which the WAL is flushed")
+	blockCacheSize := flags.Uint64("block-cache-size", defaults.BlockCacheSize, "size of the block cache in bytes")
+	l0SSTSize := flags.Uint64("l0-sst-size", defaults.L0SSTSizeBytes, "size of the memtable flushed to an L0 SST in bytes")
+	codec := flags.String("compression", defaults.CompressionCodec.String(), "compression codec of SSTs; "+
+		"none, snappy, zlib, lz4 or zstd")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *num <= 0 || *concurrency <= 0 || *valueSize < 0 || *readPercent < 0 || *readPercent > 100 {
+		return fmt.Errorf("-num and -concurrency must be positive, and -read-percent within [0, 100]")
+	}
+	names := strings.Split(*workloads, ",")
+	for i, name := range names {
+		names[i] = strings.TrimSpace(name)
+		if !slices.Contains([]string{"fill", "readrandom", "readseq", "mixed"}, names[i]) {
+			return fmt.Errorf("unknown workload '%s'", names[i])
+		}
+	}
+	if *keySize < len(fmt.Sprint(*num-1)) {
+		return fmt.Errorf("-key-size must be at least %d to hold %d keys", len(fmt.Sprint(*num-1)), *num)
+	}
+
+	options := defaults
+	options.FlushInterval = *flushInterval
+	options.BlockCacheSize = *blockCacheSize
+	options.L0SSTSizeBytes = *l0SSTSize
+	options.Log = slog.New(slog.NewTextHandler(io.Discard, nil))
+	var err error
+	if options.CompressionCodec, err = parseCodec(*codec); err != nil {
+		return err
+	}
+
+	var bucket objstore.Bucket = objstore.NewInMemBucket()
+	if bucketFlag.isSet() {
+		if bucket, err = bucketFlag.open(); err != nil {
+			return fmt.Errorf("while opening bucket: %w", err)
+		}
+	}
+	defer func() { _ = bucket.Close() }()
+
+	db, err := slatedb.OpenWithOptions(ctx, *path, bucket, options)
+	if err != nil {
+		return fmt.Errorf("while opening database: %w", err)
+	}
+	defer func() { _ = db.Close(ctx) }()
+
+	b := &benchmark{
+		db:           db,
+		num:          *num,
+		keySize:      *keySize,
+		valueSize:    *valueSize,
+		concurrency:  *concurrency,
+		readPercent:  *readPercent,
+		writeOptions: config.WriteOptions{AwaitDurable: *awaitDurable},
+	}
+	_, _ = fmt.Fprintf(out, "keys: %d bytes, values: %d bytes, concurrency: %d, compression: %s\n",
+		*keySize, *valueSize, *concurrency, options.CompressionCodec)
+	for _, workload := range names {
+		var result benchResult
+		switch workload {
+		case "fill":
+			result, err = b.fill(ctx)
+		case "readrandom":
+			result, err = b.readRandom(ctx)
+		case "readseq":
+			result, err = b.readSeq(ctx)
+		case "mixed":
+			result, err = b.mixed(ctx)
+		}
+		if err != nil {
+			return fmt.Errorf("while running %s: %w", workload, err)
+		}
+		result.name = workload
+		result.print(out)
+	}
+	return nil
+}
+
+// parseCodec returns the compress.Codec with the given name, ignoring case
+func parseCodec(name string) (compress.Codec, error) {
+	for _, c := range []compress.Codec{compress.CodecNone, compress.CodecSnappy, compress.CodecZlib,
+		compress.CodecLz4, compress.CodecZstd} {
+		if strings.EqualFold(c.String(), name) {
+			return c, nil
+		}
+	}
+	return compress.CodecNone, fmt.Errorf("unknown compression codec '%s'", name)
+}
+
+type benchmark struct {
+	db           *slatedb.DB
+	num          int
+	keySize      int
+	valueSize    int
+	concurrency  int
+	readPercent  int
+	writeOptions config.WriteOptions
+}
+
+// benchResult is the outcome of a workload. Latencies holds the latency of each operation
+type benchResult struct {
+	name      string
+	elapsed   time.Duration
+	bytes     int64
+	notFound  int
+	latencies []time.Duration
+}
+
+// key returns the key with index `i`, which is `i` zero-padded to the key size such that the
+// keys sort in the order of their index
+func (b *benchmark) key(i int) []byte {
+	return fmt.Appendf(nil, "%0*d", b.keySize, i)
+}
+
+// run calls `op` from each of the goroutines with the index of the goroutine, and returns the
+// combined result once all of them return
+func (b *benchmark) run(op func(worker int, result *benchResult) error) (benchResult, error) {
+	results := make([]benchResult, b.concurrency)
+	errs := make([]error, b.concurrency)
+	var wg sync.WaitGroup
+	start := time.Now()
+	for w := 0; w < b.concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[w] = op(w, &results[w])
+		}()
+	}
+	wg.Wait()
+
+	combined := benchResult{elapsed: time.Since(start)}
+	for _, r := range results {
+		combined.bytes += r.bytes
+		combined.notFound += r.notFound
+		combined.latencies = append(combined.latencies, r.latencies...)
+	}
+	return combined, errors.Join(errs...)
+}
+
+// fill writes the keys in order, each goroutine writing a contiguous range of them, and then
+// flushes the WAL and the memtable, such that the keys are durable and the reads of the
+// workloads which follow are served from L0 SSTs in object storage
+func (b *benchmark) fill(ctx context.Context) (benchResult, error) {
+	result, err := b.run(func(worker int, result *benchResult) error {
+		value := newBenchValues(b.valueSize, uint64(worker))
+		first, last := b.partition(worker)
+		for i := first; i < last; i++ {
+			key := b.key(i)
+			start := time.Now()
+			if err := b.db.PutWithOptions(ctx, key, value.next(), b.writeOptions); err != nil {
+				return err
+			}
+			result.latencies = append(result.latencies, time.Since(start))
+			result.bytes += int64(len(key) + b.valueSize)
+		}
+		return nil
+	})
+	if err != nil {
+		return result, err
+	}
+	start := time.Now()
+	if err := b.db.FlushWAL(ctx); err != nil {
+		return result, err
+	}
+	err = b.db.FlushMemtableToL0()
+	result.elapsed += time.Since(start)
+	return result, err
+}
+
+// readRandom reads keys chosen at random from those written by fill
+func (b *benchmark) readRandom(ctx context.Context) (benchResult, error) {
+	return b.run(func(worker int, result *benchResult) error {
+		rng := rand.New(rand.NewPCG(uint64(worker), 0))
+		first, last := b.partition(worker)
+		for n := first; n < last; n++ {
+			key := b.key(rng.IntN(b.num))
+			start := time.Now()
+			value, err := b.db.Get(ctx, key)
+			result.latencies = append(result.latencies, time.Since(start))
+			if errors.Is(err, slatedb.ErrKeyNotFound) {
+				result.notFound++
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			result.bytes += int64(len(key) + len(value))
+		}
+		return nil
+	})
+}
+
+// readSeq scans the keys written by fill, each goroutine scanning a contiguous range of them. The
+// latency of each key is the time taken to advance the iterator to it
+func (b *benchmark) readSeq(ctx context.Context) (benchResult, error) {
+	return b.run(func(worker int, result *benchResult) error {
+		first, last := b.partition(worker)
+		var end []byte
+		if last < b.num {
+			end = b.key(last)
+		}
+		start := time.Now()
+		iter, err := b.db.Scan(ctx, b.key(first), end)
+		if err != nil {
+			return err
+		}
+		for {
+			kv, ok := iter.Next(ctx)
+			if !ok {
+				break
+			}
+			result.latencies = append(result.latencies, time.Since(start))
+			result.bytes += int64(len(kv.Key) + len(kv.Value))
+			start = time.Now()
+		}
+		return iter.Err()
+	})
+}
+
+// mixed reads and overwrites keys chosen at random from those written by fill, with
+// `readPercent` of the operations being reads
+func (b *benchmark) mixed(ctx context.Context) (benchResult, error) {
+	return b.run(func(worker int, result *benchResult) error {
+		rng := rand.New(rand.NewPCG(uint64(worker), 1))
+		value := newBenchValues(b.valueSize, uint64(worker))
+		first, last := b.partition(worker)
+		for n := first; n < last; n++ {
+			key := b.key(rng.IntN(b.num))
+			start := time.Now()
+			if rng.IntN(100) < b.readPercent {
+				v, err := b.db.Get(ctx, key)
+				result.latencies = append(result.latencies, time.Since(start))
+				if errors.Is(err, slatedb.ErrKeyNotFound) {
+					result.notFound++
+					continue
+				}
+				if err != nil {
+					return err
+				}
+				result.bytes += int64(len(key) + len(v))
+				continue
+			}
+			if err := b.db.PutWithOptions(ctx, key, value.next(), b.writeOptions); err != nil {
+				return err
+			}
+			result.latencies = append(result.latencies, time.Since(start))
+			result.bytes += int64(len(key) + b.valueSize)
+		}
+		return nil
+	})
+}
+
+// partition returns the range [first, last) of the `num` keys or operations of the goroutine
+// with index `worker`
+func (b *benchmark) partition(worker int) (int, int) {
+	return worker * b.num / b.concurrency, (worker + 1) * b.num / b.concurrency
+}
+
+// benchValues returns values of random bytes, which are sliced from a buffer of twice the value
+// size such that successive values differ without generating random bytes for each
+type benchValues struct {
+	buf []byte
+	rng *rand.Rand
+}
+
+func newBenchValues(size int, seed uint64) *benchValues {
+	rng := rand.New(rand.NewPCG(seed, 2))
+	buf := make([]byte, 2*size+1)
+	for i := range buf {
+		buf[i] = byte(rng.UintN(256))
+	}
+	return &benchValues{buf: buf, rng: rng}
+}
+
+func (v *benchValues) next() []byte {
+	size := len(v.buf) / 2
+	offset := v.rng.IntN(size + 1)
+	return v.buf[offset : offset+size]
+}
+
+func (r benchResult) print(out io.Writer) {
+	ops := len(r.latencies)
+	seconds := r.elapsed.Seconds()
+	_, _ = fmt.Fprintf(out, "%-10s %8d ops in %8s %10.0f ops/s %8.2f MB/s",
+		r.name, ops, r.elapsed.Round(time.Millisecond), float64(ops)/seconds, float64(r.bytes)/seconds/(1<<20))
+	if r.notFound > 0 {
+		_, _ = fmt.Fprintf(out, " (%d not found)", r.notFound)
+	}
+	_, _ = fmt.Fprintln(out)
+	if ops == 0 {
+		return
+	}
+
+	slices.Sort(r.latencies)
+	var total time.Duration
+	for _, l := range r.latencies {
+		total += l
+	}
+	percentile := func(p float64) time.Duration {
+		return r.latencies[min(int(p*float64(ops)), ops-1)]
+	}
+	_, _ = fmt.Fprintf(out, "%-10s avg %s  p50 %s  p90 %s  p99 %s  p99.9 %s  max %s\n", "",
+		total/time.Duration(ops), percentile(0.5), percentile(0.9), percentile(0.99), percentile(0.999),
+		r.latencies[ops-1])
+}
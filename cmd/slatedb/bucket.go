@@ -0,0 +1,30 @@
+package main
+
+import (
+	"flag"
+
+	"github.com/thanos-io/objstore"
+	"github.com/thanos-io/objstore/providers/filesystem"
+)
+
+// bucketFlags are the flags which select the bucket a command opens. Every command selects its
+// buckets with bucketFlags, such that a provider is supported by every command once it is added here.
+type bucketFlags struct {
+	dir *string
+}
+
+// addBucketFlags adds the flags which select a bucket to `flags`. The name of each flag is prefixed
+// with `prefix`, such as "source-" for the flags of a second bucket.
+func addBucketFlags(flags *flag.FlagSet, prefix string, usage string) bucketFlags {
+	return bucketFlags{dir: flags.String(prefix+"bucket", "", usage)}
+}
+
+// isSet returns true if a bucket was selected
+func (f bucketFlags) isSet() bool {
+	return *f.dir != ""
+}
+
+// open opens the selected bucket
+func (f bucketFlags) open() (objstore.Bucket, error) {
+	return filesystem.NewBucket(*f.dir)
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/slatedb/slatedb-go/slatedb"
+	"github.com/slatedb/slatedb-go/slatedb/config"
+)
+
+// filtersRebuild opens the database and rebuilds the bloom filter of every SST with the given
+// filter options. Opening the database fences its current writer, which must be stopped first.
+func filtersRebuild(ctx context.Context, args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("filters rebuild", flag.ContinueOnError)
+	bucketFlag := addBucketFlags(flags, "", "local directory containing the bucket")
+	path := flags.String("path", "", "path of the database within the bucket")
+	minFilterKeys := flags.Uint("min-filter-keys", uint(config.DefaultDBOptions().MinFilterKeys),
+		"minimum number of keys an SST must hold to be given a filter")
+	bitsPerKey := flags.Uint("bits-per-key", uint(config.DefaultDBOptions().FilterBitsPerKey),
+		"number of bits per key of each filter")
+	fpRate := flags.Float64("fp-rate", 0, "target false positive rate of each filter, which overrides -bits-per-key")
+	timeout := flags.Duration("timeout", time.Hour, "maximum time to spend rebuilding filters")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if !bucketFlag.isSet() || *path == "" {
+		return fmt.Errorf("-bucket and -path are required")
+	}
+
+	bucket, err := bucketFlag.open()
+	if err != nil {
+		return fmt.Errorf("while opening bucket: %w", err)
+	}
+	defer func() { _ = bucket.Close() }()
+
+	ctx, cancel := context.WithTimeout(ctx, *timeout)
+	defer cancel()
+
+	options := config.DefaultDBOptions()
+	options.MinFilterKeys = uint32(*minFilterKeys)
+	options.FilterBitsPerKey = uint32(*bitsPerKey)
+	options.FilterFalsePositiveRate = *fpRate
+	options.CompactorOptions = nil
+	db, err := slatedb.OpenWithOptions(ctx, *path, bucket, options)
+	if err != nil {
+		return fmt.Errorf("while opening database: %w", err)
+	}
+	report, err := db.RebuildFilters(ctx)
+	if closeErr := db.Close(ctx); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("filter rebuild failed: %w", err)
+	}
+	_, _ = fmt.Fprintf(out, "filters rebuilt: %d SSTs, %d filters\n", report.SSTs, report.Filters)
+	return nil
+}
@@ -0,0 +1,64 @@
+// Command slatedb provides tools to inspect and administer SlateDB databases.
+//
+// Usage:
+//
+//	slatedb backup verify -bucket <dir> -path <db path> [-source <db path> -checkpoint <id> [-source-bucket <dir>] [-samples <n>]]
+//	slatedb bench [-bucket <dir>] [-workloads fill,readrandom,readseq,mixed] [-num <n>] [-key-size <n>] [-value-size <n>] [-concurrency <n>]
+//	slatedb filters rebuild -bucket <dir> -path <db path> [-min-filter-keys <n>] [-bits-per-key <n> | -fp-rate <rate>]
+//	slatedb manifest show -bucket <dir> -path <db path> [-id <manifest id>] [-json]
+//	slatedb quarantine list -bucket <dir> -path <db path>
+//	slatedb quarantine clear -bucket <dir> -path <db path> <sst id>...
+//	slatedb sst dump -bucket <dir> [-entries] [-hex] [-start <key>] [-end <key>] [-limit <n>] <sst path>
+//	slatedb tail -bucket <dir> [-prefix <prefix>] <db path>
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+)
+
+const usage = `usage:
+  slatedb backup verify -bucket <dir> -path <db path> [-source <db path> -checkpoint <id> [-source-bucket <dir>] [-samples <n>]]
+  slatedb bench [-bucket <dir>] [-workloads fill,readrandom,readseq,mixed] [-num <n>] [-key-size <n>] [-value-size <n>] [-concurrency <n>]
+  slatedb filters rebuild -bucket <dir> -path <db path> [-min-filter-keys <n>] [-bits-per-key <n> | -fp-rate <rate>]
+  slatedb manifest show -bucket <dir> -path <db path> [-id <manifest id>] [-json]
+  slatedb quarantine list -bucket <dir> -path <db path>
+  slatedb quarantine clear -bucket <dir> -path <db path> <sst id>...
+  slatedb sst dump -bucket <dir> [-entries] [-hex] [-start <key>] [-end <key>] [-limit <n>] <sst path>
+  slatedb tail -bucket <dir> [-prefix <prefix>] <db path>`
+
+func main() {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	if err := run(ctx, os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, args []string, out io.Writer) error {
+	switch {
+	case len(args) >= 2 && args[0] == "backup" && args[1] == "verify":
+		return backupVerify(ctx, args[2:], out)
+	case len(args) >= 1 && args[0] == "bench":
+		return bench(ctx, args[1:], out)
+	case len(args) >= 2 && args[0] == "filters" && args[1] == "rebuild":
+		return filtersRebuild(ctx, args[2:], out)
+	case len(args) >= 2 && args[0] == "manifest" && args[1] == "show":
+		return manifestShow(ctx, args[2:], out)
+	case len(args) >= 2 && args[0] == "quarantine" && args[1] == "list":
+		return quarantineList(ctx, args[2:], out)
+	case len(args) >= 2 && args[0] == "quarantine" && args[1] == "clear":
+		return quarantineClear(ctx, args[2:], out)
+	case len(args) >= 2 && args[0] == "sst" && args[1] == "dump":
+		return sstDump(ctx, args[2:], out)
+	case len(args) >= 1 && args[0] == "tail":
+		return tail(ctx, args[1:], out)
+	}
+	return errors.New(usage)
+}
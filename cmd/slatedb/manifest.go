@@ -0,0 +1,251 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/slatedb/slatedb-go/internal/sstable"
+	"github.com/slatedb/slatedb-go/slatedb/manifest"
+	"github.com/slatedb/slatedb-go/slatedb/store"
+)
+
+// manifestShow prints the manifest versions of the database, and the core state and checkpoints
+// recorded by the latest manifest, or by the manifest selected with -id. The manifest is read
+// directly from the bucket, such that the database need not be opened.
+func manifestShow(_ context.Context, args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("manifest show", flag.ContinueOnError)
+	bucketFlag := addBucketFlags(flags, "", "local directory containing the bucket")
+	path := flags.String("path", "", "path of the database within the bucket")
+	id := flags.Uint64("id", 0, "ID of the manifest to show, the latest manifest if zero")
+	asJSON := flags.Bool("json", false, "print the manifest as JSON")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if !bucketFlag.isSet() || *path == "" {
+		return fmt.Errorf("-bucket and -path are required")
+	}
+
+	bucket, err := bucketFlag.open()
+	if err != nil {
+		return fmt.Errorf("while opening bucket: %w", err)
+	}
+	defer func() { _ = bucket.Close() }()
+
+	manifestStore := store.NewManifestStore(*path, bucket)
+	versions, err := manifestStore.ListManifests()
+	if err != nil {
+		return fmt.Errorf("while listing manifests: %w", err)
+	}
+	if len(versions) == 0 {
+		return fmt.Errorf("no manifest found at '%s'", *path)
+	}
+	if *id == 0 {
+		*id = versions[len(versions)-1].ID
+	}
+	m, err := manifestStore.ReadManifest(*id)
+	if err != nil {
+		return fmt.Errorf("while reading manifest: %w", err)
+	}
+
+	report := newManifestReport(*id, versions, m)
+	if *asJSON {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+	report.print(out)
+	return nil
+}
+
+// manifestReport is the manifest printed by manifestShow
+type manifestReport struct {
+	ID             uint64            `json:"id"`
+	Versions       []manifestVersion `json:"versions"`
+	WriterEpoch    uint64            `json:"writer_epoch"`
+	CompactorEpoch uint64            `json:"compactor_epoch"`
+	Features       []string          `json:"features"`
+
+	// NextWALID is the ID of the next WAL SST to be written. The WAL SSTs after
+	// LastCompactedWALID have yet to be flushed to L0
+	NextWALID          uint64 `json:"next_wal_id"`
+	LastCompactedWALID uint64 `json:"last_compacted_wal_id"`
+	LastSeq            uint64 `json:"last_seq"`
+
+	L0LastCompacted string            `json:"l0_last_compacted,omitempty"`
+	L0              []sstReport       `json:"l0"`
+	SortedRuns      []sortedRunReport `json:"sorted_runs"`
+	Checkpoints     []checkpoint      `json:"checkpoints"`
+	ExternalDBs     []externalDB      `json:"external_dbs,omitempty"`
+	Quarantined     []quarantinedSST  `json:"quarantined,omitempty"`
+}
+
+type manifestVersion struct {
+	ID           uint64    `json:"id"`
+	LastModified time.Time `json:"last_modified"`
+}
+
+type sstReport struct {
+	ID         string     `json:"id"`
+	FirstKey   string     `json:"first_key"`
+	LastKey    string     `json:"last_key,omitempty"`
+	Size       uint64     `json:"size"`
+	NumEntries uint64     `json:"num_entries,omitempty"`
+	CreatedAt  *time.Time `json:"created_at,omitempty"`
+}
+
+type sortedRunReport struct {
+	ID   uint32      `json:"id"`
+	SSTs []sstReport `json:"ssts"`
+}
+
+type checkpoint struct {
+	ID         uint64     `json:"id"`
+	ManifestID uint64     `json:"manifest_id"`
+	ExpireTime *time.Time `json:"expire_time,omitempty"`
+}
+
+type externalDB struct {
+	Path         string `json:"path"`
+	CheckpointID uint64 `json:"checkpoint_id"`
+	SSTs         int    `json:"ssts"`
+}
+
+type quarantinedSST struct {
+	ID     string    `json:"id"`
+	Reason string    `json:"reason"`
+	Time   time.Time `json:"time"`
+}
+
+func newManifestReport(id uint64, versions []store.ManifestFileMetadata, m *manifest.Manifest) manifestReport {
+	core := m.Core.Snapshot()
+	report := manifestReport{
+		ID:                 id,
+		WriterEpoch:        m.WriterEpoch.Load(),
+		CompactorEpoch:     m.CompactorEpoch.Load(),
+		Features:           m.Features.Names(),
+		NextWALID:          core.NextWalSstID.Load(),
+		LastCompactedWALID: core.LastCompactedWalSSTID.Load(),
+		LastSeq:            core.LastSeq.Load(),
+		L0:                 make([]sstReport, 0, len(core.L0)),
+		SortedRuns:         make([]sortedRunReport, 0, len(core.Compacted)),
+		Checkpoints:        make([]checkpoint, 0, len(m.Checkpoints)),
+	}
+	for _, v := range versions {
+		report.Versions = append(report.Versions, manifestVersion{ID: v.ID, LastModified: v.LastModified})
+	}
+	if last, ok := core.L0LastCompacted.Get(); ok {
+		report.L0LastCompacted = last.String()
+	}
+	for _, sst := range core.L0 {
+		report.L0 = append(report.L0, newSSTReport(sst))
+	}
+	for _, sr := range core.Compacted {
+		run := sortedRunReport{ID: sr.ID, SSTs: make([]sstReport, 0, len(sr.SSTList))}
+		for _, sst := range sr.SSTList {
+			run.SSTs = append(run.SSTs, newSSTReport(sst))
+		}
+		report.SortedRuns = append(report.SortedRuns, run)
+	}
+	for _, c := range m.Checkpoints {
+		report.Checkpoints = append(report.Checkpoints, checkpoint{
+			ID:         c.ID,
+			ManifestID: c.ManifestID,
+			ExpireTime: optionalTime(c.ExpireTime),
+		})
+	}
+	for _, e := range m.ExternalDBs {
+		report.ExternalDBs = append(report.ExternalDBs, externalDB{
+			Path:         e.Path,
+			CheckpointID: e.CheckpointID,
+			SSTs:         len(e.SSTIDs),
+		})
+	}
+	for _, q := range m.Quarantined {
+		report.Quarantined = append(report.Quarantined, quarantinedSST{ID: q.ID.String(), Reason: q.Reason, Time: q.Time})
+	}
+	return report
+}
+
+func newSSTReport(sst sstable.Handle) sstReport {
+	return sstReport{
+		ID:         sst.Id.String(),
+		FirstKey:   string(sst.Info.FirstKey),
+		LastKey:    string(sst.Info.LastKey),
+		Size:       sst.Info.EncodedSize(),
+		NumEntries: sst.Info.NumEntries,
+		CreatedAt:  optionalTime(sst.Info.CreatedAt),
+	}
+}
+
+// optionalTime returns nil if `t` is zero, such that it is omitted from the JSON
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+func (r manifestReport) print(out io.Writer) {
+	_, _ = fmt.Fprintf(out, "Manifest %d\n", r.ID)
+	_, _ = fmt.Fprintf(out, "  Writer Epoch: %d\n", r.WriterEpoch)
+	_, _ = fmt.Fprintf(out, "  Compactor Epoch: %d\n", r.CompactorEpoch)
+	_, _ = fmt.Fprintf(out, "  Features: %v\n", r.Features)
+	_, _ = fmt.Fprintf(out, "  Next WAL ID: %d\n", r.NextWALID)
+	_, _ = fmt.Fprintf(out, "  Last Compacted WAL ID: %d\n", r.LastCompactedWALID)
+	_, _ = fmt.Fprintf(out, "  Last Seq: %d\n", r.LastSeq)
+
+	_, _ = fmt.Fprintf(out, "Versions (%d):\n", len(r.Versions))
+	for _, v := range r.Versions {
+		_, _ = fmt.Fprintf(out, "  %d\t%s\n", v.ID, v.LastModified.Format(time.RFC3339))
+	}
+
+	_, _ = fmt.Fprintf(out, "L0 (%d SSTs):\n", len(r.L0))
+	if r.L0LastCompacted != "" {
+		_, _ = fmt.Fprintf(out, "  Last Compacted: %s\n", r.L0LastCompacted)
+	}
+	for _, sst := range r.L0 {
+		printSSTReport(out, "  ", sst)
+	}
+
+	_, _ = fmt.Fprintf(out, "Sorted Runs (%d):\n", len(r.SortedRuns))
+	for _, sr := range r.SortedRuns {
+		_, _ = fmt.Fprintf(out, "  Sorted Run %d (%d SSTs):\n", sr.ID, len(sr.SSTs))
+		for _, sst := range sr.SSTs {
+			printSSTReport(out, "    ", sst)
+		}
+	}
+
+	_, _ = fmt.Fprintf(out, "Checkpoints (%d):\n", len(r.Checkpoints))
+	for _, c := range r.Checkpoints {
+		expires := "never"
+		if c.ExpireTime != nil {
+			expires = c.ExpireTime.Format(time.RFC3339)
+		}
+		_, _ = fmt.Fprintf(out, "  %d\tmanifest %d\texpires %s\n", c.ID, c.ManifestID, expires)
+	}
+
+	if len(r.ExternalDBs) > 0 {
+		_, _ = fmt.Fprintf(out, "External DBs (%d):\n", len(r.ExternalDBs))
+		for _, e := range r.ExternalDBs {
+			_, _ = fmt.Fprintf(out, "  %s\tcheckpoint %d\t%d SSTs\n", e.Path, e.CheckpointID, e.SSTs)
+		}
+	}
+	if len(r.Quarantined) > 0 {
+		_, _ = fmt.Fprintf(out, "Quarantined (%d):\n", len(r.Quarantined))
+		for _, q := range r.Quarantined {
+			_, _ = fmt.Fprintf(out, "  %s\t%s\t%s\n", q.ID, q.Time.Format(time.RFC3339), q.Reason)
+		}
+	}
+}
+
+func printSSTReport(out io.Writer, indent string, sst sstReport) {
+	_, _ = fmt.Fprintf(out, "%s%s\t%d bytes\t[%q, %q]", indent, sst.ID, sst.Size, sst.FirstKey, sst.LastKey)
+	if sst.NumEntries > 0 {
+		_, _ = fmt.Fprintf(out, "\t%d entries", sst.NumEntries)
+	}
+	_, _ = fmt.Fprintln(out)
+}
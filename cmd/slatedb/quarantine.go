@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/slatedb/slatedb-go/slatedb"
+	"github.com/slatedb/slatedb-go/slatedb/config"
+)
+
+// quarantineList opens the database read-only and prints the SSTs which are quarantined because
+// corruption was detected in them
+func quarantineList(ctx context.Context, args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("quarantine list", flag.ContinueOnError)
+	bucketFlag := addBucketFlags(flags, "", "local directory containing the bucket")
+	path := flags.String("path", "", "path of the database within the bucket")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if !bucketFlag.isSet() || *path == "" {
+		return fmt.Errorf("-bucket and -path are required")
+	}
+
+	options := config.DefaultDBOptions()
+	options.ReadOnly = true
+	options.CompactorOptions = nil
+	db, closeDB, err := openDB(ctx, bucketFlag, *path, options)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	ssts, err := db.Quarantined()
+	if err != nil {
+		return err
+	}
+	for _, sst := range ssts {
+		kind := "compacted"
+		if sst.WAL {
+			kind = "wal"
+		}
+		_, _ = fmt.Fprintf(out, "%s\t%s\t%s\t%s\n", sst.SSTID, kind, sst.Time.Format(time.RFC3339), sst.Reason)
+	}
+	_, _ = fmt.Fprintf(out, "%d quarantined SSTs\n", len(ssts))
+	return nil
+}
+
+// quarantineClear opens the database and removes the SSTs with the given IDs from the quarantine.
+// Opening the database fences its current writer, which must be stopped first.
+func quarantineClear(ctx context.Context, args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("quarantine clear", flag.ContinueOnError)
+	bucketFlag := addBucketFlags(flags, "", "local directory containing the bucket")
+	path := flags.String("path", "", "path of the database within the bucket")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if !bucketFlag.isSet() || *path == "" || flags.NArg() == 0 {
+		return fmt.Errorf("-bucket, -path and at least one SST ID are required")
+	}
+
+	options := config.DefaultDBOptions()
+	options.CompactorOptions = nil
+	db, closeDB, err := openDB(ctx, bucketFlag, *path, options)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	if err := db.ClearQuarantine(flags.Args()...); err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(out, "cleared %d quarantined SSTs\n", flags.NArg())
+	return nil
+}
+
+// openDB opens the database at `path` within the bucket selected by `bucketFlag`, returning a function
+// which closes the database and the bucket
+func openDB(ctx context.Context, bucketFlag bucketFlags, path string, options config.DBOptions) (*slatedb.DB, func(), error) {
+	bucket, err := bucketFlag.open()
+	if err != nil {
+		return nil, nil, fmt.Errorf("while opening bucket: %w", err)
+	}
+	db, err := slatedb.OpenWithOptions(ctx, path, bucket, options)
+	if err != nil {
+		_ = bucket.Close()
+		return nil, nil, fmt.Errorf("while opening database: %w", err)
+	}
+	return db, func() {
+		_ = db.Close(ctx)
+		_ = bucket.Close()
+	}, nil
+}
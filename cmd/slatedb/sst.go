@@ -0,0 +1,216 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/slatedb/slatedb-go/internal/sstable"
+	"github.com/slatedb/slatedb-go/internal/sstable/block"
+	"github.com/slatedb/slatedb-go/internal/sstable/bloom"
+	"github.com/slatedb/slatedb-go/internal/types"
+	"github.com/slatedb/slatedb-go/slatedb/common"
+)
+
+// sstDump reads the SST object at the given path within the bucket and prints its info, index and
+// filter, and with -entries the entries of its blocks. Each block is decoded independently, such
+// that the blocks which are not corrupted are printed when others are.
+func sstDump(ctx context.Context, args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("sst dump", flag.ContinueOnError)
+	bucketFlag := addBucketFlags(flags, "", "local directory containing the bucket")
+	entries := flags.Bool("entries", false, "print the entries of the SST")
+	asHex := flags.Bool("hex", false, "print keys and values, and parse -start and -end, as hex rather than UTF-8")
+	start := flags.String("start", "", "only print entries with keys at or after this key")
+	end := flags.String("end", "", "only print entries with keys before this key")
+	limit := flags.Int("limit", 0, "maximum number of entries to print, unlimited if zero")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if !bucketFlag.isSet() || flags.NArg() != 1 {
+		return fmt.Errorf("-bucket and <sst path> are required")
+	}
+
+	render := renderUTF8
+	startKey, endKey := []byte(*start), []byte(*end)
+	if *asHex {
+		var err error
+		render = hex.EncodeToString
+		if startKey, err = hex.DecodeString(*start); err != nil {
+			return fmt.Errorf("while parsing -start: %w", err)
+		}
+		if endKey, err = hex.DecodeString(*end); err != nil {
+			return fmt.Errorf("while parsing -end: %w", err)
+		}
+	}
+
+	bucket, err := bucketFlag.open()
+	if err != nil {
+		return fmt.Errorf("while opening bucket: %w", err)
+	}
+	defer func() { _ = bucket.Close() }()
+
+	reader, err := bucket.Get(ctx, flags.Arg(0))
+	if err != nil {
+		return fmt.Errorf("while reading sst: %w", err)
+	}
+	data, err := io.ReadAll(reader)
+	_ = reader.Close()
+	if err != nil {
+		return fmt.Errorf("while reading sst: %w", err)
+	}
+
+	blob := sstable.NewBytesBlob(data)
+	info, err := sstable.ReadInfo(ctx, blob)
+	if err != nil {
+		return fmt.Errorf("while reading sst info: %w", err)
+	}
+	printSSTInfo(out, flags.Arg(0), len(data), info, render)
+	printSSTFilter(ctx, out, info, blob)
+
+	index, err := sstable.ReadIndex(ctx, info, blob)
+	if err != nil {
+		return fmt.Errorf("while reading sst index: %w", err)
+	}
+	blocks := index.BlockMeta()
+	_, _ = fmt.Fprintf(out, "Index (%d blocks):\n", len(blocks))
+	for i, meta := range blocks {
+		_, _ = fmt.Fprintf(out, "  Block %d: offset %d, first key %s\n", i, meta.Offset, render(meta.FirstKey))
+	}
+
+	if !*entries {
+		return nil
+	}
+	_, _ = fmt.Fprintf(out, "Entries:\n")
+	printed := 0
+	for i, meta := range blocks {
+		if len(endKey) > 0 && bytes.Compare(meta.FirstKey, endKey) >= 0 {
+			break
+		}
+		// The keys of a block are before the first key of the next block
+		if i+1 < len(blocks) && len(startKey) > 0 && bytes.Compare(blocks[i+1].FirstKey, startKey) <= 0 {
+			continue
+		}
+
+		blk, err := sstable.ReadBlockRaw(info, index, uint64(i), data)
+		if err != nil {
+			_, _ = fmt.Fprintf(out, "  ERROR: %s\n", err)
+			continue
+		}
+		iter := block.NewIterator(blk)
+		for {
+			entry, ok := iter.NextEntry(ctx)
+			if !ok {
+				break
+			}
+			if bytes.Compare(entry.Key, startKey) < 0 {
+				continue
+			}
+			if len(endKey) > 0 && bytes.Compare(entry.Key, endKey) >= 0 {
+				break
+			}
+			printSSTEntry(out, entry, render)
+			printed++
+			if *limit > 0 && printed >= *limit {
+				return nil
+			}
+		}
+		for _, w := range iter.Warnings().Warnings {
+			_, _ = fmt.Fprintf(out, "  ERROR: block %d: %s\n", i, w)
+		}
+	}
+	return nil
+}
+
+func printSSTInfo(out io.Writer, path string, size int, info *sstable.Info, render func([]byte) string) {
+	props := info.Properties()
+	_, _ = fmt.Fprintf(out, "SST %s (%d bytes)\n", path, size)
+	_, _ = fmt.Fprintf(out, "  First Key: %s\n", render(props.FirstKey))
+	if props.NumEntries > 0 {
+		_, _ = fmt.Fprintf(out, "  Last Key: %s\n", render(props.LastKey))
+		_, _ = fmt.Fprintf(out, "  Entries: %d (%d tombstones)\n", props.NumEntries, props.NumTombstones)
+		_, _ = fmt.Fprintf(out, "  Raw Key Size: %d\n", props.RawKeySize)
+		_, _ = fmt.Fprintf(out, "  Raw Value Size: %d\n", props.RawValueSize)
+	}
+	if !props.CreatedAt.IsZero() {
+		_, _ = fmt.Fprintf(out, "  Created At: %s\n", props.CreatedAt.Format(time.RFC3339Nano))
+	}
+	_, _ = fmt.Fprintf(out, "  Data Size: %d\n", props.DataSize)
+	_, _ = fmt.Fprintf(out, "  Index: offset %d, length %d\n", info.IndexOffset, info.IndexLen)
+	_, _ = fmt.Fprintf(out, "  Filter: offset %d, length %d\n", info.FilterOffset, info.FilterLen)
+	_, _ = fmt.Fprintf(out, "  Compression Codec: %s\n", info.CompressionCodec)
+	_, _ = fmt.Fprintf(out, "  Block Compression Flags: %t\n", info.BlockCompressionFlags)
+	_, _ = fmt.Fprintf(out, "  Block Checksum: %s\n", info.BlockChecksum)
+	if info.BlockRestartInterval > 0 {
+		_, _ = fmt.Fprintf(out, "  Block Restart Interval: %d\n", info.BlockRestartInterval)
+	}
+	for _, t := range info.RangeTombstones {
+		_, _ = fmt.Fprintf(out, "  Range Tombstone: [%s, %s) seq %d\n", render(t.Start), render(t.End), t.Seq)
+	}
+}
+
+// printSSTFilter prints the bloom filter of the SST, or of each of its partitions if the filter
+// is partitioned. A filter which cannot be read is reported rather than failing the dump.
+func printSSTFilter(ctx context.Context, out io.Writer, info *sstable.Info, blob common.ReadOnlyBlob) {
+	_, _ = fmt.Fprintf(out, "Filter:\n")
+	if info.FilterLen == 0 {
+		_, _ = fmt.Fprintf(out, "  No Bloom Filter\n")
+		return
+	}
+	if info.FilterBitsPerKey > 0 {
+		_, _ = fmt.Fprintf(out, "  Bits Per Key: %d (false positive rate %.4f)\n",
+			info.FilterBitsPerKey, bloom.FalsePositiveRate(info.FilterBitsPerKey))
+	}
+	if info.FilterPrefixExtractor != "" {
+		_, _ = fmt.Fprintf(out, "  Prefix Extractor: %s\n", info.FilterPrefixExtractor)
+	}
+
+	var filters []bloom.Filter
+	if info.FilterIndexLen > 0 {
+		_, partitions, err := sstable.ReadFilterPartitions(ctx, info, blob)
+		if err != nil {
+			_, _ = fmt.Fprintf(out, "  ERROR: %s\n", err)
+			return
+		}
+		_, _ = fmt.Fprintf(out, "  Partitions: %d\n", len(partitions))
+		filters = partitions
+	} else {
+		filter, err := sstable.ReadFilter(ctx, info, blob)
+		if err != nil {
+			_, _ = fmt.Fprintf(out, "  ERROR: %s\n", err)
+			return
+		}
+		if f, ok := filter.Get(); ok {
+			filters = append(filters, f)
+		}
+	}
+	for _, f := range filters {
+		_, _ = fmt.Fprintf(out, "  Probes: %d, Length: %d, Hash: %s, Seed: %d\n",
+			f.NumProbes, len(f.Data), f.Hash, f.Seed)
+	}
+}
+
+func printSSTEntry(out io.Writer, entry types.RowEntry, render func([]byte) string) {
+	var line string
+	switch entry.Value.Kind {
+	case types.KindTombStone:
+		line = fmt.Sprintf("seq=%d DELETE %s", entry.Seq, render(entry.Key))
+	case types.KindMerge:
+		line = fmt.Sprintf("seq=%d MERGE  %s = %s", entry.Seq, render(entry.Key), render(entry.Value.Value))
+	default:
+		line = fmt.Sprintf("seq=%d PUT    %s = %s", entry.Seq, render(entry.Key), render(entry.Value.Value))
+	}
+	if !entry.ExpireAt.IsZero() {
+		line += " expires " + entry.ExpireAt.Format(time.RFC3339)
+	}
+	_, _ = fmt.Fprintf(out, "  %s\n", line)
+}
+
+// renderUTF8 renders `b` as a quoted string, escaping the bytes which are not printable UTF-8
+func renderUTF8(b []byte) string {
+	return strconv.Quote(string(b))
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/slatedb/slatedb-go/slatedb"
+)
+
+// tail prints each change committed to the WAL of the database until interrupted
+func tail(ctx context.Context, args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("tail", flag.ContinueOnError)
+	bucketFlag := addBucketFlags(flags, "", "local directory containing the bucket")
+	prefix := flags.String("prefix", "", "only print changes to keys with this prefix")
+	interval := flags.Duration("interval", time.Second, "how often to poll for new WAL SSTs")
+	fromStart := flags.Bool("from-start", false, "print all changes in the WAL, not only new changes")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if !bucketFlag.isSet() || flags.NArg() != 1 {
+		return fmt.Errorf("-bucket and <db path> are required")
+	}
+
+	bucket, err := bucketFlag.open()
+	if err != nil {
+		return fmt.Errorf("while opening bucket: %w", err)
+	}
+	defer func() { _ = bucket.Close() }()
+
+	tailer := slatedb.NewWALTailer(flags.Arg(0), bucket, 0)
+	if !*fromStart {
+		latest, err := tailer.LatestWALID()
+		if err != nil {
+			return err
+		}
+		tailer = slatedb.NewWALTailer(flags.Arg(0), bucket, latest)
+	}
+
+	ticker := time.NewTicker(*interval)
+	defer ticker.Stop()
+	for {
+		entries, err := tailer.Poll(ctx)
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			if !bytes.HasPrefix(e.Key, []byte(*prefix)) {
+				continue
+			}
+			if e.Tombstone {
+				_, _ = fmt.Fprintf(out, "wal=%d DELETE %q\n", e.WALID, e.Key)
+				continue
+			}
+			_, _ = fmt.Fprintf(out, "wal=%d PUT    %q = %q\n", e.WALID, e.Key, e.Value)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/slatedb/slatedb-go/slatedb"
+)
+
+// kv is an entry of the response of GET /scan
+type kv struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// newHandler returns the HTTP API of the service, which serves `db`
+func newHandler(db *slatedb.DB) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /kv/{key}", func(w http.ResponseWriter, r *http.Request) {
+		value, err := db.Get(r.Context(), []byte(r.PathValue("key")))
+		if errors.Is(err, slatedb.ErrKeyNotFound) {
+			http.NotFound(w, r)
+			return
+		}
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		_, _ = w.Write(value)
+	})
+	mux.HandleFunc("PUT /kv/{key}", func(w http.ResponseWriter, r *http.Request) {
+		value, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		// Put returns once the value is durable in object storage
+		if err := db.Put(r.Context(), []byte(r.PathValue("key")), value); err != nil {
+			writeError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("DELETE /kv/{key}", func(w http.ResponseWriter, r *http.Request) {
+		if err := db.Delete(r.Context(), []byte(r.PathValue("key"))); err != nil {
+			writeError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("GET /scan", func(w http.ResponseWriter, r *http.Request) {
+		start, end := rangeParams(r)
+		it, err := db.Scan(r.Context(), start, end)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		result := make([]kv, 0)
+		for {
+			entry, ok := it.Next(r.Context())
+			if !ok {
+				break
+			}
+			result = append(result, kv{Key: string(entry.Key), Value: string(entry.Value)})
+		}
+		if err := it.Err(); err != nil {
+			writeError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(result)
+	})
+	mux.HandleFunc("POST /flush", func(w http.ResponseWriter, r *http.Request) {
+		if err := db.FlushMemtableToL0(); err != nil {
+			writeError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("POST /compact", func(w http.ResponseWriter, r *http.Request) {
+		start, end := rangeParams(r)
+		if err := db.CompactRange(r.Context(), start, end); err != nil {
+			writeError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("GET /health", func(w http.ResponseWriter, r *http.Request) {
+		if err := db.HealthCheck(); err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	return mux
+}
+
+// rangeParams returns the `start` and `end` query parameters of the request, which are nil if absent
+func rangeParams(r *http.Request) ([]byte, []byte) {
+	var start, end []byte
+	if r.URL.Query().Has("start") {
+		start = []byte(r.URL.Query().Get("start"))
+	}
+	if r.URL.Query().Has("end") {
+		end = []byte(r.URL.Query().Get("end"))
+	}
+	return start, end
+}
+
+// writeError responds with the error returned by the database
+func writeError(w http.ResponseWriter, err error) {
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore"
+
+	"github.com/slatedb/slatedb-go/slatedb"
+	"github.com/slatedb/slatedb-go/slatedb/config"
+	"github.com/slatedb/slatedb-go/slatedb/faultbucket"
+)
+
+// TestKVServer runs the service against a bucket which injects faults, and exercises the paths
+// of the database which a service depends on: open, write, flush, compaction and restart
+func TestKVServer(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	testKVServer(t, ctx, objstore.NewInMemBucket(), "kvserver")
+}
+
+// testKVServer runs the scenario of TestKVServer against the database at `path` in `inner`, which
+// is wrapped by a bucket which injects faults
+func testKVServer(t *testing.T, ctx context.Context, inner objstore.Bucket, path string) {
+	bucket := faultbucket.New(inner, faultbucket.Options{Seed: 1})
+	srv := startServer(t, ctx, bucket, path)
+
+	for _, key := range []string{"a", "b", "c", "d"} {
+		srv.expect(t, http.MethodPut, "/kv/"+key, "value-"+key, http.StatusNoContent)
+	}
+	srv.expect(t, http.MethodGet, "/kv/a", "", http.StatusOK, "value-a")
+	srv.expect(t, http.MethodDelete, "/kv/d", "", http.StatusNoContent)
+	srv.expect(t, http.MethodGet, "/kv/d", "", http.StatusNotFound)
+	srv.expect(t, http.MethodPost, "/flush", "", http.StatusNoContent)
+	assert.Equal(t, []kv{{"a", "value-a"}, {"b", "value-b"}, {"c", "value-c"}}, srv.scan(t, ""))
+
+	// A write is retried until its upload succeeds
+	bucket.SetFault(faultbucket.OpUpload, faultbucket.Fault{ErrorRate: 0.5})
+	srv.expect(t, http.MethodPut, "/kv/e", "value-e", http.StatusNoContent)
+	bucket.ClearFaults()
+	srv.expect(t, http.MethodGet, "/kv/e", "", http.StatusOK, "value-e")
+
+	srv.expect(t, http.MethodPost, "/flush", "", http.StatusNoContent)
+	srv.expect(t, http.MethodPost, "/compact", "", http.StatusNoContent)
+	assert.Equal(t, []kv{{"b", "value-b"}, {"c", "value-c"}}, srv.scan(t, "?start=b&end=d"))
+	srv.expect(t, http.MethodGet, "/health", "", http.StatusNoContent)
+
+	// The writes are served once the service restarts, including those which were not flushed
+	srv.expect(t, http.MethodPut, "/kv/f", "value-f", http.StatusNoContent)
+	srv.stop(t, ctx)
+	srv = startServer(t, ctx, bucket, path)
+	assert.Equal(t, []kv{{"a", "value-a"}, {"b", "value-b"}, {"c", "value-c"}, {"e", "value-e"},
+		{"f", "value-f"}}, srv.scan(t, ""))
+
+	// A read which fails in object storage is reported, and succeeds once it recovers
+	srv.expect(t, http.MethodPut, "/kv/g", "value-g", http.StatusNoContent)
+	srv.expect(t, http.MethodPost, "/flush", "", http.StatusNoContent)
+	srv.stop(t, ctx)
+	srv = startServer(t, ctx, bucket, path)
+	bucket.SetFault(faultbucket.OpGet, faultbucket.Fault{ErrorRate: 1})
+	bucket.SetFault(faultbucket.OpGetRange, faultbucket.Fault{ErrorRate: 1})
+	srv.expect(t, http.MethodGet, "/kv/a", "", http.StatusInternalServerError)
+	bucket.ClearFaults()
+	srv.expect(t, http.MethodGet, "/kv/a", "", http.StatusOK, "value-a")
+	srv.stop(t, ctx)
+}
+
+// testServer is the service serving a database through an httptest.Server
+type testServer struct {
+	db   *slatedb.DB
+	http *httptest.Server
+}
+
+func startServer(t *testing.T, ctx context.Context, bucket objstore.Bucket, path string) *testServer {
+	options := config.DefaultDBOptions()
+	options.CompactorOptions.PollInterval = 100 * time.Millisecond
+	db, err := slatedb.OpenWithOptions(ctx, path, bucket, options)
+	require.NoError(t, err)
+	return &testServer{db: db, http: httptest.NewServer(newHandler(db))}
+}
+
+func (s *testServer) stop(t *testing.T, ctx context.Context) {
+	s.http.Close()
+	require.NoError(t, s.db.Close(ctx))
+}
+
+// expect sends a request with `body` to `path`, and asserts the status and body of the response
+func (s *testServer) expect(t *testing.T, method, path, body string, status int, expected ...string) {
+	t.Helper()
+	req, err := http.NewRequest(method, s.http.URL+path, strings.NewReader(body))
+	require.NoError(t, err)
+	resp, err := s.http.Client().Do(req)
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, status, resp.StatusCode, "%s %s: %s", method, path, data)
+	for _, e := range expected {
+		assert.Equal(t, e, string(data))
+	}
+}
+
+// scan returns the entries returned by GET /scan with the query `query`
+func (s *testServer) scan(t *testing.T, query string) []kv {
+	t.Helper()
+	resp, err := s.http.Client().Get(s.http.URL + "/scan" + query)
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var result []kv
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	return result
+}
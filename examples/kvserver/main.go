@@ -0,0 +1,94 @@
+// Command kvserver is an example service which serves a SlateDB database over HTTP. It shows how
+// a service opens, writes, flushes, compacts and closes a database, and is exercised by the
+// integration tests of this package, which double as a regression suite for the public API.
+//
+// Usage:
+//
+//	kvserver -addr :8080 -bucket <dir> -path <db path>
+//
+// The API is:
+//
+//	GET    /kv/{key}                 returns the value of the key, or 404
+//	PUT    /kv/{key}                 sets the value of the key to the request body
+//	DELETE /kv/{key}                 deletes the key
+//	GET    /scan?start=<k>&end=<k>   returns the keys in [start, end) as JSON
+//	POST   /flush                    flushes the memtable to L0
+//	POST   /compact?start=<k>&end=<k> compacts the keys in [start, end) into the bottommost sorted run
+//	GET    /health                   returns 503 if the database is unhealthy
+//
+// If -bucket is empty the database is held in memory, and is lost when the service stops. The
+// bucket may be any objstore.Bucket, such as an S3 bucket served by MinIO or localstack.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/thanos-io/objstore"
+	"github.com/thanos-io/objstore/providers/filesystem"
+
+	"github.com/slatedb/slatedb-go/slatedb"
+	"github.com/slatedb/slatedb-go/slatedb/config"
+)
+
+func main() {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	if err := run(ctx, os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("kvserver", flag.ContinueOnError)
+	addr := flags.String("addr", ":8080", "address the HTTP API listens on")
+	bucketDir := flags.String("bucket", "", "local directory containing the bucket; in memory if empty")
+	path := flags.String("path", "kvserver", "path of the database within the bucket")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	var bucket objstore.Bucket = objstore.NewInMemBucket()
+	if *bucketDir != "" {
+		fs, err := filesystem.NewBucket(*bucketDir)
+		if err != nil {
+			return err
+		}
+		bucket = fs
+	}
+
+	db, err := slatedb.OpenWithOptions(ctx, *path, bucket, config.DefaultDBOptions())
+	if err != nil {
+		return err
+	}
+
+	srv := &http.Server{Addr: *addr, Handler: newHandler(db)}
+	errCh := make(chan error, 1)
+	go func() { errCh <- srv.ListenAndServe() }()
+	slog.Info("serving database", "addr", *addr, "path", *path)
+
+	select {
+	case err = <-errCh:
+	case <-ctx.Done():
+		// The requests in progress are completed before the database is closed
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		err = srv.Shutdown(shutdownCtx)
+	}
+	if errors.Is(err, http.ErrServerClosed) {
+		err = nil
+	}
+
+	closeCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return errors.Join(err, db.Close(closeCtx))
+}
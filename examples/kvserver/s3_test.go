@@ -0,0 +1,68 @@
+//go:build s3
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore"
+	"github.com/thanos-io/objstore/providers/s3"
+
+	"github.com/slatedb/slatedb-go/slatedb/s3bucket"
+)
+
+// TestKVServerS3 runs the scenario of TestKVServer against an S3 compatible endpoint, such as
+// MinIO or localstack, rather than an in memory bucket, with the manifests written by conditional
+// uploads, see s3bucket.Bucket. It is built with the s3 build tag and
+// configured by the environment, for example
+//
+//	SLATEDB_S3_ENDPOINT=localhost:9000 SLATEDB_S3_BUCKET=slatedb \
+//	SLATEDB_S3_ACCESS_KEY=minioadmin SLATEDB_S3_SECRET_KEY=minioadmin SLATEDB_S3_INSECURE=true \
+//	go test -tags s3 -run TestKVServerS3 ./examples/kvserver
+//
+// The bucket must exist. Each run writes the database to a new path in the bucket, which is
+// deleted once the test completes.
+func TestKVServerS3(t *testing.T) {
+	endpoint := os.Getenv("SLATEDB_S3_ENDPOINT")
+	if endpoint == "" {
+		t.Skip("SLATEDB_S3_ENDPOINT is not set")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	config := s3.DefaultConfig
+	config.Endpoint = endpoint
+	config.Bucket = os.Getenv("SLATEDB_S3_BUCKET")
+	config.Region = os.Getenv("SLATEDB_S3_REGION")
+	config.AccessKey = os.Getenv("SLATEDB_S3_ACCESS_KEY")
+	config.SecretKey = os.Getenv("SLATEDB_S3_SECRET_KEY")
+	config.Insecure = os.Getenv("SLATEDB_S3_INSECURE") == "true"
+	// MinIO and localstack serve buckets by path rather than by virtual host
+	config.BucketLookupType = s3.PathLookup
+	s3Bucket, err := s3.NewBucketWithConfig(log.NewNopLogger(), config, "kvserver-test", nil)
+	require.NoError(t, err)
+	defer func() { _ = s3Bucket.Close() }()
+	bucket := s3bucket.New(s3Bucket, s3bucket.Config{
+		Endpoint:     config.Endpoint,
+		Bucket:       config.Bucket,
+		Region:       config.Region,
+		AccessKey:    config.AccessKey,
+		SecretKey:    config.SecretKey,
+		SessionToken: config.SessionToken,
+		Insecure:     config.Insecure,
+	})
+
+	path := fmt.Sprintf("kvserver-test-%d", time.Now().UnixNano())
+	defer func() {
+		_ = bucket.Iter(context.Background(), path, func(name string) error {
+			return bucket.Delete(context.Background(), name)
+		}, objstore.WithRecursiveIter())
+	}()
+	testKVServer(t, ctx, bucket, path)
+}
@@ -78,6 +78,31 @@ func CodecToFlatBuf(c Codec) flatbuf.CompressionCodec {
 
 // Encode the provided byte slice
 func Encode(buf []byte, codec Codec) ([]byte, error) {
+	return EncodeWithLevel(buf, codec, 0)
+}
+
+// ValidLevel returns true if `level` is a valid compression level for the codec. Levels are
+// only supported by CodecZlib (1 to 9) and CodecZstd (1 to 22), zero selects the default
+// level of any codec.
+func ValidLevel(codec Codec, level int) bool {
+	switch {
+	case level == 0:
+		return true
+	case codec == CodecZlib:
+		return level >= zlib.BestSpeed && level <= zlib.BestCompression
+	case codec == CodecZstd:
+		return level >= 1 && level <= 22
+	default:
+		return false
+	}
+}
+
+// EncodeWithLevel encodes the provided byte slice with the compression level of the
+// codec, see ValidLevel. Decoding does not depend on the level.
+func EncodeWithLevel(buf []byte, codec Codec, level int) ([]byte, error) {
+	if !ValidLevel(codec, level) {
+		return nil, internal.Err("invalid compression level %d for codec %s", level, codec)
+	}
 	switch codec {
 	case CodecNone:
 		return buf, nil
@@ -86,9 +111,15 @@ func Encode(buf []byte, codec Codec) ([]byte, error) {
 		return snappy.Encode(nil, buf), nil
 
 	case CodecZlib:
+		if level == 0 {
+			level = zlib.DefaultCompression
+		}
 		var b bytes.Buffer
-		w := zlib.NewWriter(&b)
-		_, err := w.Write(buf)
+		w, err := zlib.NewWriterLevel(&b, level)
+		if err != nil {
+			return nil, err
+		}
+		_, err = w.Write(buf)
 		_ = w.Close()
 		if err != nil {
 			return nil, err
@@ -106,8 +137,12 @@ func Encode(buf []byte, codec Codec) ([]byte, error) {
 		return b.Bytes(), nil
 
 	case CodecZstd:
+		var opts []zstd.EOption
+		if level != 0 {
+			opts = append(opts, zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(level)))
+		}
 		var b bytes.Buffer
-		w, err := zstd.NewWriter(&b)
+		w, err := zstd.NewWriter(&b, opts...)
 		if err != nil {
 			return nil, err
 		}
@@ -83,3 +83,28 @@ func TestCompressDecompressLargeInput(t *testing.T) {
 		})
 	}
 }
+
+func TestCompressWithLevel(t *testing.T) {
+	input := bytes.Repeat([]byte("Compression level test "), 1000)
+	for _, codec := range []Codec{CodecZlib, CodecZstd} {
+		fastest, err := EncodeWithLevel(input, codec, 1)
+		require.NoError(t, err)
+		best, err := EncodeWithLevel(input, codec, 9)
+		require.NoError(t, err)
+		assert.LessOrEqual(t, len(best), len(fastest))
+
+		// the level is not needed to decode
+		for _, compressed := range [][]byte{fastest, best} {
+			decompressed, err := Decode(compressed, codec)
+			require.NoError(t, err)
+			assert.Equal(t, input, decompressed)
+		}
+	}
+
+	assert.True(t, ValidLevel(CodecZstd, 22))
+	assert.False(t, ValidLevel(CodecZstd, 23))
+	assert.False(t, ValidLevel(CodecZlib, 10))
+	assert.False(t, ValidLevel(CodecSnappy, 1))
+	_, err := EncodeWithLevel(input, CodecSnappy, 1)
+	assert.Error(t, err)
+}
@@ -0,0 +1,14 @@
+package internal
+
+import "context"
+
+// DetachContext returns a context which is not cancelled when ctx is done, but which has the
+// same deadline as ctx, if any. It is used for requests which are started by a call but which
+// outlive it, such as the blocks an iterator prefetches.
+func DetachContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	detached := context.WithoutCancel(ctx)
+	if deadline, ok := ctx.Deadline(); ok {
+		return context.WithDeadline(detached, deadline)
+	}
+	return context.WithCancel(detached)
+}
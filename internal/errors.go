@@ -58,3 +58,25 @@ type ExportedKeyNotFound struct {
 func (e ExportedKeyNotFound) Error() string {
 	return e.Msg
 }
+
+// ErrBlockCorruption returns an error indicating the block at index `block` of the SST `sstID`
+// failed to decode, where `err` is the reason the block failed to decode
+func ErrBlockCorruption(sstID string, block uint64, err error) error {
+	return &ExportedBlockCorruption{SSTID: sstID, Block: block, err: err}
+}
+
+type ExportedBlockCorruption struct {
+	// SSTID is the ID of the SST which holds the corrupted block
+	SSTID string
+	// Block is the index of the corrupted block within the SST
+	Block uint64
+	err   error
+}
+
+func (e ExportedBlockCorruption) Error() string {
+	return fmt.Sprintf("corrupted block '%d' of sst '%s': %s", e.Block, e.SSTID, e.err)
+}
+
+func (e ExportedBlockCorruption) Unwrap() error {
+	return e.err
+}
@@ -248,12 +248,26 @@ func CompactedSsTableEnd(builder *flatbuffers.Builder) flatbuffers.UOffsetT {
 }
 
 type SsTableInfoT struct {
-	FirstKey          []byte           `json:"first_key"`
-	IndexOffset       uint64           `json:"index_offset"`
-	IndexLen          uint64           `json:"index_len"`
-	FilterOffset      uint64           `json:"filter_offset"`
-	FilterLen         uint64           `json:"filter_len"`
-	CompressionFormat CompressionCodec `json:"compression_format"`
+	FirstKey              []byte             `json:"first_key"`
+	IndexOffset           uint64             `json:"index_offset"`
+	IndexLen              uint64             `json:"index_len"`
+	FilterOffset          uint64             `json:"filter_offset"`
+	FilterLen             uint64             `json:"filter_len"`
+	CompressionFormat     CompressionCodec   `json:"compression_format"`
+	BlockCompressionFlags bool               `json:"block_compression_flags"`
+	RangeTombstones       []*RangeTombstoneT `json:"range_tombstones"`
+	IngestSeq             uint64             `json:"ingest_seq"`
+	BlockChecksum         byte               `json:"block_checksum"`
+	FilterIndexLen        uint64             `json:"filter_index_len"`
+	FilterBitsPerKey      uint32             `json:"filter_bits_per_key"`
+	FilterPrefixExtractor string             `json:"filter_prefix_extractor"`
+	BlockRestartInterval  uint16             `json:"block_restart_interval"`
+	LastKey               []byte             `json:"last_key"`
+	NumEntries            uint64             `json:"num_entries"`
+	NumTombstones         uint64             `json:"num_tombstones"`
+	RawKeySize            uint64             `json:"raw_key_size"`
+	RawValueSize          uint64             `json:"raw_value_size"`
+	CreationTimeMs        int64              `json:"creation_time_ms"`
 }
 
 func (t *SsTableInfoT) Pack(builder *flatbuffers.Builder) flatbuffers.UOffsetT {
@@ -264,6 +278,27 @@ func (t *SsTableInfoT) Pack(builder *flatbuffers.Builder) flatbuffers.UOffsetT {
 	if t.FirstKey != nil {
 		firstKeyOffset = builder.CreateByteString(t.FirstKey)
 	}
+	rangeTombstonesOffset := flatbuffers.UOffsetT(0)
+	if t.RangeTombstones != nil {
+		rangeTombstonesLength := len(t.RangeTombstones)
+		rangeTombstonesOffsets := make([]flatbuffers.UOffsetT, rangeTombstonesLength)
+		for j := 0; j < rangeTombstonesLength; j++ {
+			rangeTombstonesOffsets[j] = t.RangeTombstones[j].Pack(builder)
+		}
+		SsTableInfoStartRangeTombstonesVector(builder, rangeTombstonesLength)
+		for j := rangeTombstonesLength - 1; j >= 0; j-- {
+			builder.PrependUOffsetT(rangeTombstonesOffsets[j])
+		}
+		rangeTombstonesOffset = builder.EndVector(rangeTombstonesLength)
+	}
+	filterPrefixExtractorOffset := flatbuffers.UOffsetT(0)
+	if t.FilterPrefixExtractor != "" {
+		filterPrefixExtractorOffset = builder.CreateString(t.FilterPrefixExtractor)
+	}
+	lastKeyOffset := flatbuffers.UOffsetT(0)
+	if t.LastKey != nil {
+		lastKeyOffset = builder.CreateByteString(t.LastKey)
+	}
 	SsTableInfoStart(builder)
 	SsTableInfoAddFirstKey(builder, firstKeyOffset)
 	SsTableInfoAddIndexOffset(builder, t.IndexOffset)
@@ -271,6 +306,20 @@ func (t *SsTableInfoT) Pack(builder *flatbuffers.Builder) flatbuffers.UOffsetT {
 	SsTableInfoAddFilterOffset(builder, t.FilterOffset)
 	SsTableInfoAddFilterLen(builder, t.FilterLen)
 	SsTableInfoAddCompressionFormat(builder, t.CompressionFormat)
+	SsTableInfoAddBlockCompressionFlags(builder, t.BlockCompressionFlags)
+	SsTableInfoAddRangeTombstones(builder, rangeTombstonesOffset)
+	SsTableInfoAddIngestSeq(builder, t.IngestSeq)
+	SsTableInfoAddBlockChecksum(builder, t.BlockChecksum)
+	SsTableInfoAddFilterIndexLen(builder, t.FilterIndexLen)
+	SsTableInfoAddFilterBitsPerKey(builder, t.FilterBitsPerKey)
+	SsTableInfoAddFilterPrefixExtractor(builder, filterPrefixExtractorOffset)
+	SsTableInfoAddBlockRestartInterval(builder, t.BlockRestartInterval)
+	SsTableInfoAddLastKey(builder, lastKeyOffset)
+	SsTableInfoAddNumEntries(builder, t.NumEntries)
+	SsTableInfoAddNumTombstones(builder, t.NumTombstones)
+	SsTableInfoAddRawKeySize(builder, t.RawKeySize)
+	SsTableInfoAddRawValueSize(builder, t.RawValueSize)
+	SsTableInfoAddCreationTimeMs(builder, t.CreationTimeMs)
 	return SsTableInfoEnd(builder)
 }
 
@@ -281,6 +330,26 @@ func (rcv *SsTableInfo) UnPackTo(t *SsTableInfoT) {
 	t.FilterOffset = rcv.FilterOffset()
 	t.FilterLen = rcv.FilterLen()
 	t.CompressionFormat = rcv.CompressionFormat()
+	t.BlockCompressionFlags = rcv.BlockCompressionFlags()
+	rangeTombstonesLength := rcv.RangeTombstonesLength()
+	t.RangeTombstones = make([]*RangeTombstoneT, rangeTombstonesLength)
+	for j := 0; j < rangeTombstonesLength; j++ {
+		x := RangeTombstone{}
+		rcv.RangeTombstones(&x, j)
+		t.RangeTombstones[j] = x.UnPack()
+	}
+	t.IngestSeq = rcv.IngestSeq()
+	t.BlockChecksum = rcv.BlockChecksum()
+	t.FilterIndexLen = rcv.FilterIndexLen()
+	t.FilterBitsPerKey = rcv.FilterBitsPerKey()
+	t.FilterPrefixExtractor = string(rcv.FilterPrefixExtractor())
+	t.BlockRestartInterval = rcv.BlockRestartInterval()
+	t.LastKey = rcv.LastKeyBytes()
+	t.NumEntries = rcv.NumEntries()
+	t.NumTombstones = rcv.NumTombstones()
+	t.RawKeySize = rcv.RawKeySize()
+	t.RawValueSize = rcv.RawValueSize()
+	t.CreationTimeMs = rcv.CreationTimeMs()
 }
 
 func (rcv *SsTableInfo) UnPack() *SsTableInfoT {
@@ -421,8 +490,202 @@ func (rcv *SsTableInfo) MutateCompressionFormat(n CompressionCodec) bool {
 	return rcv._tab.MutateInt8Slot(14, int8(n))
 }
 
+func (rcv *SsTableInfo) BlockCompressionFlags() bool {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(16))
+	if o != 0 {
+		return rcv._tab.GetBool(o + rcv._tab.Pos)
+	}
+	return false
+}
+
+func (rcv *SsTableInfo) MutateBlockCompressionFlags(n bool) bool {
+	return rcv._tab.MutateBoolSlot(16, n)
+}
+
+func (rcv *SsTableInfo) RangeTombstones(obj *RangeTombstone, j int) bool {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(18))
+	if o != 0 {
+		x := rcv._tab.Vector(o)
+		x += flatbuffers.UOffsetT(j) * 4
+		x = rcv._tab.Indirect(x)
+		obj.Init(rcv._tab.Bytes, x)
+		return true
+	}
+	return false
+}
+
+func (rcv *SsTableInfo) RangeTombstonesLength() int {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(18))
+	if o != 0 {
+		return rcv._tab.VectorLen(o)
+	}
+	return 0
+}
+
+func (rcv *SsTableInfo) IngestSeq() uint64 {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(20))
+	if o != 0 {
+		return rcv._tab.GetUint64(o + rcv._tab.Pos)
+	}
+	return 0
+}
+
+func (rcv *SsTableInfo) MutateIngestSeq(n uint64) bool {
+	return rcv._tab.MutateUint64Slot(20, n)
+}
+
+func (rcv *SsTableInfo) BlockChecksum() byte {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(22))
+	if o != 0 {
+		return rcv._tab.GetByte(o + rcv._tab.Pos)
+	}
+	return 0
+}
+
+func (rcv *SsTableInfo) MutateBlockChecksum(n byte) bool {
+	return rcv._tab.MutateByteSlot(22, n)
+}
+
+func (rcv *SsTableInfo) FilterIndexLen() uint64 {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(24))
+	if o != 0 {
+		return rcv._tab.GetUint64(o + rcv._tab.Pos)
+	}
+	return 0
+}
+
+func (rcv *SsTableInfo) MutateFilterIndexLen(n uint64) bool {
+	return rcv._tab.MutateUint64Slot(24, n)
+}
+
+func (rcv *SsTableInfo) FilterBitsPerKey() uint32 {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(26))
+	if o != 0 {
+		return rcv._tab.GetUint32(o + rcv._tab.Pos)
+	}
+	return 0
+}
+
+func (rcv *SsTableInfo) MutateFilterBitsPerKey(n uint32) bool {
+	return rcv._tab.MutateUint32Slot(26, n)
+}
+
+func (rcv *SsTableInfo) FilterPrefixExtractor() []byte {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(28))
+	if o != 0 {
+		return rcv._tab.ByteVector(o + rcv._tab.Pos)
+	}
+	return nil
+}
+
+func (rcv *SsTableInfo) BlockRestartInterval() uint16 {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(30))
+	if o != 0 {
+		return rcv._tab.GetUint16(o + rcv._tab.Pos)
+	}
+	return 0
+}
+
+func (rcv *SsTableInfo) MutateBlockRestartInterval(n uint16) bool {
+	return rcv._tab.MutateUint16Slot(30, n)
+}
+
+func (rcv *SsTableInfo) LastKey(j int) byte {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(32))
+	if o != 0 {
+		a := rcv._tab.Vector(o)
+		return rcv._tab.GetByte(a + flatbuffers.UOffsetT(j*1))
+	}
+	return 0
+}
+
+func (rcv *SsTableInfo) LastKeyLength() int {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(32))
+	if o != 0 {
+		return rcv._tab.VectorLen(o)
+	}
+	return 0
+}
+
+func (rcv *SsTableInfo) LastKeyBytes() []byte {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(32))
+	if o != 0 {
+		return rcv._tab.ByteVector(o + rcv._tab.Pos)
+	}
+	return nil
+}
+
+func (rcv *SsTableInfo) MutateLastKey(j int, n byte) bool {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(32))
+	if o != 0 {
+		a := rcv._tab.Vector(o)
+		return rcv._tab.MutateByte(a+flatbuffers.UOffsetT(j*1), n)
+	}
+	return false
+}
+
+func (rcv *SsTableInfo) NumEntries() uint64 {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(34))
+	if o != 0 {
+		return rcv._tab.GetUint64(o + rcv._tab.Pos)
+	}
+	return 0
+}
+
+func (rcv *SsTableInfo) MutateNumEntries(n uint64) bool {
+	return rcv._tab.MutateUint64Slot(34, n)
+}
+
+func (rcv *SsTableInfo) NumTombstones() uint64 {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(36))
+	if o != 0 {
+		return rcv._tab.GetUint64(o + rcv._tab.Pos)
+	}
+	return 0
+}
+
+func (rcv *SsTableInfo) MutateNumTombstones(n uint64) bool {
+	return rcv._tab.MutateUint64Slot(36, n)
+}
+
+func (rcv *SsTableInfo) RawKeySize() uint64 {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(38))
+	if o != 0 {
+		return rcv._tab.GetUint64(o + rcv._tab.Pos)
+	}
+	return 0
+}
+
+func (rcv *SsTableInfo) MutateRawKeySize(n uint64) bool {
+	return rcv._tab.MutateUint64Slot(38, n)
+}
+
+func (rcv *SsTableInfo) RawValueSize() uint64 {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(40))
+	if o != 0 {
+		return rcv._tab.GetUint64(o + rcv._tab.Pos)
+	}
+	return 0
+}
+
+func (rcv *SsTableInfo) MutateRawValueSize(n uint64) bool {
+	return rcv._tab.MutateUint64Slot(40, n)
+}
+
+func (rcv *SsTableInfo) CreationTimeMs() int64 {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(42))
+	if o != 0 {
+		return rcv._tab.GetInt64(o + rcv._tab.Pos)
+	}
+	return 0
+}
+
+func (rcv *SsTableInfo) MutateCreationTimeMs(n int64) bool {
+	return rcv._tab.MutateInt64Slot(42, n)
+}
+
 func SsTableInfoStart(builder *flatbuffers.Builder) {
-	builder.StartObject(6)
+	builder.StartObject(20)
 }
 func SsTableInfoAddFirstKey(builder *flatbuffers.Builder, firstKey flatbuffers.UOffsetT) {
 	builder.PrependUOffsetTSlot(0, flatbuffers.UOffsetT(firstKey), 0)
@@ -445,91 +708,168 @@ func SsTableInfoAddFilterLen(builder *flatbuffers.Builder, filterLen uint64) {
 func SsTableInfoAddCompressionFormat(builder *flatbuffers.Builder, compressionFormat CompressionCodec) {
 	builder.PrependInt8Slot(5, int8(compressionFormat), 0)
 }
+func SsTableInfoAddBlockCompressionFlags(builder *flatbuffers.Builder, blockCompressionFlags bool) {
+	builder.PrependBoolSlot(6, blockCompressionFlags, false)
+}
+func SsTableInfoAddRangeTombstones(builder *flatbuffers.Builder, rangeTombstones flatbuffers.UOffsetT) {
+	builder.PrependUOffsetTSlot(7, flatbuffers.UOffsetT(rangeTombstones), 0)
+}
+func SsTableInfoStartRangeTombstonesVector(builder *flatbuffers.Builder, numElems int) flatbuffers.UOffsetT {
+	return builder.StartVector(4, numElems, 4)
+}
+func SsTableInfoAddIngestSeq(builder *flatbuffers.Builder, ingestSeq uint64) {
+	builder.PrependUint64Slot(8, ingestSeq, 0)
+}
+func SsTableInfoAddBlockChecksum(builder *flatbuffers.Builder, blockChecksum byte) {
+	builder.PrependByteSlot(9, blockChecksum, 0)
+}
+func SsTableInfoAddFilterIndexLen(builder *flatbuffers.Builder, filterIndexLen uint64) {
+	builder.PrependUint64Slot(10, filterIndexLen, 0)
+}
+func SsTableInfoAddFilterBitsPerKey(builder *flatbuffers.Builder, filterBitsPerKey uint32) {
+	builder.PrependUint32Slot(11, filterBitsPerKey, 0)
+}
+func SsTableInfoAddFilterPrefixExtractor(builder *flatbuffers.Builder, filterPrefixExtractor flatbuffers.UOffsetT) {
+	builder.PrependUOffsetTSlot(12, flatbuffers.UOffsetT(filterPrefixExtractor), 0)
+}
+func SsTableInfoAddBlockRestartInterval(builder *flatbuffers.Builder, blockRestartInterval uint16) {
+	builder.PrependUint16Slot(13, blockRestartInterval, 0)
+}
+func SsTableInfoAddLastKey(builder *flatbuffers.Builder, lastKey flatbuffers.UOffsetT) {
+	builder.PrependUOffsetTSlot(14, flatbuffers.UOffsetT(lastKey), 0)
+}
+func SsTableInfoStartLastKeyVector(builder *flatbuffers.Builder, numElems int) flatbuffers.UOffsetT {
+	return builder.StartVector(1, numElems, 1)
+}
+func SsTableInfoAddNumEntries(builder *flatbuffers.Builder, numEntries uint64) {
+	builder.PrependUint64Slot(15, numEntries, 0)
+}
+func SsTableInfoAddNumTombstones(builder *flatbuffers.Builder, numTombstones uint64) {
+	builder.PrependUint64Slot(16, numTombstones, 0)
+}
+func SsTableInfoAddRawKeySize(builder *flatbuffers.Builder, rawKeySize uint64) {
+	builder.PrependUint64Slot(17, rawKeySize, 0)
+}
+func SsTableInfoAddRawValueSize(builder *flatbuffers.Builder, rawValueSize uint64) {
+	builder.PrependUint64Slot(18, rawValueSize, 0)
+}
+func SsTableInfoAddCreationTimeMs(builder *flatbuffers.Builder, creationTimeMs int64) {
+	builder.PrependInt64Slot(19, creationTimeMs, 0)
+}
 func SsTableInfoEnd(builder *flatbuffers.Builder) flatbuffers.UOffsetT {
 	return builder.EndObject()
 }
 
-type BlockMetaT struct {
-	Offset   uint64 `json:"offset"`
-	FirstKey []byte `json:"first_key"`
+type RangeTombstoneT struct {
+	Start []byte `json:"start"`
+	End   []byte `json:"end"`
+	Seq   uint64 `json:"seq"`
 }
 
-func (t *BlockMetaT) Pack(builder *flatbuffers.Builder) flatbuffers.UOffsetT {
+func (t *RangeTombstoneT) Pack(builder *flatbuffers.Builder) flatbuffers.UOffsetT {
 	if t == nil {
 		return 0
 	}
-	firstKeyOffset := flatbuffers.UOffsetT(0)
-	if t.FirstKey != nil {
-		firstKeyOffset = builder.CreateByteString(t.FirstKey)
+	startOffset := flatbuffers.UOffsetT(0)
+	if t.Start != nil {
+		startOffset = builder.CreateByteString(t.Start)
 	}
-	BlockMetaStart(builder)
-	BlockMetaAddOffset(builder, t.Offset)
-	BlockMetaAddFirstKey(builder, firstKeyOffset)
-	return BlockMetaEnd(builder)
+	endOffset := flatbuffers.UOffsetT(0)
+	if t.End != nil {
+		endOffset = builder.CreateByteString(t.End)
+	}
+	RangeTombstoneStart(builder)
+	RangeTombstoneAddStart(builder, startOffset)
+	RangeTombstoneAddEnd(builder, endOffset)
+	RangeTombstoneAddSeq(builder, t.Seq)
+	return RangeTombstoneEnd(builder)
 }
 
-func (rcv *BlockMeta) UnPackTo(t *BlockMetaT) {
-	t.Offset = rcv.Offset()
-	t.FirstKey = rcv.FirstKeyBytes()
+func (rcv *RangeTombstone) UnPackTo(t *RangeTombstoneT) {
+	t.Start = rcv.StartBytes()
+	t.End = rcv.EndBytes()
+	t.Seq = rcv.Seq()
 }
 
-func (rcv *BlockMeta) UnPack() *BlockMetaT {
+func (rcv *RangeTombstone) UnPack() *RangeTombstoneT {
 	if rcv == nil {
 		return nil
 	}
-	t := &BlockMetaT{}
+	t := &RangeTombstoneT{}
 	rcv.UnPackTo(t)
 	return t
 }
 
-type BlockMeta struct {
+type RangeTombstone struct {
 	_tab flatbuffers.Table
 }
 
-func GetRootAsBlockMeta(buf []byte, offset flatbuffers.UOffsetT) *BlockMeta {
+func GetRootAsRangeTombstone(buf []byte, offset flatbuffers.UOffsetT) *RangeTombstone {
 	n := flatbuffers.GetUOffsetT(buf[offset:])
-	x := &BlockMeta{}
+	x := &RangeTombstone{}
 	x.Init(buf, n+offset)
 	return x
 }
 
-func FinishBlockMetaBuffer(builder *flatbuffers.Builder, offset flatbuffers.UOffsetT) {
+func FinishRangeTombstoneBuffer(builder *flatbuffers.Builder, offset flatbuffers.UOffsetT) {
 	builder.Finish(offset)
 }
 
-func GetSizePrefixedRootAsBlockMeta(buf []byte, offset flatbuffers.UOffsetT) *BlockMeta {
+func GetSizePrefixedRootAsRangeTombstone(buf []byte, offset flatbuffers.UOffsetT) *RangeTombstone {
 	n := flatbuffers.GetUOffsetT(buf[offset+flatbuffers.SizeUint32:])
-	x := &BlockMeta{}
+	x := &RangeTombstone{}
 	x.Init(buf, n+offset+flatbuffers.SizeUint32)
 	return x
 }
 
-func FinishSizePrefixedBlockMetaBuffer(builder *flatbuffers.Builder, offset flatbuffers.UOffsetT) {
+func FinishSizePrefixedRangeTombstoneBuffer(builder *flatbuffers.Builder, offset flatbuffers.UOffsetT) {
 	builder.FinishSizePrefixed(offset)
 }
 
-func (rcv *BlockMeta) Init(buf []byte, i flatbuffers.UOffsetT) {
+func (rcv *RangeTombstone) Init(buf []byte, i flatbuffers.UOffsetT) {
 	rcv._tab.Bytes = buf
 	rcv._tab.Pos = i
 }
 
-func (rcv *BlockMeta) Table() flatbuffers.Table {
+func (rcv *RangeTombstone) Table() flatbuffers.Table {
 	return rcv._tab
 }
 
-func (rcv *BlockMeta) Offset() uint64 {
+func (rcv *RangeTombstone) Start(j int) byte {
 	o := flatbuffers.UOffsetT(rcv._tab.Offset(4))
 	if o != 0 {
-		return rcv._tab.GetUint64(o + rcv._tab.Pos)
+		a := rcv._tab.Vector(o)
+		return rcv._tab.GetByte(a + flatbuffers.UOffsetT(j*1))
 	}
 	return 0
 }
 
-func (rcv *BlockMeta) MutateOffset(n uint64) bool {
-	return rcv._tab.MutateUint64Slot(4, n)
+func (rcv *RangeTombstone) StartLength() int {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(4))
+	if o != 0 {
+		return rcv._tab.VectorLen(o)
+	}
+	return 0
 }
 
-func (rcv *BlockMeta) FirstKey(j int) byte {
+func (rcv *RangeTombstone) StartBytes() []byte {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(4))
+	if o != 0 {
+		return rcv._tab.ByteVector(o + rcv._tab.Pos)
+	}
+	return nil
+}
+
+func (rcv *RangeTombstone) MutateStart(j int, n byte) bool {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(4))
+	if o != 0 {
+		a := rcv._tab.Vector(o)
+		return rcv._tab.MutateByte(a+flatbuffers.UOffsetT(j*1), n)
+	}
+	return false
+}
+
+func (rcv *RangeTombstone) End(j int) byte {
 	o := flatbuffers.UOffsetT(rcv._tab.Offset(6))
 	if o != 0 {
 		a := rcv._tab.Vector(o)
@@ -538,7 +878,7 @@ func (rcv *BlockMeta) FirstKey(j int) byte {
 	return 0
 }
 
-func (rcv *BlockMeta) FirstKeyLength() int {
+func (rcv *RangeTombstone) EndLength() int {
 	o := flatbuffers.UOffsetT(rcv._tab.Offset(6))
 	if o != 0 {
 		return rcv._tab.VectorLen(o)
@@ -546,7 +886,7 @@ func (rcv *BlockMeta) FirstKeyLength() int {
 	return 0
 }
 
-func (rcv *BlockMeta) FirstKeyBytes() []byte {
+func (rcv *RangeTombstone) EndBytes() []byte {
 	o := flatbuffers.UOffsetT(rcv._tab.Offset(6))
 	if o != 0 {
 		return rcv._tab.ByteVector(o + rcv._tab.Pos)
@@ -554,7 +894,7 @@ func (rcv *BlockMeta) FirstKeyBytes() []byte {
 	return nil
 }
 
-func (rcv *BlockMeta) MutateFirstKey(j int, n byte) bool {
+func (rcv *RangeTombstone) MutateEnd(j int, n byte) bool {
 	o := flatbuffers.UOffsetT(rcv._tab.Offset(6))
 	if o != 0 {
 		a := rcv._tab.Vector(o)
@@ -563,340 +903,1126 @@ func (rcv *BlockMeta) MutateFirstKey(j int, n byte) bool {
 	return false
 }
 
-func BlockMetaStart(builder *flatbuffers.Builder) {
-	builder.StartObject(2)
+func (rcv *RangeTombstone) Seq() uint64 {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(8))
+	if o != 0 {
+		return rcv._tab.GetUint64(o + rcv._tab.Pos)
+	}
+	return 0
 }
-func BlockMetaAddOffset(builder *flatbuffers.Builder, offset uint64) {
-	builder.PrependUint64Slot(0, offset, 0)
+
+func (rcv *RangeTombstone) MutateSeq(n uint64) bool {
+	return rcv._tab.MutateUint64Slot(8, n)
 }
-func BlockMetaAddFirstKey(builder *flatbuffers.Builder, firstKey flatbuffers.UOffsetT) {
-	builder.PrependUOffsetTSlot(1, flatbuffers.UOffsetT(firstKey), 0)
+
+func RangeTombstoneStart(builder *flatbuffers.Builder) {
+	builder.StartObject(3)
 }
-func BlockMetaStartFirstKeyVector(builder *flatbuffers.Builder, numElems int) flatbuffers.UOffsetT {
+func RangeTombstoneAddStart(builder *flatbuffers.Builder, start flatbuffers.UOffsetT) {
+	builder.PrependUOffsetTSlot(0, flatbuffers.UOffsetT(start), 0)
+}
+func RangeTombstoneStartStartVector(builder *flatbuffers.Builder, numElems int) flatbuffers.UOffsetT {
 	return builder.StartVector(1, numElems, 1)
 }
-func BlockMetaEnd(builder *flatbuffers.Builder) flatbuffers.UOffsetT {
+func RangeTombstoneAddEnd(builder *flatbuffers.Builder, end flatbuffers.UOffsetT) {
+	builder.PrependUOffsetTSlot(1, flatbuffers.UOffsetT(end), 0)
+}
+func RangeTombstoneStartEndVector(builder *flatbuffers.Builder, numElems int) flatbuffers.UOffsetT {
+	return builder.StartVector(1, numElems, 1)
+}
+func RangeTombstoneAddSeq(builder *flatbuffers.Builder, seq uint64) {
+	builder.PrependUint64Slot(2, seq, 0)
+}
+func RangeTombstoneEnd(builder *flatbuffers.Builder) flatbuffers.UOffsetT {
 	return builder.EndObject()
 }
 
-type SsTableIndexT struct {
-	BlockMeta []*BlockMetaT `json:"block_meta"`
+type BlockMetaT struct {
+	Offset   uint64 `json:"offset"`
+	FirstKey []byte `json:"first_key"`
 }
 
-func (t *SsTableIndexT) Pack(builder *flatbuffers.Builder) flatbuffers.UOffsetT {
+func (t *BlockMetaT) Pack(builder *flatbuffers.Builder) flatbuffers.UOffsetT {
 	if t == nil {
 		return 0
 	}
-	blockMetaOffset := flatbuffers.UOffsetT(0)
-	if t.BlockMeta != nil {
-		blockMetaLength := len(t.BlockMeta)
-		blockMetaOffsets := make([]flatbuffers.UOffsetT, blockMetaLength)
-		for j := 0; j < blockMetaLength; j++ {
-			blockMetaOffsets[j] = t.BlockMeta[j].Pack(builder)
-		}
-		SsTableIndexStartBlockMetaVector(builder, blockMetaLength)
-		for j := blockMetaLength - 1; j >= 0; j-- {
-			builder.PrependUOffsetT(blockMetaOffsets[j])
-		}
-		blockMetaOffset = builder.EndVector(blockMetaLength)
-	}
-	SsTableIndexStart(builder)
-	SsTableIndexAddBlockMeta(builder, blockMetaOffset)
-	return SsTableIndexEnd(builder)
+	firstKeyOffset := flatbuffers.UOffsetT(0)
+	if t.FirstKey != nil {
+		firstKeyOffset = builder.CreateByteString(t.FirstKey)
+	}
+	BlockMetaStart(builder)
+	BlockMetaAddOffset(builder, t.Offset)
+	BlockMetaAddFirstKey(builder, firstKeyOffset)
+	return BlockMetaEnd(builder)
 }
 
-func (rcv *SsTableIndex) UnPackTo(t *SsTableIndexT) {
-	blockMetaLength := rcv.BlockMetaLength()
-	t.BlockMeta = make([]*BlockMetaT, blockMetaLength)
-	for j := 0; j < blockMetaLength; j++ {
-		x := BlockMeta{}
-		rcv.BlockMeta(&x, j)
-		t.BlockMeta[j] = x.UnPack()
-	}
+func (rcv *BlockMeta) UnPackTo(t *BlockMetaT) {
+	t.Offset = rcv.Offset()
+	t.FirstKey = rcv.FirstKeyBytes()
 }
 
-func (rcv *SsTableIndex) UnPack() *SsTableIndexT {
+func (rcv *BlockMeta) UnPack() *BlockMetaT {
 	if rcv == nil {
 		return nil
 	}
-	t := &SsTableIndexT{}
+	t := &BlockMetaT{}
 	rcv.UnPackTo(t)
 	return t
 }
 
-type SsTableIndex struct {
+type BlockMeta struct {
 	_tab flatbuffers.Table
 }
 
-func GetRootAsSsTableIndex(buf []byte, offset flatbuffers.UOffsetT) *SsTableIndex {
+func GetRootAsBlockMeta(buf []byte, offset flatbuffers.UOffsetT) *BlockMeta {
 	n := flatbuffers.GetUOffsetT(buf[offset:])
-	x := &SsTableIndex{}
+	x := &BlockMeta{}
 	x.Init(buf, n+offset)
 	return x
 }
 
-func FinishSsTableIndexBuffer(builder *flatbuffers.Builder, offset flatbuffers.UOffsetT) {
+func FinishBlockMetaBuffer(builder *flatbuffers.Builder, offset flatbuffers.UOffsetT) {
 	builder.Finish(offset)
 }
 
-func GetSizePrefixedRootAsSsTableIndex(buf []byte, offset flatbuffers.UOffsetT) *SsTableIndex {
+func GetSizePrefixedRootAsBlockMeta(buf []byte, offset flatbuffers.UOffsetT) *BlockMeta {
 	n := flatbuffers.GetUOffsetT(buf[offset+flatbuffers.SizeUint32:])
-	x := &SsTableIndex{}
+	x := &BlockMeta{}
 	x.Init(buf, n+offset+flatbuffers.SizeUint32)
 	return x
 }
 
-func FinishSizePrefixedSsTableIndexBuffer(builder *flatbuffers.Builder, offset flatbuffers.UOffsetT) {
+func FinishSizePrefixedBlockMetaBuffer(builder *flatbuffers.Builder, offset flatbuffers.UOffsetT) {
 	builder.FinishSizePrefixed(offset)
 }
 
-func (rcv *SsTableIndex) Init(buf []byte, i flatbuffers.UOffsetT) {
+func (rcv *BlockMeta) Init(buf []byte, i flatbuffers.UOffsetT) {
 	rcv._tab.Bytes = buf
 	rcv._tab.Pos = i
 }
 
-func (rcv *SsTableIndex) Table() flatbuffers.Table {
+func (rcv *BlockMeta) Table() flatbuffers.Table {
 	return rcv._tab
 }
 
-func (rcv *SsTableIndex) BlockMeta(obj *BlockMeta, j int) bool {
+func (rcv *BlockMeta) Offset() uint64 {
 	o := flatbuffers.UOffsetT(rcv._tab.Offset(4))
 	if o != 0 {
-		x := rcv._tab.Vector(o)
-		x += flatbuffers.UOffsetT(j) * 4
-		x = rcv._tab.Indirect(x)
-		obj.Init(rcv._tab.Bytes, x)
-		return true
+		return rcv._tab.GetUint64(o + rcv._tab.Pos)
 	}
-	return false
+	return 0
 }
 
-func (rcv *SsTableIndex) BlockMetaLength() int {
-	o := flatbuffers.UOffsetT(rcv._tab.Offset(4))
+func (rcv *BlockMeta) MutateOffset(n uint64) bool {
+	return rcv._tab.MutateUint64Slot(4, n)
+}
+
+func (rcv *BlockMeta) FirstKey(j int) byte {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(6))
+	if o != 0 {
+		a := rcv._tab.Vector(o)
+		return rcv._tab.GetByte(a + flatbuffers.UOffsetT(j*1))
+	}
+	return 0
+}
+
+func (rcv *BlockMeta) FirstKeyLength() int {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(6))
 	if o != 0 {
 		return rcv._tab.VectorLen(o)
 	}
 	return 0
 }
 
-func SsTableIndexStart(builder *flatbuffers.Builder) {
-	builder.StartObject(1)
+func (rcv *BlockMeta) FirstKeyBytes() []byte {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(6))
+	if o != 0 {
+		return rcv._tab.ByteVector(o + rcv._tab.Pos)
+	}
+	return nil
 }
-func SsTableIndexAddBlockMeta(builder *flatbuffers.Builder, blockMeta flatbuffers.UOffsetT) {
-	builder.PrependUOffsetTSlot(0, flatbuffers.UOffsetT(blockMeta), 0)
+
+func (rcv *BlockMeta) MutateFirstKey(j int, n byte) bool {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(6))
+	if o != 0 {
+		a := rcv._tab.Vector(o)
+		return rcv._tab.MutateByte(a+flatbuffers.UOffsetT(j*1), n)
+	}
+	return false
 }
-func SsTableIndexStartBlockMetaVector(builder *flatbuffers.Builder, numElems int) flatbuffers.UOffsetT {
-	return builder.StartVector(4, numElems, 4)
+
+func BlockMetaStart(builder *flatbuffers.Builder) {
+	builder.StartObject(2)
 }
-func SsTableIndexEnd(builder *flatbuffers.Builder) flatbuffers.UOffsetT {
+func BlockMetaAddOffset(builder *flatbuffers.Builder, offset uint64) {
+	builder.PrependUint64Slot(0, offset, 0)
+}
+func BlockMetaAddFirstKey(builder *flatbuffers.Builder, firstKey flatbuffers.UOffsetT) {
+	builder.PrependUOffsetTSlot(1, flatbuffers.UOffsetT(firstKey), 0)
+}
+func BlockMetaStartFirstKeyVector(builder *flatbuffers.Builder, numElems int) flatbuffers.UOffsetT {
+	return builder.StartVector(1, numElems, 1)
+}
+func BlockMetaEnd(builder *flatbuffers.Builder) flatbuffers.UOffsetT {
 	return builder.EndObject()
 }
 
-type ManifestV1T struct {
-	ManifestId         uint64               `json:"manifest_id"`
-	WriterEpoch        uint64               `json:"writer_epoch"`
-	CompactorEpoch     uint64               `json:"compactor_epoch"`
-	WalIdLastCompacted uint64               `json:"wal_id_last_compacted"`
-	WalIdLastSeen      uint64               `json:"wal_id_last_seen"`
-	L0LastCompacted    *CompactedSstIdT     `json:"l0_last_compacted"`
-	L0                 []*CompactedSsTableT `json:"l0"`
-	Compacted          []*SortedRunT        `json:"compacted"`
-	Snapshots          []*SnapshotT         `json:"snapshots"`
+type InlineValueT struct {
+	Key []byte `json:"key"`
+	Row []byte `json:"row"`
 }
 
-func (t *ManifestV1T) Pack(builder *flatbuffers.Builder) flatbuffers.UOffsetT {
+func (t *InlineValueT) Pack(builder *flatbuffers.Builder) flatbuffers.UOffsetT {
 	if t == nil {
 		return 0
 	}
-	l0LastCompactedOffset := t.L0LastCompacted.Pack(builder)
-	l0Offset := flatbuffers.UOffsetT(0)
-	if t.L0 != nil {
-		l0Length := len(t.L0)
-		l0Offsets := make([]flatbuffers.UOffsetT, l0Length)
-		for j := 0; j < l0Length; j++ {
-			l0Offsets[j] = t.L0[j].Pack(builder)
-		}
-		ManifestV1StartL0Vector(builder, l0Length)
-		for j := l0Length - 1; j >= 0; j-- {
-			builder.PrependUOffsetT(l0Offsets[j])
-		}
-		l0Offset = builder.EndVector(l0Length)
-	}
-	compactedOffset := flatbuffers.UOffsetT(0)
-	if t.Compacted != nil {
-		compactedLength := len(t.Compacted)
-		compactedOffsets := make([]flatbuffers.UOffsetT, compactedLength)
-		for j := 0; j < compactedLength; j++ {
-			compactedOffsets[j] = t.Compacted[j].Pack(builder)
-		}
-		ManifestV1StartCompactedVector(builder, compactedLength)
-		for j := compactedLength - 1; j >= 0; j-- {
-			builder.PrependUOffsetT(compactedOffsets[j])
-		}
-		compactedOffset = builder.EndVector(compactedLength)
+	keyOffset := flatbuffers.UOffsetT(0)
+	if t.Key != nil {
+		keyOffset = builder.CreateByteString(t.Key)
 	}
-	snapshotsOffset := flatbuffers.UOffsetT(0)
-	if t.Snapshots != nil {
-		snapshotsLength := len(t.Snapshots)
-		snapshotsOffsets := make([]flatbuffers.UOffsetT, snapshotsLength)
-		for j := 0; j < snapshotsLength; j++ {
-			snapshotsOffsets[j] = t.Snapshots[j].Pack(builder)
-		}
-		ManifestV1StartSnapshotsVector(builder, snapshotsLength)
-		for j := snapshotsLength - 1; j >= 0; j-- {
-			builder.PrependUOffsetT(snapshotsOffsets[j])
-		}
-		snapshotsOffset = builder.EndVector(snapshotsLength)
+	rowOffset := flatbuffers.UOffsetT(0)
+	if t.Row != nil {
+		rowOffset = builder.CreateByteString(t.Row)
 	}
-	ManifestV1Start(builder)
-	ManifestV1AddManifestId(builder, t.ManifestId)
-	ManifestV1AddWriterEpoch(builder, t.WriterEpoch)
-	ManifestV1AddCompactorEpoch(builder, t.CompactorEpoch)
-	ManifestV1AddWalIdLastCompacted(builder, t.WalIdLastCompacted)
-	ManifestV1AddWalIdLastSeen(builder, t.WalIdLastSeen)
-	ManifestV1AddL0LastCompacted(builder, l0LastCompactedOffset)
-	ManifestV1AddL0(builder, l0Offset)
-	ManifestV1AddCompacted(builder, compactedOffset)
-	ManifestV1AddSnapshots(builder, snapshotsOffset)
-	return ManifestV1End(builder)
+	InlineValueStart(builder)
+	InlineValueAddKey(builder, keyOffset)
+	InlineValueAddRow(builder, rowOffset)
+	return InlineValueEnd(builder)
 }
 
-func (rcv *ManifestV1) UnPackTo(t *ManifestV1T) {
-	t.ManifestId = rcv.ManifestId()
-	t.WriterEpoch = rcv.WriterEpoch()
-	t.CompactorEpoch = rcv.CompactorEpoch()
-	t.WalIdLastCompacted = rcv.WalIdLastCompacted()
-	t.WalIdLastSeen = rcv.WalIdLastSeen()
-	t.L0LastCompacted = rcv.L0LastCompacted(nil).UnPack()
-	l0Length := rcv.L0Length()
-	t.L0 = make([]*CompactedSsTableT, l0Length)
-	for j := 0; j < l0Length; j++ {
-		x := CompactedSsTable{}
-		rcv.L0(&x, j)
-		t.L0[j] = x.UnPack()
-	}
-	compactedLength := rcv.CompactedLength()
-	t.Compacted = make([]*SortedRunT, compactedLength)
-	for j := 0; j < compactedLength; j++ {
-		x := SortedRun{}
-		rcv.Compacted(&x, j)
-		t.Compacted[j] = x.UnPack()
-	}
-	snapshotsLength := rcv.SnapshotsLength()
-	t.Snapshots = make([]*SnapshotT, snapshotsLength)
-	for j := 0; j < snapshotsLength; j++ {
-		x := Snapshot{}
-		rcv.Snapshots(&x, j)
-		t.Snapshots[j] = x.UnPack()
-	}
+func (rcv *InlineValue) UnPackTo(t *InlineValueT) {
+	t.Key = rcv.KeyBytes()
+	t.Row = rcv.RowBytes()
 }
 
-func (rcv *ManifestV1) UnPack() *ManifestV1T {
+func (rcv *InlineValue) UnPack() *InlineValueT {
 	if rcv == nil {
 		return nil
 	}
-	t := &ManifestV1T{}
+	t := &InlineValueT{}
 	rcv.UnPackTo(t)
 	return t
 }
 
-type ManifestV1 struct {
+type InlineValue struct {
 	_tab flatbuffers.Table
 }
 
-func GetRootAsManifestV1(buf []byte, offset flatbuffers.UOffsetT) *ManifestV1 {
+func GetRootAsInlineValue(buf []byte, offset flatbuffers.UOffsetT) *InlineValue {
 	n := flatbuffers.GetUOffsetT(buf[offset:])
-	x := &ManifestV1{}
+	x := &InlineValue{}
 	x.Init(buf, n+offset)
 	return x
 }
 
-func FinishManifestV1Buffer(builder *flatbuffers.Builder, offset flatbuffers.UOffsetT) {
+func FinishInlineValueBuffer(builder *flatbuffers.Builder, offset flatbuffers.UOffsetT) {
 	builder.Finish(offset)
 }
 
-func GetSizePrefixedRootAsManifestV1(buf []byte, offset flatbuffers.UOffsetT) *ManifestV1 {
+func GetSizePrefixedRootAsInlineValue(buf []byte, offset flatbuffers.UOffsetT) *InlineValue {
 	n := flatbuffers.GetUOffsetT(buf[offset+flatbuffers.SizeUint32:])
-	x := &ManifestV1{}
+	x := &InlineValue{}
 	x.Init(buf, n+offset+flatbuffers.SizeUint32)
 	return x
 }
 
-func FinishSizePrefixedManifestV1Buffer(builder *flatbuffers.Builder, offset flatbuffers.UOffsetT) {
+func FinishSizePrefixedInlineValueBuffer(builder *flatbuffers.Builder, offset flatbuffers.UOffsetT) {
 	builder.FinishSizePrefixed(offset)
 }
 
-func (rcv *ManifestV1) Init(buf []byte, i flatbuffers.UOffsetT) {
+func (rcv *InlineValue) Init(buf []byte, i flatbuffers.UOffsetT) {
 	rcv._tab.Bytes = buf
 	rcv._tab.Pos = i
 }
 
-func (rcv *ManifestV1) Table() flatbuffers.Table {
+func (rcv *InlineValue) Table() flatbuffers.Table {
 	return rcv._tab
 }
 
-func (rcv *ManifestV1) ManifestId() uint64 {
+func (rcv *InlineValue) Key(j int) byte {
 	o := flatbuffers.UOffsetT(rcv._tab.Offset(4))
 	if o != 0 {
-		return rcv._tab.GetUint64(o + rcv._tab.Pos)
+		a := rcv._tab.Vector(o)
+		return rcv._tab.GetByte(a + flatbuffers.UOffsetT(j*1))
 	}
 	return 0
 }
 
-func (rcv *ManifestV1) MutateManifestId(n uint64) bool {
-	return rcv._tab.MutateUint64Slot(4, n)
-}
-
-func (rcv *ManifestV1) WriterEpoch() uint64 {
-	o := flatbuffers.UOffsetT(rcv._tab.Offset(6))
+func (rcv *InlineValue) KeyLength() int {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(4))
 	if o != 0 {
-		return rcv._tab.GetUint64(o + rcv._tab.Pos)
+		return rcv._tab.VectorLen(o)
 	}
 	return 0
 }
 
-func (rcv *ManifestV1) MutateWriterEpoch(n uint64) bool {
-	return rcv._tab.MutateUint64Slot(6, n)
-}
-
-func (rcv *ManifestV1) CompactorEpoch() uint64 {
-	o := flatbuffers.UOffsetT(rcv._tab.Offset(8))
+func (rcv *InlineValue) KeyBytes() []byte {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(4))
 	if o != 0 {
-		return rcv._tab.GetUint64(o + rcv._tab.Pos)
+		return rcv._tab.ByteVector(o + rcv._tab.Pos)
 	}
-	return 0
+	return nil
 }
 
-func (rcv *ManifestV1) MutateCompactorEpoch(n uint64) bool {
-	return rcv._tab.MutateUint64Slot(8, n)
+func (rcv *InlineValue) MutateKey(j int, n byte) bool {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(4))
+	if o != 0 {
+		a := rcv._tab.Vector(o)
+		return rcv._tab.MutateByte(a+flatbuffers.UOffsetT(j*1), n)
+	}
+	return false
 }
 
-func (rcv *ManifestV1) WalIdLastCompacted() uint64 {
-	o := flatbuffers.UOffsetT(rcv._tab.Offset(10))
+func (rcv *InlineValue) Row(j int) byte {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(6))
 	if o != 0 {
-		return rcv._tab.GetUint64(o + rcv._tab.Pos)
+		a := rcv._tab.Vector(o)
+		return rcv._tab.GetByte(a + flatbuffers.UOffsetT(j*1))
 	}
 	return 0
 }
 
-func (rcv *ManifestV1) MutateWalIdLastCompacted(n uint64) bool {
-	return rcv._tab.MutateUint64Slot(10, n)
+func (rcv *InlineValue) RowLength() int {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(6))
+	if o != 0 {
+		return rcv._tab.VectorLen(o)
+	}
+	return 0
 }
 
-func (rcv *ManifestV1) WalIdLastSeen() uint64 {
-	o := flatbuffers.UOffsetT(rcv._tab.Offset(12))
+func (rcv *InlineValue) RowBytes() []byte {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(6))
 	if o != 0 {
-		return rcv._tab.GetUint64(o + rcv._tab.Pos)
+		return rcv._tab.ByteVector(o + rcv._tab.Pos)
 	}
-	return 0
+	return nil
 }
 
-func (rcv *ManifestV1) MutateWalIdLastSeen(n uint64) bool {
+func (rcv *InlineValue) MutateRow(j int, n byte) bool {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(6))
+	if o != 0 {
+		a := rcv._tab.Vector(o)
+		return rcv._tab.MutateByte(a+flatbuffers.UOffsetT(j*1), n)
+	}
+	return false
+}
+
+func InlineValueStart(builder *flatbuffers.Builder) {
+	builder.StartObject(2)
+}
+func InlineValueAddKey(builder *flatbuffers.Builder, key flatbuffers.UOffsetT) {
+	builder.PrependUOffsetTSlot(0, flatbuffers.UOffsetT(key), 0)
+}
+func InlineValueStartKeyVector(builder *flatbuffers.Builder, numElems int) flatbuffers.UOffsetT {
+	return builder.StartVector(1, numElems, 1)
+}
+func InlineValueAddRow(builder *flatbuffers.Builder, row flatbuffers.UOffsetT) {
+	builder.PrependUOffsetTSlot(1, flatbuffers.UOffsetT(row), 0)
+}
+func InlineValueStartRowVector(builder *flatbuffers.Builder, numElems int) flatbuffers.UOffsetT {
+	return builder.StartVector(1, numElems, 1)
+}
+func InlineValueEnd(builder *flatbuffers.Builder) flatbuffers.UOffsetT {
+	return builder.EndObject()
+}
+
+type SsTableIndexT struct {
+	BlockMeta    []*BlockMetaT   `json:"block_meta"`
+	InlineValues []*InlineValueT `json:"inline_values"`
+}
+
+func (t *SsTableIndexT) Pack(builder *flatbuffers.Builder) flatbuffers.UOffsetT {
+	if t == nil {
+		return 0
+	}
+	blockMetaOffset := flatbuffers.UOffsetT(0)
+	if t.BlockMeta != nil {
+		blockMetaLength := len(t.BlockMeta)
+		blockMetaOffsets := make([]flatbuffers.UOffsetT, blockMetaLength)
+		for j := 0; j < blockMetaLength; j++ {
+			blockMetaOffsets[j] = t.BlockMeta[j].Pack(builder)
+		}
+		SsTableIndexStartBlockMetaVector(builder, blockMetaLength)
+		for j := blockMetaLength - 1; j >= 0; j-- {
+			builder.PrependUOffsetT(blockMetaOffsets[j])
+		}
+		blockMetaOffset = builder.EndVector(blockMetaLength)
+	}
+	inlineValuesOffset := flatbuffers.UOffsetT(0)
+	if t.InlineValues != nil {
+		inlineValuesLength := len(t.InlineValues)
+		inlineValuesOffsets := make([]flatbuffers.UOffsetT, inlineValuesLength)
+		for j := 0; j < inlineValuesLength; j++ {
+			inlineValuesOffsets[j] = t.InlineValues[j].Pack(builder)
+		}
+		SsTableIndexStartInlineValuesVector(builder, inlineValuesLength)
+		for j := inlineValuesLength - 1; j >= 0; j-- {
+			builder.PrependUOffsetT(inlineValuesOffsets[j])
+		}
+		inlineValuesOffset = builder.EndVector(inlineValuesLength)
+	}
+	SsTableIndexStart(builder)
+	SsTableIndexAddBlockMeta(builder, blockMetaOffset)
+	SsTableIndexAddInlineValues(builder, inlineValuesOffset)
+	return SsTableIndexEnd(builder)
+}
+
+func (rcv *SsTableIndex) UnPackTo(t *SsTableIndexT) {
+	blockMetaLength := rcv.BlockMetaLength()
+	t.BlockMeta = make([]*BlockMetaT, blockMetaLength)
+	for j := 0; j < blockMetaLength; j++ {
+		x := BlockMeta{}
+		rcv.BlockMeta(&x, j)
+		t.BlockMeta[j] = x.UnPack()
+	}
+	inlineValuesLength := rcv.InlineValuesLength()
+	t.InlineValues = make([]*InlineValueT, inlineValuesLength)
+	for j := 0; j < inlineValuesLength; j++ {
+		x := InlineValue{}
+		rcv.InlineValues(&x, j)
+		t.InlineValues[j] = x.UnPack()
+	}
+}
+
+func (rcv *SsTableIndex) UnPack() *SsTableIndexT {
+	if rcv == nil {
+		return nil
+	}
+	t := &SsTableIndexT{}
+	rcv.UnPackTo(t)
+	return t
+}
+
+type SsTableIndex struct {
+	_tab flatbuffers.Table
+}
+
+func GetRootAsSsTableIndex(buf []byte, offset flatbuffers.UOffsetT) *SsTableIndex {
+	n := flatbuffers.GetUOffsetT(buf[offset:])
+	x := &SsTableIndex{}
+	x.Init(buf, n+offset)
+	return x
+}
+
+func FinishSsTableIndexBuffer(builder *flatbuffers.Builder, offset flatbuffers.UOffsetT) {
+	builder.Finish(offset)
+}
+
+func GetSizePrefixedRootAsSsTableIndex(buf []byte, offset flatbuffers.UOffsetT) *SsTableIndex {
+	n := flatbuffers.GetUOffsetT(buf[offset+flatbuffers.SizeUint32:])
+	x := &SsTableIndex{}
+	x.Init(buf, n+offset+flatbuffers.SizeUint32)
+	return x
+}
+
+func FinishSizePrefixedSsTableIndexBuffer(builder *flatbuffers.Builder, offset flatbuffers.UOffsetT) {
+	builder.FinishSizePrefixed(offset)
+}
+
+func (rcv *SsTableIndex) Init(buf []byte, i flatbuffers.UOffsetT) {
+	rcv._tab.Bytes = buf
+	rcv._tab.Pos = i
+}
+
+func (rcv *SsTableIndex) Table() flatbuffers.Table {
+	return rcv._tab
+}
+
+func (rcv *SsTableIndex) BlockMeta(obj *BlockMeta, j int) bool {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(4))
+	if o != 0 {
+		x := rcv._tab.Vector(o)
+		x += flatbuffers.UOffsetT(j) * 4
+		x = rcv._tab.Indirect(x)
+		obj.Init(rcv._tab.Bytes, x)
+		return true
+	}
+	return false
+}
+
+func (rcv *SsTableIndex) BlockMetaLength() int {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(4))
+	if o != 0 {
+		return rcv._tab.VectorLen(o)
+	}
+	return 0
+}
+
+func (rcv *SsTableIndex) InlineValues(obj *InlineValue, j int) bool {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(6))
+	if o != 0 {
+		x := rcv._tab.Vector(o)
+		x += flatbuffers.UOffsetT(j) * 4
+		x = rcv._tab.Indirect(x)
+		obj.Init(rcv._tab.Bytes, x)
+		return true
+	}
+	return false
+}
+
+func (rcv *SsTableIndex) InlineValuesLength() int {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(6))
+	if o != 0 {
+		return rcv._tab.VectorLen(o)
+	}
+	return 0
+}
+
+func SsTableIndexStart(builder *flatbuffers.Builder) {
+	builder.StartObject(2)
+}
+func SsTableIndexAddBlockMeta(builder *flatbuffers.Builder, blockMeta flatbuffers.UOffsetT) {
+	builder.PrependUOffsetTSlot(0, flatbuffers.UOffsetT(blockMeta), 0)
+}
+func SsTableIndexStartBlockMetaVector(builder *flatbuffers.Builder, numElems int) flatbuffers.UOffsetT {
+	return builder.StartVector(4, numElems, 4)
+}
+func SsTableIndexAddInlineValues(builder *flatbuffers.Builder, inlineValues flatbuffers.UOffsetT) {
+	builder.PrependUOffsetTSlot(1, flatbuffers.UOffsetT(inlineValues), 0)
+}
+func SsTableIndexStartInlineValuesVector(builder *flatbuffers.Builder, numElems int) flatbuffers.UOffsetT {
+	return builder.StartVector(4, numElems, 4)
+}
+func SsTableIndexEnd(builder *flatbuffers.Builder) flatbuffers.UOffsetT {
+	return builder.EndObject()
+}
+
+type ManifestV1T struct {
+	ManifestId         uint64               `json:"manifest_id"`
+	WriterEpoch        uint64               `json:"writer_epoch"`
+	CompactorEpoch     uint64               `json:"compactor_epoch"`
+	WalIdLastCompacted uint64               `json:"wal_id_last_compacted"`
+	WalIdLastSeen      uint64               `json:"wal_id_last_seen"`
+	L0LastCompacted    *CompactedSstIdT     `json:"l0_last_compacted"`
+	L0                 []*CompactedSsTableT `json:"l0"`
+	Compacted          []*SortedRunT        `json:"compacted"`
+	Snapshots          []*SnapshotT         `json:"snapshots"`
+	Features           uint64               `json:"features"`
+	LastSeq            uint64               `json:"last_seq"`
+	ExternalDbs        []*ExternalDbT       `json:"external_dbs"`
+	FilterSidecars     []*CompactedSstIdT   `json:"filter_sidecars"`
+	Quarantined        []*QuarantinedSstT   `json:"quarantined"`
+	Leases             []*SstLeaseT         `json:"leases"`
+}
+
+func (t *ManifestV1T) Pack(builder *flatbuffers.Builder) flatbuffers.UOffsetT {
+	if t == nil {
+		return 0
+	}
+	l0LastCompactedOffset := t.L0LastCompacted.Pack(builder)
+	l0Offset := flatbuffers.UOffsetT(0)
+	if t.L0 != nil {
+		l0Length := len(t.L0)
+		l0Offsets := make([]flatbuffers.UOffsetT, l0Length)
+		for j := 0; j < l0Length; j++ {
+			l0Offsets[j] = t.L0[j].Pack(builder)
+		}
+		ManifestV1StartL0Vector(builder, l0Length)
+		for j := l0Length - 1; j >= 0; j-- {
+			builder.PrependUOffsetT(l0Offsets[j])
+		}
+		l0Offset = builder.EndVector(l0Length)
+	}
+	compactedOffset := flatbuffers.UOffsetT(0)
+	if t.Compacted != nil {
+		compactedLength := len(t.Compacted)
+		compactedOffsets := make([]flatbuffers.UOffsetT, compactedLength)
+		for j := 0; j < compactedLength; j++ {
+			compactedOffsets[j] = t.Compacted[j].Pack(builder)
+		}
+		ManifestV1StartCompactedVector(builder, compactedLength)
+		for j := compactedLength - 1; j >= 0; j-- {
+			builder.PrependUOffsetT(compactedOffsets[j])
+		}
+		compactedOffset = builder.EndVector(compactedLength)
+	}
+	snapshotsOffset := flatbuffers.UOffsetT(0)
+	if t.Snapshots != nil {
+		snapshotsLength := len(t.Snapshots)
+		snapshotsOffsets := make([]flatbuffers.UOffsetT, snapshotsLength)
+		for j := 0; j < snapshotsLength; j++ {
+			snapshotsOffsets[j] = t.Snapshots[j].Pack(builder)
+		}
+		ManifestV1StartSnapshotsVector(builder, snapshotsLength)
+		for j := snapshotsLength - 1; j >= 0; j-- {
+			builder.PrependUOffsetT(snapshotsOffsets[j])
+		}
+		snapshotsOffset = builder.EndVector(snapshotsLength)
+	}
+	externalDbsOffset := flatbuffers.UOffsetT(0)
+	if t.ExternalDbs != nil {
+		externalDbsLength := len(t.ExternalDbs)
+		externalDbsOffsets := make([]flatbuffers.UOffsetT, externalDbsLength)
+		for j := 0; j < externalDbsLength; j++ {
+			externalDbsOffsets[j] = t.ExternalDbs[j].Pack(builder)
+		}
+		ManifestV1StartExternalDbsVector(builder, externalDbsLength)
+		for j := externalDbsLength - 1; j >= 0; j-- {
+			builder.PrependUOffsetT(externalDbsOffsets[j])
+		}
+		externalDbsOffset = builder.EndVector(externalDbsLength)
+	}
+	filterSidecarsOffset := flatbuffers.UOffsetT(0)
+	if t.FilterSidecars != nil {
+		filterSidecarsLength := len(t.FilterSidecars)
+		filterSidecarsOffsets := make([]flatbuffers.UOffsetT, filterSidecarsLength)
+		for j := 0; j < filterSidecarsLength; j++ {
+			filterSidecarsOffsets[j] = t.FilterSidecars[j].Pack(builder)
+		}
+		ManifestV1StartFilterSidecarsVector(builder, filterSidecarsLength)
+		for j := filterSidecarsLength - 1; j >= 0; j-- {
+			builder.PrependUOffsetT(filterSidecarsOffsets[j])
+		}
+		filterSidecarsOffset = builder.EndVector(filterSidecarsLength)
+	}
+	quarantinedOffset := flatbuffers.UOffsetT(0)
+	if t.Quarantined != nil {
+		quarantinedLength := len(t.Quarantined)
+		quarantinedOffsets := make([]flatbuffers.UOffsetT, quarantinedLength)
+		for j := 0; j < quarantinedLength; j++ {
+			quarantinedOffsets[j] = t.Quarantined[j].Pack(builder)
+		}
+		ManifestV1StartQuarantinedVector(builder, quarantinedLength)
+		for j := quarantinedLength - 1; j >= 0; j-- {
+			builder.PrependUOffsetT(quarantinedOffsets[j])
+		}
+		quarantinedOffset = builder.EndVector(quarantinedLength)
+	}
+	leasesOffset := flatbuffers.UOffsetT(0)
+	if t.Leases != nil {
+		leasesLength := len(t.Leases)
+		leasesOffsets := make([]flatbuffers.UOffsetT, leasesLength)
+		for j := 0; j < leasesLength; j++ {
+			leasesOffsets[j] = t.Leases[j].Pack(builder)
+		}
+		ManifestV1StartLeasesVector(builder, leasesLength)
+		for j := leasesLength - 1; j >= 0; j-- {
+			builder.PrependUOffsetT(leasesOffsets[j])
+		}
+		leasesOffset = builder.EndVector(leasesLength)
+	}
+	ManifestV1Start(builder)
+	ManifestV1AddManifestId(builder, t.ManifestId)
+	ManifestV1AddWriterEpoch(builder, t.WriterEpoch)
+	ManifestV1AddCompactorEpoch(builder, t.CompactorEpoch)
+	ManifestV1AddWalIdLastCompacted(builder, t.WalIdLastCompacted)
+	ManifestV1AddWalIdLastSeen(builder, t.WalIdLastSeen)
+	ManifestV1AddL0LastCompacted(builder, l0LastCompactedOffset)
+	ManifestV1AddL0(builder, l0Offset)
+	ManifestV1AddCompacted(builder, compactedOffset)
+	ManifestV1AddSnapshots(builder, snapshotsOffset)
+	ManifestV1AddFeatures(builder, t.Features)
+	ManifestV1AddLastSeq(builder, t.LastSeq)
+	ManifestV1AddExternalDbs(builder, externalDbsOffset)
+	ManifestV1AddFilterSidecars(builder, filterSidecarsOffset)
+	ManifestV1AddQuarantined(builder, quarantinedOffset)
+	ManifestV1AddLeases(builder, leasesOffset)
+	return ManifestV1End(builder)
+}
+
+func (rcv *ManifestV1) UnPackTo(t *ManifestV1T) {
+	t.ManifestId = rcv.ManifestId()
+	t.WriterEpoch = rcv.WriterEpoch()
+	t.CompactorEpoch = rcv.CompactorEpoch()
+	t.WalIdLastCompacted = rcv.WalIdLastCompacted()
+	t.WalIdLastSeen = rcv.WalIdLastSeen()
+	t.L0LastCompacted = rcv.L0LastCompacted(nil).UnPack()
+	l0Length := rcv.L0Length()
+	t.L0 = make([]*CompactedSsTableT, l0Length)
+	for j := 0; j < l0Length; j++ {
+		x := CompactedSsTable{}
+		rcv.L0(&x, j)
+		t.L0[j] = x.UnPack()
+	}
+	compactedLength := rcv.CompactedLength()
+	t.Compacted = make([]*SortedRunT, compactedLength)
+	for j := 0; j < compactedLength; j++ {
+		x := SortedRun{}
+		rcv.Compacted(&x, j)
+		t.Compacted[j] = x.UnPack()
+	}
+	snapshotsLength := rcv.SnapshotsLength()
+	t.Snapshots = make([]*SnapshotT, snapshotsLength)
+	for j := 0; j < snapshotsLength; j++ {
+		x := Snapshot{}
+		rcv.Snapshots(&x, j)
+		t.Snapshots[j] = x.UnPack()
+	}
+	t.Features = rcv.Features()
+	t.LastSeq = rcv.LastSeq()
+	externalDbsLength := rcv.ExternalDbsLength()
+	t.ExternalDbs = make([]*ExternalDbT, externalDbsLength)
+	for j := 0; j < externalDbsLength; j++ {
+		x := ExternalDb{}
+		rcv.ExternalDbs(&x, j)
+		t.ExternalDbs[j] = x.UnPack()
+	}
+	filterSidecarsLength := rcv.FilterSidecarsLength()
+	t.FilterSidecars = make([]*CompactedSstIdT, filterSidecarsLength)
+	for j := 0; j < filterSidecarsLength; j++ {
+		x := CompactedSstId{}
+		rcv.FilterSidecars(&x, j)
+		t.FilterSidecars[j] = x.UnPack()
+	}
+	quarantinedLength := rcv.QuarantinedLength()
+	t.Quarantined = make([]*QuarantinedSstT, quarantinedLength)
+	for j := 0; j < quarantinedLength; j++ {
+		x := QuarantinedSst{}
+		rcv.Quarantined(&x, j)
+		t.Quarantined[j] = x.UnPack()
+	}
+	leasesLength := rcv.LeasesLength()
+	t.Leases = make([]*SstLeaseT, leasesLength)
+	for j := 0; j < leasesLength; j++ {
+		x := SstLease{}
+		rcv.Leases(&x, j)
+		t.Leases[j] = x.UnPack()
+	}
+}
+
+func (rcv *ManifestV1) UnPack() *ManifestV1T {
+	if rcv == nil {
+		return nil
+	}
+	t := &ManifestV1T{}
+	rcv.UnPackTo(t)
+	return t
+}
+
+type ManifestV1 struct {
+	_tab flatbuffers.Table
+}
+
+func GetRootAsManifestV1(buf []byte, offset flatbuffers.UOffsetT) *ManifestV1 {
+	n := flatbuffers.GetUOffsetT(buf[offset:])
+	x := &ManifestV1{}
+	x.Init(buf, n+offset)
+	return x
+}
+
+func FinishManifestV1Buffer(builder *flatbuffers.Builder, offset flatbuffers.UOffsetT) {
+	builder.Finish(offset)
+}
+
+func GetSizePrefixedRootAsManifestV1(buf []byte, offset flatbuffers.UOffsetT) *ManifestV1 {
+	n := flatbuffers.GetUOffsetT(buf[offset+flatbuffers.SizeUint32:])
+	x := &ManifestV1{}
+	x.Init(buf, n+offset+flatbuffers.SizeUint32)
+	return x
+}
+
+func FinishSizePrefixedManifestV1Buffer(builder *flatbuffers.Builder, offset flatbuffers.UOffsetT) {
+	builder.FinishSizePrefixed(offset)
+}
+
+func (rcv *ManifestV1) Init(buf []byte, i flatbuffers.UOffsetT) {
+	rcv._tab.Bytes = buf
+	rcv._tab.Pos = i
+}
+
+func (rcv *ManifestV1) Table() flatbuffers.Table {
+	return rcv._tab
+}
+
+func (rcv *ManifestV1) ManifestId() uint64 {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(4))
+	if o != 0 {
+		return rcv._tab.GetUint64(o + rcv._tab.Pos)
+	}
+	return 0
+}
+
+func (rcv *ManifestV1) MutateManifestId(n uint64) bool {
+	return rcv._tab.MutateUint64Slot(4, n)
+}
+
+func (rcv *ManifestV1) WriterEpoch() uint64 {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(6))
+	if o != 0 {
+		return rcv._tab.GetUint64(o + rcv._tab.Pos)
+	}
+	return 0
+}
+
+func (rcv *ManifestV1) MutateWriterEpoch(n uint64) bool {
+	return rcv._tab.MutateUint64Slot(6, n)
+}
+
+func (rcv *ManifestV1) CompactorEpoch() uint64 {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(8))
+	if o != 0 {
+		return rcv._tab.GetUint64(o + rcv._tab.Pos)
+	}
+	return 0
+}
+
+func (rcv *ManifestV1) MutateCompactorEpoch(n uint64) bool {
+	return rcv._tab.MutateUint64Slot(8, n)
+}
+
+func (rcv *ManifestV1) WalIdLastCompacted() uint64 {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(10))
+	if o != 0 {
+		return rcv._tab.GetUint64(o + rcv._tab.Pos)
+	}
+	return 0
+}
+
+func (rcv *ManifestV1) MutateWalIdLastCompacted(n uint64) bool {
+	return rcv._tab.MutateUint64Slot(10, n)
+}
+
+func (rcv *ManifestV1) WalIdLastSeen() uint64 {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(12))
+	if o != 0 {
+		return rcv._tab.GetUint64(o + rcv._tab.Pos)
+	}
+	return 0
+}
+
+func (rcv *ManifestV1) MutateWalIdLastSeen(n uint64) bool {
 	return rcv._tab.MutateUint64Slot(12, n)
 }
 
-func (rcv *ManifestV1) L0LastCompacted(obj *CompactedSstId) *CompactedSstId {
-	o := flatbuffers.UOffsetT(rcv._tab.Offset(14))
+func (rcv *ManifestV1) L0LastCompacted(obj *CompactedSstId) *CompactedSstId {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(14))
+	if o != 0 {
+		x := rcv._tab.Indirect(o + rcv._tab.Pos)
+		if obj == nil {
+			obj = new(CompactedSstId)
+		}
+		obj.Init(rcv._tab.Bytes, x)
+		return obj
+	}
+	return nil
+}
+
+func (rcv *ManifestV1) L0(obj *CompactedSsTable, j int) bool {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(16))
+	if o != 0 {
+		x := rcv._tab.Vector(o)
+		x += flatbuffers.UOffsetT(j) * 4
+		x = rcv._tab.Indirect(x)
+		obj.Init(rcv._tab.Bytes, x)
+		return true
+	}
+	return false
+}
+
+func (rcv *ManifestV1) L0Length() int {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(16))
+	if o != 0 {
+		return rcv._tab.VectorLen(o)
+	}
+	return 0
+}
+
+func (rcv *ManifestV1) Compacted(obj *SortedRun, j int) bool {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(18))
+	if o != 0 {
+		x := rcv._tab.Vector(o)
+		x += flatbuffers.UOffsetT(j) * 4
+		x = rcv._tab.Indirect(x)
+		obj.Init(rcv._tab.Bytes, x)
+		return true
+	}
+	return false
+}
+
+func (rcv *ManifestV1) CompactedLength() int {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(18))
+	if o != 0 {
+		return rcv._tab.VectorLen(o)
+	}
+	return 0
+}
+
+func (rcv *ManifestV1) Snapshots(obj *Snapshot, j int) bool {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(20))
+	if o != 0 {
+		x := rcv._tab.Vector(o)
+		x += flatbuffers.UOffsetT(j) * 4
+		x = rcv._tab.Indirect(x)
+		obj.Init(rcv._tab.Bytes, x)
+		return true
+	}
+	return false
+}
+
+func (rcv *ManifestV1) SnapshotsLength() int {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(20))
+	if o != 0 {
+		return rcv._tab.VectorLen(o)
+	}
+	return 0
+}
+
+func (rcv *ManifestV1) Features() uint64 {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(22))
+	if o != 0 {
+		return rcv._tab.GetUint64(o + rcv._tab.Pos)
+	}
+	return 0
+}
+
+func (rcv *ManifestV1) MutateFeatures(n uint64) bool {
+	return rcv._tab.MutateUint64Slot(22, n)
+}
+
+func (rcv *ManifestV1) LastSeq() uint64 {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(24))
+	if o != 0 {
+		return rcv._tab.GetUint64(o + rcv._tab.Pos)
+	}
+	return 0
+}
+
+func (rcv *ManifestV1) MutateLastSeq(n uint64) bool {
+	return rcv._tab.MutateUint64Slot(24, n)
+}
+
+func (rcv *ManifestV1) ExternalDbs(obj *ExternalDb, j int) bool {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(26))
+	if o != 0 {
+		x := rcv._tab.Vector(o)
+		x += flatbuffers.UOffsetT(j) * 4
+		x = rcv._tab.Indirect(x)
+		obj.Init(rcv._tab.Bytes, x)
+		return true
+	}
+	return false
+}
+
+func (rcv *ManifestV1) ExternalDbsLength() int {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(26))
+	if o != 0 {
+		return rcv._tab.VectorLen(o)
+	}
+	return 0
+}
+
+func (rcv *ManifestV1) FilterSidecars(obj *CompactedSstId, j int) bool {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(28))
+	if o != 0 {
+		x := rcv._tab.Vector(o)
+		x += flatbuffers.UOffsetT(j) * 4
+		x = rcv._tab.Indirect(x)
+		obj.Init(rcv._tab.Bytes, x)
+		return true
+	}
+	return false
+}
+
+func (rcv *ManifestV1) FilterSidecarsLength() int {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(28))
+	if o != 0 {
+		return rcv._tab.VectorLen(o)
+	}
+	return 0
+}
+
+func (rcv *ManifestV1) Quarantined(obj *QuarantinedSst, j int) bool {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(30))
+	if o != 0 {
+		x := rcv._tab.Vector(o)
+		x += flatbuffers.UOffsetT(j) * 4
+		x = rcv._tab.Indirect(x)
+		obj.Init(rcv._tab.Bytes, x)
+		return true
+	}
+	return false
+}
+
+func (rcv *ManifestV1) QuarantinedLength() int {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(30))
+	if o != 0 {
+		return rcv._tab.VectorLen(o)
+	}
+	return 0
+}
+
+func (rcv *ManifestV1) Leases(obj *SstLease, j int) bool {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(32))
+	if o != 0 {
+		x := rcv._tab.Vector(o)
+		x += flatbuffers.UOffsetT(j) * 4
+		x = rcv._tab.Indirect(x)
+		obj.Init(rcv._tab.Bytes, x)
+		return true
+	}
+	return false
+}
+
+func (rcv *ManifestV1) LeasesLength() int {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(32))
+	if o != 0 {
+		return rcv._tab.VectorLen(o)
+	}
+	return 0
+}
+
+func ManifestV1Start(builder *flatbuffers.Builder) {
+	builder.StartObject(15)
+}
+func ManifestV1AddManifestId(builder *flatbuffers.Builder, manifestId uint64) {
+	builder.PrependUint64Slot(0, manifestId, 0)
+}
+func ManifestV1AddWriterEpoch(builder *flatbuffers.Builder, writerEpoch uint64) {
+	builder.PrependUint64Slot(1, writerEpoch, 0)
+}
+func ManifestV1AddCompactorEpoch(builder *flatbuffers.Builder, compactorEpoch uint64) {
+	builder.PrependUint64Slot(2, compactorEpoch, 0)
+}
+func ManifestV1AddWalIdLastCompacted(builder *flatbuffers.Builder, walIdLastCompacted uint64) {
+	builder.PrependUint64Slot(3, walIdLastCompacted, 0)
+}
+func ManifestV1AddWalIdLastSeen(builder *flatbuffers.Builder, walIdLastSeen uint64) {
+	builder.PrependUint64Slot(4, walIdLastSeen, 0)
+}
+func ManifestV1AddL0LastCompacted(builder *flatbuffers.Builder, l0LastCompacted flatbuffers.UOffsetT) {
+	builder.PrependUOffsetTSlot(5, flatbuffers.UOffsetT(l0LastCompacted), 0)
+}
+func ManifestV1AddL0(builder *flatbuffers.Builder, l0 flatbuffers.UOffsetT) {
+	builder.PrependUOffsetTSlot(6, flatbuffers.UOffsetT(l0), 0)
+}
+func ManifestV1StartL0Vector(builder *flatbuffers.Builder, numElems int) flatbuffers.UOffsetT {
+	return builder.StartVector(4, numElems, 4)
+}
+func ManifestV1AddCompacted(builder *flatbuffers.Builder, compacted flatbuffers.UOffsetT) {
+	builder.PrependUOffsetTSlot(7, flatbuffers.UOffsetT(compacted), 0)
+}
+func ManifestV1StartCompactedVector(builder *flatbuffers.Builder, numElems int) flatbuffers.UOffsetT {
+	return builder.StartVector(4, numElems, 4)
+}
+func ManifestV1AddSnapshots(builder *flatbuffers.Builder, snapshots flatbuffers.UOffsetT) {
+	builder.PrependUOffsetTSlot(8, flatbuffers.UOffsetT(snapshots), 0)
+}
+func ManifestV1StartSnapshotsVector(builder *flatbuffers.Builder, numElems int) flatbuffers.UOffsetT {
+	return builder.StartVector(4, numElems, 4)
+}
+func ManifestV1AddFeatures(builder *flatbuffers.Builder, features uint64) {
+	builder.PrependUint64Slot(9, features, 0)
+}
+func ManifestV1AddLastSeq(builder *flatbuffers.Builder, lastSeq uint64) {
+	builder.PrependUint64Slot(10, lastSeq, 0)
+}
+func ManifestV1AddExternalDbs(builder *flatbuffers.Builder, externalDbs flatbuffers.UOffsetT) {
+	builder.PrependUOffsetTSlot(11, flatbuffers.UOffsetT(externalDbs), 0)
+}
+func ManifestV1StartExternalDbsVector(builder *flatbuffers.Builder, numElems int) flatbuffers.UOffsetT {
+	return builder.StartVector(4, numElems, 4)
+}
+func ManifestV1AddFilterSidecars(builder *flatbuffers.Builder, filterSidecars flatbuffers.UOffsetT) {
+	builder.PrependUOffsetTSlot(12, flatbuffers.UOffsetT(filterSidecars), 0)
+}
+func ManifestV1StartFilterSidecarsVector(builder *flatbuffers.Builder, numElems int) flatbuffers.UOffsetT {
+	return builder.StartVector(4, numElems, 4)
+}
+func ManifestV1AddQuarantined(builder *flatbuffers.Builder, quarantined flatbuffers.UOffsetT) {
+	builder.PrependUOffsetTSlot(13, flatbuffers.UOffsetT(quarantined), 0)
+}
+func ManifestV1StartQuarantinedVector(builder *flatbuffers.Builder, numElems int) flatbuffers.UOffsetT {
+	return builder.StartVector(4, numElems, 4)
+}
+func ManifestV1AddLeases(builder *flatbuffers.Builder, leases flatbuffers.UOffsetT) {
+	builder.PrependUOffsetTSlot(14, flatbuffers.UOffsetT(leases), 0)
+}
+func ManifestV1StartLeasesVector(builder *flatbuffers.Builder, numElems int) flatbuffers.UOffsetT {
+	return builder.StartVector(4, numElems, 4)
+}
+func ManifestV1End(builder *flatbuffers.Builder) flatbuffers.UOffsetT {
+	return builder.EndObject()
+}
+
+type SstLeaseT struct {
+	Id           *CompactedSstIdT   `json:"id"`
+	SstIds       []*CompactedSstIdT `json:"sst_ids"`
+	ExpireTimeMs uint64             `json:"expire_time_ms"`
+}
+
+func (t *SstLeaseT) Pack(builder *flatbuffers.Builder) flatbuffers.UOffsetT {
+	if t == nil {
+		return 0
+	}
+	idOffset := t.Id.Pack(builder)
+	sstIdsOffset := flatbuffers.UOffsetT(0)
+	if t.SstIds != nil {
+		sstIdsLength := len(t.SstIds)
+		sstIdsOffsets := make([]flatbuffers.UOffsetT, sstIdsLength)
+		for j := 0; j < sstIdsLength; j++ {
+			sstIdsOffsets[j] = t.SstIds[j].Pack(builder)
+		}
+		SstLeaseStartSstIdsVector(builder, sstIdsLength)
+		for j := sstIdsLength - 1; j >= 0; j-- {
+			builder.PrependUOffsetT(sstIdsOffsets[j])
+		}
+		sstIdsOffset = builder.EndVector(sstIdsLength)
+	}
+	SstLeaseStart(builder)
+	SstLeaseAddId(builder, idOffset)
+	SstLeaseAddSstIds(builder, sstIdsOffset)
+	SstLeaseAddExpireTimeMs(builder, t.ExpireTimeMs)
+	return SstLeaseEnd(builder)
+}
+
+func (rcv *SstLease) UnPackTo(t *SstLeaseT) {
+	t.Id = rcv.Id(nil).UnPack()
+	sstIdsLength := rcv.SstIdsLength()
+	t.SstIds = make([]*CompactedSstIdT, sstIdsLength)
+	for j := 0; j < sstIdsLength; j++ {
+		x := CompactedSstId{}
+		rcv.SstIds(&x, j)
+		t.SstIds[j] = x.UnPack()
+	}
+	t.ExpireTimeMs = rcv.ExpireTimeMs()
+}
+
+func (rcv *SstLease) UnPack() *SstLeaseT {
+	if rcv == nil {
+		return nil
+	}
+	t := &SstLeaseT{}
+	rcv.UnPackTo(t)
+	return t
+}
+
+type SstLease struct {
+	_tab flatbuffers.Table
+}
+
+func GetRootAsSstLease(buf []byte, offset flatbuffers.UOffsetT) *SstLease {
+	n := flatbuffers.GetUOffsetT(buf[offset:])
+	x := &SstLease{}
+	x.Init(buf, n+offset)
+	return x
+}
+
+func FinishSstLeaseBuffer(builder *flatbuffers.Builder, offset flatbuffers.UOffsetT) {
+	builder.Finish(offset)
+}
+
+func GetSizePrefixedRootAsSstLease(buf []byte, offset flatbuffers.UOffsetT) *SstLease {
+	n := flatbuffers.GetUOffsetT(buf[offset+flatbuffers.SizeUint32:])
+	x := &SstLease{}
+	x.Init(buf, n+offset+flatbuffers.SizeUint32)
+	return x
+}
+
+func FinishSizePrefixedSstLeaseBuffer(builder *flatbuffers.Builder, offset flatbuffers.UOffsetT) {
+	builder.FinishSizePrefixed(offset)
+}
+
+func (rcv *SstLease) Init(buf []byte, i flatbuffers.UOffsetT) {
+	rcv._tab.Bytes = buf
+	rcv._tab.Pos = i
+}
+
+func (rcv *SstLease) Table() flatbuffers.Table {
+	return rcv._tab
+}
+
+func (rcv *SstLease) Id(obj *CompactedSstId) *CompactedSstId {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(4))
 	if o != 0 {
 		x := rcv._tab.Indirect(o + rcv._tab.Pos)
 		if obj == nil {
@@ -908,8 +2034,8 @@ func (rcv *ManifestV1) L0LastCompacted(obj *CompactedSstId) *CompactedSstId {
 	return nil
 }
 
-func (rcv *ManifestV1) L0(obj *CompactedSsTable, j int) bool {
-	o := flatbuffers.UOffsetT(rcv._tab.Offset(16))
+func (rcv *SstLease) SstIds(obj *CompactedSstId, j int) bool {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(6))
 	if o != 0 {
 		x := rcv._tab.Vector(o)
 		x += flatbuffers.UOffsetT(j) * 4
@@ -920,36 +2046,157 @@ func (rcv *ManifestV1) L0(obj *CompactedSsTable, j int) bool {
 	return false
 }
 
-func (rcv *ManifestV1) L0Length() int {
-	o := flatbuffers.UOffsetT(rcv._tab.Offset(16))
+func (rcv *SstLease) SstIdsLength() int {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(6))
 	if o != 0 {
 		return rcv._tab.VectorLen(o)
 	}
 	return 0
 }
 
-func (rcv *ManifestV1) Compacted(obj *SortedRun, j int) bool {
-	o := flatbuffers.UOffsetT(rcv._tab.Offset(18))
+func (rcv *SstLease) ExpireTimeMs() uint64 {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(8))
 	if o != 0 {
-		x := rcv._tab.Vector(o)
-		x += flatbuffers.UOffsetT(j) * 4
-		x = rcv._tab.Indirect(x)
-		obj.Init(rcv._tab.Bytes, x)
-		return true
+		return rcv._tab.GetUint64(o + rcv._tab.Pos)
 	}
-	return false
+	return 0
 }
 
-func (rcv *ManifestV1) CompactedLength() int {
-	o := flatbuffers.UOffsetT(rcv._tab.Offset(18))
+func (rcv *SstLease) MutateExpireTimeMs(n uint64) bool {
+	return rcv._tab.MutateUint64Slot(8, n)
+}
+
+func SstLeaseStart(builder *flatbuffers.Builder) {
+	builder.StartObject(3)
+}
+func SstLeaseAddId(builder *flatbuffers.Builder, id flatbuffers.UOffsetT) {
+	builder.PrependUOffsetTSlot(0, flatbuffers.UOffsetT(id), 0)
+}
+func SstLeaseAddSstIds(builder *flatbuffers.Builder, sstIds flatbuffers.UOffsetT) {
+	builder.PrependUOffsetTSlot(1, flatbuffers.UOffsetT(sstIds), 0)
+}
+func SstLeaseStartSstIdsVector(builder *flatbuffers.Builder, numElems int) flatbuffers.UOffsetT {
+	return builder.StartVector(4, numElems, 4)
+}
+func SstLeaseAddExpireTimeMs(builder *flatbuffers.Builder, expireTimeMs uint64) {
+	builder.PrependUint64Slot(2, expireTimeMs, 0)
+}
+func SstLeaseEnd(builder *flatbuffers.Builder) flatbuffers.UOffsetT {
+	return builder.EndObject()
+}
+
+type ExternalDbT struct {
+	Path         string             `json:"path"`
+	CheckpointId uint64             `json:"checkpoint_id"`
+	SstIds       []*CompactedSstIdT `json:"sst_ids"`
+}
+
+func (t *ExternalDbT) Pack(builder *flatbuffers.Builder) flatbuffers.UOffsetT {
+	if t == nil {
+		return 0
+	}
+	pathOffset := flatbuffers.UOffsetT(0)
+	if t.Path != "" {
+		pathOffset = builder.CreateString(t.Path)
+	}
+	sstIdsOffset := flatbuffers.UOffsetT(0)
+	if t.SstIds != nil {
+		sstIdsLength := len(t.SstIds)
+		sstIdsOffsets := make([]flatbuffers.UOffsetT, sstIdsLength)
+		for j := 0; j < sstIdsLength; j++ {
+			sstIdsOffsets[j] = t.SstIds[j].Pack(builder)
+		}
+		ExternalDbStartSstIdsVector(builder, sstIdsLength)
+		for j := sstIdsLength - 1; j >= 0; j-- {
+			builder.PrependUOffsetT(sstIdsOffsets[j])
+		}
+		sstIdsOffset = builder.EndVector(sstIdsLength)
+	}
+	ExternalDbStart(builder)
+	ExternalDbAddPath(builder, pathOffset)
+	ExternalDbAddCheckpointId(builder, t.CheckpointId)
+	ExternalDbAddSstIds(builder, sstIdsOffset)
+	return ExternalDbEnd(builder)
+}
+
+func (rcv *ExternalDb) UnPackTo(t *ExternalDbT) {
+	t.Path = string(rcv.Path())
+	t.CheckpointId = rcv.CheckpointId()
+	sstIdsLength := rcv.SstIdsLength()
+	t.SstIds = make([]*CompactedSstIdT, sstIdsLength)
+	for j := 0; j < sstIdsLength; j++ {
+		x := CompactedSstId{}
+		rcv.SstIds(&x, j)
+		t.SstIds[j] = x.UnPack()
+	}
+}
+
+func (rcv *ExternalDb) UnPack() *ExternalDbT {
+	if rcv == nil {
+		return nil
+	}
+	t := &ExternalDbT{}
+	rcv.UnPackTo(t)
+	return t
+}
+
+type ExternalDb struct {
+	_tab flatbuffers.Table
+}
+
+func GetRootAsExternalDb(buf []byte, offset flatbuffers.UOffsetT) *ExternalDb {
+	n := flatbuffers.GetUOffsetT(buf[offset:])
+	x := &ExternalDb{}
+	x.Init(buf, n+offset)
+	return x
+}
+
+func FinishExternalDbBuffer(builder *flatbuffers.Builder, offset flatbuffers.UOffsetT) {
+	builder.Finish(offset)
+}
+
+func GetSizePrefixedRootAsExternalDb(buf []byte, offset flatbuffers.UOffsetT) *ExternalDb {
+	n := flatbuffers.GetUOffsetT(buf[offset+flatbuffers.SizeUint32:])
+	x := &ExternalDb{}
+	x.Init(buf, n+offset+flatbuffers.SizeUint32)
+	return x
+}
+
+func FinishSizePrefixedExternalDbBuffer(builder *flatbuffers.Builder, offset flatbuffers.UOffsetT) {
+	builder.FinishSizePrefixed(offset)
+}
+
+func (rcv *ExternalDb) Init(buf []byte, i flatbuffers.UOffsetT) {
+	rcv._tab.Bytes = buf
+	rcv._tab.Pos = i
+}
+
+func (rcv *ExternalDb) Table() flatbuffers.Table {
+	return rcv._tab
+}
+
+func (rcv *ExternalDb) Path() []byte {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(4))
 	if o != 0 {
-		return rcv._tab.VectorLen(o)
+		return rcv._tab.ByteVector(o + rcv._tab.Pos)
+	}
+	return nil
+}
+
+func (rcv *ExternalDb) CheckpointId() uint64 {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(6))
+	if o != 0 {
+		return rcv._tab.GetUint64(o + rcv._tab.Pos)
 	}
 	return 0
 }
 
-func (rcv *ManifestV1) Snapshots(obj *Snapshot, j int) bool {
-	o := flatbuffers.UOffsetT(rcv._tab.Offset(20))
+func (rcv *ExternalDb) MutateCheckpointId(n uint64) bool {
+	return rcv._tab.MutateUint64Slot(6, n)
+}
+
+func (rcv *ExternalDb) SstIds(obj *CompactedSstId, j int) bool {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(8))
 	if o != 0 {
 		x := rcv._tab.Vector(o)
 		x += flatbuffers.UOffsetT(j) * 4
@@ -960,54 +2207,169 @@ func (rcv *ManifestV1) Snapshots(obj *Snapshot, j int) bool {
 	return false
 }
 
-func (rcv *ManifestV1) SnapshotsLength() int {
-	o := flatbuffers.UOffsetT(rcv._tab.Offset(20))
+func (rcv *ExternalDb) SstIdsLength() int {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(8))
 	if o != 0 {
 		return rcv._tab.VectorLen(o)
 	}
 	return 0
 }
 
-func ManifestV1Start(builder *flatbuffers.Builder) {
-	builder.StartObject(9)
+func ExternalDbStart(builder *flatbuffers.Builder) {
+	builder.StartObject(3)
 }
-func ManifestV1AddManifestId(builder *flatbuffers.Builder, manifestId uint64) {
-	builder.PrependUint64Slot(0, manifestId, 0)
+func ExternalDbAddPath(builder *flatbuffers.Builder, path flatbuffers.UOffsetT) {
+	builder.PrependUOffsetTSlot(0, flatbuffers.UOffsetT(path), 0)
 }
-func ManifestV1AddWriterEpoch(builder *flatbuffers.Builder, writerEpoch uint64) {
-	builder.PrependUint64Slot(1, writerEpoch, 0)
+func ExternalDbAddCheckpointId(builder *flatbuffers.Builder, checkpointId uint64) {
+	builder.PrependUint64Slot(1, checkpointId, 0)
 }
-func ManifestV1AddCompactorEpoch(builder *flatbuffers.Builder, compactorEpoch uint64) {
-	builder.PrependUint64Slot(2, compactorEpoch, 0)
+func ExternalDbAddSstIds(builder *flatbuffers.Builder, sstIds flatbuffers.UOffsetT) {
+	builder.PrependUOffsetTSlot(2, flatbuffers.UOffsetT(sstIds), 0)
 }
-func ManifestV1AddWalIdLastCompacted(builder *flatbuffers.Builder, walIdLastCompacted uint64) {
-	builder.PrependUint64Slot(3, walIdLastCompacted, 0)
+func ExternalDbStartSstIdsVector(builder *flatbuffers.Builder, numElems int) flatbuffers.UOffsetT {
+	return builder.StartVector(4, numElems, 4)
 }
-func ManifestV1AddWalIdLastSeen(builder *flatbuffers.Builder, walIdLastSeen uint64) {
-	builder.PrependUint64Slot(4, walIdLastSeen, 0)
+func ExternalDbEnd(builder *flatbuffers.Builder) flatbuffers.UOffsetT {
+	return builder.EndObject()
 }
-func ManifestV1AddL0LastCompacted(builder *flatbuffers.Builder, l0LastCompacted flatbuffers.UOffsetT) {
-	builder.PrependUOffsetTSlot(5, flatbuffers.UOffsetT(l0LastCompacted), 0)
+
+type QuarantinedSstT struct {
+	SstId           *CompactedSstIdT `json:"sst_id"`
+	WalId           uint64           `json:"wal_id"`
+	Reason          string           `json:"reason"`
+	QuarantineTimeS uint32           `json:"quarantine_time_s"`
 }
-func ManifestV1AddL0(builder *flatbuffers.Builder, l0 flatbuffers.UOffsetT) {
-	builder.PrependUOffsetTSlot(6, flatbuffers.UOffsetT(l0), 0)
+
+func (t *QuarantinedSstT) Pack(builder *flatbuffers.Builder) flatbuffers.UOffsetT {
+	if t == nil {
+		return 0
+	}
+	sstIdOffset := t.SstId.Pack(builder)
+	reasonOffset := flatbuffers.UOffsetT(0)
+	if t.Reason != "" {
+		reasonOffset = builder.CreateString(t.Reason)
+	}
+	QuarantinedSstStart(builder)
+	QuarantinedSstAddSstId(builder, sstIdOffset)
+	QuarantinedSstAddWalId(builder, t.WalId)
+	QuarantinedSstAddReason(builder, reasonOffset)
+	QuarantinedSstAddQuarantineTimeS(builder, t.QuarantineTimeS)
+	return QuarantinedSstEnd(builder)
 }
-func ManifestV1StartL0Vector(builder *flatbuffers.Builder, numElems int) flatbuffers.UOffsetT {
-	return builder.StartVector(4, numElems, 4)
+
+func (rcv *QuarantinedSst) UnPackTo(t *QuarantinedSstT) {
+	t.SstId = rcv.SstId(nil).UnPack()
+	t.WalId = rcv.WalId()
+	t.Reason = string(rcv.Reason())
+	t.QuarantineTimeS = rcv.QuarantineTimeS()
 }
-func ManifestV1AddCompacted(builder *flatbuffers.Builder, compacted flatbuffers.UOffsetT) {
-	builder.PrependUOffsetTSlot(7, flatbuffers.UOffsetT(compacted), 0)
+
+func (rcv *QuarantinedSst) UnPack() *QuarantinedSstT {
+	if rcv == nil {
+		return nil
+	}
+	t := &QuarantinedSstT{}
+	rcv.UnPackTo(t)
+	return t
 }
-func ManifestV1StartCompactedVector(builder *flatbuffers.Builder, numElems int) flatbuffers.UOffsetT {
-	return builder.StartVector(4, numElems, 4)
+
+type QuarantinedSst struct {
+	_tab flatbuffers.Table
 }
-func ManifestV1AddSnapshots(builder *flatbuffers.Builder, snapshots flatbuffers.UOffsetT) {
-	builder.PrependUOffsetTSlot(8, flatbuffers.UOffsetT(snapshots), 0)
+
+func GetRootAsQuarantinedSst(buf []byte, offset flatbuffers.UOffsetT) *QuarantinedSst {
+	n := flatbuffers.GetUOffsetT(buf[offset:])
+	x := &QuarantinedSst{}
+	x.Init(buf, n+offset)
+	return x
 }
-func ManifestV1StartSnapshotsVector(builder *flatbuffers.Builder, numElems int) flatbuffers.UOffsetT {
-	return builder.StartVector(4, numElems, 4)
+
+func FinishQuarantinedSstBuffer(builder *flatbuffers.Builder, offset flatbuffers.UOffsetT) {
+	builder.Finish(offset)
 }
-func ManifestV1End(builder *flatbuffers.Builder) flatbuffers.UOffsetT {
+
+func GetSizePrefixedRootAsQuarantinedSst(buf []byte, offset flatbuffers.UOffsetT) *QuarantinedSst {
+	n := flatbuffers.GetUOffsetT(buf[offset+flatbuffers.SizeUint32:])
+	x := &QuarantinedSst{}
+	x.Init(buf, n+offset+flatbuffers.SizeUint32)
+	return x
+}
+
+func FinishSizePrefixedQuarantinedSstBuffer(builder *flatbuffers.Builder, offset flatbuffers.UOffsetT) {
+	builder.FinishSizePrefixed(offset)
+}
+
+func (rcv *QuarantinedSst) Init(buf []byte, i flatbuffers.UOffsetT) {
+	rcv._tab.Bytes = buf
+	rcv._tab.Pos = i
+}
+
+func (rcv *QuarantinedSst) Table() flatbuffers.Table {
+	return rcv._tab
+}
+
+func (rcv *QuarantinedSst) SstId(obj *CompactedSstId) *CompactedSstId {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(4))
+	if o != 0 {
+		x := rcv._tab.Indirect(o + rcv._tab.Pos)
+		if obj == nil {
+			obj = new(CompactedSstId)
+		}
+		obj.Init(rcv._tab.Bytes, x)
+		return obj
+	}
+	return nil
+}
+
+func (rcv *QuarantinedSst) WalId() uint64 {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(6))
+	if o != 0 {
+		return rcv._tab.GetUint64(o + rcv._tab.Pos)
+	}
+	return 0
+}
+
+func (rcv *QuarantinedSst) MutateWalId(n uint64) bool {
+	return rcv._tab.MutateUint64Slot(6, n)
+}
+
+func (rcv *QuarantinedSst) Reason() []byte {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(8))
+	if o != 0 {
+		return rcv._tab.ByteVector(o + rcv._tab.Pos)
+	}
+	return nil
+}
+
+func (rcv *QuarantinedSst) QuarantineTimeS() uint32 {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(10))
+	if o != 0 {
+		return rcv._tab.GetUint32(o + rcv._tab.Pos)
+	}
+	return 0
+}
+
+func (rcv *QuarantinedSst) MutateQuarantineTimeS(n uint32) bool {
+	return rcv._tab.MutateUint32Slot(10, n)
+}
+
+func QuarantinedSstStart(builder *flatbuffers.Builder) {
+	builder.StartObject(4)
+}
+func QuarantinedSstAddSstId(builder *flatbuffers.Builder, sstId flatbuffers.UOffsetT) {
+	builder.PrependUOffsetTSlot(0, flatbuffers.UOffsetT(sstId), 0)
+}
+func QuarantinedSstAddWalId(builder *flatbuffers.Builder, walId uint64) {
+	builder.PrependUint64Slot(1, walId, 0)
+}
+func QuarantinedSstAddReason(builder *flatbuffers.Builder, reason flatbuffers.UOffsetT) {
+	builder.PrependUOffsetTSlot(2, flatbuffers.UOffsetT(reason), 0)
+}
+func QuarantinedSstAddQuarantineTimeS(builder *flatbuffers.Builder, quarantineTimeS uint32) {
+	builder.PrependUint32Slot(3, quarantineTimeS, 0)
+}
+func QuarantinedSstEnd(builder *flatbuffers.Builder) flatbuffers.UOffsetT {
 	return builder.EndObject()
 }
 
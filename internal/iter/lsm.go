@@ -0,0 +1,76 @@
+package iter
+
+import (
+	"cmp"
+	"context"
+	"slices"
+)
+
+// Layer identifies the part of the LSM tree an iterator reads from. Layers are
+// declared from the most to the least recent, such that entries from a layer
+// declared first shadow the entries of the same key in layers declared after it.
+type Layer int
+
+const (
+	// LayerWAL is the current WAL, which holds writes not yet flushed to object storage
+	LayerWAL Layer = iota + 1
+	// LayerImmWAL is a WAL which has been frozen but not yet applied to the memtable
+	LayerImmWAL
+	// LayerMemtable is the current memtable
+	LayerMemtable
+	// LayerImmMemtable is a memtable which has been frozen but not yet flushed to L0
+	LayerImmMemtable
+	// LayerL0 is an SST in L0
+	LayerL0
+	// LayerSortedRun is a compacted sorted run
+	LayerSortedRun
+)
+
+// Source is an iterator along with its position in the LSM tree
+type Source struct {
+	Layer Layer
+	// Age orders sources within the same Layer, where lower values are more recent.
+	// For example, the index of an L0 SST in the newest first list of L0 SSTs.
+	Age  int
+	Iter KVIterator
+}
+
+// NewLSMMerge merges the sources such that when a key is present in more than one
+// source, only the entry from the most recent source is returned. Sources are ordered
+// by Layer and then by Age, regardless of the order in which they are provided, giving
+// the precedence memtable > immutable memtables > L0 (newest first) > sorted runs
+// (newest first). Sources with a nil Iter are ignored.
+//
+// Reads and compactions must both merge with NewLSMMerge, such that they agree on
+// which entry of a key is the most recent.
+func NewLSMMerge(ctx context.Context, sources ...Source) *MergeSort {
+	return NewMergeSort(ctx, sortSources(sources)...)
+}
+
+// NewReverseLSMMerge is like NewLSMMerge, but merges sources which each return keys in
+// descending order, such that the merged keys are also in descending order
+func NewReverseLSMMerge(ctx context.Context, sources ...Source) *MergeSort {
+	return NewReverseMergeSort(ctx, sortSources(sources)...)
+}
+
+// sortSources returns the iterators of the sources from the most to the least recent
+func sortSources(sources []Source) []KVIterator {
+	sorted := make([]Source, 0, len(sources))
+	for _, s := range sources {
+		if s.Iter != nil {
+			sorted = append(sorted, s)
+		}
+	}
+	slices.SortStableFunc(sorted, func(a, b Source) int {
+		if c := cmp.Compare(a.Layer, b.Layer); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Age, b.Age)
+	})
+
+	iters := make([]KVIterator, 0, len(sorted))
+	for _, s := range sorted {
+		iters = append(iters, s.Iter)
+	}
+	return iters
+}
@@ -0,0 +1,124 @@
+package iter_test
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	assert2 "github.com/slatedb/slatedb-go/internal/assert"
+	"github.com/slatedb/slatedb-go/internal/iter"
+	"github.com/slatedb/slatedb-go/internal/types"
+)
+
+func TestLSMMergePrecedence(t *testing.T) {
+	// sources are provided out of order, the most recent source must still win
+	mergeIter := iter.NewLSMMerge(context.Background(),
+		iter.Source{Layer: iter.LayerSortedRun, Age: 0, Iter: iter.NewEntryIterator().
+			Add([]byte("aaaa"), []byte("sr"))},
+		iter.Source{Layer: iter.LayerL0, Age: 1, Iter: iter.NewEntryIterator().
+			Add([]byte("aaaa"), []byte("old l0")).
+			Add([]byte("bbbb"), []byte("old l0"))},
+		iter.Source{Layer: iter.LayerL0, Age: 0, Iter: iter.NewEntryIterator().
+			Add([]byte("bbbb"), []byte("new l0"))},
+		iter.Source{Layer: iter.LayerL0, Age: 2, Iter: nil},
+		iter.Source{Layer: iter.LayerMemtable, Iter: iter.NewEntryIterator().
+			Add([]byte("cccc"), []byte("memtable"))},
+		iter.Source{Layer: iter.LayerWAL, Iter: iter.NewEntryIterator().
+			Add([]byte("cccc"), []byte("wal"))},
+	)
+	assert2.NextEntry(t, mergeIter, []byte("aaaa"), []byte("old l0"))
+	assert2.NextEntry(t, mergeIter, []byte("bbbb"), []byte("new l0"))
+	assert2.NextEntry(t, mergeIter, []byte("cccc"), []byte("wal"))
+
+	_, ok := mergeIter.NextEntry(context.Background())
+	assert.False(t, ok, "Expected no more entries")
+}
+
+// TestLSMMergeProperty generates random LSM trees and checks the forward and reverse merges
+// against a model which applies the writes of each source from the oldest to the most recent.
+func TestLSMMergeProperty(t *testing.T) {
+	layers := []iter.Layer{iter.LayerWAL, iter.LayerImmWAL, iter.LayerMemtable,
+		iter.LayerImmMemtable, iter.LayerL0, iter.LayerSortedRun}
+	rng := rand.New(rand.NewSource(1))
+
+	for round := 0; round < 200; round++ {
+		type source struct {
+			layer   iter.Layer
+			age     int
+			entries []types.RowEntry
+		}
+		var sources []source
+		for _, layer := range layers {
+			count := rng.Intn(4)
+			for age := 0; age < count; age++ {
+				// each source holds a sorted subset of a small keyspace, such that keys collide
+				var entries []types.RowEntry
+				for k := 0; k < 20; k++ {
+					if rng.Intn(3) != 0 {
+						continue
+					}
+					entry := types.RowEntry{Key: []byte(fmt.Sprintf("key%02d", k))}
+					if rng.Intn(5) == 0 {
+						entry.Value = types.Value{Kind: types.KindTombStone}
+					} else {
+						entry.Value = types.Value{Value: []byte(fmt.Sprintf("%d/%d/%d", layer, age, k))}
+					}
+					entries = append(entries, entry)
+				}
+				sources = append(sources, source{layer: layer, age: age, entries: entries})
+			}
+		}
+
+		// the model applies sources from the oldest to the most recent
+		model := make(map[string]types.Value)
+		for i := len(sources) - 1; i >= 0; i-- {
+			for _, e := range sources[i].entries {
+				model[string(e.Key)] = e.Value
+			}
+		}
+
+		rng.Shuffle(len(sources), func(i, j int) { sources[i], sources[j] = sources[j], sources[i] })
+		input := make([]iter.Source, 0, len(sources))
+		for _, s := range sources {
+			input = append(input, iter.Source{Layer: s.layer, Age: s.age, Iter: iter.NewEntryIterator(s.entries...)})
+		}
+
+		var keys []string
+		mergeIter := iter.NewLSMMerge(context.Background(), input...)
+		for {
+			entry, ok := mergeIter.NextEntry(context.Background())
+			if !ok {
+				break
+			}
+			keys = append(keys, string(entry.Key))
+			require.Equal(t, model[string(entry.Key)], entry.Value, "round %d key %s", round, entry.Key)
+		}
+		require.Len(t, keys, len(model), "round %d", round)
+		require.True(t, slices.IsSorted(keys), "round %d", round)
+
+		// the reverse merge of the same sources returns the same entries in descending order
+		input = input[:0]
+		for _, s := range sources {
+			reversed := slices.Clone(s.entries)
+			slices.Reverse(reversed)
+			input = append(input, iter.Source{Layer: s.layer, Age: s.age, Iter: iter.NewEntryIterator(reversed...)})
+		}
+		var reverseKeys []string
+		mergeIter = iter.NewReverseLSMMerge(context.Background(), input...)
+		for {
+			entry, ok := mergeIter.NextEntry(context.Background())
+			if !ok {
+				break
+			}
+			reverseKeys = append(reverseKeys, string(entry.Key))
+			require.Equal(t, model[string(entry.Key)], entry.Value, "round %d key %s", round, entry.Key)
+		}
+		slices.Reverse(reverseKeys)
+		require.Equal(t, keys, reverseKeys, "round %d", round)
+	}
+}
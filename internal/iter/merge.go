@@ -11,7 +11,7 @@ import (
 
 type MergeSort struct {
 	iterators []KVIterator
-	heap      minHeap
+	heap      mergeHeap
 	lastKey   []byte
 	warn      types.ErrWarn
 }
@@ -28,9 +28,23 @@ type MergeSort struct {
 // and an iterator in the list at index 1 which also has key 'a'
 // the key value from the iterator at index 0 will be used.
 func NewMergeSort(ctx context.Context, iterators ...KVIterator) *MergeSort {
+	return newMergeSort(ctx, false, iterators...)
+}
+
+// NewReverseMergeSort is like NewMergeSort, but merges iterators which each return
+// keys in descending order, such that the merged keys are also in descending order.
+// Precedence for duplicate keys is the same as NewMergeSort.
+func NewReverseMergeSort(ctx context.Context, iterators ...KVIterator) *MergeSort {
+	return newMergeSort(ctx, true, iterators...)
+}
+
+func newMergeSort(ctx context.Context, reverse bool, iterators ...KVIterator) *MergeSort {
 	ms := &MergeSort{
 		iterators: iterators,
-		heap:      make(minHeap, 0, len(iterators)),
+		heap: mergeHeap{
+			items:   make([]heapItem, 0, len(iterators)),
+			reverse: reverse,
+		},
 	}
 
 	// Initialize the heap with the first element from each iterator
@@ -84,28 +98,36 @@ type heapItem struct {
 	index int
 }
 
-type minHeap []heapItem
+// mergeHeap orders items by ascending key, or by descending key if reverse
+// is true. Items with the same key are ordered by the index of their iterator.
+type mergeHeap struct {
+	items   []heapItem
+	reverse bool
+}
 
-func (e heapItem) Compare(other heapItem) int {
-	cmpValue := bytes.Compare(e.kv.Key, other.kv.Key)
+func (h *mergeHeap) compare(a, b heapItem) int {
+	cmpValue := bytes.Compare(a.kv.Key, b.kv.Key)
 	if cmpValue == 0 {
-		return cmp.Compare(e.index, other.index)
+		return cmp.Compare(a.index, b.index)
+	}
+	if h.reverse {
+		return -cmpValue
 	}
 	return cmpValue
 }
 
-func (h minHeap) Len() int           { return len(h) }
-func (h minHeap) Less(i, j int) bool { return h[i].Compare(h[j]) < 0 }
-func (h minHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *mergeHeap) Len() int           { return len(h.items) }
+func (h *mergeHeap) Less(i, j int) bool { return h.compare(h.items[i], h.items[j]) < 0 }
+func (h *mergeHeap) Swap(i, j int)      { h.items[i], h.items[j] = h.items[j], h.items[i] }
 
-func (h *minHeap) Push(x interface{}) {
-	*h = append(*h, x.(heapItem))
+func (h *mergeHeap) Push(x interface{}) {
+	h.items = append(h.items, x.(heapItem))
 }
 
-func (h *minHeap) Pop() interface{} {
-	old := *h
+func (h *mergeHeap) Pop() interface{} {
+	old := h.items
 	n := len(old)
 	x := old[n-1]
-	*h = old[0 : n-1]
+	h.items = old[0 : n-1]
 	return x
 }
@@ -0,0 +1,178 @@
+// Package profile attributes the time spent by the write path work of the database, such as
+// WAL flushes, memtable flushes and compactions, to the stages of that work. The time spent
+// in each stage is recorded, and the goroutine doing the work may optionally be labeled with
+// the work and stage such that the samples of a CPU profile are attributed to them.
+//
+// The labels are "slatedb_work", which is one of "wal_flush", "memtable_flush" or "compaction",
+// and "slatedb_stage", which is one of "read", "encode", "compress", "upload" or "manifest".
+// For example, the compression of compaction output can be selected from a CPU profile with
+//
+//	go tool pprof -tagfocus=slatedb_stage=compress,slatedb_work=compaction
+package profile
+
+import (
+	"context"
+	"runtime/pprof"
+	"sync/atomic"
+	"time"
+)
+
+// Work is a kind of write path work
+type Work int
+
+const (
+	WorkWALFlush Work = iota
+	WorkMemtableFlush
+	WorkCompaction
+	numWork
+)
+
+// String returns the name of the work used as the value of the "slatedb_work" label
+func (w Work) String() string {
+	switch w {
+	case WorkWALFlush:
+		return "wal_flush"
+	case WorkMemtableFlush:
+		return "memtable_flush"
+	case WorkCompaction:
+		return "compaction"
+	default:
+		return "unknown"
+	}
+}
+
+// Stage is a stage of write path work
+type Stage int
+
+const (
+	stageNone Stage = iota
+
+	// StageRead is reading the input of a compaction
+	StageRead
+	// StageEncode is encoding entries into blocks, and building the index and filter of an SST
+	StageEncode
+	// StageCompress is compressing blocks, the index and the filter, and computing their checksums
+	StageCompress
+	// StageUpload is writing an SST to object storage
+	StageUpload
+	// StageManifest is writing the manifest which records the SSTs
+	StageManifest
+	numStages
+)
+
+// String returns the name of the stage used as the value of the "slatedb_stage" label
+func (s Stage) String() string {
+	switch s {
+	case StageRead:
+		return "read"
+	case StageEncode:
+		return "encode"
+	case StageCompress:
+		return "compress"
+	case StageUpload:
+		return "upload"
+	case StageManifest:
+		return "manifest"
+	default:
+		return "none"
+	}
+}
+
+// ------------------------------------------------
+// Recorder
+// ------------------------------------------------
+
+// Recorder accumulates the time spent in each stage of each kind of work. A Recorder is safe
+// for concurrent use.
+type Recorder struct {
+	labels bool
+	nanos  [numWork][numStages]atomic.Uint64
+}
+
+// NewRecorder returns a Recorder. If `labels` is true, the goroutines doing work are labeled
+// with the work and stage, see runtime/pprof.
+func NewRecorder(labels bool) *Recorder {
+	return &Recorder{labels: labels}
+}
+
+// Duration returns the total time spent in `stage` by `work`
+func (r *Recorder) Duration(work Work, stage Stage) time.Duration {
+	if r == nil {
+		return 0
+	}
+	return time.Duration(r.nanos[work][stage].Load())
+}
+
+// Do calls f with a Profile of `work`, which f uses to enter and exit the stages of the work.
+// If the Recorder was created with labels, the calling goroutine is labeled with the work
+// until f returns. If the Recorder is nil, f is called with a nil Profile, which records nothing.
+func (r *Recorder) Do(work Work, f func(p *Profile)) {
+	if r == nil {
+		f(nil)
+		return
+	}
+	p := &Profile{recorder: r, work: work}
+	if !r.labels {
+		f(p)
+		p.Exit(stageNone)
+		return
+	}
+	pprof.Do(context.Background(), pprof.Labels("slatedb_work", work.String()), func(ctx context.Context) {
+		p.labels[stageNone] = ctx
+		for s := stageNone + 1; s < numStages; s++ {
+			p.labels[s] = pprof.WithLabels(ctx, pprof.Labels("slatedb_stage", s.String()))
+		}
+		f(p)
+		p.Exit(stageNone)
+	})
+}
+
+// ------------------------------------------------
+// Profile
+// ------------------------------------------------
+
+// Profile records the stages of a single unit of work, such as a flush or a compaction. Stages
+// are nested by entering a stage while in another, and the time spent in the inner stage is
+// not included in the outer stage. A Profile must only be used by the goroutine which called
+// Recorder.Do, and a nil Profile records nothing.
+type Profile struct {
+	recorder *Recorder
+	work     Work
+	stage    Stage
+	start    time.Time
+
+	// labels holds the labeled context of each stage, or nil if goroutines are not labeled
+	labels [numStages]context.Context
+}
+
+// Enter enters `stage`, returning the stage which was exited such that it can be restored
+// with Exit, for example
+//
+//	defer p.Exit(p.Enter(profile.StageUpload))
+func (p *Profile) Enter(stage Stage) Stage {
+	if p == nil {
+		return stageNone
+	}
+	prev := p.stage
+	p.switchTo(stage)
+	return prev
+}
+
+// Exit exits the current stage, returning to the stage `prev` returned by Enter
+func (p *Profile) Exit(prev Stage) {
+	if p == nil {
+		return
+	}
+	p.switchTo(prev)
+}
+
+func (p *Profile) switchTo(stage Stage) {
+	now := time.Now()
+	if p.stage != stageNone {
+		p.recorder.nanos[p.work][p.stage].Add(uint64(max(now.Sub(p.start), 0)))
+	}
+	p.stage, p.start = stage, now
+	if ctx := p.labels[stage]; ctx != nil {
+		pprof.SetGoroutineLabels(ctx)
+	}
+}
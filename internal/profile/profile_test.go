@@ -0,0 +1,53 @@
+package profile
+
+import (
+	"bytes"
+	"runtime/pprof"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNestedStagesAreExclusive(t *testing.T) {
+	r := NewRecorder(false)
+	r.Do(WorkCompaction, func(p *Profile) {
+		prev := p.Enter(StageEncode)
+		time.Sleep(20 * time.Millisecond)
+		inner := p.Enter(StageCompress)
+		time.Sleep(20 * time.Millisecond)
+		p.Exit(inner)
+		p.Exit(prev)
+	})
+
+	encode := r.Duration(WorkCompaction, StageEncode)
+	compress := r.Duration(WorkCompaction, StageCompress)
+	assert.GreaterOrEqual(t, encode, 20*time.Millisecond)
+	assert.Less(t, encode, 40*time.Millisecond)
+	assert.GreaterOrEqual(t, compress, 20*time.Millisecond)
+	assert.Zero(t, r.Duration(WorkMemtableFlush, StageEncode))
+}
+
+func TestLabels(t *testing.T) {
+	r := NewRecorder(true)
+	var buf bytes.Buffer
+	r.Do(WorkMemtableFlush, func(p *Profile) {
+		defer p.Exit(p.Enter(StageUpload))
+		require.NoError(t, pprof.Lookup("goroutine").WriteTo(&buf, 1))
+	})
+	assert.Contains(t, buf.String(), `"slatedb_stage":"upload"`)
+	assert.Contains(t, buf.String(), `"slatedb_work":"memtable_flush"`)
+	assert.Positive(t, r.Duration(WorkMemtableFlush, StageUpload))
+}
+
+func TestNilRecorder(t *testing.T) {
+	var r *Recorder
+	called := false
+	r.Do(WorkWALFlush, func(p *Profile) {
+		called = true
+		p.Exit(p.Enter(StageEncode))
+	})
+	assert.True(t, called)
+	assert.Zero(t, r.Duration(WorkWALFlush, StageEncode))
+}
@@ -18,6 +18,15 @@ type Block struct {
 	FirstKey []byte
 	Data     []byte
 	Offsets  []uint16
+
+	// RestartInterval is the number of rows between restart points if the block was built by a
+	// Builder with restarts, see NewBuilderWithRestarts. The key of each row is then stored
+	// relative to the key of the previous row, except the row at each restart point which holds
+	// its full key, and Offsets holds only the offsets of the restart points. A key is found by
+	// a binary search of the restart points, followed by a scan of at most RestartInterval rows.
+	//
+	// If zero, Offsets holds the offset of every row, and keys are stored relative to FirstKey.
+	RestartInterval uint16
 }
 
 // Encode encodes the Block into a byte slice using the following format
@@ -26,7 +35,9 @@ type Block struct {
 // shares no prefix with any previous keys. Subsequent keys in the block store
 // only store the suffix of the first if they share a common prefix with the first
 // key in the block, If they don't share a common prefix, then the suffix holds
-// the full key.
+// the full key. In a block with restarts, keys store only the suffix of the previous
+// key, and Block.Offsets holds only the offsets of the restart points, see
+// Block.RestartInterval.
 // +-----------------------------------------------+
 // |               Block                           |
 // +-----------------------------------------------+
@@ -51,7 +62,117 @@ type Block struct {
 // |  |  Checksum (4 bytes)                     |  |
 // |  +-----------------------------------------+  |
 // +-----------------------------------------------+
-func Encode(b *Block, codec compress.Codec) ([]byte, error) {
+//
+// The block is compressed with the codec at the compression level, see compress.EncodeWithLevel.
+// The checksum is a CRC32 (IEEE) of the compressed block, see EncodeWithOptions to use CRC32C.
+func Encode(b *Block, codec compress.Codec, level int) ([]byte, error) {
+	return EncodeWithOptions(b, Options{Codec: codec, Level: level})
+}
+
+// EncodeWithFlag encodes the Block in the same format as Encode, except a compression flag
+// (1 byte) is added before the checksum. The block is compressed with the provided codec,
+// if compression saves less than 1/minCompressionSavings of the block size, the
+// uncompressed block is stored instead and the flag is set to flagUncompressed.
+//
+// This avoids paying the cost of decompression on read for blocks which do not
+// compress well, such as blocks of already compressed values.
+func EncodeWithFlag(b *Block, codec compress.Codec, level int) ([]byte, error) {
+	return EncodeWithOptions(b, Options{Codec: codec, Level: level, CompressionFlag: true})
+}
+
+// Checksum is the algorithm of the checksum appended to each encoded block
+type Checksum uint8
+
+const (
+	// ChecksumCRC32 is a CRC32 using the IEEE polynomial, used by blocks written before
+	// ChecksumCRC32C was introduced
+	ChecksumCRC32 Checksum = iota
+
+	// ChecksumCRC32C is a CRC32 using the Castagnoli polynomial, which has better error
+	// detection than IEEE and is hardware accelerated on most platforms
+	ChecksumCRC32C
+)
+
+var crc32cTable = crc32.MakeTable(crc32.Castagnoli)
+
+// String returns the name of the checksum algorithm
+func (c Checksum) String() string {
+	switch c {
+	case ChecksumCRC32:
+		return "crc32"
+	case ChecksumCRC32C:
+		return "crc32c"
+	default:
+		return fmt.Sprintf("unknown(%d)", uint8(c))
+	}
+}
+
+func (c Checksum) sum(data []byte) (uint32, error) {
+	switch c {
+	case ChecksumCRC32:
+		return crc32.ChecksumIEEE(data), nil
+	case ChecksumCRC32C:
+		return crc32.Checksum(data, crc32cTable), nil
+	default:
+		return 0, internal.Err("unknown block checksum '%s'", c)
+	}
+}
+
+// Options are the options a block is encoded and decoded with. A block must be decoded
+// with the same options it was encoded with.
+type Options struct {
+	// The codec the block is compressed with
+	Codec compress.Codec
+
+	// The compression level of Codec, see compress.EncodeWithLevel. Unused when decoding.
+	Level int
+
+	// If true, the block is encoded with a compression flag, see EncodeWithFlag
+	CompressionFlag bool
+
+	// The algorithm of the checksum appended to the block
+	Checksum Checksum
+
+	// The restart interval of the block, see Block.RestartInterval. Unused when encoding, as the
+	// restart interval is set by the Builder.
+	RestartInterval uint16
+}
+
+// EncodeWithOptions encodes the Block in the format of Encode, or EncodeWithFlag if
+// Options.CompressionFlag is true, with the checksum computed by Options.Checksum
+func EncodeWithOptions(b *Block, opts Options) ([]byte, error) {
+	raw := encodeRaw(b)
+	compressed, err := compress.EncodeWithLevel(raw, opts.Codec, opts.Level)
+	if err != nil {
+		return nil, err
+	}
+	if !opts.CompressionFlag {
+		return appendChecksum(compressed, opts.Checksum)
+	}
+
+	flag := flagCompressed
+	if opts.Codec == compress.CodecNone || len(compressed) > len(raw)-len(raw)/minCompressionSavings {
+		compressed = raw
+		flag = flagUncompressed
+	}
+
+	buf := make([]byte, 0, len(compressed)+1+common.SizeOfUint32)
+	buf = append(buf, compressed...)
+	buf = append(buf, flag)
+	return appendChecksum(buf, opts.Checksum)
+}
+
+const (
+	flagUncompressed = byte(0)
+	flagCompressed   = byte(1)
+
+	// Compression must reduce the block size by at least 1/minCompressionSavings (12.5%)
+	// for EncodeWithFlag to store the compressed block
+	minCompressionSavings = 8
+)
+
+// encodeRaw returns the Block.Data and Block.Offsets in the uncompressed block format
+func encodeRaw(b *Block) []byte {
 	bufSize := len(b.Data) + len(b.Offsets)*common.SizeOfUint16 + common.SizeOfUint16
 
 	buf := make([]byte, 0, bufSize)
@@ -60,33 +181,78 @@ func Encode(b *Block, codec compress.Codec) ([]byte, error) {
 	for _, offset := range b.Offsets {
 		buf = binary.BigEndian.AppendUint16(buf, offset)
 	}
-	buf = binary.BigEndian.AppendUint16(buf, uint16(len(b.Offsets)))
+	return binary.BigEndian.AppendUint16(buf, uint16(len(b.Offsets)))
+}
 
-	compressed, err := compress.Encode(buf, codec)
+// appendChecksum returns a new buffer exactly the size of the data plus the checksum
+func appendChecksum(data []byte, checksum Checksum) ([]byte, error) {
+	sum, err := checksum.sum(data)
 	if err != nil {
 		return nil, err
 	}
-
-	// Make a new buffer exactly the size of the compressed plus the checksum
-	buf = make([]byte, 0, len(compressed)+common.SizeOfUint32)
-	buf = append(buf, compressed...)
-	buf = binary.BigEndian.AppendUint32(buf, crc32.ChecksumIEEE(compressed))
-	return buf, nil
+	buf := make([]byte, 0, len(data)+common.SizeOfUint32)
+	buf = append(buf, data...)
+	return binary.BigEndian.AppendUint32(buf, sum), nil
 }
 
 // Decode converts the encoded byte slice into the provided Block
 func Decode(b *Block, input []byte, codec compress.Codec) error {
+	return DecodeWithOptions(b, input, Options{Codec: codec})
+}
+
+// DecodeWithFlag converts a byte slice encoded by EncodeWithFlag into the provided Block
+func DecodeWithFlag(b *Block, input []byte, codec compress.Codec) error {
+	return DecodeWithOptions(b, input, Options{Codec: codec, CompressionFlag: true})
+}
+
+// DecodeWithOptions converts a byte slice encoded by EncodeWithOptions into the provided Block.
+// An error is returned if the checksum of the block does not match, such as when the block
+// was truncated or corrupted by the object store.
+func DecodeWithOptions(b *Block, input []byte, opts Options) error {
+	compressed, err := verifyChecksum(input, opts.Checksum)
+	if err != nil {
+		return err
+	}
+	b.RestartInterval = opts.RestartInterval
+	if !opts.CompressionFlag {
+		return decodeCompressed(b, compressed, opts.Codec)
+	}
+	if len(compressed) < 1 {
+		return internal.Err("corrupted block: block is missing compression flag")
+	}
+
+	codec := opts.Codec
+	flag := compressed[len(compressed)-1]
+	switch flag {
+	case flagUncompressed:
+		codec = compress.CodecNone
+	case flagCompressed:
+	default:
+		return internal.Err("corrupted block: invalid compression flag '%d'", flag)
+	}
+	return decodeCompressed(b, compressed[:len(compressed)-1], codec)
+}
+
+// verifyChecksum returns the input without the checksum if the checksum is valid
+func verifyChecksum(input []byte, checksum Checksum) ([]byte, error) {
 	if len(input) < 6 {
-		return internal.Err("corrupted block: block is too small; must be at least 6 bytes")
+		return nil, internal.Err("corrupted block: block is too small; must be at least 6 bytes")
 	}
 
 	// last 4 bytes hold the checksum
 	checksumIndex := len(input) - common.SizeOfUint32
 	compressed := input[:checksumIndex]
-	if binary.BigEndian.Uint32(input[checksumIndex:]) != crc32.ChecksumIEEE(compressed) {
-		return internal.Err("corrupted block: checksum mismatch")
+	sum, err := checksum.sum(compressed)
+	if err != nil {
+		return nil, err
 	}
+	if binary.BigEndian.Uint32(input[checksumIndex:]) != sum {
+		return nil, internal.Err("corrupted block: %s checksum mismatch", checksum)
+	}
+	return compressed, nil
+}
 
+func decodeCompressed(b *Block, compressed []byte, codec compress.Codec) error {
 	buf, err := compress.Decode(compressed, codec)
 	if err != nil {
 		return err
@@ -138,6 +304,11 @@ type Builder struct {
 	data      []byte
 	blockSize uint64
 	firstKey  []byte
+
+	// restartInterval, rows and lastKey are used by a Builder with restarts, see NewBuilderWithRestarts
+	restartInterval uint16
+	rows            int
+	lastKey         []byte
 }
 
 // NewBuilder builds a block of key values in the v0RowCodec
@@ -153,6 +324,15 @@ func NewBuilder(blockSize uint64) *Builder {
 	}
 }
 
+// NewBuilderWithRestarts is like NewBuilder, except the key of each row is stored relative
+// to the key of the previous row, and every restartInterval rows a restart point holds the
+// full key. See Block.RestartInterval. If restartInterval is zero, it is the same as NewBuilder.
+func NewBuilderWithRestarts(blockSize uint64, restartInterval uint16) *Builder {
+	b := NewBuilder(blockSize)
+	b.restartInterval = restartInterval
+	return b
+}
+
 func (b *Builder) curBlockSize() int {
 	return common.SizeOfUint16 + // number of key-value pairs in the block
 		(len(b.offsets) * common.SizeOfUint16) + // offsets
@@ -161,23 +341,42 @@ func (b *Builder) curBlockSize() int {
 
 func (b *Builder) Add(key []byte, row Row) bool {
 	assert.True(len(key) > 0, "key must not be empty")
-	row.keyPrefixLen = computePrefixLen(b.firstKey, key)
+	// Without restarts, every row has an offset and is a restart point relative to the first key
+	restart := b.restartInterval == 0 || b.rows%int(b.restartInterval) == 0
+	switch {
+	case b.restartInterval == 0:
+		row.keyPrefixLen = computePrefixLen(b.firstKey, key)
+	case restart:
+		row.keyPrefixLen = 0
+	default:
+		row.keyPrefixLen = computePrefixLen(b.lastKey, key)
+	}
 	row.keySuffix = key[row.keyPrefixLen:]
 
 	// If adding the key-value pair would exceed the block size limit, don't add it.
 	// (Unless the block is empty, in which case, allow the block to exceed the limit.)
 	// NOTE: This is the current block size, plus the size of a new offset in block.Offsets,
 	// plus the size of the new row to be added.
-	if uint64(b.curBlockSize()+common.SizeOfUint16+v0Size(row)) > b.blockSize && !b.IsEmpty() {
+	size := b.curBlockSize() + v0Size(row)
+	if restart {
+		size += common.SizeOfUint16
+	}
+	if uint64(size) > b.blockSize && !b.IsEmpty() {
 		return false
 	}
 
-	b.offsets = append(b.offsets, uint16(len(b.data)))
+	if restart {
+		b.offsets = append(b.offsets, uint16(len(b.data)))
+	}
 	b.data = append(b.data, v0RowCodec.Encode(row)...)
+	b.rows++
 
 	if b.firstKey == nil {
 		b.firstKey = bytes.Clone(key)
 	}
+	if b.restartInterval > 0 {
+		b.lastKey = append(b.lastKey[:0], key...)
+	}
 	return true
 }
 
@@ -197,15 +396,20 @@ func (b *Builder) Build() (*Block, error) {
 		return nil, internal.Err("assertion failed; block cannot be empty")
 	}
 	return &Block{
-		FirstKey: b.firstKey,
-		Offsets:  b.offsets,
-		Data:     b.data,
+		FirstKey:        b.firstKey,
+		Offsets:         b.offsets,
+		Data:            b.data,
+		RestartInterval: b.restartInterval,
 	}, nil
 }
 
 func PrettyPrint(block *Block) string {
 	buf := new(bytes.Buffer)
 	it := NewIterator(block)
+	if block.RestartInterval > 0 {
+		prettyPrintRows(buf, it, block)
+		return buf.String()
+	}
 	for _, offset := range block.Offsets {
 		kv, ok := it.NextEntry(context.Background())
 		if !ok {
@@ -230,6 +434,29 @@ func PrettyPrint(block *Block) string {
 	return buf.String()
 }
 
+// prettyPrintRows prints the rows of a block with restarts, which only has the offsets of the
+// restart points
+func prettyPrintRows(buf *bytes.Buffer, it *Iterator, block *Block) {
+	_, _ = fmt.Fprintf(buf, "Restart Interval: %d\n", block.RestartInterval)
+	_, _ = fmt.Fprintf(buf, "Restart Offsets: %v\n", block.Offsets)
+	for {
+		kv, ok := it.NextEntry(context.Background())
+		if !ok {
+			break
+		}
+		_, _ = fmt.Fprintf(buf, "    Key: []byte(\"%s\") - %d bytes\n", Truncate(kv.Key, 30), len(kv.Key))
+		if kv.Value.IsTombstone() {
+			_, _ = fmt.Fprintf(buf, "    IsTombstone\n")
+		} else {
+			v := kv.Value.Value
+			_, _ = fmt.Fprintf(buf, "  Value: []byte(\"%s\") - %d bytes\n", Truncate(v, 30), len(v))
+		}
+	}
+	if warn := it.Warnings(); !warn.Empty() {
+		_, _ = fmt.Fprintf(buf, "WARN: %s\n", warn.String())
+	}
+}
+
 // Truncate takes a given byte slice and truncates it to the provided
 // length appending "..." to the end if the slice was truncated and returning
 // the result as a string.
@@ -4,7 +4,10 @@ import (
 	"bytes"
 	"context"
 	"encoding/binary"
+	"fmt"
 	"hash/crc32"
+	"math/rand"
+	"slices"
 	"testing"
 
 	assert2 "github.com/slatedb/slatedb-go/internal/assert"
@@ -26,7 +29,7 @@ func TestNewBuilder(t *testing.T) {
 	b, err := bb.Build()
 	assert.NoError(t, err)
 
-	encoded, err := block.Encode(b, compress.CodecNone)
+	encoded, err := block.Encode(b, compress.CodecNone, 0)
 	assert.NoError(t, err)
 
 	var decoded block.Block
@@ -46,7 +49,7 @@ func TestBlockCompression(t *testing.T) {
 	b, err := bb.Build()
 	assert.Nil(t, err)
 
-	encoded, err := block.Encode(b, compress.CodecLz4)
+	encoded, err := block.Encode(b, compress.CodecLz4, 0)
 	assert.NoError(t, err)
 
 	var decoded block.Block
@@ -56,6 +59,98 @@ func TestBlockCompression(t *testing.T) {
 	assert.Equal(t, b.Offsets, decoded.Offsets)
 }
 
+func TestBlockCompressionWithFlag(t *testing.T) {
+	compressible := block.NewBuilder(4096)
+	assert.True(t, compressible.AddValue([]byte("key1"), bytes.Repeat([]byte("a"), 1000)))
+	assert.True(t, compressible.AddValue([]byte("key2"), bytes.Repeat([]byte("b"), 1000)))
+	compressibleBlock, err := compressible.Build()
+	require.NoError(t, err)
+
+	// random bytes do not compress
+	random := make([]byte, 1000)
+	_, _ = rand.New(rand.NewSource(1)).Read(random)
+	incompressible := block.NewBuilder(4096)
+	assert.True(t, incompressible.AddValue([]byte("key1"), random))
+	incompressibleBlock, err := incompressible.Build()
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		name       string
+		block      *block.Block
+		codec      compress.Codec
+		compressed bool
+	}{
+		{name: "compressible", block: compressibleBlock, codec: compress.CodecZstd, compressed: true},
+		{name: "incompressible", block: incompressibleBlock, codec: compress.CodecZstd, compressed: false},
+		{name: "no codec", block: compressibleBlock, codec: compress.CodecNone, compressed: false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			encoded, err := block.EncodeWithFlag(tc.block, tc.codec, 0)
+			require.NoError(t, err)
+
+			flag := encoded[len(encoded)-common.SizeOfUint32-1]
+			if tc.compressed {
+				assert.Equal(t, byte(1), flag)
+				assert.Less(t, len(encoded), len(tc.block.Data))
+			} else {
+				assert.Equal(t, byte(0), flag)
+			}
+
+			var decoded block.Block
+			require.NoError(t, block.DecodeWithFlag(&decoded, encoded, tc.codec))
+			assert.Equal(t, tc.block.Data, decoded.Data)
+			assert.Equal(t, tc.block.Offsets, decoded.Offsets)
+		})
+	}
+}
+
+func TestDecodeWithInvalidFlag(t *testing.T) {
+	bb := block.NewBuilder(4096)
+	assert.True(t, bb.AddValue([]byte("key1"), []byte("value1")))
+	b, err := bb.Build()
+	require.NoError(t, err)
+
+	encoded, err := block.EncodeWithFlag(b, compress.CodecNone, 0)
+	require.NoError(t, err)
+
+	checksumIndex := len(encoded) - common.SizeOfUint32
+	encoded[checksumIndex-1] = 5
+	binary.BigEndian.PutUint32(encoded[checksumIndex:], crc32.ChecksumIEEE(encoded[:checksumIndex]))
+
+	var decoded block.Block
+	assert.ErrorContains(t, block.DecodeWithFlag(&decoded, encoded, compress.CodecNone), "invalid compression flag")
+}
+
+func TestEncodeWithChecksum(t *testing.T) {
+	bb := block.NewBuilder(4096)
+	assert.True(t, bb.AddValue([]byte("key1"), []byte("value1")))
+	assert.True(t, bb.AddValue([]byte("key2"), []byte("value2")))
+	b, err := bb.Build()
+	require.NoError(t, err)
+
+	for _, flag := range []bool{false, true} {
+		opts := block.Options{Codec: compress.CodecSnappy, CompressionFlag: flag, Checksum: block.ChecksumCRC32C}
+		encoded, err := block.EncodeWithOptions(b, opts)
+		require.NoError(t, err)
+		checksumIndex := len(encoded) - common.SizeOfUint32
+		assert.Equal(t, crc32.Checksum(encoded[:checksumIndex], crc32.MakeTable(crc32.Castagnoli)),
+			binary.BigEndian.Uint32(encoded[checksumIndex:]))
+
+		var decoded block.Block
+		require.NoError(t, block.DecodeWithOptions(&decoded, encoded, opts))
+		assert.Equal(t, b.Data, decoded.Data)
+		assert.Equal(t, b.Offsets, decoded.Offsets)
+
+		// A block is rejected if decoded with a different checksum
+		err = block.DecodeWithOptions(&decoded, encoded, block.Options{Codec: compress.CodecSnappy, CompressionFlag: flag})
+		assert.ErrorContains(t, err, "crc32 checksum mismatch")
+
+		encoded[0]++
+		err = block.DecodeWithOptions(&decoded, encoded, opts)
+		assert.ErrorContains(t, err, "crc32c checksum mismatch")
+	}
+}
+
 func TestSmallestCompressedBlock(t *testing.T) {
 	testCases := []struct {
 		codec compress.Codec
@@ -76,7 +171,7 @@ func TestSmallestCompressedBlock(t *testing.T) {
 		b, err := bb.Build()
 		assert.Nil(t, err)
 
-		encoded, err := block.Encode(b, tc.codec)
+		encoded, err := block.Encode(b, tc.codec, 0)
 		assert.NoError(t, err)
 
 		var decoded block.Block
@@ -100,7 +195,7 @@ func TestBlockWithTombstone(t *testing.T) {
 	b, err := bb.Build()
 	assert.Nil(t, err)
 
-	encoded, err := block.Encode(b, compress.CodecNone)
+	encoded, err := block.Encode(b, compress.CodecNone, 0)
 	assert.NoError(t, err)
 
 	var decoded block.Block
@@ -244,6 +339,51 @@ func TestIterFromEnd(t *testing.T) {
 	assert.Equal(t, types.RowEntry{}, e)
 }
 
+func TestReverseIterator(t *testing.T) {
+	kvPairs := []types.KeyValue{
+		{Key: []byte("donkey"), Value: []byte("kong")},
+		{Key: []byte("kratos"), Value: []byte("atreus")},
+		{Key: []byte("super"), Value: []byte("mario")},
+	}
+
+	bb := block.NewBuilder(1024)
+	for _, kv := range kvPairs {
+		assert.True(t, bb.AddValue(kv.Key, kv.Value))
+	}
+
+	b, err := bb.Build()
+	require.NoError(t, err)
+
+	keys := func(iter *block.Iterator) []string {
+		var result []string
+		for {
+			e, ok := iter.NextEntry(context.Background())
+			if !ok {
+				return result
+			}
+			result = append(result, string(e.Key))
+		}
+	}
+
+	iter, err := block.NewReverseIterator(b)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"super", "kratos", "donkey"}, keys(iter))
+
+	for _, tt := range []struct {
+		key      string
+		expected []string
+	}{
+		{key: "kratos", expected: []string{"kratos", "donkey"}},
+		{key: "ka", expected: []string{"donkey"}},
+		{key: "zzz", expected: []string{"super", "kratos", "donkey"}},
+		{key: "a", expected: nil},
+	} {
+		iter, err := block.NewReverseIteratorAtKey(b, []byte(tt.key))
+		require.NoError(t, err)
+		assert.Equal(t, tt.expected, keys(iter), "reverse from '%s'", tt.key)
+	}
+}
+
 func TestNewBuilderWithOffsets(t *testing.T) {
 	bb := block.NewBuilder(4096)
 	assert.True(t, bb.IsEmpty())
@@ -403,7 +543,7 @@ func TestDecodeCorruptV0Block(t *testing.T) {
 			assert.True(t, bb.AddValue([]byte("key2"), []byte("value2")))
 			b, err := bb.Build()
 			require.NoError(t, err)
-			encoded, err := block.Encode(b, compress.CodecNone)
+			encoded, err := block.Encode(b, compress.CodecNone, 0)
 			require.NoError(t, err)
 			corruptedBlock := tt.corruptFunc(encoded)
 			err = block.Decode(b, corruptedBlock, compress.CodecNone)
@@ -525,3 +665,101 @@ func TestNewIteratorAtKeyWithCorruptedKeys(t *testing.T) {
 		//t.Logf("Warnings: %s", iter.Warnings())
 	})
 }
+
+func TestBlockWithRestarts(t *testing.T) {
+	var keys []string
+	for i := 0; i < 40; i++ {
+		keys = append(keys, fmt.Sprintf("user/%04d", i*2))
+	}
+
+	build := func(t *testing.T, restartInterval uint16) *block.Block {
+		bb := block.NewBuilderWithRestarts(4096, restartInterval)
+		for _, key := range keys {
+			require.True(t, bb.AddValue([]byte(key), []byte("value-"+key)))
+		}
+		b, err := bb.Build()
+		require.NoError(t, err)
+
+		opts := block.Options{Codec: compress.CodecNone, RestartInterval: restartInterval}
+		encoded, err := block.EncodeWithOptions(b, opts)
+		require.NoError(t, err)
+		var decoded block.Block
+		require.NoError(t, block.DecodeWithOptions(&decoded, encoded, opts))
+		return &decoded
+	}
+
+	collect := func(t *testing.T, iter *block.Iterator) []string {
+		result := []string{}
+		for {
+			e, ok := iter.NextEntry(context.Background())
+			if !ok {
+				require.True(t, iter.Warnings().Empty(), iter.Warnings().String())
+				return result
+			}
+			assert.Equal(t, "value-"+string(e.Key), string(e.Value.Value))
+			result = append(result, string(e.Key))
+		}
+	}
+	reversed := func(s []string) []string {
+		r := slices.Clone(s)
+		slices.Reverse(r)
+		return r
+	}
+
+	for _, restartInterval := range []uint16{1, 3, 16, 64} {
+		t.Run(fmt.Sprintf("RestartInterval%d", restartInterval), func(t *testing.T) {
+			b := build(t, restartInterval)
+			assert.Equal(t, restartInterval, b.RestartInterval)
+			assert.Equal(t, (len(keys)+int(restartInterval)-1)/int(restartInterval), len(b.Offsets))
+
+			assert.Equal(t, keys, collect(t, block.NewIterator(b)))
+
+			for _, tt := range []struct {
+				key   string
+				index int
+			}{
+				{key: "a", index: 0},
+				{key: keys[0], index: 0},
+				{key: keys[17], index: 17},
+				{key: "user/0035", index: 18},
+				{key: keys[len(keys)-1], index: len(keys) - 1},
+				{key: "zzz", index: len(keys)},
+			} {
+				iter, err := block.NewIteratorAtKey(b, []byte(tt.key))
+				require.NoError(t, err)
+				assert.Equal(t, keys[tt.index:], collect(t, iter), "forward from '%s'", tt.key)
+
+				iter, err = block.NewReverseIteratorAtKey(b, []byte(tt.key))
+				require.NoError(t, err)
+				expected := tt.index
+				if tt.index < len(keys) && keys[tt.index] == tt.key {
+					expected++
+				}
+				assert.Equal(t, reversed(keys[:expected]), collect(t, iter), "reverse from '%s'", tt.key)
+			}
+
+			iter, err := block.NewReverseIterator(b)
+			require.NoError(t, err)
+			assert.Equal(t, reversed(keys), collect(t, iter))
+		})
+	}
+
+	t.Run("Smaller", func(t *testing.T) {
+		bb := block.NewBuilder(4096)
+		for _, key := range keys {
+			require.True(t, bb.AddValue([]byte(key), []byte("value-"+key)))
+		}
+		legacy, err := bb.Build()
+		require.NoError(t, err)
+
+		b := build(t, 16)
+		assert.Less(t, len(b.Data)+2*len(b.Offsets), len(legacy.Data)+2*len(legacy.Offsets))
+	})
+
+	t.Run("PrettyPrint", func(t *testing.T) {
+		out := block.PrettyPrint(build(t, 16))
+		for _, key := range keys {
+			assert.Contains(t, out, key)
+		}
+	})
+}
@@ -16,6 +16,23 @@ type Iterator struct {
 	offsetIndex uint64
 	warn        types.ErrWarn
 	firstKey    []byte
+
+	// A reverse iterator returns the rows at offsets [lowIndex, offsetIndex) from last to first
+	reverse  bool
+	lowIndex uint64
+
+	// The position of a forward iterator of a block with restarts; pos is the offset in
+	// block.Data of the next row, whose key is stored relative to prevKey. Rows with keys
+	// less than seekKey are skipped.
+	pos     int
+	prevKey []byte
+	seekKey []byte
+
+	// The rows of a restart interval which a reverse iterator of a block with restarts has yet
+	// to return, and the index of the restart interval to decode once they have been returned.
+	// Rows with keys greater than seekKey are skipped.
+	pending      []types.RowEntry
+	restartIndex int
 }
 
 // NewIterator constructs a block.Iterator that starts at the beginning of the block
@@ -26,9 +43,54 @@ func NewIterator(block *Block) *Iterator {
 	}
 }
 
+// newRestartIterator returns an Iterator of a block with restarts, see Block.RestartInterval
+func newRestartIterator(block *Block, key []byte, reverse bool) (*Iterator, error) {
+	if len(block.Offsets) <= 0 {
+		return nil, internal.Err("number of block.Offsets must be greater than zero")
+	}
+	iter := &Iterator{block: block, reverse: reverse, seekKey: bytes.Clone(key)}
+	if reverse {
+		iter.restartIndex = len(block.Offsets) - 1
+		if key != nil {
+			// The rows of the restart intervals after the last restart point whose key is not
+			// greater than the key are all greater than the key
+			iter.restartIndex = iter.searchRestarts(key) - 1
+		}
+		return iter, nil
+	}
+	if key != nil {
+		// Iteration begins at the last restart point whose key is not greater than the key
+		i := max(iter.searchRestarts(key)-1, 0)
+		iter.pos = int(block.Offsets[i])
+	}
+	return iter, nil
+}
+
+// searchRestarts returns the index of the first restart point whose key is greater than `key`,
+// or the number of restart points if there is none
+func (iter *Iterator) searchRestarts(key []byte) int {
+	block := iter.block
+	return sort.Search(len(block.Offsets), func(i int) bool {
+		if int(block.Offsets[i]) >= len(block.Data) {
+			iter.warn.Add("block.Offset[%d] = %d is out of bounds", i, block.Offsets[i])
+			return false
+		}
+		// The row at a restart point holds its full key, so it is peeked without a prefix
+		p, err := v0RowCodec.PeekAtKey(block.Data[block.Offsets[i]:], nil)
+		if err != nil {
+			iter.warn.Add("while peeking at restart block.Offset[%d]: %s", i, err)
+			return false
+		}
+		return bytes.Compare(p.keySuffix, key) > 0
+	})
+}
+
 // NewIteratorAtKey Construct a block.Iterator that starts at the given key, or at the first
 // key greater than the given key if the exact key given is not in the block.
 func NewIteratorAtKey(block *Block, key []byte) (*Iterator, error) {
+	if block.RestartInterval > 0 {
+		return newRestartIterator(block, key, false)
+	}
 	if len(block.Offsets) <= 0 {
 		return nil, internal.Err("number of block.Offsets must be greater than zero")
 	}
@@ -81,17 +143,88 @@ func NewIteratorAtKey(block *Block, key []byte) (*Iterator, error) {
 	}, nil
 }
 
+// NewReverseIterator constructs a block.Iterator that starts at the end of the block
+// and returns the keys of the block in descending order.
+func NewReverseIterator(block *Block) (*Iterator, error) {
+	return newReverseIterator(block, nil)
+}
+
+// NewReverseIteratorAtKey constructs a block.Iterator that returns keys in descending order
+// starting at the given key, or at the last key less than the given key if the exact key
+// given is not in the block.
+func NewReverseIteratorAtKey(block *Block, key []byte) (*Iterator, error) {
+	return newReverseIterator(block, key)
+}
+
+func newReverseIterator(block *Block, key []byte) (*Iterator, error) {
+	if block.RestartInterval > 0 {
+		return newRestartIterator(block, key, true)
+	}
+	if len(block.Offsets) <= 0 {
+		return nil, internal.Err("number of block.Offsets must be greater than zero")
+	}
+	var warn types.ErrWarn
+
+	// Rows are decoded relative to the first full key, so unlike a forward iterator
+	// it must be located before any row is decoded. See NewIteratorAtKey.
+	first, idx, ok := firstFullKey(block, &warn)
+	if !ok {
+		if warn.Empty() {
+			return nil, fmt.Errorf("corrupted block; no full key found")
+		}
+		return nil, &warn
+	}
+
+	end := len(block.Offsets)
+	if key != nil {
+		// Search for the first row with a key greater than the given key
+		end = idx + sort.Search(len(block.Offsets)-idx, func(i int) bool {
+			if block.Offsets[i+idx] > uint16(len(block.Data)) {
+				warn.Add("block.Offset[%d] = %d is out of bounds", i+idx, block.Offsets[i+idx])
+				return false
+			}
+			p, err := v0RowCodec.PeekAtKey(block.Data[block.Offsets[i+idx]:], first.keySuffix)
+			if err != nil {
+				warn.Add("while peeking at block.Offset[%d]: %s", i+idx, err)
+				return false
+			}
+			return bytes.Compare(v0FullKey(p, first.keySuffix), key) > 0
+		})
+	}
+
+	return &Iterator{
+		firstKey:    bytes.Clone(first.keySuffix),
+		offsetIndex: uint64(end),
+		lowIndex:    uint64(idx),
+		reverse:     true,
+		block:       block,
+		warn:        warn,
+	}, nil
+}
+
 func (iter *Iterator) NextEntry(ctx context.Context) (types.RowEntry, bool) {
-	if iter.offsetIndex >= uint64(len(iter.block.Offsets)) {
+	if iter.block.RestartInterval > 0 {
+		if iter.reverse {
+			return iter.prevRestartEntry()
+		}
+		return iter.nextRestartEntry()
+	}
+	index := iter.offsetIndex
+	if iter.reverse {
+		if index <= iter.lowIndex {
+			return types.RowEntry{}, false
+		}
+		index--
+	} else if index >= uint64(len(iter.block.Offsets)) {
 		return types.RowEntry{}, false
 	}
 
 	data := iter.block.Data
-	offset := iter.block.Offsets[iter.offsetIndex]
+	offset := iter.block.Offsets[index]
 
 	r, err := v0RowCodec.Decode(data[offset:], iter.firstKey)
 	if err != nil {
-		iter.warn.Add("while decoding block.Offset[%d]: %s", iter.offsetIndex, err)
+		iter.warn.Add("while decoding block.Offset[%d]: %s", index, err)
 		return types.RowEntry{}, false
 	}
 
@@ -99,11 +232,97 @@ func (iter *Iterator) NextEntry(ctx context.Context) (types.RowEntry, bool) {
 		iter.firstKey = v0FullKey(*r, nil)
 	}
 
-	iter.offsetIndex += 1
+	if iter.reverse {
+		iter.offsetIndex = index
+	} else {
+		iter.offsetIndex = index + 1
+	}
+	return rowEntry(r, v0FullKey(*r, iter.firstKey)), true
+}
+
+func rowEntry(r *Row, key []byte) types.RowEntry {
 	return types.RowEntry{
-		Key:   v0FullKey(*r, iter.firstKey),
-		Value: r.ToValue(),
-	}, true
+		Key:      key,
+		Value:    r.ToValue(),
+		Seq:      r.Seq,
+		ExpireAt: r.ExpireAt,
+		Checksum: r.Checksum,
+	}
+}
+
+// nextRestartEntry returns the next row of a forward iterator of a block with restarts. If a row
+// cannot be decoded, iteration resumes at the next restart point, whose key does not depend on
+// the rows before it.
+func (iter *Iterator) nextRestartEntry() (types.RowEntry, bool) {
+	data := iter.block.Data
+	for iter.pos < len(data) {
+		r, err := v0RowCodec.Decode(data[iter.pos:], iter.prevKey)
+		if err != nil {
+			iter.warn.Add("while decoding row at block.Data[%d]: %s", iter.pos, err)
+			next := sort.Search(len(iter.block.Offsets), func(i int) bool {
+				return int(iter.block.Offsets[i]) > iter.pos
+			})
+			iter.pos, iter.prevKey = len(data), nil
+			if next < len(iter.block.Offsets) {
+				iter.pos = int(iter.block.Offsets[next])
+			}
+			continue
+		}
+		key := v0FullKey(*r, iter.prevKey)
+		iter.pos += v0Size(*r)
+		iter.prevKey = key
+		if iter.seekKey != nil {
+			if bytes.Compare(key, iter.seekKey) < 0 {
+				continue
+			}
+			iter.seekKey = nil
+		}
+		return rowEntry(r, key), true
+	}
+	return types.RowEntry{}, false
+}
+
+// prevRestartEntry returns the next row of a reverse iterator of a block with restarts. The rows
+// of each restart interval are decoded from first to last, and then returned from last to first.
+func (iter *Iterator) prevRestartEntry() (types.RowEntry, bool) {
+	for len(iter.pending) == 0 {
+		if iter.restartIndex < 0 {
+			return types.RowEntry{}, false
+		}
+		iter.pending = iter.decodeRestartInterval(iter.restartIndex)
+		iter.restartIndex--
+	}
+	last := len(iter.pending) - 1
+	entry := iter.pending[last]
+	iter.pending = iter.pending[:last]
+	return entry, true
+}
+
+// decodeRestartInterval returns the rows of the restart interval `index` whose keys are not
+// greater than seekKey. If a row cannot be decoded, the rows before it are returned.
+func (iter *Iterator) decodeRestartInterval(index int) []types.RowEntry {
+	block := iter.block
+	start, end := int(block.Offsets[index]), len(block.Data)
+	if index+1 < len(block.Offsets) {
+		end = min(int(block.Offsets[index+1]), end)
+	}
+	var rows []types.RowEntry
+	var prevKey []byte
+	for pos := start; pos < end; {
+		r, err := v0RowCodec.Decode(block.Data[pos:end], prevKey)
+		if err != nil {
+			iter.warn.Add("while decoding row at block.Data[%d]: %s", pos, err)
+			break
+		}
+		key := v0FullKey(*r, prevKey)
+		if iter.seekKey != nil && bytes.Compare(key, iter.seekKey) > 0 {
+			break
+		}
+		rows = append(rows, rowEntry(r, key))
+		pos += v0Size(*r)
+		prevKey = key
+	}
+	return rows
 }
 
 // Warnings returns types.ErrWarn if there was an error during iteration.
@@ -4,9 +4,12 @@ import (
 	"bytes"
 	"encoding/binary"
 	"github.com/slatedb/slatedb-go/internal"
+	"hash/crc32"
 	"math"
 	"time"
 
+	"github.com/samber/mo"
+
 	"github.com/slatedb/slatedb-go/internal/assert"
 	"github.com/slatedb/slatedb-go/internal/types"
 	"github.com/slatedb/slatedb-go/slatedb/common"
@@ -20,6 +23,8 @@ const (
 	flagTombstone v0RowFlags = 1 << iota
 	flagHasExpire
 	flagHasCreate
+	flagHasChecksum
+	flagHasEntryChecksum
 
 	v0ErrPrefix = "corrupt v0 row: "
 )
@@ -29,6 +34,11 @@ type Row struct {
 	ExpireAt  time.Time
 	CreatedAt time.Time
 	Value     types.Value
+	Checksum  mo.Option[uint32]
+
+	// EntryChecksum, if true, encodes the row followed by a CRC32C checksum of the encoded row,
+	// such that Decode returns an error if any byte of the row, rather than only the value, is corrupt
+	EntryChecksum bool
 
 	// Used by v0Codec, might consider moving into a separate
 	// v0Row structure if future row versions are radically different
@@ -83,25 +93,37 @@ func v0Flags(r Row) v0RowFlags {
 	if r.Value.IsTombstone() {
 		flags |= flagTombstone
 	}
-	if r.ExpireAt.Nanosecond() != 0 {
+	if !r.ExpireAt.IsZero() {
 		flags |= flagHasExpire
 	}
-	if r.CreatedAt.Nanosecond() != 0 {
+	if !r.CreatedAt.IsZero() {
 		flags |= flagHasCreate
 	}
+	if r.Checksum.IsPresent() && !r.Value.IsTombstone() {
+		flags |= flagHasChecksum
+	}
+	if r.EntryChecksum {
+		flags |= flagHasEntryChecksum
+	}
 	return flags
 }
 
 func v0Size(r Row) int {
 	size := 2 + 2 + len(r.keySuffix) + 8 + 1 // keyPrefixLen + keySuffixLen + keySuffix + Seq + Flags
-	if r.ExpireAt.Nanosecond() != 0 {
+	if !r.ExpireAt.IsZero() {
 		size += 8
 	}
-	if r.CreatedAt.Nanosecond() != 0 {
+	if !r.CreatedAt.IsZero() {
 		size += 8
 	}
 	if !r.Value.IsTombstone() {
 		size += 4 + len(r.Value.Value) // value_len + value
+		if r.Checksum.IsPresent() {
+			size += 4
+		}
+	}
+	if r.EntryChecksum {
+		size += 4
 	}
 	return size
 }
@@ -121,6 +143,15 @@ type v0Codec struct{}
 //	| KeyPrefixLen   | KeySuffixLen   | KeySuffix   | seq     | flags     | expireAt  | createdAt | valueLen  | value     |
 //	|---------------------------------------------------------------------------------------------------------------------|
 //
+//	followed by an optional uint32 checksum of the value when flags & FlagHasChecksum
+//
+// ```
+//
+// Both are followed by an optional uint32 CRC32C checksum of all the bytes of the row which
+// precede it when flags & FlagHasEntryChecksum
+//
+// ```txt
+//
 // ```
 //
 // And for tombstones (flags & Tombstone == 1):
@@ -144,6 +175,8 @@ type v0Codec struct{}
 // | `createdAt`      | `int64`  | Optional, only has value when flags & FlagHasCreate    |
 // | `value_len`      | `uint32` | Length of the value                                    |
 // | `value`          | `[]byte` | Value bytes                                            |
+// | `checksum`       | `uint32` | Optional, only has value when flags & FlagHasChecksum  |
+// | `entryChecksum`  | `uint32` | Optional, only when flags & FlagHasEntryChecksum       |
 //
 // NOTE: both expireAt and createdAt are epoch
 func (c v0Codec) Encode(r Row) []byte {
@@ -169,11 +202,11 @@ func (c v0Codec) Encode(r Row) []byte {
 	offset++
 
 	// Encode ExpireAt and CreatedAt if present
-	if r.ExpireAt.Nanosecond() != 0 {
+	if !r.ExpireAt.IsZero() {
 		binary.BigEndian.PutUint64(output[offset:], uint64(r.ExpireAt.UnixMilli()))
 		offset += 8
 	}
-	if r.CreatedAt.Nanosecond() != 0 {
+	if !r.CreatedAt.IsZero() {
 		binary.BigEndian.PutUint64(output[offset:], uint64(r.CreatedAt.UnixMilli()))
 		offset += 8
 	}
@@ -183,8 +216,16 @@ func (c v0Codec) Encode(r Row) []byte {
 		binary.BigEndian.PutUint32(output[offset:], uint32(len(r.Value.Value)))
 		offset += 4
 		copy(output[offset:], r.Value.Value)
+		offset += len(r.Value.Value)
+		if checksum, ok := r.Checksum.Get(); ok {
+			binary.BigEndian.PutUint32(output[offset:], checksum)
+			offset += 4
+		}
 	}
 
+	if r.EntryChecksum {
+		binary.BigEndian.PutUint32(output[offset:], crc32.Checksum(output[:offset], crc32cTable))
+	}
 	return output
 }
 
@@ -253,10 +294,27 @@ func (c v0Codec) Decode(data []byte, firstKey []byte) (*Row, error) {
 		value := make([]byte, valueLen)
 		copy(value, data[offset:offset+int(valueLen)])
 		r.Value = types.Value{Value: value}
+		offset += int(valueLen)
+		if flags&flagHasChecksum != 0 {
+			if len(data[offset:]) < 4 {
+				return nil, internal.Err(v0ErrPrefix + "data length too short for checksum")
+			}
+			r.Checksum = mo.Some(binary.BigEndian.Uint32(data[offset:]))
+			offset += 4
+		}
 	} else {
 		r.Value = types.Value{Kind: types.KindTombStone}
 	}
 
+	if flags&flagHasEntryChecksum != 0 {
+		if len(data[offset:]) < 4 {
+			return nil, internal.Err(v0ErrPrefix + "data length too short for entry checksum")
+		}
+		if binary.BigEndian.Uint32(data[offset:]) != crc32.Checksum(data[:offset], crc32cTable) {
+			return nil, internal.Err(v0ErrPrefix + "entry checksum mismatch")
+		}
+		r.EntryChecksum = true
+	}
 	return &r, nil
 }
 
@@ -287,6 +345,27 @@ func (c v0Codec) PeekAtKey(data []byte, firstKey []byte) (Row, error) {
 	return r, nil
 }
 
+// EncodeInlineRow encodes the entry as a v0 row which holds the full key as its suffix, such that
+// it can be stored and decoded outside a block, see DecodeInlineRow
+func EncodeInlineRow(entry types.RowEntry) []byte {
+	return v0RowCodec.Encode(Row{
+		Seq:       entry.Seq,
+		ExpireAt:  entry.ExpireAt,
+		Value:     entry.Value,
+		Checksum:  entry.Checksum,
+		keySuffix: entry.Key,
+	})
+}
+
+// DecodeInlineRow decodes a row encoded by EncodeInlineRow
+func DecodeInlineRow(data []byte) (types.RowEntry, error) {
+	r, err := v0RowCodec.Decode(data, nil)
+	if err != nil {
+		return types.RowEntry{}, err
+	}
+	return rowEntry(r, r.keySuffix), nil
+}
+
 // computePrefixLen calculates the length of the common prefix between two byte slices.
 // Source: https://users.rust-lang.org/t/how-to-find-common-prefix-of-two-byte-slices-effectively/25815/4
 func computePrefixLen(lhs, rhs []byte) uint16 {
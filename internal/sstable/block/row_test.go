@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/kapetan-io/tackle/random"
+	"github.com/samber/mo"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -38,6 +39,13 @@ func TestRowFlags(t *testing.T) {
 			},
 			expected: flagHasExpire,
 		},
+		{
+			name: "WithExpireOnWholeSecond",
+			row: Row{
+				ExpireAt: time.Unix(10, 0),
+			},
+			expected: flagHasExpire,
+		},
 		{
 			name: "WithCreate",
 			row: Row{
@@ -45,6 +53,21 @@ func TestRowFlags(t *testing.T) {
 			},
 			expected: flagHasCreate,
 		},
+		{
+			name: "WithChecksum",
+			row: Row{
+				Checksum: mo.Some(uint32(1)),
+			},
+			expected: flagHasChecksum,
+		},
+		{
+			name: "TombstoneWithChecksum",
+			row: Row{
+				Value:    types.Value{Kind: types.KindTombStone},
+				Checksum: mo.Some(uint32(1)),
+			},
+			expected: flagTombstone,
+		},
 		{
 			name: "AllFlags",
 			row: Row{
@@ -115,6 +138,32 @@ func TestV0RowCodecDecodeErrors(t *testing.T) {
 	}
 }
 
+func TestV0RowCodecEntryChecksum(t *testing.T) {
+	row := Row{
+		Seq:           42,
+		ExpireAt:      time.UnixMilli(1000),
+		Value:         types.Value{Value: []byte("value")},
+		EntryChecksum: true,
+		keySuffix:     []byte("key"),
+	}
+	encoded := v0RowCodec.Encode(row)
+	require.Len(t, encoded, v0Size(row))
+	decoded, err := v0RowCodec.Decode(encoded, nil)
+	require.NoError(t, err)
+	assert.Equal(t, row, *decoded)
+
+	// A corrupt byte anywhere in the key, sequence number, timestamps or value is detected
+	for _, i := range []int{4, 10, 16, len(encoded) - 6, len(encoded) - 1} {
+		corrupt := bytes.Clone(encoded)
+		corrupt[i] ^= 0xff
+		_, err := v0RowCodec.Decode(corrupt, nil)
+		require.Error(t, err, "byte %d", i)
+	}
+
+	_, err = v0RowCodec.Decode(encoded[:len(encoded)-2], nil)
+	require.ErrorContains(t, err, v0ErrPrefix+"data length too short for entry checksum")
+}
+
 func TestV0CodecPeekAtKeyErrors(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -201,6 +250,18 @@ func TestRowCodecV0EncodeAndDecode(t *testing.T) {
 			},
 			firstKeyPrefix: []byte("timecreate"),
 		},
+		{
+			name: "RowWithChecksum",
+			row: Row{
+				keyPrefixLen: 2,
+				keySuffix:    []byte("sum"),
+				Seq:          7,
+				Value:        types.Value{Value: []byte("value")},
+				ExpireAt:     time.UnixMilli(10),
+				Checksum:     mo.Some(types.ValueChecksum([]byte("value"))),
+			},
+			firstKeyPrefix: []byte("checksum"),
+		},
 		{
 			name: "TombstoneRow",
 			row: Row{
@@ -424,7 +485,7 @@ func TestV0EstimateBlockSize(t *testing.T) {
 
 	b, err := bb.Build()
 	assert.NoError(t, err)
-	blk, err := Encode(b, compress.CodecNone)
+	blk, err := Encode(b, compress.CodecNone, 0)
 	assert.NoError(t, err)
 
 	estimatedSize := V0EstimateBlockSize([]types.KeyValue{{Key: []byte("k"), Value: []byte("v")}})
@@ -2,17 +2,71 @@ package bloom
 
 import (
 	"encoding/binary"
+	"fmt"
 	"hash/crc32"
 	"hash/fnv"
+	"math"
+
+	"github.com/cespare/xxhash/v2"
 
 	"github.com/slatedb/slatedb-go/internal"
 	"github.com/slatedb/slatedb-go/internal/compress"
 	"github.com/slatedb/slatedb-go/slatedb/common"
 )
 
+// Hash identifies the function used to hash keys added to a filter. The hash and seed
+// are persisted with the filter, such that filters built with any supported hash remain
+// readable regardless of the hash currently configured for new filters.
+type Hash uint8
+
+const (
+	// HashFNV64 is the 64-bit FNV-1 hash. Filters written before the hash was
+	// persisted with the filter always use HashFNV64 with a seed of zero.
+	HashFNV64 Hash = iota
+
+	// HashXXH64 is the 64-bit xxHash
+	HashXXH64
+)
+
+// hashedFilterFlag is set on the number of probes when the hash and seed
+// are encoded into the filter. See Encode
+const hashedFilterFlag = uint16(0x8000)
+
+func (h Hash) valid() bool {
+	return h == HashFNV64 || h == HashXXH64
+}
+
+func (h Hash) String() string {
+	switch h {
+	case HashFNV64:
+		return "fnv64"
+	case HashXXH64:
+		return "xxh64"
+	}
+	return fmt.Sprintf("unknown(%d)", int(h))
+}
+
+// Sum returns the hash of the key using the provided seed
+func (h Hash) Sum(key []byte, seed uint64) uint64 {
+	if h == HashXXH64 {
+		d := xxhash.NewWithSeed(seed)
+		_, _ = d.Write(key)
+		return d.Sum64()
+	}
+
+	hash := fnv.New64()
+	if seed != 0 {
+		_, _ = hash.Write(binary.BigEndian.AppendUint64(nil, seed))
+	}
+	_, _ = hash.Write(key)
+	return hash.Sum64()
+}
+
 type Filter struct {
 	NumProbes uint16
 	Data      []byte
+	Hash      Hash
+	Seed      uint64
 }
 
 // HasKey returns true if the key might exist in the bloom filter, false if it definitely does not
@@ -21,7 +75,7 @@ func (f *Filter) HasKey(key []byte) bool {
 		return false
 	}
 
-	probes := probesForKey(filterHash(key), f.NumProbes, uint32(len(f.Data)*8))
+	probes := probesForKey(f.Hash.Sum(key, f.Seed), f.NumProbes, uint32(len(f.Data)*8))
 	for _, p := range probes {
 		if !checkBit(uint64(p), f.Data) {
 			return false
@@ -39,6 +93,10 @@ func (f *Filter) HasKey(key []byte) bool {
 // |  +-----------------------------------------+  |
 // |  |  Num of Probes (2 bytes)                |  |
 // |  +-----------------------------------------+  |
+// |  |  Hash (1 byte) - optional               |  |
+// |  +-----------------------------------------+  |
+// |  |  Seed (8 bytes) - optional              |  |
+// |  +-----------------------------------------+  |
 // |  |  Bit Array (N * bitsPerKey)             |  |
 // |  |  +-----------------------------------+  |  |
 // |  |  |  Bit 0                            |  |  |
@@ -49,10 +107,27 @@ func (f *Filter) HasKey(key []byte) bool {
 // |  |  Checksum (4 bytes)                     |  |
 // |  +-----------------------------------------+  |
 // +-----------------------------------------------+
+//
+// The hash and seed are only encoded if the filter does not use HashFNV64 with a zero
+// seed, in which case the high bit of Num of Probes is set. This keeps filters which use
+// the default hash readable by older versions.
 func Encode(f Filter, codec compress.Codec) ([]byte, error) {
-	buf := make([]byte, 2+len(f.Data))
-	binary.BigEndian.PutUint16(buf[:2], f.NumProbes)
-	copy(buf[2:], f.Data)
+	if !f.Hash.valid() {
+		return nil, internal.Err("unsupported filter hash '%d'", f.Hash)
+	}
+	if f.NumProbes&hashedFilterFlag != 0 {
+		return nil, internal.Err("filter has too many probes '%d'", f.NumProbes)
+	}
+
+	buf := make([]byte, 0, 2+1+8+len(f.Data))
+	if f.Hash == HashFNV64 && f.Seed == 0 {
+		buf = binary.BigEndian.AppendUint16(buf, f.NumProbes)
+	} else {
+		buf = binary.BigEndian.AppendUint16(buf, f.NumProbes|hashedFilterFlag)
+		buf = append(buf, byte(f.Hash))
+		buf = binary.BigEndian.AppendUint64(buf, f.Seed)
+	}
+	buf = append(buf, f.Data...)
 
 	compressed, err := compress.Encode(buf, codec)
 	if err != nil {
@@ -84,34 +159,62 @@ func Decode(data []byte, codec compress.Codec) (Filter, error) {
 	}
 
 	numProbes := binary.BigEndian.Uint16(buf[:2])
+	if numProbes&hashedFilterFlag == 0 {
+		return Filter{
+			NumProbes: numProbes,
+			Data:      buf[2:],
+			Hash:      HashFNV64,
+		}, nil
+	}
+
+	if len(buf) < 2+1+8 {
+		return Filter{}, internal.Err("corrupt filter: filter is too small to contain hash and seed")
+	}
+	hash := Hash(buf[2])
+	if !hash.valid() {
+		return Filter{}, internal.Err("unsupported filter hash '%d'; filter was "+
+			"built by an incompatible version", hash)
+	}
 	return Filter{
-		NumProbes: numProbes,
-		Data:      buf[2:],
+		NumProbes: numProbes &^ hashedFilterFlag,
+		Data:      buf[2+1+8:],
+		Hash:      hash,
+		Seed:      binary.BigEndian.Uint64(buf[3:]),
 	}, nil
 }
 
 type Builder struct {
 	keyHashes  []uint64
 	bitsPerKey uint32
+	hash       Hash
+	seed       uint64
 }
 
+// NewBuilder returns a Builder which hashes keys with HashFNV64 and a zero seed
 func NewBuilder(bitsPerKey uint32) *Builder {
+	return NewBuilderWithHash(bitsPerKey, HashFNV64, 0)
+}
+
+// NewBuilderWithHash returns a Builder which hashes keys with the provided hash and seed
+func NewBuilderWithHash(bitsPerKey uint32, hash Hash, seed uint64) *Builder {
 	return &Builder{
 		keyHashes:  make([]uint64, 0),
 		bitsPerKey: bitsPerKey,
+		hash:       hash,
+		seed:       seed,
 	}
 }
 
 // Add adds a new key to the bloom filter. This method
 // assumes the keys added are all unique.
 func (b *Builder) Add(key []byte) {
-	b.keyHashes = append(b.keyHashes, filterHash(key))
+	b.keyHashes = append(b.keyHashes, b.hash.Sum(key, b.seed))
 }
 
 // Build builds the bloom filter using enhanced double hashing
 func (b *Builder) Build() Filter {
 	if len(b.keyHashes) == 0 {
-		return Filter{}
+		return Filter{Hash: b.hash, Seed: b.seed}
 	}
 
 	numProbes := optimalNumProbes(b.bitsPerKey)
@@ -129,6 +232,8 @@ func (b *Builder) Build() Filter {
 	return Filter{
 		NumProbes: numProbes,
 		Data:      buf,
+		Hash:      b.hash,
+		Seed:      b.seed,
 	}
 }
 
@@ -138,12 +243,6 @@ func filterBytes(numKeys uint32, bitsPerKey uint32) uint64 {
 	return uint64((filterBits + 7) / 8)
 }
 
-func filterHash(key []byte) uint64 {
-	hash := fnv.New64()
-	hash.Write(key)
-	return hash.Sum64()
-}
-
 func probesForKey(keyHash uint64, numProbes uint16, filtrBits uint32) []uint32 {
 	// implements enhanced double hashing from:
 	// https://www.khoury.northeastern.edu/~pete/pub/bloom-filters-verification.pdf
@@ -174,5 +273,30 @@ func setBit(bit uint64, buf []byte) {
 func optimalNumProbes(bitsPerKey uint32) uint16 {
 	// bits_per_key * ln(2)
 	// https://en.wikipedia.org/wiki/Bloom_filter#Optimal_number_of_hash_functions
-	return uint16(float32(bitsPerKey) * 0.69)
+	return max(uint16(float32(bitsPerKey)*0.69), 1)
+}
+
+// FalsePositiveRate returns the expected false positive rate of a filter built with `bitsPerKey`
+func FalsePositiveRate(bitsPerKey uint32) float64 {
+	if bitsPerKey == 0 {
+		return 1
+	}
+	// (1 - e^(-k/b))^k, where k is the number of probes and b is the bits per key
+	// https://en.wikipedia.org/wiki/Bloom_filter#Probability_of_false_positives
+	k := float64(optimalNumProbes(bitsPerKey))
+	return math.Pow(1-math.Exp(-k/float64(bitsPerKey)), k)
+}
+
+// BitsPerKeyForFalsePositiveRate returns the smallest number of bits per key of a filter whose
+// expected false positive rate is at most `rate`, or zero if `rate` is not between 0 and 1 exclusive
+func BitsPerKeyForFalsePositiveRate(rate float64) uint32 {
+	if !(rate > 0 && rate < 1) {
+		return 0
+	}
+	// An estimate of -ln(rate) / ln(2)^2, which is adjusted for the whole number of probes
+	bitsPerKey := max(uint32(math.Ceil(-math.Log(rate)/(math.Ln2*math.Ln2))), 1)
+	for FalsePositiveRate(bitsPerKey) > rate {
+		bitsPerKey++
+	}
+	return bitsPerKey
 }
@@ -3,6 +3,7 @@ package bloom
 import (
 	"encoding/binary"
 	"fmt"
+	"hash/crc32"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -52,6 +53,65 @@ func TestEncodeDecode(t *testing.T) {
 	assert.Equal(t, filter.Data, decoded.Data)
 }
 
+func TestEncodeDecodeWithHash(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		hash Hash
+		seed uint64
+	}{
+		{name: "fnv64 with seed", hash: HashFNV64, seed: 42},
+		{name: "xxh64", hash: HashXXH64},
+		{name: "xxh64 with seed", hash: HashXXH64, seed: 42},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			fb := NewBuilderWithHash(10, tc.hash, tc.seed)
+			fb.Add([]byte("test1"))
+			fb.Add([]byte("test2"))
+			filter := fb.Build()
+
+			encoded, err := Encode(filter, compress.CodecNone)
+			require.NoError(t, err)
+			decoded, err := Decode(encoded, compress.CodecNone)
+			require.NoError(t, err)
+			assert.Equal(t, filter, decoded)
+			assert.True(t, decoded.HasKey([]byte("test1")))
+			assert.True(t, decoded.HasKey([]byte("test2")))
+			assert.False(t, decoded.HasKey([]byte("test3")))
+		})
+	}
+}
+
+func TestEncodeDefaultHashIsLegacyFormat(t *testing.T) {
+	fb := NewBuilder(10)
+	fb.Add([]byte("test1"))
+	filter := fb.Build()
+
+	encoded, err := Encode(filter, compress.CodecNone)
+	require.NoError(t, err)
+	assert.Equal(t, filter.NumProbes, binary.BigEndian.Uint16(encoded[:2]))
+	assert.Len(t, encoded, 2+len(filter.Data)+common.SizeOfUint32)
+}
+
+func TestDecodeUnsupportedHash(t *testing.T) {
+	fb := NewBuilderWithHash(10, HashXXH64, 0)
+	fb.Add([]byte("test1"))
+	filter := fb.Build()
+	filter.Hash = Hash(99)
+
+	_, err := Encode(filter, compress.CodecNone)
+	assert.ErrorContains(t, err, "unsupported filter hash")
+
+	// A filter written by a newer version with an unknown hash must not be used
+	filter.Hash = HashXXH64
+	encoded, err := Encode(filter, compress.CodecNone)
+	require.NoError(t, err)
+	encoded[2] = 99
+	binary.BigEndian.PutUint32(encoded[len(encoded)-4:], crc32.ChecksumIEEE(encoded[:len(encoded)-4]))
+
+	_, err = Decode(encoded, compress.CodecNone)
+	assert.ErrorContains(t, err, "unsupported filter hash")
+}
+
 func TestEmptyFilter(t *testing.T) {
 	fb := NewBuilder(10)
 	filter := fb.Build()
@@ -117,6 +177,21 @@ func TestFilterEffective(t *testing.T) {
 	assert.True(t, float32(fp)/float32(keysToTest) < 0.01)
 }
 
+func TestFalsePositiveRate(t *testing.T) {
+	assert.InDelta(t, 0.0084, FalsePositiveRate(10), 0.0001)
+	assert.Equal(t, float64(1), FalsePositiveRate(0))
+	assert.Greater(t, FalsePositiveRate(5), FalsePositiveRate(10))
+
+	assert.Equal(t, uint32(10), BitsPerKeyForFalsePositiveRate(0.01))
+	for _, rate := range []float64{0.5, 0.1, 0.01, 0.001, 0.0001} {
+		bitsPerKey := BitsPerKeyForFalsePositiveRate(rate)
+		assert.LessOrEqual(t, FalsePositiveRate(bitsPerKey), rate)
+		assert.Greater(t, FalsePositiveRate(bitsPerKey-1), rate)
+	}
+	assert.Equal(t, uint32(0), BitsPerKeyForFalsePositiveRate(0))
+	assert.Equal(t, uint32(0), BitsPerKeyForFalsePositiveRate(1))
+}
+
 func TestSetSpecifiedBitOnly(t *testing.T) {
 	cases := []struct {
 		buf      []byte
@@ -188,3 +263,21 @@ func TestComputeProbes(t *testing.T) {
 	}
 	assert.Equal(t, expected, probes)
 }
+
+func TestPrefixExtractors(t *testing.T) {
+	fixed := FixedPrefix(3)
+	assert.Equal(t, "fixed:3", fixed.Name())
+	prefix, ok := fixed.Prefix([]byte("abcdef"))
+	assert.True(t, ok)
+	assert.Equal(t, []byte("abc"), prefix)
+	_, ok = fixed.Prefix([]byte("ab"))
+	assert.False(t, ok)
+
+	delimited := DelimitedPrefix('/')
+	assert.Equal(t, "delimited:47", delimited.Name())
+	prefix, ok = delimited.Prefix([]byte("tenant1/user/1"))
+	assert.True(t, ok)
+	assert.Equal(t, []byte("tenant1/"), prefix)
+	_, ok = delimited.Prefix([]byte("tenant1"))
+	assert.False(t, ok)
+}
@@ -0,0 +1,61 @@
+package bloom
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// PrefixExtractor extracts the prefix of a key, such as the tenant of a tenant-scoped key. When
+// a PrefixExtractor is configured, the prefix of each key is added to the filter of an SSTable
+// along with the key itself, such that a scan of the keys of a prefix can skip the SSTables
+// which hold no key with the prefix.
+type PrefixExtractor interface {
+	// Name identifies the extractor, and is recorded in the SSTables whose filters hold the
+	// prefixes it extracted. Filters are only used for prefix scans if they were built by an
+	// extractor of the same name, so the name must change whenever the extracted prefixes do.
+	Name() string
+
+	// Prefix returns the prefix of `key`, or false if the key has no prefix. If Prefix returns
+	// the prefix P for a key, it must return P for every key which begins with P.
+	Prefix(key []byte) ([]byte, bool)
+}
+
+// FixedPrefix returns a PrefixExtractor whose prefix of a key is the first `n` bytes of the key.
+// Keys shorter than `n` bytes have no prefix.
+func FixedPrefix(n int) PrefixExtractor {
+	return fixedPrefix(n)
+}
+
+type fixedPrefix int
+
+func (f fixedPrefix) Name() string {
+	return fmt.Sprintf("fixed:%d", int(f))
+}
+
+func (f fixedPrefix) Prefix(key []byte) ([]byte, bool) {
+	if len(key) < int(f) {
+		return nil, false
+	}
+	return key[:f], true
+}
+
+// DelimitedPrefix returns a PrefixExtractor whose prefix of a key is the bytes of the key up to
+// and including the first occurrence of `delim`, such as "tenant1/" for the key "tenant1/user1"
+// with the delimiter '/'. Keys without the delimiter have no prefix.
+func DelimitedPrefix(delim byte) PrefixExtractor {
+	return delimitedPrefix(delim)
+}
+
+type delimitedPrefix byte
+
+func (d delimitedPrefix) Name() string {
+	return fmt.Sprintf("delimited:%d", byte(d))
+}
+
+func (d delimitedPrefix) Prefix(key []byte) ([]byte, bool) {
+	i := bytes.IndexByte(key, byte(d))
+	if i < 0 {
+		return nil, false
+	}
+	return key[:i+1], true
+}
@@ -3,6 +3,8 @@ package sstable
 import (
 	"bytes"
 	"encoding/binary"
+	"math"
+	"time"
 
 	"github.com/gammazero/deque"
 	"github.com/samber/mo"
@@ -10,6 +12,7 @@ import (
 	"github.com/slatedb/slatedb-go/internal/assert"
 	"github.com/slatedb/slatedb-go/internal/compress"
 	"github.com/slatedb/slatedb-go/internal/flatbuf"
+	"github.com/slatedb/slatedb-go/internal/profile"
 	"github.com/slatedb/slatedb-go/internal/sstable/block"
 	"github.com/slatedb/slatedb-go/internal/sstable/bloom"
 	"github.com/slatedb/slatedb-go/internal/types"
@@ -62,6 +65,11 @@ type Table struct {
 // |  +-----------------------------------------+  |
 // |  |  Checksum (4 bytes)                     |  |
 // |  +-----------------------------------------+  |
+// |  or, if the filter is partitioned             |
+// |  +-----------------------------------------+  |
+// |  |  List of bloom.Filter partitions        |  |
+// |  |  FilterIndex                            |  |
+// |  +-----------------------------------------+  |
 // |                                               |
 // |  +-----------------------------------------+  |
 // |  |  flatbuf.SsTableIndexT                  |  |
@@ -81,6 +89,7 @@ type Table struct {
 // |  |  - Offset of flatbuf.SsTableIndexT      |  |
 // |  |  - Length of flatbuf.SsTableIndexT      |  |
 // |  |  - The Compression Codec                |  |
+// |  |  - Range Tombstones                     |  |
 // |  +-----------------------------------------+  |
 // |  |  Checksum of SsTableInfoT (4 bytes)     |  |
 // |  +-----------------------------------------+  |
@@ -99,6 +108,9 @@ type Builder struct {
 	// firstKey is the first key of the first block in the SSTable
 	firstKey mo.Option[[]byte]
 
+	// rangeTombstones are written to the Info of the SSTable, see Info.RangeTombstones
+	rangeTombstones []types.RangeTombstone
+
 	// The encoded/serialized blocks that get added to the SSTable
 	blocks *deque.Deque[[]byte]
 
@@ -112,6 +124,30 @@ type Builder struct {
 
 	// config is the config options used to build the SSTable
 	conf Config
+
+	// partitions holds the finished partitions of the filter if the filter is partitioned,
+	// and partitionBlocks is the number of blocks added to the current partition.
+	// See Config.FilterPartitionBlocks
+	partitions      []filterPartition
+	partitionBlocks uint32
+	partitionKey    []byte
+
+	// lastPrefix is the prefix most recently added to the filter, see Config.PrefixExtractor
+	lastPrefix []byte
+
+	// inlineValues are the entries stored in the index, see Config.InlineValueBytes
+	inlineValues []*flatbuf.InlineValueT
+
+	// lastKey is the key most recently added, which is the last key of the SSTable once built
+	lastKey []byte
+
+	// numTombstones, rawKeySize and rawValueSize are recorded in the Info, see Properties
+	numTombstones uint64
+	rawKeySize    uint64
+	rawValueSize  uint64
+
+	// prof, if not nil, records the time spent encoding and compressing the SSTable
+	prof *profile.Profile
 }
 
 // Config specifies how SSTable is Encoded and Decoded
@@ -124,19 +160,69 @@ type Config struct {
 	// of items is faster than looking up in a bloom filter.
 	MinFilterKeys uint32
 
+	// FilterBitsPerKey is the number of bits per key of new bloom filters, which determines
+	// the false positive rate of the filter. See bloom.BitsPerKeyForFalsePositiveRate
 	FilterBitsPerKey uint32
 
+	// The hash and seed used to build new bloom filters. The hash and seed used by
+	// existing filters are encoded into the filter and used when reading the filter.
+	FilterHash bloom.Hash
+	FilterSeed uint64
+
+	// If not nil, the prefix of each key extracted by PrefixExtractor is added to the filter
+	// along with the key, and the name of the extractor is recorded in the Info of the SSTable
+	PrefixExtractor bloom.PrefixExtractor
+
 	// The codec used to compress new SSTables. The compression codec used in
 	// existing SSTables already written disk is encoded into the SSTableInfo and
 	// will be used when decompressing the blocks in that SSTable.
 	Compression compress.Codec
+
+	// The compression level used to compress the blocks of new SSTables, zero selects
+	// the default level of the codec. See compress.ValidLevel
+	CompressionLevel int
+
+	// If true, each block is only stored compressed if compression significantly
+	// reduces the size of the block. See block.EncodeWithFlag
+	AutoCompression bool
+
+	// If not zero, the filter is partitioned into a bloom filter of the keys of each
+	// FilterPartitionBlocks consecutive blocks, such that a point lookup reads the filter
+	// of the blocks which may hold the key rather than the filter of every key in the
+	// SSTable. SSTables with no more than FilterPartitionBlocks blocks have a single filter.
+	// See FilterIndex
+	FilterPartitionBlocks uint32
+
+	// If not zero, the keys of each block are stored relative to the previous key, with the
+	// full key stored every BlockRestartInterval rows at a restart point, see block.Block
+	BlockRestartInterval uint16
+
+	// If not zero, the newest entry of each key whose value is at most InlineValueBytes long,
+	// including tombstones, is also stored in the index, such that a point read of the key
+	// needs only the index rather than the index and a block. See Index.InlineEntry
+	InlineValueBytes uint32
+
+	// If true, each row is encoded with a checksum of the entire row, such that a corrupt or
+	// torn row is detected when it is read rather than decoded as garbage, see block.Row
+	EntryChecksums bool
+
+	// Now returns the time recorded as the creation time of new SSTables, see Info.CreatedAt.
+	// If nil, time.Now is used
+	Now func() time.Time
+}
+
+// filterPartition is a finished partition of a partitioned filter
+type filterPartition struct {
+	firstKey []byte
+	filter   bloom.Filter
+	encoded  []byte
 }
 
 // NewBuilder create a builder
 func NewBuilder(conf Config) *Builder {
 	return &Builder{
-		filterBuilder: bloom.NewBuilder(conf.FilterBitsPerKey),
-		blockBuilder:  block.NewBuilder(conf.BlockSize),
+		filterBuilder: bloom.NewBuilderWithHash(conf.FilterBitsPerKey, conf.FilterHash, conf.FilterSeed),
+		blockBuilder:  block.NewBuilderWithRestarts(conf.BlockSize, conf.BlockRestartInterval),
 		blocks:        deque.New[[]byte](0),
 		blockMetaList: []*flatbuf.BlockMetaT{},
 		firstKey:      mo.None[[]byte](),
@@ -146,6 +232,14 @@ func NewBuilder(conf Config) *Builder {
 	}
 }
 
+// now returns the creation time of the SSTable, see Config.Now
+func (b *Builder) now() time.Time {
+	if b.conf.Now != nil {
+		return b.conf.Now()
+	}
+	return time.Now()
+}
+
 func (b *Builder) AddValue(key []byte, value []byte) error {
 	// TODO(thrawn01): As of now, all of the code assumes if the value is missing it is
 	//  a tombstone. Once we implement transactions we should remove AddValue() method and
@@ -157,9 +251,27 @@ func (b *Builder) AddValue(key []byte, value []byte) error {
 	return b.Add(key, types.RowEntry{Value: types.Value{Value: value}})
 }
 
+// SetProfile sets the Profile which records the time spent encoding and compressing the
+// SSTable, see profile.StageEncode and profile.StageCompress
+func (b *Builder) SetProfile(p *profile.Profile) {
+	b.prof = p
+}
+
 func (b *Builder) Add(key []byte, entry types.RowEntry) error {
+	defer b.prof.Exit(b.prof.Enter(profile.StageEncode))
 	b.numKeys += 1
-	row := block.Row{Value: entry.Value}
+	b.rawKeySize += uint64(len(key))
+	b.rawValueSize += uint64(len(entry.Value.Value))
+	if entry.Value.IsTombstone() {
+		b.numTombstones++
+	}
+	row := block.Row{
+		Seq:           entry.Seq,
+		ExpireAt:      entry.ExpireAt,
+		Value:         entry.Value,
+		Checksum:      entry.Checksum,
+		EntryChecksum: b.conf.EntryChecksums,
+	}
 
 	if !b.blockBuilder.Add(key, row) {
 		// Create a new block builder and append block data
@@ -179,9 +291,35 @@ func (b *Builder) Add(key []byte, entry types.RowEntry) error {
 	}
 
 	b.filterBuilder.Add(key)
+	if b.conf.PrefixExtractor != nil {
+		// Keys are added in order, so the keys of a prefix are consecutive
+		if prefix, ok := b.conf.PrefixExtractor.Prefix(key); ok && !bytes.Equal(prefix, b.lastPrefix) {
+			b.filterBuilder.Add(prefix)
+			b.lastPrefix = prefix
+		}
+	}
+
+	if b.conf.InlineValueBytes > 0 {
+		// Versions of a key are added newest first, so only the first version is inlined
+		if !bytes.Equal(key, b.lastKey) && len(entry.Value.Value) <= int(b.conf.InlineValueBytes) &&
+			len(key) <= math.MaxUint16 {
+			entry.Key = key
+			b.inlineValues = append(b.inlineValues, &flatbuf.InlineValueT{
+				Key: bytes.Clone(key),
+				Row: block.EncodeInlineRow(entry),
+			})
+		}
+	}
+	b.lastKey = key
 	return nil
 }
 
+// AddRangeTombstone adds a range tombstone to the SSTable. Unlike entries, range
+// tombstones may be added in any order.
+func (b *Builder) AddRangeTombstone(tombstone types.RangeTombstone) {
+	b.rangeTombstones = append(b.rangeTombstones, tombstone)
+}
+
 func (b *Builder) NextBlock() mo.Option[[]byte] {
 	if b.blocks.Len() == 0 {
 		return mo.None[[]byte]()
@@ -195,13 +333,20 @@ func (b *Builder) finishBlock() ([]byte, error) {
 	}
 
 	blockBuilder := b.blockBuilder
-	b.blockBuilder = block.NewBuilder(b.conf.BlockSize)
+	b.blockBuilder = block.NewBuilderWithRestarts(b.conf.BlockSize, b.conf.BlockRestartInterval)
 	blk, err := blockBuilder.Build()
 	if err != nil {
 		return nil, err
 	}
 
-	buf, err := block.Encode(blk, b.conf.Compression)
+	prev := b.prof.Enter(profile.StageCompress)
+	buf, err := block.EncodeWithOptions(blk, block.Options{
+		Codec:           b.conf.Compression,
+		Level:           b.conf.CompressionLevel,
+		CompressionFlag: b.conf.AutoCompression,
+		Checksum:        block.ChecksumCRC32C,
+	})
+	b.prof.Exit(prev)
 	if err != nil {
 		return nil, err
 	}
@@ -209,10 +354,54 @@ func (b *Builder) finishBlock() ([]byte, error) {
 	blockMeta := flatbuf.BlockMetaT{Offset: b.currentLen, FirstKey: blk.FirstKey}
 	b.blockMetaList = append(b.blockMetaList, &blockMeta)
 
+	if b.conf.FilterPartitionBlocks > 0 {
+		if b.partitionBlocks == 0 {
+			b.partitionKey = blk.FirstKey
+		}
+		b.partitionBlocks++
+		if b.partitionBlocks == b.conf.FilterPartitionBlocks {
+			if err := b.finishPartition(); err != nil {
+				return nil, err
+			}
+		}
+	}
 	return buf, nil
 }
 
+// finishPartition builds the filter of the keys added since the previous partition was finished
+func (b *Builder) finishPartition() error {
+	filter := b.filterBuilder.Build()
+	b.filterBuilder = bloom.NewBuilderWithHash(b.conf.FilterBitsPerKey, b.conf.FilterHash, b.conf.FilterSeed)
+	prev := b.prof.Enter(profile.StageCompress)
+	encoded, err := bloom.Encode(filter, b.conf.Compression)
+	b.prof.Exit(prev)
+	if err != nil {
+		return err
+	}
+	b.partitions = append(b.partitions, filterPartition{firstKey: b.partitionKey, filter: filter, encoded: encoded})
+	b.partitionBlocks = 0
+	b.lastPrefix = nil
+	return nil
+}
+
+// encodePartitionedFilter encodes the finished partitions followed by their FilterIndex
+func (b *Builder) encodePartitionedFilter() ([]byte, int) {
+	var buf []byte
+	index := FilterIndex{Partitions: make([]FilterPartition, 0, len(b.partitions))}
+	for _, p := range b.partitions {
+		index.Partitions = append(index.Partitions, FilterPartition{
+			FirstKey: p.firstKey,
+			Offset:   uint64(len(buf)),
+			Len:      uint64(len(p.encoded)),
+		})
+		buf = append(buf, p.encoded...)
+	}
+	encodedIndex := encodeFilterIndex(index)
+	return append(buf, encodedIndex...), len(encodedIndex)
+}
+
 func (b *Builder) Build() (*Table, error) {
+	defer b.prof.Exit(b.prof.Enter(profile.StageEncode))
 	buf, err := b.finishBlock()
 	if err != nil {
 		return nil, err
@@ -220,22 +409,52 @@ func (b *Builder) Build() (*Table, error) {
 
 	// Write the filter if the total number of keys equals of exceeds minFilterKeys
 	maybeFilter := mo.None[bloom.Filter]()
-	filterLen := 0
+	filterLen, filterIndexLen, filterBitsPerKey := 0, 0, uint32(0)
+	prefixExtractor := ""
 	filterOffset := b.currentLen + uint64(len(buf))
 	if b.numKeys >= b.conf.MinFilterKeys {
-		filter := b.filterBuilder.Build()
-		encodedFilter, err := bloom.Encode(filter, b.conf.Compression)
-		if err != nil {
-			return nil, err
+		if b.partitionBlocks > 0 {
+			if err := b.finishPartition(); err != nil {
+				return nil, err
+			}
+		}
+
+		switch len(b.partitions) {
+		case 0:
+			filter := b.filterBuilder.Build()
+			prev := b.prof.Enter(profile.StageCompress)
+			encodedFilter, err := bloom.Encode(filter, b.conf.Compression)
+			b.prof.Exit(prev)
+			if err != nil {
+				return nil, err
+			}
+			filterLen = len(encodedFilter)
+			buf = append(buf, encodedFilter...)
+			maybeFilter = mo.Some(filter)
+		case 1:
+			// A single partition is stored as the filter of the SSTable
+			filterLen = len(b.partitions[0].encoded)
+			buf = append(buf, b.partitions[0].encoded...)
+			maybeFilter = mo.Some(b.partitions[0].filter)
+		default:
+			var encodedFilter []byte
+			encodedFilter, filterIndexLen = b.encodePartitionedFilter()
+			filterLen = len(encodedFilter)
+			buf = append(buf, encodedFilter...)
+		}
+		if filterLen > 0 {
+			filterBitsPerKey = b.conf.FilterBitsPerKey
+			if b.conf.PrefixExtractor != nil {
+				prefixExtractor = b.conf.PrefixExtractor.Name()
+			}
 		}
-		filterLen = len(encodedFilter)
-		buf = append(buf, encodedFilter...)
-		maybeFilter = mo.Some(filter)
 	}
 
 	// Compress and Write the index block
-	sstIndex := flatbuf.SsTableIndexT{BlockMeta: b.blockMetaList}
+	sstIndex := flatbuf.SsTableIndexT{BlockMeta: b.blockMetaList, InlineValues: b.inlineValues}
+	prev := b.prof.Enter(profile.StageCompress)
 	encodedIndex, err := encodeIndex(sstIndex, b.conf.Compression)
+	b.prof.Exit(prev)
 	if err != nil {
 		return nil, err
 	}
@@ -253,6 +472,20 @@ func (b *Builder) Build() (*Table, error) {
 		FilterOffset:     filterOffset,
 		FilterLen:        uint64(filterLen),
 		CompressionCodec: b.conf.Compression,
+
+		BlockCompressionFlags: b.conf.AutoCompression,
+		RangeTombstones:       b.rangeTombstones,
+		BlockChecksum:         block.ChecksumCRC32C,
+		FilterIndexLen:        uint64(filterIndexLen),
+		FilterBitsPerKey:      filterBitsPerKey,
+		FilterPrefixExtractor: prefixExtractor,
+		BlockRestartInterval:  b.conf.BlockRestartInterval,
+		LastKey:               bytes.Clone(b.lastKey),
+		NumEntries:            uint64(b.numKeys),
+		NumTombstones:         b.numTombstones,
+		RawKeySize:            b.rawKeySize,
+		RawValueSize:          b.rawValueSize,
+		CreatedAt:             time.UnixMilli(b.now().UnixMilli()),
 	}
 	buf = append(buf, EncodeInfo(sstInfo)...)
 
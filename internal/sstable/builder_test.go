@@ -3,6 +3,7 @@ package sstable_test
 import (
 	"context"
 	"fmt"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -12,6 +13,7 @@ import (
 	"github.com/slatedb/slatedb-go/internal/compress"
 	"github.com/slatedb/slatedb-go/internal/sstable"
 	"github.com/slatedb/slatedb-go/internal/sstable/block"
+	"github.com/slatedb/slatedb-go/internal/sstable/bloom"
 	"github.com/slatedb/slatedb-go/internal/types"
 	"github.com/slatedb/slatedb-go/slatedb/common"
 )
@@ -93,6 +95,136 @@ func TestBuilder(t *testing.T) {
 
 		assert.Equal(t, compress.CodecSnappy, table.Info.CompressionCodec)
 	})
+
+	t.Run("FilterBitsPerKey", func(t *testing.T) {
+		builder := sstable.NewBuilder(sstable.Config{
+			BlockSize:        4096,
+			MinFilterKeys:    0,
+			FilterBitsPerKey: 16,
+			Compression:      compress.CodecNone,
+		})
+		require.NoError(t, builder.AddValue([]byte("key1"), []byte("value1")))
+		table, err := builder.Build()
+		require.NoError(t, err)
+		assert.Equal(t, uint32(16), table.Info.FilterBitsPerKey)
+
+		info, err := sstable.ReadInfo(context.Background(), sstable.NewBytesBlob(sstable.EncodeTable(table)))
+		require.NoError(t, err)
+		assert.Equal(t, uint32(16), info.FilterBitsPerKey)
+
+		assert.Empty(t, info.FilterPrefixExtractor)
+
+		// SSTables without a filter do not record the bits per key
+		builder = sstable.NewBuilder(sstable.Config{
+			BlockSize:        4096,
+			MinFilterKeys:    10,
+			FilterBitsPerKey: 16,
+			Compression:      compress.CodecNone,
+		})
+		require.NoError(t, builder.AddValue([]byte("key1"), []byte("value1")))
+		table, err = builder.Build()
+		require.NoError(t, err)
+		assert.Equal(t, uint32(0), table.Info.FilterBitsPerKey)
+	})
+
+	t.Run("BlockRestartInterval", func(t *testing.T) {
+		ctx := context.Background()
+		builder := sstable.NewBuilder(sstable.Config{
+			BlockSize:            256,
+			MinFilterKeys:        10,
+			FilterBitsPerKey:     10,
+			Compression:          compress.CodecSnappy,
+			BlockRestartInterval: 4,
+		})
+		for i := 0; i < 100; i++ {
+			require.NoError(t, builder.AddValue([]byte(fmt.Sprintf("key%03d", i)), []byte(fmt.Sprintf("value%d", i))))
+		}
+		table, err := builder.Build()
+		require.NoError(t, err)
+		assert.Equal(t, uint16(4), table.Info.BlockRestartInterval)
+		assert.True(t, table.Blocks.Len() > 1, "Expected multiple blocks")
+
+		blob := sstable.NewBytesBlob(sstable.EncodeTable(table))
+		info, err := sstable.ReadInfo(ctx, blob)
+		require.NoError(t, err)
+		assert.Equal(t, uint16(4), info.BlockRestartInterval)
+
+		index, err := sstable.ReadIndex(ctx, info, blob)
+		require.NoError(t, err)
+		blocks, err := sstable.ReadBlocks(ctx, info, index, common.Range{Start: 0, End: uint64(index.BlockMetaLength())}, blob)
+		require.NoError(t, err)
+
+		i := 0
+		for _, b := range blocks {
+			it := block.NewIterator(&b)
+			for {
+				e, ok := it.NextEntry(ctx)
+				if !ok {
+					break
+				}
+				assert.Equal(t, fmt.Sprintf("key%03d", i), string(e.Key))
+				assert.Equal(t, fmt.Sprintf("value%d", i), string(e.Value.Value))
+				i++
+			}
+		}
+		assert.Equal(t, 100, i)
+	})
+}
+
+func TestPrefixFilter(t *testing.T) {
+	builder := sstable.NewBuilder(sstable.Config{
+		BlockSize:        4096,
+		MinFilterKeys:    0,
+		FilterBitsPerKey: 10,
+		PrefixExtractor:  bloom.FixedPrefix(2),
+		Compression:      compress.CodecNone,
+	})
+	for _, key := range []string{"aa1", "aa2", "ab1", "b"} {
+		require.NoError(t, builder.AddValue([]byte(key), []byte("value")))
+	}
+	table, err := builder.Build()
+	require.NoError(t, err)
+
+	info, err := sstable.ReadInfo(context.Background(), sstable.NewBytesBlob(sstable.EncodeTable(table)))
+	require.NoError(t, err)
+	assert.Equal(t, "fixed:2", info.FilterPrefixExtractor)
+
+	// The filter holds the keys along with their prefixes
+	filter, ok := table.Bloom.Get()
+	require.True(t, ok)
+	for _, key := range []string{"aa1", "aa2", "ab1", "b", "aa", "ab"} {
+		assert.True(t, filter.HasKey([]byte(key)), key)
+	}
+	assert.False(t, filter.HasKey([]byte("zz")))
+}
+
+func TestIndexConcurrentReads(t *testing.T) {
+	ctx := context.Background()
+	conf := sstable.DefaultConfig()
+	conf.BlockSize = 32
+	builder := sstable.NewBuilder(conf)
+	for i := 0; i < 10; i++ {
+		require.NoError(t, builder.AddValue([]byte(fmt.Sprintf("key%d", i)), []byte("value")))
+	}
+	table, err := builder.Build()
+	require.NoError(t, err)
+	blob := sstable.NewBytesBlob(sstable.EncodeTable(table))
+	info, err := sstable.ReadInfo(ctx, blob)
+	require.NoError(t, err)
+	index, err := sstable.ReadIndex(ctx, info, blob)
+	require.NoError(t, err)
+
+	// the index is decoded by the first of the readers, such as the prefetches of an iterator
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.Equal(t, index.BlockMetaLength(), len(index.BlockMeta()))
+		}()
+	}
+	wg.Wait()
+	assert.Greater(t, index.BlockMetaLength(), 1)
 }
 
 func TestEncodeDecode(t *testing.T) {
@@ -163,3 +295,158 @@ func TestEncodeDecode(t *testing.T) {
 	assert.True(t, f.HasKey([]byte("key2")))
 	assert.True(t, f.HasKey([]byte("key3")))
 }
+
+func TestPartitionedFilter(t *testing.T) {
+	ctx := context.Background()
+	newBuilder := func() *sstable.Builder {
+		return sstable.NewBuilder(sstable.Config{
+			BlockSize:             128,
+			MinFilterKeys:         0,
+			FilterBitsPerKey:      10,
+			FilterPartitionBlocks: 2,
+			Compression:           compress.CodecNone,
+		})
+	}
+
+	t.Run("Partitioned", func(t *testing.T) {
+		builder := newBuilder()
+		var keys [][]byte
+		for i := 0; i < 100; i++ {
+			key := []byte(fmt.Sprintf("key%03d", i))
+			keys = append(keys, key)
+			require.NoError(t, builder.AddValue(key, []byte(fmt.Sprintf("value%03d", i))))
+		}
+		table, err := builder.Build()
+		require.NoError(t, err)
+		assert.True(t, table.Blocks.Len() > 4, "Expected multiple partitions")
+		assert.True(t, table.Info.FilterIndexLen > 0)
+		assert.False(t, table.Bloom.IsPresent())
+
+		blob := sstable.NewBytesBlob(sstable.EncodeTable(table))
+		info, err := sstable.ReadInfo(ctx, blob)
+		require.NoError(t, err)
+		assert.Equal(t, table.Info.FilterIndexLen, info.FilterIndexLen)
+
+		// A partitioned filter is not read as a monolithic filter
+		filter, err := sstable.ReadFilter(ctx, info, blob)
+		require.NoError(t, err)
+		assert.False(t, filter.IsPresent())
+
+		index, err := sstable.ReadFilterIndex(ctx, info, blob)
+		require.NoError(t, err)
+		assert.True(t, len(index.Partitions) > 1)
+		assert.Equal(t, keys[0], index.Partitions[0].FirstKey)
+
+		for _, key := range keys {
+			i, ok := index.PartitionForKey(key)
+			require.True(t, ok)
+			f, err := sstable.ReadFilterPartition(ctx, info, index.Partitions[i], blob)
+			require.NoError(t, err)
+			assert.True(t, f.HasKey(key), "partition '%d' should have key '%s'", i, key)
+		}
+		_, ok := index.PartitionForKey([]byte("a"))
+		assert.False(t, ok)
+
+		all, filters, err := sstable.ReadFilterPartitions(ctx, info, blob)
+		require.NoError(t, err)
+		assert.Len(t, filters, len(all.Partitions))
+	})
+
+	t.Run("SinglePartition", func(t *testing.T) {
+		builder := newBuilder()
+		require.NoError(t, builder.AddValue([]byte("key1"), []byte("value1")))
+		require.NoError(t, builder.AddValue([]byte("key2"), []byte("value2")))
+		table, err := builder.Build()
+		require.NoError(t, err)
+		assert.Equal(t, uint64(0), table.Info.FilterIndexLen)
+		assert.True(t, table.Bloom.IsPresent())
+	})
+
+	t.Run("CorruptedIndex", func(t *testing.T) {
+		index, err := sstable.DecodeFilterIndex([]byte{0, 0, 0, 1, 0, 0, 0, 0})
+		assert.Nil(t, index)
+		assert.ErrorContains(t, err, "checksum mismatch")
+	})
+}
+
+// blobStore is a TableStore which reads the blocks of an SSTable from a blob. If the blob is
+// nil, reading a block fails.
+type blobStore struct {
+	index *sstable.Index
+	blob  common.ReadOnlyBlob
+}
+
+func (s blobStore) ReadIndex(context.Context, *sstable.Handle) (*sstable.Index, error) {
+	return s.index, nil
+}
+
+func (s blobStore) ReadBlocksUsingIndex(ctx context.Context, handle *sstable.Handle, r common.Range,
+	index *sstable.Index) ([]block.Block, error) {
+	if s.blob == nil {
+		return nil, fmt.Errorf("blocks are not stored")
+	}
+	return sstable.ReadBlocks(ctx, handle.Info, index, r, s.blob)
+}
+
+func (s blobStore) ReadAheadBytes() uint64 { return 0 }
+
+func (s blobStore) PrefetchConcurrency() int { return 0 }
+
+func TestInlineValues(t *testing.T) {
+	ctx := context.Background()
+	builder := sstable.NewBuilder(sstable.Config{
+		BlockSize:        4096,
+		MinFilterKeys:    10,
+		FilterBitsPerKey: 10,
+		Compression:      compress.CodecNone,
+		InlineValueBytes: 8,
+	})
+	require.NoError(t, builder.Add([]byte("key1"), types.RowEntry{Value: types.Value{Value: []byte("new")}, Seq: 2}))
+	require.NoError(t, builder.Add([]byte("key1"), types.RowEntry{Value: types.Value{Value: []byte("old")}, Seq: 1}))
+	require.NoError(t, builder.Add([]byte("key2"), types.RowEntry{Value: types.Value{Kind: types.KindTombStone}, Seq: 3}))
+	require.NoError(t, builder.Add([]byte("key3"), types.RowEntry{Value: types.Value{Value: []byte("too large value")}, Seq: 4}))
+	require.NoError(t, builder.Add([]byte("key4"), types.RowEntry{Value: types.Value{Value: []byte("small")}, Seq: 5}))
+	table, err := builder.Build()
+	require.NoError(t, err)
+
+	blob := sstable.NewBytesBlob(sstable.EncodeTable(table))
+	index, err := sstable.ReadIndex(ctx, table.Info, blob)
+	require.NoError(t, err)
+
+	entry, ok, err := index.InlineEntry([]byte("key1"))
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, []byte("key1"), entry.Key)
+	assert.Equal(t, []byte("new"), entry.Value.Value)
+	assert.Equal(t, uint64(2), entry.Seq)
+
+	entry, ok, err = index.InlineEntry([]byte("key2"))
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.True(t, entry.Value.IsTombstone())
+
+	for _, key := range []string{"key0", "key3", "key5"} {
+		_, ok, err = index.InlineEntry([]byte(key))
+		require.NoError(t, err)
+		assert.False(t, ok, "key '%s' should not be inlined", key)
+	}
+	assert.Len(t, index.BlockMeta(), 1)
+
+	// The inlined entry is returned without reading a block
+	handle := sstable.NewHandle(sstable.NewIDWal(1), table.Info)
+	it, err := sstable.NewPointIterator(ctx, handle, []byte("key4"), blobStore{index: index})
+	require.NoError(t, err)
+	entry, ok = it.NextEntry(ctx)
+	require.True(t, ok)
+	assert.Equal(t, []byte("small"), entry.Value.Value)
+	_, ok = it.NextEntry(ctx)
+	assert.False(t, ok)
+	assert.False(t, it.Warnings().Empty())
+
+	// A key which is not inlined is read from its block
+	it, err = sstable.NewPointIterator(ctx, handle, []byte("key3"), blobStore{index: index, blob: blob})
+	require.NoError(t, err)
+	entry, ok = it.NextEntry(ctx)
+	require.True(t, ok)
+	assert.Equal(t, []byte("too large value"), entry.Value.Value)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/binary"
 	"fmt"
+	"io"
 
 	"github.com/samber/mo"
 	"github.com/slatedb/slatedb-go/internal"
@@ -47,8 +48,11 @@ func ReadInfo(ctx context.Context, obj common.ReadOnlyBlob) (*Info, error) {
 	return DecodeInfo(metadataBytes)
 }
 
+// ReadFilter reads the bloom filter of the SSTable. None is returned if the SSTable does not have
+// a filter, or if its filter is partitioned, in which case the partition which may hold a key is
+// read with ReadFilterIndex and ReadFilterPartition.
 func ReadFilter(ctx context.Context, sstInfo *Info, obj common.ReadOnlyBlob) (mo.Option[bloom.Filter], error) {
-	if sstInfo.FilterLen < 1 {
+	if sstInfo.FilterLen < 1 || sstInfo.FilterIndexLen > 0 {
 		return mo.None[bloom.Filter](), nil
 	}
 
@@ -105,6 +109,25 @@ func getBlockRange(rng common.Range, sstInfo *Info, index *Index) common.Range {
 // ReadBlocks reads the complete data required into a byte slice (dataBytes)
 // and then breaks the data up into slice of Blocks (decodedBlocks) which is returned
 func ReadBlocks(ctx context.Context, info *Info, index *Index, r common.Range, obj common.ReadOnlyBlob) ([]block.Block, error) {
+	encodedBlocks, err := ReadEncodedBlocks(ctx, info, index, r, obj)
+	if err != nil {
+		return nil, err
+	}
+
+	decodedBlocks := make([]block.Block, 0, len(encodedBlocks))
+	for i, encoded := range encodedBlocks {
+		var decodedBlock block.Block
+		if err := DecodeBlock(&decodedBlock, encoded, info); err != nil {
+			return nil, fmt.Errorf("while decoding block '%d': %w", r.Start+uint64(i), err)
+		}
+		decodedBlocks = append(decodedBlocks, decodedBlock)
+	}
+	return decodedBlocks, nil
+}
+
+// ReadEncodedBlocks reads the blocks within r with a single range request, and returns the
+// encoded bytes of each block without decoding them. See DecodeBlock
+func ReadEncodedBlocks(ctx context.Context, info *Info, index *Index, r common.Range, obj common.ReadOnlyBlob) ([][]byte, error) {
 	if r.Start >= r.End {
 		return nil, fmt.Errorf("block start '%d' range cannot be greater than end range '%d'", r.Start, r.End)
 	}
@@ -113,48 +136,84 @@ func ReadBlocks(ctx context.Context, info *Info, index *Index, r common.Range, o
 			r.End, index.BlockMetaLength())
 	}
 
-	if r.Start == r.End {
-		return []block.Block{}, nil
-	}
-
 	rng := getBlockRange(r, info, index)
 	dataBytes, err := obj.ReadRange(ctx, rng)
 	if err != nil {
 		return nil, fmt.Errorf("while reading block range [%d:%d]: %w", rng.Start, rng.End, err)
 	}
 
+	// An object store may return fewer bytes than requested, such as for a truncated object.
+	// The blocks are clamped to the bytes returned, such that the checksum of a truncated block
+	// fails when it is decoded.
+	size := uint64(len(dataBytes))
 	startOffset := rng.Start
-	var decodedBlocks []block.Block
+	encodedBlocks := make([][]byte, 0, r.End-r.Start)
 	blockMetaList := index.BlockMeta()
-	compressionCodec := info.CompressionCodec
-
 	for i := r.Start; i < r.End; i++ {
-		bytesStart := blockMetaList[i].Offset - startOffset
-		var blockBytes []byte
+		bytesStart := min(blockMetaList[i].Offset-startOffset, size)
 		if i == uint64(index.BlockMetaLength())-1 {
-			blockBytes = dataBytes[bytesStart:]
+			encodedBlocks = append(encodedBlocks, dataBytes[bytesStart:])
 		} else {
-			bytesEnd := blockMetaList[i+1].Offset - startOffset
-			blockBytes = dataBytes[bytesStart:bytesEnd]
+			bytesEnd := min(blockMetaList[i+1].Offset-startOffset, size)
+			encodedBlocks = append(encodedBlocks, dataBytes[bytesStart:bytesEnd])
 		}
+	}
+	return encodedBlocks, nil
+}
 
-		var decodedBlock block.Block
-		if err := block.Decode(&decodedBlock, blockBytes, compressionCodec); err != nil {
-			return nil, fmt.Errorf("while decoding block '%d' data[%d:%d]: %w",
-				i, bytesStart, int(bytesStart)+len(blockBytes), err)
+// StreamBlocks reads the blocks within r from the reader returned by `open` for the byte range of
+// the blocks, calling `decode` with the encoded bytes of each block as soon as they are read rather
+// than once the whole range is read. Unlike ReadBlocks, a range which is shorter than expected,
+// such as that of a truncated object, is an error.
+func StreamBlocks(ctx context.Context, info *Info, index *Index, r common.Range,
+	open func(context.Context, common.Range) (io.ReadCloser, error),
+	decode func(b *block.Block, encoded []byte, blockIndex uint64) error) ([]block.Block, error) {
+	if r.Start >= r.End {
+		return nil, fmt.Errorf("block start '%d' range cannot be greater than end range '%d'", r.Start, r.End)
+	}
+	if r.End > uint64(index.BlockMetaLength()) {
+		return nil, fmt.Errorf("block end '%d' range cannot be greater than size of block meta range '%d'",
+			r.End, index.BlockMetaLength())
+	}
+
+	rng := getBlockRange(r, info, index)
+	reader, err := open(ctx, rng)
+	if err != nil {
+		return nil, fmt.Errorf("while reading block range [%d:%d]: %w", rng.Start, rng.End, err)
+	}
+	defer func() { _ = reader.Close() }()
+
+	blocks := make([]block.Block, r.End-r.Start)
+	for i := r.Start; i < r.End; i++ {
+		blockRange := getBlockRange(common.Range{Start: i, End: i + 1}, info, index)
+		encoded := make([]byte, blockRange.End-blockRange.Start)
+		if _, err := io.ReadFull(reader, encoded); err != nil {
+			return nil, fmt.Errorf("while reading block '%d': %w", i, err)
+		}
+		if err := decode(&blocks[i-r.Start], encoded, i); err != nil {
+			return nil, err
 		}
-		decodedBlocks = append(decodedBlocks, decodedBlock)
 	}
-	return decodedBlocks, nil
+	return blocks, nil
 }
 
 func ReadBlockRaw(info *Info, index *Index, blockIndex uint64, sstBytes []byte) (*block.Block, error) {
 	blockRange := getBlockRange(common.Range{Start: blockIndex, End: blockIndex + 1}, info, index)
 
 	var blk block.Block
-	if err := block.Decode(&blk, sstBytes[blockRange.Start:blockRange.End], info.CompressionCodec); err != nil {
+	if err := DecodeBlock(&blk, sstBytes[blockRange.Start:blockRange.End], info); err != nil {
 		return nil, fmt.Errorf("while decoding block '%d' data[%d:%d]: %w",
 			blockIndex, blockRange.Start, blockRange.End, err)
 	}
 	return &blk, nil
 }
+
+// DecodeBlock decodes a block read by ReadEncodedBlocks into the provided Block
+func DecodeBlock(b *block.Block, input []byte, info *Info) error {
+	return block.DecodeWithOptions(b, input, block.Options{
+		Codec:           info.CompressionCodec,
+		CompressionFlag: info.BlockCompressionFlags,
+		Checksum:        info.BlockChecksum,
+		RestartInterval: info.BlockRestartInterval,
+	})
+}
@@ -0,0 +1,181 @@
+package sstable
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"sort"
+
+	"github.com/slatedb/slatedb-go/internal"
+	"github.com/slatedb/slatedb-go/internal/sstable/bloom"
+	"github.com/slatedb/slatedb-go/slatedb/common"
+)
+
+// FilterPartition locates a partition of a partitioned filter. Each partition is a bloom.Filter
+// of the keys of a range of consecutive blocks.
+type FilterPartition struct {
+	// FirstKey is the first key of the first block of the partition
+	FirstKey []byte
+
+	// Offset is the offset of the partition from Info.FilterOffset
+	Offset uint64
+
+	// Len is the length of the encoded partition
+	Len uint64
+}
+
+// FilterIndex is the index of a partitioned filter, which is stored at the end of the filter
+// after the partitions. A point lookup reads the index, and then only the partition which may
+// hold the key, rather than the filter of every key in the SSTable. See Config.FilterPartitionBlocks
+//
+// The FilterIndex is encoded using binary.BigEndian in the following format:
+//
+// +-----------------------------------------------+
+// |               Filter Index                    |
+// +-----------------------------------------------+
+// |  +-----------------------------------------+  |
+// |  |  Number of Partitions (4 bytes)         |  |
+// |  +-----------------------------------------+  |
+// |  |  List of Partitions                     |  |
+// |  |  +-----------------------------------+  |  |
+// |  |  |  Offset (8 bytes)                 |  |  |
+// |  |  |  Length (4 bytes)                 |  |  |
+// |  |  |  First Key Length (2 bytes)       |  |  |
+// |  |  |  First Key                        |  |  |
+// |  |  +-----------------------------------+  |  |
+// |  |  ...                                    |  |
+// |  +-----------------------------------------+  |
+// |  |  Checksum (4 bytes)                     |  |
+// |  +-----------------------------------------+  |
+// +-----------------------------------------------+
+type FilterIndex struct {
+	// Partitions are ordered by their FirstKey
+	Partitions []FilterPartition
+}
+
+// PartitionForKey returns the index of the partition which holds `key` if the SSTable holds
+// it, or false if `key` is before the first key of the SSTable
+func (fi *FilterIndex) PartitionForKey(key []byte) (int, bool) {
+	// The index of the first partition whose first key is greater than the key
+	i := sort.Search(len(fi.Partitions), func(i int) bool {
+		return bytes.Compare(fi.Partitions[i].FirstKey, key) > 0
+	})
+	if i == 0 {
+		return 0, false
+	}
+	return i - 1, true
+}
+
+func encodeFilterIndex(index FilterIndex) []byte {
+	buf := binary.BigEndian.AppendUint32(nil, uint32(len(index.Partitions)))
+	for _, p := range index.Partitions {
+		buf = binary.BigEndian.AppendUint64(buf, p.Offset)
+		buf = binary.BigEndian.AppendUint32(buf, uint32(p.Len))
+		buf = binary.BigEndian.AppendUint16(buf, uint16(len(p.FirstKey)))
+		buf = append(buf, p.FirstKey...)
+	}
+	return binary.BigEndian.AppendUint32(buf, crc32.ChecksumIEEE(buf))
+}
+
+// DecodeFilterIndex decodes the FilterIndex from the provided byte slice
+func DecodeFilterIndex(buf []byte) (*FilterIndex, error) {
+	if len(buf) < 2*common.SizeOfUint32 {
+		return nil, internal.Err("corrupted filter index; too short")
+	}
+	checksumIndex := len(buf) - common.SizeOfUint32
+	if binary.BigEndian.Uint32(buf[checksumIndex:]) != crc32.ChecksumIEEE(buf[:checksumIndex]) {
+		return nil, internal.Err("corrupted filter index; checksum mismatch")
+	}
+
+	data := buf[:checksumIndex]
+	numPartitions := binary.BigEndian.Uint32(data)
+	data = data[common.SizeOfUint32:]
+	index := &FilterIndex{Partitions: make([]FilterPartition, 0, numPartitions)}
+	for i := uint32(0); i < numPartitions; i++ {
+		if len(data) < 8+common.SizeOfUint32+common.SizeOfUint16 {
+			return nil, internal.Err("corrupted filter index; partition '%d' is truncated", i)
+		}
+		p := FilterPartition{
+			Offset: binary.BigEndian.Uint64(data),
+			Len:    uint64(binary.BigEndian.Uint32(data[8:])),
+		}
+		keyLen := int(binary.BigEndian.Uint16(data[8+common.SizeOfUint32:]))
+		data = data[8+common.SizeOfUint32+common.SizeOfUint16:]
+		if len(data) < keyLen {
+			return nil, internal.Err("corrupted filter index; partition '%d' is truncated", i)
+		}
+		p.FirstKey = bytes.Clone(data[:keyLen])
+		data = data[keyLen:]
+		index.Partitions = append(index.Partitions, p)
+	}
+	if len(data) != 0 {
+		return nil, internal.Err("corrupted filter index; '%d' trailing bytes", len(data))
+	}
+	return index, nil
+}
+
+// ReadFilterIndex reads the FilterIndex of an SSTable with a partitioned filter
+func ReadFilterIndex(ctx context.Context, info *Info, obj common.ReadOnlyBlob) (*FilterIndex, error) {
+	if info.FilterIndexLen == 0 || info.FilterIndexLen > info.FilterLen {
+		return nil, internal.Err("sstable does not have a partitioned filter")
+	}
+	end := info.FilterOffset + info.FilterLen
+	buf, err := obj.ReadRange(ctx, common.Range{Start: end - info.FilterIndexLen, End: end})
+	if err != nil {
+		return nil, fmt.Errorf("while reading filter index: %w", err)
+	}
+	return DecodeFilterIndex(buf)
+}
+
+// ReadFilterPartition reads the partition `p` of the partitioned filter of an SSTable
+func ReadFilterPartition(ctx context.Context, info *Info, p FilterPartition, obj common.ReadOnlyBlob) (bloom.Filter, error) {
+	if p.Offset+p.Len > info.FilterLen-info.FilterIndexLen {
+		return bloom.Filter{}, internal.Err("corrupted filter index; partition is beyond the end of the filter")
+	}
+	start := info.FilterOffset + p.Offset
+	buf, err := obj.ReadRange(ctx, common.Range{Start: start, End: start + p.Len})
+	if err != nil {
+		return bloom.Filter{}, fmt.Errorf("while reading filter partition: %w", err)
+	}
+	return bloom.Decode(buf, info.CompressionCodec)
+}
+
+// ReadFilterPartitions reads the FilterIndex and every partition of the partitioned filter of an
+// SSTable with a single range request, such as to verify the filter
+func ReadFilterPartitions(ctx context.Context, info *Info, obj common.ReadOnlyBlob) (*FilterIndex, []bloom.Filter, error) {
+	if info.FilterIndexLen == 0 || info.FilterIndexLen > info.FilterLen {
+		return nil, nil, internal.Err("sstable does not have a partitioned filter")
+	}
+	buf, err := obj.ReadRange(ctx, common.Range{Start: info.FilterOffset, End: info.FilterOffset + info.FilterLen})
+	if err != nil {
+		return nil, nil, fmt.Errorf("while reading filter: %w", err)
+	}
+	return DecodeFilterPartitions(buf, info)
+}
+
+// DecodeFilterPartitions decodes the FilterIndex and every partition of the partitioned filter
+// `buf` of an SSTable, which is the range of Info.FilterOffset and Info.FilterLen
+func DecodeFilterPartitions(buf []byte, info *Info) (*FilterIndex, []bloom.Filter, error) {
+	if info.FilterIndexLen == 0 || info.FilterIndexLen > uint64(len(buf)) {
+		return nil, nil, internal.Err("corrupted filter; filter index is beyond the end of the filter")
+	}
+	partitionsLen := uint64(len(buf)) - info.FilterIndexLen
+	index, err := DecodeFilterIndex(buf[partitionsLen:])
+	if err != nil {
+		return nil, nil, err
+	}
+	filters := make([]bloom.Filter, 0, len(index.Partitions))
+	for i, p := range index.Partitions {
+		if p.Offset+p.Len > partitionsLen {
+			return nil, nil, internal.Err("corrupted filter index; partition '%d' is beyond the end of the filter", i)
+		}
+		f, err := bloom.Decode(buf[p.Offset:p.Offset+p.Len], info.CompressionCodec)
+		if err != nil {
+			return nil, nil, fmt.Errorf("while decoding filter partition '%d': %w", i, err)
+		}
+		filters = append(filters, f)
+	}
+	return index, filters, nil
+}
@@ -4,37 +4,82 @@ import (
 	"bytes"
 	"encoding/binary"
 	"hash/crc32"
+	"sort"
+	"sync"
+	"time"
 
 	flatbuffers "github.com/google/flatbuffers/go"
 	"github.com/slatedb/slatedb-go/internal"
 	"github.com/slatedb/slatedb-go/internal/compress"
 	"github.com/slatedb/slatedb-go/internal/flatbuf"
+	"github.com/slatedb/slatedb-go/internal/sstable/block"
+	"github.com/slatedb/slatedb-go/internal/types"
 	"github.com/slatedb/slatedb-go/slatedb/common"
 )
 
 type Index struct {
 	Data []byte
 
-	blockMetaT   []*flatbuf.BlockMetaT
+	// The index is decoded lazily, once, such that it may be read concurrently, such as by the
+	// prefetches of an Iterator
+	indexOnce    sync.Once
 	sstableIndex *flatbuf.SsTableIndex
+	metaOnce     sync.Once
+	blockMetaT   []*flatbuf.BlockMetaT
+}
+
+// root returns the decoded root of the index
+func (info *Index) root() *flatbuf.SsTableIndex {
+	info.indexOnce.Do(func() {
+		info.sstableIndex = flatbuf.GetRootAsSsTableIndex(info.Data, 0)
+	})
+	return info.sstableIndex
 }
 
 func (info *Index) BlockMeta() []*flatbuf.BlockMetaT {
-	// Return the cached result if available
-	if info.blockMetaT != nil {
-		return info.blockMetaT
-	}
-	info.sstableIndex = flatbuf.GetRootAsSsTableIndex(info.Data, 0)
-	info.blockMetaT = info.sstableIndex.UnPack().BlockMeta
+	info.metaOnce.Do(func() {
+		sstableIndex := info.root()
+		// Only unpack the block metas, the inline values are looked up by InlineEntry
+		metas := make([]*flatbuf.BlockMetaT, sstableIndex.BlockMetaLength())
+		for i := range metas {
+			var meta flatbuf.BlockMeta
+			sstableIndex.BlockMeta(&meta, i)
+			metas[i] = meta.UnPack()
+		}
+		info.blockMetaT = metas
+	})
 	return info.blockMetaT
 }
 
-func (info *Index) BlockMetaLength() int {
-	if info.sstableIndex != nil {
-		return info.sstableIndex.BlockMetaLength()
+// InlineEntry returns the newest entry of the key if its value is inlined into the index,
+// see Config.InlineValueBytes
+func (info *Index) InlineEntry(key []byte) (types.RowEntry, bool, error) {
+	sstableIndex := info.root()
+	n := sstableIndex.InlineValuesLength()
+	if n == 0 {
+		return types.RowEntry{}, false, nil
 	}
-	info.sstableIndex = flatbuf.GetRootAsSsTableIndex(info.Data, 0)
-	return info.sstableIndex.BlockMetaLength()
+	var inline flatbuf.InlineValue
+	i := sort.Search(n, func(i int) bool {
+		sstableIndex.InlineValues(&inline, i)
+		return bytes.Compare(inline.KeyBytes(), key) >= 0
+	})
+	if i == n {
+		return types.RowEntry{}, false, nil
+	}
+	sstableIndex.InlineValues(&inline, i)
+	if !bytes.Equal(inline.KeyBytes(), key) {
+		return types.RowEntry{}, false, nil
+	}
+	entry, err := block.DecodeInlineRow(inline.RowBytes())
+	if err != nil {
+		return types.RowEntry{}, false, err
+	}
+	return entry, true, nil
+}
+
+func (info *Index) BlockMetaLength() int {
+	return info.root().BlockMetaLength()
 }
 
 func (info *Index) Clone() *Index {
@@ -54,7 +99,62 @@ func SstInfoToFlatBuf(info *Info) *flatbuf.SsTableInfoT {
 		FilterOffset:      info.FilterOffset,
 		FilterLen:         info.FilterLen,
 		CompressionFormat: compress.CodecToFlatBuf(info.CompressionCodec),
+
+		BlockCompressionFlags: info.BlockCompressionFlags,
+		RangeTombstones:       RangeTombstonesToFlatBuf(info.RangeTombstones),
+		IngestSeq:             info.IngestSeq,
+		BlockChecksum:         byte(info.BlockChecksum),
+		FilterIndexLen:        info.FilterIndexLen,
+		FilterBitsPerKey:      info.FilterBitsPerKey,
+		FilterPrefixExtractor: info.FilterPrefixExtractor,
+		BlockRestartInterval:  info.BlockRestartInterval,
+		LastKey:               bytes.Clone(info.LastKey),
+		NumEntries:            info.NumEntries,
+		NumTombstones:         info.NumTombstones,
+		RawKeySize:            info.RawKeySize,
+		RawValueSize:          info.RawValueSize,
+		CreationTimeMs:        CreationTimeToFlatBuf(info.CreatedAt),
+	}
+}
+
+// CreationTimeToFlatBuf returns Info.CreatedAt as milliseconds since the Unix epoch, or
+// zero if it is not set
+func CreationTimeToFlatBuf(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixMilli()
+}
+
+// CreationTimeFromFlatBuf returns Info.CreatedAt from milliseconds since the Unix epoch,
+// or the zero time if `ms` is zero
+func CreationTimeFromFlatBuf(ms int64) time.Time {
+	if ms == 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(ms)
+}
+
+func RangeTombstonesToFlatBuf(tombstones []types.RangeTombstone) []*flatbuf.RangeTombstoneT {
+	if len(tombstones) == 0 {
+		return nil
+	}
+	result := make([]*flatbuf.RangeTombstoneT, 0, len(tombstones))
+	for _, t := range tombstones {
+		result = append(result, &flatbuf.RangeTombstoneT{Start: bytes.Clone(t.Start), End: bytes.Clone(t.End), Seq: t.Seq})
 	}
+	return result
+}
+
+func RangeTombstonesFromFlatBuf(tombstones []*flatbuf.RangeTombstoneT) []types.RangeTombstone {
+	if len(tombstones) == 0 {
+		return nil
+	}
+	result := make([]types.RangeTombstone, 0, len(tombstones))
+	for _, t := range tombstones {
+		result = append(result, types.RangeTombstone{Start: bytes.Clone(t.Start), End: bytes.Clone(t.End), Seq: t.Seq})
+	}
+	return result
 }
 
 // EncodeInfo encodes the provided Info into flatbuf.SsTableInfoT flat []byte
@@ -63,6 +163,15 @@ func EncodeInfo(info *Info) []byte {
 	// Encode the Info struct as flatbuf.SsTableInfoT
 	builder := flatbuffers.NewBuilder(0)
 	firstKey := builder.CreateByteVector(info.FirstKey)
+	rangeTombstones := encodeRangeTombstones(builder, info.RangeTombstones)
+	var prefixExtractor flatbuffers.UOffsetT
+	if info.FilterPrefixExtractor != "" {
+		prefixExtractor = builder.CreateString(info.FilterPrefixExtractor)
+	}
+	var lastKey flatbuffers.UOffsetT
+	if info.LastKey != nil {
+		lastKey = builder.CreateByteVector(info.LastKey)
+	}
 
 	flatbuf.SsTableInfoStart(builder)
 	flatbuf.SsTableInfoAddFirstKey(builder, firstKey)
@@ -71,6 +180,25 @@ func EncodeInfo(info *Info) []byte {
 	flatbuf.SsTableInfoAddFilterOffset(builder, info.FilterOffset)
 	flatbuf.SsTableInfoAddFilterLen(builder, info.FilterLen)
 	flatbuf.SsTableInfoAddCompressionFormat(builder, flatbuf.CompressionCodec(info.CompressionCodec))
+	flatbuf.SsTableInfoAddBlockCompressionFlags(builder, info.BlockCompressionFlags)
+	if len(info.RangeTombstones) != 0 {
+		flatbuf.SsTableInfoAddRangeTombstones(builder, rangeTombstones)
+	}
+	flatbuf.SsTableInfoAddBlockChecksum(builder, byte(info.BlockChecksum))
+	flatbuf.SsTableInfoAddFilterIndexLen(builder, info.FilterIndexLen)
+	flatbuf.SsTableInfoAddFilterBitsPerKey(builder, info.FilterBitsPerKey)
+	if info.FilterPrefixExtractor != "" {
+		flatbuf.SsTableInfoAddFilterPrefixExtractor(builder, prefixExtractor)
+	}
+	flatbuf.SsTableInfoAddBlockRestartInterval(builder, info.BlockRestartInterval)
+	if info.LastKey != nil {
+		flatbuf.SsTableInfoAddLastKey(builder, lastKey)
+	}
+	flatbuf.SsTableInfoAddNumEntries(builder, info.NumEntries)
+	flatbuf.SsTableInfoAddNumTombstones(builder, info.NumTombstones)
+	flatbuf.SsTableInfoAddRawKeySize(builder, info.RawKeySize)
+	flatbuf.SsTableInfoAddRawValueSize(builder, info.RawValueSize)
+	flatbuf.SsTableInfoAddCreationTimeMs(builder, CreationTimeToFlatBuf(info.CreatedAt))
 	infoOffset := flatbuf.SsTableInfoEnd(builder)
 
 	builder.Finish(infoOffset)
@@ -80,6 +208,23 @@ func EncodeInfo(info *Info) []byte {
 	return binary.BigEndian.AppendUint32(b, crc32.ChecksumIEEE(b))
 }
 
+// encodeRangeTombstones adds the range tombstones to the builder as a vector
+// of flatbuf.RangeTombstone and returns the offset of the vector
+func encodeRangeTombstones(builder *flatbuffers.Builder, tombstones []types.RangeTombstone) flatbuffers.UOffsetT {
+	if len(tombstones) == 0 {
+		return 0
+	}
+	offsets := make([]flatbuffers.UOffsetT, 0, len(tombstones))
+	for _, t := range RangeTombstonesToFlatBuf(tombstones) {
+		offsets = append(offsets, t.Pack(builder))
+	}
+	flatbuf.SsTableInfoStartRangeTombstonesVector(builder, len(offsets))
+	for i := len(offsets) - 1; i >= 0; i-- {
+		builder.PrependUOffsetT(offsets[i])
+	}
+	return builder.EndVector(len(offsets))
+}
+
 func DecodeIndex(buf []byte, codec compress.Codec) (*Index, error) {
 	if len(buf) <= common.SizeOfUint32 {
 		return nil, internal.Err("corrupted index; too short")
@@ -119,7 +264,27 @@ func DecodeInfo(b []byte) (*Info, error) {
 		FilterOffset:     fbInfo.FilterOffset(),
 		FilterLen:        fbInfo.FilterLen(),
 		CompressionCodec: compress.Codec(fbInfo.CompressionFormat()),
+
+		BlockCompressionFlags: fbInfo.BlockCompressionFlags(),
+		BlockChecksum:         block.Checksum(fbInfo.BlockChecksum()),
+		FilterIndexLen:        fbInfo.FilterIndexLen(),
+		FilterBitsPerKey:      fbInfo.FilterBitsPerKey(),
+		FilterPrefixExtractor: string(fbInfo.FilterPrefixExtractor()),
+		BlockRestartInterval:  fbInfo.BlockRestartInterval(),
+		LastKey:               bytes.Clone(fbInfo.LastKeyBytes()),
+		NumEntries:            fbInfo.NumEntries(),
+		NumTombstones:         fbInfo.NumTombstones(),
+		RawKeySize:            fbInfo.RawKeySize(),
+		RawValueSize:          fbInfo.RawValueSize(),
+		CreatedAt:             CreationTimeFromFlatBuf(fbInfo.CreationTimeMs()),
+	}
+	rangeTombstones := make([]*flatbuf.RangeTombstoneT, 0, fbInfo.RangeTombstonesLength())
+	for i := 0; i < fbInfo.RangeTombstonesLength(); i++ {
+		var t flatbuf.RangeTombstone
+		fbInfo.RangeTombstones(&t, i)
+		rangeTombstones = append(rangeTombstones, t.UnPack())
 	}
+	info.RangeTombstones = RangeTombstonesFromFlatBuf(rangeTombstones)
 	return info, nil
 }
 
@@ -4,7 +4,12 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"runtime"
+	"slices"
 
+	"github.com/samber/mo"
+
+	"github.com/slatedb/slatedb-go/internal"
 	"github.com/slatedb/slatedb-go/internal/sstable/block"
 	"github.com/slatedb/slatedb-go/internal/types"
 	"github.com/slatedb/slatedb-go/slatedb/common"
@@ -13,6 +18,14 @@ import (
 type TableStore interface {
 	ReadIndex(context.Context, *Handle) (*Index, error)
 	ReadBlocksUsingIndex(context.Context, *Handle, common.Range, *Index) ([]block.Block, error)
+
+	// ReadAheadBytes is the number of bytes of adjacent blocks an Iterator reads with a single
+	// request. An Iterator reads at least one block per request.
+	ReadAheadBytes() uint64
+
+	// PrefetchConcurrency is the number of requests an Iterator has in flight for the blocks
+	// after the blocks being iterated. If zero, blocks are read when the iteration reaches them.
+	PrefetchConcurrency() int
 }
 
 // Iterator iterates through KeyValue pairs present in the SSTable.
@@ -24,6 +37,20 @@ type Iterator struct {
 	index     *Index
 	fromKey   []byte
 	nextBlock uint64
+
+	// blocks holds the blocks which have been read ahead of the block being iterated, in the
+	// order they are iterated. See TableStore.ReadAheadBytes
+	blocks []block.Block
+
+	// prefetches are the requests in flight for the blocks after iter.blocks, in the order they
+	// are iterated. See TableStore.PrefetchConcurrency
+	prefetches []*prefetch
+
+	// A reverse iterator reads the blocks before nextBlock from last to first
+	reverse bool
+
+	// inline is the entry returned before the blocks are read, see NewPointIterator
+	inline mo.Option[types.RowEntry]
 }
 
 func NewIterator(ctx context.Context, handle *Handle, store TableStore) (*Iterator, error) {
@@ -56,9 +83,82 @@ func NewIteratorAtKey(ctx context.Context, handle *Handle, key []byte, store Tab
 	return iter, nil
 }
 
+// NewPointIterator returns an Iterator starting at the given key like NewIteratorAtKey. If the
+// newest entry of the key is inlined into the index, it is returned without reading a block,
+// see Config.InlineValueBytes
+func NewPointIterator(ctx context.Context, handle *Handle, key []byte, store TableStore) (*Iterator, error) {
+	iter, err := NewIteratorAtKey(ctx, handle, key, store)
+	if err != nil {
+		return nil, err
+	}
+	entry, ok, err := iter.index.InlineEntry(key)
+	if err != nil {
+		// The entry is read from its block instead
+		iter.warn.Add("while reading inline value of SST '%s': %s", handle.Id.String(), err.Error())
+		return iter, nil
+	}
+	if ok {
+		iter.inline = mo.Some(entry)
+		// The older versions of the key are skipped
+		iter.fromKey = append(bytes.Clone(key), 0)
+	}
+	return iter, nil
+}
+
+// NewReverseIterator returns an Iterator over the SSTable which returns keys in descending order
+func NewReverseIterator(ctx context.Context, handle *Handle, store TableStore) (*Iterator, error) {
+	index, err := store.ReadIndex(ctx, handle)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Iterator{
+		handle:    handle,
+		store:     store,
+		index:     index,
+		nextBlock: uint64(index.BlockMetaLength()),
+		reverse:   true,
+	}, nil
+}
+
+// NewReverseIteratorAtKey returns an Iterator which returns keys in descending order starting
+// at the given key, or at the last key less than the given key if the key is not in the SSTable.
+func NewReverseIteratorAtKey(ctx context.Context, handle *Handle, key []byte, store TableStore) (*Iterator, error) {
+	index, err := store.ReadIndex(ctx, handle)
+	if err != nil {
+		return nil, err
+	}
+
+	iter := &Iterator{
+		fromKey: bytes.Clone(key),
+		handle:  handle,
+		store:   store,
+		index:   index,
+		reverse: true,
+	}
+	if index.BlockMetaLength() > 0 {
+		iter.nextBlock = iter.firstBlockIncludingOrAfterKey(index, key) + 1
+	}
+	return iter, nil
+}
+
 func (iter *Iterator) NextEntry(ctx context.Context) (types.RowEntry, bool) {
+	if kv, ok := iter.inline.Get(); ok {
+		iter.inline = mo.None[types.RowEntry]()
+		if iter.handle.Info.IngestSeq != 0 {
+			kv.Seq = iter.handle.Info.IngestSeq
+		}
+		return kv, true
+	}
 	for {
 		if iter.blockIter == nil {
+			// Yield between blocks, such that a long-running iteration does not starve other
+			// goroutines and stops before fetching another block once ctx is done
+			runtime.Gosched()
+			if err := ctx.Err(); err != nil {
+				iter.warn.Add("while iterating SST '%s': %s", iter.handle.Id.String(), err.Error())
+				return types.RowEntry{}, false
+			}
 			it, err := iter.nextBlockIter(ctx)
 			if err != nil {
 				// TODO(thrawn01): This could be a transient error, or a corruption error
@@ -84,37 +184,185 @@ func (iter *Iterator) NextEntry(ctx context.Context) (types.RowEntry, bool) {
 			continue
 		}
 
+		if iter.handle.Info.IngestSeq != 0 {
+			kv.Seq = iter.handle.Info.IngestSeq
+		}
 		return kv, true
 	}
 }
 
-// nextBlockIter fetches the next block and returns an iterator for that block
+// nextBlockIter returns an iterator for the next block, reading the block and the blocks after
+// it up to TableStore.ReadAheadBytes if they have not been read
 func (iter *Iterator) nextBlockIter(ctx context.Context) (*block.Iterator, error) {
-	if iter.nextBlock >= uint64(iter.index.BlockMetaLength()) {
-		return nil, nil // No more blocks to read
-	}
-
-	// Fetch the next block
-	rng := common.Range{Start: iter.nextBlock, End: iter.nextBlock + 1}
-	blocks, err := iter.store.ReadBlocksUsingIndex(ctx, iter.handle, rng, iter.index)
-	if err != nil {
-		return nil, fmt.Errorf("while reading block range [%d:%d]: %w", rng.Start, rng.End, err)
+	if iter.reverse {
+		return iter.prevBlockIter(ctx)
 	}
-	if len(blocks) == 0 {
-		return nil, fmt.Errorf("block read range [%d:%d] returned zero blocks", rng.Start, rng.End)
+	b, err := iter.popBlock(ctx, func() bool {
+		return iter.nextBlock >= uint64(iter.index.BlockMetaLength())
+	})
+	if b == nil || err != nil {
+		return nil, err
 	}
 
-	// Increment the iter.nextBlock
-	iter.nextBlock++
-
 	// If iter.fromKey is present use NewIteratorAtKey() to find the key in the block
 	if iter.fromKey != nil {
 		// Will return an iterator nearest to where the key should be if it doesn't exist.
-		return block.NewIteratorAtKey(&blocks[0], iter.fromKey)
+		return block.NewIteratorAtKey(b, iter.fromKey)
 	}
 
 	// Iterate through all the blocks
-	return block.NewIterator(&blocks[0]), nil
+	return block.NewIterator(b), nil
+}
+
+// prevBlockIter returns a reverse iterator for the block before iter.nextBlock, reading the
+// block and the blocks before it up to TableStore.ReadAheadBytes if they have not been read
+func (iter *Iterator) prevBlockIter(ctx context.Context) (*block.Iterator, error) {
+	b, err := iter.popBlock(ctx, func() bool { return iter.nextBlock == 0 })
+	if b == nil || err != nil {
+		return nil, err
+	}
+
+	// Only the first block read may contain keys greater than iter.fromKey; every key
+	// of the blocks before it is less than iter.fromKey
+	if iter.fromKey != nil {
+		return block.NewReverseIteratorAtKey(b, iter.fromKey)
+	}
+	return block.NewReverseIterator(b)
+}
+
+// popBlock returns the next block to iterate, reading the blocks which follow it if they have
+// not been read, or nil once `done` returns true and no blocks remain. The empty blocks returned by
+// TableStore.ReadBlocksUsingIndex in place of the corrupted blocks of a quarantined SST are skipped.
+func (iter *Iterator) popBlock(ctx context.Context, done func() bool) (*block.Block, error) {
+	for {
+		if len(iter.blocks) == 0 {
+			if len(iter.prefetches) == 0 && done() {
+				return nil, nil
+			}
+			if err := iter.readNext(ctx); err != nil {
+				return nil, err
+			}
+		}
+		b := &iter.blocks[0]
+		iter.blocks = iter.blocks[1:]
+		if len(b.Offsets) > 0 {
+			return b, nil
+		}
+	}
+}
+
+// readAheadRange returns the range of blocks to read: the next block in the direction of
+// iteration, and the blocks adjacent to it up to TableStore.ReadAheadBytes
+func (iter *Iterator) readAheadRange() common.Range {
+	limit := iter.store.ReadAheadBytes()
+	size := func(rng common.Range) uint64 {
+		bytesRange := getBlockRange(rng, iter.handle.Info, iter.index)
+		return bytesRange.End - bytesRange.Start
+	}
+	if iter.reverse {
+		rng := common.Range{Start: iter.nextBlock - 1, End: iter.nextBlock}
+		for rng.Start > 0 && size(common.Range{Start: rng.Start - 1, End: rng.End}) <= limit {
+			rng.Start--
+		}
+		return rng
+	}
+	rng := common.Range{Start: iter.nextBlock, End: iter.nextBlock + 1}
+	for rng.End < uint64(iter.index.BlockMetaLength()) && size(common.Range{Start: rng.Start, End: rng.End + 1}) <= limit {
+		rng.End++
+	}
+	return rng
+}
+
+// readNext queues the next range of blocks to be iterated. If TableStore.PrefetchConcurrency
+// is non-zero, the requests for the ranges after it are started before waiting for it, such
+// that they are fetched while its blocks are iterated.
+func (iter *Iterator) readNext(ctx context.Context) error {
+	if iter.store.PrefetchConcurrency() == 0 {
+		rng := iter.readAheadRange()
+		iter.advance(rng)
+		blocks, err := iter.readRange(ctx, rng)
+		if err != nil {
+			return err
+		}
+		iter.blocks = blocks
+		return nil
+	}
+
+	iter.Prefetch(ctx)
+	p := iter.prefetches[0]
+	iter.prefetches = iter.prefetches[1:]
+	iter.Prefetch(ctx)
+	select {
+	case <-p.done:
+	case <-ctx.Done():
+		// The request remains queued, such that the iteration may continue with another ctx
+		iter.prefetches = slices.Insert(iter.prefetches, 0, p)
+		return ctx.Err()
+	}
+	if p.err != nil {
+		return p.err
+	}
+	iter.blocks = p.blocks
+	return nil
+}
+
+// prefetch is a request for a range of blocks which was started before the iteration reached them
+type prefetch struct {
+	done   chan struct{}
+	blocks []block.Block
+	err    error
+}
+
+// Prefetch starts requests for the blocks which have not been read, until
+// TableStore.PrefetchConcurrency requests are in flight. The requests are not cancelled when
+// ctx is done, as the ctx of a call to NextEntry may end when the call returns, but are
+// bounded by the deadline of ctx.
+func (iter *Iterator) Prefetch(ctx context.Context) {
+	for len(iter.prefetches) < iter.store.PrefetchConcurrency() && iter.hasUnreadBlocks() {
+		rng := iter.readAheadRange()
+		iter.advance(rng)
+		p := &prefetch{done: make(chan struct{})}
+		iter.prefetches = append(iter.prefetches, p)
+
+		fetchCtx, cancel := internal.DetachContext(ctx)
+		go func() {
+			defer close(p.done)
+			defer cancel()
+			p.blocks, p.err = iter.readRange(fetchCtx, rng)
+		}()
+	}
+}
+
+// hasUnreadBlocks returns true if blocks remain which have not been requested
+func (iter *Iterator) hasUnreadBlocks() bool {
+	if iter.reverse {
+		return iter.nextBlock > 0
+	}
+	return iter.nextBlock < uint64(iter.index.BlockMetaLength())
+}
+
+// advance moves iter.nextBlock past `rng` once the blocks of `rng` have been requested
+func (iter *Iterator) advance(rng common.Range) {
+	if iter.reverse {
+		iter.nextBlock = rng.Start
+	} else {
+		iter.nextBlock = rng.End
+	}
+}
+
+// readRange reads the blocks of `rng` with a single request, in the order they are iterated
+func (iter *Iterator) readRange(ctx context.Context, rng common.Range) ([]block.Block, error) {
+	blocks, err := iter.store.ReadBlocksUsingIndex(ctx, iter.handle, rng, iter.index)
+	if err != nil {
+		return nil, fmt.Errorf("while reading block range [%d:%d]: %w", rng.Start, rng.End, err)
+	}
+	if uint64(len(blocks)) != rng.End-rng.Start {
+		return nil, fmt.Errorf("block read range [%d:%d] returned %d blocks", rng.Start, rng.End, len(blocks))
+	}
+	if iter.reverse {
+		slices.Reverse(blocks)
+	}
+	return blocks, nil
 }
 
 // firstBlockIncludingOrAfterKey performs a binary search on the SSTable index to find the first block
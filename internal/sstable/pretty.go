@@ -4,8 +4,10 @@ import (
 	"bytes"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/slatedb/slatedb-go/internal/sstable/block"
+	"github.com/slatedb/slatedb-go/internal/sstable/bloom"
 )
 
 // PrettyPrint returns a string representation of the SSTable in a human-readable format
@@ -58,11 +60,38 @@ func PrettyPrint(table *Table) string {
 	// Print SSTable Info
 	_, _ = fmt.Fprintf(&buf, "SSTable Info:\n")
 	_, _ = fmt.Fprintf(&buf, "  First Key: %s\n", string(table.Info.FirstKey))
+	if table.Info.NumEntries > 0 {
+		_, _ = fmt.Fprintf(&buf, "  Last Key: %s\n", string(table.Info.LastKey))
+		_, _ = fmt.Fprintf(&buf, "  Entries: %d (%d tombstones)\n", table.Info.NumEntries, table.Info.NumTombstones)
+		_, _ = fmt.Fprintf(&buf, "  Raw Key Size: %d\n", table.Info.RawKeySize)
+		_, _ = fmt.Fprintf(&buf, "  Raw Value Size: %d\n", table.Info.RawValueSize)
+	}
+	if !table.Info.CreatedAt.IsZero() {
+		_, _ = fmt.Fprintf(&buf, "  Created At: %s\n", table.Info.CreatedAt.UTC().Format(time.RFC3339Nano))
+	}
 	_, _ = fmt.Fprintf(&buf, "  Index Offset: %d\n", table.Info.IndexOffset)
 	_, _ = fmt.Fprintf(&buf, "  Index Length: %d\n", table.Info.IndexLen)
 	_, _ = fmt.Fprintf(&buf, "  Filter Offset: %d\n", table.Info.FilterOffset)
 	_, _ = fmt.Fprintf(&buf, "  Filter Length: %d\n", table.Info.FilterLen)
+	if table.Info.FilterIndexLen > 0 {
+		_, _ = fmt.Fprintf(&buf, "  Filter Index Length: %d\n", table.Info.FilterIndexLen)
+	}
+	if table.Info.FilterBitsPerKey > 0 {
+		_, _ = fmt.Fprintf(&buf, "  Filter Bits Per Key: %d (false positive rate %.4f)\n",
+			table.Info.FilterBitsPerKey, bloom.FalsePositiveRate(table.Info.FilterBitsPerKey))
+	}
+	if table.Info.FilterPrefixExtractor != "" {
+		_, _ = fmt.Fprintf(&buf, "  Filter Prefix Extractor: %s\n", table.Info.FilterPrefixExtractor)
+	}
 	_, _ = fmt.Fprintf(&buf, "  Compression Codec: %s\n", table.Info.CompressionCodec)
+	_, _ = fmt.Fprintf(&buf, "  Block Compression Flags: %t\n", table.Info.BlockCompressionFlags)
+	if table.Info.BlockRestartInterval > 0 {
+		_, _ = fmt.Fprintf(&buf, "  Block Restart Interval: %d\n", table.Info.BlockRestartInterval)
+	}
+	_, _ = fmt.Fprintf(&buf, "  Block Checksum: %s\n", table.Info.BlockChecksum)
+	for _, t := range table.Info.RangeTombstones {
+		_, _ = fmt.Fprintf(&buf, "  Range Tombstone: [%s, %s) seq %d\n", string(t.Start), string(t.End), t.Seq)
+	}
 
 	// Print Bloom Filter info if present
 	if filter, ok := table.Bloom.Get(); ok {
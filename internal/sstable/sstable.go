@@ -2,10 +2,18 @@ package sstable
 
 import (
 	"bytes"
+	"time"
 
 	"github.com/slatedb/slatedb-go/internal/compress"
+	"github.com/slatedb/slatedb-go/internal/sstable/block"
+	"github.com/slatedb/slatedb-go/internal/types"
 )
 
+// FormatVersion is the version of the SSTable format, whose rows are encoded with the v0 row
+// codec. Additions to the format which older binaries cannot read are recorded in the manifest
+// as manifest.Features rather than a new version.
+const FormatVersion = 0
+
 // Info contains meta information on the SSTable when it is serialized.
 // This is used when we read SSTable as a slice of bytes from object storage and we want to parse the slice of bytes
 // Each SSTable is a list of blocks and each block is a list of KeyValues
@@ -28,6 +36,77 @@ type Info struct {
 
 	// the codec used to compress/decompress SSTable before writing/reading from object storage
 	CompressionCodec compress.Codec
+
+	// if true, each block has a flag indicating if the block was compressed
+	// with CompressionCodec or stored uncompressed. See block.EncodeWithFlag
+	BlockCompressionFlags bool
+
+	// the range tombstones written to the SSTable. Range tombstones are held by the Info rather
+	// than the blocks, such that they are available from the manifest without reading the SSTable
+	RangeTombstones []types.RangeTombstone
+
+	// if non-zero, the sequence number of every entry in the SSTable. IngestSeq is assigned
+	// when an SSTable is ingested, and is only recorded in the manifest and not the SSTable.
+	IngestSeq uint64
+
+	// the algorithm of the checksum appended to each block, see block.Checksum. SSTables
+	// written before BlockChecksum was recorded have a CRC32 (IEEE) checksum.
+	BlockChecksum block.Checksum
+
+	// if non-zero, the filter is partitioned and FilterIndexLen is the length of the filter
+	// index at the end of the filter, which is preceded by the filter partitions. The filter is
+	// a single bloom filter if zero. See FilterIndex
+	FilterIndexLen uint64
+
+	// the number of bits per key of the filter, or of each partition of a partitioned filter,
+	// see bloom.FalsePositiveRate. Zero if the SSTable has no filter, or was written before
+	// FilterBitsPerKey was recorded.
+	FilterBitsPerKey uint32
+
+	// the name of the bloom.PrefixExtractor whose prefixes of the keys were added to the filter
+	// along with the keys, or empty if the filter only holds the keys
+	FilterPrefixExtractor string
+
+	// the number of rows between the restart points of each block, see block.Block.RestartInterval.
+	// Zero if keys are stored relative to the first key of their block.
+	BlockRestartInterval uint16
+
+	// the last key of the SSTable, the number of entries, including tombstones, and of tombstones,
+	// and the total size of their keys and values before they are encoded. Range tombstones are
+	// not counted. Zero if the SSTable was written before the table properties were recorded,
+	// as is CreatedAt. See Properties
+	LastKey       []byte
+	NumEntries    uint64
+	NumTombstones uint64
+	RawKeySize    uint64
+	RawValueSize  uint64
+
+	// the time at which the SSTable was built, with millisecond precision
+	CreatedAt time.Time
+}
+
+// EncodedSize returns the number of bytes of the blocks, filter and index of the SSTable, which
+// is the size of the SSTable in object storage less the metadata which follows the index
+func (info *Info) EncodedSize() uint64 {
+	return info.IndexOffset + info.IndexLen
+}
+
+// Properties returns the table properties of the SSTable, see Properties
+func (info *Info) Properties() Properties {
+	return Properties{
+		NumEntries:       info.NumEntries,
+		NumTombstones:    info.NumTombstones,
+		NumRangeDeletes:  uint64(len(info.RangeTombstones)),
+		RawKeySize:       info.RawKeySize,
+		RawValueSize:     info.RawValueSize,
+		DataSize:         info.FilterOffset,
+		FilterSize:       info.FilterLen,
+		IndexSize:        info.IndexLen,
+		FirstKey:         info.FirstKey,
+		LastKey:          info.LastKey,
+		CreatedAt:        info.CreatedAt,
+		CompressionCodec: info.CompressionCodec,
+	}
 }
 
 func (info *Info) Clone() *Info {
@@ -38,5 +117,64 @@ func (info *Info) Clone() *Info {
 		FilterOffset:     info.FilterOffset,
 		FilterLen:        info.FilterLen,
 		CompressionCodec: info.CompressionCodec,
+
+		BlockCompressionFlags: info.BlockCompressionFlags,
+		RangeTombstones:       cloneRangeTombstones(info.RangeTombstones),
+		IngestSeq:             info.IngestSeq,
+		BlockChecksum:         info.BlockChecksum,
+		FilterIndexLen:        info.FilterIndexLen,
+		FilterBitsPerKey:      info.FilterBitsPerKey,
+		FilterPrefixExtractor: info.FilterPrefixExtractor,
+		BlockRestartInterval:  info.BlockRestartInterval,
+		LastKey:               bytes.Clone(info.LastKey),
+		NumEntries:            info.NumEntries,
+		NumTombstones:         info.NumTombstones,
+		RawKeySize:            info.RawKeySize,
+		RawValueSize:          info.RawValueSize,
+		CreatedAt:             info.CreatedAt,
+	}
+}
+
+// Properties are the statistics of the entries of an SSTable recorded by the Builder when the
+// SSTable is built, such that the compactor and tooling need not read the SSTable to learn of its
+// contents. The counts and raw sizes are zero, and LastKey and CreatedAt are unset, for SSTables
+// written before the table properties were recorded.
+type Properties struct {
+	// NumEntries is the number of entries, including tombstones, NumTombstones the number of
+	// entries which are tombstones, and NumRangeDeletes the number of range tombstones
+	NumEntries      uint64
+	NumTombstones   uint64
+	NumRangeDeletes uint64
+
+	// RawKeySize and RawValueSize are the total size of the keys and values of the entries
+	// before they are encoded into blocks and compressed
+	RawKeySize   uint64
+	RawValueSize uint64
+
+	// DataSize, FilterSize and IndexSize are the sizes of the encoded and compressed blocks,
+	// filter and index of the SSTable
+	DataSize   uint64
+	FilterSize uint64
+	IndexSize  uint64
+
+	// FirstKey and LastKey are the smallest and largest keys of the SSTable
+	FirstKey []byte
+	LastKey  []byte
+
+	// CreatedAt is the time at which the SSTable was built
+	CreatedAt time.Time
+
+	// CompressionCodec is the codec with which the blocks, filter and index are compressed
+	CompressionCodec compress.Codec
+}
+
+func cloneRangeTombstones(tombstones []types.RangeTombstone) []types.RangeTombstone {
+	if len(tombstones) == 0 {
+		return nil
+	}
+	result := make([]types.RangeTombstone, 0, len(tombstones))
+	for _, t := range tombstones {
+		result = append(result, types.RangeTombstone{Start: bytes.Clone(t.Start), End: bytes.Clone(t.End), Seq: t.Seq})
 	}
+	return result
 }
@@ -2,13 +2,16 @@ package sstable_test
 
 import (
 	"bytes"
+	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/slatedb/slatedb-go/internal/compress"
 	"github.com/slatedb/slatedb-go/internal/sstable"
+	"github.com/slatedb/slatedb-go/internal/types"
 )
 
 func TestInfoClone(t *testing.T) {
@@ -43,6 +46,19 @@ func TestEncodeInfo(t *testing.T) {
 		FilterOffset:     300,
 		FilterLen:        400,
 		CompressionCodec: compress.CodecSnappy,
+
+		BlockCompressionFlags: true,
+		BlockRestartInterval:  16,
+		RangeTombstones: []types.RangeTombstone{
+			{Start: []byte("a"), End: []byte("c"), Seq: 5},
+			{Start: []byte("b"), End: []byte("z"), Seq: 7},
+		},
+		LastKey:       []byte("testkey9"),
+		NumEntries:    10,
+		NumTombstones: 2,
+		RawKeySize:    80,
+		RawValueSize:  160,
+		CreatedAt:     time.UnixMilli(1700000000123),
 	}
 
 	buf := sstable.EncodeInfo(info)
@@ -59,6 +75,47 @@ func TestEncodeInfo(t *testing.T) {
 	assert.Equal(t, info.FilterOffset, decodedInfo.FilterOffset)
 	assert.Equal(t, info.FilterLen, decodedInfo.FilterLen)
 	assert.Equal(t, info.CompressionCodec, decodedInfo.CompressionCodec)
+	assert.Equal(t, info.BlockCompressionFlags, decodedInfo.BlockCompressionFlags)
+	assert.Equal(t, info.BlockRestartInterval, decodedInfo.BlockRestartInterval)
+	assert.Equal(t, info.RangeTombstones, decodedInfo.RangeTombstones)
+	assert.Equal(t, info.LastKey, decodedInfo.LastKey)
+	assert.Equal(t, info.NumEntries, decodedInfo.NumEntries)
+	assert.Equal(t, info.NumTombstones, decodedInfo.NumTombstones)
+	assert.Equal(t, info.RawKeySize, decodedInfo.RawKeySize)
+	assert.Equal(t, info.RawValueSize, decodedInfo.RawValueSize)
+	assert.True(t, info.CreatedAt.Equal(decodedInfo.CreatedAt))
+}
+
+func TestTableProperties(t *testing.T) {
+	createdAt := time.UnixMilli(1700000000123)
+	builder := sstable.NewBuilder(sstable.Config{
+		BlockSize:        64,
+		MinFilterKeys:    1,
+		FilterBitsPerKey: 10,
+		Compression:      compress.CodecNone,
+		Now:              func() time.Time { return createdAt },
+	})
+	require.NoError(t, builder.AddValue([]byte("key1"), []byte("value1")))
+	require.NoError(t, builder.Add([]byte("key2"), types.RowEntry{
+		Key:   []byte("key2"),
+		Value: types.Value{Kind: types.KindTombStone},
+	}))
+	require.NoError(t, builder.AddValue([]byte("key3"), []byte("value3")))
+
+	table, err := builder.Build()
+	require.NoError(t, err)
+	info, err := sstable.ReadInfo(context.Background(), sstable.NewBytesBlob(sstable.EncodeTable(table)))
+	require.NoError(t, err)
+
+	props := info.Properties()
+	assert.Equal(t, uint64(3), props.NumEntries)
+	assert.Equal(t, uint64(1), props.NumTombstones)
+	assert.Equal(t, uint64(12), props.RawKeySize)
+	assert.Equal(t, uint64(12), props.RawValueSize)
+	assert.Equal(t, []byte("key1"), props.FirstKey)
+	assert.Equal(t, []byte("key3"), props.LastKey)
+	assert.Equal(t, info.FilterOffset, props.DataSize)
+	assert.True(t, createdAt.Equal(props.CreatedAt))
 }
 
 func TestEncodeTable(t *testing.T) {
@@ -86,6 +86,11 @@ func NewHandle(id ID, info *Info) *Handle {
 	return &Handle{id, info}
 }
 
+// Properties returns the table properties of the SSTable, see Properties
+func (h *Handle) Properties() Properties {
+	return h.Info.Properties()
+}
+
 func (h *Handle) RangeCoversKey(key []byte) bool {
 	if len(h.Info.FirstKey) == 0 {
 		return false
@@ -0,0 +1,290 @@
+// Package task supervises the long-running background loops of the database, such as the
+// WAL flush, memtable flush and compaction loops. A loop which returns an error or panics
+// is restarted with exponential backoff, and a loop which keeps failing is marked as failed
+// such that the failure can be reported by a health check.
+package task
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/kapetan-io/tackle/set"
+	"golang.org/x/sync/errgroup"
+)
+
+// ErrTaskFailed is returned by Manager.Err when a task has exhausted its restarts
+var ErrTaskFailed = errors.New("background task failed")
+
+// Func is the body of a task. Func should return nil once ctx is cancelled or the task
+// has otherwise finished its work; returning an error causes the task to be restarted.
+type Func func(ctx context.Context) error
+
+type Options struct {
+	// MinBackoff is the time to wait before restarting a task after its first failure.
+	// The wait doubles with each consecutive failure up to MaxBackoff.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+
+	// MaxRestarts is the number of consecutive failures after which a task is no longer
+	// restarted and is reported as failed. A task which runs for at least MaxBackoff
+	// before failing is no longer considered to be failing consecutively.
+	MaxRestarts int
+
+	// OnEvent, if not nil, is called when a task fails, is restarted or gives up
+	OnEvent func(Event)
+
+	Log *slog.Logger
+}
+
+func DefaultOptions() Options {
+	return Options{
+		MinBackoff:  100 * time.Millisecond,
+		MaxBackoff:  10 * time.Second,
+		MaxRestarts: 10,
+	}
+}
+
+// ------------------------------------------------
+// Event
+// ------------------------------------------------
+
+type EventType int
+
+const (
+	// EventFailed is emitted when a task returns an error or panics
+	EventFailed EventType = iota + 1
+	// EventRestarted is emitted when a failed task is started again
+	EventRestarted
+	// EventGaveUp is emitted when a task has exhausted its restarts
+	EventGaveUp
+	// EventDegraded is emitted when a task has failed for long enough that the database
+	// stops accepting writes
+	EventDegraded
+	// EventResumed is emitted when a degraded database accepts writes again
+	EventResumed
+)
+
+func (t EventType) String() string {
+	switch t {
+	case EventFailed:
+		return "failed"
+	case EventRestarted:
+		return "restarted"
+	case EventGaveUp:
+		return "gave_up"
+	case EventDegraded:
+		return "degraded"
+	case EventResumed:
+		return "resumed"
+	}
+	return "unknown"
+}
+
+type Event struct {
+	Task     string
+	Type     EventType
+	Err      error
+	Restarts int
+}
+
+// ------------------------------------------------
+// Status
+// ------------------------------------------------
+
+// Status describes the health of a task
+type Status struct {
+	Name    string
+	Running bool
+	// Failed is true if the task exhausted its restarts and is no longer running
+	Failed   bool
+	Restarts int
+	LastErr  error
+}
+
+// ------------------------------------------------
+// Manager
+// ------------------------------------------------
+
+type Manager struct {
+	opts   Options
+	group  errgroup.Group
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu    sync.Mutex
+	tasks []*Status
+}
+
+func NewManager(opts Options) *Manager {
+	defaults := DefaultOptions()
+	set.Default(&opts.MinBackoff, defaults.MinBackoff)
+	set.Default(&opts.MaxBackoff, defaults.MaxBackoff)
+	set.Default(&opts.MaxRestarts, defaults.MaxRestarts)
+	set.Default(&opts.Log, slog.Default())
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Manager{
+		opts:   opts,
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Go starts the task `name` running `fn`. The returned Handle is closed once the task
+// has finished, either because `fn` returned nil or because the task gave up.
+func (m *Manager) Go(name string, fn Func) *Handle {
+	status := &Status{Name: name, Running: true}
+	m.mu.Lock()
+	m.tasks = append(m.tasks, status)
+	m.mu.Unlock()
+
+	h := &Handle{done: make(chan struct{})}
+	m.group.Go(func() error {
+		defer close(h.done)
+		return m.supervise(status, fn)
+	})
+	return h
+}
+
+func (m *Manager) supervise(status *Status, fn Func) error {
+	log := m.opts.Log.With("task", status.Name)
+	backoff := m.opts.MinBackoff
+	failures := 0
+	for {
+		started := time.Now()
+		err := run(m.ctx, fn)
+		if err == nil {
+			m.update(status, func(s *Status) { s.Running = false })
+			return nil
+		}
+
+		// A task which ran for a while before failing is not crash looping
+		if time.Since(started) >= m.opts.MaxBackoff {
+			failures = 0
+			backoff = m.opts.MinBackoff
+		}
+		failures++
+		log.Error("background task failed", "error", err, "failures", failures)
+		m.update(status, func(s *Status) { s.LastErr = err })
+		m.emit(Event{Task: status.Name, Type: EventFailed, Err: err, Restarts: status.Restarts})
+
+		// The task is not restarted once the Manager is stopping
+		if m.ctx.Err() != nil {
+			m.update(status, func(s *Status) { s.Running = false })
+			return nil
+		}
+		if failures > m.opts.MaxRestarts {
+			m.update(status, func(s *Status) {
+				s.Running = false
+				s.Failed = true
+			})
+			m.emit(Event{Task: status.Name, Type: EventGaveUp, Err: err, Restarts: status.Restarts})
+			return fmt.Errorf("%w: task '%s': %w", ErrTaskFailed, status.Name, err)
+		}
+
+		select {
+		case <-time.After(backoff):
+		case <-m.ctx.Done():
+			m.update(status, func(s *Status) { s.Running = false })
+			return nil
+		}
+		backoff = min(2*backoff, m.opts.MaxBackoff)
+
+		m.update(status, func(s *Status) { s.Restarts++ })
+		log.Warn("restarting background task", "restarts", status.Restarts)
+		m.emit(Event{Task: status.Name, Type: EventRestarted, Restarts: status.Restarts})
+	}
+}
+
+// run calls fn, converting a panic into an error
+func run(ctx context.Context, fn Func) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v\n%s", r, debug.Stack())
+		}
+	}()
+	return fn(ctx)
+}
+
+func (m *Manager) update(status *Status, fn func(s *Status)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	fn(status)
+}
+
+func (m *Manager) emit(e Event) {
+	if m.opts.OnEvent != nil {
+		m.opts.OnEvent(e)
+	}
+}
+
+// Status returns the status of every task started by the Manager
+func (m *Manager) Status() []Status {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	result := make([]Status, 0, len(m.tasks))
+	for _, s := range m.tasks {
+		result = append(result, *s)
+	}
+	return result
+}
+
+// Err returns an error wrapping ErrTaskFailed for each task which has exhausted its restarts
+func (m *Manager) Err() error {
+	var errs []error
+	for _, s := range m.Status() {
+		if s.Failed {
+			errs = append(errs, fmt.Errorf("%w: task '%s': %w", ErrTaskFailed, s.Name, s.LastErr))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Cancel cancels the context passed to every task without waiting for the tasks to return,
+// such that a task may stop every task, including itself
+func (m *Manager) Cancel() {
+	m.cancel()
+}
+
+// Stop cancels the context passed to every task and waits for the tasks to return
+func (m *Manager) Stop(ctx context.Context) error {
+	m.cancel()
+	done := make(chan error, 1)
+	go func() {
+		done <- m.group.Wait()
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// ------------------------------------------------
+// Handle
+// ------------------------------------------------
+
+// Handle is used to wait for a task started by Manager.Go to finish
+type Handle struct {
+	done chan struct{}
+}
+
+// Done returns a channel which is closed once the task has finished
+func (h *Handle) Done() <-chan struct{} {
+	return h.done
+}
+
+// Wait blocks until the task has finished or ctx is done
+func (h *Handle) Wait(ctx context.Context) error {
+	select {
+	case <-h.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
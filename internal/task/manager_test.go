@@ -0,0 +1,109 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManagerRestartsFailedTasks(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var mu sync.Mutex
+	var events []EventType
+	m := NewManager(Options{
+		MinBackoff: time.Millisecond,
+		MaxBackoff: 10 * time.Millisecond,
+		OnEvent: func(e Event) {
+			mu.Lock()
+			defer mu.Unlock()
+			events = append(events, e.Type)
+		},
+	})
+
+	// the task fails with an error, then panics, then finishes
+	var runs atomic.Int32
+	h := m.Go("flaky", func(ctx context.Context) error {
+		switch runs.Add(1) {
+		case 1:
+			return errors.New("failed")
+		case 2:
+			panic("crashed")
+		}
+		return nil
+	})
+	require.NoError(t, h.Wait(ctx))
+
+	assert.Equal(t, int32(3), runs.Load())
+	mu.Lock()
+	assert.Equal(t, []EventType{EventFailed, EventRestarted, EventFailed, EventRestarted}, events)
+	mu.Unlock()
+
+	status := m.Status()
+	require.Len(t, status, 1)
+	assert.Equal(t, "flaky", status[0].Name)
+	assert.Equal(t, 2, status[0].Restarts)
+	assert.False(t, status[0].Running)
+	assert.False(t, status[0].Failed)
+	assert.ErrorContains(t, status[0].LastErr, "crashed")
+	assert.NoError(t, m.Err())
+	assert.NoError(t, m.Stop(ctx))
+}
+
+func TestManagerGivesUpAfterMaxRestarts(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var gaveUp atomic.Bool
+	m := NewManager(Options{
+		MinBackoff:  time.Millisecond,
+		MaxBackoff:  10 * time.Millisecond,
+		MaxRestarts: 2,
+		OnEvent: func(e Event) {
+			if e.Type == EventGaveUp {
+				gaveUp.Store(true)
+			}
+		},
+	})
+
+	var runs atomic.Int32
+	h := m.Go("broken", func(ctx context.Context) error {
+		runs.Add(1)
+		return errors.New("failed")
+	})
+	require.NoError(t, h.Wait(ctx))
+
+	assert.Equal(t, int32(3), runs.Load())
+	assert.True(t, gaveUp.Load())
+	assert.ErrorIs(t, m.Err(), ErrTaskFailed)
+	assert.ErrorIs(t, m.Stop(ctx), ErrTaskFailed)
+}
+
+func TestManagerStopCancelsTasks(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	m := NewManager(Options{})
+	m.Go("loop", func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	})
+	// a task which fails while the Manager is stopping is not restarted or reported as failed
+	m.Go("failing", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	require.NoError(t, m.Stop(ctx))
+	assert.NoError(t, m.Err())
+	for _, s := range m.Status() {
+		assert.False(t, s.Running)
+		assert.Equal(t, 0, s.Restarts)
+	}
+}
@@ -0,0 +1,226 @@
+// Package tracing instruments the database with OpenTelemetry spans. The reads and writes of the
+// database, WAL flushes, memtable flushes and compactions each start a span, and the requests made
+// to object storage on their behalf are recorded as child spans, such that the time spent waiting
+// on object storage can be attributed to the operation which made the requests.
+//
+// The attributes of the spans are prefixed with "slatedb.", such as "slatedb.sst_id".
+package tracing
+
+import (
+	"context"
+	"io"
+
+	"github.com/thanos-io/objstore"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// TracerName is the name of the tracer of the database, which is used as the instrumentation scope of its spans
+const TracerName = "github.com/slatedb/slatedb-go"
+
+// Attribute keys of the spans and span events
+const (
+	KeyBytes        = attribute.Key("slatedb.bytes")
+	KeyBlocks       = attribute.Key("slatedb.blocks")
+	KeyFirstBlock   = attribute.Key("slatedb.first_block")
+	KeySSTID        = attribute.Key("slatedb.sst_id")
+	KeySSTIDs       = attribute.Key("slatedb.sst_ids")
+	KeyOutputSSTIDs = attribute.Key("slatedb.output_sst_ids")
+	KeyWALID        = attribute.Key("slatedb.wal_id")
+	KeyObject       = attribute.Key("slatedb.object")
+	KeyFound        = attribute.Key("slatedb.found")
+	KeyCompactionID = attribute.Key("slatedb.compaction_id")
+	KeySortedRun    = attribute.Key("slatedb.sorted_run")
+	KeySortedRuns   = attribute.Key("slatedb.sorted_runs")
+	KeyBytesRead    = attribute.Key("slatedb.bytes_read")
+	KeyBytesWritten = attribute.Key("slatedb.bytes_written")
+)
+
+// Tracer returns the tracer of the database from `tp`, or a tracer which records
+// nothing if `tp` is nil
+func Tracer(tp trace.TracerProvider) trace.Tracer {
+	if tp == nil {
+		tp = noop.NewTracerProvider()
+	}
+	return tp.Tracer(TracerName)
+}
+
+// End records `err` as the status of the span, if it is not nil, and ends the span
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// ------------------------------------------------
+// Bucket
+// ------------------------------------------------
+
+// Bucket wraps an objstore.Bucket and records a span for each request made to it
+type Bucket struct {
+	objstore.Bucket
+	tracer trace.Tracer
+}
+
+// NewBucket returns a Bucket which records the requests made to `bucket` with `tracer`
+func NewBucket(bucket objstore.Bucket, tracer trace.Tracer) *Bucket {
+	return &Bucket{Bucket: bucket, tracer: tracer}
+}
+
+// start starts the span of a request of `op` on the object `name`, as a child of the span of `ctx`
+func (b *Bucket) start(ctx context.Context, op, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	attrs = append(attrs, KeyObject.String(name))
+	return b.tracer.Start(ctx, "objstore."+op, trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...))
+}
+
+// end ends the span of a request which failed with `err`, if not nil. Reads of missing
+// objects are not recorded as errors, as the database expects some objects to be missing.
+func (b *Bucket) end(span trace.Span, err error) {
+	if err != nil && b.IsObjNotFoundErr(err) {
+		err = nil
+	}
+	End(span, err)
+}
+
+// Unwrap returns the bucket whose requests are recorded
+func (b *Bucket) Unwrap() objstore.Bucket {
+	return b.Bucket
+}
+
+// Get records the span of the request until the object is received or fails, such that
+// the time spent reading the object is included
+func (b *Bucket) Get(ctx context.Context, name string) (io.ReadCloser, error) {
+	ctx, span := b.start(ctx, "Get", name)
+	rc, err := b.Bucket.Get(ctx, name)
+	if err != nil {
+		b.end(span, err)
+		return nil, err
+	}
+	return &tracedReader{ReadCloser: rc, bucket: b, span: span}, nil
+}
+
+// GetRange records the span of the request until the range is received or fails, such
+// that the time spent reading the range is included
+func (b *Bucket) GetRange(ctx context.Context, name string, off, length int64) (io.ReadCloser, error) {
+	ctx, span := b.start(ctx, "GetRange", name, attribute.Int64("slatedb.offset", off), KeyBytes.Int64(length))
+	rc, err := b.Bucket.GetRange(ctx, name, off, length)
+	if err != nil {
+		b.end(span, err)
+		return nil, err
+	}
+	return &tracedReader{ReadCloser: rc, bucket: b, span: span}, nil
+}
+
+func (b *Bucket) Upload(ctx context.Context, name string, r io.Reader) error {
+	ctx, span := b.start(ctx, "Upload", name, uploadSize(r)...)
+	err := b.Bucket.Upload(ctx, name, r)
+	b.end(span, err)
+	return err
+}
+
+// UploadIfNotExists implements store.ConditionalBucket, and is recorded as an upload. If the
+// wrapped bucket is not a store.ConditionalBucket, the existence of the object is checked with
+// Exists before it is uploaded, as it is for a bucket which is not a store.ConditionalBucket.
+func (b *Bucket) UploadIfNotExists(ctx context.Context, name string, r io.Reader) (bool, error) {
+	cb, ok := b.Bucket.(interface {
+		UploadIfNotExists(ctx context.Context, name string, r io.Reader) (bool, error)
+	})
+	if !ok {
+		exists, err := b.Exists(ctx, name)
+		if err != nil || exists {
+			return false, err
+		}
+		return true, b.Upload(ctx, name, r)
+	}
+	ctx, span := b.start(ctx, "UploadIfNotExists", name, uploadSize(r)...)
+	uploaded, err := cb.UploadIfNotExists(ctx, name, r)
+	span.SetAttributes(attribute.Bool("slatedb.uploaded", uploaded))
+	b.end(span, err)
+	return uploaded, err
+}
+
+func (b *Bucket) Delete(ctx context.Context, name string) error {
+	ctx, span := b.start(ctx, "Delete", name)
+	err := b.Bucket.Delete(ctx, name)
+	b.end(span, err)
+	return err
+}
+
+func (b *Bucket) Exists(ctx context.Context, name string) (bool, error) {
+	ctx, span := b.start(ctx, "Exists", name)
+	exists, err := b.Bucket.Exists(ctx, name)
+	b.end(span, err)
+	return exists, err
+}
+
+func (b *Bucket) Attributes(ctx context.Context, name string) (objstore.ObjectAttributes, error) {
+	ctx, span := b.start(ctx, "Attributes", name)
+	attrs, err := b.Bucket.Attributes(ctx, name)
+	b.end(span, err)
+	return attrs, err
+}
+
+func (b *Bucket) Iter(ctx context.Context, dir string, f func(string) error, options ...objstore.IterOption) error {
+	ctx, span := b.start(ctx, "Iter", dir)
+	err := b.Bucket.Iter(ctx, dir, f, options...)
+	b.end(span, err)
+	return err
+}
+
+func (b *Bucket) IterWithAttributes(ctx context.Context, dir string, f func(objstore.IterObjectAttributes) error,
+	options ...objstore.IterOption) error {
+	ctx, span := b.start(ctx, "Iter", dir)
+	err := b.Bucket.IterWithAttributes(ctx, dir, f, options...)
+	b.end(span, err)
+	return err
+}
+
+// tracedReader ends the span of a read once the object has been read or closed
+type tracedReader struct {
+	io.ReadCloser
+	bucket *Bucket
+	span   trace.Span
+	read   int64
+	ended  bool
+}
+
+func (r *tracedReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.read += int64(n)
+	if err == io.EOF {
+		r.finish(nil)
+	} else if err != nil {
+		r.finish(err)
+	}
+	return n, err
+}
+
+func (r *tracedReader) Close() error {
+	err := r.ReadCloser.Close()
+	r.finish(nil)
+	return err
+}
+
+func (r *tracedReader) finish(err error) {
+	if r.ended {
+		return
+	}
+	r.ended = true
+	r.span.SetAttributes(attribute.Int64("slatedb.bytes_received", r.read))
+	r.bucket.end(r.span, err)
+}
+
+// uploadSize returns the size of an upload read from `r` as an attribute, if it is known. The
+// reader is not wrapped, as buckets upload readers of a known size more efficiently.
+func uploadSize(r io.Reader) []attribute.KeyValue {
+	size, err := objstore.TryToGetSize(r)
+	if err != nil {
+		return nil
+	}
+	return []attribute.KeyValue{KeyBytes.Int64(size)}
+}
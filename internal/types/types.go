@@ -1,6 +1,10 @@
 package types
 
 import (
+	"bytes"
+	"hash/crc32"
+	"time"
+
 	"github.com/samber/mo"
 )
 
@@ -24,10 +28,67 @@ type RowEntry struct {
 	Key   []byte
 	Value Value
 
+	// Seq is the sequence number allocated to the write which produced this entry.
+	// Sequence numbers increase monotonically with each Put or Delete. Entries written
+	// before sequence numbers were allocated have a Seq of zero.
+	Seq uint64
+
+	// ExpireAt is the time after which the entry is treated as not found.
+	// The zero time indicates the entry never expires.
+	ExpireAt time.Time
+
+	// Checksum is the checksum of the value computed when the value was written, see ValueChecksum.
+	// It is absent for tombstones and for values written without a checksum.
+	Checksum mo.Option[uint32]
+
 	// // Future Use
-	// Seq     uint64
 	// Created time.Time
-	// Expired time.Time
+}
+
+// ValueChecksum returns the checksum of a value stored in RowEntry.Checksum
+func ValueChecksum(value []byte) uint32 {
+	return crc32.ChecksumIEEE(value)
+}
+
+// ChecksumMatches returns false if the entry has a checksum which does not match its value
+func (e RowEntry) ChecksumMatches() bool {
+	checksum, ok := e.Checksum.Get()
+	return !ok || e.Value.IsTombstone() || checksum == ValueChecksum(e.Value.Value)
+}
+
+// IsExpired returns true if the entry has an expiry which is not after `now`
+func (e RowEntry) IsExpired(now time.Time) bool {
+	return !e.ExpireAt.IsZero() && !now.Before(e.ExpireAt)
+}
+
+// RangeTombstone deletes the keys in the range [Start, End) which were written before it,
+// that is every entry of a key in the range with a Seq less than the Seq of the RangeTombstone.
+type RangeTombstone struct {
+	Start []byte
+	End   []byte
+	Seq   uint64
+}
+
+// Covers returns true if the entry was deleted by the RangeTombstone
+func (t RangeTombstone) Covers(e RowEntry) bool {
+	return e.Seq < t.Seq && bytes.Compare(e.Key, t.Start) >= 0 && bytes.Compare(e.Key, t.End) < 0
+}
+
+// Overlaps returns true if any key in the range [start, end) is within the RangeTombstone.
+// If start or end is empty, the range is unbounded on that side.
+func (t RangeTombstone) Overlaps(start []byte, end []byte) bool {
+	return (len(end) == 0 || bytes.Compare(t.Start, end) < 0) &&
+		(len(start) == 0 || bytes.Compare(start, t.End) < 0)
+}
+
+// IsCovered returns true if the entry was deleted by any of the range tombstones
+func IsCovered(tombstones []RangeTombstone, e RowEntry) bool {
+	for _, t := range tombstones {
+		if t.Covers(e) {
+			return true
+		}
+	}
+	return false
 }
 
 // Value in a RowEntry which has a Kind that identifies
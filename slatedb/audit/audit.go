@@ -0,0 +1,73 @@
+// Package audit defines the records emitted by the database for every committed mutation
+// when an audit Sink is configured via config.DBOptions.AuditSink
+package audit
+
+import (
+	"hash/fnv"
+	"time"
+)
+
+type Op int
+
+const (
+	OpPut Op = iota + 1
+	OpDelete
+	// OpDeleteRange is the deletion of a range of keys, Record.KeyHash is the hash of the start of the range
+	OpDeleteRange
+)
+
+func (o Op) String() string {
+	switch o {
+	case OpPut:
+		return "Put"
+	case OpDelete:
+		return "Delete"
+	case OpDeleteRange:
+		return "DeleteRange"
+	}
+	return "Unknown"
+}
+
+// Record describes a single committed mutation. The key itself is not included,
+// only a hash of the key, such that the audit stream does not leak key contents.
+type Record struct {
+	// KeyHash is the 64-bit FNV-1a hash of the mutated key. See HashKey
+	KeyHash uint64
+
+	// Op is the type of mutation
+	Op Op
+
+	// WriterID identifies the writer as configured by config.DBOptions.AuditWriterID
+	WriterID string
+
+	// WriterEpoch is the epoch of the writer which committed the mutation
+	WriterEpoch uint64
+
+	// Timestamp is the time at which the mutation was committed to object storage
+	Timestamp time.Time
+
+	// WALID is the id of the WAL SSTable in which the mutation was committed. Mutations
+	// are ordered by WALID, mutations within the same WAL are committed atomically. It is
+	// zero if the mutation was committed to L0 without a WAL, such as while the WAL is disabled,
+	// by a batch written directly to L0, or by DB.ReplaceRange.
+	WALID uint64
+
+	// Seq is the sequence number of the write which produced the mutation, which orders the
+	// mutations within the same WAL, and the mutations committed without a WAL
+	Seq uint64
+}
+
+// Sink receives audit records. Write is called with the records of each WAL after the WAL is
+// durably committed to object storage and before writers awaiting durability are notified, and
+// likewise with the records of each memtable, batch or range committed to L0 without a WAL.
+// Implementations must not retain the slice after Write returns.
+type Sink interface {
+	Write(records []Record)
+}
+
+// HashKey returns the hash of the key as used in Record.KeyHash
+func HashKey(key []byte) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write(key)
+	return h.Sum64()
+}
@@ -0,0 +1,114 @@
+package slatedb
+
+import (
+	"context"
+	"time"
+
+	"github.com/kapetan-io/tackle/set"
+	"github.com/slatedb/slatedb-go/internal"
+	"github.com/slatedb/slatedb-go/slatedb/config"
+)
+
+// ------------------------------------------------
+// Compaction auto-tuning
+// ------------------------------------------------
+
+// The gains of the compaction controller. The output of the controller is the fraction of the
+// range between the minimum and maximum rate limits, and its error is the relative distance of
+// the latency from the target, such that the gains do not depend on the units of either.
+const (
+	autoTuneKp = 0.5
+	autoTuneKi = 0.2
+	autoTuneKd = 0.1
+)
+
+// defaultAutoTuneInterval is how often the rate limit is adjusted if CompactionAutoTune.Interval is zero
+const defaultAutoTuneInterval = time.Second
+
+// compactionController is a PID controller which chooses the rate limit of compactions from
+// the mean latency of Get and Put. See config.CompactionAutoTune
+type compactionController struct {
+	opts config.CompactionAutoTune
+
+	// integral is the sum of the errors, bounded such that its term is within [0, 1]
+	integral float64
+	// prevErr is the error of the previous sample
+	prevErr float64
+}
+
+func newCompactionController(opts config.CompactionAutoTune) *compactionController {
+	// The integral starts at its upper bound, such that compactions are not throttled until
+	// the latency exceeds the target
+	return &compactionController{opts: opts, integral: 1 / autoTuneKi}
+}
+
+// next returns the rate limit given the mean latency of Get and Put since the previous call.
+// The latency is zero if there were no calls, which allows compactions to speed up.
+func (c *compactionController) next(latency time.Duration) uint64 {
+	e := clamp(float64(c.opts.TargetLatency-latency)/float64(c.opts.TargetLatency), -1, 1)
+	c.integral = clamp(c.integral+e, 0, 1/autoTuneKi)
+	fraction := autoTuneKp*e + autoTuneKi*c.integral + autoTuneKd*(e-c.prevErr)
+	c.prevErr = e
+
+	fraction = clamp(fraction, 0, 1)
+	return c.opts.MinBytesPerSecond + uint64(fraction*float64(c.opts.MaxBytesPerSecond-c.opts.MinBytesPerSecond))
+}
+
+func clamp(v, lo, hi float64) float64 {
+	return min(max(v, lo), hi)
+}
+
+// validateAutoTune returns an error if the bounds of `opts` are invalid
+func validateAutoTune(opts *config.CompactionAutoTune) error {
+	if opts == nil {
+		return nil
+	}
+	if opts.TargetLatency <= 0 {
+		return internal.ErrInvalidArgument("compaction auto-tune target latency must be greater than zero")
+	}
+	if opts.MinBytesPerSecond == 0 || opts.MaxBytesPerSecond < opts.MinBytesPerSecond {
+		return internal.ErrInvalidArgument("compaction auto-tune bounds [%d, %d] are invalid",
+			opts.MinBytesPerSecond, opts.MaxBytesPerSecond)
+	}
+	return nil
+}
+
+// spawnCompactionAutoTuneTask adjusts the rate limit of the compactor every
+// CompactionAutoTune.Interval if CompactorOptions.AutoTune is set
+func (db *DB) spawnCompactionAutoTuneTask() {
+	if db.compactor == nil || db.opts.CompactorOptions.AutoTune == nil {
+		return
+	}
+	opts := *db.opts.CompactorOptions.AutoTune
+	set.Default(&opts.Interval, defaultAutoTuneInterval)
+	controller := newCompactionController(opts)
+	db.compactor.SetRateLimit(opts.MaxBytesPerSecond)
+
+	db.tasks.Go("compaction_autotune", func(ctx context.Context) error {
+		ticker := db.newIdleTicker(opts.Interval)
+		defer ticker.Stop()
+		ops, nanos := db.foregroundLatency()
+		for {
+			select {
+			case <-ticker.C():
+				nextOps, nextNanos := db.foregroundLatency()
+				var latency time.Duration
+				if nextOps > ops {
+					latency = time.Duration((nextNanos - nanos) / (nextOps - ops))
+				}
+				ops, nanos = nextOps, nextNanos
+				db.compactor.SetRateLimit(controller.next(latency))
+				ticker.pauseIfIdle()
+			case <-ticker.Woken():
+				ticker.resume()
+			case <-ctx.Done():
+				return nil
+			}
+		}
+	})
+}
+
+// foregroundLatency returns the number of calls to Get, Put and Delete and their total latency
+func (db *DB) foregroundLatency() (ops uint64, nanos uint64) {
+	return db.stats.gets.Load() + db.stats.writes.Load(), db.stats.getNanos.Load() + db.stats.writeNanos.Load()
+}
@@ -0,0 +1,45 @@
+package slatedb
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/slatedb/slatedb-go/slatedb/config"
+)
+
+func TestCompactionController(t *testing.T) {
+	controller := newCompactionController(config.CompactionAutoTune{
+		TargetLatency:     10 * time.Millisecond,
+		MinBytesPerSecond: 1_000,
+		MaxBytesPerSecond: 101_000,
+	})
+
+	// compactions are not throttled while the latency is below the target
+	assert.Equal(t, uint64(101_000), controller.next(time.Millisecond))
+	assert.Equal(t, uint64(101_000), controller.next(0))
+
+	// the limit falls while the latency is above the target, until it reaches the minimum
+	prev := uint64(101_000)
+	for i := 0; i < 5; i++ {
+		limit := controller.next(30 * time.Millisecond)
+		assert.LessOrEqual(t, limit, prev)
+		prev = limit
+	}
+	assert.Equal(t, uint64(1_000), prev)
+
+	// the limit holds while the latency is on target, and rises once it falls below
+	controller.next(10 * time.Millisecond)
+	held := controller.next(10 * time.Millisecond)
+	assert.Equal(t, held, controller.next(10*time.Millisecond))
+	assert.Greater(t, controller.next(2*time.Millisecond), held)
+}
+
+func TestValidateAutoTune(t *testing.T) {
+	assert.NoError(t, validateAutoTune(nil))
+	assert.NoError(t, validateAutoTune(&config.CompactionAutoTune{TargetLatency: time.Millisecond, MinBytesPerSecond: 1, MaxBytesPerSecond: 1}))
+	assert.Error(t, validateAutoTune(&config.CompactionAutoTune{MinBytesPerSecond: 1, MaxBytesPerSecond: 2}))
+	assert.Error(t, validateAutoTune(&config.CompactionAutoTune{TargetLatency: time.Millisecond, MaxBytesPerSecond: 2}))
+	assert.Error(t, validateAutoTune(&config.CompactionAutoTune{TargetLatency: time.Millisecond, MinBytesPerSecond: 3, MaxBytesPerSecond: 2}))
+}
@@ -0,0 +1,172 @@
+package slatedb
+
+import (
+	"bytes"
+	"context"
+	"slices"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/samber/mo"
+
+	"github.com/slatedb/slatedb-go/internal"
+	"github.com/slatedb/slatedb-go/internal/sstable"
+	"github.com/slatedb/slatedb-go/internal/types"
+	"github.com/slatedb/slatedb-go/slatedb/config"
+	"github.com/slatedb/slatedb-go/slatedb/table"
+)
+
+// WriteBatch holds puts and deletes, of the default keyspace and of any Keyspace, which are
+// written by DB.Write with a single commit. Readers observe either every write of the batch or
+// none of them, such that an application which maintains a record in one keyspace and an index
+// of it in another never observes the record without its index. A WriteBatch is not safe for
+// concurrent use.
+type WriteBatch struct {
+	entries []types.RowEntry
+}
+
+// NewWriteBatch returns an empty WriteBatch
+func NewWriteBatch() *WriteBatch {
+	return &WriteBatch{}
+}
+
+// Put adds a put of the key-value pair in the default keyspace to the batch
+func (b *WriteBatch) Put(key []byte, value []byte) {
+	b.entries = append(b.entries, types.RowEntry{
+		Key:   bytes.Clone(key),
+		Value: types.Value{Kind: types.KindKeyValue, Value: bytes.Clone(value)},
+	})
+}
+
+// Delete adds a delete of the key in the default keyspace to the batch
+func (b *WriteBatch) Delete(key []byte) {
+	b.entries = append(b.entries, types.RowEntry{
+		Key:   bytes.Clone(key),
+		Value: types.Value{Kind: types.KindTombStone},
+	})
+}
+
+// PutKeyspace adds a put of the key-value pair in the keyspace `ks` to the batch. The value
+// expires after the TTL of the keyspace, if any, has elapsed since it was added.
+func (b *WriteBatch) PutKeyspace(ks *Keyspace, key []byte, value []byte) {
+	b.entries = append(b.entries, types.RowEntry{
+		Key:      ks.key(key),
+		Value:    types.Value{Kind: types.KindKeyValue, Value: bytes.Clone(value)},
+		ExpireAt: ks.expireAt(),
+	})
+}
+
+// DeleteKeyspace adds a delete of the key in the keyspace `ks` to the batch
+func (b *WriteBatch) DeleteKeyspace(ks *Keyspace, key []byte) {
+	b.entries = append(b.entries, types.RowEntry{
+		Key:   ks.key(key),
+		Value: types.Value{Kind: types.KindTombStone},
+	})
+}
+
+// Len returns the number of writes in the batch
+func (b *WriteBatch) Len() int {
+	return len(b.entries)
+}
+
+// Write commits the writes of the batch, see WriteWithOptions
+func (db *DB) Write(ctx context.Context, batch *WriteBatch) error {
+	return db.WriteWithOptions(ctx, batch, config.DefaultWriteOptions())
+}
+
+// WriteWithOptions commits the writes of the batch, in the order they were added, through the WAL.
+// The writes are added to the same WAL SST, such that they are either all durable or none of them
+// are, and they are added to the memtable at once, such that Get, Scan and Snapshot observe either
+// every write of the batch or none of them. If a key is written more than once by the batch, the
+// last write wins. A batch which holds an empty key is rejected without writing any key.
+//
+// A batch of at least DBOptions.DirectL0BatchBytes is instead written to L0 SSTs which are added
+// to the database with a single manifest update, such that it is also observed all at once.
+func (db *DB) WriteWithOptions(ctx context.Context, batch *WriteBatch, options config.WriteOptions) error {
+	for _, entry := range batch.entries {
+		if len(entry.Key) == 0 {
+			return internal.ErrInvalidArgument("argument 'key' cannot be empty or nil")
+		}
+	}
+	if db.opts.ReadOnly {
+		return ErrReadOnly
+	}
+	if err := db.writeErr(); err != nil {
+		return err
+	}
+	if len(batch.entries) == 0 {
+		return nil
+	}
+
+	entries := make([]types.RowEntry, len(batch.entries))
+	var size uint64
+	for i, entry := range batch.entries {
+		if db.opts.ValueChecksums && !entry.Value.IsTombstone() {
+			entry.Checksum = mo.Some(types.ValueChecksum(entry.Value.Value))
+		}
+		entries[i] = entry
+		size += uint64(len(entry.Key) + len(entry.Value.Value))
+	}
+
+	defer db.recordWrite(db.opts.Clock.Now())
+	db.markActive()
+	db.stats.bytesIngested.Add(size)
+	if db.opts.DirectL0BatchBytes > 0 && size >= db.opts.DirectL0BatchBytes {
+		return db.writeBatchToL0(ctx, entries)
+	}
+	if !db.walEnabled() {
+		return db.writeMemtable(ctx, options, func() *table.Memtable {
+			return db.state.MemtableWrite(entries)
+		})
+	}
+	db.writeMu.RLock()
+	currentWAL := db.state.WalPutBatch(entries)
+	db.writeMu.RUnlock()
+	db.maybeRollWAL(currentWAL)
+	if options.AwaitDurable {
+		return db.awaitWALDurable(ctx, currentWAL)
+	}
+	return nil
+}
+
+// writeBatchToL0 writes the entries of a batch to SSTs which are added to L0 with a single
+// manifest update, rather than through the WAL and memtable. See DBOptions.DirectL0BatchBytes
+func (db *DB) writeBatchToL0(ctx context.Context, entries []types.RowEntry) error {
+	slices.SortStableFunc(entries, func(a, b types.RowEntry) int {
+		return bytes.Compare(a.Key, b.Key)
+	})
+	w := db.NewIngestWriter()
+	for i, entry := range entries {
+		// The last write of each key wins
+		if i+1 < len(entries) && bytes.Equal(entry.Key, entries[i+1].Key) {
+			continue
+		}
+		if err := w.addEntry(ctx, entry); err != nil {
+			return err
+		}
+	}
+	ssts, err := w.Finish(ctx)
+	if err != nil {
+		w.Abort()
+		return err
+	}
+
+	// Every entry of the batch is assigned the same sequence number
+	log := db.opts.Log.With("batch_id", ulid.Make().String())
+	var batchSeq uint64
+	err = db.addToL0(ctx, log, func(seq uint64) []sstable.Handle {
+		batchSeq = seq
+		handles := make([]sstable.Handle, 0, len(ssts))
+		for i := len(ssts) - 1; i >= 0; i-- {
+			handle := ssts[i].handle.Clone()
+			handle.Info.IngestSeq = seq
+			handles = append(handles, *handle)
+		}
+		return handles
+	})
+	if err != nil {
+		return err
+	}
+	db.auditL0(ingestAudit(ssts), batchSeq)
+	log.Info("wrote batch directly to L0", "ssts", len(ssts), "entries", len(entries))
+	return nil
+}
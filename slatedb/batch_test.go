@@ -0,0 +1,172 @@
+package slatedb
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore"
+
+	"github.com/slatedb/slatedb-go/slatedb/config"
+)
+
+func TestWriteBatch(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	bucket := objstore.NewInMemBucket()
+	db, err := OpenWithOptions(ctx, "/tmp/test_kv_store", bucket, testDBOptions(0, 1024))
+	require.NoError(t, err)
+	records, err := db.Keyspace("records")
+	require.NoError(t, err)
+	index, err := db.Keyspace("index")
+	require.NoError(t, err)
+	require.NoError(t, db.Put(ctx, []byte("key1"), []byte("value1")))
+
+	batch := NewWriteBatch()
+	batch.PutKeyspace(records, []byte("user1"), []byte("alice"))
+	batch.PutKeyspace(index, []byte("alice"), []byte("user1"))
+	batch.Delete([]byte("key1"))
+	batch.Put([]byte("key2"), []byte("value2"))
+	batch.Put([]byte("key2"), []byte("value3"))
+	assert.Equal(t, 5, batch.Len())
+	require.NoError(t, db.Write(ctx, batch))
+
+	val, err := records.Get(ctx, []byte("user1"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("alice"), val)
+	val, err = index.Get(ctx, []byte("alice"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("user1"), val)
+	_, err = db.Get(ctx, []byte("key1"))
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+	// the last write of a key in the batch wins
+	val, err = db.Get(ctx, []byte("key2"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("value3"), val)
+
+	// a batch with an empty key is rejected without writing any key
+	batch = NewWriteBatch()
+	batch.PutKeyspace(records, []byte("user2"), []byte("bob"))
+	batch.PutKeyspace(index, nil, []byte("user2"))
+	assert.ErrorContains(t, db.Write(ctx, batch), "argument 'key' cannot be empty or nil")
+	_, err = records.Get(ctx, []byte("user2"))
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+	require.NoError(t, db.Write(ctx, NewWriteBatch()))
+
+	// the batch is recovered from the WAL along with the other writes
+	require.NoError(t, db.Close(ctx))
+	db, err = OpenWithOptions(ctx, "/tmp/test_kv_store", bucket, testDBOptions(0, 1024))
+	require.NoError(t, err)
+	defer func() { _ = db.Close(ctx) }()
+	index, err = db.Keyspace("index")
+	require.NoError(t, err)
+	val, err = index.Get(ctx, []byte("alice"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("user1"), val)
+}
+
+func TestWriteBatchIsAtomic(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	options := testDBOptions(0, 1024)
+	options.FlushInterval = time.Millisecond
+	db, err := OpenWithOptions(ctx, "/tmp/test_kv_store", objstore.NewInMemBucket(), options)
+	require.NoError(t, err)
+	defer func() { _ = db.Close(ctx) }()
+	records, err := db.Keyspace("records")
+	require.NoError(t, err)
+	index, err := db.Keyspace("index")
+	require.NoError(t, err)
+
+	const numBatches = 200
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < numBatches; i++ {
+			batch := NewWriteBatch()
+			batch.PutKeyspace(records, []byte(fmt.Sprintf("user%03d", i)), []byte("record"))
+			batch.PutKeyspace(index, []byte(fmt.Sprintf("user%03d", i)), []byte("index"))
+			if err := db.WriteWithOptions(ctx, batch, config.WriteOptions{AwaitDurable: false}); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}()
+
+	// every snapshot holds both the record and its index, or neither of them
+	for _, level := range []config.ReadLevel{config.Committed, config.Uncommitted} {
+		for i := 0; i < numBatches; i++ {
+			snapshot, err := db.Snapshot(ctx)
+			require.NoError(t, err)
+			key := []byte(fmt.Sprintf("user%03d", i))
+			_, recordErr := snapshot.GetWithOptions(ctx, records.key(key), config.ReadOptions{ReadLevel: level})
+			_, indexErr := snapshot.GetWithOptions(ctx, index.key(key), config.ReadOptions{ReadLevel: level})
+			assert.Equal(t, recordErr == nil, indexErr == nil, "user%03d", i)
+			snapshot.Release()
+		}
+	}
+	<-done
+}
+
+func TestWriteBatchDirectToL0(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	bucket := objstore.NewInMemBucket()
+	options := testDBOptions(0, 1024*1024)
+	options.DirectL0BatchBytes = 1024
+	options.ValueChecksums = true
+	db, err := OpenWithOptions(ctx, "/tmp/test_kv_store", bucket, options)
+	require.NoError(t, err)
+	require.NoError(t, db.Put(ctx, []byte("key000"), []byte("old")))
+	require.NoError(t, db.Put(ctx, []byte("key001"), []byte("old")))
+
+	// a batch below the threshold is written through the WAL
+	batch := NewWriteBatch()
+	batch.Put([]byte("small"), []byte("value"))
+	require.NoError(t, db.Write(ctx, batch))
+	assert.Empty(t, db.state.CoreStateSnapshot().L0)
+
+	batch = NewWriteBatch()
+	for i := 100; i > 0; i-- {
+		batch.Put([]byte(fmt.Sprintf("key%03d", i)), []byte(fmt.Sprintf("value%d", i)))
+	}
+	batch.Delete([]byte("key000"))
+	batch.Put([]byte("key050"), []byte("last"))
+	require.NoError(t, db.WriteWithOptions(ctx, batch, config.WriteOptions{AwaitDurable: false}))
+
+	// the prior writes are flushed to an L0 SST, which is older than the SST of the batch
+	l0 := db.state.CoreStateSnapshot().L0
+	require.Len(t, l0, 2)
+	assert.NotZero(t, l0[0].Info.IngestSeq)
+	assert.Zero(t, l0[1].Info.IngestSeq)
+	assert.Zero(t, db.state.Memtable().Size())
+
+	// writes which follow the batch are newer than it
+	require.NoError(t, db.Put(ctx, []byte("key002"), []byte("new")))
+
+	check := func(db *DB) {
+		_, err := db.Get(ctx, []byte("key000"))
+		assert.ErrorIs(t, err, ErrKeyNotFound)
+		for key, expected := range map[string]string{
+			"key001": "value1", "key002": "new", "key050": "last", "key100": "value100", "small": "value",
+		} {
+			val, err := db.Get(ctx, []byte(key))
+			require.NoError(t, err)
+			assert.Equal(t, []byte(expected), val, key)
+		}
+	}
+	check(db)
+
+	// the batch is durable once written
+	require.NoError(t, db.Close(ctx))
+	db, err = OpenWithOptions(ctx, "/tmp/test_kv_store", bucket, options)
+	require.NoError(t, err)
+	defer func() { _ = db.Close(ctx) }()
+	check(db)
+}
@@ -0,0 +1,138 @@
+package slatedb
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"github.com/slatedb/slatedb-go/internal"
+	"github.com/slatedb/slatedb-go/slatedb/store"
+)
+
+// defaultChangePollInterval is how often a ChangeStream lists new WAL SSTs if
+// config.DBOptions.FlushInterval is zero
+const defaultChangePollInterval = 100 * time.Millisecond
+
+// ChangeStream returns the changes written to a database in the order of their sequence
+// numbers, see DB.Changes
+type ChangeStream struct {
+	tailer   *WALTailer
+	lastSeq  uint64
+	interval time.Duration
+	pending  []WALEntry
+}
+
+// Changes returns a ChangeStream of the writes to the database with a sequence number greater than
+// `fromSeq`, such that downstream systems may replicate or index the database. Each change is a
+// WALEntry, and is returned once the WAL SST which holds it has been written to object storage.
+// The WAL SSTs are listed every config.DBOptions.FlushInterval, such that the stream also returns
+// the changes of a writer in another process when the database is opened read-only.
+//
+// Writes to the same key which are flushed in the same WAL SST are coalesced into the last of
+// them, and writes which bypass the WAL, such as those of DB.IngestSST or of a database with
+// config.DBOptions.WALEnabled set to false, are not returned. ErrChangesUnavailable is returned if
+// the WAL SSTs which held changes after `fromSeq` have been deleted by the garbage collector, see
+// config.DBOptions.GCMinAge, which must exceed the time a stream may fall behind the writer.
+func (db *DB) Changes(ctx context.Context, fromSeq uint64) (*ChangeStream, error) {
+	if !db.walEnabled() {
+		return nil, internal.ErrInvalidArgument("changes cannot be streamed while the WAL is disabled")
+	}
+	s := &ChangeStream{
+		tailer:   &WALTailer{tableStore: db.tableStore},
+		lastSeq:  fromSeq,
+		interval: db.opts.FlushInterval,
+	}
+	if s.interval <= 0 {
+		s.interval = defaultChangePollInterval
+	}
+
+	wals, err := db.tableStore.ListWALObjects(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(wals) == 0 {
+		// Every WAL SST has been deleted, so only the changes which have not been written are streamed
+		if fromSeq < db.state.LastSeq() {
+			return nil, ErrChangesUnavailable
+		}
+		return s, nil
+	}
+
+	// The sequence numbers of the entries of each WAL SST follow those of the WAL SSTs before it, so
+	// the stream begins with the last WAL SST whose first entry follows `fromSeq`. An empty WAL SST
+	// does not end the search, such that the stream begins no later than necessary.
+	start := -1
+	lo, hi := 0, len(wals)
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		minSeq, ok, err := s.minSeq(ctx, wals[mid])
+		if err != nil {
+			return nil, err
+		}
+		if ok && minSeq <= fromSeq+1 {
+			start, lo = mid, mid+1
+		} else {
+			hi = mid
+		}
+	}
+	if start < 0 {
+		start = 0
+		// The WAL SSTs before the first are deleted once they have been flushed to L0 and the
+		// changes which followed `fromSeq` may have been in them
+		if first := wals[0].ID.WalID().MustGet(); first > 1 {
+			minSeq, ok, err := s.minSeq(ctx, wals[0])
+			if err != nil {
+				return nil, err
+			}
+			if !ok || fromSeq+1 < minSeq {
+				return nil, ErrChangesUnavailable
+			}
+		}
+	}
+	s.tailer.lastWALID = wals[start].ID.WalID().MustGet() - 1
+	return s, nil
+}
+
+// minSeq returns the lowest sequence number of the entries of `wal`, or false if it has no entries
+func (s *ChangeStream) minSeq(ctx context.Context, wal store.TableObject) (uint64, bool, error) {
+	entries, err := s.tailer.readWAL(ctx, wal)
+	if err != nil || len(entries) == 0 {
+		return 0, false, err
+	}
+	return slices.MinFunc(entries, func(a, b WALEntry) int { return cmp.Compare(a.Seq, b.Seq) }).Seq, true, nil
+}
+
+// Next returns the next change, and waits for one to be written to the WAL if there is none.
+// It returns an error if the WAL could not be read, or ctx is done.
+func (s *ChangeStream) Next(ctx context.Context) (WALEntry, error) {
+	for len(s.pending) == 0 {
+		// The entries of the WAL SSTs read before an error are returned before the error
+		entries, err := s.tailer.Poll(ctx)
+		// The entries of each WAL SST are ordered by key, and those of later WAL SSTs have
+		// greater sequence numbers
+		slices.SortStableFunc(entries, func(a, b WALEntry) int { return cmp.Compare(a.Seq, b.Seq) })
+		for _, e := range entries {
+			if e.Seq > s.lastSeq {
+				s.pending = append(s.pending, e)
+			}
+		}
+		if len(s.pending) > 0 {
+			break
+		}
+		if err != nil {
+			return WALEntry{}, err
+		}
+		timer := time.NewTimer(s.interval)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return WALEntry{}, ctx.Err()
+		}
+	}
+	change := s.pending[0]
+	s.pending = s.pending[1:]
+	s.lastSeq = change.Seq
+	return change, nil
+}
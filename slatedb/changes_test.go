@@ -0,0 +1,72 @@
+package slatedb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore"
+)
+
+func TestChanges(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	bucket := objstore.NewInMemBucket()
+	options := testDBOptions(0, 1024)
+	options.GCMinAge = time.Millisecond
+	db, err := OpenWithOptions(ctx, "/tmp/test_kv_store", bucket, options)
+	require.NoError(t, err)
+	defer func() { _ = db.Close(ctx) }()
+
+	require.NoError(t, db.Put(ctx, []byte("key1"), []byte("value1")))
+	require.NoError(t, db.Put(ctx, []byte("key2"), []byte("value2")))
+	require.NoError(t, db.Delete(ctx, []byte("key1")))
+
+	stream, err := db.Changes(ctx, 0)
+	require.NoError(t, err)
+	var changes []WALEntry
+	for i := 0; i < 3; i++ {
+		change, err := stream.Next(ctx)
+		require.NoError(t, err)
+		assert.False(t, change.Time.IsZero())
+		changes = append(changes, change)
+	}
+	assert.Equal(t, []byte("key1"), changes[0].Key)
+	assert.Equal(t, []byte("value1"), changes[0].Value)
+	assert.Equal(t, []byte("key2"), changes[1].Key)
+	assert.Equal(t, []byte("key1"), changes[2].Key)
+	assert.True(t, changes[2].Tombstone)
+	assert.Less(t, changes[0].Seq, changes[1].Seq)
+	assert.Less(t, changes[1].Seq, changes[2].Seq)
+
+	// the stream waits for the next change to be written
+	waitCtx, waitCancel := context.WithTimeout(ctx, 200*time.Millisecond)
+	_, err = stream.Next(waitCtx)
+	waitCancel()
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	require.NoError(t, db.Put(ctx, []byte("key3"), []byte("value3")))
+	change, err := stream.Next(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("key3"), change.Key)
+
+	// a stream begins after the sequence number passed to Changes
+	stream, err = db.Changes(ctx, changes[1].Seq)
+	require.NoError(t, err)
+	change, err = stream.Next(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, changes[2].Seq, change.Seq)
+
+	// the changes of the WAL SSTs deleted by the garbage collector are unavailable
+	require.NoError(t, db.FlushMemtableToL0())
+	time.Sleep(10 * time.Millisecond)
+	report, err := db.CollectGarbage(ctx)
+	require.NoError(t, err)
+	require.Positive(t, report.WALSSTs)
+	_, err = db.Changes(ctx, 0)
+	assert.ErrorIs(t, err, ErrChangesUnavailable)
+	_, err = db.Changes(ctx, db.state.LastSeq())
+	assert.NoError(t, err)
+}
@@ -0,0 +1,98 @@
+package slatedb
+
+import (
+	"context"
+	"time"
+
+	"github.com/slatedb/slatedb-go/internal"
+	"github.com/slatedb/slatedb-go/slatedb/config"
+	"github.com/slatedb/slatedb-go/slatedb/manifest"
+	"github.com/slatedb/slatedb-go/slatedb/state"
+	"github.com/slatedb/slatedb-go/slatedb/store"
+)
+
+// Checkpoint references the manifest which describes the state of the database at the time the
+// checkpoint was created, see DB.CreateCheckpoint
+type Checkpoint = manifest.Checkpoint
+
+// CreateCheckpoint flushes the WAL and writes the current state of the database to a new manifest
+// along with a checkpoint which references it. The manifest, and the SSTs it references, are retained
+// until the checkpoint is deleted with DeleteCheckpoint or expires, such that the state of the database
+// at the time of the checkpoint can be read after the SSTs have been compacted.
+func (db *DB) CreateCheckpoint(ctx context.Context, options config.CheckpointOptions) (Checkpoint, error) {
+	if options.Lifetime < 0 {
+		return Checkpoint{}, internal.ErrInvalidArgument("argument 'Lifetime' cannot be negative")
+	}
+	if db.opts.ReadOnly {
+		return Checkpoint{}, ErrReadOnly
+	}
+	if err := db.FlushWAL(ctx); err != nil {
+		return Checkpoint{}, err
+	}
+
+	now := db.opts.Clock.Now()
+	var expireTime time.Time
+	if options.Lifetime > 0 {
+		expireTime = time.Unix(now.Add(options.Lifetime).Unix(), 0).UTC()
+	}
+	flusher := MemtableFlusher{
+		db:       db,
+		manifest: db.manifest,
+		log:      db.opts.Log,
+	}
+	checkpoint, err := db.manifest.CreateCheckpoint(db.opts.Log, now, expireTime, func() (*state.CoreStateSnapshot, error) {
+		if err := flusher.loadManifest(); err != nil {
+			return nil, err
+		}
+		return db.state.CoreStateSnapshot(), nil
+	})
+	if err != nil {
+		return Checkpoint{}, err
+	}
+	db.opts.Log.Info("created checkpoint", "checkpoint_id", checkpoint.ID, "manifest_id", checkpoint.ManifestID)
+	return checkpoint, nil
+}
+
+// ListCheckpoints returns the checkpoints recorded in the latest manifest, including expired
+// checkpoints which have not yet been removed. Expired checkpoints are removed from the
+// manifest when a checkpoint is created.
+func (db *DB) ListCheckpoints() ([]Checkpoint, error) {
+	if db.opts.ReadOnly {
+		stored, err := store.LoadStoredManifest(db.manifestStore)
+		if err != nil {
+			return nil, err
+		}
+		sm, ok := stored.Get()
+		if !ok {
+			return nil, internal.Err("manifest no longer exists")
+		}
+		return sm.Checkpoints(), nil
+	}
+
+	flusher := MemtableFlusher{
+		db:       db,
+		manifest: db.manifest,
+		log:      db.opts.Log,
+	}
+	if err := flusher.loadManifest(); err != nil {
+		return nil, err
+	}
+	return db.manifest.Checkpoints(), nil
+}
+
+// DeleteCheckpoint removes the checkpoint with `id`, such that the manifest it references may be
+// pruned. Deleting a checkpoint which does not exist is not an error.
+func (db *DB) DeleteCheckpoint(id uint64) error {
+	if db.opts.ReadOnly {
+		return ErrReadOnly
+	}
+	flusher := MemtableFlusher{
+		db:       db,
+		manifest: db.manifest,
+		log:      db.opts.Log,
+	}
+	if err := flusher.loadManifest(); err != nil {
+		return err
+	}
+	return db.manifest.DeleteCheckpoint(id)
+}
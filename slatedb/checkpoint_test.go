@@ -0,0 +1,62 @@
+package slatedb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore"
+
+	"github.com/slatedb/slatedb-go/slatedb/config"
+)
+
+func TestCheckpoints(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	bucket := objstore.NewInMemBucket()
+	dbPath := "/tmp/test_kv_store"
+	db, err := OpenWithOptions(ctx, dbPath, bucket, testDBOptions(0, 1024))
+	require.NoError(t, err)
+	defer func() { _ = db.Close(ctx) }()
+
+	require.NoError(t, db.Put(ctx, []byte("key1"), []byte("value1")))
+	require.NoError(t, db.FlushMemtableToL0())
+
+	_, err = db.CreateCheckpoint(ctx, config.CheckpointOptions{Lifetime: -time.Second})
+	assert.Error(t, err)
+	first, err := db.CreateCheckpoint(ctx, config.DefaultCheckpointOptions())
+	require.NoError(t, err)
+	assert.True(t, first.ExpireTime.IsZero())
+	second, err := db.CreateCheckpoint(ctx, config.CheckpointOptions{Lifetime: time.Hour})
+	require.NoError(t, err)
+	assert.False(t, second.ExpireTime.IsZero())
+	assert.Greater(t, second.ManifestID, first.ManifestID)
+
+	checkpoints, err := db.ListCheckpoints()
+	require.NoError(t, err)
+	assert.Equal(t, []Checkpoint{first, second}, checkpoints)
+
+	// the checkpoints are visible to a read-only DB, which cannot modify them
+	readOnly := testDBOptions(0, 1024)
+	readOnly.ReadOnly = true
+	reader, err := OpenWithOptions(ctx, dbPath, bucket, readOnly)
+	require.NoError(t, err)
+	defer func() { _ = reader.Close(ctx) }()
+	checkpoints, err = reader.ListCheckpoints()
+	require.NoError(t, err)
+	assert.Len(t, checkpoints, 2)
+	_, err = reader.CreateCheckpoint(ctx, config.DefaultCheckpointOptions())
+	assert.ErrorIs(t, err, ErrReadOnly)
+	assert.ErrorIs(t, reader.DeleteCheckpoint(first.ID), ErrReadOnly)
+
+	// checkpoints are retained when the manifest is written by a memtable flush
+	require.NoError(t, db.Put(ctx, []byte("key2"), []byte("value2")))
+	require.NoError(t, db.FlushMemtableToL0())
+	require.NoError(t, db.DeleteCheckpoint(first.ID))
+	checkpoints, err = db.ListCheckpoints()
+	require.NoError(t, err)
+	assert.Equal(t, []Checkpoint{second}, checkpoints)
+}
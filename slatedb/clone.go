@@ -0,0 +1,125 @@
+package slatedb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/thanos-io/objstore"
+
+	"github.com/slatedb/slatedb-go/internal"
+	"github.com/slatedb/slatedb-go/internal/sstable"
+	"github.com/slatedb/slatedb-go/slatedb/config"
+	"github.com/slatedb/slatedb-go/slatedb/manifest"
+	"github.com/slatedb/slatedb-go/slatedb/store"
+)
+
+// CreateClone creates a new database at `clonePath` which holds the state of the database at
+// `sourcePath` at the time the checkpoint with `checkpointID` was created. The SSTs of the source
+// database are referenced by the manifest of the clone rather than copied, such that a clone of a
+// large database is cheap to create. Only the WAL SSTs which were not yet flushed to L0 are copied.
+//
+// The clone may be opened and written to like any other database, and writes to the clone are not
+// visible to the source database. The checkpoint must not be deleted, nor expire, while the clone
+// is in use, as it prevents the referenced SSTs from being deleted from the source database.
+func CreateClone(ctx context.Context, clonePath string, sourcePath string, checkpointID uint64, bucket objstore.Bucket) error {
+	return CreateCloneWithOptions(ctx, clonePath, sourcePath, checkpointID, bucket, config.DefaultCloneOptions())
+}
+
+// CreateCloneWithOptions is like CreateClone, but checks the expiry of the checkpoint against
+// config.CloneOptions.Clock
+func CreateCloneWithOptions(ctx context.Context, clonePath string, sourcePath string, checkpointID uint64,
+	bucket objstore.Bucket, options config.CloneOptions) error {
+	if clonePath == sourcePath {
+		return internal.ErrInvalidArgument("argument 'clonePath' cannot be the same as 'sourcePath'")
+	}
+
+	sourceManifestStore := store.NewManifestStore(sourcePath, bucket)
+	stored, err := store.LoadStoredManifest(sourceManifestStore)
+	if err != nil {
+		return err
+	}
+	sm, ok := stored.Get()
+	if !ok {
+		return internal.Err("no database found at path '%s'", sourcePath)
+	}
+
+	idx := slices.IndexFunc(sm.Checkpoints(), func(c manifest.Checkpoint) bool { return c.ID == checkpointID })
+	if idx < 0 {
+		return internal.ErrInvalidArgument("checkpoint '%d' not found in database at path '%s'", checkpointID, sourcePath)
+	}
+	checkpoint := sm.Checkpoints()[idx]
+	if options.Clock == nil {
+		options.Clock = config.SystemClock{}
+	}
+	if checkpoint.Expired(options.Clock.Now()) {
+		return internal.ErrInvalidArgument("checkpoint '%d' has expired", checkpointID)
+	}
+
+	cloneManifestStore := store.NewManifestStore(clonePath, bucket)
+	existing, err := store.LoadStoredManifest(cloneManifestStore)
+	if err != nil {
+		return err
+	}
+	if existing.IsPresent() {
+		return internal.ErrInvalidArgument("a database already exists at path '%s'", clonePath)
+	}
+
+	source, err := sourceManifestStore.ReadManifest(checkpoint.ManifestID)
+	if err != nil {
+		return fmt.Errorf("while reading manifest of checkpoint '%d': %w", checkpointID, err)
+	}
+
+	// SSTs which the source itself references from an external database are read from that
+	// database, every other SST is read from the source.
+	external := make(map[ulid.ULID]bool)
+	for _, db := range source.ExternalDBs {
+		for _, id := range db.SSTIDs {
+			external[id] = true
+		}
+	}
+	core := source.Core.Snapshot()
+	var sstIDs []ulid.ULID
+	addSST := func(handle sstable.Handle) {
+		if id, ok := handle.Id.CompactedID().Get(); ok && !external[id] {
+			sstIDs = append(sstIDs, id)
+		}
+	}
+	for _, handle := range core.L0 {
+		addSST(handle)
+	}
+	for _, sr := range core.Compacted {
+		for _, handle := range sr.SSTList {
+			addSST(handle)
+		}
+	}
+
+	clone := &manifest.Manifest{
+		Core:     core.ToCoreState(),
+		Features: source.Features,
+		ExternalDBs: append(slices.Clone(source.ExternalDBs), manifest.ExternalDB{
+			Path:         sourcePath,
+			CheckpointID: checkpointID,
+			SSTIDs:       sstIDs,
+		}),
+		FilterSidecars: source.FilterSidecars,
+	}
+
+	// The WAL SSTs which were not flushed to L0 are replayed when the clone is opened, and
+	// are copied such that later writes to the WAL of the source are not replayed.
+	sourceTableStore := store.NewTableStore(bucket, sstable.DefaultConfig(), sourcePath)
+	cloneTableStore := store.NewTableStore(bucket, sstable.DefaultConfig(), clonePath)
+	for id := core.LastCompactedWalSSTID.Load() + 1; id < core.NextWalSstID.Load(); id++ {
+		if err := sourceTableStore.CopySST(ctx, sstable.NewIDWal(id), cloneTableStore); err != nil {
+			return fmt.Errorf("while copying WAL: %w", err)
+		}
+	}
+
+	_, err = store.NewStoredManifestFrom(cloneManifestStore, clone)
+	if errors.Is(err, internal.ErrAlreadyExists) {
+		return internal.ErrInvalidArgument("a database already exists at path '%s'", clonePath)
+	}
+	return err
+}
@@ -0,0 +1,101 @@
+package slatedb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore"
+
+	"github.com/slatedb/slatedb-go/slatedb/config"
+)
+
+func TestCreateClone(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	bucket := objstore.NewInMemBucket()
+	sourcePath := "/tmp/test_kv_store"
+	clonePath := "/tmp/test_kv_store_clone"
+	source, err := OpenWithOptions(ctx, sourcePath, bucket, testDBOptions(0, 1024))
+	require.NoError(t, err)
+	defer func() { _ = source.Close(ctx) }()
+
+	require.NoError(t, source.Put(ctx, []byte("key1"), []byte("l0")))
+	require.NoError(t, source.FlushMemtableToL0())
+	require.NoError(t, source.Put(ctx, []byte("key2"), []byte("wal")))
+	checkpoint, err := source.CreateCheckpoint(ctx, config.DefaultCheckpointOptions())
+	require.NoError(t, err)
+	require.NoError(t, source.Put(ctx, []byte("key3"), []byte("after")))
+
+	assert.Error(t, CreateClone(ctx, clonePath, sourcePath, checkpoint.ID+100, bucket))
+	assert.Error(t, CreateClone(ctx, sourcePath, sourcePath, checkpoint.ID, bucket))
+	require.NoError(t, CreateClone(ctx, clonePath, sourcePath, checkpoint.ID, bucket))
+	assert.Error(t, CreateClone(ctx, clonePath, sourcePath, checkpoint.ID, bucket))
+
+	report, err := Verify(ctx, clonePath, bucket)
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.SSTs)
+
+	clone, err := OpenWithOptions(ctx, clonePath, bucket, testDBOptions(0, 1024))
+	require.NoError(t, err)
+	defer func() { _ = clone.Close(ctx) }()
+
+	// the clone holds the state of the source at the time of the checkpoint
+	it, err := clone.Scan(ctx, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"key1": "l0", "key2": "wal"}, scanAll(t, it))
+
+	// writes to the clone are not visible to the source
+	require.NoError(t, clone.Delete(ctx, []byte("key1")))
+	require.NoError(t, clone.Put(ctx, []byte("key4"), []byte("clone")))
+	require.NoError(t, clone.FlushMemtableToL0())
+	it, err = clone.Scan(ctx, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"key2": "wal", "key4": "clone"}, scanAll(t, it))
+	it, err = source.Scan(ctx, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"key1": "l0", "key2": "wal", "key3": "after"}, scanAll(t, it))
+
+	// a clone of the clone reads the SSTs of both the source and the clone
+	second, err := clone.CreateCheckpoint(ctx, config.DefaultCheckpointOptions())
+	require.NoError(t, err)
+	require.NoError(t, CreateClone(ctx, "/tmp/test_kv_store_clone2", clonePath, second.ID, bucket))
+	readOnly := testDBOptions(0, 1024)
+	readOnly.ReadOnly = true
+	clone2, err := OpenWithOptions(ctx, "/tmp/test_kv_store_clone2", bucket, readOnly)
+	require.NoError(t, err)
+	defer func() { _ = clone2.Close(ctx) }()
+	it, err = clone2.Scan(ctx, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"key2": "wal", "key4": "clone"}, scanAll(t, it))
+}
+
+func TestCreateCloneWithClock(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	bucket := objstore.NewInMemBucket()
+	sourcePath := "/tmp/test_kv_store"
+	clock := config.NewManualClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	options := testDBOptions(0, 1024)
+	options.Clock = clock
+	source, err := OpenWithOptions(ctx, sourcePath, bucket, options)
+	require.NoError(t, err)
+	defer func() { _ = source.Close(ctx) }()
+	checkpoint, err := source.CreateCheckpoint(ctx, config.CheckpointOptions{Lifetime: time.Hour})
+	require.NoError(t, err)
+
+	// the expiry of the checkpoint is checked against the clock of the source rather than the
+	// wall clock
+	assert.Error(t, CreateClone(ctx, "/tmp/test_kv_store_clone", sourcePath, checkpoint.ID, bucket))
+	cloneOptions := config.CloneOptions{Clock: clock}
+	require.NoError(t, CreateCloneWithOptions(ctx, "/tmp/test_kv_store_clone", sourcePath, checkpoint.ID,
+		bucket, cloneOptions))
+
+	clock.Advance(2 * time.Hour)
+	assert.Error(t, CreateCloneWithOptions(ctx, "/tmp/test_kv_store_clone2", sourcePath, checkpoint.ID,
+		bucket, cloneOptions))
+}
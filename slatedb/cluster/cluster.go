@@ -0,0 +1,183 @@
+// Package cluster splits the keys of a dataset across several SlateDB databases, each a
+// partition with its own path and writer, for applications which need more write throughput
+// than a single writer provides but do not need a distributed layer. Keys are mapped to
+// partitions on the client with rendezvous hashing, such that adding a partition only moves
+// the keys which the new partition takes over, see Resplit.
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/cespare/xxhash/v2"
+
+	"github.com/slatedb/slatedb-go/internal"
+	"github.com/slatedb/slatedb-go/slatedb"
+	"github.com/slatedb/slatedb-go/slatedb/config"
+)
+
+// resplitBatchKeys is the number of keys Resplit copies before the copies are flushed and the
+// keys are deleted from the source partition
+const resplitBatchKeys = 1000
+
+// Partitioner maps keys to partitions with rendezvous (highest random weight) hashing. Each key
+// is assigned to the partition whose hash of the key and the partition path is the highest,
+// such that every client with the same partitions assigns a key to the same partition, and
+// adding a partition moves about 1/N of the keys, all to the new partition.
+type Partitioner struct {
+	paths  []string
+	hashes []uint64
+}
+
+// NewPartitioner returns a Partitioner of the partitions `paths`, which are the paths of the
+// partition databases. The order of the paths does not change the partition of a key.
+func NewPartitioner(paths ...string) (*Partitioner, error) {
+	if len(paths) == 0 {
+		return nil, internal.ErrInvalidArgument("at least one partition is required")
+	}
+	p := &Partitioner{
+		paths:  make([]string, 0, len(paths)),
+		hashes: make([]uint64, 0, len(paths)),
+	}
+	for _, path := range paths {
+		if path == "" {
+			return nil, internal.ErrInvalidArgument("partition path must not be empty")
+		}
+		if slices.Contains(p.paths, path) {
+			return nil, internal.ErrInvalidArgument("partition '%s' is listed more than once", path)
+		}
+		p.paths = append(p.paths, path)
+		p.hashes = append(p.hashes, xxhash.Sum64String(path))
+	}
+	return p, nil
+}
+
+// Paths returns the paths of the partitions
+func (p *Partitioner) Paths() []string {
+	return slices.Clone(p.paths)
+}
+
+// WithPartitions returns a Partitioner of the partitions of `p` along with the new partitions
+// `paths`. Keys assigned to a different partition by the returned Partitioner are moved by Resplit.
+func (p *Partitioner) WithPartitions(paths ...string) (*Partitioner, error) {
+	return NewPartitioner(append(slices.Clone(p.paths), paths...)...)
+}
+
+// PartitionForKey returns the path of the partition which `key` is assigned to
+func (p *Partitioner) PartitionForKey(key []byte) string {
+	keyHash := xxhash.Sum64(key)
+	best, bestWeight := 0, uint64(0)
+	for i, h := range p.hashes {
+		w := weight(keyHash, h)
+		// Ties are broken by path, such that the order of the paths does not matter
+		if i == 0 || w > bestWeight || (w == bestWeight && p.paths[i] < p.paths[best]) {
+			best, bestWeight = i, w
+		}
+	}
+	return p.paths[best]
+}
+
+// weight returns the weight of the partition with the path hash `pathHash` for the key with the
+// hash `keyHash`, mixed with the finalizer of splitmix64 such that the weights of a key for
+// each partition are independent
+func weight(keyHash, pathHash uint64) uint64 {
+	z := keyHash ^ (pathHash * 0x9e3779b97f4a7c15)
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+// ------------------------------------------------
+// Resplit
+// ------------------------------------------------
+
+// ResplitReport summarizes the keys moved by Resplit
+type ResplitReport struct {
+	// The number of keys of the source partition which were scanned
+	Scanned int
+
+	// The number of keys which were moved to another partition, by partition path
+	Moved map[string]int
+}
+
+// Resplit moves every key of the partition `from` which `p` assigns to another partition to
+// that partition, such as after a partition is added with WithPartitions. `dbs` holds the open
+// database of each partition of `p`, keyed by path. Each batch of keys is written to its new
+// partition and flushed before it is deleted from `from`, such that an interrupted Resplit can
+// be run again without losing keys.
+//
+// Resplit copies the value of each key as of when it was called, so writes to the keys of `from`
+// must be paused until Resplit returns, after which they are routed with `p`. Reads of a key
+// which is not found in its partition of `p` may fall back to `from` until Resplit returns. The
+// remaining TTL of a key written with PutWithTTL is not preserved by the move.
+func Resplit(ctx context.Context, p *Partitioner, from string, dbs map[string]*slatedb.DB) (ResplitReport, error) {
+	report := ResplitReport{Moved: make(map[string]int)}
+	for _, path := range p.paths {
+		if dbs[path] == nil {
+			return report, internal.ErrInvalidArgument("no database was provided for partition '%s'", path)
+		}
+	}
+	src, ok := dbs[from]
+	if !ok || src == nil {
+		return report, internal.ErrInvalidArgument("no database was provided for partition '%s'", from)
+	}
+
+	iter, err := src.Scan(ctx, nil, nil)
+	if err != nil {
+		return report, fmt.Errorf("while scanning partition '%s': %w", from, err)
+	}
+	defer iter.Close()
+	var batch [][]byte
+	written := make(map[string]struct{})
+	for {
+		kv, ok := iter.Next(ctx)
+		if !ok {
+			break
+		}
+		report.Scanned++
+		to := p.PartitionForKey(kv.Key)
+		if to == from {
+			continue
+		}
+		if err := dbs[to].PutWithOptions(ctx, kv.Key, kv.Value, config.WriteOptions{AwaitDurable: false}); err != nil {
+			return report, fmt.Errorf("while writing key to partition '%s': %w", to, err)
+		}
+		written[to] = struct{}{}
+		batch = append(batch, bytes.Clone(kv.Key))
+		report.Moved[to]++
+
+		if len(batch) >= resplitBatchKeys {
+			if err := deleteMoved(ctx, src, dbs, written, batch); err != nil {
+				return report, err
+			}
+			batch = batch[:0]
+			clear(written)
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return report, fmt.Errorf("while scanning partition '%s': %w", from, err)
+	}
+	return report, deleteMoved(ctx, src, dbs, written, batch)
+}
+
+// deleteMoved flushes the partitions `written` to which the keys `batch` were copied, and then
+// deletes the keys from the source partition `src`
+func deleteMoved(ctx context.Context, src *slatedb.DB, dbs map[string]*slatedb.DB,
+	written map[string]struct{}, batch [][]byte) error {
+	if len(batch) == 0 {
+		return nil
+	}
+	for path := range written {
+		if err := dbs[path].FlushWAL(ctx); err != nil {
+			return fmt.Errorf("while flushing partition '%s': %w", path, err)
+		}
+	}
+	for _, key := range batch {
+		if err := src.DeleteWithOptions(ctx, key, config.WriteOptions{AwaitDurable: false}); err != nil {
+			return fmt.Errorf("while deleting moved key: %w", err)
+		}
+	}
+	return src.FlushWAL(ctx)
+}
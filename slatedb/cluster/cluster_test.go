@@ -0,0 +1,108 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore"
+
+	"github.com/slatedb/slatedb-go/slatedb"
+	"github.com/slatedb/slatedb-go/slatedb/config"
+)
+
+func TestPartitionForKey(t *testing.T) {
+	_, err := NewPartitioner()
+	assert.ErrorContains(t, err, "at least one partition is required")
+	_, err = NewPartitioner("a", "a")
+	assert.ErrorContains(t, err, "partition 'a' is listed more than once")
+
+	p, err := NewPartitioner("p0", "p1", "p2")
+	require.NoError(t, err)
+	reordered, err := NewPartitioner("p2", "p0", "p1")
+	require.NoError(t, err)
+	grown, err := p.WithPartitions("p3")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"p0", "p1", "p2", "p3"}, grown.Paths())
+
+	counts := make(map[string]int)
+	moved := 0
+	const numKeys = 10000
+	for i := 0; i < numKeys; i++ {
+		key := []byte(fmt.Sprintf("key%05d", i))
+		path := p.PartitionForKey(key)
+		counts[path]++
+		assert.Equal(t, path, reordered.PartitionForKey(key))
+
+		// A key only moves to the new partition
+		if newPath := grown.PartitionForKey(key); newPath != path {
+			assert.Equal(t, "p3", newPath)
+			moved++
+		}
+	}
+	for _, path := range p.Paths() {
+		assert.InDelta(t, numKeys/3, counts[path], numKeys/10, "partition %s", path)
+	}
+	assert.InDelta(t, numKeys/4, moved, numKeys/10)
+}
+
+func TestResplit(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	bucket := objstore.NewInMemBucket()
+	options := config.DefaultDBOptions()
+	options.FlushInterval = 10 * time.Millisecond
+	options.CompactorOptions = nil
+	dbs := make(map[string]*slatedb.DB)
+	for _, path := range []string{"/p0", "/p1"} {
+		db, err := slatedb.OpenWithOptions(ctx, path, bucket, options)
+		require.NoError(t, err)
+		defer func() { _ = db.Close(ctx) }()
+		dbs[path] = db
+	}
+
+	// Every key is written to the only partition
+	p, err := NewPartitioner("/p0")
+	require.NoError(t, err)
+	const numKeys = 100
+	for i := 0; i < numKeys; i++ {
+		key := []byte(fmt.Sprintf("key%03d", i))
+		require.Equal(t, "/p0", p.PartitionForKey(key))
+		require.NoError(t, dbs["/p0"].PutWithOptions(ctx, key, key, config.WriteOptions{AwaitDurable: false}))
+	}
+	require.NoError(t, dbs["/p0"].FlushWAL(ctx))
+
+	grown, err := p.WithPartitions("/p1")
+	require.NoError(t, err)
+	_, err = Resplit(ctx, grown, "/p0", map[string]*slatedb.DB{"/p0": dbs["/p0"]})
+	assert.ErrorContains(t, err, "no database was provided for partition '/p1'")
+
+	report, err := Resplit(ctx, grown, "/p0", dbs)
+	require.NoError(t, err)
+	assert.Equal(t, numKeys, report.Scanned)
+	assert.Greater(t, report.Moved["/p1"], 0)
+
+	// Each key is only found in its partition
+	for i := 0; i < numKeys; i++ {
+		key := []byte(fmt.Sprintf("key%03d", i))
+		path := grown.PartitionForKey(key)
+		for other, db := range dbs {
+			val, err := db.Get(ctx, key)
+			if other == path {
+				require.NoError(t, err)
+				assert.Equal(t, key, val)
+			} else {
+				assert.ErrorIs(t, err, slatedb.ErrKeyNotFound)
+			}
+		}
+	}
+
+	// Resplit is idempotent
+	report, err = Resplit(ctx, grown, "/p0", dbs)
+	require.NoError(t, err)
+	assert.Equal(t, 0, report.Moved["/p1"])
+}
@@ -1,12 +1,22 @@
 package common
 
-import "github.com/gammazero/deque"
+import (
+	"github.com/gammazero/deque"
+
+	"github.com/slatedb/slatedb-go/internal"
+)
+
+// ErrFenced indicates a newer writer, or a newer compactor, has opened the database and fenced
+// this one by incrementing its epoch in the manifest, after which this writer or compactor can
+// no longer update the database
+var ErrFenced = internal.ErrFenced
 
 const (
-	// uint16 and uint32 sizes are constant as per https://go.dev/ref/spec#Size_and_alignment_guarantees
+	// uint16, uint32 and uint64 sizes are constant as per https://go.dev/ref/spec#Size_and_alignment_guarantees
 
 	SizeOfUint16 = 2
 	SizeOfUint32 = 4
+	SizeOfUint64 = 8
 )
 
 type Range struct {
@@ -0,0 +1,54 @@
+package slatedb
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/slatedb/slatedb-go/internal"
+)
+
+// CompactRange flushes the memtable to L0 and compacts the L0 SSTs and sorted runs which overlap the
+// key range [start, end) into a single sorted run at the bottom level, where a nil start or end is
+// unbounded. Tombstones, and the versions of the keys they delete, are dropped by the compaction,
+// such that CompactRange reclaims the space of a bulk delete on demand rather than once the
+// compactor merges the sorted runs.
+//
+// Each sorted run spans the whole key space, so in practice every L0 SST and sorted run is merged.
+// CompactRange waits for the compactions in progress to finish and returns once the compaction is
+// written to the manifest. The database must be opened with config.DBOptions.CompactorOptions.
+func (db *DB) CompactRange(ctx context.Context, start, end []byte) error {
+	if db.opts.ReadOnly {
+		return ErrReadOnly
+	}
+	if start != nil && end != nil && bytes.Compare(start, end) >= 0 {
+		return internal.ErrInvalidArgument("start '%s' must be less than end '%s'", start, end)
+	}
+	if db.compactor == nil {
+		return internal.ErrInvalidArgument("CompactRange requires CompactorOptions")
+	}
+	if err := db.writeErr(); err != nil {
+		return err
+	}
+	if err := db.flushToL0(ctx); err != nil {
+		return err
+	}
+	if err := db.compactor.CompactRange(ctx, start, end); err != nil {
+		return fmt.Errorf("while compacting range: %w", err)
+	}
+	return nil
+}
+
+// flushToL0 flushes the WAL, and then the memtable to L0, such that every write acknowledged before
+// flushToL0 was called is in L0
+func (db *DB) flushToL0(ctx context.Context) error {
+	if err := db.FlushWAL(ctx); err != nil {
+		return fmt.Errorf("while flushing the WAL: %w", err)
+	}
+	if db.walEnabled() && db.state.Memtable().Size() > 0 {
+		if err := db.FlushMemtableToL0(); err != nil {
+			return fmt.Errorf("while flushing the memtable: %w", err)
+		}
+	}
+	return nil
+}
@@ -3,10 +3,12 @@ package compacted
 import (
 	"bytes"
 	"context"
+	"slices"
 	"sort"
 
 	"github.com/samber/mo"
 
+	"github.com/slatedb/slatedb-go/internal"
 	"github.com/slatedb/slatedb-go/internal/assert"
 	"github.com/slatedb/slatedb-go/internal/sstable"
 	"github.com/slatedb/slatedb-go/internal/types"
@@ -60,10 +62,24 @@ type SortedRunIterator struct {
 	sstListIter   *SSTListIterator
 	tableStore    sstable.TableStore
 	warn          types.ErrWarn
+
+	// A reverse iterator visits the SSTs from last to first and returns keys in descending order
+	reverse bool
+
+	// next is the iterator of the SST after the current one, which is opened while the current
+	// SST is iterated if TableStore.PrefetchConcurrency is non-zero
+	next *pendingIterator
+}
+
+// pendingIterator is an sstable.Iterator being opened in the background
+type pendingIterator struct {
+	done chan struct{}
+	iter *sstable.Iterator
+	err  error
 }
 
 func NewSortedRunIterator(ctx context.Context, sr SortedRun, store sstable.TableStore) (*SortedRunIterator, error) {
-	return newSortedRunIter(ctx, sr.SSTList, store, mo.None[[]byte]())
+	return newSortedRunIter(ctx, sr.SSTList, store, mo.None[[]byte](), false)
 }
 
 func NewSortedRunIteratorFromKey(ctx context.Context, sr SortedRun, key []byte, store sstable.TableStore) (*SortedRunIterator, error) {
@@ -73,13 +89,34 @@ func NewSortedRunIteratorFromKey(ctx context.Context, sr SortedRun, key []byte,
 		sstList = sr.SSTList[idx:]
 	}
 
-	return newSortedRunIter(ctx, sstList, store, mo.Some(key))
+	return newSortedRunIter(ctx, sstList, store, mo.Some(key), false)
+}
+
+// NewReverseSortedRunIterator returns an iterator over the sorted run which returns keys in descending order
+func NewReverseSortedRunIterator(ctx context.Context, sr SortedRun, store sstable.TableStore) (*SortedRunIterator, error) {
+	sstList := slices.Clone(sr.SSTList)
+	slices.Reverse(sstList)
+	return newSortedRunIter(ctx, sstList, store, mo.None[[]byte](), true)
+}
+
+// NewReverseSortedRunIteratorFromKey returns an iterator which returns keys in descending order starting
+// at the given key, or at the last key less than the given key if the key is not in the sorted run.
+func NewReverseSortedRunIteratorFromKey(ctx context.Context, sr SortedRun, key []byte,
+	store sstable.TableStore) (*SortedRunIterator, error) {
+	var sstList []sstable.Handle
+	// If no SST has a first key at or before the key, every key of the sorted run is greater than the key
+	if idx, ok := sr.indexOfSSTWithKey(key).Get(); ok {
+		sstList = slices.Clone(sr.SSTList[:idx+1])
+		slices.Reverse(sstList)
+	}
+	return newSortedRunIter(ctx, sstList, store, mo.Some(key), true)
 }
 
 func newSortedRunIter(ctx context.Context,
 	sstList []sstable.Handle,
 	store sstable.TableStore,
-	fromKey mo.Option[[]byte]) (*SortedRunIterator, error) {
+	fromKey mo.Option[[]byte],
+	reverse bool) (*SortedRunIterator, error) {
 
 	sstListIter := newSSTListIterator(sstList)
 	currentKVIter := mo.None[*sstable.Iterator]()
@@ -87,27 +124,91 @@ func newSortedRunIter(ctx context.Context,
 	if ok {
 		var iter *sstable.Iterator
 		var err error
-		if fromKey.IsPresent() {
-			key, _ := fromKey.Get()
+		key, hasKey := fromKey.Get()
+		switch {
+		case hasKey && reverse:
+			iter, err = sstable.NewReverseIteratorAtKey(ctx, &sst, key, store)
+		case hasKey:
 			iter, err = sstable.NewIteratorAtKey(ctx, &sst, key, store)
-			if err != nil {
-				return nil, err
-			}
-		} else {
+		case reverse:
+			iter, err = sstable.NewReverseIterator(ctx, &sst, store)
+		default:
 			iter, err = sstable.NewIterator(ctx, &sst, store)
-			if err != nil {
-				return nil, err
-			}
+		}
+		if err != nil {
+			return nil, err
 		}
 
 		currentKVIter = mo.Some(iter)
 	}
 
-	return &SortedRunIterator{
+	iter := &SortedRunIterator{
 		currentKVIter: currentKVIter,
 		sstListIter:   sstListIter,
 		tableStore:    store,
-	}, nil
+		reverse:       reverse,
+	}
+	iter.openNext(ctx)
+	return iter, nil
+}
+
+// openNext starts opening the iterator of the next SST and prefetching its first blocks if
+// TableStore.PrefetchConcurrency is non-zero. As with sstable.Iterator.Prefetch, the requests
+// are bounded by the deadline of ctx rather than cancelled when ctx is done.
+func (iter *SortedRunIterator) openNext(ctx context.Context) {
+	if iter.tableStore.PrefetchConcurrency() == 0 {
+		return
+	}
+	sst, ok := iter.sstListIter.Next()
+	if !ok {
+		return
+	}
+	next := &pendingIterator{done: make(chan struct{})}
+	iter.next = next
+
+	fetchCtx, cancel := internal.DetachContext(ctx)
+	go func() {
+		defer close(next.done)
+		defer cancel()
+		next.iter, next.err = iter.newSSTIterator(fetchCtx, &sst)
+		if next.err == nil {
+			next.iter.Prefetch(fetchCtx)
+		}
+	}()
+}
+
+// nextSSTIterator returns the iterator of the next SST, or nil if every SST has been iterated
+func (iter *SortedRunIterator) nextSSTIterator(ctx context.Context) (*sstable.Iterator, error) {
+	if iter.tableStore.PrefetchConcurrency() == 0 {
+		sst, ok := iter.sstListIter.Next()
+		if !ok {
+			return nil, nil
+		}
+		return iter.newSSTIterator(ctx, &sst)
+	}
+
+	next := iter.next
+	if next == nil {
+		return nil, nil
+	}
+	select {
+	case <-next.done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	iter.next = nil
+	if next.err != nil {
+		return nil, next.err
+	}
+	iter.openNext(ctx)
+	return next.iter, nil
+}
+
+func (iter *SortedRunIterator) newSSTIterator(ctx context.Context, sst *sstable.Handle) (*sstable.Iterator, error) {
+	if iter.reverse {
+		return sstable.NewReverseIterator(ctx, sst, iter.tableStore)
+	}
+	return sstable.NewIterator(ctx, sst, iter.tableStore)
 }
 
 func (iter *SortedRunIterator) NextEntry(ctx context.Context) (types.RowEntry, bool) {
@@ -126,20 +227,18 @@ func (iter *SortedRunIterator) NextEntry(ctx context.Context) (types.RowEntry, b
 			}
 		}
 
-		sst, ok := iter.sstListIter.Next()
-		if !ok {
+		newKVIter, err := iter.nextSSTIterator(ctx)
+		if err != nil {
+			iter.warn.Add("while creating SSTable iterator: %s", err.Error())
+			return types.RowEntry{}, false
+		}
+		if newKVIter == nil {
 			if warn := kvIter.Warnings(); warn != nil {
 				iter.warn.Merge(warn)
 			}
 			return types.RowEntry{}, false
 		}
 
-		newKVIter, err := sstable.NewIterator(ctx, &sst, iter.tableStore)
-		if err != nil {
-			iter.warn.Add("while creating SSTable iterator: %s", err.Error())
-			return types.RowEntry{}, false
-		}
-
 		iter.currentKVIter = mo.Some(newKVIter)
 	}
 }
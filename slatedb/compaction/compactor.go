@@ -2,18 +2,34 @@ package compaction
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"slices"
 	"strconv"
+	"time"
 
 	"github.com/oklog/ulid/v2"
 	"github.com/samber/mo"
+	"github.com/slatedb/slatedb-go/internal"
+	"github.com/slatedb/slatedb-go/internal/profile"
 	"github.com/slatedb/slatedb-go/internal/sstable"
+	"github.com/slatedb/slatedb-go/internal/task"
 	"github.com/slatedb/slatedb-go/slatedb/compacted"
 	"github.com/slatedb/slatedb-go/slatedb/config"
+	"github.com/slatedb/slatedb-go/slatedb/state"
 	"github.com/slatedb/slatedb-go/slatedb/store"
 )
 
+// Scheduler decides which compactions the compactor runs, such that users may plug in their own
+// policies, such as compacting the SSTs of a tenant or compacting by age. SizeTieredCompactionScheduler
+// is used unless config.CompactorOptions.Scheduler is set.
 type Scheduler interface {
-	maybeScheduleCompaction(state *CompactorState) []Compaction
+	// ShouldCompact is called each time the compactor polls the manifest or finishes a compaction,
+	// and returns the compactions to start given the L0 SSTs and sorted runs of the database and the
+	// compactions in progress held by `state`. A compaction which conflicts with the state, such as
+	// a compaction whose sources are being compacted, is logged and skipped, see
+	// CompactorState.SubmitCompaction. The state must not be modified.
+	ShouldCompact(state *CompactorState) []Compaction
 }
 
 type CompactorMainMsg int
@@ -23,8 +39,10 @@ const (
 )
 
 type Result struct {
-	SortedRun *compacted.SortedRun
-	Error     error
+	// CompactionID is the Compaction.ID of the job which produced the result
+	CompactionID string
+	SortedRun    *compacted.SortedRun
+	Error        error
 }
 
 // ------------------------------------------------
@@ -50,6 +68,13 @@ func NewSourceIDSST(id ulid.ULID) SourceID {
 	}
 }
 
+func NewSourceIDSortedRun(id uint32) SourceID {
+	return SourceID{
+		typ:   SortedRunID,
+		value: strconv.FormatUint(uint64(id), 10),
+	}
+}
+
 func (s SourceID) SortedRunID() mo.Option[uint32] {
 	if s.typ != SortedRunID {
 		return mo.None[uint32]()
@@ -85,6 +110,7 @@ const (
 
 type Compaction struct {
 	Status      Status
+	id          string
 	sources     []SourceID
 	destination uint32
 }
@@ -92,14 +118,75 @@ type Compaction struct {
 func NewCompaction(sources []SourceID, destination uint32) Compaction {
 	return Compaction{
 		Status:      Submitted,
+		id:          ulid.Make().String(),
 		sources:     sources,
 		destination: destination,
 	}
 }
 
+// Sources returns the L0 SSTs and sorted runs merged by the compaction
+func (c Compaction) Sources() []SourceID {
+	return c.sources
+}
+
+// Destination returns the ID of the sorted run written by the compaction
+func (c Compaction) Destination() uint32 {
+	return c.destination
+}
+
+// ID returns the correlation ID of the compaction. The ID is included in every log line
+// related to the compaction, such that the lifecycle of the SSTs it reads and writes can
+// be reconstructed from the logs.
+func (c Compaction) ID() string {
+	return c.id
+}
+
+// errSourcesChanged is returned when the sources of a compaction are no longer referenced by the
+// latest manifest, such as when they were compacted by another compactor
+var errSourcesChanged = errors.New("compaction sources are no longer referenced by the manifest")
+
+// validateSources returns errSourcesChanged if a source of the compaction, as it was in `inputs`
+// when the compaction was started, is not referenced by `current`. Each L0 source must be an L0 SST
+// of `current`, and each sorted run source must be a sorted run of `current` with the same SSTs.
+func (c Compaction) validateSources(inputs *state.CoreStateSnapshot, current *state.CoreStateSnapshot) error {
+	l0s := make(map[ulid.ULID]bool)
+	for _, sst := range current.L0 {
+		if id, ok := sst.Id.CompactedID().Get(); ok {
+			l0s[id] = true
+		}
+	}
+	sstIDs := func(snapshot *state.CoreStateSnapshot, srID uint32) mo.Option[[]sstable.ID] {
+		for _, sr := range snapshot.Compacted {
+			if sr.ID == srID {
+				ids := make([]sstable.ID, 0, len(sr.SSTList))
+				for _, sst := range sr.SSTList {
+					ids = append(ids, sst.Id)
+				}
+				return mo.Some(ids)
+			}
+		}
+		return mo.None[[]sstable.ID]()
+	}
+
+	for _, src := range c.sources {
+		if id, ok := src.SstID().Get(); ok && !l0s[id] {
+			return fmt.Errorf("%w; L0 SST '%s' not found", errSourcesChanged, id)
+		}
+		if srID, ok := src.SortedRunID().Get(); ok {
+			expected, _ := sstIDs(inputs, srID).Get()
+			actual, found := sstIDs(current, srID).Get()
+			if !found || !slices.Equal(expected, actual) {
+				return fmt.Errorf("%w; sorted run '%d' has changed", errSourcesChanged, srID)
+			}
+		}
+	}
+	return nil
+}
+
 // Compactor - The Orchestrator checks with the Scheduler if Level0 needs to be compacted.
 // If compaction is needed, the Orchestrator gives Jobs to the Executor.
-// The Executor creates new goroutine for each Job and the results are written to a channel.
+// The Executor creates new goroutine for each Job, runs up to CompactorOptions.MaxConcurrentCompactions
+// of them at once, and writes the results to a channel which the Orchestrator commits one at a time.
 type Compactor struct {
 	orchestrator *Orchestrator
 }
@@ -119,6 +206,83 @@ func (c *Compactor) Close(ctx context.Context) error {
 	return c.orchestrator.shutdown(ctx)
 }
 
+// CompactRange compacts the L0 SSTs and sorted runs overlapping the key range [start, end), where a
+// nil start or end is unbounded, into a single sorted run at the bottom level, dropping tombstones and
+// the versions they delete. It waits for the compactions in flight to finish, and returns once the
+// compaction is committed to the manifest or `ctx` is done. See newManualCompaction.
+func (c *Compactor) CompactRange(ctx context.Context, start, end []byte) error {
+	m := &manualCompaction{start: start, end: end, done: make(chan error, 1)}
+	select {
+	case c.orchestrator.manualCh <- m:
+	case <-c.orchestrator.loop.Done():
+		return errCompactorClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case err := <-m.done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// CompactionsCompleted returns the number of compactions committed to the manifest
+// since the Compactor was created
+func (c *Compactor) CompactionsCompleted() uint64 {
+	return c.orchestrator.completed.Load()
+}
+
+// BytesRead returns the encoded size of the SSTs read by the compactions of the Compactor
+// since it was created, see sstable.Info.EncodedSize
+func (c *Compactor) BytesRead() uint64 {
+	return c.orchestrator.executor.bytesRead.Load()
+}
+
+// BytesWritten returns the encoded size of the SSTs written by the compactions of the
+// Compactor since it was created
+func (c *Compactor) BytesWritten() uint64 {
+	return c.orchestrator.executor.bytesWritten.Load()
+}
+
+// ManifestConflicts returns the number of manifest writes of the Compactor which conflicted
+// with a manifest written by another process since it was created
+func (c *Compactor) ManifestConflicts() uint64 {
+	return c.orchestrator.ManifestConflicts()
+}
+
+// StageDuration returns the time spent by the compactions of the Compactor in `stage`
+// since the Compactor was created
+func (c *Compactor) StageDuration(stage profile.Stage) time.Duration {
+	return c.orchestrator.profiler.Duration(profile.WorkCompaction, stage)
+}
+
+// SetRateLimit sets the number of bytes per second compactions may merge, summed over every
+// compaction in progress, replacing CompactorOptions.MaxBytesPerSecond. Zero removes the limit.
+func (c *Compactor) SetRateLimit(bytesPerSecond uint64) {
+	c.orchestrator.executor.limiter.setLimit(bytesPerSecond)
+}
+
+// RateLimit returns the number of bytes per second compactions may merge, or zero if
+// compactions are not throttled
+func (c *Compactor) RateLimit() uint64 {
+	return c.orchestrator.executor.limiter.limit()
+}
+
+// HealthCheck returns internal.ErrFenced if another compactor has fenced this one, or an error
+// if the compaction loop has exhausted its restarts
+func (c *Compactor) HealthCheck() error {
+	if c.orchestrator.fenced.Load() {
+		return internal.ErrFenced
+	}
+	return c.orchestrator.tasks.Err()
+}
+
+// TaskStatus returns the status of the compaction loop
+func (c *Compactor) TaskStatus() []task.Status {
+	return c.orchestrator.tasks.Status()
+}
+
 func spawnAndRunCompactionOrchestrator(
 	manifestStore *store.ManifestStore,
 	tableStore *store.TableStore,
@@ -134,7 +298,12 @@ func spawnAndRunCompactionOrchestrator(
 }
 
 type Job struct {
+	id          string
 	destination uint32
 	sstList     []sstable.Handle
 	sortedRuns  []compacted.SortedRun
+
+	// bottommost is true if the compaction includes the oldest sorted run, in which
+	// case no older version of a key exists below the output of the compaction
+	bottommost bool
 }
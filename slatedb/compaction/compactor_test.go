@@ -0,0 +1,42 @@
+package compaction
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/slatedb/slatedb-go/internal/sstable"
+	"github.com/slatedb/slatedb-go/slatedb/compacted"
+	"github.com/slatedb/slatedb-go/slatedb/state"
+)
+
+func TestCompactionShouldValidateSources(t *testing.T) {
+	inputs := buildSchedulerTestState(2, 2).DbState
+	l0ID, _ := inputs.L0[1].Id.CompactedID().Get()
+	compaction := NewCompaction([]SourceID{NewSourceIDSST(l0ID), NewSourceIDSortedRun(0)}, 0)
+	clone := func() *state.CoreStateSnapshot {
+		return &state.CoreStateSnapshot{L0: slices.Clone(inputs.L0), Compacted: slices.Clone(inputs.Compacted)}
+	}
+
+	assert.NoError(t, compaction.validateSources(inputs, clone()))
+
+	// the L0 SST was compacted by another compactor
+	current := clone()
+	current.L0 = current.L0[:1]
+	assert.ErrorIs(t, compaction.validateSources(inputs, current), errSourcesChanged)
+
+	// the sorted run was replaced by another compactor
+	current = clone()
+	current.Compacted[1] = compacted.SortedRun{
+		ID:      0,
+		SSTList: []sstable.Handle{{Id: sstable.NewIDCompacted(ulid.Make())}},
+	}
+	assert.ErrorIs(t, compaction.validateSources(inputs, current), errSourcesChanged)
+
+	// the sorted run was merged into another sorted run
+	current = clone()
+	current.Compacted = current.Compacted[:1]
+	assert.ErrorIs(t, compaction.validateSources(inputs, current), errSourcesChanged)
+}
@@ -1,37 +1,109 @@
 package compaction
 
 import (
+	"bytes"
 	"context"
+	"fmt"
+	"log/slog"
+	"slices"
 	"sync"
 	"sync/atomic"
 
+	"github.com/kapetan-io/tackle/set"
 	"github.com/oklog/ulid/v2"
 	"github.com/slatedb/slatedb-go/internal/assert"
 	"github.com/slatedb/slatedb-go/internal/iter"
+	"github.com/slatedb/slatedb-go/internal/profile"
 	"github.com/slatedb/slatedb-go/internal/sstable"
+	"github.com/slatedb/slatedb-go/internal/tracing"
 	"github.com/slatedb/slatedb-go/internal/types"
 	"github.com/slatedb/slatedb-go/slatedb/compacted"
 	"github.com/slatedb/slatedb-go/slatedb/config"
 	"github.com/slatedb/slatedb-go/slatedb/store"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type Executor struct {
 	options    *config.CompactorOptions
 	tableStore *store.TableStore
+	log        *slog.Logger
+	clock      config.Clock
 
 	resultCh chan Result
 	tasksWG  sync.WaitGroup
 	stopped  atomic.Bool
+
+	// profiler records the time spent in each stage of the compactions, or is nil
+	profiler *profile.Recorder
+
+	// tracer records the span of each compaction, see config.DBOptions.TracerProvider
+	tracer trace.Tracer
+
+	// listener is notified as each compaction begins and ends, see config.DBOptions.EventListener
+	listener config.EventListener
+
+	// keyspaces are written to compacted SSTs of their own, see config.DBOptions.Keyspaces
+	keyspaces map[string]config.KeyspaceOptions
+
+	// bytesRead and bytesWritten are the encoded sizes of the input and output SSTs
+	// of the compactions executed, see sstable.Info.EncodedSize
+	bytesRead    atomic.Uint64
+	bytesWritten atomic.Uint64
+
+	// limiter throttles the compactions, see CompactorOptions.MaxBytesPerSecond
+	limiter rateLimiter
+
+	// workers holds a token for each compaction being executed, such that no more than
+	// CompactorOptions.MaxConcurrentCompactions are executed at once
+	workers chan struct{}
+
+	// stopCtx is cancelled by stop, such that compactions delayed by the limiter return
+	stopCtx    context.Context
+	cancelStop context.CancelFunc
 }
 
 func newExecutor(
 	options *config.CompactorOptions,
 	tableStore *store.TableStore,
+	log *slog.Logger,
+	clock config.Clock,
 ) *Executor {
-	return &Executor{
-		options:    options,
-		tableStore: tableStore,
-		resultCh:   make(chan Result, 1),
+	set.Default(&log, slog.Default())
+	if clock == nil {
+		clock = config.SystemClock{}
+	}
+	opts := *options
+	set.Default(&opts.Timeout, config.DefaultCompactorOptions().Timeout)
+	set.Default(&opts.MaxConcurrentCompactions, 1)
+	stopCtx, cancelStop := context.WithCancel(context.Background())
+	e := &Executor{
+		options:    &opts,
+		tableStore: compactionTableStore(options, tableStore),
+		log:        log,
+		clock:      clock,
+		// A result is buffered for each compaction in flight, such that stop does not wait
+		// for the orchestrator to receive the results of compactions which finished
+		resultCh:   make(chan Result, opts.MaxConcurrentCompactions),
+		stopCtx:    stopCtx,
+		cancelStop: cancelStop,
+		workers:    make(chan struct{}, opts.MaxConcurrentCompactions),
+		tracer:     tracing.Tracer(nil),
+		listener:   config.NoopEventListener{},
+	}
+	e.limiter.setLimit(opts.MaxBytesPerSecond)
+	return e
+}
+
+// compactionTableStore returns the TableStore the compactions read from and write to, whose
+// block cache is determined by CompactorOptions.BlockCache
+func compactionTableStore(options *config.CompactorOptions, tableStore *store.TableStore) *store.TableStore {
+	switch options.BlockCache {
+	case config.CompactionBlockCacheBypass:
+		return tableStore.WithBlockCache(0, false)
+	case config.CompactionBlockCacheSeparate:
+		return tableStore.WithBlockCache(options.BlockCacheSize, false)
+	default:
+		return tableStore
 	}
 }
 
@@ -44,63 +116,171 @@ func (e *Executor) nextCompactionResult() (Result, bool) {
 	}
 }
 
-// create an iterator for CompactionJob.sstList and another iterator for CompactionJob.sortedRuns
-// Return the merged iterator for the above 2 iterators
-func (e *Executor) loadIterators(compaction Job) (iter.KVIterator, error) {
+// iteratorStore returns the table store read by an iterator over an input of a compaction,
+// which prefetches blocks as configured by CompactorOptions.PrefetchConcurrency
+func (e *Executor) iteratorStore() *store.TableStore {
+	ts := e.tableStore.Clone()
+	ts.SetPrefetchConcurrency(e.options.PrefetchConcurrency)
+	return ts
+}
+
+// loadIterators returns an iterator which merges the L0 SSTs and sorted runs of the compaction.
+// Both CompactionJob.sstList and CompactionJob.sortedRuns are ordered from newest to oldest.
+func (e *Executor) loadIterators(spanCtx context.Context, compaction Job) (iter.KVIterator, error) {
 	assert.True(
 		!(len(compaction.sstList) == 0 && len(compaction.sortedRuns) == 0),
 		"Compaction sources cannot be empty",
 	)
 
-	l0Iters := make([]iter.KVIterator, 0)
-	for _, sst := range compaction.sstList {
-		ctx, cancel := context.WithTimeout(context.Background(), e.options.Timeout)
-		sstIter, err := sstable.NewIterator(ctx, &sst, e.tableStore.Clone())
+	sources := make([]iter.Source, 0, len(compaction.sstList)+len(compaction.sortedRuns))
+	for i, sst := range compaction.sstList {
+		ctx, cancel := context.WithTimeout(spanCtx, e.options.Timeout)
+		sstIter, err := sstable.NewIterator(ctx, &sst, e.iteratorStore())
 		cancel()
 		if err != nil {
 			return nil, err
 		}
-		l0Iters = append(l0Iters, sstIter)
+		sources = append(sources, iter.Source{Layer: iter.LayerL0, Age: i, Iter: sstIter})
 	}
 
-	srIters := make([]iter.KVIterator, 0)
-	for _, sr := range compaction.sortedRuns {
-		ctx, cancel := context.WithTimeout(context.Background(), e.options.Timeout)
-		srIter, err := compacted.NewSortedRunIterator(ctx, sr, e.tableStore.Clone())
+	// The range tombstones of the L0 SSTs and of each sorted run are newer than every entry of
+	// the sorted runs which follow, such that SSTs of those runs which are entirely deleted by
+	// the tombstones are dropped without being read.
+	newerTombstones := make([]types.RangeTombstone, 0)
+	for _, sst := range compaction.sstList {
+		newerTombstones = append(newerTombstones, sst.Info.RangeTombstones...)
+	}
+	for i, sr := range compaction.sortedRuns {
+		if covered := coveredSSTs(newerTombstones, sr); len(covered) > 0 {
+			e.log.Debug("dropping SSTs deleted by range tombstones", "compaction_id", compaction.id,
+				"sorted_run", sr.ID, "ssts", len(covered))
+			sr.SSTList = slices.DeleteFunc(slices.Clone(sr.SSTList), func(sst sstable.Handle) bool {
+				return slices.Contains(covered, sst.Id)
+			})
+		}
+		for _, sst := range sr.SSTList {
+			newerTombstones = append(newerTombstones, sst.Info.RangeTombstones...)
+		}
+
+		ctx, cancel := context.WithTimeout(spanCtx, e.options.Timeout)
+		srIter, err := compacted.NewSortedRunIterator(ctx, sr, e.iteratorStore())
 		cancel()
 		if err != nil {
 			return nil, err
 		}
-		srIters = append(srIters, srIter)
+		sources = append(sources, iter.Source{Layer: iter.LayerSortedRun, Age: i, Iter: srIter})
 	}
 
-	var l0MergeIter, srMergeIter iter.KVIterator
-	ctx, cancel := context.WithTimeout(context.Background(), e.options.Timeout)
+	ctx, cancel := context.WithTimeout(spanCtx, e.options.Timeout)
 	defer cancel()
-	if len(compaction.sortedRuns) == 0 {
-		l0MergeIter = iter.NewMergeSort(ctx, l0Iters...)
-		return l0MergeIter, nil
-	} else if len(compaction.sstList) == 0 {
-		srMergeIter = iter.NewMergeSort(ctx, srIters...)
-		return srMergeIter, nil
-	}
+	return iter.NewLSMMerge(ctx, sources...), nil
+}
 
-	it := iter.NewMergeSort(ctx, l0MergeIter, srMergeIter)
-	return it, nil
+// newWriter returns a writer of a compacted SST of the keys of `keyspace` whose stages are
+// recorded by `p`
+func (e *Executor) newWriter(p *profile.Profile, keyspace string) *store.EncodedSSTableWriter {
+	id := sstable.NewIDCompacted(ulid.Make())
+	writer := e.tableStore.TableWriterForKeyspace(id, store.SSTLevelCompacted, keyspace)
+	writer.SetProfile(p)
+	return writer
 }
 
-func (e *Executor) executeCompaction(compaction Job) (*compacted.SortedRun, error) {
-	allIter, err := e.loadIterators(compaction)
+// maxSSTSize returns the size of the compacted SSTs of `keyspace`, see
+// config.KeyspaceCompactionOptions.MaxSSTSize
+func (e *Executor) maxSSTSize(keyspace string) uint64 {
+	if ks, ok := e.keyspaces[keyspace]; ok && ks.Compaction != nil && ks.Compaction.MaxSSTSize > 0 {
+		return ks.Compaction.MaxSSTSize
+	}
+	return e.options.MaxSSTSize
+}
+
+// executeCompaction writes the sorted run of the compaction. The compaction is in
+// profile.StageRead except when its output is encoded, compressed or uploaded.
+func (e *Executor) executeCompaction(compaction Job, p *profile.Profile) (_ *compacted.SortedRun, err error) {
+	defer p.Exit(p.Enter(profile.StageRead))
+	// The requests made by the compaction to object storage are recorded as children of its span
+	spanCtx, span := e.tracer.Start(context.Background(), "slatedb.Compaction", trace.WithAttributes(
+		tracing.KeyCompactionID.String(compaction.id), tracing.KeySortedRun.Int64(int64(compaction.destination))))
+	defer func() { tracing.End(span, err) }()
+	log := e.log.With("compaction_id", compaction.id)
+	inputIDs := make([]string, 0, len(compaction.sstList))
+	for _, sst := range compaction.sstList {
+		inputIDs = append(inputIDs, sst.Id.String())
+	}
+	srIDs := make([]uint32, 0, len(compaction.sortedRuns))
+	for _, sr := range compaction.sortedRuns {
+		srIDs = append(srIDs, sr.ID)
+		for _, sst := range sr.SSTList {
+			inputIDs = append(inputIDs, sst.Id.String())
+		}
+	}
+	log.Info("started compaction", "destination", compaction.destination,
+		"sorted_runs", srIDs, "ssts", inputIDs)
+	e.listener.OnCompactionBegin(config.CompactionBeginEvent{
+		CompactionID: compaction.id,
+		Destination:  compaction.destination,
+		SSTIDs:       inputIDs,
+		SortedRuns:   srIDs,
+	})
+	start := e.clock.Now()
+	end := config.CompactionEndEvent{CompactionID: compaction.id, Destination: compaction.destination}
+	defer func() {
+		end.Duration = e.clock.Now().Sub(start)
+		end.Err = err
+		e.listener.OnCompactionEnd(end)
+		if err != nil {
+			e.listener.OnBackgroundError(config.BackgroundErrorEvent{Task: "compaction", Err: err})
+		}
+	}()
+
+	allIter, err := e.loadIterators(spanCtx, compaction)
 	if err != nil {
 		return nil, err
 	}
 	var warn types.ErrWarn
+	now := e.clock.Now()
 
+	// Entries deleted by the range tombstones of the compaction are dropped. Unless the compaction is
+	// bottommost, the range tombstones are written to the first SST of the sorted run such that they
+	// continue to delete older versions of the keys in sorted runs which are not part of the compaction.
+	tombstones := rangeTombstones(compaction)
+	retainTombstones := !compaction.bottommost && len(tombstones) > 0
+
+	// The keys of each keyspace of config.DBOptions.Keyspaces are written to SSTs of their own,
+	// as such an SST is started once the first key of its keyspace is read
 	outputSSTs := make([]sstable.Handle, 0)
-	currentWriter := e.tableStore.TableWriter(sstable.NewIDCompacted(ulid.Make()))
+	var currentWriter *store.EncodedSSTableWriter
+	var currentKeyspace string
+	// The SST being written is not uploaded if the compaction fails
+	defer func() {
+		if currentWriter != nil {
+			currentWriter.Abort()
+		}
+	}()
 	currentSize := 0
+	startSST := func(keyspace string) {
+		currentWriter = e.newWriter(p, keyspace)
+		currentKeyspace = keyspace
+		if retainTombstones && len(outputSSTs) == 0 {
+			for _, t := range tombstones {
+				currentWriter.AddRangeTombstone(t)
+			}
+		}
+	}
+	finishSST := func() error {
+		ctx, cancel := context.WithTimeout(spanCtx, e.options.Timeout)
+		sst, err := currentWriter.Close(ctx)
+		cancel()
+		currentWriter, currentSize = nil, 0
+		if err != nil {
+			return err
+		}
+		log.Debug("wrote compacted SST", "sst_id", sst.Id.String())
+		outputSSTs = append(outputSSTs, *sst)
+		return nil
+	}
 	for {
-		ctx, cancel := context.WithTimeout(context.Background(), e.options.Timeout)
+		ctx, cancel := context.WithTimeout(spanCtx, e.options.Timeout)
 		kv, ok := allIter.NextEntry(ctx)
 		cancel()
 		if !ok {
@@ -110,46 +290,160 @@ func (e *Executor) executeCompaction(compaction Job) (*compacted.SortedRun, erro
 			break
 		}
 
-		value := kv.Value.GetValue()
-		err = currentWriter.Add(kv.Key, value)
+		if types.IsCovered(tombstones, kv) {
+			continue
+		}
+
+		if kv.IsExpired(now) {
+			// The expired value is dropped. Unless the compaction is bottommost, a tombstone
+			// is written in its place such that older versions of the key are not resurrected.
+			if compaction.bottommost {
+				continue
+			}
+			kv = types.RowEntry{Key: kv.Key, Value: types.Value{Kind: types.KindTombStone}, Seq: kv.Seq}
+		}
+
+		if e.options.Filter != nil && kv.Value.Kind == types.KindKeyValue {
+			if kv, ok = e.filterEntry(kv, compaction.bottommost); !ok {
+				continue
+			}
+		}
+
+		keyspace, _ := config.KeyspaceOf(e.keyspaces, kv.Key)
+		if currentWriter != nil && keyspace != currentKeyspace {
+			if err := finishSST(); err != nil {
+				return nil, err
+			}
+		}
+		if currentWriter == nil {
+			startSST(keyspace)
+		}
+		err = currentWriter.AddEntry(kv)
 		if err != nil {
 			return nil, err
 		}
 
-		currentSize += len(kv.Key)
-		if value.IsPresent() {
-			val, _ := value.Get()
-			currentSize += len(val)
+		size := len(kv.Key)
+		if !kv.Value.IsTombstone() {
+			size += len(kv.Value.Value)
+		}
+		currentSize += size
+		if err := e.limiter.wait(e.stopCtx, size); err != nil {
+			return nil, fmt.Errorf("while throttling compaction: %w", err)
 		}
 
-		if uint64(currentSize) > e.options.MaxSSTSize {
-			currentSize = 0
-			finishedWriter := currentWriter
-			currentWriter = e.tableStore.TableWriter(sstable.NewIDCompacted(ulid.Make()))
-			ctx, cancel := context.WithTimeout(context.Background(), e.options.Timeout)
-			sst, err := finishedWriter.Close(ctx)
-			cancel()
-			if err != nil {
+		if uint64(currentSize) > e.maxSSTSize(keyspace) {
+			if err := finishSST(); err != nil {
 				return nil, err
 			}
-			outputSSTs = append(outputSSTs, *sst)
 		}
 	}
-	if currentSize > 0 {
-		ctx, cancel := context.WithTimeout(context.Background(), e.options.Timeout)
-		sst, err := currentWriter.Close(ctx)
-		cancel()
+	if retainTombstones && len(outputSSTs) == 0 && currentWriter == nil {
+		// Every SST of a sorted run must have a first key. When the range tombstones deleted every
+		// entry of the compaction, a tombstone is written for the lowest key deleted by the range tombstones.
+		anchor := slices.MinFunc(tombstones, func(a, b types.RangeTombstone) int {
+			return bytes.Compare(a.Start, b.Start)
+		})
+		keyspace, _ := config.KeyspaceOf(e.keyspaces, anchor.Start)
+		startSST(keyspace)
+		err = currentWriter.AddEntry(types.RowEntry{Key: anchor.Start, Value: types.Value{Kind: types.KindTombStone},
+			Seq: anchor.Seq})
 		if err != nil {
 			return nil, err
 		}
-		outputSSTs = append(outputSSTs, *sst)
 	}
+	if currentWriter != nil {
+		if err := finishSST(); err != nil {
+			return nil, err
+		}
+	}
+
+	var bytesRead, bytesWritten uint64
+	outputIDs := make([]string, 0, len(outputSSTs))
+	for _, sst := range outputSSTs {
+		outputIDs = append(outputIDs, sst.Id.String())
+		bytesWritten += sst.Info.EncodedSize()
+	}
+	for _, sst := range compaction.sstList {
+		bytesRead += sst.Info.EncodedSize()
+	}
+	for _, sr := range compaction.sortedRuns {
+		for _, sst := range sr.SSTList {
+			bytesRead += sst.Info.EncodedSize()
+		}
+	}
+	e.bytesRead.Add(bytesRead)
+	e.bytesWritten.Add(bytesWritten)
+	end.OutputSSTIDs, end.BytesRead, end.BytesWritten = outputIDs, bytesRead, bytesWritten
+	span.SetAttributes(
+		tracing.KeySSTIDs.StringSlice(inputIDs),
+		tracing.KeyOutputSSTIDs.StringSlice(outputIDs),
+		tracing.KeyBytesRead.Int64(int64(bytesRead)),
+		tracing.KeyBytesWritten.Int64(int64(bytesWritten)),
+	)
+	log.Info("compacted sorted run", "destination", compaction.destination, "ssts", outputIDs)
 	return &compacted.SortedRun{
 		ID:      compaction.destination,
 		SSTList: outputSSTs,
 	}, warn.If()
 }
 
+// filterEntry applies CompactorOptions.Filter to `kv`, and returns false if the entry is dropped. Unless
+// the compaction is bottommost, a removed value is replaced by a tombstone such that older versions of
+// the key are not resurrected.
+func (e *Executor) filterEntry(kv types.RowEntry, bottommost bool) (types.RowEntry, bool) {
+	decision, value := e.options.Filter.Filter(kv.Key, kv.Value.Value)
+	switch decision {
+	case config.CompactionRemove:
+		if bottommost {
+			return kv, false
+		}
+		return types.RowEntry{Key: kv.Key, Value: types.Value{Kind: types.KindTombStone}, Seq: kv.Seq}, true
+	case config.CompactionChangeValue:
+		kv.Value = types.Value{Kind: types.KindKeyValue, Value: value}
+	}
+	return kv, true
+}
+
+// rangeTombstones returns the range tombstones of every SST of the compaction
+func rangeTombstones(compaction Job) []types.RangeTombstone {
+	result := make([]types.RangeTombstone, 0)
+	for _, sst := range compaction.sstList {
+		result = append(result, sst.Info.RangeTombstones...)
+	}
+	for _, sr := range compaction.sortedRuns {
+		for _, sst := range sr.SSTList {
+			result = append(result, sst.Info.RangeTombstones...)
+		}
+	}
+	return result
+}
+
+// coveredSSTs returns the IDs of the SSTs of the sorted run whose keys are all deleted by one of
+// the tombstones, which must be newer than every entry of the sorted run. The keys of an SST precede
+// the first key of the next SST of the run, while the last key of the last SST is only known by
+// reading it, such that the last SST is never covered. SSTs which hold range tombstones are never
+// covered, as their tombstones must continue to delete older versions of keys.
+func coveredSSTs(tombstones []types.RangeTombstone, sr compacted.SortedRun) []sstable.ID {
+	covered := make([]sstable.ID, 0)
+	if len(tombstones) == 0 {
+		return covered
+	}
+	for i := 0; i+1 < len(sr.SSTList); i++ {
+		sst, next := sr.SSTList[i], sr.SSTList[i+1]
+		if sst.Info == nil || next.Info == nil || len(sst.Info.RangeTombstones) > 0 {
+			continue
+		}
+		for _, t := range tombstones {
+			if bytes.Compare(t.Start, sst.Info.FirstKey) <= 0 && bytes.Compare(next.Info.FirstKey, t.End) <= 0 {
+				covered = append(covered, sst.Id)
+				break
+			}
+		}
+	}
+	return covered
+}
+
 func (e *Executor) startCompaction(compaction Job) {
 	if e.isStopped() {
 		return
@@ -159,17 +453,30 @@ func (e *Executor) startCompaction(compaction Job) {
 	go func() {
 		defer e.tasksWG.Done()
 
+		select {
+		case e.workers <- struct{}{}:
+		case <-e.stopCtx.Done():
+			return
+		}
 		if e.isStopped() {
+			<-e.workers
 			return
 		}
 
-		var result Result
-		sortedRun, err := e.executeCompaction(compaction)
+		result := Result{CompactionID: compaction.id}
+		var sortedRun *compacted.SortedRun
+		var err error
+		e.profiler.Do(profile.WorkCompaction, func(p *profile.Profile) {
+			sortedRun, err = e.executeCompaction(compaction, p)
+		})
+		// The worker is released before the result is sent, such that a compaction waiting
+		// for a worker does not wait for the orchestrator to commit this one
+		<-e.workers
 		if err != nil {
-			// TODO(thrawn01): log the error somewhere.
-			result = Result{Error: err}
+			// The error is logged by the Orchestrator along with the compaction ID
+			result.Error = err
 		} else if sortedRun != nil {
-			result = Result{SortedRun: sortedRun}
+			result.SortedRun = sortedRun
 		}
 		e.resultCh <- result
 	}()
@@ -177,6 +484,7 @@ func (e *Executor) startCompaction(compaction Job) {
 
 func (e *Executor) stop() {
 	e.stopped.Store(true)
+	e.cancelStop()
 	e.waitForTasksCompletion()
 }
 
@@ -0,0 +1,344 @@
+package compaction
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore"
+
+	"github.com/slatedb/slatedb-go/internal/sstable"
+	"github.com/slatedb/slatedb-go/internal/types"
+	"github.com/slatedb/slatedb-go/slatedb/compacted"
+	"github.com/slatedb/slatedb-go/slatedb/config"
+	"github.com/slatedb/slatedb-go/slatedb/store"
+)
+
+func TestExecutorDropsExpiredRows(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := config.NewManualClock(start)
+	tableStore := store.NewTableStore(objstore.NewInMemBucket(), sstable.DefaultConfig(), "/test/db")
+
+	writer := tableStore.TableWriter(sstable.NewIDCompacted(ulid.Make()))
+	for _, entry := range []types.RowEntry{
+		{Key: []byte("a"), Value: types.Value{Value: []byte("expired")}, Seq: 1, ExpireAt: start.Add(time.Minute)},
+		{Key: []byte("b"), Value: types.Value{Value: []byte("live")}, Seq: 2, ExpireAt: start.Add(time.Hour)},
+		{Key: []byte("c"), Value: types.Value{Value: []byte("forever")}, Seq: 3},
+	} {
+		require.NoError(t, writer.AddEntry(entry))
+	}
+	sst, err := writer.Close(ctx)
+	require.NoError(t, err)
+
+	executor := newExecutor(config.DefaultCompactorOptions(), tableStore, nil, clock)
+	clock.Advance(time.Minute)
+
+	compact := func(bottommost bool) []types.RowEntry {
+		t.Helper()
+		sr, err := executor.executeCompaction(Job{
+			id:         "compaction",
+			sstList:    []sstable.Handle{*sst},
+			bottommost: bottommost,
+		}, nil)
+		require.NoError(t, err)
+		require.Len(t, sr.SSTList, 1)
+		iter, err := sstable.NewIterator(ctx, &sr.SSTList[0], tableStore)
+		require.NoError(t, err)
+		var entries []types.RowEntry
+		for {
+			entry, ok := iter.NextEntry(ctx)
+			if !ok {
+				return entries
+			}
+			entries = append(entries, entry)
+		}
+	}
+
+	// the expired value is replaced by a tombstone such that older versions are not resurrected
+	entries := compact(false)
+	require.Len(t, entries, 3)
+	assert.Equal(t, []byte("a"), entries[0].Key)
+	assert.True(t, entries[0].Value.IsTombstone())
+	assert.Equal(t, []byte("live"), entries[1].Value.Value)
+	assert.Equal(t, start.Add(time.Hour), entries[1].ExpireAt.UTC())
+	assert.Equal(t, []byte("forever"), entries[2].Value.Value)
+
+	// no older versions exist below a bottommost compaction, so the expired row is dropped
+	entries = compact(true)
+	require.Len(t, entries, 2)
+	assert.Equal(t, []byte("b"), entries[0].Key)
+	assert.Equal(t, []byte("c"), entries[1].Key)
+}
+
+// prefixFilter removes the values of the keys with prefix "tmp/" and upper-cases the values of
+// the keys with prefix "upper/"
+type prefixFilter struct{}
+
+func (prefixFilter) Filter(key []byte, value []byte) (config.CompactionDecision, []byte) {
+	switch {
+	case bytes.HasPrefix(key, []byte("tmp/")):
+		return config.CompactionRemove, nil
+	case bytes.HasPrefix(key, []byte("upper/")):
+		return config.CompactionChangeValue, bytes.ToUpper(value)
+	}
+	return config.CompactionKeep, nil
+}
+
+func TestExecutorAppliesCompactionFilter(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	tableStore := store.NewTableStore(objstore.NewInMemBucket(), sstable.DefaultConfig(), "/test/db")
+	writer := tableStore.TableWriter(sstable.NewIDCompacted(ulid.Make()))
+	for _, entry := range []types.RowEntry{
+		{Key: []byte("keep"), Value: types.Value{Value: []byte("value")}, Seq: 1},
+		{Key: []byte("tmp/a"), Value: types.Value{Value: []byte("value")}, Seq: 2},
+		{Key: []byte("tmp/b"), Value: types.Value{Kind: types.KindTombStone}, Seq: 3},
+		{Key: []byte("upper/a"), Value: types.Value{Value: []byte("value")}, Seq: 4},
+	} {
+		require.NoError(t, writer.AddEntry(entry))
+	}
+	sst, err := writer.Close(ctx)
+	require.NoError(t, err)
+
+	options := config.DefaultCompactorOptions()
+	options.Filter = prefixFilter{}
+	executor := newExecutor(options, tableStore, nil, nil)
+	compact := func(bottommost bool) []types.RowEntry {
+		t.Helper()
+		sr, err := executor.executeCompaction(Job{id: "compaction", sstList: []sstable.Handle{*sst},
+			bottommost: bottommost}, nil)
+		require.NoError(t, err)
+		require.Len(t, sr.SSTList, 1)
+		iter, err := sstable.NewIterator(ctx, &sr.SSTList[0], tableStore)
+		require.NoError(t, err)
+		var entries []types.RowEntry
+		for {
+			entry, ok := iter.NextEntry(ctx)
+			if !ok {
+				return entries
+			}
+			entries = append(entries, entry)
+		}
+	}
+
+	// the removed value is replaced by a tombstone such that older versions are not resurrected
+	entries := compact(false)
+	require.Len(t, entries, 4)
+	assert.Equal(t, []byte("value"), entries[0].Value.Value)
+	assert.True(t, entries[1].Value.IsTombstone())
+	assert.Equal(t, uint64(2), entries[1].Seq)
+	assert.True(t, entries[2].Value.IsTombstone())
+	assert.Equal(t, []byte("VALUE"), entries[3].Value.Value)
+	assert.Equal(t, uint64(4), entries[3].Seq)
+
+	// no older versions exist below a bottommost compaction, so the removed value is dropped
+	entries = compact(true)
+	require.Len(t, entries, 3)
+	assert.Equal(t, []byte("keep"), entries[0].Key)
+	assert.Equal(t, []byte("tmp/b"), entries[1].Key)
+	assert.Equal(t, []byte("upper/a"), entries[2].Key)
+}
+
+func TestExecutorAppliesRangeTombstones(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	tableStore := store.NewTableStore(objstore.NewInMemBucket(), sstable.DefaultConfig(), "/test/db")
+	writeSST := func(tombstones []types.RangeTombstone, entries ...types.RowEntry) sstable.Handle {
+		t.Helper()
+		writer := tableStore.TableWriter(sstable.NewIDCompacted(ulid.Make()))
+		for _, entry := range entries {
+			require.NoError(t, writer.AddEntry(entry))
+		}
+		for _, tombstone := range tombstones {
+			writer.AddRangeTombstone(tombstone)
+		}
+		sst, err := writer.Close(ctx)
+		require.NoError(t, err)
+		return *sst
+	}
+
+	tombstone := types.RangeTombstone{Start: []byte("a"), End: []byte("c"), Seq: 4}
+	older := writeSST(nil,
+		types.RowEntry{Key: []byte("a"), Value: types.Value{Value: []byte("deleted")}, Seq: 1},
+		types.RowEntry{Key: []byte("b"), Value: types.Value{Value: []byte("after")}, Seq: 5},
+		types.RowEntry{Key: []byte("c"), Value: types.Value{Value: []byte("outside")}, Seq: 2},
+	)
+	newer := writeSST([]types.RangeTombstone{tombstone})
+
+	executor := newExecutor(config.DefaultCompactorOptions(), tableStore, nil, nil)
+	compact := func(bottommost bool, ssts ...sstable.Handle) (*sstable.Handle, []types.RowEntry) {
+		t.Helper()
+		sr, err := executor.executeCompaction(Job{id: "compaction", sstList: ssts, bottommost: bottommost}, nil)
+		require.NoError(t, err)
+		require.Len(t, sr.SSTList, 1)
+		iter, err := sstable.NewIterator(ctx, &sr.SSTList[0], tableStore)
+		require.NoError(t, err)
+		var entries []types.RowEntry
+		for {
+			entry, ok := iter.NextEntry(ctx)
+			if !ok {
+				return &sr.SSTList[0], entries
+			}
+			entries = append(entries, entry)
+		}
+	}
+
+	// the entry written before the range tombstone is dropped, and the range tombstone
+	// is retained such that it deletes older versions of the key in other sorted runs
+	sst, entries := compact(false, newer, older)
+	require.Len(t, entries, 2)
+	assert.Equal(t, []byte("b"), entries[0].Key)
+	assert.Equal(t, []byte("c"), entries[1].Key)
+	assert.Equal(t, []types.RangeTombstone{tombstone}, sst.Info.RangeTombstones)
+
+	sst, entries = compact(true, newer, older)
+	require.Len(t, entries, 2)
+	assert.Empty(t, sst.Info.RangeTombstones)
+
+	// when every entry is deleted, the output SST holds a tombstone for the start of the range
+	sst, entries = compact(false, newer, writeSST(nil,
+		types.RowEntry{Key: []byte("b"), Value: types.Value{Value: []byte("deleted")}, Seq: 1}))
+	require.Len(t, entries, 1)
+	assert.Equal(t, []byte("a"), entries[0].Key)
+	assert.True(t, entries[0].Value.IsTombstone())
+	assert.Equal(t, []types.RangeTombstone{tombstone}, sst.Info.RangeTombstones)
+}
+
+func TestExecutorDropsSSTsCoveredByRangeTombstones(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	bucket := objstore.NewInMemBucket()
+	tableStore := store.NewTableStore(bucket, sstable.DefaultConfig(), "/test/db")
+	writeSST := func(tombstones []types.RangeTombstone, keys ...string) sstable.Handle {
+		t.Helper()
+		writer := tableStore.TableWriter(sstable.NewIDCompacted(ulid.Make()))
+		for _, key := range keys {
+			require.NoError(t, writer.AddEntry(types.RowEntry{Key: []byte(key), Value: types.Value{Value: []byte(key)}, Seq: 1}))
+		}
+		for _, tombstone := range tombstones {
+			writer.AddRangeTombstone(tombstone)
+		}
+		sst, err := writer.Close(ctx)
+		require.NoError(t, err)
+		return *sst
+	}
+
+	tombstone := types.RangeTombstone{Start: []byte("a"), End: []byte("c"), Seq: 2}
+	newer := compacted.SortedRun{ID: 1, SSTList: []sstable.Handle{writeSST([]types.RangeTombstone{tombstone}, "z")}}
+	older := compacted.SortedRun{ID: 0, SSTList: []sstable.Handle{
+		writeSST(nil, "a", "aa"),
+		writeSST(nil, "b", "bb"),
+		writeSST(nil, "bc", "d"),
+	}}
+	assert.Equal(t, []sstable.ID{older.SSTList[0].Id, older.SSTList[1].Id}, coveredSSTs([]types.RangeTombstone{tombstone}, older))
+	assert.True(t, hasCoveredSSTs([]compacted.SortedRun{newer, older}))
+	assert.False(t, hasCoveredSSTs([]compacted.SortedRun{older, newer}))
+
+	// the covered SSTs are not read, so the compaction succeeds once they are deleted
+	for _, sst := range older.SSTList[:2] {
+		require.NoError(t, bucket.Delete(ctx, "/test/db/compacted/"+sst.Id.Value+".sst"))
+	}
+	executor := newExecutor(config.DefaultCompactorOptions(), tableStore, nil, nil)
+	sr, err := executor.executeCompaction(Job{id: "compaction", sortedRuns: []compacted.SortedRun{newer, older}, bottommost: true}, nil)
+	require.NoError(t, err)
+	require.Len(t, sr.SSTList, 1)
+	iter, err := sstable.NewIterator(ctx, &sr.SSTList[0], tableStore)
+	require.NoError(t, err)
+	var keys []string
+	for {
+		entry, ok := iter.NextEntry(ctx)
+		if !ok {
+			break
+		}
+		keys = append(keys, string(entry.Key))
+	}
+	assert.Equal(t, []string{"d", "z"}, keys)
+}
+
+func TestExecutorBlockCache(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	for _, tc := range []struct {
+		name       string
+		blockCache config.CompactionBlockCache
+		shared     bool
+		separate   bool
+	}{
+		{name: "Shared", blockCache: config.CompactionBlockCacheShared, shared: true},
+		{name: "Bypass", blockCache: config.CompactionBlockCacheBypass},
+		{name: "Separate", blockCache: config.CompactionBlockCacheSeparate, separate: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tableStore := store.NewTableStore(objstore.NewInMemBucket(), sstable.DefaultConfig(), "/test/db")
+			tableStore.SetBlockCache(1024*1024, false)
+			writer := tableStore.TableWriter(sstable.NewIDCompacted(ulid.Make()))
+			require.NoError(t, writer.AddEntry(types.RowEntry{Key: []byte("a"), Value: types.Value{Value: []byte("1")}, Seq: 1}))
+			sst, err := writer.Close(ctx)
+			require.NoError(t, err)
+
+			options := config.DefaultCompactorOptions()
+			options.BlockCache = tc.blockCache
+			options.BlockCacheSize = 1024 * 1024
+			executor := newExecutor(options, tableStore, nil, nil)
+			_, err = executor.executeCompaction(Job{id: "compaction", sstList: []sstable.Handle{*sst}}, nil)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.shared, tableStore.BlockCacheStats().Size > 0)
+			if tc.separate {
+				assert.Greater(t, executor.tableStore.BlockCacheStats().Size, uint64(0))
+			}
+		})
+	}
+}
+
+func TestExecutorRateLimit(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	tableStore := store.NewTableStore(objstore.NewInMemBucket(), sstable.DefaultConfig(), "/test/db")
+	writer := tableStore.TableWriter(sstable.NewIDCompacted(ulid.Make()))
+	value := make([]byte, 1000)
+	for i := 0; i < 20; i++ {
+		key := []byte{byte('a' + i)}
+		require.NoError(t, writer.AddEntry(types.RowEntry{Key: key, Value: types.Value{Value: value}, Seq: uint64(i + 1)}))
+	}
+	sst, err := writer.Close(ctx)
+	require.NoError(t, err)
+	job := Job{id: "compaction", sstList: []sstable.Handle{*sst}}
+
+	// about 20KB merged at 100KB/s takes about 200ms
+	options := config.DefaultCompactorOptions()
+	options.MaxBytesPerSecond = 100_000
+	executor := newExecutor(options, tableStore, nil, nil)
+	start := time.Now()
+	_, err = executor.executeCompaction(job, nil)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), 150*time.Millisecond)
+
+	// a throttled compaction returns once the executor is stopped
+	executor.limiter.setLimit(1)
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := executor.executeCompaction(job, nil)
+		errCh <- err
+	}()
+	time.Sleep(50 * time.Millisecond)
+	executor.stop()
+	select {
+	case err := <-errCh:
+		assert.ErrorIs(t, err, context.Canceled)
+	case <-ctx.Done():
+		t.Fatal("throttled compaction did not return after stop")
+	}
+}
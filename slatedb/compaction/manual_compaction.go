@@ -0,0 +1,111 @@
+package compaction
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/slatedb/slatedb-go/internal/assert"
+	"github.com/slatedb/slatedb-go/internal/sstable"
+	"github.com/slatedb/slatedb-go/slatedb/state"
+)
+
+// errCompactorClosed is returned to a manual compaction which did not finish before the compactor was closed
+var errCompactorClosed = errors.New("compactor closed before the manual compaction finished")
+
+// manualCompaction is a compaction of a key range requested by Compactor.CompactRange
+type manualCompaction struct {
+	// the key range to compact, where a nil start or end is unbounded
+	start, end []byte
+
+	// id is the Compaction.ID of the compaction once it has been started
+	id string
+
+	// done receives the result of the compaction, and is buffered such that the orchestrator
+	// never blocks on a caller which has given up waiting
+	done chan error
+}
+
+// maybeStartManualCompaction starts the first pending manual compaction once no other compaction is
+// in flight, and returns true if a manual compaction is pending or running. The scheduler is not
+// consulted while a manual compaction is pending, otherwise it could start compactions indefinitely
+// ahead of it.
+func (o *Orchestrator) maybeStartManualCompaction() bool {
+	for len(o.manual) > 0 {
+		m := o.manual[0]
+		if m.id != "" || len(o.State.Compactions) > 0 {
+			return true
+		}
+		compaction, ok := newManualCompaction(o.State.DbState, m.start, m.end)
+		if !ok {
+			o.finishManualCompaction(nil)
+			continue
+		}
+		if err := o.State.SubmitCompaction(compaction); err != nil {
+			o.finishManualCompaction(err)
+			continue
+		}
+		o.log.Info("starting manual compaction", "compaction_id", compaction.id,
+			"start", string(m.start), "end", string(m.end))
+		m.id = compaction.id
+		o.startCompaction(compaction)
+		return true
+	}
+	return false
+}
+
+// finishManualCompaction returns `err` to the first pending manual compaction and removes it
+func (o *Orchestrator) finishManualCompaction(err error) {
+	o.manual[0].done <- err
+	o.manual = o.manual[1:]
+}
+
+// isManualCompaction returns true if `id` is the ID of the manual compaction which is running
+func (o *Orchestrator) isManualCompaction(id string) bool {
+	return len(o.manual) > 0 && o.manual[0].id == id
+}
+
+// newManualCompaction returns the compaction of every L0 SST and sorted run of `dbState` into a
+// single sorted run, or false if no L0 SST or sorted run overlaps the range [start, end).
+//
+// Each sorted run spans the whole key space, so the L0 SSTs and sorted runs overlapping the range
+// cannot be merged without the others while keeping the newer versions of each key above the
+// older. The compaction is bottommost, such that tombstones and the versions they delete are dropped.
+func newManualCompaction(dbState *state.CoreStateSnapshot, start, end []byte) (Compaction, bool) {
+	overlaps := false
+	for _, sst := range dbState.L0 {
+		overlaps = overlaps || sstOverlaps(sst, end)
+	}
+	for _, sr := range dbState.Compacted {
+		overlaps = overlaps || (len(sr.SSTList) > 0 && sstOverlaps(sr.SSTList[0], end))
+	}
+	if !overlaps {
+		return Compaction{}, false
+	}
+
+	// L0 SSTs and sorted runs are ordered from newest to oldest, such that the newest takes
+	// precedence during the merge
+	sources := make([]SourceID, 0, len(dbState.L0)+len(dbState.Compacted))
+	for _, sst := range dbState.L0 {
+		id, ok := sst.Id.CompactedID().Get()
+		assert.True(ok, "Expected valid compacted ID")
+		sources = append(sources, NewSourceIDSST(id))
+	}
+	for _, sr := range dbState.Compacted {
+		sources = append(sources, NewSourceIDSortedRun(sr.ID))
+	}
+
+	destination := uint32(0)
+	if len(dbState.Compacted) > 0 {
+		destination = dbState.Compacted[0].ID
+		if len(dbState.L0) > 0 {
+			destination++
+		}
+	}
+	return NewCompaction(sources, destination), true
+}
+
+// sstOverlaps returns true if the keys of `sst` may be less than `end`. The SSTable only records
+// its first key, so an SSTable is assumed to extend to the end of the key space.
+func sstOverlaps(sst sstable.Handle, end []byte) bool {
+	return end == nil || bytes.Compare(sst.Info.FirstKey, end) < 0
+}
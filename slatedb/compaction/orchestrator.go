@@ -3,16 +3,20 @@ package compaction
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log/slog"
-	"sync"
-	"time"
+	"sync/atomic"
 
 	"github.com/oklog/ulid/v2"
 	"github.com/slatedb/slatedb-go/internal"
 	"github.com/slatedb/slatedb-go/internal/assert"
+	"github.com/slatedb/slatedb-go/internal/profile"
 	"github.com/slatedb/slatedb-go/internal/sstable"
+	"github.com/slatedb/slatedb-go/internal/task"
+	"github.com/slatedb/slatedb-go/internal/tracing"
 	"github.com/slatedb/slatedb-go/slatedb/compacted"
 	"github.com/slatedb/slatedb-go/slatedb/config"
+	"github.com/slatedb/slatedb-go/slatedb/state"
 	"github.com/slatedb/slatedb-go/slatedb/store"
 )
 
@@ -26,8 +30,26 @@ type Orchestrator struct {
 	// compactorMsgCh - When CompactionOrchestrator receives a CompactorShutdown message on this channel,
 	// it calls executor.stop
 	compactorMsgCh chan CompactorMainMsg
-	waitGroup      sync.WaitGroup
-	log            *slog.Logger
+
+	// manualCh receives the manual compactions requested by Compactor.CompactRange, which are
+	// queued in manual and run one at a time, see maybeStartManualCompaction
+	manualCh chan *manualCompaction
+	manual   []*manualCompaction
+
+	tasks *task.Manager
+	loop  *task.Handle
+	log   *slog.Logger
+	clock config.Clock
+
+	// completed is the number of compactions committed to the manifest
+	completed atomic.Uint64
+
+	// fenced is set once another compactor has incremented the compactor epoch of the manifest,
+	// after which the loop stops, see stopIfFenced
+	fenced atomic.Bool
+
+	// profiler records the time spent in each stage of the compactions
+	profiler *profile.Recorder
 }
 
 func NewOrchestrator(
@@ -44,7 +66,7 @@ func NewOrchestrator(
 	}
 	storedManifest, _ := sm.Get()
 
-	manifest, err := store.NewCompactorFenceableManifest(&storedManifest)
+	manifest, err := store.NewCompactorFenceableManifest(storedManifest)
 	if err != nil {
 		return nil, err
 	}
@@ -54,8 +76,17 @@ func NewOrchestrator(
 		return nil, err
 	}
 
-	scheduler := loadCompactionScheduler()
-	executor := newExecutor(opts.CompactorOptions, tableStore)
+	scheduler, err := loadCompactionScheduler(opts)
+	if err != nil {
+		return nil, err
+	}
+	executor := newExecutor(opts.CompactorOptions, tableStore, opts.Log, opts.Clock)
+	executor.profiler = profile.NewRecorder(opts.ProfileLabels)
+	executor.tracer = tracing.Tracer(opts.TracerProvider)
+	executor.keyspaces = opts.Keyspaces
+	if opts.EventListener != nil {
+		executor.listener = opts.EventListener
+	}
 
 	o := Orchestrator{
 		options:        opts.CompactorOptions,
@@ -64,53 +95,66 @@ func NewOrchestrator(
 		scheduler:      scheduler,
 		executor:       executor,
 		compactorMsgCh: make(chan CompactorMainMsg, 1),
+		manualCh:       make(chan *manualCompaction),
+		tasks:          task.NewManager(task.Options{OnEvent: opts.TaskEventHandler(), Log: opts.Log}),
 		log:            opts.Log,
+		clock:          opts.Clock,
+		profiler:       executor.profiler,
+	}
+	if o.clock == nil {
+		o.clock = config.SystemClock{}
 	}
 	return &o, nil
 }
 
 func (o *Orchestrator) spawnLoop(opts config.DBOptions) {
-	o.waitGroup.Add(1)
-	go func() {
-		defer o.waitGroup.Done()
-
-		ticker := time.NewTicker(opts.CompactorOptions.PollInterval)
+	o.loop = o.tasks.Go("compactor", func(ctx context.Context) error {
+		ticker := o.clock.NewTicker(opts.CompactorOptions.PollInterval)
 		defer ticker.Stop()
 
 		for {
-			resultPresent := o.processCompactionResult(opts.Log)
-			if !resultPresent && o.executor.isStopped() {
-				break
-			}
-
+			// The loop blocks until there is a compaction result or a tick, rather than polling for
+			// results, such that an idle compactor does not occupy a CPU
 			select {
-			case <-ticker.C:
-				err := o.loadManifest()
-				assert.True(err == nil, "Failed to load manifest")
+			case result := <-o.executor.resultCh:
+				o.handleCompactionResult(result, opts.Log)
+			case <-ticker.C():
+				if err := o.loadManifest(); err != nil && !o.stopIfFenced(err) {
+					return fmt.Errorf("while loading manifest: %w", err)
+				}
+			case m := <-o.manualCh:
+				// The manifest is loaded such that the compaction includes the latest L0 SSTs
+				o.manual = append(o.manual, m)
+				if err := o.loadManifest(); err != nil && !o.stopIfFenced(err) {
+					return fmt.Errorf("while loading manifest: %w", err)
+				}
 			case <-o.compactorMsgCh:
 				// we receive Shutdown msg on compactorMsgCh. Stop the executor.
-				// Don't return and let the loop continue until there are no more compaction results to process
 				o.executor.stop()
 				ticker.Stop()
-			default:
+			case <-ctx.Done():
+				o.executor.stop()
+			}
+
+			if o.executor.isStopped() {
+				// Process the results of the compactions which finished before the executor stopped
+				for o.processCompactionResult(opts.Log) {
+				}
+				for len(o.manual) > 0 {
+					o.finishManualCompaction(errCompactorClosed)
+				}
+				return nil
 			}
 		}
-	}()
+	})
 }
 
 func (o *Orchestrator) shutdown(ctx context.Context) error {
 	o.compactorMsgCh <- CompactorShutdown
-	done := make(chan struct{})
-	go func() {
-		o.waitGroup.Wait()
-		close(done)
-	}()
-	select {
-	case <-done:
-	case <-ctx.Done():
-		return ctx.Err()
+	if err := o.loop.Wait(ctx); err != nil {
+		return err
 	}
-	return nil
+	return o.tasks.Stop(ctx)
 }
 
 func (o *Orchestrator) loadManifest() error {
@@ -140,8 +184,15 @@ func (o *Orchestrator) refreshDBState() error {
 }
 
 func (o *Orchestrator) maybeScheduleCompactions() error {
-	compactions := o.scheduler.maybeScheduleCompaction(o.State)
+	if o.maybeStartManualCompaction() {
+		return nil
+	}
+	compactions := o.scheduler.ShouldCompact(o.State)
 	for _, compaction := range compactions {
+		if len(o.State.Compactions) >= o.executor.options.MaxConcurrentCompactions {
+			// The remaining compactions are scheduled again once a compaction finishes
+			break
+		}
 		err := o.SubmitCompaction(compaction)
 		if err != nil {
 			return err
@@ -182,40 +233,122 @@ func (o *Orchestrator) startCompaction(compaction Compaction) {
 	}
 
 	sortedRuns := make([]compacted.SortedRun, 0)
+	bottommost := false
 	for _, sID := range compaction.sources {
 		srID, ok := sID.SortedRunID().Get()
 		if ok {
 			sortedRuns = append(sortedRuns, srsByID[srID])
+			// dbState.Compacted is ordered from newest to oldest
+			if srID == dbState.Compacted[len(dbState.Compacted)-1].ID {
+				bottommost = true
+			}
 		}
 	}
 
 	o.executor.startCompaction(Job{
+		id:          compaction.id,
 		destination: compaction.destination,
 		sstList:     ssts,
 		sortedRuns:  sortedRuns,
+		bottommost:  bottommost,
 	})
 }
 
 func (o *Orchestrator) processCompactionResult(log *slog.Logger) bool {
 	result, resultPresent := o.executor.nextCompactionResult()
 	if resultPresent {
-		if result.Error != nil {
-			log.Error("Error executing compaction", "error", result.Error)
-		} else if result.SortedRun != nil {
-			err := o.FinishCompaction(result.SortedRun)
-			assert.True(err == nil, "Failed to finish compaction")
-		}
+		o.handleCompactionResult(result, log)
 	}
 	return resultPresent
 }
 
+func (o *Orchestrator) handleCompactionResult(result Result, log *slog.Logger) {
+	if result.Error != nil {
+		log.Error("Error executing compaction",
+			"compaction_id", result.CompactionID, "error", result.Error)
+		if o.isManualCompaction(result.CompactionID) {
+			// The failed compaction is removed such that the compactions of the scheduler, or a
+			// later manual compaction, may compact its sources
+			for destination, compaction := range o.State.Compactions {
+				if compaction.id == result.CompactionID {
+					delete(o.State.Compactions, destination)
+				}
+			}
+			o.finishManualCompaction(result.Error)
+			err := o.maybeScheduleCompactions()
+			assert.True(err == nil, "Failed to schedule compactions")
+		}
+	} else if result.SortedRun != nil {
+		err := o.FinishCompaction(result.SortedRun)
+		assert.True(err == nil || o.stopIfFenced(err), "Failed to finish compaction")
+	}
+}
+
+// stopIfFenced stops the executor and returns true if `err` reports that another compactor has
+// fenced this one, in which case the loop exits once the compactions in flight have stopped. Their
+// output is not committed, and is deleted by garbage collection.
+func (o *Orchestrator) stopIfFenced(err error) bool {
+	if !errors.Is(err, internal.ErrFenced) {
+		return false
+	}
+	o.log.Warn("compactor fenced by another compactor; stopping", "error", err)
+	o.fenced.Store(true)
+	o.executor.stop()
+	return true
+}
+
 func (o *Orchestrator) FinishCompaction(outputSR *compacted.SortedRun) error {
+	log := o.log
+	compaction, submitted := o.State.Compactions[outputSR.ID]
+	if submitted {
+		log = log.With("compaction_id", compaction.id)
+	}
+	inputs := o.State.DbState
 	o.State.FinishCompaction(outputSR)
 	o.logCompactionState()
-	err := o.writeManifest()
+
+	// The sources are validated against the latest manifest before each write, such that a
+	// compaction whose sources were compacted by another compactor is not committed.
+	var err error
+	o.profiler.Do(profile.WorkCompaction, func(p *profile.Profile) {
+		defer p.Exit(p.Enter(profile.StageManifest))
+		err = o.manifest.UpdateDBStateWithRetry(log, func() (*state.CoreStateSnapshot, error) {
+			if err := o.loadManifest(); err != nil {
+				return nil, err
+			}
+			if submitted {
+				current, err := o.manifest.DbState()
+				if err != nil {
+					return nil, err
+				}
+				if err := compaction.validateSources(inputs, current); err != nil {
+					return nil, err
+				}
+			}
+			return o.State.DbState.Clone(), nil
+		})
+	})
+	if errors.Is(err, errSourcesChanged) {
+		// Discard the output of the compaction and continue from the state of the latest manifest
+		log.Warn("aborted compaction", "error", err)
+		current, err := o.manifest.DbState()
+		if err != nil {
+			return err
+		}
+		o.State.DbState = current.Clone()
+		if submitted && o.isManualCompaction(compaction.id) {
+			// The manual compaction is retried with the sources of the latest manifest
+			o.manual[0].id = ""
+		}
+		return o.maybeScheduleCompactions()
+	}
 	if err != nil {
 		return err
 	}
+	o.completed.Add(1)
+	if submitted && o.isManualCompaction(compaction.id) {
+		o.finishManualCompaction(nil)
+	}
 
 	err = o.maybeScheduleCompactions()
 	if err != nil {
@@ -224,27 +357,16 @@ func (o *Orchestrator) FinishCompaction(outputSR *compacted.SortedRun) error {
 	return nil
 }
 
-func (o *Orchestrator) writeManifest() error {
-	for {
-		err := o.loadManifest()
-		if err != nil {
-			return err
-		}
-
-		core := o.State.DbState.Clone()
-		err = o.manifest.UpdateDBState(core)
-		if errors.Is(err, internal.ErrAlreadyExists) {
-			o.log.Warn("conflicting manifest version. retry write", "error", err)
-			continue
-		}
-		return err
-	}
+// ManifestConflicts returns the number of times the compactor's manifest writes
+// conflicted with a manifest written by another process.
+func (o *Orchestrator) ManifestConflicts() uint64 {
+	return o.manifest.Conflicts()
 }
 
 func (o *Orchestrator) SubmitCompaction(compaction Compaction) error {
 	err := o.State.SubmitCompaction(compaction)
 	if err != nil {
-		o.log.Warn("invalid compaction", "error", err)
+		o.log.Warn("invalid compaction", "compaction_id", compaction.id, "error", err)
 		return nil
 	}
 	o.startCompaction(compaction)
@@ -254,7 +376,7 @@ func (o *Orchestrator) SubmitCompaction(compaction Compaction) error {
 func (o *Orchestrator) logCompactionState() {
 	// LogState(o.log, o.state.dbState)
 	for _, compaction := range o.State.Compactions {
-		o.log.Info("in-flight compaction", "compaction", compaction)
+		o.log.Info("in-flight compaction", "compaction_id", compaction.id, "compaction", compaction)
 	}
 }
 
@@ -274,6 +396,14 @@ func loadState(manifest *store.FenceableManifest) (*CompactorState, error) {
 	return NewCompactorState(dbState.Clone(), nil), nil
 }
 
-func loadCompactionScheduler() Scheduler {
-	return SizeTieredCompactionScheduler{}
+func loadCompactionScheduler(opts config.DBOptions) (Scheduler, error) {
+	if opts.CompactorOptions.Scheduler == nil {
+		return NewSizeTieredCompactionScheduler(opts.CompactorOptions).WithKeyspaces(opts.Keyspaces), nil
+	}
+	scheduler, ok := opts.CompactorOptions.Scheduler.(Scheduler)
+	if !ok {
+		return nil, internal.ErrInvalidArgument("CompactorOptions.Scheduler %T does not implement compaction.Scheduler",
+			opts.CompactorOptions.Scheduler)
+	}
+	return scheduler, nil
 }
@@ -0,0 +1,63 @@
+package compaction
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// rateLimitSlack is how far compactions may run ahead of the rate limit before they are
+// delayed, such that compactions sleep rarely and for long rather than after every entry
+const rateLimitSlack = 10 * time.Millisecond
+
+// rateLimiter limits the rate at which compactions merge bytes. The limit is shared by every
+// compaction of the Executor, such that it bounds their combined rate. It measures wall time
+// rather than config.Clock, as it delays the compactions by sleeping.
+type rateLimiter struct {
+	// bytesPerSecond is the limit, or zero if compactions are not throttled
+	bytesPerSecond atomic.Uint64
+
+	mu sync.Mutex
+	// next is the time at which the bytes merged so far are within the limit
+	next time.Time
+}
+
+func (l *rateLimiter) setLimit(bytesPerSecond uint64) {
+	l.bytesPerSecond.Store(bytesPerSecond)
+}
+
+func (l *rateLimiter) limit() uint64 {
+	return l.bytesPerSecond.Load()
+}
+
+// wait records that `n` bytes were merged, and blocks until the bytes merged are within the
+// limit or until ctx is done
+func (l *rateLimiter) wait(ctx context.Context, n int) error {
+	rate := l.bytesPerSecond.Load()
+	if rate == 0 || n <= 0 {
+		return nil
+	}
+	now := time.Now()
+	l.mu.Lock()
+	// Time during which nothing was merged is not credited, such that compactions which
+	// start after an idle period do not burst above the limit
+	if l.next.Before(now) {
+		l.next = now
+	}
+	l.next = l.next.Add(time.Duration(float64(n) / float64(rate) * float64(time.Second)))
+	delay := l.next.Sub(now)
+	l.mu.Unlock()
+	if delay <= rateLimitSlack {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -1,29 +1,139 @@
 package compaction
 
 import (
+	"github.com/kapetan-io/tackle/set"
 	"github.com/slatedb/slatedb-go/internal/assert"
+	"github.com/slatedb/slatedb-go/internal/sstable"
+	"github.com/slatedb/slatedb-go/internal/types"
+	"github.com/slatedb/slatedb-go/slatedb/compacted"
+	"github.com/slatedb/slatedb-go/slatedb/config"
 )
 
-type SizeTieredCompactionScheduler struct{}
+// SizeTieredCompactionScheduler is the default Scheduler. It compacts L0 into a new sorted run once
+// config.CompactorOptions.MinL0CompactionSSTs L0 SSTs have accumulated, or once a keyspace has
+// config.KeyspaceCompactionOptions.MinL0CompactionSSTs L0 SSTs, and merges every sorted run once
+// there are more than config.CompactorOptions.MaxSortedRuns.
+type SizeTieredCompactionScheduler struct {
+	// The number of L0 SSTs which triggers a compaction of L0 into a new sorted run
+	minL0SSTs int
+	// The maximum number of L0 SSTs merged by a single compaction. Zero means no limit.
+	maxL0SSTs int
+	// The maximum number of sorted runs before they are merged. Zero means no limit.
+	maxSortedRuns int
+	// The keyspaces whose number of L0 SSTs may trigger a compaction of L0
+	keyspaces map[string]config.KeyspaceOptions
+}
+
+// NewSizeTieredCompactionScheduler returns the SizeTieredCompactionScheduler configured by `opts`,
+// such that a Scheduler may delegate to it
+func NewSizeTieredCompactionScheduler(opts *config.CompactorOptions) SizeTieredCompactionScheduler {
+	s := SizeTieredCompactionScheduler{
+		minL0SSTs:     opts.MinL0CompactionSSTs,
+		maxL0SSTs:     opts.MaxL0CompactionSSTs,
+		maxSortedRuns: opts.MaxSortedRuns,
+	}
+	set.Default(&s.minL0SSTs, 4)
+	return s
+}
 
-func (s SizeTieredCompactionScheduler) maybeScheduleCompaction(state *CompactorState) []Compaction {
+// WithKeyspaces returns the scheduler which also compacts L0 once a keyspace of `keyspaces` has
+// config.KeyspaceCompactionOptions.MinL0CompactionSSTs L0 SSTs, see config.DBOptions.Keyspaces
+func (s SizeTieredCompactionScheduler) WithKeyspaces(
+	keyspaces map[string]config.KeyspaceOptions) SizeTieredCompactionScheduler {
+	s.keyspaces = keyspaces
+	return s
+}
+
+func (s SizeTieredCompactionScheduler) ShouldCompact(state *CompactorState) []Compaction {
 	dbState := state.DbState
-	// for now, just compact l0 down to a new sorted run each time
 	compactions := make([]Compaction, 0)
-	if len(dbState.L0) >= 4 {
+
+	nextSortedRunID := uint32(0)
+	if len(dbState.Compacted) > 0 {
+		nextSortedRunID = dbState.Compacted[0].ID + 1
+	}
+
+	if len(dbState.L0) >= s.minL0SSTs || s.keyspaceReachedMinL0(dbState.L0) {
+		// L0 is ordered from newest to oldest, the oldest SSTs are compacted first
+		l0 := dbState.L0
+		if s.maxL0SSTs > 0 && len(l0) > s.maxL0SSTs {
+			l0 = l0[len(l0)-s.maxL0SSTs:]
+		}
+
 		sources := make([]SourceID, 0)
-		for _, sst := range dbState.L0 {
+		for _, sst := range l0 {
 			id, ok := sst.Id.CompactedID().Get()
 			assert.True(ok, "Expected valid compacted ID")
 			sources = append(sources, NewSourceIDSST(id))
 		}
+		compactions = append(compactions, NewCompaction(sources, nextSortedRunID))
+	}
 
-		nextSortedRunID := uint32(0)
-		if len(dbState.Compacted) > 0 {
-			nextSortedRunID = dbState.Compacted[0].ID + 1
+	// Merging sorted runs while another merge is in flight could result in the same
+	// sorted run being used as a source of more than one compaction. A compaction of L0
+	// writes a sorted run newer than every sorted run merged, such that both may run in
+	// parallel. Sorted runs are merged early when range tombstones have deleted SSTs of
+	// older runs, as the merge drops those SSTs without reading them.
+	tooManyRuns := s.maxSortedRuns > 0 && len(dbState.Compacted) > s.maxSortedRuns
+	if (tooManyRuns || hasCoveredSSTs(dbState.Compacted)) && !mergeInFlight(state) {
+		// Sorted runs are ordered from newest to oldest, such that the newest
+		// run takes precedence during the merge. The merged run replaces the newest.
+		sources := make([]SourceID, 0)
+		for _, sr := range dbState.Compacted {
+			sources = append(sources, NewSourceIDSortedRun(sr.ID))
 		}
-
-		compactions = append(compactions, NewCompaction(sources, nextSortedRunID))
+		compactions = append(compactions, NewCompaction(sources, dbState.Compacted[0].ID))
 	}
 	return compactions
 }
+
+// keyspaceReachedMinL0 returns true if a keyspace has as many L0 SSTs as triggers a compaction of
+// L0. The keyspace of an SST is the keyspace of its first key, as the keys of each keyspace are
+// written to SSTs of their own.
+func (s SizeTieredCompactionScheduler) keyspaceReachedMinL0(l0 []sstable.Handle) bool {
+	counts := make(map[string]int)
+	for _, sst := range l0 {
+		if sst.Info == nil {
+			continue
+		}
+		name, ok := config.KeyspaceOf(s.keyspaces, sst.Info.FirstKey)
+		if !ok {
+			continue
+		}
+		counts[name]++
+		c := s.keyspaces[name].Compaction
+		if c != nil && c.MinL0CompactionSSTs > 0 && counts[name] >= c.MinL0CompactionSSTs {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeInFlight returns true if a compaction in flight has a sorted run as a source
+func mergeInFlight(state *CompactorState) bool {
+	for _, compaction := range state.Compactions {
+		for _, src := range compaction.sources {
+			if src.SortedRunID().IsPresent() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hasCoveredSSTs returns true if the range tombstones of a sorted run delete every key of an
+// SST of an older sorted run, see coveredSSTs
+func hasCoveredSSTs(sortedRuns []compacted.SortedRun) bool {
+	newerTombstones := make([]types.RangeTombstone, 0)
+	for _, sr := range sortedRuns {
+		if len(coveredSSTs(newerTombstones, sr)) > 0 {
+			return true
+		}
+		for _, sst := range sr.SSTList {
+			if sst.Info != nil {
+				newerTombstones = append(newerTombstones, sst.Info.RangeTombstones...)
+			}
+		}
+	}
+	return false
+}
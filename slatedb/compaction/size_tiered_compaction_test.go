@@ -0,0 +1,160 @@
+package compaction
+
+import (
+	"testing"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/slatedb/slatedb-go/internal/sstable"
+	"github.com/slatedb/slatedb-go/internal/types"
+	"github.com/slatedb/slatedb-go/slatedb/compacted"
+	"github.com/slatedb/slatedb-go/slatedb/config"
+	"github.com/slatedb/slatedb-go/slatedb/state"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchedulerShouldWaitForMinL0SSTs(t *testing.T) {
+	scheduler := NewSizeTieredCompactionScheduler(&config.CompactorOptions{MinL0CompactionSSTs: 3})
+
+	compactorState := buildSchedulerTestState(2, 0)
+	assert.Empty(t, scheduler.ShouldCompact(compactorState))
+
+	compactorState = buildSchedulerTestState(3, 0)
+	compactions := scheduler.ShouldCompact(compactorState)
+	require.Len(t, compactions, 1)
+	assert.Len(t, compactions[0].sources, 3)
+	assert.Equal(t, uint32(0), compactions[0].destination)
+}
+
+func TestSchedulerShouldCompactOldestL0SSTsUpToMax(t *testing.T) {
+	scheduler := NewSizeTieredCompactionScheduler(&config.CompactorOptions{
+		MinL0CompactionSSTs: 2,
+		MaxL0CompactionSSTs: 2,
+	})
+
+	compactorState := buildSchedulerTestState(5, 1)
+	compactions := scheduler.ShouldCompact(compactorState)
+	require.Len(t, compactions, 1)
+	require.Len(t, compactions[0].sources, 2)
+
+	l0 := compactorState.DbState.L0
+	for i, src := range compactions[0].sources {
+		expected, _ := l0[len(l0)-2+i].Id.CompactedID().Get()
+		assert.Equal(t, expected, src.SstID().MustGet())
+	}
+	assert.Equal(t, uint32(1), compactions[0].destination)
+}
+
+func TestSchedulerShouldCompactL0OnceKeyspaceReachesMinL0SSTs(t *testing.T) {
+	scheduler := NewSizeTieredCompactionScheduler(&config.CompactorOptions{MinL0CompactionSSTs: 10}).
+		WithKeyspaces(map[string]config.KeyspaceOptions{
+			"hot":  {Compaction: &config.KeyspaceCompactionOptions{MinL0CompactionSSTs: 2}},
+			"cold": {},
+		})
+
+	compactorState := buildSchedulerTestState(4, 0)
+	for i, key := range []string{"hot\x00a", "cold\x00a", "cold\x00b", "other"} {
+		compactorState.DbState.L0[i].Info = &sstable.Info{FirstKey: []byte(key)}
+	}
+	assert.Empty(t, scheduler.ShouldCompact(compactorState))
+
+	// the oldest L0 SSTs are compacted, along with those of the other keyspaces
+	compactorState.DbState.L0[3].Info.FirstKey = []byte("hot\x00b")
+	compactions := scheduler.ShouldCompact(compactorState)
+	require.Len(t, compactions, 1)
+	assert.Len(t, compactions[0].sources, 4)
+}
+
+func TestSchedulerShouldMergeSortedRunsAboveMax(t *testing.T) {
+	scheduler := NewSizeTieredCompactionScheduler(&config.CompactorOptions{MaxSortedRuns: 2})
+
+	compactorState := buildSchedulerTestState(0, 2)
+	assert.Empty(t, scheduler.ShouldCompact(compactorState))
+
+	compactorState = buildSchedulerTestState(0, 3)
+	compactions := scheduler.ShouldCompact(compactorState)
+	require.Len(t, compactions, 1)
+	require.Len(t, compactions[0].sources, 3)
+	for i, src := range compactions[0].sources {
+		assert.Equal(t, compactorState.DbState.Compacted[i].ID, src.SortedRunID().MustGet())
+	}
+	assert.Equal(t, uint32(2), compactions[0].destination)
+
+	// No sorted runs are merged while another compaction is in flight
+	require.NoError(t, compactorState.SubmitCompaction(compactions[0]))
+	assert.Empty(t, scheduler.ShouldCompact(compactorState))
+}
+
+func TestSchedulerShouldMergeSortedRunsWhileCompactingL0(t *testing.T) {
+	scheduler := NewSizeTieredCompactionScheduler(&config.CompactorOptions{MinL0CompactionSSTs: 2, MaxSortedRuns: 2})
+	compactorState := buildSchedulerTestState(2, 3)
+
+	compactions := scheduler.ShouldCompact(compactorState)
+	require.Len(t, compactions, 2)
+	require.NoError(t, compactorState.SubmitCompaction(compactions[0]))
+	assert.Equal(t, uint32(3), compactions[0].destination)
+
+	// The sorted runs are merged while L0 is compacted into a newer sorted run
+	compactions = scheduler.ShouldCompact(compactorState)
+	require.Len(t, compactions, 2)
+	require.NoError(t, compactorState.SubmitCompaction(compactions[1]))
+	assert.Equal(t, uint32(2), compactions[1].destination)
+	assert.Len(t, compactorState.Compactions, 2)
+}
+
+func TestCompactorStateShouldRejectSortedRunsWhichAreNotAdjacent(t *testing.T) {
+	compactorState := buildSchedulerTestState(0, 3)
+	gap := NewCompaction([]SourceID{NewSourceIDSortedRun(2), NewSourceIDSortedRun(0)}, 2)
+	assert.Error(t, compactorState.SubmitCompaction(gap))
+
+	adjacent := NewCompaction([]SourceID{NewSourceIDSortedRun(1), NewSourceIDSortedRun(0)}, 1)
+	require.NoError(t, compactorState.SubmitCompaction(adjacent))
+	// the remaining sorted run is merged in parallel
+	require.NoError(t, compactorState.SubmitCompaction(NewCompaction([]SourceID{NewSourceIDSortedRun(2)}, 2)))
+}
+
+func TestSchedulerShouldMergeSortedRunsWithCoveredSSTs(t *testing.T) {
+	scheduler := NewSizeTieredCompactionScheduler(config.DefaultCompactorOptions())
+	compactorState := buildSchedulerTestState(0, 2)
+	handle := func(firstKey string, tombstones ...types.RangeTombstone) sstable.Handle {
+		return sstable.Handle{
+			Id:   sstable.NewIDCompacted(ulid.Make()),
+			Info: &sstable.Info{FirstKey: []byte(firstKey), RangeTombstones: tombstones},
+		}
+	}
+	compactorState.DbState.Compacted[0].SSTList = []sstable.Handle{
+		handle("x", types.RangeTombstone{Start: []byte("a"), End: []byte("o"), Seq: 10}),
+	}
+	compactorState.DbState.Compacted[1].SSTList = []sstable.Handle{handle("b"), handle("n")}
+	compactions := scheduler.ShouldCompact(compactorState)
+	require.Len(t, compactions, 1)
+	assert.Len(t, compactions[0].sources, 2)
+
+	// the last SST of a sorted run is never covered, as its last key is unknown
+	compactorState.DbState.Compacted[1].SSTList = []sstable.Handle{handle("b")}
+	assert.Empty(t, scheduler.ShouldCompact(compactorState))
+}
+
+func TestSchedulerShouldNotMergeSortedRunsByDefault(t *testing.T) {
+	scheduler := NewSizeTieredCompactionScheduler(config.DefaultCompactorOptions())
+	assert.Empty(t, scheduler.ShouldCompact(buildSchedulerTestState(0, 100)))
+}
+
+// buildSchedulerTestState returns a CompactorState with the number of L0 SSTs
+// and sorted runs requested, ordered from newest to oldest.
+func buildSchedulerTestState(numL0 int, numSortedRuns int) *CompactorState {
+	dbState := &state.CoreStateSnapshot{
+		L0:        make([]sstable.Handle, 0),
+		Compacted: make([]compacted.SortedRun, 0),
+	}
+	for i := 0; i < numL0; i++ {
+		dbState.L0 = append(dbState.L0, sstable.Handle{Id: sstable.NewIDCompacted(ulid.Make())})
+	}
+	for i := numSortedRuns - 1; i >= 0; i-- {
+		dbState.Compacted = append(dbState.Compacted, compacted.SortedRun{
+			ID:      uint32(i),
+			SSTList: []sstable.Handle{{Id: sstable.NewIDCompacted(ulid.Make())}},
+		})
+	}
+	return NewCompactorState(dbState, nil)
+}
@@ -3,6 +3,7 @@ package compaction
 import (
 	"log/slog"
 	"math"
+	"slices"
 	"strconv"
 
 	"github.com/kapetan-io/tackle/set"
@@ -42,6 +43,10 @@ func (c *CompactorState) SubmitCompaction(compaction Compaction) error {
 		return internal.Err("ongoing compaction exists for this destination")
 	}
 
+	if err := c.validateSources(compaction); err != nil {
+		return err
+	}
+
 	for _, sr := range c.DbState.Compacted {
 		if sr.ID == compaction.destination {
 			if !c.oneOfTheSourceSRMatchesDestination(compaction) {
@@ -52,11 +57,69 @@ func (c *CompactorState) SubmitCompaction(compaction Compaction) error {
 		}
 	}
 
-	c.log.Info("accepted submitted compaction:", "compaction", compaction)
+	c.log.Info("accepted submitted compaction:", "compaction_id", compaction.id, "compaction", compaction)
 	c.Compactions[compaction.destination] = compaction
 	return nil
 }
 
+// validateSources returns an error if the compaction has no sources, or a source which is not an
+// L0 SST or sorted run of the state or which is a source of a compaction in progress. As compactions
+// may run in parallel, the sorted runs of a compaction must be adjacent, otherwise its output would
+// take precedence over a newer sorted run left between them, and a compaction of L0 is refused while
+// another is in progress, as L0 compactions must be committed from oldest to newest.
+func (c *CompactorState) validateSources(compaction Compaction) error {
+	if len(compaction.sources) == 0 {
+		return internal.Err("compaction has no sources")
+	}
+	inFlight := make(map[SourceID]bool)
+	l0InFlight := false
+	for _, other := range c.Compactions {
+		for _, src := range other.sources {
+			inFlight[src] = true
+			l0InFlight = l0InFlight || src.SstID().IsPresent()
+		}
+	}
+	l0s := make(map[ulid.ULID]bool)
+	for _, sst := range c.DbState.L0 {
+		if id, ok := sst.Id.CompactedID().Get(); ok {
+			l0s[id] = true
+		}
+	}
+	// srs maps the ID of each sorted run to its position, from newest to oldest
+	srs := make(map[uint32]int)
+	for i, sr := range c.DbState.Compacted {
+		srs[sr.ID] = i
+	}
+	positions := make([]int, 0)
+	for _, src := range compaction.sources {
+		if inFlight[src] {
+			return internal.Err("compaction source %s is a source of a compaction in progress", src.value)
+		}
+		if id, ok := src.SstID().Get(); ok {
+			if !l0s[id] {
+				return internal.Err("compaction source L0 SST '%s' not found", id)
+			}
+			if l0InFlight {
+				return internal.Err("a compaction of L0 is in progress")
+			}
+		}
+		if id, ok := src.SortedRunID().Get(); ok {
+			i, found := srs[id]
+			if !found {
+				return internal.Err("compaction source sorted run '%d' not found", id)
+			}
+			positions = append(positions, i)
+		}
+	}
+	slices.Sort(positions)
+	for i := 1; i < len(positions); i++ {
+		if positions[i] != positions[i-1]+1 {
+			return internal.Err("compaction source sorted runs are not adjacent")
+		}
+	}
+	return nil
+}
+
 func (c *CompactorState) oneOfTheSourceSRMatchesDestination(compaction Compaction) bool {
 	for _, src := range compaction.sources {
 		if src.typ == SortedRunID {
@@ -89,6 +152,7 @@ func (c *CompactorState) RefreshDBState(writerState *state.CoreStateSnapshot) {
 	merged.L0 = mergedL0s
 	merged.LastCompactedWalSSTID.Store(writerState.LastCompactedWalSSTID.Load())
 	merged.NextWalSstID.Store(writerState.NextWalSstID.Load())
+	merged.LastSeq.Store(writerState.LastSeq.Load())
 	c.DbState = merged
 }
 
@@ -99,7 +163,7 @@ func (c *CompactorState) FinishCompaction(outputSR *compacted.SortedRun) {
 	if !ok {
 		return
 	}
-	c.log.Info("finished compaction", "compaction", compaction)
+	c.log.Info("finished compaction", "compaction_id", compaction.id, "compaction", compaction)
 
 	compactionL0s := make(map[ulid.ULID]bool)
 	compactionSRs := make(map[uint32]bool)
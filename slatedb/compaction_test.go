@@ -1,9 +1,13 @@
 package slatedb
 
 import (
+	"bytes"
 	"context"
 	"log/slog"
 	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -77,6 +81,15 @@ func TestCompactorCompactsL0(t *testing.T) {
 	next, ok := iter.NextEntry(context.Background())
 	assert.False(t, ok)
 	assert.Equal(t, types.RowEntry{}, next)
+
+	// the flush and compaction loops are supervised
+	assert.NoError(t, db.HealthCheck())
+	names := make([]string, 0)
+	for _, s := range db.TaskStatus() {
+		assert.True(t, s.Running)
+		names = append(names, s.Name)
+	}
+	assert.Equal(t, []string{"wal_flush", "memtable_flush", "metrics_sample", "compactor"}, names)
 }
 
 func TestShouldWriteManifestSafely(t *testing.T) {
@@ -136,6 +149,178 @@ func TestShouldWriteManifestSafely(t *testing.T) {
 	assert.Equal(t, l0IDsToCompact[0].SstID(), dbState.L0LastCompacted)
 }
 
+// syncBuffer is a bytes.Buffer which may be written by background tasks
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestFlushAndCompactionLogCorrelationIDs(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	var logs syncBuffer
+	log := slog.New(slog.NewJSONHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	options := dbOptions(nil)
+	options.Log = log
+	_, manifestStore, tableStore, db := buildTestDB(options)
+	require.NoError(t, db.Put(ctx, repeatedChar('a', 32), repeatedChar('b', 96)))
+	require.NoError(t, db.Close(ctx))
+
+	// every line about the L0 flush carries the flush ID
+	assert.Contains(t, logs.String(), `"msg":"flushed memtable to L0","flush_id":`)
+
+	compactorOpts := compactorOptions()
+	compactorOpts.Log = log
+	orchestrator, err := compaction.NewOrchestrator(compactorOpts, manifestStore, tableStore)
+	require.NoError(t, err)
+
+	sources := make([]compaction.SourceID, 0)
+	l0IDs := make([]string, 0)
+	for _, sst := range orchestrator.State.DbState.L0 {
+		id, ok := sst.Id.CompactedID().Get()
+		require.True(t, ok)
+		sources = append(sources, compaction.NewSourceIDSST(id))
+		l0IDs = append(l0IDs, id.String())
+	}
+	require.NotEmpty(t, l0IDs)
+
+	c := compaction.NewCompaction(sources, 0)
+	require.NotEmpty(t, c.ID())
+	require.NoError(t, orchestrator.SubmitCompaction(c))
+	orchestrator.WaitForTasksCompletion()
+	result, ok := orchestrator.NextCompactionResult()
+	require.True(t, ok)
+	assert.Equal(t, c.ID(), result.CompactionID)
+	require.NoError(t, orchestrator.FinishCompaction(result.SortedRun))
+
+	// the compaction ID links the input SSTs to the output SSTs
+	var started, finished string
+	for _, line := range strings.Split(logs.String(), "\n") {
+		if !strings.Contains(line, `"compaction_id":"`+c.ID()+`"`) {
+			continue
+		}
+		if strings.Contains(line, `"msg":"started compaction"`) {
+			started = line
+		}
+		if strings.Contains(line, `"msg":"compacted sorted run"`) {
+			finished = line
+		}
+	}
+	for _, id := range l0IDs {
+		assert.Contains(t, started, id)
+	}
+	for _, sst := range result.SortedRun.SSTList {
+		assert.Contains(t, finished, sst.Id.String())
+	}
+}
+
+// recordingListener records the events of a database, see config.DBOptions.EventListener
+type recordingListener struct {
+	config.NoopEventListener
+	mu               sync.Mutex
+	walFlushes       []config.WALFlushedEvent
+	memtableFlushes  []config.MemtableFlushedEvent
+	compactionBegins []config.CompactionBeginEvent
+	compactionEnds   []config.CompactionEndEvent
+	manifests        []config.ManifestUpdatedEvent
+	taskEvents       []config.TaskEventType
+}
+
+func (l *recordingListener) OnWALFlushed(e config.WALFlushedEvent) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.walFlushes = append(l.walFlushes, e)
+}
+
+func (l *recordingListener) OnMemtableFlushed(e config.MemtableFlushedEvent) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.memtableFlushes = append(l.memtableFlushes, e)
+}
+
+func (l *recordingListener) OnCompactionBegin(e config.CompactionBeginEvent) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.compactionBegins = append(l.compactionBegins, e)
+}
+
+func (l *recordingListener) OnCompactionEnd(e config.CompactionEndEvent) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.compactionEnds = append(l.compactionEnds, e)
+}
+
+func (l *recordingListener) OnManifestUpdated(e config.ManifestUpdatedEvent) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.manifests = append(l.manifests, e)
+}
+
+func (l *recordingListener) OnTaskEvent(e config.TaskEvent) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.taskEvents = append(l.taskEvents, e.Type)
+}
+
+func TestEventListener(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*20)
+	defer cancel()
+
+	listener := &recordingListener{}
+	options := dbOptions(compactorOptions().CompactorOptions)
+	options.EventListener = listener
+	_, _, _, db := buildTestDB(options)
+	defer func() { _ = db.Close(ctx) }()
+	for i := 0; i < 4; i++ {
+		require.NoError(t, db.Put(ctx, repeatedChar(rune('a'+i), 16), repeatedChar(rune('b'+i), 48)))
+		require.NoError(t, db.Put(ctx, repeatedChar(rune('j'+i), 16), repeatedChar(rune('k'+i), 48)))
+	}
+
+	require.Eventually(t, func() bool {
+		listener.mu.Lock()
+		defer listener.mu.Unlock()
+		return len(listener.compactionEnds) > 0
+	}, 10*time.Second, 50*time.Millisecond)
+
+	listener.mu.Lock()
+	defer listener.mu.Unlock()
+	require.NotEmpty(t, listener.walFlushes)
+	assert.NotZero(t, listener.walFlushes[0].Bytes)
+	require.NotEmpty(t, listener.memtableFlushes)
+	assert.NotZero(t, listener.memtableFlushes[0].ManifestID)
+
+	// the compaction which ended is the one which began, and compacted the flushed L0 SSTs
+	begin, end := listener.compactionBegins[0], listener.compactionEnds[0]
+	assert.Equal(t, begin.CompactionID, end.CompactionID)
+	assert.NoError(t, end.Err)
+	assert.NotEmpty(t, end.OutputSSTIDs)
+	assert.NotZero(t, end.BytesRead)
+	assert.NotZero(t, end.BytesWritten)
+	assert.Contains(t, begin.SSTIDs, listener.memtableFlushes[0].SSTID)
+
+	// each memtable flush wrote the manifest which records its L0 SST
+	manifests := make(map[uint64]config.ManifestUpdatedEvent)
+	for _, m := range listener.manifests {
+		manifests[m.ManifestID] = m
+	}
+	for _, flush := range listener.memtableFlushes {
+		assert.Contains(t, manifests, flush.ManifestID)
+	}
+}
+
 func buildTestDB(options config.DBOptions) (objstore.Bucket, *store.ManifestStore, *store.TableStore, *DB) {
 	bucket := objstore.NewInMemBucket()
 	db, err := OpenWithOptions(context.Background(), testPath, bucket, options)
@@ -179,6 +364,158 @@ func TestShouldRegisterCompactionAsSubmitted(t *testing.T) {
 	assert.Equal(t, compaction.Submitted, compactorState.Compactions[0].Status)
 }
 
+func TestShouldRejectCompactionWithInvalidSources(t *testing.T) {
+	_, _, compactorState := buildTestState(t)
+	l0 := compactorState.DbState.L0
+
+	assert.Error(t, compactorState.SubmitCompaction(compaction.NewCompaction(nil, 0)))
+	unknown := compaction.NewCompaction([]compaction.SourceID{compaction.NewSourceIDSST(ulid.Make())}, 0)
+	assert.Error(t, compactorState.SubmitCompaction(unknown))
+	unknown = compaction.NewCompaction([]compaction.SourceID{compaction.NewSourceIDSortedRun(7)}, 0)
+	assert.Error(t, compactorState.SubmitCompaction(unknown))
+
+	// an L0 SST cannot be the source of two compactions in progress
+	assert.NoError(t, compactorState.SubmitCompaction(buildL0Compaction(l0[:1], 0)))
+	assert.Error(t, compactorState.SubmitCompaction(buildL0Compaction(l0, 1)))
+	// compactions of L0 are committed in order, such that they run one at a time
+	assert.Error(t, compactorState.SubmitCompaction(buildL0Compaction(l0[1:], 1)))
+	assert.Equal(t, 1, len(compactorState.Compactions))
+}
+
+// l0Scheduler is a compaction.Scheduler which compacts every L0 SST into a new sorted run
+type l0Scheduler struct {
+	calls atomic.Int64
+}
+
+func (s *l0Scheduler) ShouldCompact(state *compaction.CompactorState) []compaction.Compaction {
+	s.calls.Add(1)
+	dbState := state.DbState
+	if len(dbState.L0) == 0 || len(state.Compactions) > 0 {
+		return nil
+	}
+	destination := uint32(0)
+	if len(dbState.Compacted) > 0 {
+		destination = dbState.Compacted[0].ID + 1
+	}
+	sources := make([]compaction.SourceID, 0, len(dbState.L0))
+	for _, sst := range dbState.L0 {
+		sources = append(sources, compaction.NewSourceIDSST(sst.Id.CompactedID().MustGet()))
+	}
+	return []compaction.Compaction{compaction.NewCompaction(sources, destination)}
+}
+
+func TestCompactionScheduler(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	scheduler := &l0Scheduler{}
+	options := dbOptions(&config.CompactorOptions{
+		PollInterval: 100 * time.Millisecond,
+		MaxSSTSize:   1024 * 1024 * 1024,
+		Scheduler:    scheduler,
+	})
+	db, err := OpenWithOptions(ctx, testPath, objstore.NewInMemBucket(), options)
+	require.NoError(t, err)
+	defer func() { _ = db.Close(ctx) }()
+
+	// a single L0 SST is compacted, which the default scheduler leaves in L0
+	require.NoError(t, db.Put(ctx, []byte("key"), []byte("value")))
+	require.NoError(t, db.FlushMemtableToL0())
+	require.Eventually(t, func() bool {
+		return len(db.state.CoreStateSnapshot().Compacted) == 1
+	}, 10*time.Second, 50*time.Millisecond)
+	assert.Positive(t, scheduler.calls.Load())
+
+	value, err := db.Get(ctx, []byte("key"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("value"), value)
+
+	// the scheduler must implement compaction.Scheduler
+	options.CompactorOptions.Scheduler = struct{}{}
+	_, err = OpenWithOptions(ctx, "/test/other", objstore.NewInMemBucket(), options)
+	assert.Error(t, err)
+}
+
+func TestCompactionOfKeyspaces(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	// L0 is compacted once the keyspace "blobs" has two L0 SSTs, the memtable is flushed to three
+	options := dbOptions(&config.CompactorOptions{
+		PollInterval:        100 * time.Millisecond,
+		MaxSSTSize:          1024 * 1024 * 1024,
+		MinL0CompactionSSTs: 100,
+	})
+	options.L0SSTSizeBytes = 64 * 1024
+	options.Keyspaces = keyspaceSSTOptions()
+	db, err := OpenWithOptions(ctx, testPath, objstore.NewInMemBucket(), options)
+	require.NoError(t, err)
+	defer func() { _ = db.Close(ctx) }()
+
+	writeKeyspaceSSTs(t, ctx, db)
+	require.NoError(t, db.FlushMemtableToL0())
+	require.Eventually(t, func() bool {
+		return len(db.state.CoreStateSnapshot().Compacted) == 1
+	}, 10*time.Second, 50*time.Millisecond)
+
+	// the sorted run holds SSTs of each keyspace, of the keyspace's size and codec
+	snapshot := db.state.CoreStateSnapshot()
+	assert.Empty(t, snapshot.L0)
+	compacted := sstsByKeyspace(db, snapshot.Compacted[0].SSTList)
+	require.Len(t, compacted, 3)
+	assert.GreaterOrEqual(t, len(compacted["blobs"]), 3)
+	for _, sst := range compacted["blobs"] {
+		assert.Equal(t, compress.CodecSnappy, sst.Info.CompressionCodec)
+		assert.Less(t, sst.Info.EncodedSize(), uint64(2*1024))
+	}
+	require.Len(t, compacted["index"], 1)
+	assert.Equal(t, compress.CodecZstd, compacted["index"][0].Info.CompressionCodec)
+	require.Len(t, compacted[""], 1)
+	assert.Equal(t, compress.CodecNone, compacted[""][0].Info.CompressionCodec)
+
+	blobs, err := db.Keyspace("blobs")
+	require.NoError(t, err)
+	value, err := blobs.Get(ctx, []byte("blob7"))
+	require.NoError(t, err)
+	assert.Equal(t, repeatedChar('h', 400), value)
+}
+
+func TestCompactRange(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	options := dbOptions(&config.CompactorOptions{
+		PollInterval: 100 * time.Millisecond,
+		MaxSSTSize:   1024 * 1024 * 1024,
+	})
+	db, err := OpenWithOptions(ctx, testPath, objstore.NewInMemBucket(), options)
+	require.NoError(t, err)
+	defer func() { _ = db.Close(ctx) }()
+
+	require.NoError(t, db.Put(ctx, []byte("key1"), []byte("value1")))
+	require.NoError(t, db.Put(ctx, []byte("key2"), []byte("value2")))
+	require.NoError(t, db.FlushMemtableToL0())
+	// the delete is in the memtable, which CompactRange flushes to L0
+	require.NoError(t, db.Delete(ctx, []byte("key1")))
+
+	assert.Error(t, db.CompactRange(ctx, []byte("key2"), []byte("key1")))
+	require.NoError(t, db.CompactRange(ctx, []byte("key1"), []byte("key2")))
+	require.Eventually(t, func() bool {
+		snapshot := db.state.CoreStateSnapshot()
+		return len(snapshot.L0) == 0 && len(snapshot.Compacted) == 1
+	}, 10*time.Second, 50*time.Millisecond)
+
+	_, err = db.Get(ctx, []byte("key1"))
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+	value, err := db.Get(ctx, []byte("key2"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("value2"), value)
+
+	// a range with no L0 SSTs or sorted runs is a no-op
+	require.NoError(t, db.CompactRange(ctx, nil, []byte("a")))
+	assert.Equal(t, uint64(1), db.compactor.CompactionsCompleted())
+}
+
 func TestShouldUpdateDBStateWhenCompactionFinished(t *testing.T) {
 	_, _, compactorState := buildTestState(t)
 	beforeCompaction := compactorState.DbState.Clone()
@@ -335,7 +672,7 @@ func TestShouldRefreshDBStateCorrectlyWhenAllL0Compacted(t *testing.T) {
 	assert.Equal(t, expectedID, actualID)
 }
 
-func waitForManifestWithL0Len(storedManifest store.StoredManifest, size int) *state.CoreStateSnapshot {
+func waitForManifestWithL0Len(storedManifest *store.StoredManifest, size int) *state.CoreStateSnapshot {
 	startTime := time.Now()
 	for time.Since(startTime) < time.Second*10 {
 		dbState, err := storedManifest.Refresh()
@@ -358,7 +695,7 @@ func buildL0Compaction(sstList []sstable.Handle, destination uint32) compaction.
 	return compaction.NewCompaction(sources, destination)
 }
 
-func buildTestState(t *testing.T) (objstore.Bucket, store.StoredManifest, *compaction.CompactorState) {
+func buildTestState(t *testing.T) (objstore.Bucket, *store.StoredManifest, *compaction.CompactorState) {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
 	defer cancel()
 	t.Helper()
@@ -0,0 +1,83 @@
+package slatedb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/thanos-io/objstore"
+
+	"github.com/slatedb/slatedb-go/slatedb/compaction"
+	"github.com/slatedb/slatedb-go/slatedb/config"
+)
+
+// Compactor compacts a database in a process other than its writer, such that compactions do not
+// compete with the writer for CPU and bandwidth. The writer is opened with
+// config.DBOptions.CompactorOptions set to nil, so that it does not compact. See OpenCompactor
+type Compactor struct {
+	compactor *compaction.Compactor
+
+	// metrics are registered with config.DBOptions.MetricsRegisterer, if it is set
+	metrics    *promMetrics
+	registerer prometheus.Registerer
+}
+
+// OpenCompactor starts compacting the existing database at `path` as configured by
+// options.CompactorOptions, or by config.DefaultCompactorOptions if it is nil. Opening a Compactor
+// increments the compactor epoch of the manifest, which fences the compactor of any other process;
+// a fenced compactor stops and reports an error from Compactor.HealthCheck. The options
+// which determine how SSTs are written, such as the compression codecs, should match the writer's.
+func OpenCompactor(ctx context.Context, path string, bucket objstore.Bucket, options config.DBOptions) (*Compactor, error) {
+	if options.CompactorOptions == nil {
+		options.CompactorOptions = config.DefaultCompactorOptions()
+	}
+	metrics := newPromMetrics()
+	bucket = instrumentBucket(bucket, options, metrics)
+	tableStore, manifestStore, err := newStores(path, bucket, &options)
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := metrics.register(options.MetricsRegisterer); err != nil {
+		return nil, fmt.Errorf("while registering metrics: %w", err)
+	}
+	compactor, err := compaction.NewCompactor(manifestStore, tableStore, options)
+	if err != nil {
+		metrics.unregister(options.MetricsRegisterer)
+		return nil, fmt.Errorf("while creating compactor: %w", err)
+	}
+	metrics.compactor.Store(compactor)
+	return &Compactor{compactor: compactor, metrics: metrics, registerer: options.MetricsRegisterer}, nil
+}
+
+// CompactRange compacts the keys in the range [start, end) into the bottommost sorted run,
+// see DB.CompactRange
+func (c *Compactor) CompactRange(ctx context.Context, start, end []byte) error {
+	return c.compactor.CompactRange(ctx, start, end)
+}
+
+// CompactionsCompleted returns the number of compactions committed to the manifest since
+// the Compactor was opened
+func (c *Compactor) CompactionsCompleted() uint64 {
+	return c.compactor.CompactionsCompleted()
+}
+
+// ManifestConflicts returns the number of manifest writes of the Compactor which conflicted
+// with a manifest written by the writer since the Compactor was opened
+func (c *Compactor) ManifestConflicts() uint64 {
+	return c.compactor.ManifestConflicts()
+}
+
+// HealthCheck returns an error if another compactor has been opened for the database, which fences
+// this one, or if the compaction loop has failed
+func (c *Compactor) HealthCheck() error {
+	return c.compactor.HealthCheck()
+}
+
+// Close stops the compactions, waiting for those in progress to finish
+func (c *Compactor) Close(ctx context.Context) error {
+	defer c.metrics.unregister(c.registerer)
+	return c.compactor.Close(ctx)
+}
@@ -0,0 +1,56 @@
+package slatedb
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore"
+
+	"github.com/slatedb/slatedb-go/slatedb/config"
+)
+
+func TestOpenCompactor(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	bucket := objstore.NewInMemBucket()
+	db, err := OpenWithOptions(ctx, testPath, bucket, testDBOptions(0, 1024))
+	require.NoError(t, err)
+	defer func() { _ = db.Close(ctx) }()
+	require.Nil(t, db.compactor)
+
+	compactorOptions := testDBOptionsCompactor(0, 1024, &config.CompactorOptions{
+		PollInterval:        100 * time.Millisecond,
+		MaxSSTSize:          1024 * 1024 * 1024,
+		MinL0CompactionSSTs: 2,
+	})
+	compactor, err := OpenCompactor(ctx, testPath, bucket, compactorOptions)
+	require.NoError(t, err)
+	defer func() { _ = compactor.Close(ctx) }()
+
+	// The writer sees the sorted run written by the compactor of the other process
+	for i := 0; i < 2; i++ {
+		require.NoError(t, db.Put(ctx, []byte(fmt.Sprintf("key%d", i)), []byte("value")))
+		require.NoError(t, db.FlushMemtableToL0())
+	}
+	require.Eventually(t, func() bool {
+		return compactor.CompactionsCompleted() == 1 && len(db.state.CoreStateSnapshot().Compacted) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	value, err := db.Get(ctx, []byte("key0"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("value"), value)
+	require.NoError(t, compactor.HealthCheck())
+
+	// Opening another compactor fences the first, which stops
+	other, err := OpenCompactor(ctx, testPath, bucket, compactorOptions)
+	require.NoError(t, err)
+	defer func() { _ = other.Close(ctx) }()
+	require.Eventually(t, func() bool {
+		return compactor.HealthCheck() != nil
+	}, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, other.HealthCheck())
+}
@@ -0,0 +1,127 @@
+package config
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock is the source of time used by the database for flush and poll tickers, timestamps
+// and expiry. Tests and simulations can provide a ManualClock to control the passage of time.
+type Clock interface {
+	// Now returns the current time
+	Now() time.Time
+
+	// NewTicker returns a Ticker which delivers the time on its channel every `d`
+	NewTicker(d time.Duration) Ticker
+}
+
+// Ticker delivers ticks of a Clock at intervals. Like time.Ticker, ticks are
+// dropped if the receiver does not keep up.
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// ------------------------------------------------
+// SystemClock
+// ------------------------------------------------
+
+// SystemClock is a Clock which uses the system time
+type SystemClock struct{}
+
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (SystemClock) NewTicker(d time.Duration) Ticker {
+	return systemTicker{ticker: time.NewTicker(d)}
+}
+
+type systemTicker struct {
+	ticker *time.Ticker
+}
+
+func (t systemTicker) C() <-chan time.Time {
+	return t.ticker.C
+}
+
+func (t systemTicker) Stop() {
+	t.ticker.Stop()
+}
+
+// ------------------------------------------------
+// ManualClock
+// ------------------------------------------------
+
+// ManualClock is a Clock whose time only changes when Advance is called, such
+// that tests can deterministically fire tickers and expire values.
+type ManualClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	tickers map[*manualTicker]struct{}
+}
+
+func NewManualClock(now time.Time) *ManualClock {
+	return &ManualClock{
+		now:     now,
+		tickers: make(map[*manualTicker]struct{}),
+	}
+}
+
+func (c *ManualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *ManualClock) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("non-positive interval for ManualClock.NewTicker")
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &manualTicker{
+		clock:  c,
+		period: d,
+		next:   c.now.Add(d),
+		ch:     make(chan time.Time, 1),
+	}
+	c.tickers[t] = struct{}{}
+	return t
+}
+
+// Advance moves the time of the clock forward by `d` and fires every ticker whose
+// interval has elapsed. A ticker fires at most once per call to Advance.
+func (c *ManualClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	for t := range c.tickers {
+		if c.now.Before(t.next) {
+			continue
+		}
+		select {
+		case t.ch <- c.now:
+		default:
+		}
+		elapsed := c.now.Sub(t.next)/t.period + 1
+		t.next = t.next.Add(elapsed * t.period)
+	}
+}
+
+type manualTicker struct {
+	clock  *ManualClock
+	period time.Duration
+	next   time.Time
+	ch     chan time.Time
+}
+
+func (t *manualTicker) C() <-chan time.Time {
+	return t.ch
+}
+
+func (t *manualTicker) Stop() {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	delete(t.clock.tickers, t)
+}
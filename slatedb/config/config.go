@@ -1,10 +1,17 @@
 package config
 
 import (
+	"bytes"
 	"log/slog"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/samber/mo"
 	"github.com/slatedb/slatedb-go/internal/compress"
+	"github.com/slatedb/slatedb-go/internal/sstable/bloom"
+	"github.com/slatedb/slatedb-go/slatedb/audit"
+	"github.com/thanos-io/objstore"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // DBOptions Configuration opts for the database. These opts are set on client startup.
@@ -29,9 +36,48 @@ type DBOptions struct {
 	// bytes to object storage.
 	FlushInterval time.Duration
 
+	// MaxUnflushedWALBytes, if not zero, is the number of bytes of the mutable WAL at which it is
+	// frozen and flushed to object storage without waiting for the next FlushInterval, such that
+	// a burst of writes does not grow the writes which may be lost on a crash, or the memory
+	// held by the WAL, beyond this size. The WAL is flushed once by the write which reaches the
+	// size, so it may exceed the size while that flush is in progress.
+	MaxUnflushedWALBytes uint64
+
+	// WALEnabled, if set to false, disables the WAL in object storage. Writes are added directly
+	// to the memtable and are only durable once the memtable is flushed to L0, such that a crash
+	// loses every write since the last memtable flush, in exchange for writing each key to object
+	// storage once rather than twice. A write which awaits durability waits for the memtable to
+	// be flushed to L0 at the next FlushInterval, and FlushWAL, Close and CreateCheckpoint flush
+	// the memtable to L0. WAL SSTs written while the WAL was enabled are still replayed by Open.
+	// If None, the WAL is enabled.
+	WALEnabled mo.Option[bool]
+
+	// LocalWALDir, if not empty, is a directory on local disk to which each write to the WAL is
+	// appended and synced, such that a write which awaits durability returns once it is synced to
+	// local disk rather than once the WAL SST which holds it is written to object storage. WAL SSTs
+	// are still written to object storage every FlushInterval, after which the local WAL segment of
+	// each SST is removed. When the database is opened, the writes of the local WAL which are not in
+	// object storage are replayed and flushed to L0.
+	//
+	// A write is only as durable as the local disk until its WAL SST is written to object storage,
+	// and it is only replayed by the writer which next opens the database, with the same LocalWALDir,
+	// which must not be shared by databases. The writes of a writer which was fenced by a writer
+	// with another LocalWALDir are discarded, including those acknowledged before the writer
+	// detected that it was fenced. It is ignored by a read-only DB, and cannot be used if
+	// WALEnabled is false.
+	LocalWALDir string
+
+	// WALEntryChecksums, if true, stores a checksum of each entry of the WAL SSTs, covering its key,
+	// value and metadata. When the WAL is replayed by Open, replay stops at the first entry whose
+	// checksum does not match, such as a torn or corrupt write, and Open fails with ErrCorruptWAL
+	// unless RecoveryMode permits the WAL to be truncated at that entry. Adds 4 bytes to each entry
+	// of the WAL. WAL SSTs written before it was set are replayed as before.
+	WALEntryChecksums bool
+
 	// How frequently to poll for new manifest files. Refreshing the manifest file
 	// allows writers to detect fencing operations and allows readers to detect newly
-	// compacted data.
+	// compacted data. If zero, the manifest is never polled and is only refreshed
+	// when the writer updates it.
 	ManifestPollInterval time.Duration
 
 	// Write SSTables with a bloom filter if the number of keys in the SSTable
@@ -39,6 +85,64 @@ type DBOptions struct {
 	// faster without a bloom filter.
 	MinFilterKeys uint32
 
+	// FilterBitsPerKey is the number of bits per key of the bloom filters of new SSTs. More bits
+	// per key lower the false positive rate of the filter, such that fewer reads fetch a block
+	// which does not hold the key, at the cost of larger filters. 10 bits per key has a false
+	// positive rate of about 1%. The bits per key of each SST are recorded in the SST, see
+	// bloom.FalsePositiveRate. Defaults to 10.
+	FilterBitsPerKey uint32
+
+	// FilterFalsePositiveRate, if not zero, is the target false positive rate of the bloom filters
+	// of new SSTs, which must be between 0 and 1 exclusive, and overrides FilterBitsPerKey with
+	// the fewest bits per key which achieve it. See bloom.BitsPerKeyForFalsePositiveRate
+	FilterFalsePositiveRate float64
+
+	// FilterPartitionBlocks partitions the bloom filter of each SST written by compaction into a
+	// filter of the keys of each FilterPartitionBlocks data blocks, along with an index of the
+	// partitions. A point lookup then reads the partition which may hold the key, rather than the
+	// filter of every key in the SST, which is large for the SSTs of a sorted run. SSTs with no more
+	// than FilterPartitionBlocks blocks have a single filter. If zero, filters are not partitioned.
+	FilterPartitionBlocks uint32
+
+	// MaxConcurrentFilterFetches caps the number of bloom filters fetched from object
+	// storage at once to serve reads. When the filter cache is cold, such as after a
+	// restart, every read may need to fetch a filter for each SST it considers. Once the
+	// cap is reached, reads probe the SST index and blocks without the filter instead of
+	// fetching it, which prevents a thundering herd of small requests. Filters are only
+	// fetched for SSTs whose key range may include the key. If zero, filter fetches are
+	// not limited.
+	MaxConcurrentFilterFetches int
+
+	// HedgedReads hedges and limits the reads of SST blocks and indexes from object storage made by
+	// Get, which tames the tail latency caused by slow requests to object storage. See HedgedReadOptions
+	HedgedReads HedgedReadOptions
+
+	// ReadAheadBytes is the number of bytes of adjacent SST blocks which scans and compactions read
+	// from object storage with a single request, rather than a request per block. This reduces the
+	// number of requests made by long scans and compactions, at the cost of reading blocks which a
+	// scan which stops early does not need. Get reads a single block. If zero, blocks are read with
+	// a request per block.
+	ReadAheadBytes uint64
+
+	// BlockCacheSize is the number of bytes of SST data blocks cached in memory, such that
+	// repeated reads of a block are not fetched from object storage and decoded again. If
+	// zero, blocks are not cached.
+	BlockCacheSize uint64
+
+	// BlockCacheCompressed caches blocks as they are stored in object storage rather than
+	// decoded. This fits more blocks in BlockCacheSize when a CompressionCodec is used, at
+	// the cost of decompressing a block each time it is read from the cache.
+	BlockCacheCompressed bool
+
+	// DiskCacheDir is a local directory in which the byte ranges read from L0 and compacted
+	// SSTs are cached, such that repeated reads, including reads after a restart, do not
+	// require a round trip to object storage. If empty, ranges are not cached on disk.
+	DiskCacheDir string
+
+	// DiskCacheSize is the number of bytes which may be cached in DiskCacheDir before the
+	// least recently used ranges are evicted.
+	DiskCacheSize uint64
+
 	// The minimum size a memtable needs to be before it is frozen and flushed to
 	// L0 object storage. Writes will still be flushed to the object storage WAL
 	// (based on FlushInterval) regardless of this value. Memtable sizes are checked
@@ -67,12 +171,246 @@ type DBOptions struct {
 	//   secondary readers to see new data.
 	L0SSTSizeBytes uint64
 
+	// SSTWriteBufferSize is the number of bytes of an SST written by compaction or an IngestWriter
+	// which are buffered in memory. Once the buffer is full, the SST is streamed to object storage,
+	// which object storage providers such as S3 and GCS upload in parts, such that the memory used
+	// by a compaction does not grow with CompactorOptions.MaxSSTSize. An SST no larger than the
+	// buffer is uploaded with a single request. SSTs are not streamed to objstore.InMemBucket, which
+	// blocks other operations while it reads an upload. If zero, defaults to 8 MiB.
+	SSTWriteBufferSize uint64
+
+	// DirectL0BatchBytes, if not zero, is the number of bytes of keys and values at which a
+	// WriteBatch is written directly to L0 SSTs rather than through the WAL and memtable, such
+	// that a very large batch is not written to object storage twice. The SSTs are added to L0
+	// with a single manifest update once the WAL and memtable have been flushed, and DB.Write
+	// returns once the batch is durable regardless of WriteOptions.AwaitDurable. Writes are
+	// blocked while the SSTs are added.
+	DirectL0BatchBytes uint64
+
+	// InlineValueBytes, if not zero, stores the newest version of each key whose value is at
+	// most InlineValueBytes long, and each tombstone, in the index of L0 and compacted SSTs as
+	// well as in their blocks, such that a Get of the key reads only the index of the SST rather
+	// than the index and a block. This halves the reads of workloads with tiny values, such as
+	// feature flags and counters, at the cost of larger indexes, which are held in the cache.
+	// SSTs written before it was set are read as before.
+	InlineValueBytes uint32
+
+	// The number of most recent manifest versions retained in object storage. Each
+	// time the writer updates the manifest, and each time garbage is collected, older
+	// versions beyond this count are deleted. Retaining a few versions gives slow readers
+	// a grace period during which the manifest they loaded is still stored. The manifests
+	// referenced by checkpoints are always retained. If both ManifestRetention and
+	// ManifestRetentionTime are zero, all manifest versions are retained.
+	ManifestRetention int
+
+	// ManifestRetentionTime is the minimum time a manifest version is retained in object
+	// storage after it was written, in addition to the ManifestRetention most recent versions,
+	// such that the grace period of slow readers does not depend on how frequently the manifest
+	// is updated. If ManifestRetention is zero, only the latest manifest version is retained
+	// beyond this time.
+	ManifestRetentionTime time.Duration
+
+	// ManifestCompression, if not compress.CodecNone, compresses each manifest written by the
+	// writer and the compactor, which reduces the bytes transferred by each manifest poll of
+	// databases with very large manifests. Compressed manifests cannot be read by versions of
+	// slatedb which do not support them, which fail to open the database as requiring an
+	// unsupported feature. Manifests are read whether or not they are compressed.
+	ManifestCompression compress.Codec
+
+	// AuditSink receives an audit.Record for every mutation committed to the WAL, or to L0 while
+	// the WAL is disabled, by a batch written directly to L0 or by DB.ReplaceRange. If nil,
+	// no audit records are produced. Because the WAL only holds the latest value of a key,
+	// a key which is written more than once before the WAL is flushed is audited once.
+	AuditSink audit.Sink
+
+	// AuditWriterID identifies this writer in the audit records passed to AuditSink
+	AuditWriterID string
+
+	// If true, the database is opened in read-only mode. No flush or compaction
+	// tasks are started, the writer epoch is never incremented (such that the current
+	// writer is not fenced) and all writes fail with ErrReadOnly. The database
+	// reflects the manifest and WAL at the time it was opened, unless MaxStaleness is set.
+	ReadOnly bool
+
+	// MaxStaleness bounds how out of date the view of a database opened with ReadOnly
+	// may be. If the manifest and WAL were last loaded longer than MaxStaleness ago when
+	// a read is made, they are loaded again before the read is served. Lower values see
+	// new writes sooner at the cost of more object storage requests. If zero, the view is
+	// never refreshed and reflects the database at the time it was opened.
+	MaxStaleness time.Duration
+
+	// ScanLeaseDuration, if non-zero, makes each DBIterator of a database opened with ReadOnly
+	// record a lease in the manifest, which retains the SSTs read by the iterator from the garbage
+	// collector of the writer until the iterator is closed, however long it outlives GCMinAge. The
+	// lease is renewed every ScanLeaseDuration / 2 while the iterator is open, and expires once it
+	// goes ScanLeaseDuration without being renewed, such as if the process exits. The lease adds a
+	// manifest write to the creation and the close of each iterator.
+	ScanLeaseDuration time.Duration
+
 	// Log used to log database warnings
 	Log *slog.Logger
 
+	// Clock is the source of time for flush and poll tickers and timestamps. If nil,
+	// defaults to SystemClock. Tests may provide a ManualClock to control time.
+	Clock Clock
+
+	// If true, the goroutines doing WAL flushes, memtable flushes and compactions are labeled
+	// with the work and its stage, such that the samples of a CPU profile of the process are
+	// attributed to the stages of the write path. See DB.WriteStageTimings for the time spent
+	// in each stage, which is recorded regardless of ProfileLabels.
+	ProfileLabels bool
+
+	// MetricsRegisterer, if not nil, is the Prometheus registerer with which the database, or a
+	// Compactor opened with these options, registers its metrics: the latency of Get and of writes,
+	// the duration and bytes of WAL flushes, memtable flushes, the bytes read and written by
+	// compaction, the requests made to object storage and the hits and misses of the block cache.
+	// The metrics are unregistered by Close. Use prometheus.WrapRegistererWith to label the
+	// metrics of each database registered with the same registerer.
+	MetricsRegisterer prometheus.Registerer
+
+	// TracerProvider, if not nil, provides the OpenTelemetry tracer with which Get, Put, Scan, WAL
+	// flushes, memtable flushes and compactions record spans, which carry the IDs of the SSTs read
+	// or written along with their block and byte counts. Each request made to object storage is
+	// recorded as a child span of the operation which made it. If nil, no spans are recorded.
+	TracerProvider trace.TracerProvider
+
+	// OnTaskEvent, if not nil, is called when a background task such as the WAL flush,
+	// memtable flush or compaction loop fails, is restarted, or exhausts its restarts.
+	// A task which has exhausted its restarts is also reported by DB.HealthCheck. It is
+	// also called when the database is degraded or resumed, see MaxFlushFailureDuration.
+	OnTaskEvent func(TaskEvent)
+
+	// OnDurable, if not nil, is called each time the durable watermark of the database advances,
+	// that is when a WAL SST is uploaded to object storage or a memtable flush writes the manifest.
+	// Writes are durable once the watermark includes their WAL, such that an application may
+	// release downstream work, as in the outbox pattern, once its writes are safe. OnDurable is
+	// called by the flush tasks, one call at a time with a watermark which never decreases, and
+	// must not block. See DB.DurableWatermark
+	OnDurable func(DurableWatermark)
+
+	// EventListener, if not nil, is notified of the WAL flushes, memtable flushes and compactions of
+	// the database, of each manifest it writes and of the failures of its background work, such that
+	// an application may alert on failures or keep its own records without polling the database.
+	// See config.EventListener
+	EventListener EventListener
+
+	// MaxFlushFailureDuration is the time for which WAL or memtable flushes may fail continuously
+	// before the database is degraded. A degraded database rejects writes with ErrDegraded, rather
+	// than buffering an unbounded amount of unflushed writes in memory, and continues to serve
+	// reads until DB.Resume succeeds. If zero, the database is never degraded.
+	MaxFlushFailureDuration time.Duration
+
+	// ScrubInterval is how often the writer verifies the checksums of every SST referenced by the
+	// manifest in the background, see DB.VerifyChecksums. Each corruption found is logged, and the
+	// report of the most recent scrub is returned by DB.ScrubReport. If zero, SSTs are not scrubbed.
+	ScrubInterval time.Duration
+
+	// QuarantineCorruptSSTs quarantines an L0 or compacted SST once a corrupted block is read from
+	// it, or once corruption is found in it by DB.VerifyChecksums or a scrub, rather than failing
+	// every read which includes it. The corrupted blocks of a quarantined SST are skipped, such
+	// that reads return the blocks which are intact along with the older versions of the keys of
+	// the corrupted blocks. A WAL SST which is truncated by RecoveryModeTruncate or
+	// RecoveryModeRepair is also quarantined. Quarantined SSTs are recorded in the manifest and
+	// reported by DB.HealthCheck until they are removed by DB.ClearQuarantine. See DB.Quarantined
+	QuarantineCorruptSSTs bool
+
+	// GCInterval is how often the writer deletes the WAL SSTs, compacted SSTs, filter sidecars and
+	// manifests in object storage which are no longer referenced by the database, such as those
+	// leaked by a crash between the upload of an SST and the update of the manifest. See
+	// DB.CollectGarbage. If zero, garbage is only collected when DB.CollectGarbage is called.
+	GCInterval time.Duration
+
+	// GCMinAge is the minimum age of an unreferenced object before it is deleted by the garbage
+	// collector. It must exceed the time taken by the longest flush or compaction, whose SSTs are
+	// uploaded before they are referenced by the manifest, and the time a reader may continue to
+	// read the state of a manifest after a newer manifest was written. If zero, defaults to 24 hours.
+	GCMinAge time.Duration
+
+	// IdleTimeout is the time the writer goes without a write before it is idle. An idle database
+	// stops the tickers of its WAL flush, manifest poll, metrics and scrub tasks, trims its block
+	// cache to IdleBlockCacheSize and releases its cached filters, such that a process embedding
+	// many mostly idle databases does not spend memory and wakeups on them. The next write wakes
+	// the database. The compactor, which may run in another process, is not paused. If zero, the
+	// database is never idle. See DB.Idle
+	IdleTimeout time.Duration
+
+	// IdleBlockCacheSize is the number of bytes of blocks the block cache keeps while the database
+	// is idle, see IdleTimeout. Blocks evicted when the database went idle are read again from
+	// object storage when they are next needed.
+	IdleBlockCacheSize uint64
+
+	// RecoveryMode determines how Open handles WAL SSTs which are inconsistent with the manifest,
+	// such as a WAL SST which is missing while a later WAL SST is present, or which are corrupt.
+	// Defaults to RecoveryModeStrict, which fails Open with ErrInconsistentWAL or ErrCorruptWAL.
+	RecoveryMode RecoveryMode
+
+	// HandOffToken, if not empty, is the token returned by DB.HandOff of the previous writer. Open
+	// fails with ErrStaleHandOff if another writer opened the database after the hand-off, such that
+	// the writer starts from the state which was handed off. It is ignored by a read-only DB.
+	HandOffToken string
+
+	// The hash function and seed used when building bloom filters for new SSTables.
+	// Both are persisted with each filter, such that SSTables written with a different
+	// hash or seed remain readable. Defaults to bloom.HashFNV64 with a zero seed.
+	FilterHash bloom.Hash
+	FilterSeed uint64
+
+	// If not nil, the prefix of each key extracted by PrefixExtractor, such as the tenant of
+	// tenant-scoped keys, is added to the bloom filter of each SST along with the key. A scan
+	// whose range lies within a single prefix, such as DB.ScanPrefix, then skips the SSTs whose
+	// filter excludes the prefix. Only the filters of SSTs written with an extractor of the same
+	// name are used for scans; partitioned filters and rebuilt filters are not.
+	// See bloom.FixedPrefix and bloom.DelimitedPrefix
+	PrefixExtractor bloom.PrefixExtractor
+
 	// Configuration opts for the compactor.
 	CompactorOptions *CompactorOptions
+
+	// Keyspaces holds the options of the keyspaces whose keys are flushed and compacted as
+	// configured by KeyspaceOptions.L0SSTSizeBytes, KeyspaceOptions.CompressionCodec and
+	// KeyspaceOptions.Compaction, by name. The keys of each of these keyspaces are written to
+	// L0 and compacted SSTs of their own. As SSTs are written by the writer and the compactor,
+	// which may run in another process, the keyspaces must be registered with the options of
+	// both. DB.Keyspace returns the registered keyspaces with their options.
+	Keyspaces map[string]KeyspaceOptions
+
 	CompressionCodec compress.Codec
+
+	// If true, a checksum of each value is computed when the value is written and stored with the
+	// value, and the checksum is verified each time the value is read by Get. Unlike the checksums
+	// of SST blocks, which are verified before the block is decompressed, the value checksum is
+	// verified after every layer of the read path, such that a bug which corrupts a value in the
+	// memtable, compaction or decompression is detected. Values written with a checksum carry it
+	// through compaction, which adds 4 bytes to each value stored.
+	ValueChecksums bool
+
+	// If true, each SSTable block is compressed with CompressionCodec and stored
+	// uncompressed when compression reduces the block size by less than 12.5%,
+	// such as blocks of already compressed values. This saves the CPU cost of
+	// decompressing those blocks on read.
+	AutoCompression bool
+
+	// BlockRestartInterval stores the key of each row of an SSTable block relative to the key of
+	// the previous row rather than the first key of the block, with the full key stored every
+	// BlockRestartInterval rows at a restart point. This shrinks SSTables whose keys share long
+	// prefixes, such as sequential keys, and their block reads, as only the restart points are
+	// indexed. A key is found by a binary search of the restart points followed by a scan of at
+	// most BlockRestartInterval rows; 16 is a good balance. If zero, blocks index every row.
+	BlockRestartInterval uint16
+
+	// CompressionLevel is the level at which CompressionCodec compresses SSTable blocks,
+	// trading write CPU for smaller SSTables. Levels are supported by compress.CodecZstd
+	// (1 to 22) and compress.CodecZlib (1 to 9). If zero, the default level of the codec is used.
+	CompressionLevel int
+
+	// WALCompressionCodec, L0CompressionCodec and CompactedCompressionCodec override
+	// CompressionCodec for the SSTables of the WAL, of L0 and of the compacted sorted runs
+	// respectively, such as to leave the short-lived WAL uncompressed while compressing
+	// compacted data with compress.CodecZstd. If None, CompressionCodec is used. The
+	// CompressionLevel is applied to each codec which supports levels.
+	WALCompressionCodec       mo.Option[compress.Codec]
+	L0CompressionCodec        mo.Option[compress.Codec]
+	CompactedCompressionCodec mo.Option[compress.Codec]
 }
 
 func DefaultDBOptions() DBOptions {
@@ -80,10 +418,62 @@ func DefaultDBOptions() DBOptions {
 		FlushInterval:        100 * time.Millisecond,
 		ManifestPollInterval: 1 * time.Second,
 		MinFilterKeys:        1000,
+		FilterBitsPerKey:     10,
 		L0SSTSizeBytes:       64 * 1024 * 1024,
+		BlockCacheSize:       64 * 1024 * 1024,
 		CompactorOptions:     DefaultCompactorOptions(),
 		CompressionCodec:     compress.CodecNone,
 		Log:                  slog.Default(),
+		Clock:                SystemClock{},
+	}
+}
+
+// ReaderOptions Configuration for a DBReader opened with OpenReader. A DBReader serves reads of a
+// database written by another process, and never fences the writer.
+type ReaderOptions struct {
+	// How frequently to poll for a new manifest, such that the reader sees newly flushed and
+	// compacted data. If zero, the manifest is never polled and the reader reflects the
+	// database at the time it was opened. The DBReaders of a process which poll the same
+	// database at the same interval and Clock share a single read of the manifest per poll.
+	ManifestPollInterval time.Duration
+
+	// If true, the WAL SSTs which have not yet been flushed to L0 are replayed each time the
+	// manifest is loaded, such that the reader sees writes once they are durable rather than
+	// once they are flushed to L0. This requires a list and read of each WAL SST, which adds
+	// object storage requests to each poll.
+	TailWAL bool
+
+	// WALPollInterval, if non-zero, makes the reader a replica, which lists the WAL SSTs every
+	// WALPollInterval and applies those written since it last did so to the memtables of its view,
+	// without reloading the manifest. A replica sees writes soon after they are durable, with a
+	// list of the WAL SSTs and a read of each new WAL SST per poll, rather than once they are
+	// flushed to L0, or a replay of every WAL SST not yet flushed to L0 per manifest poll as with
+	// TailWAL. The manifest continues to be polled each ManifestPollInterval, which drops the writes
+	// which have been flushed to L0 from the memtables. WALPollInterval implies TailWAL.
+	WALPollInterval time.Duration
+
+	// MaxStaleness bounds how out of date the view of a reader may be when a read is served. If the
+	// view was last refreshed longer than MaxStaleness ago, it is refreshed before the read is served:
+	// the WAL SSTs written since are applied by a replica, see WALPollInterval, while the manifest is
+	// reloaded by other readers. If zero, reads are served from the view of the most recent poll.
+	MaxStaleness time.Duration
+
+	// ScanLeaseDuration, if non-zero, makes each DBIterator of the reader record a lease in the
+	// manifest which retains the SSTs it reads until it is closed, see DBOptions.ScanLeaseDuration
+	ScanLeaseDuration time.Duration
+
+	// Log used to log reader warnings
+	Log *slog.Logger
+
+	// Clock is the source of time for the poll ticker. If nil, defaults to SystemClock.
+	Clock Clock
+}
+
+func DefaultReaderOptions() ReaderOptions {
+	return ReaderOptions{
+		ManifestPollInterval: 1 * time.Second,
+		Log:                  slog.Default(),
+		Clock:                SystemClock{},
 	}
 }
 
@@ -102,11 +492,56 @@ const (
 	Uncommitted
 )
 
+// HedgedReadOptions configures the hedging of the reads made by Get, see DBOptions.HedgedReads
+type HedgedReadOptions struct {
+	// Percentile of the latency of recent reads, between 0 and 1, after which a read which has not
+	// completed is hedged by sending a second request for the same range, such as 0.95. The first
+	// response is used and the other request is canceled. If zero, reads are not hedged.
+	Percentile float64
+
+	// MinDelay is the shortest time after which a read is hedged, which prevents fast reads from
+	// being hedged when the latency of reads is low. Reads are hedged after MinDelay until enough
+	// reads have been observed to compute the Percentile.
+	MinDelay time.Duration
+
+	// MaxInFlight caps the number of reads, including hedges, made by Get which are in flight
+	// at once. Reads beyond the cap wait for a read to complete, while a hedge is not sent. If
+	// zero, reads are not limited.
+	MaxInFlight int
+}
+
+// RecoveryMode determines how the WAL is replayed when Open finds the WAL SSTs in object
+// storage are inconsistent with the manifest. See DBOptions.RecoveryMode
+type RecoveryMode int
+
+const (
+	// RecoveryModeStrict - Open fails if a WAL SST which is expected by the manifest is missing
+	RecoveryModeStrict RecoveryMode = iota
+
+	// RecoveryModeRepair - Open logs the missing WAL SSTs and replays the WAL SSTs which are
+	// present, such that the writes held by the missing WAL SSTs are lost. A corrupt WAL SST is
+	// truncated as with RecoveryModeTruncate.
+	RecoveryModeRepair
+
+	// RecoveryModeTruncate - Open replays the WAL up to the first corrupt or torn entry, logs the
+	// corruption and discards the rest of the WAL, such that the writes which follow the corrupt
+	// entry are lost. Open still fails if a WAL SST is missing.
+	RecoveryModeTruncate
+)
+
 // ReadOptions Configuration for client read operations. `ReadOptions` is supplied for each
 // read call and controls the behavior of the read.
 type ReadOptions struct {
 	// The read commit level for read operations.
 	ReadLevel ReadLevel
+
+	// AllowStale, if true, serves a Get whose reads of SSTs cannot complete before ctx is done
+	// from the data which is at hand rather than failing: the memtables, and the filters, indexes
+	// and blocks held by the block cache and disk cache. The SSTs which may hold the key but
+	// are not cached are skipped, such that a newer value of the key may exist. Such an answer
+	// is returned along with an error which wraps slatedb.ErrStaleRead, and also wraps
+	// slatedb.ErrKeyNotFound if the key was not found.
+	AllowStale bool
 }
 
 func DefaultReadOptions() ReadOptions {
@@ -115,6 +550,48 @@ func DefaultReadOptions() ReadOptions {
 	}
 }
 
+// IteratorOptions Configuration for the iterator returned by a scan. `IteratorOptions`
+// is supplied for each scan and controls the behavior of the iterator.
+type IteratorOptions struct {
+	// The read commit level of the scan, see ReadOptions
+	ReadLevel ReadLevel
+
+	// If true, the keys of the range are returned in descending order
+	Reverse bool
+
+	// If not nil, Transform is called with the value of each key returned by the iterator, and
+	// the iterator returns the transformed value in place of the value. See TransformFunc
+	Transform TransformFunc
+
+	// MaxDuration, if not zero, is the time after which the iterator stops returning keys, such
+	// that a scan which visits far more keys than expected cannot run indefinitely. Once exceeded,
+	// Next returns false and Err returns an error which wraps context.DeadlineExceeded.
+	MaxDuration time.Duration
+
+	// Throughput, if true, favours bandwidth over latency, for scans which read most of the
+	// database such as a full export. Rather than a few blocks at a time, the SSTs of the scan are
+	// read with requests of up to 64 MiB, which usually span an entire SST, and the blocks of each
+	// request are decoded as they are received while the next request is in flight. The blocks read
+	// by the scan bypass the block cache and disk cache, such that the scan does not evict the blocks
+	// of other reads. Each SST being iterated may hold two requests of blocks in memory.
+	Throughput bool
+}
+
+// TransformFunc transforms the value of a key returned by an iterator, for instance to decode the
+// value and project only the fields the caller needs. The transformed value should be appended to
+// `dst` and returned. `dst` is a buffer which the iterator reuses for each key, such that the
+// transformed values do not require an allocation per key; a transformed value is only valid
+// until the next key is returned by the iterator.
+//
+// Returning an error stops the iteration, and the error is returned by the Err method of the iterator.
+type TransformFunc func(dst []byte, key []byte, value []byte) ([]byte, error)
+
+func DefaultIteratorOptions() IteratorOptions {
+	return IteratorOptions{
+		ReadLevel: Committed,
+	}
+}
+
 // WriteOptions Configuration for client write operations. `WriteOptions` is supplied for each
 // write call and controls the behavior of the write.
 type WriteOptions struct {
@@ -129,26 +606,258 @@ func DefaultWriteOptions() WriteOptions {
 	}
 }
 
+// KeyspaceOptions Configuration for DB.KeyspaceWithOptions and DBOptions.Keyspaces. TTL applies
+// to the writes made through the Keyspace. The keys of every keyspace share the memtables, L0 and
+// sorted runs of the database, the options which determine how the SSTs of a keyspace are written
+// only apply to the keyspaces of DBOptions.Keyspaces, which are written to SSTs of their own.
+type KeyspaceOptions struct {
+	// TTL, if non-zero, is the time after which each value put through the keyspace expires,
+	// such that a keyspace of metrics may expire while the other keyspaces do not, see
+	// DB.PutWithTTL
+	TTL time.Duration
+
+	// L0SSTSizeBytes, if non-zero, is the size of the keys and values of the keyspace at which
+	// they are split into another L0 SST when a memtable is flushed, or a WriteBatch or an
+	// IngestWriter writes to L0, in place of DBOptions.L0SSTSizeBytes. A keyspace of large values
+	// which are scanned may be flushed to larger SSTs than a keyspace of small values which are read
+	// by key. The memtable is still flushed once it reaches DBOptions.L0SSTSizeBytes.
+	L0SSTSizeBytes uint64
+
+	// CompressionCodec, if Some, compresses the L0 and compacted SSTs of the keyspace in place of
+	// DBOptions.L0CompressionCodec and DBOptions.CompactedCompressionCodec, such that a keyspace of
+	// values which do not compress is not compressed while the others are
+	CompressionCodec mo.Option[compress.Codec]
+
+	// Compaction, if not nil, configures the compaction of the keyspace
+	Compaction *KeyspaceCompactionOptions
+}
+
+func DefaultKeyspaceOptions() KeyspaceOptions {
+	return KeyspaceOptions{}
+}
+
+// KeyspaceCompactionOptions Configuration of the compaction of a keyspace of DBOptions.Keyspaces,
+// see KeyspaceOptions.Compaction
+type KeyspaceCompactionOptions struct {
+	// MinL0CompactionSSTs, if non-zero, is the number of L0 SSTs of the keyspace which triggers a
+	// compaction of L0 before CompactorOptions.MinL0CompactionSSTs SSTs have accumulated, such that
+	// a keyspace which is read often is compacted early. As L0 is compacted from its oldest SST,
+	// the older L0 SSTs of the other keyspaces are compacted along with those of the keyspace.
+	MinL0CompactionSSTs int
+
+	// MaxSSTSize, if non-zero, is the maximum size of the compacted SSTs of the keyspace in place
+	// of CompactorOptions.MaxSSTSize
+	MaxSSTSize uint64
+}
+
+// KeyspaceOf returns the name of the keyspace of `keyspaces` which holds `key`, the keys of a
+// keyspace being prefixed with its name and a zero byte, or false if no keyspace of `keyspaces`
+// holds `key`. See DBOptions.Keyspaces
+func KeyspaceOf(keyspaces map[string]KeyspaceOptions, key []byte) (string, bool) {
+	if len(keyspaces) == 0 {
+		return "", false
+	}
+	i := bytes.IndexByte(key, 0x00)
+	if i < 0 {
+		return "", false
+	}
+	if _, ok := keyspaces[string(key[:i])]; !ok {
+		return "", false
+	}
+	return string(key[:i]), true
+}
+
+// CheckpointOptions Configuration for DB.CreateCheckpoint
+type CheckpointOptions struct {
+	// Lifetime is the time after which the checkpoint expires and no longer pins the state
+	// of the database. If zero, the checkpoint never expires and must be deleted.
+	Lifetime time.Duration
+}
+
+func DefaultCheckpointOptions() CheckpointOptions {
+	return CheckpointOptions{}
+}
+
+// CloneOptions Configuration for slatedb.CreateCloneWithOptions
+type CloneOptions struct {
+	// Clock is the clock against which the expiry of the checkpoint is checked, which should be
+	// the DBOptions.Clock of the source database. If nil, SystemClock is used
+	Clock Clock
+}
+
+func DefaultCloneOptions() CloneOptions {
+	return CloneOptions{Clock: SystemClock{}}
+}
+
+// VerifyOptions Configuration for slatedb.VerifyWithOptions
+type VerifyOptions struct {
+	// SourcePath, if not empty, is the path within SourceBucket of the database the backup was
+	// copied from. Samples keys chosen at random from the backup are compared with their values
+	// in the source at the checkpoint CheckpointID, the checkpoint the backup was copied from,
+	// which confirms the backup is restorable before the source is aged out.
+	SourcePath   string
+	SourceBucket objstore.Bucket
+	CheckpointID uint64
+
+	// Samples is the number of keys of the backup compared with the source. If zero, 100 keys
+	// are compared.
+	Samples int
+}
+
+func DefaultVerifyOptions() VerifyOptions {
+	return VerifyOptions{Samples: 100}
+}
+
 type CompactorOptions struct {
 	// The interval at which the compactor checks for a new manifest and decides
 	// if a compaction must be scheduled
 	PollInterval time.Duration
 
 	// Timeout is the time compaction should wait before timing out network
-	// operations
+	// operations. If zero, defaults to 5 seconds.
 	Timeout time.Duration
 
 	// A compacted SSTable's maximum size (in bytes). If more data needs to be
 	// written to a Sorted Run during a compaction, a new SSTable will be created
 	// in the Sorted Run when this size is exceeded.
 	MaxSSTSize uint64
+
+	// The number of L0 SSTables which must accumulate before they are merged
+	// into a new Sorted Run. If zero, defaults to 4.
+	MinL0CompactionSSTs int
+
+	// The maximum number of L0 SSTables merged into a new Sorted Run by a single
+	// compaction. The oldest L0 SSTables are compacted first. If zero, all L0
+	// SSTables are merged in a single compaction.
+	MaxL0CompactionSSTs int
+
+	// The maximum number of Sorted Runs allowed before all Sorted Runs are merged
+	// into a single Sorted Run. If zero, Sorted Runs are never merged.
+	MaxSortedRuns int
+
+	// MaxConcurrentCompactions is the number of compactions executed at once. Compactions run in
+	// parallel only if their sources are disjoint, such as a compaction of L0 and a merge of
+	// sorted runs; compactions of L0 run one at a time, as their sorted runs must be committed
+	// in order. If zero, defaults to 1.
+	MaxConcurrentCompactions int
+
+	// BlockCache determines whether the blocks read by compactions are cached in the block cache
+	// of the database. Compactions read each block of their input once, such that caching the
+	// blocks evicts the blocks read by Get and Scan. Defaults to CompactionBlockCacheShared.
+	BlockCache CompactionBlockCache
+
+	// BlockCacheSize is the number of bytes of blocks cached for compactions if BlockCache is
+	// CompactionBlockCacheSeparate. If zero, blocks read by compactions are not cached.
+	BlockCacheSize uint64
+
+	// PrefetchConcurrency is the number of requests each input SST of a compaction has in flight
+	// for the blocks after those being merged, such that the latency of object storage overlaps
+	// with merging. Each request reads up to DBOptions.ReadAheadBytes of adjacent blocks, and
+	// the next SST of each input sorted run is opened while the current SST is merged.
+	// If zero, blocks are read when the merge reaches them. DefaultCompactorOptions sets it to 2.
+	PrefetchConcurrency int
+
+	// MaxBytesPerSecond limits the rate at which compactions merge entries, summed over every
+	// compaction in progress, such that compactions leave bandwidth and CPU to Get and Put.
+	// If zero, compactions are not throttled. If AutoTune is set, the limit is adjusted by
+	// the database and MaxBytesPerSecond is only the initial limit.
+	MaxBytesPerSecond uint64
+
+	// AutoTune adjusts the rate limit of compactions to keep the mean latency of Get and Put
+	// near a target, so the limit need not be tuned for each workload. If nil, the limit is
+	// MaxBytesPerSecond.
+	AutoTune *CompactionAutoTune
+
+	// Scheduler, if not nil, decides which compactions are run in place of the size-tiered
+	// scheduler configured by MinL0CompactionSSTs, MaxL0CompactionSSTs and MaxSortedRuns. It must
+	// implement compaction.Scheduler, which cannot be named here as the compaction package
+	// depends on this package. See compaction.NewSizeTieredCompactionScheduler to delegate to the
+	// default scheduler.
+	Scheduler any
+
+	// Filter, if not nil, is called by compactions for the value of each key they merge, such that
+	// applications may purge data which has expired or become invalid without deleting it.
+	Filter CompactionFilter
 }
 
+// CompactionDecision is the decision of a CompactionFilter for the value of a key
+type CompactionDecision int
+
+const (
+	// CompactionKeep writes the value unchanged
+	CompactionKeep CompactionDecision = iota
+	// CompactionRemove deletes the key, as if it had been deleted when the value was written
+	CompactionRemove
+	// CompactionChangeValue replaces the value with the value returned by the filter
+	CompactionChangeValue
+)
+
+// CompactionFilter decides whether a compaction keeps, removes or changes the value of each key it
+// merges. Only the most recent value of a key is filtered; tombstones are not. A key is filtered each
+// time it is compacted, so the decision must not depend on how many times the key has been filtered.
+// Reads are not filtered: they return the value until the compaction which filters it is committed.
+type CompactionFilter interface {
+	// Filter returns the decision for the value of `key`, and the new value if the decision is
+	// CompactionChangeValue. It is called concurrently by the compactions in progress, and must not
+	// retain `key` or `value`.
+	Filter(key []byte, value []byte) (CompactionDecision, []byte)
+}
+
+// CompactionAutoTune configures the controller which adjusts the rate limit of compactions
+// from the latency of Get and Put. See CompactorOptions.AutoTune
+type CompactionAutoTune struct {
+	// TargetLatency is the mean latency of Get and Put the controller aims for. Compactions are
+	// throttled towards MinBytesPerSecond while the latency is above the target, and allowed up
+	// to MaxBytesPerSecond while it is below.
+	TargetLatency time.Duration
+
+	// MinBytesPerSecond and MaxBytesPerSecond bound the rate limit. MinBytesPerSecond must be
+	// greater than zero such that compactions always make progress, and MaxBytesPerSecond must
+	// not be less than MinBytesPerSecond.
+	MinBytesPerSecond uint64
+	MaxBytesPerSecond uint64
+
+	// Interval is how often the latency is sampled and the rate limit adjusted. If zero,
+	// defaults to 1 second.
+	Interval time.Duration
+}
+
+// CompactionBlockCache determines how compactions use the block cache of the database.
+// See CompactorOptions.BlockCache
+type CompactionBlockCache int
+
+const (
+	// CompactionBlockCacheShared - Compactions read blocks through the block cache of the database,
+	// and the blocks they read are added to it
+	CompactionBlockCacheShared CompactionBlockCache = iota
+
+	// CompactionBlockCacheBypass - Compactions read blocks from object storage without reading or
+	// adding to the block cache of the database
+	CompactionBlockCacheBypass
+
+	// CompactionBlockCacheSeparate - Compactions read blocks through a cache of
+	// CompactorOptions.BlockCacheSize bytes which is not shared with Get and Scan
+	CompactionBlockCacheSeparate
+)
+
 func DefaultCompactorOptions() *CompactorOptions {
 	return &CompactorOptions{
 		PollInterval: 5 * time.Second,
 		// Ideally the timeout should be less than or equal to the poll interval.
-		Timeout:    5 * time.Second,
-		MaxSSTSize: 1024 * 1024 * 1024,
+		Timeout:             5 * time.Second,
+		MaxSSTSize:          1024 * 1024 * 1024,
+		MinL0CompactionSSTs: 4,
+		PrefetchConcurrency: 2,
 	}
 }
+
+// DurableWatermark identifies the writes of a database which are durable in object storage
+type DurableWatermark struct {
+	// WALID is the ID of the most recent WAL SST uploaded to object storage. Every write in a WAL
+	// SST with an ID of WALID or less is durable, and survives a crash of the writer.
+	WALID uint64
+
+	// ManifestID is the ID of the most recent manifest written by a memtable flush. The L0 SSTs
+	// recorded by the manifest hold every write in the WAL SSTs it reports as compacted.
+	ManifestID uint64
+}
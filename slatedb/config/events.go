@@ -0,0 +1,199 @@
+package config
+
+import (
+	"time"
+
+	"github.com/slatedb/slatedb-go/internal/task"
+)
+
+// EventListener is notified of the background work of a database, such as flushes, compactions
+// and manifest updates, see DBOptions.EventListener. Its methods are called by the background
+// tasks of the database as the work completes, and must not block or call back into the database.
+// Embed NoopEventListener to implement a subset of the events.
+type EventListener interface {
+	// OnWALFlushed is called once a WAL SST has been written to object storage
+	OnWALFlushed(WALFlushedEvent)
+
+	// OnMemtableFlushed is called once an immutable memtable has been written to an L0 SST and
+	// the manifest which records the SST has been written
+	OnMemtableFlushed(MemtableFlushedEvent)
+
+	// OnCompactionBegin is called when the compactor starts executing a compaction
+	OnCompactionBegin(CompactionBeginEvent)
+
+	// OnCompactionEnd is called once a compaction has written its sorted run, or has failed. The
+	// sorted run is committed to the manifest afterwards, see OnManifestUpdated.
+	OnCompactionEnd(CompactionEndEvent)
+
+	// OnManifestUpdated is called each time the database, or its compactor, writes a manifest
+	OnManifestUpdated(ManifestUpdatedEvent)
+
+	// OnBackgroundError is called when a WAL flush or memtable flush fails, when a compaction
+	// fails, and when a background task returns an error or panics, see DBOptions.OnTaskEvent
+	OnBackgroundError(BackgroundErrorEvent)
+
+	// OnTaskEvent is called when a background task fails, is restarted or exhausts its restarts,
+	// and when the database is degraded or resumed, as is DBOptions.OnTaskEvent
+	OnTaskEvent(TaskEvent)
+}
+
+// NoopEventListener is an EventListener which ignores every event
+type NoopEventListener struct{}
+
+func (NoopEventListener) OnWALFlushed(WALFlushedEvent)           {}
+func (NoopEventListener) OnMemtableFlushed(MemtableFlushedEvent) {}
+func (NoopEventListener) OnCompactionBegin(CompactionBeginEvent) {}
+func (NoopEventListener) OnCompactionEnd(CompactionEndEvent)     {}
+func (NoopEventListener) OnManifestUpdated(ManifestUpdatedEvent) {}
+func (NoopEventListener) OnBackgroundError(BackgroundErrorEvent) {}
+func (NoopEventListener) OnTaskEvent(TaskEvent)                  {}
+
+// TaskEventHandler returns the handler of the events of the background tasks configured by `o`,
+// which converts each event to a TaskEvent and notifies it, see NotifyTaskEvent
+func (o *DBOptions) TaskEventHandler() func(task.Event) {
+	return func(event task.Event) {
+		o.NotifyTaskEvent(TaskEvent{
+			Task:     event.Task,
+			Type:     TaskEventType(event.Type),
+			Err:      event.Err,
+			Restarts: event.Restarts,
+		})
+	}
+}
+
+// NotifyTaskEvent calls OnTaskEvent and EventListener.OnTaskEvent with `event`, and reports the
+// failure of a task to EventListener.OnBackgroundError
+func (o *DBOptions) NotifyTaskEvent(event TaskEvent) {
+	if o.OnTaskEvent != nil {
+		o.OnTaskEvent(event)
+	}
+	if o.EventListener == nil {
+		return
+	}
+	o.EventListener.OnTaskEvent(event)
+	if event.Type == TaskFailed {
+		o.EventListener.OnBackgroundError(BackgroundErrorEvent{Task: event.Task, Err: event.Err})
+	}
+}
+
+// TaskEventType is the type of a TaskEvent
+type TaskEventType int
+
+// The values of TaskEventType are those of the events of the task manager, see TaskEventHandler
+const (
+	// TaskFailed is emitted when a task returns an error or panics
+	TaskFailed = TaskEventType(task.EventFailed)
+	// TaskRestarted is emitted when a failed task is started again
+	TaskRestarted = TaskEventType(task.EventRestarted)
+	// TaskGaveUp is emitted when a task has exhausted its restarts
+	TaskGaveUp = TaskEventType(task.EventGaveUp)
+	// TaskDegraded is emitted when a flush task has failed for longer than
+	// DBOptions.MaxFlushFailureDuration, such that the database stops accepting writes
+	TaskDegraded = TaskEventType(task.EventDegraded)
+	// TaskResumed is emitted when a degraded database accepts writes again
+	TaskResumed = TaskEventType(task.EventResumed)
+)
+
+func (t TaskEventType) String() string {
+	return task.EventType(t).String()
+}
+
+// TaskEvent describes a failure or restart of a background task, or a transition of the database
+// to or from degraded, see DBOptions.OnTaskEvent
+type TaskEvent struct {
+	// Task is the name of the background task, such as "wal_flush" or "memtable_flush"
+	Task string
+	Type TaskEventType
+
+	// Err is the error with which the task failed, or which degraded the database
+	Err error
+
+	// Restarts is the number of times the task has been restarted
+	Restarts int
+}
+
+type WALFlushedEvent struct {
+	// WALID is the ID of the WAL SST
+	WALID uint64
+
+	// Bytes is the encoded size of the WAL SST
+	Bytes uint64
+
+	// Duration is the time taken to write the WAL SST
+	Duration time.Duration
+}
+
+type MemtableFlushedEvent struct {
+	// SSTID is the ID of the L0 SST, or of the first of the SSTs if the memtable held the keys
+	// of keyspaces of DBOptions.Keyspaces, which are written to SSTs of their own
+	SSTID string
+
+	// SSTs is the number of L0 SSTs the memtable was written to
+	SSTs int
+
+	// LastWALID is the ID of the last WAL SST whose writes are held by the L0 SST
+	LastWALID uint64
+
+	// ManifestID is the ID of the manifest which records the L0 SST
+	ManifestID uint64
+
+	// Bytes is the encoded size of the L0 SSTs
+	Bytes uint64
+
+	// Duration is the time taken to write the L0 SST and the manifest
+	Duration time.Duration
+}
+
+type CompactionBeginEvent struct {
+	CompactionID string
+
+	// Destination is the ID of the sorted run written by the compaction
+	Destination uint32
+
+	// SSTIDs are the IDs of the SSTs compacted, those of L0 followed by those of the sorted runs
+	SSTIDs []string
+
+	// SortedRuns are the IDs of the sorted runs compacted
+	SortedRuns []uint32
+}
+
+type CompactionEndEvent struct {
+	CompactionID string
+
+	// Destination is the ID of the sorted run written by the compaction
+	Destination uint32
+
+	// OutputSSTIDs are the IDs of the SSTs of the sorted run written
+	OutputSSTIDs []string
+
+	// BytesRead and BytesWritten are the encoded sizes of the input and output SSTs
+	BytesRead    uint64
+	BytesWritten uint64
+
+	// Duration is the time taken to execute the compaction
+	Duration time.Duration
+
+	// Err is the error with which the compaction failed, or nil
+	Err error
+}
+
+type ManifestUpdatedEvent struct {
+	// ManifestID is the ID of the manifest written
+	ManifestID uint64
+
+	// WriterEpoch and CompactorEpoch are the epochs recorded by the manifest
+	WriterEpoch    uint64
+	CompactorEpoch uint64
+
+	// L0SSTs and SortedRuns are the number of L0 SSTs and sorted runs recorded by the manifest
+	L0SSTs     int
+	SortedRuns int
+}
+
+type BackgroundErrorEvent struct {
+	// Task is the name of the background work which failed, such as "wal_flush",
+	// "memtable_flush", "compaction" or the name of a background task
+	Task string
+
+	Err error
+}
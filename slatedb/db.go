@@ -6,24 +6,46 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
 	"math"
+	"slices"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/kapetan-io/tackle/set"
+	"github.com/samber/mo"
 	"github.com/slatedb/slatedb-go/internal"
 	"github.com/slatedb/slatedb-go/internal/assert"
+	"github.com/slatedb/slatedb-go/internal/compress"
+	"github.com/slatedb/slatedb-go/internal/iter"
+	"github.com/slatedb/slatedb-go/internal/profile"
 	"github.com/slatedb/slatedb-go/internal/sstable"
+	"github.com/slatedb/slatedb-go/internal/sstable/block"
+	"github.com/slatedb/slatedb-go/internal/sstable/bloom"
+	"github.com/slatedb/slatedb-go/internal/task"
+	"github.com/slatedb/slatedb-go/internal/tracing"
 	"github.com/slatedb/slatedb-go/internal/types"
+	"github.com/slatedb/slatedb-go/slatedb/common"
 	"github.com/slatedb/slatedb-go/slatedb/compacted"
 	"github.com/slatedb/slatedb-go/slatedb/compaction"
 	"github.com/slatedb/slatedb-go/slatedb/config"
+	"github.com/slatedb/slatedb-go/slatedb/manifest"
 	"github.com/slatedb/slatedb-go/slatedb/state"
 	"github.com/slatedb/slatedb-go/slatedb/store"
+	"github.com/slatedb/slatedb-go/slatedb/table"
 	"github.com/thanos-io/objstore"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const BlockSize = 4096
 
+// ConditionalBucket is a bucket which can upload an object only if it does not exist, such that
+// competing writers cannot both write the same manifest version. A bucket passed to Open which
+// implements ConditionalBucket is used for conditional writes, see store.ConditionalBucket. An
+// S3 bucket is made a ConditionalBucket by s3bucket.New.
+type ConditionalBucket = store.ConditionalBucket
+
 // ErrInternal indicates an internal error outside the control of slatedb
 // has occurred. For instance, if the `DBOptions.on_corruption` callback
 // was invoked and returned an error.
@@ -45,10 +67,69 @@ type ErrRetryable = internal.ExportedRetryableError
 // complete.
 type ErrInvalidArgument = internal.ExportedInvalidArgument
 
+// ErrBlockCorrupted indicates a block of an SST failed to decode, such as when the block
+// checksum does not match because the object store returned truncated or corrupted data.
+// ErrBlockCorrupted identifies the SST and the index of the block within the SST.
+type ErrBlockCorrupted = internal.ExportedBlockCorruption
+
 // ErrKeyNotFound indicates the requested key was not found in the
 // database.
 var ErrKeyNotFound = errors.New("key not found")
 
+// ErrStaleRead indicates a Get with config.ReadOptions.AllowStale could not read every SST which may
+// hold the key before the ctx was done. The value returned with the error may not be the most recent.
+var ErrStaleRead = errors.New("stale read")
+
+// ErrRangeLocked indicates DB.LockRange found an unexpired lock which overlaps the range
+var ErrRangeLocked = errors.New("range is locked")
+
+// ErrLockLost indicates a RangeLock expired or was removed before it was renewed
+var ErrLockLost = errors.New("range lock has been lost")
+
+// ErrChecksumMismatch indicates a value read by Get does not match the checksum computed
+// when it was written. See config.DBOptions.ValueChecksums
+var ErrChecksumMismatch = errors.New("value checksum mismatch")
+
+// ErrInconsistentWAL indicates the WAL SSTs in object storage are inconsistent with the
+// manifest, such that replaying the WAL would lose writes. See config.DBOptions.RecoveryMode
+var ErrInconsistentWAL = errors.New("WAL is inconsistent with the manifest")
+
+// ErrCorruptWAL indicates an entry of a WAL SST is corrupt or torn, such that the WAL cannot be
+// replayed past it. See config.DBOptions.WALEntryChecksums and config.DBOptions.RecoveryMode
+var ErrCorruptWAL = errors.New("WAL is corrupt")
+
+// ErrQuarantined indicates SSTs of the database were quarantined because corruption was detected
+// in them, see DB.Quarantined
+var ErrQuarantined = errors.New("corrupted SSTs are quarantined")
+
+// ErrHandedOff indicates a write was attempted on a database which has been handed off by DB.HandOff
+var ErrHandedOff = errors.New("database has been handed off")
+
+// ErrStaleHandOff indicates another writer opened the database after the hand-off whose token was
+// passed to Open, see config.DBOptions.HandOffToken
+var ErrStaleHandOff = errors.New("database was opened by another writer since the hand-off")
+
+// ErrChangesUnavailable indicates the WAL SSTs which held the changes requested from DB.Changes have
+// been deleted, such as by the garbage collector, such that the changes can no longer be streamed
+var ErrChangesUnavailable = errors.New("changes are no longer available in the WAL")
+
+// ErrReadOnly indicates a write was attempted on a database opened
+// with config.DBOptions.ReadOnly
+var ErrReadOnly = errors.New("database is read-only")
+
+// ErrFenced indicates a write or flush was rejected because a newer writer has opened the
+// database. A fenced writer stops its background tasks, and must be closed. See common.ErrFenced
+var ErrFenced = common.ErrFenced
+
+// ErrDegraded indicates a write was rejected because WAL or memtable flushes have failed
+// for longer than DBOptions.MaxFlushFailureDuration. Reads continue to be served, and
+// writes are accepted again once DB.Resume succeeds.
+var ErrDegraded = errors.New("database is degraded")
+
+// ErrSnapshotReleased indicates a read was attempted on a Snapshot
+// after Snapshot.Release was called
+var ErrSnapshotReleased = errors.New("snapshot has been released")
+
 // TODO(thrawn01): Export the Corruption Types here
 
 type DB struct {
@@ -57,61 +138,231 @@ type DB struct {
 	compactor  *compaction.Compactor
 	opts       config.DBOptions
 	state      *state.DBState
+	stats      dbStats
+	statsStore *store.StatsStore
+	lockStore  *store.LockStore
+	sstAccess  *sstAccessStats
+	metrics    metricsHistory
+
+	// prom holds the Prometheus metrics of the database, which are only exported if
+	// config.DBOptions.MetricsRegisterer is set
+	prom *promMetrics
+
+	// tracer records the spans of the database, see config.DBOptions.TracerProvider
+	tracer trace.Tracer
+
+	// localWAL, if not nil, holds the writes of the WAL until they are written to object
+	// storage, see config.DBOptions.LocalWALDir
+	localWAL *store.LocalWAL
+
+	// profiler records the time spent in each stage of the WAL and memtable flushes,
+	// see DB.WriteStageTimings
+	profiler *profile.Recorder
+
+	// durable is the durable watermark, see DB.DurableWatermark
+	durable durableWatermark
+
+	// scrubReport is the report of the most recent background scrub, see DB.ScrubReport
+	scrubMu     sync.Mutex
+	scrubReport mo.Option[ChecksumReport]
+
+	// quarantine holds the SSTs which have been quarantined but not yet recorded in the manifest
+	quarantine quarantineQueue
+
+	// gcMu serializes the garbage collections of the writer, see DB.CollectGarbage
+	gcMu sync.Mutex
+
+	// pins are the SSTs read by the open Snapshots and DBIterators, see DB.CollectGarbage
+	pins sstPins
+
+	// leases are the leases recorded in the manifest by the DBIterators of a read-only DB, see
+	// config.DBOptions.ScanLeaseDuration
+	leases sstLeases
+
+	// walFlushMu serializes the flushes of the immutable WALs, such that DB.FlushWAL may be
+	// called while the WAL flush task is flushing
+	walFlushMu sync.Mutex
+
+	// manifestStore is used by a read-only DB to refresh its view of the database, and by the
+	// writer to collect garbage. refreshMu guards lastRefresh, the time the view was last loaded.
+	// See config.DBOptions.MaxStaleness
+	manifestStore *store.ManifestStore
+	refreshMu     sync.Mutex
+	lastRefresh   time.Time
+
+	// features are the on-disk format features recorded in the manifest most recently
+	// loaded by a read-only DB, guarded by refreshMu. See DB.FormatInfo
+	features manifest.Features
+
+	// skipWAL is true if the WAL is not replayed, see config.ReaderOptions.TailWAL
+	skipWAL bool
+
+	// walEnd, if not zero, is the ID of the first WAL SST which is not replayed, such as the first
+	// WAL SST written after the checkpoint the DB was opened at, see openCheckpoint
+	walEnd uint64
+
+	// replica is true if the WAL SSTs are applied to the view of a read-only DB as they are written,
+	// see config.ReaderOptions.WALPollInterval
+	replica bool
+
+	// walRepaired is true if the WAL was replayed despite missing or corrupt WAL SSTs, see
+	// config.RecoveryModeRepair and config.RecoveryModeTruncate
+	walRepaired bool
+
+	// handedOff is true once DB.HandOff is called, after which writes fail with ErrHandedOff
+	handedOff atomic.Bool
+
+	// fenced is true once a newer writer has fenced this one, after which writes fail with ErrFenced.
+	// fencedCtx is cancelled once the writer is fenced, see checkFenced
+	fenced    atomic.Bool
+	fencedCtx context.Context
+	fence     context.CancelFunc
 
 	// walFlushNotifierCh - When DB.Close is called, we send a notification to this channel
 	// and the goroutine running the walFlush task reads this channel and shuts down
 	walFlushNotifierCh chan context.Context
 
+	// walFlushRequestCh - Sending to this channel causes the walFlush task to flush the WAL immediately.
+	// A DB opened with OpenInMemory requests a flush for every write which awaits durability.
+	walFlushRequestCh chan struct{}
+	inMemory          bool
+
 	// memtableFlushNotifierCh - When DB.Close is called, we send a Shutdown notification to this channel
 	// and the goroutine running the memtableFlush task reads this channel and shuts down
 	memtableFlushNotifierCh chan<- MemtableFlushThreadMsg
 
-	// walFlushTaskWG - When DB.Close is called, this is used to wait till the walFlush task goroutine is completed
-	walFlushTaskWG *sync.WaitGroup
+	// tasks supervises the walFlush and memtableFlush tasks. When DB.Close is called, walFlushTask and
+	// memtableFlushTask are used to wait till each task has completed
+	tasks             *task.Manager
+	walFlushTask      *task.Handle
+	memtableFlushTask *task.Handle
+
+	// featuresMu guards rangeTombstonesEnabled, which is true once manifest.FeatureRangeTombstones
+	// has been recorded in the manifest
+	featuresMu             sync.Mutex
+	rangeTombstonesEnabled bool
 
-	// memtableFlushTaskWG - When DB.Close is called, this is used to wait till the memtableFlush task goroutine is completed
-	memtableFlushTaskWG *sync.WaitGroup
+	// writeMu is held for reading while a write is added to the WAL, and for writing
+	// by ReplaceRange such that writes are blocked until the range is replaced
+	writeMu sync.RWMutex
+
+	// flushFailures tracks failing flushes, see config.DBOptions.MaxFlushFailureDuration
+	flushFailures flushFailures
+
+	// memtableFlushRequested is set by a write which awaits durability while the WAL is disabled,
+	// such that the memtable is flushed to L0 at the next FlushInterval. See config.DBOptions.WALEnabled
+	memtableFlushRequested atomic.Bool
+
+	// idle tracks whether the database is idle, see config.DBOptions.IdleTimeout
+	idle *idleTracker
 }
 
 func Open(ctx context.Context, path string, bucket objstore.Bucket) (*DB, error) {
 	return OpenWithOptions(ctx, path, bucket, config.DefaultDBOptions())
 }
 
+// OpenInMemory opens a new database backed by an in-memory bucket, such that nothing is persisted
+// once the database is closed. This is intended for unit tests and ephemeral caches. Writes which
+// await durability flush the WAL immediately instead of waiting for the next FlushInterval, and as
+// no other process can access the database, the manifest is never polled.
+func OpenInMemory(ctx context.Context, options config.DBOptions) (*DB, error) {
+	options.ManifestPollInterval = 0
+	db, err := OpenWithOptions(ctx, "in-memory", objstore.NewInMemBucket(), options)
+	if err != nil {
+		return nil, err
+	}
+	db.inMemory = true
+	return db, nil
+}
+
 func OpenWithOptions(ctx context.Context, path string, bucket objstore.Bucket, options config.DBOptions) (*DB, error) {
-	conf := sstable.DefaultConfig()
-	conf.BlockSize = BlockSize
-	conf.MinFilterKeys = options.MinFilterKeys
-	conf.Compression = options.CompressionCodec
-	set.Default(&options.Log, slog.Default())
+	return openWithOptions(ctx, path, bucket, options, false)
+}
 
-	tableStore := store.NewTableStore(bucket, conf, path)
-	manifestStore := store.NewManifestStore(path, bucket)
+// openWithOptions opens the database. If skipWAL is true, the database must be read-only and the WAL
+// is not replayed, such that only writes which have been flushed to L0 are visible.
+func openWithOptions(ctx context.Context, path string, bucket objstore.Bucket, options config.DBOptions,
+	skipWAL bool) (_ *DB, err error) {
+	metrics := newPromMetrics()
+	if err := metrics.register(options.MetricsRegisterer); err != nil {
+		return nil, fmt.Errorf("while registering metrics: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			metrics.unregister(options.MetricsRegisterer)
+		}
+	}()
+	bucket = instrumentBucket(bucket, options, metrics)
+
+	tableStore, manifestStore, err := newStores(path, bucket, &options)
+	if err != nil {
+		return nil, err
+	}
+	statsStore := store.NewStatsStore(path, bucket)
+	lockStore := store.NewLockStore(path, bucket)
+	if options.ReadOnly {
+		return openReadOnly(ctx, path, options, tableStore, manifestStore, statsStore, lockStore, metrics, skipWAL)
+	}
+	var handedOff uint64
+	if options.HandOffToken != "" {
+		if handedOff, err = parseHandOffToken(options.HandOffToken); err != nil {
+			return nil, err
+		}
+	}
 	manifest, err := getManifest(manifestStore)
 
 	if err != nil {
 		return nil, err
 	}
+	if options.HandOffToken != "" {
+		if err := checkHandOff(handedOff, manifest.Epoch()); err != nil {
+			return nil, err
+		}
+	}
+
+	// Record the on-disk features this writer may use before any data is written with them
+	if err := manifest.EnableFeatures(requiredFeatures(options)); err != nil {
+		return nil, fmt.Errorf("while enabling manifest features: %w", err)
+	}
 
 	memtableFlushNotifierCh := make(chan MemtableFlushThreadMsg, math.MaxUint8)
 	dbState, err := manifest.DbState()
 	if err != nil {
 		return nil, err
 	}
+	tableStore.SetExternalDBs(manifest.ExternalDBs())
+	tableStore.SetFilterSidecars(manifest.FilterSidecars())
+	tableStore.SetQuarantined(quarantinedIDs(manifest.Quarantined()))
 
-	db, err := newDB(ctx, options, tableStore, dbState.ToCoreState(), memtableFlushNotifierCh)
+	db, err := newDB(ctx, options, tableStore, statsStore, dbState.ToCoreState(), memtableFlushNotifierCh, false)
 	if err != nil {
 		return nil, fmt.Errorf("during db init: %w", err)
 	}
 	db.manifest = manifest
+	db.manifestStore = manifestStore
+	db.lockStore = lockStore
+	db.prom = metrics
+	tableStore.SetCorruptionHandler(db.quarantineSST)
+	db.initDurable()
+	if db.walRepaired {
+		if err := db.flushReplayedWAL(); err != nil {
+			return nil, fmt.Errorf("while flushing the repaired WAL: %w", err)
+		}
+	}
+	if options.LocalWALDir != "" {
+		if err := db.openLocalWAL(); err != nil {
+			return nil, fmt.Errorf("while opening the local WAL: %w", err)
+		}
+	}
 
 	db.walFlushNotifierCh = make(chan context.Context, math.MaxUint8)
 	// we start 2 background threads
 	// one thread for flushing WAL to object store and then to memtable. Flushing happens every FlushInterval Duration
-	db.spawnWALFlushTask(db.walFlushNotifierCh, db.walFlushTaskWG)
+	db.walFlushTask = db.spawnWALFlushTask(db.walFlushNotifierCh)
 	// another thread for
 	// 1. flushing Immutable memtables to L0. Flushing happens when memtable size reaches L0SSTSizeBytes
 	// 2. loading manifest from object store and update current DBState. This happens every ManifestPollInterval milliseconds
-	db.spawnMemtableFlushTask(manifest, memtableFlushNotifierCh, db.memtableFlushTaskWG)
+	db.memtableFlushTask = db.spawnMemtableFlushTask(manifest, memtableFlushNotifierCh)
 
 	var compactor *compaction.Compactor
 	if db.opts.CompactorOptions != nil {
@@ -121,11 +372,234 @@ func OpenWithOptions(ctx context.Context, path string, bucket objstore.Bucket, o
 		}
 	}
 	db.compactor = compactor
+	metrics.bind(db)
+	db.spawnMetricsTask()
+	db.spawnScrubTask()
+	db.spawnGCTask()
+	db.spawnQuarantineTask()
+	db.spawnCompactionAutoTuneTask()
+
+	return db, nil
+}
+
+// newStores validates `options`, sets the defaults of its Log, Clock and EventListener, and returns the stores of
+// the SSTs and manifests of the database at `path` which they configure
+func newStores(path string, bucket objstore.Bucket, options *config.DBOptions) (*store.TableStore,
+	*store.ManifestStore, error) {
+	conf := sstable.DefaultConfig()
+	conf.BlockSize = BlockSize
+	conf.MinFilterKeys = options.MinFilterKeys
+	conf.Compression = options.CompressionCodec
+	conf.AutoCompression = options.AutoCompression
+	conf.BlockRestartInterval = options.BlockRestartInterval
+	conf.CompressionLevel = options.CompressionLevel
+	levelCodecs := map[store.SSTLevel]mo.Option[compress.Codec]{
+		store.SSTLevelWAL:       options.WALCompressionCodec,
+		store.SSTLevelL0:        options.L0CompressionCodec,
+		store.SSTLevelCompacted: options.CompactedCompressionCodec,
+	}
+	overrides := slices.Collect(maps.Values(levelCodecs))
+	for name, ks := range options.Keyspaces {
+		if err := validateKeyspace(name, ks); err != nil {
+			return nil, nil, err
+		}
+		overrides = append(overrides, ks.CompressionCodec)
+	}
+	if err := validateCompressionLevel(options.CompressionCodec, overrides, options.CompressionLevel); err != nil {
+		return nil, nil, err
+	}
+	conf.FilterHash = options.FilterHash
+	conf.FilterSeed = options.FilterSeed
+	bitsPerKey, err := filterBitsPerKey(*options)
+	if err != nil {
+		return nil, nil, err
+	}
+	conf.FilterBitsPerKey = bitsPerKey
+	conf.PrefixExtractor = options.PrefixExtractor
+	set.Default(&options.Log, slog.Default())
+	if options.Clock == nil {
+		options.Clock = config.SystemClock{}
+	}
+	conf.Now = options.Clock.Now
+	if options.EventListener == nil {
+		options.EventListener = config.NoopEventListener{}
+	}
+
+	tableStore := store.NewTableStore(bucket, conf, path)
+	for level, codec := range levelCodecs {
+		if c, ok := codec.Get(); ok {
+			tableStore.SetLevelCompression(level, c)
+		}
+	}
+	for name, ks := range options.Keyspaces {
+		if c, ok := ks.CompressionCodec.Get(); ok {
+			tableStore.SetKeyspaceCompression(name, c)
+		}
+	}
+	tableStore.SetFilterPartitions(options.FilterPartitionBlocks)
+	tableStore.SetInlineValues(options.InlineValueBytes)
+	tableStore.SetWALEntryChecksums(options.WALEntryChecksums)
+	tableStore.SetWriteBufferSize(options.SSTWriteBufferSize)
+	tableStore.SetReadAhead(options.ReadAheadBytes)
+	tableStore.LimitFilterFetches(options.MaxConcurrentFilterFetches)
+	tableStore.SetBlockCache(options.BlockCacheSize, options.BlockCacheCompressed)
+	if err := tableStore.SetDiskCache(options.DiskCacheDir, options.DiskCacheSize); err != nil {
+		return nil, nil, fmt.Errorf("while opening disk cache: %w", err)
+	}
+	if p := options.HedgedReads.Percentile; p < 0 || p >= 1 {
+		return nil, nil, internal.ErrInvalidArgument("hedged read percentile %v must be between 0 and 1", p)
+	}
+	if options.LocalWALDir != "" && !options.WALEnabled.OrElse(true) {
+		return nil, nil, internal.ErrInvalidArgument("LocalWALDir cannot be set while the WAL is disabled")
+	}
+	if options.CompactorOptions != nil {
+		if err := validateAutoTune(options.CompactorOptions.AutoTune); err != nil {
+			return nil, nil, err
+		}
+	}
+	tableStore.SetHedgedReads(options.HedgedReads.Percentile, options.HedgedReads.MinDelay,
+		options.HedgedReads.MaxInFlight)
+	manifestStore := store.NewManifestStore(path, bucket)
+	if options.ManifestCompression < compress.CodecNone || options.ManifestCompression > compress.CodecZstd {
+		return nil, nil, internal.ErrInvalidArgument("invalid ManifestCompression codec %d", options.ManifestCompression)
+	}
+	manifestStore.SetCompression(options.ManifestCompression)
+	if listener := options.EventListener; listener != (config.NoopEventListener{}) {
+		manifestStore.OnWrite(func(id uint64, m *manifest.Manifest) {
+			core := m.Core.Snapshot()
+			listener.OnManifestUpdated(config.ManifestUpdatedEvent{
+				ManifestID:     id,
+				WriterEpoch:    m.WriterEpoch.Load(),
+				CompactorEpoch: m.CompactorEpoch.Load(),
+				L0SSTs:         len(core.L0),
+				SortedRuns:     len(core.Compacted),
+			})
+		})
+	}
+	return tableStore, manifestStore, nil
+}
+
+// instrumentBucket wraps `bucket` such that its requests are recorded by `metrics` if
+// config.DBOptions.MetricsRegisterer is set, and traced if config.DBOptions.TracerProvider is set
+func instrumentBucket(bucket objstore.Bucket, options config.DBOptions, metrics *promMetrics) objstore.Bucket {
+	if options.MetricsRegisterer != nil {
+		bucket = metrics.instrumentBucket(bucket)
+	}
+	if options.TracerProvider != nil {
+		bucket = tracing.NewBucket(bucket, tracing.Tracer(options.TracerProvider))
+	}
+	return bucket
+}
 
+// openReadOnly opens the database without fencing the writer or starting any background tasks
+func openReadOnly(
+	ctx context.Context,
+	path string,
+	options config.DBOptions,
+	tableStore *store.TableStore,
+	manifestStore *store.ManifestStore,
+	statsStore *store.StatsStore,
+	lockStore *store.LockStore,
+	metrics *promMetrics,
+	skipWAL bool,
+) (*DB, error) {
+	stored, err := store.LoadStoredManifest(manifestStore)
+	if err != nil {
+		return nil, err
+	}
+	sm, ok := stored.Get()
+	if !ok {
+		return nil, internal.Err("no database found at path '%s'", path)
+	}
+	tableStore.SetExternalDBs(sm.ExternalDBs())
+	tableStore.SetFilterSidecars(sm.FilterSidecars())
+	tableStore.SetQuarantined(quarantinedIDs(sm.Quarantined()))
+
+	memtableFlushNotifierCh := make(chan MemtableFlushThreadMsg, math.MaxUint8)
+	db, err := newDB(ctx, options, tableStore, statsStore, sm.DbState().ToCoreState(), memtableFlushNotifierCh, skipWAL)
+	if err != nil {
+		return nil, fmt.Errorf("during db init: %w", err)
+	}
+	db.manifestStore = manifestStore
+	db.lockStore = lockStore
+	db.lastRefresh = db.opts.Clock.Now()
+	db.features = sm.Features()
+	db.prom = metrics
+	tableStore.SetCorruptionHandler(db.quarantineSST)
+	metrics.bind(db)
+	db.spawnMetricsTask()
+	db.spawnLeaseTask()
 	return db, nil
 }
 
+// maybeRefresh reloads the manifest and WAL of a read-only DB, or applies the new WAL SSTs to a
+// replica, if they were last loaded longer than DBOptions.MaxStaleness ago
+func (db *DB) maybeRefresh(ctx context.Context) error {
+	if !db.opts.ReadOnly || db.opts.MaxStaleness <= 0 {
+		return nil
+	}
+
+	db.refreshMu.Lock()
+	defer db.refreshMu.Unlock()
+	if db.opts.Clock.Now().Sub(db.lastRefresh) < db.opts.MaxStaleness {
+		return nil
+	}
+	if db.replica {
+		return db.applyWAL(ctx)
+	}
+	return db.refresh(ctx)
+}
+
+// applyWAL applies the WAL SSTs written since the view of a replica was last refreshed to its
+// memtables, without reloading the manifest. If the WAL SSTs which follow the view have been
+// deleted, once they were flushed to L0, the view is reloaded from the manifest instead.
+// refreshMu must be held by the caller.
+func (db *DB) applyWAL(ctx context.Context) error {
+	err := db.replayWALAfter(ctx, db.state, db.state.NextWALID()-1)
+	if errors.Is(err, ErrInconsistentWAL) {
+		return db.refresh(ctx)
+	}
+	if err != nil {
+		return err
+	}
+	db.lastRefresh = db.opts.Clock.Now()
+	return nil
+}
+
+// refresh reloads the manifest and WAL of a read-only DB. refreshMu must be held by the caller.
+func (db *DB) refresh(ctx context.Context) error {
+	stored, err := store.LoadStoredManifest(db.manifestStore)
+	if err != nil {
+		return err
+	}
+	return db.refreshFrom(ctx, stored)
+}
+
+// refreshFrom reloads the view of a read-only DB from the manifest `stored`, and replays the WAL.
+// refreshMu must be held by the caller.
+func (db *DB) refreshFrom(ctx context.Context, stored mo.Option[*store.StoredManifest]) error {
+	sm, ok := stored.Get()
+	if !ok {
+		return internal.Err("manifest no longer exists")
+	}
+
+	// The WAL is replayed into a new state so reads continue to be served
+	// from the current view until the refreshed view is complete
+	dbState := state.NewDBState(sm.DbState().ToCoreState())
+	if err := db.replayWAL(ctx, dbState); err != nil {
+		return err
+	}
+	db.state.ReplaceWith(dbState)
+	db.lastRefresh = db.opts.Clock.Now()
+	db.features = sm.Features()
+	return nil
+}
+
 func (db *DB) Close(ctx context.Context) error {
+	defer db.prom.unregister(db.opts.MetricsRegisterer)
+	if db.opts.ReadOnly {
+		return db.tasks.Stop(ctx)
+	}
 	var errs []error
 
 	if db.compactor != nil {
@@ -136,30 +610,25 @@ func (db *DB) Close(ctx context.Context) error {
 
 	// notify flush task goroutine to shutdown and wait for it to shutdown cleanly
 	db.walFlushNotifierCh <- ctx
-	done := make(chan struct{})
-	go func() {
-		db.walFlushTaskWG.Wait()
-		close(done)
-	}()
-	select {
-	case <-done:
-	case <-ctx.Done():
-		errs = append(errs, ctx.Err())
+	if err := db.walFlushTask.Wait(ctx); err != nil {
+		errs = append(errs, err)
+	}
+
+	if db.localWAL != nil {
+		if err := db.localWAL.Close(); err != nil {
+			errs = append(errs, err)
+		}
 	}
 
 	// notify memTable flush task goroutine to shutdown and wait for it to shutdown cleanly
 	db.memtableFlushNotifierCh <- Shutdown
-	done = make(chan struct{})
-	go func() {
-		db.memtableFlushTaskWG.Wait()
-		close(done)
-	}()
-	select {
-	case <-done:
-	case <-ctx.Done():
-		errs = append(errs, ctx.Err())
+	if err := db.memtableFlushTask.Wait(ctx); err != nil {
+		errs = append(errs, err)
 	}
 
+	if err := db.tasks.Stop(ctx); err != nil {
+		errs = append(errs, err)
+	}
 	return errors.Join(errs...)
 }
 
@@ -168,22 +637,65 @@ func (db *DB) Put(ctx context.Context, key []byte, value []byte) error {
 }
 
 func (db *DB) PutWithOptions(ctx context.Context, key []byte, value []byte, options config.WriteOptions) error {
-	if len(key) == 0 {
-		return internal.ErrInvalidArgument("argument 'key' cannot be empty or nil")
-	}
-
-	currentWAL := db.state.WalPut(types.RowEntry{
+	return db.putEntry(ctx, types.RowEntry{
 		Value: types.Value{
 			Kind:  types.KindKeyValue,
 			Value: value,
 		},
 		Key: key,
-	})
+	}, options)
+}
+
+// PutWithTTL writes the key-value pair such that it expires once `ttl` has elapsed
+// according to DBOptions.Clock. Get and Scan treat an expired key as not found, and
+// compaction eventually removes the expired value from storage.
+//
+// The expiry is stored with millisecond precision.
+func (db *DB) PutWithTTL(ctx context.Context, key []byte, value []byte, ttl time.Duration) error {
+	if ttl <= 0 {
+		return internal.ErrInvalidArgument("argument 'ttl' must be greater than zero")
+	}
+	return db.putEntry(ctx, types.RowEntry{
+		Value: types.Value{
+			Kind:  types.KindKeyValue,
+			Value: value,
+		},
+		Key:      key,
+		ExpireAt: db.opts.Clock.Now().Add(ttl).Truncate(time.Millisecond),
+	}, config.DefaultWriteOptions())
+}
+
+func (db *DB) putEntry(ctx context.Context, entry types.RowEntry, options config.WriteOptions) (err error) {
+	if len(entry.Key) == 0 {
+		return internal.ErrInvalidArgument("argument 'key' cannot be empty or nil")
+	}
+	if db.opts.ReadOnly {
+		return ErrReadOnly
+	}
+	if err := db.writeErr(); err != nil {
+		return err
+	}
+	if db.opts.ValueChecksums {
+		entry.Checksum = mo.Some(types.ValueChecksum(entry.Value.Value))
+	}
+	defer db.recordWrite(db.opts.Clock.Now())
+	db.markActive()
+	ctx, span := db.tracer.Start(ctx, "slatedb.Put",
+		trace.WithAttributes(tracing.KeyBytes.Int(len(entry.Key)+len(entry.Value.Value))))
+	defer func() { tracing.End(span, err) }()
+
+	db.stats.bytesIngested.Add(uint64(len(entry.Key) + len(entry.Value.Value)))
+	if !db.walEnabled() {
+		return db.writeMemtable(ctx, options, func() *table.Memtable {
+			return db.state.MemtableWrite([]types.RowEntry{entry})
+		})
+	}
+	db.writeMu.RLock()
+	currentWAL := db.state.WalPut(entry)
+	db.writeMu.RUnlock()
+	db.maybeRollWAL(currentWAL)
 	if options.AwaitDurable {
-		// we wait for WAL to be flushed to memtable and then we send a notification
-		// to goroutine to flush memtable to L0. we do not wait till its flushed to L0
-		// because client can read the key from memtable
-		return currentWAL.Table().AwaitWALFlush(ctx)
+		return db.awaitWALDurable(ctx, currentWAL)
 	}
 	return nil
 }
@@ -198,72 +710,205 @@ func (db *DB) Get(ctx context.Context, key []byte) ([]byte, error) {
 //
 // if readlevel is Committed we start searching key in the following order
 // mutable memtable, immutable memtables, SSTs in L0, compacted Sorted runs
-func (db *DB) GetWithOptions(ctx context.Context, key []byte, options config.ReadOptions) ([]byte, error) {
-	snapshot := db.state.Snapshot()
+func (db *DB) GetWithOptions(ctx context.Context, key []byte, options config.ReadOptions) (_ []byte, err error) {
+	defer db.recordGet(db.opts.Clock.Now())
+	ctx, span := db.tracer.Start(ctx, "slatedb.Get")
+	defer func() { endReadSpan(span, err) }()
+	if err := db.maybeRefresh(ctx); err != nil {
+		if !options.AllowStale || ctx.Err() == nil {
+			return nil, fmt.Errorf("while refreshing read-only view: %w", err)
+		}
+		// The view which was last loaded is read
+		value, err := db.getFromState(ctx, db.state.Snapshot(), key, options)
+		if errors.Is(err, ErrStaleRead) {
+			return value, err
+		}
+		stale := fmt.Errorf("%w: the read-only view was not refreshed before ctx was done", ErrStaleRead)
+		if err != nil {
+			return value, fmt.Errorf("%w: %w", stale, err)
+		}
+		return value, stale
+	}
+	return db.getFromState(ctx, db.state.Snapshot(), key, options)
+}
+
+// getFromState searches for the key in the provided state, see GetWithOptions
+func (db *DB) getFromState(ctx context.Context, snapshot *state.DBStateSnapshot, key []byte,
+	options config.ReadOptions) ([]byte, error) {
+	now := db.opts.Clock.Now()
+	// The most recent entry of the key is not found if it was deleted by a range tombstone
+	tombstones := snapshot.RangeTombstones(key, keySuccessor(key), options.ReadLevel == config.Uncommitted)
+	check := func(entry types.RowEntry) ([]byte, error) {
+		if types.IsCovered(tombstones, entry) {
+			return nil, ErrKeyNotFound
+		}
+		if db.opts.ValueChecksums && !entry.ChecksumMatches() {
+			return nil, fmt.Errorf("%w for key '%s'", ErrChecksumMismatch, key)
+		}
+		return checkEntry(entry, now)
+	}
 
 	if options.ReadLevel == config.Uncommitted {
 		// search for key in mutable WAL
-		val, ok := snapshot.Wal.Get(key).Get()
+		entry, ok := snapshot.Wal.GetEntry(key).Get()
 		if ok { // key is present or tombstoned
-			return checkValue(val)
+			return check(entry)
 		}
 		// search for key in ImmutableWALs
 		immWALList := snapshot.ImmWALs
 		for i := 0; i < immWALList.Len(); i++ {
 			immWAL := immWALList.At(i)
-			val, ok := immWAL.Get(key).Get()
+			entry, ok := immWAL.GetEntry(key).Get()
 			if ok { // key is present or tombstoned
-				return checkValue(val)
+				return check(entry)
 			}
 		}
 	}
 
 	// search for key in mutable memtable
-	val, ok := snapshot.Memtable.Get(key).Get()
+	entry, ok := snapshot.Memtable.GetEntry(key).Get()
 	if ok { // key is present or tombstoned
-		return checkValue(val)
+		return check(entry)
 	}
 	// search for key in Immutable memtables
 	immMemtables := snapshot.ImmMemtables
 	for i := 0; i < immMemtables.Len(); i++ {
 		immTable := immMemtables.At(i)
-		val, ok := immTable.Get(key).Get()
+		entry, ok := immTable.GetEntry(key).Get()
 		if ok {
-			return checkValue(val)
+			return check(entry)
 		}
 	}
 
+	reads := db.newPointReads(ctx, key, options)
+
 	// search for key in SSTs in L0
 	for _, sst := range snapshot.Core.L0 {
-		if db.sstMayIncludeKey(ctx, sst, key) {
-			iter, err := sstable.NewIteratorAtKey(ctx, &sst, key, db.tableStore.Clone())
+		if reads.sstMayIncludeKey(sst, key) {
+			db.stats.sstProbes.Add(1)
+			db.sstAccess.record(sst.Id, db.opts.Clock.Now())
+			traceProbe(ctx, sst.Id)
+			kv, ok, err := reads.read(func(ctx context.Context, ts *store.TableStore) (iter.KVIterator, error) {
+				return sstable.NewPointIterator(ctx, &sst, key, ts)
+			})
 			if err != nil {
 				return nil, err
 			}
-
-			kv, ok := iter.NextEntry(ctx)
-			if ok && bytes.Equal(kv.Key, key) {
-				return checkValue(kv.Value)
+			if ok {
+				return reads.result(check(kv))
 			}
 		}
 	}
 
 	// search for key in compacted Sorted runs
 	for _, sr := range snapshot.Core.Compacted {
-		if db.srMayIncludeKey(ctx, sr, key) {
-			iter, err := compacted.NewSortedRunIteratorFromKey(ctx, sr, key, db.tableStore.Clone())
+		if reads.srMayIncludeKey(sr, key) {
+			db.stats.sstProbes.Add(1)
+			sst, found := sr.SstWithKey(key).Get()
+			if !found {
+				// Every key of the sorted run is greater than the key
+				continue
+			}
+			db.sstAccess.record(sst.Id, db.opts.Clock.Now())
+			traceProbe(ctx, sst.Id)
+			// Only the SST whose range includes the key may hold it
+			kv, ok, err := reads.read(func(ctx context.Context, ts *store.TableStore) (iter.KVIterator, error) {
+				return sstable.NewPointIterator(ctx, &sst, key, ts)
+			})
 			if err != nil {
 				return nil, err
 			}
-
-			kv, ok := iter.NextEntry(ctx)
-			if ok && bytes.Equal(kv.Key, key) {
-				return checkValue(kv.Value)
+			if ok {
+				return reads.result(check(kv))
 			}
 		}
 	}
 
-	return nil, ErrKeyNotFound
+	return reads.result(nil, ErrKeyNotFound)
+}
+
+// ------------------------------------------------
+// pointReads
+// ------------------------------------------------
+
+// pointReads reads the SSTs probed by a Get. If config.ReadOptions.AllowStale is set and ctx is
+// done before the SSTs have been read, the remaining reads are served from the caches and the
+// SSTs which are not cached are skipped. See store.TableStore.CachedOnly
+type pointReads struct {
+	db         *DB
+	ctx        context.Context
+	key        []byte
+	store      *store.TableStore
+	allowStale bool
+
+	// stale is true once reads are served from the caches, and skipped is the number of
+	// SSTs which may hold the key but could not be read
+	stale   bool
+	skipped int
+}
+
+func (db *DB) newPointReads(ctx context.Context, key []byte, options config.ReadOptions) *pointReads {
+	return &pointReads{
+		db:         db,
+		ctx:        ctx,
+		key:        key,
+		store:      db.tableStore.PointReads(),
+		allowStale: options.AllowStale,
+	}
+}
+
+// read returns the entry of the key read by the iterator returned by `open`, if the key is
+// present. If AllowStale is set and the read fails once ctx is done, it is retried from the
+// caches, and the SST is skipped if it is not cached.
+func (r *pointReads) read(open func(context.Context, *store.TableStore) (iter.KVIterator, error)) (types.RowEntry, bool, error) {
+	kv, ok, err := r.readOnce(open)
+	if err == nil || !r.allowStale {
+		return kv, ok, err
+	}
+	if !r.stale {
+		if r.ctx.Err() == nil {
+			return kv, ok, err
+		}
+		r.stale = true
+		r.ctx = context.WithoutCancel(r.ctx)
+		r.store = r.db.tableStore.CachedOnly()
+		if kv, ok, err = r.readOnce(open); err == nil {
+			return kv, ok, nil
+		}
+	}
+	r.skipped++
+	return types.RowEntry{}, false, nil
+}
+
+func (r *pointReads) readOnce(open func(context.Context, *store.TableStore) (iter.KVIterator, error)) (types.RowEntry, bool, error) {
+	it, err := open(r.ctx, r.store)
+	if err != nil {
+		return types.RowEntry{}, false, err
+	}
+	kv, ok := it.NextEntry(r.ctx)
+	if ok && bytes.Equal(kv.Key, r.key) {
+		return kv, true, nil
+	}
+	// A block which could not be read is only reported if AllowStale is set, such that it is
+	// retried from the caches
+	if !ok && r.allowStale && !it.Warnings().Empty() {
+		return types.RowEntry{}, false, it.Warnings()
+	}
+	return types.RowEntry{}, false, nil
+}
+
+// result returns the value and error of a Get, which wrap ErrStaleRead if an SST which may
+// hold the key was skipped
+func (r *pointReads) result(value []byte, err error) ([]byte, error) {
+	if r.skipped == 0 {
+		return value, err
+	}
+	stale := fmt.Errorf("%w: %d SSTs which may hold key '%s' were not read before ctx was done",
+		ErrStaleRead, r.skipped, r.key)
+	if err != nil {
+		return value, fmt.Errorf("%w: %w", stale, err)
+	}
+	return value, stale
 }
 
 func (db *DB) Delete(ctx context.Context, key []byte) error {
@@ -274,38 +919,120 @@ func (db *DB) DeleteWithOptions(ctx context.Context, key []byte, options config.
 	if len(key) == 0 {
 		return internal.ErrInvalidArgument("argument 'key' cannot be empty or nil")
 	}
+	if db.opts.ReadOnly {
+		return ErrReadOnly
+	}
+	if err := db.writeErr(); err != nil {
+		return err
+	}
 
-	currentWAL := db.state.WalPut(types.RowEntry{
+	defer db.recordWrite(db.opts.Clock.Now())
+	db.markActive()
+	db.stats.bytesIngested.Add(uint64(len(key)))
+	tombstone := types.RowEntry{
 		Value: types.Value{
 			Kind: types.KindTombStone,
 		},
 		Key: key,
-	})
+	}
+	if !db.walEnabled() {
+		return db.writeMemtable(ctx, options, func() *table.Memtable {
+			return db.state.MemtableWrite([]types.RowEntry{tombstone})
+		})
+	}
+	db.writeMu.RLock()
+	currentWAL := db.state.WalPut(tombstone)
+	db.writeMu.RUnlock()
+	db.maybeRollWAL(currentWAL)
+	if options.AwaitDurable {
+		return db.awaitWALDurable(ctx, currentWAL)
+	}
+	return nil
+}
+
+func (db *DB) DeleteRange(ctx context.Context, start []byte, end []byte) error {
+	return db.DeleteRangeWithOptions(ctx, start, end, config.DefaultWriteOptions())
+}
+
+// DeleteRangeWithOptions deletes every key in the range [start, end) by writing a single range
+// tombstone, such that the cost of the deletion does not depend on the number of keys in the range.
+// Keys written to the range after the range tombstone are not deleted. Get and Scan skip the
+// deleted keys, and compaction eventually removes them from storage.
+func (db *DB) DeleteRangeWithOptions(ctx context.Context, start []byte, end []byte, options config.WriteOptions) error {
+	if len(start) == 0 || len(end) == 0 {
+		return internal.ErrInvalidArgument("arguments 'start' and 'end' cannot be empty or nil")
+	}
+	if bytes.Compare(start, end) >= 0 {
+		return internal.ErrInvalidArgument("argument 'start' must be less than 'end'")
+	}
+	if db.opts.ReadOnly {
+		return ErrReadOnly
+	}
+	if err := db.writeErr(); err != nil {
+		return err
+	}
+	if err := db.enableRangeTombstones(); err != nil {
+		return err
+	}
+
+	defer db.recordWrite(db.opts.Clock.Now())
+	db.markActive()
+	db.stats.bytesIngested.Add(uint64(len(start) + len(end)))
+	if !db.walEnabled() {
+		return db.writeMemtable(ctx, options, func() *table.Memtable {
+			return db.state.MemtableWriteDeleteRange(bytes.Clone(start), bytes.Clone(end))
+		})
+	}
+	db.writeMu.RLock()
+	currentWAL := db.state.WalDeleteRange(bytes.Clone(start), bytes.Clone(end))
+	db.writeMu.RUnlock()
+	db.maybeRollWAL(currentWAL)
 	if options.AwaitDurable {
-		return currentWAL.Table().AwaitWALFlush(ctx)
+		return db.awaitWALDurable(ctx, currentWAL)
 	}
 	return nil
 }
 
-func (db *DB) sstMayIncludeKey(ctx context.Context, sst sstable.Handle, key []byte) bool {
+// enableRangeTombstones records manifest.FeatureRangeTombstones in the manifest before the first range
+// tombstone is written, such that binaries which do not support range tombstones refuse to open the database
+func (db *DB) enableRangeTombstones() error {
+	db.featuresMu.Lock()
+	defer db.featuresMu.Unlock()
+	if db.rangeTombstonesEnabled {
+		return nil
+	}
+	if err := db.manifest.EnableFeatures(manifest.FeatureRangeTombstones); err != nil {
+		return fmt.Errorf("while enabling manifest features: %w", err)
+	}
+	db.rangeTombstonesEnabled = true
+	return nil
+}
+
+func (r *pointReads) sstMayIncludeKey(sst sstable.Handle, key []byte) bool {
 	if !sst.RangeCoversKey(key) {
 		return false
 	}
-	filter, err := db.tableStore.ReadFilter(ctx, &sst)
-	if err == nil && filter.IsPresent() {
-		bFilter, _ := filter.Get()
-		return bFilter.HasKey(key)
-	}
-	return true
+	return r.filterMayIncludeKey(sst, key)
 }
 
-func (db *DB) srMayIncludeKey(ctx context.Context, sr compacted.SortedRun, key []byte) bool {
+func (r *pointReads) srMayIncludeKey(sr compacted.SortedRun, key []byte) bool {
 	sstOption := sr.SstWithKey(key)
 	if sstOption.IsAbsent() {
 		return false
 	}
 	sst, _ := sstOption.Get()
-	filter, err := db.tableStore.ReadFilter(ctx, &sst)
+	return r.filterMayIncludeKey(sst, key)
+}
+
+// filterMayIncludeKey returns false if the filter of the SST excludes the key. If the filter
+// could not be read, or was not fetched because DBOptions.MaxConcurrentFilterFetches was
+// reached, the key may be included and the SST must be probed.
+func (r *pointReads) filterMayIncludeKey(sst sstable.Handle, key []byte) bool {
+	ts := r.db.tableStore
+	if r.stale {
+		ts = r.store
+	}
+	filter, _, err := ts.TryReadFilterForKey(r.ctx, &sst, key)
 	if err == nil && filter.IsPresent() {
 		bFilter, _ := filter.Get()
 		return bFilter.HasKey(key)
@@ -315,49 +1042,169 @@ func (db *DB) srMayIncludeKey(ctx context.Context, sr compacted.SortedRun, key [
 
 // this is to recover from a crash. we read the WALs from object store (considered to be Uncommmitted)
 // and write the kv pairs to memtable
-func (db *DB) replayWAL(ctx context.Context) error {
-	walIDLastCompacted := db.state.LastCompactedWALID()
+func (db *DB) replayWAL(ctx context.Context, dbState *state.DBState) error {
+	if db.skipWAL {
+		return nil
+	}
+	return db.replayWALAfter(ctx, dbState, dbState.LastCompactedWALID())
+}
+
+// replayWALAfter replays the WAL SSTs which follow the WAL SST `walIDLastCompacted` into `dbState`,
+// see replayWAL
+func (db *DB) replayWALAfter(ctx context.Context, dbState *state.DBState, walIDLastCompacted uint64) error {
 	walSSTList, err := db.tableStore.GetWalSSTList(walIDLastCompacted)
 	if err != nil {
 		return err
 	}
+	if db.walEnd > 0 {
+		walSSTList = slices.DeleteFunc(walSSTList, func(id uint64) bool { return id >= db.walEnd })
+	}
+	if err := checkWALSSTs(walIDLastCompacted, walSSTList); err != nil {
+		if db.opts.RecoveryMode != config.RecoveryModeRepair {
+			return err
+		}
+		db.opts.Log.Warn("replaying the WAL SSTs which are present", "error", err)
+		db.walRepaired = true
+	}
 
 	lastSSTID := walIDLastCompacted
+	truncated := false
 	for _, sstID := range walSSTList {
 		lastSSTID = sstID
+		// The WAL SSTs which follow a corrupt entry are discarded
+		if truncated {
+			continue
+		}
 		sst, err := db.tableStore.OpenSST(ctx, sstable.NewIDWal(sstID))
 		if err != nil {
 			return err
 		}
 		assert.True(sst.Id.WalID().IsPresent(), "Invalid WAL ID")
 
-		// iterate through kv pairs in sst and populate walReplayBuf
-		iter, err := sstable.NewIterator(ctx, sst, db.tableStore.Clone())
+		walReplayBuf, err := db.readWALSST(ctx, sst)
 		if err != nil {
-			return err
+			if !errors.Is(err, ErrCorruptWAL) || (db.opts.RecoveryMode != config.RecoveryModeTruncate &&
+				db.opts.RecoveryMode != config.RecoveryModeRepair) {
+				return err
+			}
+			db.opts.Log.Warn("truncating the WAL at the first corrupt entry", "wal_sst_id", sstID,
+				"entries_replayed", len(walReplayBuf), "error", err)
+			db.walRepaired = true
+			truncated = true
+			db.quarantineSST(sst.Id, err)
+		}
+
+		// update memtable with kv pairs in walReplayBuf
+		var lastSeq uint64
+		for _, entry := range walReplayBuf {
+			dbState.MemTablePut(entry)
+			lastSeq = max(lastSeq, entry.Seq)
+		}
+		for _, t := range sst.Info.RangeTombstones {
+			// The range tombstones of a truncated WAL SST which were written after the corrupt
+			// entry are discarded along with the entries which follow it
+			if truncated && t.Seq > lastSeq {
+				continue
+			}
+			dbState.MemTableDeleteRange(t)
+		}
+
+		db.maybeFreezeMemtable(dbState, sstID)
+		if dbState.NextWALID() == sstID {
+			dbState.IncrementNextWALID()
+		}
+	}
+
+	// The manifest may include WAL IDs which were allocated but never written, such as when a
+	// writer stopped while flushing the WAL. These IDs are reused by the next WAL SSTs.
+	dbState.SetNextWALID(lastSSTID + 1)
+	return nil
+}
+
+// readWALSST returns the entries of the WAL SST. If an entry cannot be decoded, such as an entry
+// whose checksum does not match, it returns the entries which precede it along with ErrCorruptWAL.
+// See config.DBOptions.WALEntryChecksums
+func (db *DB) readWALSST(ctx context.Context, sst *sstable.Handle) ([]types.RowEntry, error) {
+	index, err := db.tableStore.ReadIndex(ctx, sst)
+	if err != nil {
+		return nil, err
+	}
+	walID := sst.Id.WalID().MustGet()
+	numBlocks := uint64(index.BlockMetaLength())
+	// A WAL SST which holds only range tombstones has no blocks
+	if numBlocks == 0 {
+		return nil, nil
+	}
+	blocks, err := db.tableStore.ReadBlocksUsingIndex(ctx, sst, common.Range{End: numBlocks}, index)
+
+	// The blocks which precede a corrupt block are replayed
+	var corruption error
+	var blockErr *internal.ExportedBlockCorruption
+	if errors.As(err, &blockErr) {
+		corruption = fmt.Errorf("%w: block %d of WAL SST %d: %w", ErrCorruptWAL, blockErr.Block, walID, err)
+		blocks, err = nil, nil
+		if blockErr.Block > 0 {
+			blocks, err = db.tableStore.ReadBlocksUsingIndex(ctx, sst, common.Range{End: blockErr.Block}, index)
 		}
+	}
+	if err != nil {
+		return nil, err
+	}
 
-		walReplayBuf := make([]types.RowEntry, 0)
+	var entries []types.RowEntry
+	for i := range blocks {
+		it := block.NewIterator(&blocks[i])
 		for {
-			kvDel, ok := iter.NextEntry(ctx)
+			entry, ok := it.NextEntry(ctx)
+			// An iterator which cannot decode an entry skips it, so iteration stops at the first warning
+			if warn := it.Warnings().If(); warn != nil {
+				return entries, fmt.Errorf("%w: block %d of WAL SST %d: %w", ErrCorruptWAL, i, walID, warn)
+			}
 			if !ok {
 				break
 			}
-			walReplayBuf = append(walReplayBuf, kvDel)
-		}
-
-		// update memtable with kv pairs in walReplayBuf
-		for _, entry := range walReplayBuf {
-			db.state.MemTablePut(entry)
+			entries = append(entries, entry)
 		}
+	}
+	return entries, corruption
+}
 
-		db.maybeFreezeMemtable(db.state, sstID)
-		if db.state.NextWALID() == sstID {
-			db.state.IncrementNextWALID()
+// checkWALSSTs returns ErrInconsistentWAL if the IDs of the WAL SSTs which follow the last WAL SST
+// compacted into L0 are not contiguous. The WAL is flushed in order, so a missing WAL SST which
+// precedes a present WAL SST was written and has since been lost.
+func checkWALSSTs(walIDLastCompacted uint64, walSSTList []uint64) error {
+	expected := walIDLastCompacted + 1
+	for _, id := range walSSTList {
+		if id != expected {
+			return fmt.Errorf("%w: WAL SSTs [%d, %d) are missing, while WAL SST %d is present",
+				ErrInconsistentWAL, expected, id, id)
 		}
+		expected++
 	}
+	return nil
+}
 
-	assert.True(lastSSTID+1 == db.state.NextWALID(), "")
+// flushReplayedWAL flushes the memtables replayed from the WAL to L0, such that the replayed
+// WAL SSTs, and any WAL SSTs missing between them or discarded by truncation, are not replayed
+// by the next Open
+func (db *DB) flushReplayedWAL() error {
+	// replayWAL sets the next WAL ID to follow the last WAL SST replayed
+	if db.state.Memtable().Size() > 0 {
+		db.state.FreezeMemtable(db.state.NextWALID() - 1)
+	}
+	flusher := MemtableFlusher{
+		db:       db,
+		manifest: db.manifest,
+		log:      db.opts.Log,
+	}
+	if err := flusher.flushImmMemtablesToL0(); err != nil {
+		return err
+	}
+	// The WAL SSTs which follow the last memtable flushed held no entries to replay
+	if last := db.state.NextWALID() - 1; db.state.LastCompactedWALID() < last {
+		db.state.SetLastCompactedWALID(last)
+		return flusher.writeManifestSafely(db.opts.Log)
+	}
 	return nil
 }
 
@@ -366,19 +1213,25 @@ func (db *DB) maybeFreezeMemtable(dbState *state.DBState, walID uint64) {
 		return
 	}
 	dbState.FreezeMemtable(walID)
-	db.memtableFlushNotifierCh <- FlushImmutableMemtables
+	if !db.opts.ReadOnly {
+		db.memtableFlushNotifierCh <- FlushImmutableMemtables
+	}
 }
 
 // FlushMemtableToL0 - Normally Memtable is flushed to Level0 of object store when it reaches a size of DBOptions.L0SSTSizeBytes
 // This method allows the user to flush Memtable to Level0 irrespective of Memtable size.
 func (db *DB) FlushMemtableToL0() error {
+	if db.opts.ReadOnly {
+		return ErrReadOnly
+	}
+	if db.fenced.Load() {
+		return ErrFenced
+	}
 	lastWalID := db.state.Memtable().LastWalID()
-	if lastWalID.IsAbsent() {
+	if lastWalID.IsAbsent() && db.walEnabled() {
 		return internal.Err("assertion failed; WAL is not yet flushed to Memtable")
 	}
-
-	walID, _ := lastWalID.Get()
-	db.state.FreezeMemtable(walID)
+	db.state.FreezeMemtable(db.memtableWALID())
 
 	flusher := MemtableFlusher{
 		db:       db,
@@ -394,11 +1247,8 @@ func getManifest(manifestStore *store.ManifestStore) (*store.FenceableManifest,
 		return nil, err
 	}
 
-	var storedManifest *store.StoredManifest
-	sm, ok := stored.Get()
-	if ok {
-		storedManifest = &sm
-	} else {
+	storedManifest, ok := stored.Get()
+	if !ok {
 		storedManifest, err = store.NewStoredManifest(manifestStore, state.NewCoreDBState())
 		if err != nil {
 			return nil, err
@@ -408,12 +1258,79 @@ func getManifest(manifestStore *store.ManifestStore) (*store.FenceableManifest,
 	return store.NewWriterFenceableManifest(storedManifest)
 }
 
+// validateCompressionLevel returns an error if `level` is not supported by each of the codecs
+// used to compress SSTs which supports levels, or if no such codec is used and `level` is not zero
+func validateCompressionLevel(codec compress.Codec, overrides []mo.Option[compress.Codec], level int) error {
+	codecs := []compress.Codec{codec}
+	for _, c := range overrides {
+		if c, ok := c.Get(); ok {
+			codecs = append(codecs, c)
+		}
+	}
+	supported := false
+	for _, c := range codecs {
+		if !compress.ValidLevel(c, 1) {
+			// The codec does not support levels, and ignores the level
+			continue
+		}
+		if !compress.ValidLevel(c, level) {
+			return internal.ErrInvalidArgument("compression level %d is not supported by codec %s", level, c)
+		}
+		supported = true
+	}
+	if level != 0 && !supported {
+		return internal.ErrInvalidArgument("compression level %d is not supported by codec %s", level, codec)
+	}
+	return nil
+}
+
+// filterBitsPerKey returns the bits per key of new bloom filters configured by `options`
+func filterBitsPerKey(options config.DBOptions) (uint32, error) {
+	if rate := options.FilterFalsePositiveRate; rate != 0 {
+		if !(rate > 0 && rate < 1) {
+			return 0, internal.ErrInvalidArgument("filter false positive rate %v must be between 0 and 1", rate)
+		}
+		return bloom.BitsPerKeyForFalsePositiveRate(rate), nil
+	}
+	if options.FilterBitsPerKey == 0 {
+		return sstable.DefaultConfig().FilterBitsPerKey, nil
+	}
+	return options.FilterBitsPerKey, nil
+}
+
+// requiredFeatures returns the on-disk format features used when writing data with `options`
+func requiredFeatures(options config.DBOptions) manifest.Features {
+	// Every SST is written with CRC32C block checksums
+	features := manifest.FeatureBlockCRC32C
+	if options.AutoCompression {
+		features |= manifest.FeatureBlockCompressionFlags
+	}
+	if options.FilterHash != bloom.HashFNV64 || options.FilterSeed != 0 {
+		features |= manifest.FeatureFilterHash
+	}
+	if options.ValueChecksums {
+		features |= manifest.FeatureValueChecksums
+	}
+	if options.FilterPartitionBlocks > 0 {
+		features |= manifest.FeaturePartitionedFilters
+	}
+	if options.BlockRestartInterval > 0 {
+		features |= manifest.FeatureBlockRestarts
+	}
+	if options.WALEntryChecksums {
+		features |= manifest.FeatureWALEntryChecksums
+	}
+	return features
+}
+
 func newDB(
 	ctx context.Context,
 	options config.DBOptions,
 	tableStore *store.TableStore,
+	statsStore *store.StatsStore,
 	coreDBState *state.CoreDBState,
 	memtableFlushNotifierCh chan<- MemtableFlushThreadMsg,
+	skipWAL bool,
 ) (*DB, error) {
 
 	dbState := state.NewDBState(coreDBState)
@@ -421,22 +1338,41 @@ func newDB(
 		state:                   dbState,
 		opts:                    options,
 		tableStore:              tableStore,
+		statsStore:              statsStore,
+		sstAccess:               newSSTAccessStats(),
+		profiler:                profile.NewRecorder(options.ProfileLabels),
+		tracer:                  tracing.Tracer(options.TracerProvider),
 		memtableFlushNotifierCh: memtableFlushNotifierCh,
-		walFlushTaskWG:          &sync.WaitGroup{},
-		memtableFlushTaskWG:     &sync.WaitGroup{},
+		walFlushRequestCh:       make(chan struct{}, 1),
+		tasks:                   newTaskManager(options),
+		skipWAL:                 skipWAL,
+		idle:                    newIdleTracker(options.IdleTimeout, options.Clock.Now()),
+		quarantine:              quarantineQueue{notify: make(chan struct{}, 1)},
 	}
-	err := db.replayWAL(ctx)
+	db.fencedCtx, db.fence = context.WithCancel(context.Background())
+	db.loadSSTAccessStats()
+	err := db.replayWAL(ctx, db.state)
 	if err != nil {
 		return nil, err
 	}
 	return db, nil
 }
 
-func checkValue(val types.Value) ([]byte, error) {
-	if val.GetValue().IsAbsent() { // key is tombstoned/deleted
+// keySuccessor returns the smallest key greater than `key`, such that [key, keySuccessor(key)) contains only `key`
+func keySuccessor(key []byte) []byte {
+	return append(bytes.Clone(key), 0)
+}
+
+// checkEntry returns the value of the entry, or ErrKeyNotFound if the entry
+// is a tombstone or has expired as of `now`
+func checkEntry(entry types.RowEntry, now time.Time) ([]byte, error) {
+	if entry.IsExpired(now) {
+		return nil, ErrKeyNotFound
+	}
+	if entry.Value.GetValue().IsAbsent() { // key is tombstoned/deleted
 		return nil, ErrKeyNotFound
 	} else { // key is present
-		value, _ := val.GetValue().Get()
+		value, _ := entry.Value.GetValue().Get()
 		return value, nil
 	}
 }
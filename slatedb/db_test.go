@@ -4,17 +4,27 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"fmt"
+	"io"
 	"math"
+	"runtime"
 	"strconv"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/oklog/ulid/v2"
+	"github.com/samber/mo"
 	assert2 "github.com/slatedb/slatedb-go/internal/assert"
 	"github.com/slatedb/slatedb-go/internal/compress"
 	"github.com/slatedb/slatedb-go/internal/sstable"
+	"github.com/slatedb/slatedb-go/internal/sstable/bloom"
 	"github.com/slatedb/slatedb-go/internal/types"
+	"github.com/slatedb/slatedb-go/slatedb/audit"
 	"github.com/slatedb/slatedb-go/slatedb/config"
+	"github.com/slatedb/slatedb-go/slatedb/faultbucket"
+	"github.com/slatedb/slatedb-go/slatedb/manifest"
 	"github.com/slatedb/slatedb-go/slatedb/state"
 	"github.com/slatedb/slatedb-go/slatedb/store"
 	"github.com/stretchr/testify/assert"
@@ -54,6 +64,990 @@ func TestPutGetDelete(t *testing.T) {
 	assert.True(t, errors.Is(err, ErrKeyNotFound))
 }
 
+func TestAmplification(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	bucket := objstore.NewInMemBucket()
+	db, err := OpenWithOptions(ctx, "/tmp/test_kv_store", bucket, testDBOptions(0, 1024))
+	require.NoError(t, err)
+	defer func() { _ = db.Close(ctx) }()
+
+	assert.Equal(t, Amplification{}, db.Amplification())
+
+	require.NoError(t, db.Put(ctx, []byte("key1"), []byte("value1")))
+	require.NoError(t, db.Put(ctx, []byte("key2"), []byte("value2")))
+	require.NoError(t, db.FlushMemtableToL0())
+
+	// the data was written once to the WAL and once to L0,
+	// along with the SST index and metadata for each.
+	amp := db.Amplification()
+	assert.Greater(t, amp.Write, 2.0)
+	assert.Equal(t, 0.0, amp.Read)
+
+	// reads served from the memtable do not probe any SSTs
+	require.NoError(t, db.Put(ctx, []byte("key3"), []byte("value3")))
+	_, err = db.Get(ctx, []byte("key3"))
+	require.NoError(t, err)
+	assert.Equal(t, 0.0, db.Amplification().Read)
+
+	// a read served from L0 probes one SST
+	_, err = db.Get(ctx, []byte("key1"))
+	require.NoError(t, err)
+	assert.Equal(t, 0.5, db.Amplification().Read)
+}
+
+func TestStats(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	bucket := objstore.NewInMemBucket()
+	db, err := OpenWithOptions(ctx, "/tmp/test_kv_store", bucket, testDBOptions(0, 1024))
+	require.NoError(t, err)
+	defer func() { _ = db.Close(ctx) }()
+
+	// the writes are in the WAL SSTs and the memtable until the memtable is flushed to L0
+	require.NoError(t, db.Put(ctx, []byte("key1"), []byte("value1")))
+	require.NoError(t, db.Put(ctx, []byte("key2"), []byte("value2")))
+	stats := db.Stats()
+	assert.Equal(t, LevelStats{}, stats.Live)
+	assert.Greater(t, stats.MemtableBytes, int64(0))
+	assert.Greater(t, stats.UncompactedWALSSTs, uint64(0))
+
+	require.NoError(t, db.FlushMemtableToL0())
+	stats = db.Stats()
+	assert.Equal(t, 1, stats.L0.SSTs)
+	assert.Greater(t, stats.L0.Bytes, uint64(0))
+	assert.Equal(t, stats.L0, stats.Live)
+	assert.Equal(t, stats.L0.Bytes, stats.PendingCompactionBytes)
+	assert.Empty(t, stats.SortedRuns)
+	assert.Equal(t, 1, stats.MaxReadAmplification)
+	assert.Equal(t, 0.0, stats.SpaceAmplification)
+	assert.Equal(t, int64(0), stats.MemtableBytes)
+	assert.Equal(t, uint64(0), stats.UncompactedWALSSTs)
+
+	value, ok := db.Property("slatedb.l0-ssts")
+	assert.True(t, ok)
+	assert.Equal(t, "1", value)
+	value, ok = db.Property("slatedb.stats")
+	assert.True(t, ok)
+	assert.Contains(t, value, `"PendingCompactionBytes":`)
+	_, ok = db.Property("slatedb.unknown")
+	assert.False(t, ok)
+	for _, name := range PropertyNames() {
+		_, ok := db.Property(name)
+		assert.True(t, ok, name)
+	}
+}
+
+func TestOpenInMemory(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	options := testDBOptions(0, 1024)
+	options.FlushInterval = time.Hour
+	db, err := OpenInMemory(ctx, options)
+	require.NoError(t, err)
+	defer func() { _ = db.Close(ctx) }()
+
+	// durable writes do not wait for the FlushInterval
+	putCtx, putCancel := context.WithTimeout(ctx, 5*time.Second)
+	defer putCancel()
+	require.NoError(t, db.Put(putCtx, []byte("key1"), []byte("value1")))
+	require.NoError(t, db.Put(putCtx, []byte("key2"), []byte("value2")))
+	require.NoError(t, db.Delete(putCtx, []byte("key2")))
+	require.NoError(t, db.FlushMemtableToL0())
+
+	val, err := db.Get(ctx, []byte("key1"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("value1"), val)
+	_, err = db.Get(ctx, []byte("key2"))
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+}
+
+func TestSSTAccessStats(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	bucket := objstore.NewInMemBucket()
+	db, err := OpenWithOptions(ctx, "/tmp/test_kv_store", bucket, testDBOptions(0, 1024))
+	require.NoError(t, err)
+
+	require.NoError(t, db.Put(ctx, []byte("key1"), []byte("value1")))
+	require.NoError(t, db.FlushMemtableToL0())
+	require.NoError(t, db.Put(ctx, []byte("key2"), []byte("value2")))
+	require.NoError(t, db.FlushMemtableToL0())
+
+	for i := 0; i < 2; i++ {
+		_, err = db.Get(ctx, []byte("key1"))
+		require.NoError(t, err)
+	}
+
+	// the SST which was never read is the coldest
+	stats := db.SSTAccessStats()
+	require.Len(t, stats, 2)
+	assert.True(t, stats[0].L0)
+	assert.Equal(t, uint64(0), stats[0].Reads)
+	assert.True(t, stats[0].LastAccess.IsZero())
+	assert.Equal(t, uint64(2), stats[1].Reads)
+	assert.False(t, stats[1].LastAccess.IsZero())
+	require.NoError(t, db.Close(ctx))
+
+	// the statistics are persisted when the DB is closed and loaded when reopened
+	db, err = OpenWithOptions(ctx, "/tmp/test_kv_store", bucket, testDBOptions(0, 1024))
+	require.NoError(t, err)
+	defer func() { _ = db.Close(ctx) }()
+
+	reopened := db.SSTAccessStats()
+	require.Len(t, reopened, 2)
+	assert.Equal(t, stats[1].ID, reopened[1].ID)
+	assert.Equal(t, uint64(2), reopened[1].Reads)
+	assert.True(t, stats[1].LastAccess.Equal(reopened[1].LastAccess))
+}
+
+type testAuditSink struct {
+	mu      sync.Mutex
+	records []audit.Record
+}
+
+func (s *testAuditSink) Write(records []audit.Record) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records = append(s.records, records...)
+}
+
+func TestAuditSink(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	sink := &testAuditSink{}
+	options := testDBOptions(0, 1024)
+	options.AuditSink = sink
+	options.AuditWriterID = "writer-1"
+
+	bucket := objstore.NewInMemBucket()
+	db, err := OpenWithOptions(ctx, "/tmp/test_kv_store", bucket, options)
+	require.NoError(t, err)
+	defer func() { _ = db.Close(ctx) }()
+
+	require.NoError(t, db.Put(ctx, []byte("key1"), []byte("value1")))
+	require.NoError(t, db.Delete(ctx, []byte("key2")))
+
+	// records are emitted before writers awaiting durability are notified
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	require.Len(t, sink.records, 2)
+
+	put := sink.records[0]
+	assert.Equal(t, audit.HashKey([]byte("key1")), put.KeyHash)
+	assert.Equal(t, audit.OpPut, put.Op)
+	assert.Equal(t, "writer-1", put.WriterID)
+	assert.Equal(t, uint64(1), put.WriterEpoch)
+	assert.False(t, put.Timestamp.IsZero())
+
+	del := sink.records[1]
+	assert.Equal(t, audit.HashKey([]byte("key2")), del.KeyHash)
+	assert.Equal(t, audit.OpDelete, del.Op)
+	assert.Less(t, put.WALID, del.WALID)
+	assert.Positive(t, put.Seq)
+	assert.Less(t, put.Seq, del.Seq)
+}
+
+func TestAuditSinkWithoutWAL(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	sink := &testAuditSink{}
+	options := testDBOptions(0, 1024*1024)
+	options.AuditSink = sink
+	options.DirectL0BatchBytes = 1024
+	options.WALEnabled = mo.Some(false)
+	db, err := OpenWithOptions(ctx, "/tmp/test_kv_store", objstore.NewInMemBucket(), options)
+	require.NoError(t, err)
+	defer func() { _ = db.Close(ctx) }()
+	records := func() []audit.Record {
+		sink.mu.Lock()
+		defer sink.mu.Unlock()
+		records := sink.records
+		sink.records = nil
+		return records
+	}
+
+	// the writes to the memtable are audited once it is flushed to L0
+	require.NoError(t, db.Put(ctx, []byte("key1"), []byte("value1")))
+	audited := records()
+	require.Len(t, audited, 1)
+	assert.Equal(t, audit.HashKey([]byte("key1")), audited[0].KeyHash)
+	assert.Equal(t, audit.OpPut, audited[0].Op)
+	assert.Zero(t, audited[0].WALID)
+	assert.Positive(t, audited[0].Seq)
+
+	// as are the writes of a batch written directly to L0
+	batch := NewWriteBatch()
+	batch.Put([]byte("key2"), bytes.Repeat([]byte("v"), 1024))
+	batch.Delete([]byte("key3"))
+	require.NoError(t, db.Write(ctx, batch))
+	audited = records()
+	require.Len(t, audited, 2)
+	assert.Equal(t, audit.HashKey([]byte("key2")), audited[0].KeyHash)
+	assert.Equal(t, audit.OpDelete, audited[1].Op)
+	assert.Equal(t, audited[0].Seq, audited[1].Seq)
+
+	// and the range tombstone and entries of a replaced range
+	writer := db.NewIngestWriter()
+	require.NoError(t, writer.Add(ctx, []byte("key4"), []byte("ingested")))
+	ssts, err := writer.Finish(ctx)
+	require.NoError(t, err)
+	require.NoError(t, db.ReplaceRange(ctx, []byte("key1"), []byte("key5"), ssts))
+	audited = records()
+	require.Len(t, audited, 2)
+	assert.Equal(t, audit.OpDeleteRange, audited[0].Op)
+	assert.Equal(t, audit.HashKey([]byte("key1")), audited[0].KeyHash)
+	assert.Equal(t, audit.HashKey([]byte("key4")), audited[1].KeyHash)
+	assert.Equal(t, audit.OpPut, audited[1].Op)
+}
+
+func TestManualClock(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := config.NewManualClock(start)
+	sink := &testAuditSink{}
+	options := testDBOptions(0, 1024)
+	options.Clock = clock
+	options.AuditSink = sink
+
+	db, err := OpenWithOptions(ctx, "/tmp/test_kv_store", objstore.NewInMemBucket(), options)
+	require.NoError(t, err)
+	defer func() { _ = db.Close(ctx) }()
+
+	require.NoError(t, db.PutWithOptions(ctx, []byte("key1"), []byte("value1"),
+		config.WriteOptions{AwaitDurable: false}))
+
+	// the WAL is not flushed until the clock reaches the next FlushInterval
+	time.Sleep(3 * options.FlushInterval)
+	_, err = db.Get(ctx, []byte("key1"))
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+
+	clock.Advance(options.FlushInterval)
+	require.Eventually(t, func() bool {
+		_, err := db.Get(ctx, []byte("key1"))
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	require.Len(t, sink.records, 1)
+	assert.Equal(t, start.Add(options.FlushInterval), sink.records[0].Timestamp)
+}
+
+func TestDegradedOnFlushFailures(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	clock := config.NewManualClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	var mu sync.Mutex
+	var events []config.TaskEventType
+	options := testDBOptions(0, 1024)
+	options.Clock = clock
+	options.MaxFlushFailureDuration = time.Minute
+	options.OnTaskEvent = func(e config.TaskEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, e.Type)
+	}
+	listener := &recordingListener{}
+	options.EventListener = listener
+
+	bucket := faultbucket.New(objstore.NewInMemBucket(), faultbucket.Options{})
+	db, err := OpenWithOptions(ctx, "/tmp/test_kv_store", bucket, options)
+	require.NoError(t, err)
+	defer func() { _ = db.Close(ctx) }()
+	require.NoError(t, db.PutWithOptions(ctx, []byte("key1"), []byte("value1"), config.WriteOptions{AwaitDurable: false}))
+	require.NoError(t, db.FlushWAL(ctx))
+
+	// writes are accepted until the WAL flush has been failing for MaxFlushFailureDuration
+	bucket.SetFault(faultbucket.OpUpload, faultbucket.Fault{ErrorRate: 1})
+	noWait := config.WriteOptions{AwaitDurable: false}
+	require.NoError(t, db.PutWithOptions(ctx, []byte("key2"), []byte("value2"), noWait))
+	assert.Error(t, db.FlushWAL(ctx))
+	clock.Advance(30 * time.Second)
+	assert.Error(t, db.FlushWAL(ctx))
+	require.NoError(t, db.HealthCheck())
+	require.NoError(t, db.PutWithOptions(ctx, []byte("key3"), []byte("value3"), noWait))
+
+	clock.Advance(time.Minute)
+	assert.Error(t, db.FlushWAL(ctx))
+	assert.ErrorIs(t, db.PutWithOptions(ctx, []byte("key4"), []byte("value4"), noWait), ErrDegraded)
+	assert.ErrorIs(t, db.Delete(ctx, []byte("key1")), ErrDegraded)
+	assert.ErrorIs(t, db.HealthCheck(), ErrDegraded)
+
+	// reads are served while the database is degraded
+	value, err := db.Get(ctx, []byte("key1"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("value1"), value)
+
+	// the database is not resumed until the flushes succeed
+	assert.Error(t, db.Resume(ctx))
+	assert.ErrorIs(t, db.HealthCheck(), ErrDegraded)
+	bucket.ClearFaults()
+	require.NoError(t, db.Resume(ctx))
+	require.NoError(t, db.HealthCheck())
+	require.NoError(t, db.PutWithOptions(ctx, []byte("key4"), []byte("value4"), noWait))
+	require.NoError(t, db.FlushWAL(ctx))
+	value, err = db.Get(ctx, []byte("key3"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("value3"), value)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []config.TaskEventType{config.TaskDegraded, config.TaskResumed}, events)
+
+	// the transitions are also notified to the EventListener
+	listener.mu.Lock()
+	defer listener.mu.Unlock()
+	assert.Equal(t, events, listener.taskEvents)
+}
+
+func TestSSTCreatedAtFromClock(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	clock := config.NewManualClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	options := testDBOptions(0, 1024)
+	options.Clock = clock
+	db, err := OpenWithOptions(ctx, "/tmp/test_kv_store", objstore.NewInMemBucket(), options)
+	require.NoError(t, err)
+	defer func() { _ = db.Close(ctx) }()
+
+	// the creation time of an SST is read from DBOptions.Clock
+	clock.Advance(time.Hour)
+	require.NoError(t, db.PutWithOptions(ctx, []byte("key1"), []byte("value1"), config.WriteOptions{AwaitDurable: false}))
+	require.NoError(t, db.FlushWAL(ctx))
+	require.NoError(t, db.FlushMemtableToL0())
+	l0 := db.state.CoreStateSnapshot().L0
+	require.Len(t, l0, 1)
+	assert.True(t, clock.Now().Equal(l0[0].Info.CreatedAt))
+}
+
+func TestIdle(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	clock := config.NewManualClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	options := testDBOptions(0, 1024)
+	options.Clock = clock
+	options.BlockCacheSize = 1024 * 1024
+	options.IdleTimeout = time.Minute
+	noWait := config.WriteOptions{AwaitDurable: false}
+
+	db, err := OpenWithOptions(ctx, "/tmp/test_kv_store", objstore.NewInMemBucket(), options)
+	require.NoError(t, err)
+	defer func() { _ = db.Close(ctx) }()
+	require.NoError(t, db.PutWithOptions(ctx, []byte("key1"), []byte("value1"), noWait))
+	require.NoError(t, db.FlushWAL(ctx))
+	require.NoError(t, db.FlushMemtableToL0())
+	_, err = db.Get(ctx, []byte("key1"))
+	require.NoError(t, err)
+	assert.Positive(t, db.tableStore.BlockCacheStats().Size)
+	assert.False(t, db.Idle())
+
+	// the database goes idle at the first WAL flush tick after IdleTimeout
+	clock.Advance(options.IdleTimeout)
+	require.Eventually(t, func() bool {
+		clock.Advance(options.FlushInterval)
+		return db.Idle()
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, uint64(0), db.tableStore.BlockCacheStats().Size)
+
+	// reads are served while idle, and a write wakes the database and resumes the WAL flush ticker
+	val, err := db.Get(ctx, []byte("key1"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("value1"), val)
+	assert.True(t, db.Idle())
+	require.NoError(t, db.PutWithOptions(ctx, []byte("key2"), []byte("value2"), noWait))
+	assert.False(t, db.Idle())
+	require.Eventually(t, func() bool {
+		clock.Advance(options.FlushInterval)
+		_, err := db.Get(ctx, []byte("key2"))
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestPutWithTTL(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	clock := config.NewManualClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	options := testDBOptions(0, 1024)
+	options.Clock = clock
+	db, err := OpenInMemory(ctx, options)
+	require.NoError(t, err)
+	defer func() { _ = db.Close(ctx) }()
+
+	require.NoError(t, db.PutWithTTL(ctx, []byte("key1"), []byte("value1"), time.Minute))
+	require.NoError(t, db.PutWithTTL(ctx, []byte("key2"), []byte("value2"), time.Hour))
+	require.NoError(t, db.Put(ctx, []byte("key3"), []byte("value3")))
+	assert.Error(t, db.PutWithTTL(ctx, []byte("key4"), []byte("value4"), 0))
+
+	value, err := db.Get(ctx, []byte("key1"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("value1"), value)
+
+	// the expiry of keys in the memtable and in L0 SSTs is honoured
+	for _, flush := range []bool{false, true} {
+		if flush {
+			require.NoError(t, db.FlushMemtableToL0())
+			require.NoError(t, db.PutWithTTL(ctx, []byte("key1"), []byte("value1"), time.Minute))
+			require.NoError(t, db.FlushMemtableToL0())
+		}
+		clock.Advance(time.Minute)
+
+		_, err = db.Get(ctx, []byte("key1"))
+		assert.ErrorIs(t, err, ErrKeyNotFound)
+		value, err = db.Get(ctx, []byte("key2"))
+		require.NoError(t, err)
+		assert.Equal(t, []byte("value2"), value)
+
+		it, err := db.Scan(ctx, nil, nil)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"key2": "value2", "key3": "value3"}, scanAll(t, it))
+	}
+}
+
+func TestDeleteRange(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	bucket := objstore.NewInMemBucket()
+	db, err := OpenWithOptions(ctx, "/tmp/test_kv_store", bucket, testDBOptions(0, 1024))
+	require.NoError(t, err)
+
+	for _, key := range []string{"key1", "key2", "key3", "key4", "key5"} {
+		require.NoError(t, db.Put(ctx, []byte(key), []byte("l0")))
+	}
+	require.NoError(t, db.FlushMemtableToL0())
+	require.NoError(t, db.Put(ctx, []byte("key3"), []byte("memtable")))
+
+	assert.Error(t, db.DeleteRange(ctx, nil, []byte("key4")))
+	assert.Error(t, db.DeleteRange(ctx, []byte("key4"), []byte("key2")))
+	require.NoError(t, db.DeleteRange(ctx, []byte("key2"), []byte("key4")))
+	// a key written after the range tombstone is not deleted
+	require.NoError(t, db.Put(ctx, []byte("key2"), []byte("after")))
+
+	assertKeys := func(expected map[string]string) {
+		t.Helper()
+		for _, key := range []string{"key1", "key2", "key3", "key4", "key5"} {
+			value, err := db.Get(ctx, []byte(key))
+			if v, ok := expected[key]; ok {
+				require.NoError(t, err, key)
+				assert.Equal(t, []byte(v), value)
+			} else {
+				assert.ErrorIs(t, err, ErrKeyNotFound, key)
+			}
+		}
+		it, err := db.Scan(ctx, nil, nil)
+		require.NoError(t, err)
+		assert.Equal(t, expected, scanAll(t, it))
+	}
+	assertKeys(map[string]string{"key1": "l0", "key2": "after", "key4": "l0", "key5": "l0"})
+
+	// the range tombstone is written to the L0 SST
+	require.NoError(t, db.FlushMemtableToL0())
+	assertKeys(map[string]string{"key1": "l0", "key2": "after", "key4": "l0", "key5": "l0"})
+	stored, err := store.LoadStoredManifest(store.NewManifestStore("/tmp/test_kv_store", bucket))
+	require.NoError(t, err)
+	sm := stored.MustGet()
+	assert.True(t, sm.Features().Has(manifest.FeatureRangeTombstones))
+
+	// a range tombstone only in the WAL is replayed when the database is reopened
+	require.NoError(t, db.DeleteRange(ctx, []byte("key4"), []byte("key9")))
+	require.NoError(t, db.Close(ctx))
+
+	db, err = OpenWithOptions(ctx, "/tmp/test_kv_store", bucket, testDBOptions(0, 1024))
+	require.NoError(t, err)
+	defer func() { _ = db.Close(ctx) }()
+	assertKeys(map[string]string{"key1": "l0", "key2": "after"})
+}
+
+func TestSequenceNumbers(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	bucket := objstore.NewInMemBucket()
+	db, err := OpenWithOptions(ctx, "/tmp/test_kv_store", bucket, testDBOptions(0, 1024))
+	require.NoError(t, err)
+
+	require.NoError(t, db.Put(ctx, []byte("key1"), []byte("value1")))
+	require.NoError(t, db.Put(ctx, []byte("key2"), []byte("value2")))
+	require.NoError(t, db.Delete(ctx, []byte("key1")))
+	assert.Equal(t, uint64(3), db.state.LastSeq())
+
+	// sequence numbers are encoded in the rows of the L0 SST
+	require.NoError(t, db.FlushMemtableToL0())
+	l0 := db.state.L0()
+	require.Len(t, l0, 1)
+	iter, err := sstable.NewIterator(ctx, &l0[0], db.tableStore.Clone())
+	require.NoError(t, err)
+	entry, ok := iter.NextEntry(ctx)
+	require.True(t, ok)
+	assert.Equal(t, []byte("key1"), entry.Key)
+	assert.True(t, entry.Value.IsTombstone())
+	assert.Equal(t, uint64(3), entry.Seq)
+	entry, ok = iter.NextEntry(ctx)
+	require.True(t, ok)
+	assert.Equal(t, []byte("key2"), entry.Key)
+	assert.Equal(t, uint64(2), entry.Seq)
+
+	// a write only in the WAL is replayed when the database is reopened
+	require.NoError(t, db.Put(ctx, []byte("key3"), []byte("value3")))
+	require.NoError(t, db.Close(ctx))
+
+	db, err = OpenWithOptions(ctx, "/tmp/test_kv_store", bucket, testDBOptions(0, 1024))
+	require.NoError(t, err)
+	defer func() { _ = db.Close(ctx) }()
+	assert.Equal(t, uint64(4), db.state.LastSeq())
+
+	require.NoError(t, db.Put(ctx, []byte("key4"), []byte("value4")))
+	assert.Equal(t, uint64(5), db.state.LastSeq())
+	stored, err := db.manifest.DbState()
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, stored.LastSeq.Load(), uint64(3))
+}
+
+func TestReadSSTsWithDifferentFilterHash(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	bucket := objstore.NewInMemBucket()
+	options := testDBOptions(0, 1024)
+	options.FilterHash = bloom.HashXXH64
+	options.FilterSeed = 1234
+	db, err := OpenWithOptions(ctx, "/tmp/test_kv_store", bucket, options)
+	require.NoError(t, err)
+	require.NoError(t, db.Put(ctx, []byte("key1"), []byte("value1")))
+	require.NoError(t, db.FlushMemtableToL0())
+	require.NoError(t, db.Close(ctx))
+
+	// SSTs written with another hash remain readable by a writer using the default hash
+	db, err = OpenWithOptions(ctx, "/tmp/test_kv_store", bucket, testDBOptions(0, 1024))
+	require.NoError(t, err)
+	defer func() { _ = db.Close(ctx) }()
+
+	val, err := db.Get(ctx, []byte("key1"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("value1"), val)
+	_, err = db.Get(ctx, []byte("key2"))
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+}
+
+func TestAutoCompression(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	bucket := objstore.NewInMemBucket()
+	options := testDBOptions(0, 1024)
+	options.CompressionCodec = compress.CodecZstd
+	options.AutoCompression = true
+	db, err := OpenWithOptions(ctx, "/tmp/test_kv_store", bucket, options)
+	require.NoError(t, err)
+	defer func() { _ = db.Close(ctx) }()
+
+	require.NoError(t, db.Put(ctx, []byte("key1"), repeatedChar('a', 256)))
+	require.NoError(t, db.Put(ctx, []byte("key2"), []byte("value2")))
+	require.NoError(t, db.FlushMemtableToL0())
+
+	val, err := db.Get(ctx, []byte("key1"))
+	require.NoError(t, err)
+	assert.Equal(t, repeatedChar('a', 256), val)
+	val, err = db.Get(ctx, []byte("key2"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("value2"), val)
+}
+
+func TestBlockRestartInterval(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	bucket := objstore.NewInMemBucket()
+	options := testDBOptions(0, 1024)
+	options.BlockRestartInterval = 4
+	db, err := OpenWithOptions(ctx, "/tmp/test_kv_store", bucket, options)
+	require.NoError(t, err)
+	defer func() { _ = db.Close(ctx) }()
+
+	for i := 0; i < 50; i++ {
+		require.NoError(t, db.Put(ctx, []byte(fmt.Sprintf("key%03d", i)), []byte(fmt.Sprintf("value%d", i))))
+	}
+	require.NoError(t, db.FlushMemtableToL0())
+	l0 := db.state.CoreStateSnapshot().L0
+	require.Len(t, l0, 1)
+	assert.Equal(t, uint16(4), l0[0].Info.BlockRestartInterval)
+
+	stored, err := store.LoadStoredManifest(store.NewManifestStore("/tmp/test_kv_store", bucket))
+	require.NoError(t, err)
+	sm := stored.MustGet()
+	assert.True(t, sm.Features().Has(manifest.FeatureBlockRestarts))
+
+	val, err := db.Get(ctx, []byte("key017"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("value17"), val)
+	_, err = db.Get(ctx, []byte("key0175"))
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+
+	expected := map[string]string{}
+	for i := 10; i < 20; i++ {
+		expected[fmt.Sprintf("key%03d", i)] = fmt.Sprintf("value%d", i)
+	}
+	it, err := db.Scan(ctx, []byte("key010"), []byte("key020"))
+	require.NoError(t, err)
+	assert.Equal(t, expected, scanAll(t, it))
+}
+
+func TestInlineValueBytes(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	bucket := objstore.NewInMemBucket()
+	options := testDBOptions(0, 1024*1024)
+	options.InlineValueBytes = 16
+	db, err := OpenWithOptions(ctx, "/tmp/test_kv_store", bucket, options)
+	require.NoError(t, err)
+	defer func() { _ = db.Close(ctx) }()
+
+	require.NoError(t, db.Put(ctx, []byte("flag"), []byte("on")))
+	require.NoError(t, db.Put(ctx, []byte("large"), repeatedChar('a', 64)))
+	require.NoError(t, db.Put(ctx, []byte("deleted"), []byte("value")))
+	require.NoError(t, db.FlushMemtableToL0())
+	require.NoError(t, db.Put(ctx, []byte("flag"), []byte("off")))
+	require.NoError(t, db.Delete(ctx, []byte("deleted")))
+	require.NoError(t, db.FlushMemtableToL0())
+
+	l0 := db.state.CoreStateSnapshot().L0
+	require.Len(t, l0, 2)
+	index, err := db.tableStore.ReadIndex(ctx, &l0[1])
+	require.NoError(t, err)
+	_, ok, err := index.InlineEntry([]byte("flag"))
+	require.NoError(t, err)
+	assert.True(t, ok)
+	_, ok, err = index.InlineEntry([]byte("large"))
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	val, err := db.Get(ctx, []byte("flag"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("off"), val)
+	val, err = db.Get(ctx, []byte("large"))
+	require.NoError(t, err)
+	assert.Equal(t, repeatedChar('a', 64), val)
+	_, err = db.Get(ctx, []byte("deleted"))
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+	_, err = db.Get(ctx, []byte("missing"))
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+}
+
+func TestFormatInfo(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	build := BuildInfo()
+	assert.NotEmpty(t, build.Version)
+	assert.Equal(t, runtime.Version(), build.GoVersion)
+	assert.Equal(t, 1, build.ManifestFormatVersion)
+	assert.Equal(t, 0, build.SSTFormatVersion)
+	assert.Contains(t, build.SupportedFeatures, "block_restarts")
+	assert.Contains(t, build.SupportedFeatures, "range_tombstones")
+
+	bucket := objstore.NewInMemBucket()
+	dbPath := "/tmp/test_kv_store"
+	options := testDBOptions(0, 1024*1024)
+	options.BlockRestartInterval = 4
+	db, err := OpenWithOptions(ctx, dbPath, bucket, options)
+	require.NoError(t, err)
+	defer func() { _ = db.Close(ctx) }()
+
+	info := db.FormatInfo()
+	assert.Equal(t, build, info.VersionInfo)
+	assert.Contains(t, info.Features, "block_restarts")
+	assert.NotContains(t, info.Features, "value_checksums")
+
+	readOnly := testDBOptions(0, 1024*1024)
+	readOnly.ReadOnly = true
+	reader, err := OpenWithOptions(ctx, dbPath, bucket, readOnly)
+	require.NoError(t, err)
+	defer func() { _ = reader.Close(ctx) }()
+	assert.Equal(t, info, reader.FormatInfo())
+}
+
+func TestLevelCompression(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	bucket := objstore.NewInMemBucket()
+	options := testDBOptions(0, 1024)
+	options.CompressionCodec = compress.CodecSnappy
+	options.CompressionLevel = 3
+	options.WALCompressionCodec = mo.Some(compress.CodecNone)
+	options.L0CompressionCodec = mo.Some(compress.CodecZstd)
+	db, err := OpenWithOptions(ctx, "/tmp/test_kv_store", bucket, options)
+	require.NoError(t, err)
+	defer func() { _ = db.Close(ctx) }()
+
+	require.NoError(t, db.Put(ctx, []byte("key1"), repeatedChar('a', 256)))
+	require.NoError(t, db.FlushWAL(ctx))
+	walIDs, err := db.tableStore.GetWalSSTList(0)
+	require.NoError(t, err)
+	require.NotEmpty(t, walIDs)
+	wal, err := db.tableStore.OpenSST(ctx, sstable.NewIDWal(walIDs[len(walIDs)-1]))
+	require.NoError(t, err)
+	assert.Equal(t, compress.CodecNone, wal.Info.CompressionCodec)
+
+	require.NoError(t, db.FlushMemtableToL0())
+	l0 := db.state.CoreStateSnapshot().L0
+	require.Len(t, l0, 1)
+	assert.Equal(t, compress.CodecZstd, l0[0].Info.CompressionCodec)
+
+	// compacted SSTs use CompressionCodec, as it is not overridden
+	writer := db.tableStore.TableWriterAtLevel(sstable.NewIDCompacted(ulid.Make()), store.SSTLevelCompacted)
+	require.NoError(t, writer.Add([]byte("key1"), mo.Some([]byte("value1"))))
+	compacted, err := writer.Close(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, compress.CodecSnappy, compacted.Info.CompressionCodec)
+
+	val, err := db.Get(ctx, []byte("key1"))
+	require.NoError(t, err)
+	assert.Equal(t, repeatedChar('a', 256), val)
+
+	// the level must be supported by every codec which supports levels
+	options.CompressionLevel = 20
+	options.L0CompressionCodec = mo.Some(compress.CodecZlib)
+	_, err = OpenWithOptions(ctx, "/tmp/test_kv_store", bucket, options)
+	assert.ErrorContains(t, err, "compression level 20 is not supported by codec Zlib")
+	options.L0CompressionCodec = mo.None[compress.Codec]()
+	_, err = OpenWithOptions(ctx, "/tmp/test_kv_store", bucket, options)
+	assert.ErrorContains(t, err, "compression level 20 is not supported")
+}
+
+func TestFilterBitsPerKey(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	bucket := objstore.NewInMemBucket()
+	options := testDBOptions(0, 1024)
+	options.FilterFalsePositiveRate = 0.001
+	db, err := OpenWithOptions(ctx, "/tmp/test_kv_store", bucket, options)
+	require.NoError(t, err)
+
+	require.NoError(t, db.Put(ctx, []byte("key1"), []byte("value1")))
+	require.NoError(t, db.FlushMemtableToL0())
+	l0 := db.state.CoreStateSnapshot().L0
+	require.Len(t, l0, 1)
+	bitsPerKey := l0[0].Info.FilterBitsPerKey
+	assert.Equal(t, bloom.BitsPerKeyForFalsePositiveRate(0.001), bitsPerKey)
+	assert.LessOrEqual(t, bloom.FalsePositiveRate(bitsPerKey), 0.001)
+	require.NoError(t, db.Close(ctx))
+
+	// the rate must be between 0 and 1
+	options.FilterFalsePositiveRate = 1.5
+	_, err = OpenWithOptions(ctx, "/tmp/test_kv_store", bucket, options)
+	assert.ErrorContains(t, err, "filter false positive rate 1.5 must be between 0 and 1")
+}
+
+func TestHedgedReads(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	bucket := objstore.NewInMemBucket()
+	options := testDBOptions(0, 1024)
+	options.HedgedReads = config.HedgedReadOptions{Percentile: 0.95, MinDelay: time.Second, MaxInFlight: 4}
+	db, err := OpenWithOptions(ctx, "/tmp/test_kv_store", bucket, options)
+	require.NoError(t, err)
+
+	require.NoError(t, db.Put(ctx, []byte("key1"), []byte("value1")))
+	require.NoError(t, db.FlushMemtableToL0())
+	val, err := db.Get(ctx, []byte("key1"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("value1"), val)
+
+	// the reads of the SST are made through the hedge, but are faster than the delay
+	stats := db.HedgedReadStats()
+	assert.Greater(t, stats.Reads, uint64(0))
+	assert.Equal(t, uint64(0), stats.Hedged)
+	assert.Equal(t, time.Second, stats.Delay)
+	require.NoError(t, db.Close(ctx))
+
+	options.HedgedReads.Percentile = 1
+	_, err = OpenWithOptions(ctx, "/tmp/test_kv_store", bucket, options)
+	assert.ErrorContains(t, err, "hedged read percentile 1 must be between 0 and 1")
+}
+
+func TestGetAllowStale(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	bucket := faultbucket.New(objstore.NewInMemBucket(), faultbucket.Options{})
+	db, err := OpenWithOptions(ctx, "/tmp/test_kv_store", bucket, testDBOptions(0, 1024*1024))
+	require.NoError(t, err)
+	defer func() { _ = db.Close(ctx) }()
+	require.NoError(t, db.Put(ctx, []byte("key1"), []byte("value1")))
+	require.NoError(t, db.Put(ctx, []byte("key2"), []byte("value2")))
+	require.NoError(t, db.FlushMemtableToL0())
+	require.NoError(t, db.Put(ctx, []byte("key2"), []byte("value2b")))
+
+	// the filter of the L0 SST is cached by the first read
+	value, err := db.Get(ctx, []byte("key1"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("value1"), value)
+
+	// reads of object storage do not complete before the deadline of the Get
+	bucket.SetFault(faultbucket.OpGetRange, faultbucket.Fault{Latency: faultbucket.FixedLatency(time.Minute)})
+	get := func(key string, options config.ReadOptions) ([]byte, error) {
+		ctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+		return db.GetWithOptions(ctx, []byte(key), options)
+	}
+	_, err = get("key1", config.ReadOptions{})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// the key may be held by the SST, whose index and blocks are not cached
+	stale := config.ReadOptions{AllowStale: true}
+	value, err = get("key1", stale)
+	assert.ErrorIs(t, err, ErrStaleRead)
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+	assert.Nil(t, value)
+
+	// keys served by the memtable, or excluded from the SST by its cached filter, are not stale
+	value, err = get("key2", stale)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("value2b"), value)
+	_, err = get("key3", stale)
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+	assert.NotErrorIs(t, err, ErrStaleRead)
+
+	bucket.ClearFaults()
+	value, err = get("key1", stale)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("value1"), value)
+}
+
+func TestReadOnly(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	bucket := objstore.NewInMemBucket()
+	dbPath := "/tmp/test_kv_store"
+	readOnly := testDBOptions(0, 1024)
+	readOnly.ReadOnly = true
+
+	_, err := OpenWithOptions(ctx, dbPath, bucket, readOnly)
+	assert.ErrorContains(t, err, "no database found")
+
+	db, err := OpenWithOptions(ctx, dbPath, bucket, testDBOptions(0, 1024))
+	require.NoError(t, err)
+	defer func() { _ = db.Close(ctx) }()
+	require.NoError(t, db.Put(ctx, []byte("key1"), []byte("value1")))
+	require.NoError(t, db.FlushMemtableToL0())
+	require.NoError(t, db.Put(ctx, []byte("key2"), []byte("value2")))
+
+	reader, err := OpenWithOptions(ctx, dbPath, bucket, readOnly)
+	require.NoError(t, err)
+	defer func() { _ = reader.Close(ctx) }()
+
+	// reads are served from L0 and the WAL
+	val, err := reader.Get(ctx, []byte("key1"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("value1"), val)
+	val, err = reader.Get(ctx, []byte("key2"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("value2"), val)
+
+	assert.ErrorIs(t, reader.Put(ctx, []byte("key3"), []byte("value3")), ErrReadOnly)
+	assert.ErrorIs(t, reader.Delete(ctx, []byte("key1")), ErrReadOnly)
+	assert.ErrorIs(t, reader.FlushWAL(ctx), ErrReadOnly)
+	assert.ErrorIs(t, reader.FlushMemtableToL0(), ErrReadOnly)
+
+	// the writer was not fenced by the read-only db
+	require.NoError(t, db.Put(ctx, []byte("key3"), []byte("value3")))
+	require.NoError(t, db.FlushMemtableToL0())
+}
+
+func TestReadOnlyMaxStaleness(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	bucket := objstore.NewInMemBucket()
+	dbPath := "/tmp/test_kv_store"
+	db, err := OpenWithOptions(ctx, dbPath, bucket, testDBOptions(0, 1024))
+	require.NoError(t, err)
+	defer func() { _ = db.Close(ctx) }()
+	require.NoError(t, db.Put(ctx, []byte("key1"), []byte("value1")))
+
+	readOnly := testDBOptions(0, 1024)
+	readOnly.ReadOnly = true
+	frozen, err := OpenWithOptions(ctx, dbPath, bucket, readOnly)
+	require.NoError(t, err)
+	readOnly.MaxStaleness = 10 * time.Millisecond
+	reader, err := OpenWithOptions(ctx, dbPath, bucket, readOnly)
+	require.NoError(t, err)
+
+	// new writes to the WAL and L0 are only seen by the reader with a staleness bound
+	require.NoError(t, db.Put(ctx, []byte("key2"), []byte("value2")))
+	require.NoError(t, db.FlushMemtableToL0())
+	require.NoError(t, db.Put(ctx, []byte("key3"), []byte("value3")))
+	time.Sleep(readOnly.MaxStaleness)
+
+	for _, key := range []string{"key1", "key2", "key3"} {
+		val, err := reader.Get(ctx, []byte(key))
+		require.NoError(t, err)
+		assert.Equal(t, []byte("value"+key[3:]), val)
+	}
+
+	_, err = frozen.Get(ctx, []byte("key2"))
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+	_, err = frozen.Get(ctx, []byte("key3"))
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+}
+
+func TestValueChecksums(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	bucket := objstore.NewInMemBucket()
+	options := testDBOptions(0, 1024)
+	options.ValueChecksums = true
+	db, err := OpenWithOptions(ctx, "/tmp/test_kv_store", bucket, options)
+	require.NoError(t, err)
+	defer func() { _ = db.Close(ctx) }()
+
+	require.NoError(t, db.Put(ctx, []byte("key1"), []byte("value1")))
+	require.NoError(t, db.FlushMemtableToL0())
+	value, err := db.Get(ctx, []byte("key1"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("value1"), value)
+	stored, err := store.LoadStoredManifest(store.NewManifestStore("/tmp/test_kv_store", bucket))
+	require.NoError(t, err)
+	sm := stored.MustGet()
+	assert.True(t, sm.Features().Has(manifest.FeatureValueChecksums))
+
+	// the checksum is stored in the SST with the value
+	sst := db.state.L0()[0]
+	iter, err := sstable.NewIterator(ctx, &sst, db.tableStore.Clone())
+	require.NoError(t, err)
+	entry, ok := iter.NextEntry(ctx)
+	require.True(t, ok)
+	assert.Equal(t, mo.Some(types.ValueChecksum([]byte("value1"))), entry.Checksum)
+
+	// a value which does not match its checksum is not returned
+	db.state.WalPut(types.RowEntry{
+		Key:      []byte("key2"),
+		Value:    types.Value{Value: []byte("corrupt")},
+		Checksum: mo.Some(types.ValueChecksum([]byte("value2"))),
+	})
+	_, err = db.GetWithOptions(ctx, []byte("key2"), config.ReadOptions{ReadLevel: config.Uncommitted})
+	assert.ErrorIs(t, err, ErrChecksumMismatch)
+}
+
 func TestGetNonExistingKey(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
 	defer cancel()
@@ -232,6 +1226,43 @@ func TestFlushWhileIterating(t *testing.T) {
 	assert.Equal(t, []byte("value3333"), e.Value.Value)
 }
 
+func TestConcurrentFlushWAL(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	options := testDBOptions(0, 1024)
+	options.FlushInterval = time.Millisecond
+	db, err := OpenWithOptions(ctx, "/tmp/test_kv_store", objstore.NewInMemBucket(), options)
+	require.NoError(t, err)
+	defer func() { _ = db.Close(ctx) }()
+
+	// FlushWAL is called while the WAL flush task flushes the same immutable WALs
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 500; i++ {
+				key := []byte(fmt.Sprintf("key-%d-%d", w, i))
+				assert.NoError(t, db.PutWithOptions(ctx, key, key, config.WriteOptions{}))
+				if i%10 == 0 {
+					assert.NoError(t, db.FlushWAL(ctx))
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	for w := 0; w < 4; w++ {
+		for i := 0; i < 500; i++ {
+			key := []byte(fmt.Sprintf("key-%d-%d", w, i))
+			value, err := db.Get(ctx, key)
+			require.NoError(t, err)
+			assert.Equal(t, key, value)
+		}
+	}
+}
+
 func TestFlushMemtableToL0(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
 	defer cancel()
@@ -336,6 +1367,107 @@ func TestBasicRestore(t *testing.T) {
 	assert.Equal(t, uint64(sstCount+2*l0Count+1), dbState.NextWalSstID.Load())
 }
 
+func TestRecoveryModeWithMissingWALSST(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	bucket := objstore.NewInMemBucket()
+	dbPath := "/tmp/test_kv_store"
+	db, err := OpenWithOptions(ctx, dbPath, bucket, testDBOptions(0, 1024))
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		require.NoError(t, db.Put(ctx, []byte(strconv.Itoa(i)), []byte(strconv.Itoa(i))))
+		require.NoError(t, db.FlushWAL(ctx))
+	}
+	walIDs, err := db.tableStore.GetWalSSTList(0)
+	require.NoError(t, err)
+	require.Len(t, walIDs, 3)
+	require.NoError(t, db.Close(ctx))
+
+	// the second WAL SST is lost, while the third is present
+	require.NoError(t, bucket.Delete(ctx, fmt.Sprintf("%s/wal/%020d.sst", dbPath, walIDs[1])))
+	_, err = OpenWithOptions(ctx, dbPath, bucket, testDBOptions(0, 1024))
+	assert.ErrorIs(t, err, ErrInconsistentWAL)
+
+	options := testDBOptions(0, 1024)
+	options.RecoveryMode = config.RecoveryModeRepair
+	db, err = OpenWithOptions(ctx, dbPath, bucket, options)
+	require.NoError(t, err)
+	it, err := db.Scan(ctx, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"0": "0", "2": "2"}, scanAll(t, it))
+	require.NoError(t, db.Put(ctx, []byte("3"), []byte("3")))
+	require.NoError(t, db.Close(ctx))
+
+	// the repaired database can be opened in strict mode
+	db, err = OpenWithOptions(ctx, dbPath, bucket, testDBOptions(0, 1024))
+	require.NoError(t, err)
+	defer func() { _ = db.Close(ctx) }()
+	it, err = db.Scan(ctx, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"0": "0", "2": "2", "3": "3"}, scanAll(t, it))
+}
+
+func TestRecoveryModeWithCorruptWALSST(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	bucket := objstore.NewInMemBucket()
+	dbPath := "/tmp/test_kv_store"
+	options := testDBOptions(0, 1024)
+	options.WALEntryChecksums = true
+	db, err := OpenWithOptions(ctx, dbPath, bucket, options)
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		require.NoError(t, db.Put(ctx, []byte(strconv.Itoa(i)), []byte("value-"+strconv.Itoa(i))))
+		require.NoError(t, db.FlushWAL(ctx))
+	}
+	walIDs, err := db.tableStore.GetWalSSTList(0)
+	require.NoError(t, err)
+	require.Len(t, walIDs, 3)
+	require.NoError(t, db.Close(ctx))
+
+	// the value of the second WAL SST is corrupt
+	path := fmt.Sprintf("%s/wal/%020d.sst", dbPath, walIDs[1])
+	r, err := bucket.Get(ctx, path)
+	require.NoError(t, err)
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+	i := bytes.Index(data, []byte("value-1"))
+	require.True(t, i >= 0)
+	data[i] ^= 0xff
+	require.NoError(t, bucket.Upload(ctx, path, bytes.NewReader(data)))
+
+	_, err = OpenWithOptions(ctx, dbPath, bucket, options)
+	assert.ErrorIs(t, err, ErrCorruptWAL)
+
+	// the WAL is truncated at the corrupt entry, discarding the WAL SSTs which follow it
+	options.RecoveryMode = config.RecoveryModeTruncate
+	db, err = OpenWithOptions(ctx, dbPath, bucket, options)
+	require.NoError(t, err)
+	it, err := db.Scan(ctx, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"0": "value-0"}, scanAll(t, it))
+	require.NoError(t, db.Put(ctx, []byte("3"), []byte("value-3")))
+	require.NoError(t, db.Close(ctx))
+
+	// the truncated database can be opened in strict mode
+	options.RecoveryMode = config.RecoveryModeStrict
+	db, err = OpenWithOptions(ctx, dbPath, bucket, options)
+	require.NoError(t, err)
+	defer func() { _ = db.Close(ctx) }()
+	it, err = db.Scan(ctx, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"0": "value-0", "3": "value-3"}, scanAll(t, it))
+}
+
+func TestCheckWALSSTs(t *testing.T) {
+	assert.NoError(t, checkWALSSTs(0, nil))
+	assert.NoError(t, checkWALSSTs(2, []uint64{3, 4, 5}))
+	assert.ErrorIs(t, checkWALSSTs(2, []uint64{4, 5}), ErrInconsistentWAL)
+	assert.ErrorIs(t, checkWALSSTs(2, []uint64{3, 5}), ErrInconsistentWAL)
+}
+
 func TestShouldReadUncommittedIfReadLevelUncommitted(t *testing.T) {
 	bucket := objstore.NewInMemBucket()
 	dbPath := "/tmp/test_kv_store"
@@ -589,7 +1721,7 @@ func doTestDeleteAndWaitForCompaction(t *testing.T, options config.DBOptions) {
 }
 
 func waitForManifestCondition(
-	sm store.StoredManifest,
+	sm *store.StoredManifest,
 	timeout time.Duration,
 	cond func(state *state.CoreStateSnapshot) bool,
 ) *state.CoreStateSnapshot {
@@ -624,3 +1756,35 @@ func testDBOptionsCompactor(minFilterKeys uint32, l0SSTSizeBytes uint64, compact
 func repeatedChar(ch rune, count int) []byte {
 	return []byte(strings.Repeat(string(ch), count))
 }
+
+func TestWriterFencedByNewerWriter(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	bucket := objstore.NewInMemBucket()
+	dbPath := "/tmp/test_kv_store"
+	db, err := OpenWithOptions(ctx, dbPath, bucket, testDBOptions(0, 1024))
+	require.NoError(t, err)
+	require.NoError(t, db.Put(ctx, []byte("key1"), []byte("value1")))
+
+	// opening a newer writer increments the writer epoch of the manifest
+	newer, err := OpenWithOptions(ctx, dbPath, bucket, testDBOptions(0, 1024))
+	require.NoError(t, err)
+	defer func() { _ = newer.Close(ctx) }()
+
+	// the older writer finds it has been fenced once it next loads the manifest
+	assert.Eventually(t, func() bool {
+		return errors.Is(db.HealthCheck(), ErrFenced)
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.ErrorIs(t, db.Put(ctx, []byte("key2"), []byte("value2")), ErrFenced)
+	assert.ErrorIs(t, db.Delete(ctx, []byte("key1")), ErrFenced)
+	assert.ErrorIs(t, db.FlushWAL(ctx), ErrFenced)
+	assert.ErrorIs(t, db.FlushMemtableToL0(), ErrFenced)
+	require.NoError(t, db.Close(ctx))
+
+	// the newer writer is unaffected
+	require.NoError(t, newer.Put(ctx, []byte("key2"), []byte("value2")))
+	value, err := newer.Get(ctx, []byte("key1"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("value1"), value)
+}
@@ -0,0 +1,71 @@
+package slatedb
+
+import (
+	"sync"
+
+	"github.com/slatedb/slatedb-go/slatedb/config"
+)
+
+// durableWatermark tracks the durable watermark of the database, see config.DBOptions.OnDurable
+type durableWatermark struct {
+	mu      sync.Mutex
+	current config.DurableWatermark
+
+	// notifyMu serializes the calls to config.DBOptions.OnDurable, and notified is the
+	// watermark most recently passed to it
+	notifyMu sync.Mutex
+	notified config.DurableWatermark
+}
+
+// DurableWatermark returns the most recent WAL SST uploaded to object storage and the most
+// recent manifest written by a memtable flush. See config.DBOptions.OnDurable to be notified
+// when the watermark advances.
+func (db *DB) DurableWatermark() config.DurableWatermark {
+	db.durable.mu.Lock()
+	defer db.durable.mu.Unlock()
+	return db.durable.current
+}
+
+// initDurable sets the watermark of a database which was opened without notifying OnDurable.
+// The WAL SSTs replayed when the database was opened are durable.
+func (db *DB) initDurable() {
+	w := config.DurableWatermark{ManifestID: db.manifest.ID()}
+	if next := db.state.NextWALID(); next > 0 {
+		w.WALID = next - 1
+	}
+	db.durable.mu.Lock()
+	db.durable.current = w
+	db.durable.mu.Unlock()
+
+	db.durable.notifyMu.Lock()
+	db.durable.notified = w
+	db.durable.notifyMu.Unlock()
+}
+
+// advanceDurable advances the durable watermark to include the WAL SST `walID` and the
+// manifest `manifestID`, either of which may be zero, and notifies OnDurable if it advanced
+func (db *DB) advanceDurable(walID uint64, manifestID uint64) {
+	d := &db.durable
+	d.mu.Lock()
+	w := config.DurableWatermark{
+		WALID:      max(d.current.WALID, walID),
+		ManifestID: max(d.current.ManifestID, manifestID),
+	}
+	advanced := w != d.current
+	d.current = w
+	d.mu.Unlock()
+	if !advanced || db.opts.OnDurable == nil {
+		return
+	}
+
+	// The watermark may have advanced again while waiting for the previous notification, in
+	// which case the latest watermark is delivered once, such that OnDurable never goes backward
+	d.notifyMu.Lock()
+	defer d.notifyMu.Unlock()
+	latest := db.DurableWatermark()
+	if latest == d.notified {
+		return
+	}
+	d.notified = latest
+	db.opts.OnDurable(latest)
+}
@@ -0,0 +1,238 @@
+package slatedb
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/samber/mo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore"
+
+	"github.com/slatedb/slatedb-go/slatedb/config"
+)
+
+func TestDurableWatermark(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	var mu sync.Mutex
+	var notified []config.DurableWatermark
+	bucket := objstore.NewInMemBucket()
+	options := testDBOptions(0, 1024)
+	options.OnDurable = func(w config.DurableWatermark) {
+		mu.Lock()
+		defer mu.Unlock()
+		notified = append(notified, w)
+	}
+	db, err := OpenWithOptions(ctx, "/tmp/test_kv_store", bucket, options)
+	require.NoError(t, err)
+	opened := db.DurableWatermark()
+
+	// The watermark includes the WAL of a write once the write is durable
+	require.NoError(t, db.Put(ctx, []byte("key1"), []byte("value1")))
+	afterPut := db.DurableWatermark()
+	assert.Greater(t, afterPut.WALID, opened.WALID)
+	assert.Equal(t, opened.ManifestID, afterPut.ManifestID)
+
+	require.NoError(t, db.FlushMemtableToL0())
+	afterFlush := db.DurableWatermark()
+	assert.Greater(t, afterFlush.ManifestID, afterPut.ManifestID)
+	require.NoError(t, db.Close(ctx))
+
+	mu.Lock()
+	require.NotEmpty(t, notified)
+	assert.Equal(t, afterPut.WALID, notified[0].WALID)
+	for i := 1; i < len(notified); i++ {
+		assert.GreaterOrEqual(t, notified[i].WALID, notified[i-1].WALID)
+		assert.GreaterOrEqual(t, notified[i].ManifestID, notified[i-1].ManifestID)
+		assert.NotEqual(t, notified[i], notified[i-1])
+	}
+	last := notified[len(notified)-1]
+	mu.Unlock()
+
+	// The WAL SSTs replayed when the database is opened are durable
+	db, err = OpenWithOptions(ctx, "/tmp/test_kv_store", bucket, testDBOptions(0, 1024))
+	require.NoError(t, err)
+	defer func() { _ = db.Close(ctx) }()
+	assert.GreaterOrEqual(t, db.DurableWatermark().WALID, last.WALID)
+	assert.Greater(t, db.DurableWatermark().ManifestID, last.ManifestID)
+}
+
+func TestMaxUnflushedWALBytes(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	options := testDBOptions(0, 1024*1024)
+	options.FlushInterval = time.Hour
+	options.MaxUnflushedWALBytes = 256
+	db, err := OpenWithOptions(ctx, "/tmp/test_kv_store", objstore.NewInMemBucket(), options)
+	require.NoError(t, err)
+	defer func() { _ = db.Close(ctx) }()
+	opened := db.DurableWatermark()
+
+	// A write below the size waits for the FlushInterval
+	noWait := config.WriteOptions{AwaitDurable: false}
+	require.NoError(t, db.PutWithOptions(ctx, []byte("key1"), repeatedChar('a', 64), noWait))
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, opened.WALID, db.DurableWatermark().WALID)
+
+	// The write which reaches the size flushes the WAL
+	require.NoError(t, db.PutWithOptions(ctx, []byte("key2"), repeatedChar('b', 256), noWait))
+	require.Eventually(t, func() bool {
+		return db.DurableWatermark().WALID > opened.WALID
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Zero(t, db.state.WAL().Size())
+}
+
+func TestWALDisabled(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	bucket := objstore.NewInMemBucket()
+	options := testDBOptions(0, 1024*1024)
+	options.WALEnabled = mo.Some(false)
+	db, err := OpenWithOptions(ctx, "/tmp/test_kv_store", bucket, options)
+	require.NoError(t, err)
+	opened := db.DurableWatermark()
+
+	// a write which does not await durability is visible but is not written to object storage
+	noWait := config.WriteOptions{AwaitDurable: false}
+	require.NoError(t, db.PutWithOptions(ctx, []byte("key1"), []byte("value1"), noWait))
+	value, err := db.Get(ctx, []byte("key1"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("value1"), value)
+	assert.Empty(t, db.state.L0())
+
+	// a write which awaits durability returns once the memtable is flushed to L0
+	require.NoError(t, db.Put(ctx, []byte("key2"), []byte("value2")))
+	assert.Len(t, db.state.L0(), 1)
+	assert.Greater(t, db.DurableWatermark().ManifestID, opened.ManifestID)
+	assert.Equal(t, opened.WALID, db.DurableWatermark().WALID)
+
+	batch := NewWriteBatch()
+	batch.Put([]byte("key3"), []byte("value3"))
+	require.NoError(t, db.WriteWithOptions(ctx, batch, noWait))
+	require.NoError(t, db.DeleteWithOptions(ctx, []byte("key1"), noWait))
+	require.NoError(t, db.Close(ctx))
+
+	// no WAL SSTs were written, and the writes were flushed to L0 by Close
+	var walSSTs []string
+	require.NoError(t, bucket.Iter(ctx, "/tmp/test_kv_store/wal/", func(name string) error {
+		walSSTs = append(walSSTs, name)
+		return nil
+	}))
+	assert.Empty(t, walSSTs)
+
+	db, err = OpenWithOptions(ctx, "/tmp/test_kv_store", bucket, testDBOptions(0, 1024*1024))
+	require.NoError(t, err)
+	defer func() { _ = db.Close(ctx) }()
+	_, err = db.Get(ctx, []byte("key1"))
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+	for _, key := range []string{"key2", "key3"} {
+		_, err := db.Get(ctx, []byte(key))
+		assert.NoError(t, err, key)
+	}
+}
+
+func TestLocalWAL(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	bucket := objstore.NewInMemBucket()
+	options := testDBOptions(0, 1024*1024)
+	options.FlushInterval = time.Hour
+	options.LocalWALDir = t.TempDir()
+	db, err := OpenWithOptions(ctx, "/tmp/test_kv_store", bucket, options)
+	require.NoError(t, err)
+	defer func() { _ = db.Close(ctx) }()
+	opened := db.DurableWatermark()
+
+	// writes which await durability return once they are synced to the local WAL
+	require.NoError(t, db.Put(ctx, []byte("key1"), []byte("value1")))
+	require.NoError(t, db.Put(ctx, []byte("key2"), []byte("value2")))
+	require.NoError(t, db.Delete(ctx, []byte("key2")))
+	batch := NewWriteBatch()
+	batch.Put([]byte("key3"), []byte("value3"))
+	batch.Put([]byte("key4"), []byte("value4"))
+	require.NoError(t, db.Write(ctx, batch))
+	assert.Equal(t, opened.WALID, db.DurableWatermark().WALID)
+	segments, err := os.ReadDir(options.LocalWALDir)
+	require.NoError(t, err)
+	assert.Len(t, segments, 1)
+
+	// a writer which opens the database before the WAL is written to object storage, such as
+	// the writer restarted after a crash, replays the writes from the local WAL
+	db2, err := OpenWithOptions(ctx, "/tmp/test_kv_store", bucket, options)
+	require.NoError(t, err)
+	for key, expected := range map[string]string{"key1": "value1", "key3": "value3", "key4": "value4"} {
+		value, err := db2.Get(ctx, []byte(key))
+		require.NoError(t, err)
+		assert.Equal(t, []byte(expected), value)
+	}
+	_, err = db2.Get(ctx, []byte("key2"))
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+	assert.Len(t, db2.state.L0(), 1)
+	segments, err = os.ReadDir(options.LocalWALDir)
+	require.NoError(t, err)
+	assert.Empty(t, segments)
+
+	// the segments of the WAL SSTs written to object storage are removed
+	require.NoError(t, db2.Put(ctx, []byte("key5"), []byte("value5")))
+	require.NoError(t, db2.Close(ctx))
+	segments, err = os.ReadDir(options.LocalWALDir)
+	require.NoError(t, err)
+	assert.Empty(t, segments)
+
+	options.WALEnabled = mo.Some(false)
+	_, err = OpenWithOptions(ctx, "/tmp/test_kv_store", bucket, options)
+	assert.Error(t, err)
+}
+
+func TestLocalWALFencedWriter(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	bucket := objstore.NewInMemBucket()
+	options := testDBOptions(0, 1024*1024)
+	options.FlushInterval = time.Hour
+	options.LocalWALDir = t.TempDir()
+	db, err := OpenWithOptions(ctx, "/tmp/test_kv_store", bucket, options)
+	require.NoError(t, err)
+	require.NoError(t, db.Put(ctx, []byte("key1"), []byte("value1")))
+	require.NoError(t, db.Put(ctx, []byte("key3"), []byte("value3")))
+
+	// a writer with another local WAL fences the writer, whose writes are not in object storage
+	newerOptions := options
+	newerOptions.LocalWALDir = t.TempDir()
+	newer, err := OpenWithOptions(ctx, "/tmp/test_kv_store", bucket, newerOptions)
+	require.NoError(t, err)
+	require.NoError(t, newer.Put(ctx, []byte("key1"), []byte("newer")))
+	require.NoError(t, newer.Close(ctx))
+
+	// writes synced to the local WAL are not acknowledged once the writer is fenced
+	assert.Eventually(t, func() bool {
+		return errors.Is(db.HealthCheck(), ErrFenced)
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.ErrorIs(t, db.Put(ctx, []byte("key2"), []byte("value2")), ErrFenced)
+	require.NoError(t, db.Close(ctx))
+
+	// a later writer with the local WAL of the fenced writer discards its writes
+	db, err = OpenWithOptions(ctx, "/tmp/test_kv_store", bucket, options)
+	require.NoError(t, err)
+	defer func() { _ = db.Close(ctx) }()
+	value, err := db.Get(ctx, []byte("key1"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("newer"), value)
+	for _, key := range []string{"key2", "key3"} {
+		_, err = db.Get(ctx, []byte(key))
+		assert.ErrorIs(t, err, ErrKeyNotFound)
+	}
+	segments, err := os.ReadDir(options.LocalWALDir)
+	require.NoError(t, err)
+	assert.Empty(t, segments)
+}
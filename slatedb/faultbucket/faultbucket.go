@@ -0,0 +1,350 @@
+// Package faultbucket provides an objstore.Bucket decorator which injects latency, errors and
+// bandwidth limits into bucket operations, such that the behavior of the database can be
+// tested under degraded object storage conditions.
+package faultbucket
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/thanos-io/objstore"
+)
+
+// ErrInjected is returned by operations which failed due to Fault.ErrorRate
+var ErrInjected = errors.New("injected object store error")
+
+// Op is a type of bucket operation
+type Op int
+
+const (
+	OpGet Op = iota + 1
+	OpGetRange
+	OpUpload
+	OpDelete
+	OpExists
+	OpAttributes
+	// OpIter applies to both Iter and IterWithAttributes
+	OpIter
+)
+
+func (o Op) String() string {
+	switch o {
+	case OpGet:
+		return "Get"
+	case OpGetRange:
+		return "GetRange"
+	case OpUpload:
+		return "Upload"
+	case OpDelete:
+		return "Delete"
+	case OpExists:
+		return "Exists"
+	case OpAttributes:
+		return "Attributes"
+	case OpIter:
+		return "Iter"
+	}
+	return "Unknown"
+}
+
+// ------------------------------------------------
+// Latency
+// ------------------------------------------------
+
+// Latency is a distribution from which the latency of each operation is sampled
+type Latency interface {
+	Sample(r *rand.Rand) time.Duration
+}
+
+type fixedLatency time.Duration
+
+func (f fixedLatency) Sample(*rand.Rand) time.Duration {
+	return time.Duration(f)
+}
+
+// FixedLatency delays every operation by `d`
+func FixedLatency(d time.Duration) Latency {
+	return fixedLatency(d)
+}
+
+type uniformLatency struct {
+	min, max time.Duration
+}
+
+func (u uniformLatency) Sample(r *rand.Rand) time.Duration {
+	if u.max <= u.min {
+		return u.min
+	}
+	return u.min + time.Duration(r.Int63n(int64(u.max-u.min)))
+}
+
+// UniformLatency delays each operation by a duration chosen uniformly from [min, max)
+func UniformLatency(min, max time.Duration) Latency {
+	return uniformLatency{min: min, max: max}
+}
+
+type normalLatency struct {
+	mean, stdDev time.Duration
+}
+
+func (n normalLatency) Sample(r *rand.Rand) time.Duration {
+	return max(0, n.mean+time.Duration(r.NormFloat64()*float64(n.stdDev)))
+}
+
+// NormalLatency delays each operation by a normally distributed duration. Negative
+// samples are treated as zero.
+func NormalLatency(mean, stdDev time.Duration) Latency {
+	return normalLatency{mean: mean, stdDev: stdDev}
+}
+
+// ------------------------------------------------
+// Bucket
+// ------------------------------------------------
+
+// Fault describes the degradation injected into a type of operation
+type Fault struct {
+	// Latency is the distribution the latency added to each operation is sampled from.
+	// If nil, no latency is added.
+	Latency Latency
+
+	// ErrorRate is the probability, between 0 and 1, that an operation fails with ErrInjected
+	ErrorRate float64
+
+	// BytesPerSecond caps the rate at which the data of a single Get, GetRange or Upload
+	// is transferred. If zero, the transfer rate is not limited.
+	BytesPerSecond int64
+}
+
+type Options struct {
+	// Faults are the faults injected into each type of operation. Operations
+	// without a Fault are passed to the wrapped bucket unchanged.
+	Faults map[Op]Fault
+
+	// Seed is used to seed the random number generator, such that the sampled
+	// latencies and injected errors are reproducible. If zero, a random seed is used.
+	Seed int64
+}
+
+// Bucket wraps an objstore.Bucket and injects the faults configured for each operation
+type Bucket struct {
+	objstore.Bucket
+
+	mu     sync.Mutex
+	faults map[Op]Fault
+	rng    *rand.Rand
+}
+
+// New returns a Bucket which injects the faults in `opts` into operations on `bucket`
+func New(bucket objstore.Bucket, opts Options) *Bucket {
+	seed := opts.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+
+	faults := make(map[Op]Fault, len(opts.Faults))
+	for op, fault := range opts.Faults {
+		faults[op] = fault
+	}
+	return &Bucket{
+		Bucket: bucket,
+		faults: faults,
+		rng:    rand.New(rand.NewSource(seed)),
+	}
+}
+
+// SetFault replaces the fault injected into operations of type `op`, such that the
+// object store can be degraded (or restored) while the database is running.
+func (b *Bucket) SetFault(op Op, fault Fault) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.faults[op] = fault
+}
+
+// ClearFaults stops injecting faults into all operations
+func (b *Bucket) ClearFaults() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.faults = make(map[Op]Fault)
+}
+
+// inject waits for the sampled latency of `op` and returns ErrInjected if the operation
+// should fail. It returns the bandwidth limit of the operation.
+func (b *Bucket) inject(ctx context.Context, op Op) (int64, error) {
+	b.mu.Lock()
+	fault, ok := b.faults[op]
+	var latency time.Duration
+	var fail bool
+	if ok {
+		if fault.Latency != nil {
+			latency = fault.Latency.Sample(b.rng)
+		}
+		fail = fault.ErrorRate > 0 && b.rng.Float64() < fault.ErrorRate
+	}
+	b.mu.Unlock()
+
+	if err := sleep(ctx, latency); err != nil {
+		return 0, err
+	}
+	if fail {
+		return 0, ErrInjected
+	}
+	return fault.BytesPerSecond, nil
+}
+
+func (b *Bucket) Get(ctx context.Context, name string) (io.ReadCloser, error) {
+	bps, err := b.inject(ctx, OpGet)
+	if err != nil {
+		return nil, err
+	}
+	rc, err := b.Bucket.Get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return throttle(ctx, rc, bps), nil
+}
+
+func (b *Bucket) GetRange(ctx context.Context, name string, off, length int64) (io.ReadCloser, error) {
+	bps, err := b.inject(ctx, OpGetRange)
+	if err != nil {
+		return nil, err
+	}
+	rc, err := b.Bucket.GetRange(ctx, name, off, length)
+	if err != nil {
+		return nil, err
+	}
+	return throttle(ctx, rc, bps), nil
+}
+
+// Unwrap returns the bucket into which faults are injected
+func (b *Bucket) Unwrap() objstore.Bucket {
+	return b.Bucket
+}
+
+func (b *Bucket) Upload(ctx context.Context, name string, r io.Reader) error {
+	bps, err := b.inject(ctx, OpUpload)
+	if err != nil {
+		return err
+	}
+	return b.Bucket.Upload(ctx, name, throttle(ctx, io.NopCloser(r), bps))
+}
+
+// UploadIfNotExists implements store.ConditionalBucket, and fails with the faults of OpUpload. If
+// the wrapped bucket is not a store.ConditionalBucket, the existence of the object is checked
+// with Exists before it is uploaded, as it is for a bucket which is not a store.ConditionalBucket.
+func (b *Bucket) UploadIfNotExists(ctx context.Context, name string, r io.Reader) (bool, error) {
+	cb, ok := b.Bucket.(interface {
+		UploadIfNotExists(ctx context.Context, name string, r io.Reader) (bool, error)
+	})
+	if !ok {
+		exists, err := b.Exists(ctx, name)
+		if err != nil || exists {
+			return false, err
+		}
+		return true, b.Upload(ctx, name, r)
+	}
+	bps, err := b.inject(ctx, OpUpload)
+	if err != nil {
+		return false, err
+	}
+	return cb.UploadIfNotExists(ctx, name, throttle(ctx, io.NopCloser(r), bps))
+}
+
+func (b *Bucket) Delete(ctx context.Context, name string) error {
+	if _, err := b.inject(ctx, OpDelete); err != nil {
+		return err
+	}
+	return b.Bucket.Delete(ctx, name)
+}
+
+func (b *Bucket) Exists(ctx context.Context, name string) (bool, error) {
+	if _, err := b.inject(ctx, OpExists); err != nil {
+		return false, err
+	}
+	return b.Bucket.Exists(ctx, name)
+}
+
+func (b *Bucket) Attributes(ctx context.Context, name string) (objstore.ObjectAttributes, error) {
+	if _, err := b.inject(ctx, OpAttributes); err != nil {
+		return objstore.ObjectAttributes{}, err
+	}
+	return b.Bucket.Attributes(ctx, name)
+}
+
+func (b *Bucket) Iter(ctx context.Context, dir string, f func(string) error, options ...objstore.IterOption) error {
+	if _, err := b.inject(ctx, OpIter); err != nil {
+		return err
+	}
+	return b.Bucket.Iter(ctx, dir, f, options...)
+}
+
+func (b *Bucket) IterWithAttributes(ctx context.Context, dir string, f func(objstore.IterObjectAttributes) error,
+	options ...objstore.IterOption) error {
+	if _, err := b.inject(ctx, OpIter); err != nil {
+		return err
+	}
+	return b.Bucket.IterWithAttributes(ctx, dir, f, options...)
+}
+
+// ------------------------------------------------
+// Throttling
+// ------------------------------------------------
+
+// throttledReader limits the rate at which data is read from the wrapped reader
+type throttledReader struct {
+	ctx   context.Context
+	rc    io.ReadCloser
+	bps   int64
+	start time.Time
+	read  int64
+}
+
+func throttle(ctx context.Context, rc io.ReadCloser, bytesPerSecond int64) io.ReadCloser {
+	if bytesPerSecond <= 0 {
+		return rc
+	}
+	return &throttledReader{ctx: ctx, rc: rc, bps: bytesPerSecond}
+}
+
+func (t *throttledReader) Read(p []byte) (int, error) {
+	if t.start.IsZero() {
+		t.start = time.Now()
+	}
+	// Read at most one tenth of a second of data at a time, such that
+	// the transfer proceeds smoothly rather than in large bursts
+	if chunk := max(1, t.bps/10); int64(len(p)) > chunk {
+		p = p[:chunk]
+	}
+
+	n, err := t.rc.Read(p)
+	t.read += int64(n)
+
+	// Wait until the elapsed time matches the time the bytes read so far should have taken
+	expected := time.Duration(float64(t.read) / float64(t.bps) * float64(time.Second))
+	if serr := sleep(t.ctx, expected-time.Since(t.start)); serr != nil {
+		return n, serr
+	}
+	return n, err
+}
+
+func (t *throttledReader) Close() error {
+	return t.rc.Close()
+}
+
+// sleep waits for `d` or until the context is cancelled
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,96 @@
+package faultbucket
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore"
+)
+
+func TestFaultBucketErrorRate(t *testing.T) {
+	ctx := context.Background()
+	inner := objstore.NewInMemBucket()
+	require.NoError(t, inner.Upload(ctx, "obj", bytes.NewReader([]byte("data"))))
+
+	bucket := New(inner, Options{
+		Faults: map[Op]Fault{OpGet: {ErrorRate: 1}},
+		Seed:   1,
+	})
+	for i := 0; i < 10; i++ {
+		_, err := bucket.Get(ctx, "obj")
+		assert.ErrorIs(t, err, ErrInjected)
+	}
+
+	// operations without a fault are unaffected
+	exists, err := bucket.Exists(ctx, "obj")
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	bucket.SetFault(OpGet, Fault{ErrorRate: 0})
+	rc, err := bucket.Get(ctx, "obj")
+	require.NoError(t, err)
+	data, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("data"), data)
+	require.NoError(t, rc.Close())
+}
+
+func TestFaultBucketLatency(t *testing.T) {
+	ctx := context.Background()
+	bucket := New(objstore.NewInMemBucket(), Options{
+		Faults: map[Op]Fault{OpUpload: {Latency: FixedLatency(50 * time.Millisecond)}},
+	})
+
+	start := time.Now()
+	require.NoError(t, bucket.Upload(ctx, "obj", bytes.NewReader([]byte("data"))))
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+
+	// the latency is abandoned when the context is cancelled
+	bucket.SetFault(OpUpload, Fault{Latency: FixedLatency(time.Minute)})
+	ctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	err := bucket.Upload(ctx, "obj", bytes.NewReader([]byte("data")))
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	bucket.ClearFaults()
+	start = time.Now()
+	require.NoError(t, bucket.Upload(context.Background(), "obj", bytes.NewReader([]byte("data"))))
+	assert.Less(t, time.Since(start), time.Minute)
+}
+
+func TestFaultBucketBandwidth(t *testing.T) {
+	ctx := context.Background()
+	inner := objstore.NewInMemBucket()
+	require.NoError(t, inner.Upload(ctx, "obj", bytes.NewReader(make([]byte, 2000))))
+
+	bucket := New(inner, Options{
+		Faults: map[Op]Fault{OpGetRange: {BytesPerSecond: 10_000}},
+	})
+
+	// reading 1000 bytes at 10KB/s takes at least 100ms
+	start := time.Now()
+	rc, err := bucket.GetRange(ctx, "obj", 0, 1000)
+	require.NoError(t, err)
+	data, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	require.NoError(t, rc.Close())
+	assert.Len(t, data, 1000)
+	assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+}
+
+func TestLatencyDistributions(t *testing.T) {
+	bucket := New(objstore.NewInMemBucket(), Options{Seed: 1})
+	for i := 0; i < 100; i++ {
+		d := UniformLatency(10*time.Millisecond, 20*time.Millisecond).Sample(bucket.rng)
+		assert.GreaterOrEqual(t, d, 10*time.Millisecond)
+		assert.Less(t, d, 20*time.Millisecond)
+
+		assert.GreaterOrEqual(t, NormalLatency(time.Millisecond, 10*time.Millisecond).Sample(bucket.rng),
+			time.Duration(0))
+	}
+}
@@ -0,0 +1,96 @@
+package slatedb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/oklog/ulid/v2"
+
+	"github.com/slatedb/slatedb-go/internal/sstable"
+	"github.com/slatedb/slatedb-go/internal/sstable/bloom"
+	"github.com/slatedb/slatedb-go/slatedb/state"
+)
+
+// RebuildFiltersReport summarizes the filters rebuilt by RebuildFilters
+type RebuildFiltersReport struct {
+	// The number of L0 and compacted SSTs whose keys were read to build a filter
+	SSTs int
+
+	// The number of filters which were written to a sidecar filter object and recorded in the
+	// manifest. SSTs with fewer keys than DBOptions.MinFilterKeys are not given a filter.
+	Filters int
+}
+
+// RebuildFilters rebuilds the bloom filter of every L0 and compacted SST using the filter options
+// the database was opened with, such as after filters are enabled for a database which was created
+// with a MinFilterKeys of math.MaxUint32. The SSTs are not rewritten; each filter is written to a
+// sidecar filter object alongside its SST, and the sidecars are recorded in the manifest such that
+// every process which opens the database afterward reads the filter from the sidecar.
+//
+// Rebuilding a filter reads every block of the SST. SSTs written after RebuildFilters is called,
+// including the output of compactions, are built with the filter options of their writer.
+func (db *DB) RebuildFilters(ctx context.Context) (RebuildFiltersReport, error) {
+	var report RebuildFiltersReport
+	if db.opts.ReadOnly {
+		return report, ErrReadOnly
+	}
+	if err := db.writeErr(); err != nil {
+		return report, err
+	}
+
+	core := db.state.CoreStateSnapshot()
+	ssts := append([]sstable.Handle{}, core.L0...)
+	for _, sr := range core.Compacted {
+		ssts = append(ssts, sr.SSTList...)
+	}
+
+	log := db.opts.Log.With("rebuild_id", ulid.Make().String())
+	filters := make(map[ulid.ULID]bloom.Filter)
+	ids := make([]ulid.ULID, 0, len(ssts))
+	for _, sst := range ssts {
+		report.SSTs++
+		filter, err := db.tableStore.RebuildFilter(ctx, &sst)
+		if err != nil {
+			return report, fmt.Errorf("while rebuilding filter of sst '%s': %w", sst.Id.String(), err)
+		}
+		f, ok := filter.Get()
+		if !ok {
+			continue
+		}
+		if err := db.tableStore.WriteFilterSidecar(ctx, sst.Id, f); err != nil {
+			return report, fmt.Errorf("while writing filter of sst '%s': %w", sst.Id.String(), err)
+		}
+		id, _ := sst.Id.CompactedID().Get()
+		filters[id] = f
+		ids = append(ids, id)
+	}
+	if len(ids) == 0 {
+		return report, nil
+	}
+
+	flusher := MemtableFlusher{
+		db:       db,
+		manifest: db.manifest,
+		log:      db.opts.Log,
+	}
+	err := db.manifest.AddFilterSidecars(log, ids, func() (*state.CoreStateSnapshot, error) {
+		if err := flusher.loadManifest(); err != nil {
+			return nil, err
+		}
+		return db.state.CoreStateSnapshot(), nil
+	})
+	if err != nil {
+		log.Error("failed to write manifest", "error", err)
+		return report, err
+	}
+
+	// The sidecars of SSTs which were compacted while their filter was rebuilt are not recorded
+	for _, id := range db.manifest.FilterSidecars() {
+		if f, ok := filters[id]; ok {
+			db.tableStore.UseFilterSidecar(sstable.NewIDCompacted(id), f)
+			report.Filters++
+		}
+	}
+	log.Info("rebuilt filters", "ssts", report.SSTs, "filters", report.Filters)
+	return report, nil
+}
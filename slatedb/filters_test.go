@@ -0,0 +1,56 @@
+package slatedb
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore"
+)
+
+func TestRebuildFilters(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	// Filters are disabled when the database is created
+	bucket := objstore.NewInMemBucket()
+	dbPath := "/tmp/test_kv_store"
+	db, err := OpenWithOptions(ctx, dbPath, bucket, testDBOptions(math.MaxUint32, 1024))
+	require.NoError(t, err)
+	require.NoError(t, db.Put(ctx, []byte("key1"), []byte("value1")))
+	require.NoError(t, db.Put(ctx, []byte("key2"), []byte("value2")))
+	require.NoError(t, db.FlushMemtableToL0())
+	l0 := db.state.CoreStateSnapshot().L0
+	require.Len(t, l0, 1)
+	filter, err := db.tableStore.ReadFilter(ctx, &l0[0])
+	require.NoError(t, err)
+	assert.True(t, filter.IsAbsent())
+	require.NoError(t, db.Close(ctx))
+
+	db, err = OpenWithOptions(ctx, dbPath, bucket, testDBOptions(0, 1024))
+	require.NoError(t, err)
+	report, err := db.RebuildFilters(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, RebuildFiltersReport{SSTs: 1, Filters: 1}, report)
+	filter, err = db.tableStore.ReadFilter(ctx, &l0[0])
+	require.NoError(t, err)
+	f := filter.MustGet()
+	assert.True(t, f.HasKey([]byte("key1")))
+	require.NoError(t, db.Close(ctx))
+
+	// The filter is read from the sidecar once the database is reopened
+	db, err = OpenWithOptions(ctx, dbPath, bucket, testDBOptions(0, 1024))
+	require.NoError(t, err)
+	defer func() { _ = db.Close(ctx) }()
+	assert.Len(t, db.manifest.FilterSidecars(), 1)
+	filter, err = db.tableStore.ReadFilter(ctx, &l0[0])
+	require.NoError(t, err)
+	f = filter.MustGet()
+	assert.True(t, f.HasKey([]byte("key2")))
+	value, err := db.Get(ctx, []byte("key2"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("value2"), value)
+}
@@ -2,27 +2,40 @@ package slatedb
 
 import (
 	"context"
-	"errors"
 	"log/slog"
-	"sync"
-	"time"
 
 	"github.com/oklog/ulid/v2"
-	"github.com/slatedb/slatedb-go/internal"
+	"github.com/slatedb/slatedb-go/internal/profile"
 	"github.com/slatedb/slatedb-go/internal/sstable"
+	"github.com/slatedb/slatedb-go/internal/task"
+	"github.com/slatedb/slatedb-go/internal/tracing"
+	"github.com/slatedb/slatedb-go/internal/types"
+	"github.com/slatedb/slatedb-go/slatedb/audit"
+	"github.com/slatedb/slatedb-go/slatedb/config"
+	"github.com/slatedb/slatedb-go/slatedb/state"
 	"github.com/slatedb/slatedb-go/slatedb/store"
 	"github.com/slatedb/slatedb-go/slatedb/table"
+	"go.opentelemetry.io/otel/trace"
 )
 
-func (db *DB) spawnWALFlushTask(walFlushNotifierCh <-chan context.Context, walFlushTaskWG *sync.WaitGroup) {
-	walFlushTaskWG.Add(1)
-	go func() {
-		defer walFlushTaskWG.Done()
-		ticker := time.NewTicker(db.opts.FlushInterval)
+func (db *DB) spawnWALFlushTask(walFlushNotifierCh <-chan context.Context) *task.Handle {
+	return db.tasks.Go("wal_flush", func(taskCtx context.Context) error {
+		ticker := db.newIdleTicker(db.opts.FlushInterval)
 		defer ticker.Stop()
 		for {
 			select {
-			case <-ticker.C:
+			case <-ticker.C():
+				ctx, cancel := context.WithTimeout(context.Background(), db.opts.FlushInterval)
+				if err := db.flushOnTick(ctx); err != nil {
+					db.opts.Log.Warn("Flush WAL failed", "error", err)
+				}
+				cancel()
+				// the WAL is flushed before the database goes idle, such that no write waits on the paused ticker
+				db.maybeEnterIdle()
+				ticker.pauseIfIdle()
+			case <-ticker.Woken():
+				ticker.resume()
+			case <-db.walFlushRequestCh:
 				ctx, cancel := context.WithTimeout(context.Background(), db.opts.FlushInterval)
 				if err := db.FlushWAL(ctx); err != nil {
 					db.opts.Log.Warn("Flush WAL failed", "error", err)
@@ -32,26 +45,107 @@ func (db *DB) spawnWALFlushTask(walFlushNotifierCh <-chan context.Context, walFl
 				if err := db.FlushWAL(ctx); err != nil {
 					db.opts.Log.Warn("Flush WAL failed", "error", err)
 				}
-				return
+				return nil
+			case <-taskCtx.Done():
+				return nil
 			}
 		}
-	}()
+	})
+}
+
+// requestWALFlush asks the WAL flush task to flush the WAL now rather than at the next FlushInterval
+func (db *DB) requestWALFlush() {
+	select {
+	case db.walFlushRequestCh <- struct{}{}:
+	default:
+		// a flush has already been requested
+	}
+}
+
+// maybeRollWAL asks the WAL flush task to freeze and flush `wal` if it holds at least
+// DBOptions.MaxUnflushedWALBytes, rather than waiting for the next FlushInterval
+func (db *DB) maybeRollWAL(wal *table.WAL) {
+	if db.opts.MaxUnflushedWALBytes > 0 && wal.Size() >= int64(db.opts.MaxUnflushedWALBytes) {
+		db.requestWALFlush()
+	}
 }
 
 // FlushWAL
 // 1. Convert mutable WAL to Immutable WAL
 // 2. Flush each Immutable WAL to object store and then to memtable
+//
+// If the WAL is disabled, the memtable is flushed to L0 instead, such that every write is
+// durable once FlushWAL returns. See config.DBOptions.WALEnabled
 func (db *DB) FlushWAL(ctx context.Context) error {
+	if db.opts.ReadOnly {
+		return ErrReadOnly
+	}
+	if db.fenced.Load() {
+		return ErrFenced
+	}
+	if !db.walEnabled() {
+		if db.state.Memtable().Size() > 0 {
+			db.state.FreezeMemtable(db.memtableWALID())
+		}
+		flusher := MemtableFlusher{
+			db:       db,
+			manifest: db.manifest,
+			log:      db.opts.Log,
+		}
+		return flusher.flushImmMemtablesToL0()
+	}
 	db.state.FreezeWAL()
 	return db.flushImmWALs(ctx)
 }
 
+// flushOnTick is called by the WAL flush task every FlushInterval. If the WAL is disabled, the
+// memtable is flushed to L0 only if a write awaits durability, and is otherwise frozen once it
+// reaches L0SSTSizeBytes as it would be when the WAL is flushed to it.
+func (db *DB) flushOnTick(ctx context.Context) error {
+	if db.walEnabled() || db.memtableFlushRequested.Swap(false) {
+		return db.FlushWAL(ctx)
+	}
+	db.maybeFreezeMemtable(db.state, db.memtableWALID())
+	return nil
+}
+
+// walEnabled returns false if writes bypass the WAL, see config.DBOptions.WALEnabled
+func (db *DB) walEnabled() bool {
+	return db.opts.WALEnabled.OrElse(true)
+}
+
+// memtableWALID returns the ID of the last WAL SST whose writes are held by the memtable or
+// by L0, with which the memtable is frozen
+func (db *DB) memtableWALID() uint64 {
+	return db.state.Memtable().LastWalID().OrElse(db.state.LastCompactedWALID())
+}
+
+// writeMemtable adds a write directly to the memtable with `write` while the WAL is disabled.
+// If the write awaits durability, it waits for the memtable to be flushed to L0, which is
+// requested for the next FlushInterval.
+func (db *DB) writeMemtable(ctx context.Context, options config.WriteOptions, write func() *table.Memtable) error {
+	db.writeMu.RLock()
+	memtable := write()
+	db.writeMu.RUnlock()
+	if !options.AwaitDurable {
+		return nil
+	}
+	db.memtableFlushRequested.Store(true)
+	if db.inMemory {
+		db.requestWALFlush()
+	}
+	return memtable.Table().AwaitWALFlush(ctx)
+}
+
 // For each Immutable WAL
 // Flush Immutable WAL to Object store
 // Flush Immutable WAL to mutable Memtable
 // If memtable has reached size L0SSTBytes then convert memtable to Immutable memtable
 // Notify any client(with AwaitDurable set to true) that flush has happened
-func (db *DB) flushImmWALs(ctx context.Context) error {
+func (db *DB) flushImmWALs(ctx context.Context) (err error) {
+	db.walFlushMu.Lock()
+	defer db.walFlushMu.Unlock()
+	defer func() { db.recordFlush("wal_flush", err) }()
 	for {
 		oldestWal := db.state.OldestImmWAL()
 		if oldestWal.IsAbsent() {
@@ -60,51 +154,142 @@ func (db *DB) flushImmWALs(ctx context.Context) error {
 
 		immWal := oldestWal.MustGet()
 		// Flush Immutable WAL to Object store
-		_, err := db.flushImmWAL(ctx, immWal)
+		_, err = db.flushImmWAL(ctx, immWal)
 		if err != nil {
 			return err
 		}
-		db.state.PopImmWAL()
-
+		// The WAL ID identifies a WAL flush in the logs
+		db.opts.Log.Debug("flushed WAL", "wal_id", immWal.ID())
 		// flush to the memtable before notifying so that data is available for reads
-		db.flushImmWALToMemtable(immWal, db.state.Memtable())
+		db.state.MoveImmWALToMemtable(immWal)
+		db.advanceDurable(immWal.ID(), 0)
+		if db.localWAL != nil {
+			if err := db.localWAL.RemoveThrough(immWal.ID()); err != nil {
+				db.opts.Log.Warn("failed to remove local WAL segment", "wal_id", immWal.ID(), "error", err)
+			}
+		}
+
+		db.auditImmWAL(immWal)
 		db.maybeFreezeMemtable(db.state, immWal.ID())
 		immWal.Table().NotifyWALFlushed()
 	}
 	return nil
 }
 
-func (db *DB) flushImmWAL(ctx context.Context, immWAL *table.ImmutableWAL) (*sstable.Handle, error) {
+func (db *DB) flushImmWAL(ctx context.Context, immWAL *table.ImmutableWAL) (sst *sstable.Handle, err error) {
 	walID := sstable.NewIDWal(immWAL.ID())
-	return db.flushImmTable(ctx, walID, immWAL.Iter())
+	start := db.opts.Clock.Now()
+	ctx, span := db.tracer.Start(ctx, "slatedb.WALFlush", trace.WithAttributes(
+		tracing.KeyWALID.Int64(int64(immWAL.ID())), tracing.KeySSTID.String(walID.String())))
+	defer func() { tracing.End(span, err) }()
+	db.profiler.Do(profile.WorkWALFlush, func(p *profile.Profile) {
+		sst, err = db.flushImmTable(ctx, walID, immWAL.Iter(), immWAL.RangeTombstones(nil, nil), p)
+	})
+	if err == nil {
+		observeSince(db.prom.walFlushDuration, db.opts.Clock, start)
+		db.prom.walFlushBytes.Add(float64(sst.Info.EncodedSize()))
+		span.SetAttributes(tracing.KeyBytes.Int64(int64(sst.Info.EncodedSize())))
+		db.opts.EventListener.OnWALFlushed(config.WALFlushedEvent{
+			WALID:    immWAL.ID(),
+			Bytes:    sst.Info.EncodedSize(),
+			Duration: db.opts.Clock.Now().Sub(start),
+		})
+	}
+	return sst, err
 }
 
-func (db *DB) flushImmWALToMemtable(immWal *table.ImmutableWAL, memtable *table.Memtable) {
-	iter := immWal.Iter()
+// auditImmWAL sends an audit.Record for each entry of the committed WAL to the configured AuditSink
+func (db *DB) auditImmWAL(immWal *table.ImmutableWAL) {
+	if db.opts.AuditSink == nil {
+		return
+	}
+	db.auditTable(immWal.ID(), immWal.Iter(), immWal.RangeTombstones(nil, nil))
+}
+
+// auditImmMemtable sends an audit.Record for each entry of the memtable flushed to L0 to the
+// configured AuditSink, if the WAL is disabled. Otherwise the entries were audited with their WAL.
+func (db *DB) auditImmMemtable(immMemtable *table.ImmutableMemtable) {
+	if db.opts.AuditSink == nil || db.walEnabled() {
+		return
+	}
+	db.auditTable(0, immMemtable.Iter(), immMemtable.RangeTombstones(nil, nil))
+}
+
+// auditTable sends an audit.Record for each entry of `iter` and each range tombstone, which were
+// committed with the WAL `walID`, or without a WAL if zero, to the configured AuditSink
+func (db *DB) auditTable(walID uint64, iter *table.KVTableIterator, rangeTombstones []types.RangeTombstone) {
+	now := db.opts.Clock.Now()
+	records := make([]audit.Record, 0)
 	for {
 		entry, err := iter.NextEntry()
 		if err != nil || entry.IsAbsent() {
 			break
 		}
 		e, _ := entry.Get()
-		memtable.Put(e)
+		op := audit.OpPut
+		if e.Value.IsTombstone() {
+			op = audit.OpDelete
+		}
+		records = append(records, audit.Record{
+			KeyHash:     audit.HashKey(e.Key),
+			Op:          op,
+			WriterID:    db.opts.AuditWriterID,
+			WriterEpoch: db.manifest.Epoch(),
+			Timestamp:   now,
+			WALID:       walID,
+			Seq:         e.Seq,
+		})
+	}
+	// The hash of a range tombstone is the hash of the start of the range
+	for _, t := range rangeTombstones {
+		records = append(records, audit.Record{
+			KeyHash:     audit.HashKey(t.Start),
+			Op:          audit.OpDeleteRange,
+			WriterID:    db.opts.AuditWriterID,
+			WriterEpoch: db.manifest.Epoch(),
+			Timestamp:   now,
+			WALID:       walID,
+			Seq:         t.Seq,
+		})
+	}
+	if len(records) > 0 {
+		db.opts.AuditSink.Write(records)
 	}
-	memtable.SetLastWalID(immWal.ID())
 }
 
-func (db *DB) flushImmTable(ctx context.Context, id sstable.ID, iter *table.KVTableIterator) (*sstable.Handle, error) {
-	sstBuilder := db.tableStore.TableBuilder()
+// auditL0 sends `records`, of the mutations committed to L0 with the sequence number `seq`
+// without a WAL, to the configured AuditSink
+func (db *DB) auditL0(records []audit.Record, seq uint64) {
+	if db.opts.AuditSink == nil || len(records) == 0 {
+		return
+	}
+	now := db.opts.Clock.Now()
+	for i := range records {
+		records[i].WriterID = db.opts.AuditWriterID
+		records[i].WriterEpoch = db.manifest.Epoch()
+		records[i].Timestamp = now
+		records[i].Seq = seq
+	}
+	db.opts.AuditSink.Write(records)
+}
+
+// flushImmTable writes the entries of `iter` and the range tombstones to the WAL SST `id`,
+// recording the stages of the flush with `p`
+func (db *DB) flushImmTable(ctx context.Context, id sstable.ID, iter *table.KVTableIterator,
+	rangeTombstones []types.RangeTombstone, p *profile.Profile) (*sstable.Handle, error) {
+	defer p.Exit(p.Enter(profile.StageEncode))
+	sstBuilder := db.tableStore.TableBuilderAtLevel(store.SSTLevelWAL)
+	sstBuilder.SetProfile(p)
+	for _, t := range rangeTombstones {
+		sstBuilder.AddRangeTombstone(t)
+	}
 	for {
 		entry, err := iter.NextEntry()
 		if err != nil || entry.IsAbsent() {
 			break
 		}
 		kv, _ := entry.Get()
-		var val []byte
-		if !kv.Value.IsTombstone() {
-			val = kv.Value.Value
-		}
-		err = sstBuilder.AddValue(kv.Key, val)
+		err = sstBuilder.Add(kv.Key, kv)
 		if err != nil {
 			return nil, err
 		}
@@ -115,7 +300,9 @@ func (db *DB) flushImmTable(ctx context.Context, id sstable.ID, iter *table.KVTa
 		return nil, err
 	}
 
+	prev := p.Enter(profile.StageUpload)
 	sst, err := db.tableStore.WriteSST(ctx, id, encodedSST)
+	p.Exit(prev)
 	if err != nil {
 		return nil, err
 	}
@@ -123,6 +310,84 @@ func (db *DB) flushImmTable(ctx context.Context, id sstable.ID, iter *table.KVTa
 	return sst, nil
 }
 
+// flushImmMemtable writes the entries and range tombstones of the memtable to L0 SSTs, recording
+// the stages of the flush with `p`, and returns the SSTs in key order. The keys of each keyspace
+// of config.DBOptions.Keyspaces are written to SSTs of their own, which are compressed with the
+// codec of the keyspace and split at its config.KeyspaceOptions.L0SSTSizeBytes. The memtable of a
+// database without such keyspaces is written to a single SST.
+func (db *DB) flushImmMemtable(ctx context.Context, immMemtable *table.ImmutableMemtable,
+	p *profile.Profile) ([]sstable.Handle, error) {
+	defer p.Exit(p.Enter(profile.StageEncode))
+	ssts := make([]sstable.Handle, 0, 1)
+	var builder *sstable.Builder
+	var keyspace string
+	var size uint64
+	newBuilder := func(ks string) {
+		builder = db.tableStore.TableBuilderForKeyspace(store.SSTLevelL0, ks)
+		builder.SetProfile(p)
+		keyspace = ks
+		// The range tombstones of L0 SSTs are read from the info of every SST, as such they
+		// are written to the first SST
+		if len(ssts) == 0 {
+			for _, t := range immMemtable.RangeTombstones(nil, nil) {
+				builder.AddRangeTombstone(t)
+			}
+		}
+	}
+	writeSST := func() error {
+		encodedSST, err := builder.Build()
+		if err != nil {
+			return err
+		}
+		prev := p.Enter(profile.StageUpload)
+		sst, err := db.tableStore.WriteSST(ctx, sstable.NewIDCompacted(ulid.Make()), encodedSST)
+		p.Exit(prev)
+		if err != nil {
+			return err
+		}
+		ssts = append(ssts, *sst)
+		builder, size = nil, 0
+		return nil
+	}
+
+	iter := immMemtable.Iter()
+	for {
+		entry, err := iter.NextEntry()
+		if err != nil || entry.IsAbsent() {
+			break
+		}
+		kv, _ := entry.Get()
+		ks, _ := config.KeyspaceOf(db.opts.Keyspaces, kv.Key)
+		if builder != nil && ks != keyspace {
+			if err := writeSST(); err != nil {
+				return nil, err
+			}
+		}
+		if builder == nil {
+			newBuilder(ks)
+		}
+		if err := builder.Add(kv.Key, kv); err != nil {
+			return nil, err
+		}
+		size += uint64(len(kv.Key) + len(kv.Value.Value))
+		if limit := db.l0SSTSize(ks, 0); limit > 0 && size >= limit {
+			if err := writeSST(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	// A memtable of range tombstones alone is written to an SST without entries
+	if builder == nil && len(ssts) == 0 {
+		newBuilder("")
+	}
+	if builder != nil {
+		if err := writeSST(); err != nil {
+			return nil, err
+		}
+	}
+	return ssts, nil
+}
+
 // ------------------------------------------------
 // MemtableFlusher
 // ------------------------------------------------
@@ -130,46 +395,58 @@ func (db *DB) flushImmTable(ctx context.Context, id sstable.ID, iter *table.KVTa
 func (db *DB) spawnMemtableFlushTask(
 	manifest *store.FenceableManifest,
 	memtableFlushNotifierCh <-chan MemtableFlushThreadMsg,
-	memtableFlushTaskWG *sync.WaitGroup,
-) {
-	memtableFlushTaskWG.Add(1)
+) *task.Handle {
+	// isShutdown is declared outside the task such that it survives a restart of the task
 	isShutdown := false
-	go func() {
-		defer memtableFlushTaskWG.Done()
+	return db.tasks.Go("memtable_flush", func(taskCtx context.Context) error {
 		flusher := MemtableFlusher{
 			log:      db.opts.Log,
 			manifest: manifest,
 			db:       db,
 		}
-		ticker := time.NewTicker(db.opts.ManifestPollInterval)
-		defer ticker.Stop()
+		// The manifest is never polled when ManifestPollInterval is zero
+		var poll *idleTicker
+		if db.opts.ManifestPollInterval > 0 {
+			poll = db.newIdleTicker(db.opts.ManifestPollInterval)
+			defer poll.Stop()
+		}
 
 		// Stop the loop when the shut down has been received and all
 		// remaining memtableFlushNotifierCh channel is drained.
 		for !(isShutdown && len(memtableFlushNotifierCh) == 0) {
 			select {
-			case <-ticker.C:
+			case <-poll.C():
 				err := flusher.loadManifest()
 				if err != nil {
 					db.opts.Log.Error("error load manifest", "error", err)
 				}
+				if err := db.persistSSTAccessStats(); err != nil {
+					db.opts.Log.Warn("failed to persist SST access stats", "error", err)
+				}
+				poll.pauseIfIdle()
+			case <-poll.Woken():
+				poll.resume()
 			case val := <-memtableFlushNotifierCh:
 				if val == Shutdown {
 					isShutdown = true
 				} else if val == FlushImmutableMemtables {
-					err := flusher.flushImmMemtablesToL0()
-					if err != nil {
-						db.opts.Log.Error("error flushing memtable", "error", err)
-					}
+					// failures are logged along with the flush ID by flushImmMemtablesToL0
+					_ = flusher.flushImmMemtablesToL0()
 				}
+			case <-taskCtx.Done():
+				return nil
 			}
 		}
 
-		err := flusher.writeManifestSafely()
+		err := flusher.writeManifestSafely(db.opts.Log)
 		if err != nil {
 			db.opts.Log.Error("error writing manifest on shutdown", "error", err)
 		}
-	}()
+		if err := db.persistSSTAccessStats(); err != nil {
+			db.opts.Log.Warn("failed to persist SST access stats", "error", err)
+		}
+		return nil
+	})
 }
 
 type MemtableFlushThreadMsg int
@@ -188,55 +465,95 @@ type MemtableFlusher struct {
 func (m *MemtableFlusher) loadManifest() error {
 	currentManifest, err := m.manifest.Refresh()
 	if err != nil {
-		return err
+		return m.db.checkFenced(err)
 	}
 	m.db.state.RefreshDBState(currentManifest)
 	return nil
 }
 
-func (m *MemtableFlusher) writeManifest() error {
-	core := m.db.state.CoreStateSnapshot()
-	return m.manifest.UpdateDBState(core)
-}
-
-func (m *MemtableFlusher) writeManifestSafely() error {
-	for {
-		err := m.loadManifest()
-		if err != nil {
-			return err
+func (m *MemtableFlusher) writeManifestSafely(log *slog.Logger) error {
+	err := m.manifest.UpdateDBStateWithRetry(log, func() (*state.CoreStateSnapshot, error) {
+		if err := m.loadManifest(); err != nil {
+			return nil, err
 		}
+		return m.db.state.CoreStateSnapshot(), nil
+	})
+	if err != nil {
+		return m.db.checkFenced(err)
+	}
+	m.db.advanceDurable(0, m.manifest.ID())
 
-		err = m.writeManifest()
-		if errors.Is(err, internal.ErrAlreadyExists) {
-			m.log.Warn("conflicting manifest version. retry write", "error", err)
-		} else if err != nil {
-			return err
-		} else {
-			return nil
+	if retain, before, ok := m.db.manifestRetention(); ok {
+		if _, err := m.manifest.PruneManifestsBefore(retain, before); err != nil {
+			// Old manifests are pruned again on the next manifest update
+			log.Warn("failed to prune old manifests", "error", err)
 		}
 	}
+	return nil
 }
 
-func (m *MemtableFlusher) flushImmMemtablesToL0() error {
+// flushImmMemtablesToL0 flushes each immutable memtable to a new L0 SST. Each flush is
+// assigned a correlation ID which is included in every log line related to the flush.
+func (m *MemtableFlusher) flushImmMemtablesToL0() (err error) {
+	defer func() { m.db.recordFlush("memtable_flush", err) }()
 	for {
 		immMemtable := m.db.state.OldestImmMemtable()
 		if immMemtable.IsAbsent() {
 			break
 		}
-
-		id := sstable.NewIDCompacted(ulid.Make())
-		ctx, cancel := context.WithTimeout(context.Background(), m.db.opts.FlushInterval)
-		sstHandle, err := m.db.flushImmTable(ctx, id, immMemtable.MustGet().Iter())
-		cancel()
+		m.db.profiler.Do(profile.WorkMemtableFlush, func(p *profile.Profile) {
+			err = m.flushImmMemtableToL0(immMemtable.MustGet(), p)
+		})
 		if err != nil {
 			return err
 		}
+		m.db.stats.memtableFlushes.Add(1)
+	}
+	return nil
+}
 
-		m.db.state.MoveImmMemtableToL0(immMemtable.MustGet(), sstHandle)
-		err = m.writeManifestSafely()
-		if err != nil {
-			return err
-		}
+// flushImmMemtableToL0 flushes the immutable memtable to a new L0 SST and writes the
+// manifest, recording the stages of the flush with `p`
+func (m *MemtableFlusher) flushImmMemtableToL0(immMemtable *table.ImmutableMemtable, p *profile.Profile) (err error) {
+	log := m.log.With("flush_id", ulid.Make().String())
+	start := m.db.opts.Clock.Now()
+	spanCtx, span := m.db.tracer.Start(context.Background(), "slatedb.MemtableFlush", trace.WithAttributes(
+		tracing.KeyWALID.Int64(int64(immMemtable.LastWalID()))))
+	defer func() { tracing.End(span, err) }()
+	ctx, cancel := context.WithTimeout(spanCtx, m.db.opts.FlushInterval)
+	ssts, err := m.db.flushImmMemtable(ctx, immMemtable, p)
+	cancel()
+	if err != nil {
+		log.Error("failed to write L0 SST", "error", err)
+		return err
+	}
+	id := ssts[0].Id
+	var encodedSize uint64
+	for _, sst := range ssts {
+		encodedSize += sst.Info.EncodedSize()
+	}
+	log.Info("flushed memtable to L0", "sst_id", id.String(), "ssts", len(ssts),
+		"last_wal_id", immMemtable.LastWalID())
+	span.SetAttributes(tracing.KeySSTID.String(id.String()), tracing.KeyBytes.Int64(int64(encodedSize)))
+
+	m.db.state.MoveImmMemtableToL0(immMemtable, ssts)
+	prev := p.Enter(profile.StageManifest)
+	err = m.writeManifestSafely(log)
+	p.Exit(prev)
+	if err != nil {
+		log.Error("failed to write manifest", "sst_id", id.String(), "error", err)
+		return err
 	}
+	// Writes which awaited the flush of the memtable while the WAL is disabled are durable
+	m.db.auditImmMemtable(immMemtable)
+	immMemtable.Table().NotifyWALFlushed()
+	m.db.opts.EventListener.OnMemtableFlushed(config.MemtableFlushedEvent{
+		SSTID:      id.String(),
+		SSTs:       len(ssts),
+		LastWALID:  immMemtable.LastWalID(),
+		ManifestID: m.manifest.ID(),
+		Bytes:      encodedSize,
+		Duration:   m.db.opts.Clock.Now().Sub(start),
+	})
 	return nil
 }
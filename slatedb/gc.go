@@ -0,0 +1,430 @@
+package slatedb
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+
+	"github.com/slatedb/slatedb-go/internal"
+	"github.com/slatedb/slatedb-go/internal/sstable"
+	"github.com/slatedb/slatedb-go/slatedb/manifest"
+	"github.com/slatedb/slatedb-go/slatedb/state"
+	"github.com/slatedb/slatedb-go/slatedb/store"
+)
+
+// defaultGCMinAge is the minimum age of an unreferenced object before it is deleted, if
+// config.DBOptions.GCMinAge is zero
+const defaultGCMinAge = 24 * time.Hour
+
+// GCReport summarizes the objects deleted by CollectGarbage
+type GCReport struct {
+	// The time the collection started
+	Started time.Time
+
+	// The number of WAL SSTs deleted, which were flushed to L0 before the oldest live manifest
+	WALSSTs int
+
+	// The number of compacted SSTs deleted, which are referenced by no live manifest
+	CompactedSSTs int
+
+	// The number of filter sidecars deleted, whose compacted SST is referenced by no live manifest
+	FilterSidecars int
+
+	// The number of manifests deleted, which are neither live, pinned by a checkpoint, nor retained
+	// by config.DBOptions.ManifestRetention or ManifestRetentionTime
+	Manifests int
+}
+
+// CollectGarbage deletes the objects in object storage which are no longer referenced by the
+// database and are older than config.DBOptions.GCMinAge. Such objects are the WAL SSTs which have
+// been flushed to L0, the compacted SSTs and filter sidecars which have been compacted away or
+// were leaked by a crash before they were recorded in the manifest, and the old manifests.
+//
+// The live manifests are the latest manifest, every manifest which was the latest within GCMinAge,
+// such that readers of a slightly older manifest continue to find its SSTs, and the manifests
+// pinned by the unexpired checkpoints. Objects referenced by a live manifest, quarantined SSTs,
+// the manifests retained by config.DBOptions.ManifestRetention and ManifestRetentionTime, and
+// the SSTs of external databases are never deleted. Nor are the SSTs read by the Snapshots of
+// the DB which have not been released and the DBIterators which have not been exhausted or
+// closed, however old the manifest they were read from, or the SSTs retained by the unexpired
+// leases of the latest manifest, which are recorded by the DBIterators of read-only databases
+// in other processes, see config.DBOptions.ScanLeaseDuration.
+func (db *DB) CollectGarbage(ctx context.Context) (GCReport, error) {
+	if db.opts.ReadOnly {
+		return GCReport{}, ErrReadOnly
+	}
+	db.gcMu.Lock()
+	defer db.gcMu.Unlock()
+
+	report := GCReport{Started: db.opts.Clock.Now()}
+	minAge := db.opts.GCMinAge
+	if minAge <= 0 {
+		minAge = defaultGCMinAge
+	}
+	// Object storage records the wall clock time an object was written, which is compared
+	// with the wall clock rather than with DBOptions.Clock
+	cutoff := time.Now().Add(-minAge)
+
+	// The objects are listed before the manifests, such that an object uploaded after the
+	// latest manifest was read is never seen
+	objects, err := db.tableStore.ListObjects(ctx)
+	if err != nil {
+		return report, err
+	}
+	live, err := db.liveManifests(cutoff, report.Started)
+	if err != nil {
+		return report, err
+	}
+
+	referenced := make(map[sstable.ID]bool)
+	lastCompactedWAL := uint64(math.MaxUint64)
+	for _, m := range live.manifests {
+		core := m.Core.Snapshot()
+		for _, h := range core.L0 {
+			referenced[h.Id] = true
+		}
+		for _, sr := range core.Compacted {
+			for _, h := range sr.SSTList {
+				referenced[h.Id] = true
+			}
+		}
+		for _, q := range m.Quarantined {
+			referenced[q.ID] = true
+		}
+		lastCompactedWAL = min(lastCompactedWAL, core.LastCompactedWalSSTID.Load())
+	}
+	for _, l := range live.leases {
+		for _, id := range l.SSTIDs {
+			referenced[sstable.NewIDCompacted(id)] = true
+		}
+	}
+	db.pins.addTo(referenced)
+	for _, obj := range objects {
+		// The SSTs which have been quarantined but not yet recorded in the manifest are
+		// also retained
+		if referenced[obj.ID] || db.tableStore.IsQuarantined(obj.ID) || !obj.LastModified.Before(cutoff) {
+			continue
+		}
+		if walID, ok := obj.ID.WalID().Get(); ok && walID > lastCompactedWAL {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+		if err := db.tableStore.DeleteObject(ctx, obj); err != nil {
+			return report, err
+		}
+		switch {
+		case obj.Sidecar:
+			report.FilterSidecars++
+		case obj.ID.Type == sstable.WAL:
+			report.WALSSTs++
+		default:
+			report.CompactedSSTs++
+		}
+	}
+
+	// The manifests are deleted once the SSTs which only they referenced are deleted, such
+	// that a collection which fails part way is completed by the next collection
+	retain, before, ok := db.manifestRetention()
+	if !ok {
+		return report, nil
+	}
+	// Nor is a manifest written within GCMinAge, which must exceed the time a writer may hold a
+	// manifest without refreshing it, see ManifestStore.SetMinPruneAge
+	if cutoff.Before(before) {
+		before = cutoff
+	}
+	for i, m := range live.listed {
+		if i >= len(live.listed)-retain || !m.LastModified.Before(before) {
+			break
+		}
+		if _, ok := live.manifests[m.ID]; ok {
+			continue
+		}
+		if err := db.manifestStore.DeleteManifest(m.ID); err != nil {
+			return report, err
+		}
+		report.Manifests++
+	}
+	return report, nil
+}
+
+// manifestRetention returns the number of most recent manifest versions retained by
+// config.DBOptions.ManifestRetention, and the time before which the older versions were written
+// for config.DBOptions.ManifestRetentionTime to no longer retain them. It returns false if every
+// manifest version is retained.
+func (db *DB) manifestRetention() (int, time.Time, bool) {
+	if db.opts.ManifestRetention <= 0 && db.opts.ManifestRetentionTime <= 0 {
+		return 0, time.Time{}, false
+	}
+	// Manifests are timestamped by object storage, see CollectGarbage
+	return max(db.opts.ManifestRetention, 1), time.Now().Add(-db.opts.ManifestRetentionTime), true
+}
+
+type liveManifests struct {
+	// listed is every stored manifest in the order of their IDs
+	listed []store.ManifestFileMetadata
+
+	// manifests are the live manifests by ID
+	manifests map[uint64]*manifest.Manifest
+
+	// leases are the leases of the latest manifest which have not expired
+	leases []manifest.Lease
+}
+
+// liveManifests reads the manifests which were the latest manifest after `cutoff`, and the
+// manifests pinned by the checkpoints of the latest manifest which have not expired at `now`,
+// along with the leases of the latest manifest which have not expired at `now`
+func (db *DB) liveManifests(cutoff time.Time, now time.Time) (liveManifests, error) {
+	listed, err := db.manifestStore.ListManifests()
+	if err != nil {
+		return liveManifests{}, fmt.Errorf("while listing manifests: %w", err)
+	}
+	live := liveManifests{listed: listed, manifests: make(map[uint64]*manifest.Manifest)}
+	if len(listed) == 0 {
+		return live, nil
+	}
+
+	read := func(id uint64) (*manifest.Manifest, error) {
+		if m, ok := live.manifests[id]; ok {
+			return m, nil
+		}
+		m, err := db.manifestStore.ReadManifest(id)
+		if err != nil {
+			return nil, fmt.Errorf("while reading manifest '%d': %w", id, err)
+		}
+		live.manifests[id] = m
+		return m, nil
+	}
+
+	// A manifest was the latest manifest until its successor was written
+	for i, m := range listed {
+		if i < len(listed)-1 && !listed[i+1].LastModified.After(cutoff) {
+			continue
+		}
+		if _, err := read(m.ID); err != nil {
+			return liveManifests{}, err
+		}
+	}
+	latest := live.manifests[listed[len(listed)-1].ID]
+	for _, c := range latest.Checkpoints {
+		if c.Expired(now) {
+			continue
+		}
+		if _, err := read(c.ManifestID); err != nil {
+			return liveManifests{}, fmt.Errorf("while reading checkpoint '%d': %w", c.ID, err)
+		}
+	}
+	for _, l := range latest.Leases {
+		if !l.Expired(now) {
+			live.leases = append(live.leases, l)
+		}
+	}
+	return live, nil
+}
+
+// sstPins are the SSTs read by the open Snapshots and DBIterators of the DB, which are retained
+// by CollectGarbage once no live manifest references them
+type sstPins struct {
+	mu     sync.Mutex
+	nextID uint64
+	pinned map[uint64][]sstable.ID
+}
+
+// pin retains the L0 SSTs and sorted runs of `core` until the returned func is called
+func (p *sstPins) pin(core *state.CoreStateSnapshot) func() {
+	ids := make([]sstable.ID, 0, len(core.L0))
+	for _, h := range core.L0 {
+		ids = append(ids, h.Id)
+	}
+	for _, sr := range core.Compacted {
+		for _, h := range sr.SSTList {
+			ids = append(ids, h.Id)
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.pinned == nil {
+		p.pinned = make(map[uint64][]sstable.ID)
+	}
+	id := p.nextID
+	p.nextID++
+	p.pinned[id] = ids
+	return func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		delete(p.pinned, id)
+	}
+}
+
+// addTo adds the pinned SSTs to `referenced`
+func (p *sstPins) addTo(referenced map[sstable.ID]bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, ids := range p.pinned {
+		for _, id := range ids {
+			referenced[id] = true
+		}
+	}
+}
+
+// sstLeases are the leases recorded in the manifest by the DBIterators of a read-only DB, which
+// retain the SSTs read by the iterators from the garbage collector of the writer, see
+// config.DBOptions.ScanLeaseDuration
+type sstLeases struct {
+	mu sync.Mutex
+	// active are the SSTs of the leases held by the open DBIterators by lease ID
+	active map[ulid.ULID][]ulid.ULID
+}
+
+// lease records a lease in the manifest which retains the compacted SSTs of `core` until the
+// returned func is called, which removes the lease. The lease is renewed by spawnLeaseTask.
+func (db *DB) lease(core *state.CoreStateSnapshot) (func(), error) {
+	ids := make([]ulid.ULID, 0, len(core.L0))
+	for _, h := range core.L0 {
+		ids = append(ids, h.Id.CompactedID().OrEmpty())
+	}
+	for _, sr := range core.Compacted {
+		for _, h := range sr.SSTList {
+			ids = append(ids, h.Id.CompactedID().OrEmpty())
+		}
+	}
+	lease := manifest.Lease{
+		ID:         ulid.Make(),
+		SSTIDs:     ids,
+		ExpireTime: db.opts.Clock.Now().Add(db.opts.ScanLeaseDuration),
+	}
+	err := db.updateLeases(func(leases []manifest.Lease) []manifest.Lease {
+		return append(leases, lease)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("while recording lease: %w", err)
+	}
+
+	db.leases.mu.Lock()
+	if db.leases.active == nil {
+		db.leases.active = make(map[ulid.ULID][]ulid.ULID)
+	}
+	db.leases.active[lease.ID] = ids
+	db.leases.mu.Unlock()
+	return func() {
+		db.leases.mu.Lock()
+		delete(db.leases.active, lease.ID)
+		db.leases.mu.Unlock()
+		err := db.updateLeases(func(leases []manifest.Lease) []manifest.Lease {
+			return slices.DeleteFunc(leases, func(l manifest.Lease) bool { return l.ID == lease.ID })
+		})
+		if err != nil {
+			db.opts.Log.Warn("unable to remove lease, it is removed once it expires",
+				"lease_id", lease.ID.String(), "error", err)
+		}
+	}, nil
+}
+
+// renewLeases extends the expire time of the leases of the open DBIterators. A lease which
+// expired before it was renewed is recorded again.
+func (db *DB) renewLeases() error {
+	expireTime := db.opts.Clock.Now().Add(db.opts.ScanLeaseDuration)
+	return db.updateLeases(func(leases []manifest.Lease) []manifest.Lease {
+		// The active leases are read on each attempt, such that a lease removed by a concurrent
+		// close is not recorded again
+		db.leases.mu.Lock()
+		defer db.leases.mu.Unlock()
+		renewed := make(map[ulid.ULID]bool)
+		for i, l := range leases {
+			if _, ok := db.leases.active[l.ID]; ok {
+				leases[i].ExpireTime = expireTime
+				renewed[l.ID] = true
+			}
+		}
+		for id, ids := range db.leases.active {
+			if !renewed[id] {
+				leases = append(leases, manifest.Lease{ID: id, SSTIDs: ids, ExpireTime: expireTime})
+			}
+		}
+		return leases
+	})
+}
+
+// updateLeases writes the leases returned by `update` to the latest manifest, see
+// store.StoredManifest.UpdateLeases
+func (db *DB) updateLeases(update func(leases []manifest.Lease) []manifest.Lease) error {
+	stored, err := store.LoadStoredManifest(db.manifestStore)
+	if err != nil {
+		return err
+	}
+	sm, ok := stored.Get()
+	if !ok {
+		return internal.Err("manifest no longer exists")
+	}
+	return sm.UpdateLeases(db.opts.Clock.Now(), update)
+}
+
+// spawnLeaseTask renews the leases of the open DBIterators of a read-only DB every
+// DBOptions.ScanLeaseDuration / 2
+func (db *DB) spawnLeaseTask() {
+	if !db.opts.ReadOnly || db.opts.ScanLeaseDuration <= 0 {
+		return
+	}
+	ticker := db.opts.Clock.NewTicker(db.opts.ScanLeaseDuration / 2)
+	db.tasks.Go("lease_renew", func(ctx context.Context) error {
+		for {
+			select {
+			case <-ticker.C():
+				db.leases.mu.Lock()
+				active := len(db.leases.active)
+				db.leases.mu.Unlock()
+				if active == 0 {
+					continue
+				}
+				if err := db.renewLeases(); err != nil {
+					db.opts.Log.Error("error renewing leases", "error", err)
+				}
+			case <-ctx.Done():
+				ticker.Stop()
+				return nil
+			}
+		}
+	})
+}
+
+// spawnGCTask collects garbage every DBOptions.GCInterval
+func (db *DB) spawnGCTask() {
+	if db.opts.GCInterval <= 0 {
+		return
+	}
+	ticker := db.newIdleTicker(db.opts.GCInterval)
+	db.tasks.Go("gc", func(ctx context.Context) error {
+		for {
+			select {
+			case <-ticker.C():
+				db.collectGarbage(ctx)
+				ticker.pauseIfIdle()
+			case <-ticker.Woken():
+				ticker.resume()
+			case <-ctx.Done():
+				ticker.Stop()
+				return nil
+			}
+		}
+	})
+}
+
+func (db *DB) collectGarbage(ctx context.Context) {
+	report, err := db.CollectGarbage(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			db.opts.Log.Warn("garbage collection failed", "error", err)
+		}
+		return
+	}
+	db.opts.Log.Info("collected garbage", "wal_ssts", report.WALSSTs, "compacted_ssts",
+		report.CompactedSSTs, "filter_sidecars", report.FilterSidecars, "manifests", report.Manifests)
+}
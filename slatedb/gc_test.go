@@ -0,0 +1,268 @@
+package slatedb
+
+import (
+	"bytes"
+	"context"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore"
+
+	"github.com/slatedb/slatedb-go/slatedb/config"
+	"github.com/slatedb/slatedb-go/slatedb/manifest"
+	"github.com/slatedb/slatedb-go/slatedb/store"
+)
+
+func TestCollectGarbage(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	bucket := objstore.NewInMemBucket()
+	dbPath := "/tmp/test_kv_store"
+	options := testDBOptionsCompactor(0, 1024, config.DefaultCompactorOptions())
+	options.GCMinAge = time.Millisecond
+	db, err := OpenWithOptions(ctx, dbPath, bucket, options)
+	require.NoError(t, err)
+
+	require.NoError(t, db.Put(ctx, []byte("key1"), []byte("value1")))
+	require.NoError(t, db.FlushMemtableToL0())
+	checkpoint, err := db.CreateCheckpoint(ctx, config.DefaultCheckpointOptions())
+	require.NoError(t, err)
+	pinned := db.state.CoreStateSnapshot().L0
+	require.NotEmpty(t, pinned)
+	require.NoError(t, db.Put(ctx, []byte("key2"), []byte("value2")))
+	require.NoError(t, db.FlushMemtableToL0())
+	// the L0 SSTs which are not pinned by the checkpoint are garbage once they are compacted
+	compactedAway := len(db.state.CoreStateSnapshot().L0) - len(pinned)
+	require.NoError(t, db.CompactRange(ctx, nil, nil))
+
+	// an SST and a filter sidecar which were uploaded, but never recorded in the manifest
+	orphan := ulid.Make().String()
+	for _, name := range []string{orphan + ".sst", orphan + ".filter"} {
+		require.NoError(t, bucket.Upload(ctx, path.Join(dbPath, "compacted", name), bytes.NewReader([]byte("orphan"))))
+	}
+
+	// nothing is deleted until it is older than GCMinAge
+	db.opts.GCMinAge = 0
+	report, err := db.CollectGarbage(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, GCReport{Started: report.Started}, report)
+	db.opts.GCMinAge = options.GCMinAge
+
+	time.Sleep(10 * time.Millisecond)
+	report, err = db.CollectGarbage(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, compactedAway+1, report.CompactedSSTs)
+	assert.Equal(t, 1, report.FilterSidecars)
+	assert.Positive(t, report.WALSSTs)
+	// every manifest is retained without ManifestRetention or ManifestRetentionTime
+	assert.Zero(t, report.Manifests)
+	exists, err := bucket.Exists(ctx, path.Join(dbPath, "compacted", orphan+".sst"))
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	// the SSTs and the manifest pinned by the checkpoint are retained
+	for _, h := range pinned {
+		exists, err := bucket.Exists(ctx, path.Join(dbPath, "compacted", h.Id.Value+".sst"))
+		require.NoError(t, err)
+		assert.True(t, exists)
+	}
+	_, err = db.manifestStore.ReadManifest(checkpoint.ManifestID)
+	require.NoError(t, err)
+
+	// once the checkpoint is deleted, the SSTs it pinned are collected
+	require.NoError(t, db.DeleteCheckpoint(checkpoint.ID))
+	time.Sleep(10 * time.Millisecond)
+	report, err = db.CollectGarbage(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, len(pinned), report.CompactedSSTs)
+	require.NoError(t, db.Close(ctx))
+
+	// the database is intact after it is reopened
+	db, err = OpenWithOptions(ctx, dbPath, bucket, testDBOptions(0, 1024))
+	require.NoError(t, err)
+	defer func() { _ = db.Close(ctx) }()
+	for _, key := range []string{"key1", "key2"} {
+		_, err := db.Get(ctx, []byte(key))
+		require.NoError(t, err)
+	}
+}
+
+func TestCollectGarbageUnderSnapshot(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	bucket := objstore.NewInMemBucket()
+	dbPath := "/tmp/test_kv_store"
+	options := testDBOptionsCompactor(0, 1024, config.DefaultCompactorOptions())
+	options.GCMinAge = time.Millisecond
+	db, err := OpenWithOptions(ctx, dbPath, bucket, options)
+	require.NoError(t, err)
+	defer func() { _ = db.Close(ctx) }()
+
+	require.NoError(t, db.Put(ctx, []byte("key1"), []byte("value1")))
+	require.NoError(t, db.Put(ctx, []byte("key2"), []byte("value2")))
+	require.NoError(t, db.FlushMemtableToL0())
+	pinned := db.state.CoreStateSnapshot().L0
+	require.NotEmpty(t, pinned)
+	snapshot, err := db.Snapshot(ctx)
+	require.NoError(t, err)
+	iter, err := db.Scan(ctx, nil, nil)
+	require.NoError(t, err)
+	kv, ok := iter.Next(ctx)
+	require.True(t, ok)
+	assert.Equal(t, []byte("key1"), kv.Key)
+
+	// the L0 SSTs read by the snapshot and the iterator are no longer referenced by a live
+	// manifest once they are compacted away
+	require.NoError(t, db.Put(ctx, []byte("key3"), []byte("value3")))
+	require.NoError(t, db.FlushMemtableToL0())
+	require.NoError(t, db.CompactRange(ctx, nil, nil))
+
+	collect := func() GCReport {
+		time.Sleep(10 * time.Millisecond)
+		report, err := db.CollectGarbage(ctx)
+		require.NoError(t, err)
+		return report
+	}
+	assertRetained := func(expected bool) {
+		for _, h := range pinned {
+			exists, err := bucket.Exists(ctx, path.Join(dbPath, "compacted", h.Id.Value+".sst"))
+			require.NoError(t, err)
+			assert.Equal(t, expected, exists)
+		}
+	}
+
+	// the SSTs are retained while the snapshot is open, unlike the L0 SST written after it
+	assert.Equal(t, 1, collect().CompactedSSTs)
+	assertRetained(true)
+	value, err := snapshot.Get(ctx, []byte("key2"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("value2"), value)
+
+	// and while the iterator is open once the snapshot is released
+	snapshot.Release()
+	assert.Zero(t, collect().CompactedSSTs)
+	assertRetained(true)
+	kv, ok = iter.Next(ctx)
+	require.True(t, ok)
+	assert.Equal(t, []byte("key2"), kv.Key)
+
+	// and are collected once the iterator is closed
+	iter.Close()
+	_, ok = iter.Next(ctx)
+	assert.False(t, ok)
+	assert.Equal(t, len(pinned), collect().CompactedSSTs)
+	assertRetained(false)
+}
+
+func TestManifestRetentionTime(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	bucket := objstore.NewInMemBucket()
+	options := testDBOptions(0, 1024)
+	options.ManifestRetentionTime = time.Hour
+	options.GCMinAge = time.Millisecond
+	db, err := OpenWithOptions(ctx, "/tmp/test_kv_store", bucket, options)
+	require.NoError(t, err)
+	defer func() { _ = db.Close(ctx) }()
+
+	// the manifests written within ManifestRetentionTime are retained by manifest updates
+	for i := 0; i < 3; i++ {
+		require.NoError(t, db.Put(ctx, []byte("key"), []byte("value")))
+		require.NoError(t, db.FlushMemtableToL0())
+	}
+	manifests, err := db.manifestStore.ListManifests()
+	require.NoError(t, err)
+	assert.Greater(t, len(manifests), 3)
+
+	// and by the garbage collector
+	report, err := db.CollectGarbage(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, report.Manifests)
+
+	db.opts.ManifestRetentionTime = time.Millisecond
+	time.Sleep(10 * time.Millisecond)
+	report, err = db.CollectGarbage(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, len(manifests)-1, report.Manifests)
+	manifests, err = db.manifestStore.ListManifests()
+	require.NoError(t, err)
+	assert.Len(t, manifests, 1)
+}
+
+func TestCollectGarbageUnderReadOnlyScan(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	bucket := objstore.NewInMemBucket()
+	dbPath := "/tmp/test_kv_store"
+	options := testDBOptionsCompactor(0, 1024, config.DefaultCompactorOptions())
+	options.GCMinAge = time.Millisecond
+	db, err := OpenWithOptions(ctx, dbPath, bucket, options)
+	require.NoError(t, err)
+	defer func() { _ = db.Close(ctx) }()
+
+	require.NoError(t, db.Put(ctx, []byte("key1"), []byte("value1")))
+	require.NoError(t, db.Put(ctx, []byte("key2"), []byte("value2")))
+	require.NoError(t, db.FlushMemtableToL0())
+	pinned := db.state.CoreStateSnapshot().L0
+	require.NotEmpty(t, pinned)
+
+	// a read-only DB stands in for a reader in another process, whose iterator is unknown to
+	// the writer but for the lease it records in the manifest
+	readerOptions := testDBOptions(0, 1024)
+	readerOptions.ReadOnly = true
+	readerOptions.CompactorOptions = nil
+	readerOptions.ScanLeaseDuration = 100 * time.Millisecond
+	reader, err := OpenWithOptions(ctx, dbPath, bucket, readerOptions)
+	require.NoError(t, err)
+	defer func() { _ = reader.Close(ctx) }()
+	iter, err := reader.Scan(ctx, nil, nil)
+	require.NoError(t, err)
+	kv, ok := iter.Next(ctx)
+	require.True(t, ok)
+	assert.Equal(t, []byte("key1"), kv.Key)
+
+	// the L0 SSTs read by the iterator are no longer referenced by a live manifest once they
+	// are compacted away
+	require.NoError(t, db.Put(ctx, []byte("key3"), []byte("value3")))
+	require.NoError(t, db.FlushMemtableToL0())
+	require.NoError(t, db.CompactRange(ctx, nil, nil))
+
+	// the SSTs are retained while the iterator is open, although it outlives GCMinAge and the
+	// lease it recorded, which is renewed
+	time.Sleep(200 * time.Millisecond)
+	report, err := db.CollectGarbage(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.CompactedSSTs)
+	for _, h := range pinned {
+		exists, err := bucket.Exists(ctx, path.Join(dbPath, "compacted", h.Id.Value+".sst"))
+		require.NoError(t, err)
+		assert.True(t, exists)
+	}
+	kv, ok = iter.Next(ctx)
+	require.True(t, ok)
+	assert.Equal(t, []byte("key2"), kv.Key)
+
+	leases := func() []manifest.Lease {
+		sm, err := store.LoadStoredManifest(db.manifestStore)
+		require.NoError(t, err)
+		return sm.MustGet().Leases()
+	}
+	require.Len(t, leases(), 1)
+	assert.False(t, leases()[0].Expired(time.Now()))
+
+	// the lease is removed once the iterator is closed, after which the SSTs are collected
+	iter.Close()
+	assert.Empty(t, leases())
+	time.Sleep(10 * time.Millisecond)
+	report, err = db.CollectGarbage(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, len(pinned), report.CompactedSSTs)
+}
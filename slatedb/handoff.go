@@ -0,0 +1,75 @@
+package slatedb
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/slatedb/slatedb-go/internal"
+)
+
+// handOffTokenPrefix is the prefix of the tokens returned by DB.HandOff, which versions the token
+const handOffTokenPrefix = "handoff-v1-"
+
+// HandOff hands the database off to a successor for planned maintenance. It stops accepting
+// writes, flushes the WAL and the memtable to L0 and closes the database, then returns a token
+// which the successor passes to Open as config.DBOptions.HandOffToken. Writes made once HandOff
+// is called fail with ErrHandedOff.
+//
+// A writer takes over a database by fencing the previous writer through the manifest rather than
+// by waiting for a lease to expire, so the successor opens immediately. The token allows the
+// successor to verify that it continues from the state this writer handed off: Open fails with
+// ErrStaleHandOff if another writer opened the database after the hand-off.
+func (db *DB) HandOff(ctx context.Context) (string, error) {
+	if db.opts.ReadOnly {
+		return "", ErrReadOnly
+	}
+	if !db.handedOff.CompareAndSwap(false, true) {
+		return "", ErrHandedOff
+	}
+	if err := db.flushToL0(ctx); err != nil {
+		return "", err
+	}
+	epoch := db.manifest.Epoch()
+	if err := db.Close(ctx); err != nil {
+		return "", fmt.Errorf("while closing the database: %w", err)
+	}
+	db.opts.Log.Info("handed off the database", "writer_epoch", epoch)
+	return handOffTokenPrefix + strconv.FormatUint(epoch, 10), nil
+}
+
+// parseHandOffToken returns the writer epoch of the writer which returned the hand-off `token`
+func parseHandOffToken(token string) (uint64, error) {
+	s, ok := strings.CutPrefix(token, handOffTokenPrefix)
+	if !ok {
+		return 0, internal.ErrInvalidArgument("invalid HandOffToken '%s'", token)
+	}
+	epoch, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, internal.ErrInvalidArgument("invalid HandOffToken '%s'", token)
+	}
+	return epoch, nil
+}
+
+// checkHandOff returns ErrStaleHandOff if `epoch`, the writer epoch of the writer which opened the
+// database, does not follow `handedOff`, the writer epoch of the writer which handed it off
+func checkHandOff(handedOff uint64, epoch uint64) error {
+	if epoch != handedOff+1 {
+		return fmt.Errorf("%w: the database was handed off by writer epoch %d, but was last opened by "+
+			"writer epoch %d", ErrStaleHandOff, handedOff, epoch-1)
+	}
+	return nil
+}
+
+// writeErr returns ErrFenced if the writer has been fenced, ErrHandedOff if the database has been
+// handed off, or ErrDegraded if it is degraded
+func (db *DB) writeErr() error {
+	if db.fenced.Load() {
+		return ErrFenced
+	}
+	if db.handedOff.Load() {
+		return ErrHandedOff
+	}
+	return db.degradedErr()
+}
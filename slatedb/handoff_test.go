@@ -0,0 +1,57 @@
+package slatedb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore"
+
+	"github.com/slatedb/slatedb-go/slatedb/config"
+	"github.com/slatedb/slatedb-go/slatedb/store"
+)
+
+func TestHandOff(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	bucket := objstore.NewInMemBucket()
+	dbPath := "/tmp/test_kv_store"
+	db, err := OpenWithOptions(ctx, dbPath, bucket, testDBOptions(0, 1024))
+	require.NoError(t, err)
+	noWait := config.WriteOptions{AwaitDurable: false}
+	require.NoError(t, db.PutWithOptions(ctx, []byte("key1"), []byte("value1"), noWait))
+	require.NoError(t, db.PutWithOptions(ctx, []byte("key2"), []byte("value2"), noWait))
+
+	token, err := db.HandOff(ctx)
+	require.NoError(t, err)
+	assert.ErrorIs(t, db.Put(ctx, []byte("key3"), []byte("value3")), ErrHandedOff)
+	_, err = db.HandOff(ctx)
+	assert.ErrorIs(t, err, ErrHandedOff)
+
+	// the writes which were not durable are flushed to L0, such that the WAL is not replayed
+	stored, err := store.LoadStoredManifest(store.NewManifestStore(dbPath, bucket))
+	require.NoError(t, err)
+	storedManifest := stored.MustGet()
+	dbState := storedManifest.DbState()
+	assert.Equal(t, dbState.NextWalSstID.Load()-1, dbState.LastCompactedWalSSTID.Load())
+
+	options := testDBOptions(0, 1024)
+	options.HandOffToken = token
+	successor, err := OpenWithOptions(ctx, dbPath, bucket, options)
+	require.NoError(t, err)
+	it, err := successor.Scan(ctx, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"key1": "value1", "key2": "value2"}, scanAll(t, it))
+	require.NoError(t, successor.Close(ctx))
+
+	// the token is stale once another writer has opened the database
+	_, err = OpenWithOptions(ctx, dbPath, bucket, options)
+	assert.ErrorIs(t, err, ErrStaleHandOff)
+
+	options.HandOffToken = "invalid"
+	_, err = OpenWithOptions(ctx, dbPath, bucket, options)
+	assert.Error(t, err)
+}
@@ -0,0 +1,172 @@
+package slatedb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/slatedb/slatedb-go/internal/task"
+	"github.com/slatedb/slatedb-go/slatedb/config"
+)
+
+// TaskEvent describes a failure or restart of a background task, see config.DBOptions.OnTaskEvent
+type TaskEvent = config.TaskEvent
+
+// TaskStatus describes the health of a background task
+type TaskStatus = task.Status
+
+func newTaskManager(options config.DBOptions) *task.Manager {
+	return task.NewManager(task.Options{
+		OnEvent: options.TaskEventHandler(),
+		Log:     options.Log,
+	})
+}
+
+// HealthCheck returns an error if a background task of the database, such as the WAL flush,
+// memtable flush or compaction loop, has failed, if the writer has been fenced by a newer writer,
+// see ErrFenced, if the database is degraded, or if SSTs are quarantined, see ErrQuarantined.
+// Tasks which fail are restarted with backoff; a task is reported as failed once it has exhausted
+// its restarts, after which writes may no longer become durable and the database should be closed
+// and opened again.
+func (db *DB) HealthCheck() error {
+	if db.fenced.Load() {
+		return ErrFenced
+	}
+	if err := db.tasks.Err(); err != nil {
+		return err
+	}
+	if err := db.degradedErr(); err != nil {
+		return err
+	}
+	if n := db.tableStore.NumQuarantined(); n > 0 {
+		return fmt.Errorf("%w: %d SSTs are quarantined, see DB.Quarantined", ErrQuarantined, n)
+	}
+	if db.compactor != nil {
+		return db.compactor.HealthCheck()
+	}
+	return nil
+}
+
+// TaskStatus returns the status of the background tasks of the database
+func (db *DB) TaskStatus() []TaskStatus {
+	status := db.tasks.Status()
+	if db.compactor != nil {
+		status = append(status, db.compactor.TaskStatus()...)
+	}
+	return status
+}
+
+// ------------------------------------------------
+// Fenced
+// ------------------------------------------------
+
+// checkFenced returns `err`, and if it reports that a newer writer has fenced this one, marks the
+// writer as fenced, such that every subsequent write and flush fails with ErrFenced, and stops its
+// background tasks. The compactor is stopped once the compactor of the newer writer fences it.
+func (db *DB) checkFenced(err error) error {
+	if !errors.Is(err, ErrFenced) || !db.fenced.CompareAndSwap(false, true) {
+		return err
+	}
+	db.opts.Log.Error("writer fenced by a newer writer; stopping background tasks", "error", err)
+	db.fence()
+	// The error may be reported by a background task, which cannot wait for itself to stop
+	db.tasks.Cancel()
+	return err
+}
+
+// ------------------------------------------------
+// Degraded
+// ------------------------------------------------
+
+// flushFailures tracks the time since which each flush task has been failing continuously, and
+// the failure which degraded the database, see config.DBOptions.MaxFlushFailureDuration
+type flushFailures struct {
+	mu    sync.Mutex
+	since map[string]time.Time
+	// degraded is the event emitted when the database was degraded, or nil
+	degraded *config.TaskEvent
+}
+
+// recordFlush records the result of a flush by the task `name`, reports a failed flush to the
+// config.DBOptions.EventListener, and degrades the database if the task has been failing for
+// longer than config.DBOptions.MaxFlushFailureDuration
+func (db *DB) recordFlush(name string, err error) {
+	if err != nil {
+		db.opts.EventListener.OnBackgroundError(config.BackgroundErrorEvent{Task: name, Err: err})
+	}
+	if db.opts.MaxFlushFailureDuration <= 0 {
+		return
+	}
+
+	f := &db.flushFailures
+	f.mu.Lock()
+	if err == nil {
+		delete(f.since, name)
+		f.mu.Unlock()
+		return
+	}
+	now := db.opts.Clock.Now()
+	since, ok := f.since[name]
+	if !ok {
+		if f.since == nil {
+			f.since = make(map[string]time.Time)
+		}
+		f.since[name] = now
+	}
+	if !ok || f.degraded != nil || now.Sub(since) < db.opts.MaxFlushFailureDuration {
+		f.mu.Unlock()
+		return
+	}
+	event := config.TaskEvent{Task: name, Type: config.TaskDegraded, Err: err}
+	f.degraded = &event
+	f.mu.Unlock()
+
+	db.opts.Log.Error("flushes have failed continuously; rejecting writes until resumed",
+		"task", name, "failing_since", since, "error", err)
+	db.opts.NotifyTaskEvent(event)
+}
+
+// degradedErr returns an error which wraps ErrDegraded if the database is degraded
+func (db *DB) degradedErr() error {
+	db.flushFailures.mu.Lock()
+	defer db.flushFailures.mu.Unlock()
+	if e := db.flushFailures.degraded; e != nil {
+		return fmt.Errorf("%w; task '%s' failed: %w", ErrDegraded, e.Task, e.Err)
+	}
+	return nil
+}
+
+// Resume flushes the WAL and immutable memtables of a degraded database and, if the flushes
+// succeed, accepts writes again. Calling Resume on a database which is not degraded flushes
+// the WAL and immutable memtables.
+func (db *DB) Resume(ctx context.Context) error {
+	if db.opts.ReadOnly {
+		return ErrReadOnly
+	}
+	if err := db.FlushWAL(ctx); err != nil {
+		return err
+	}
+	flusher := MemtableFlusher{
+		db:       db,
+		manifest: db.manifest,
+		log:      db.opts.Log,
+	}
+	if err := flusher.flushImmMemtablesToL0(); err != nil {
+		return err
+	}
+
+	db.flushFailures.mu.Lock()
+	degraded := db.flushFailures.degraded
+	db.flushFailures.degraded = nil
+	db.flushFailures.since = nil
+	db.flushFailures.mu.Unlock()
+	if degraded == nil {
+		return nil
+	}
+
+	db.opts.Log.Info("flushes succeeded; accepting writes", "task", degraded.Task)
+	db.opts.NotifyTaskEvent(config.TaskEvent{Task: degraded.Task, Type: config.TaskResumed})
+	return nil
+}
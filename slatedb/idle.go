@@ -0,0 +1,177 @@
+package slatedb
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/slatedb/slatedb-go/slatedb/config"
+)
+
+// idleTracker records the time of the most recent write, such that the database goes idle once
+// it has not been written to for DBOptions.IdleTimeout, and wakes on the next write
+type idleTracker struct {
+	timeout time.Duration
+
+	// lastWrite is the time of the most recent write, in Unix nanoseconds
+	lastWrite atomic.Int64
+	idle      atomic.Bool
+
+	// mu guards the transitions of idle and wake
+	mu sync.Mutex
+	// wake is closed when the database is written to while idle
+	wake chan struct{}
+}
+
+func newIdleTracker(timeout time.Duration, now time.Time) *idleTracker {
+	t := &idleTracker{timeout: timeout}
+	t.lastWrite.Store(now.UnixNano())
+	return t
+}
+
+// active records a write at `now`, and wakes the database if it is idle. It returns true if
+// the database was woken.
+func (t *idleTracker) active(now time.Time) bool {
+	t.lastWrite.Store(now.UnixNano())
+	// lastWrite is stored before idle is loaded, and enter stores idle before it loads lastWrite,
+	// such that either the write wakes the database or enter sees the write
+	if !t.idle.Load() {
+		return false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.idle.Load() {
+		return false
+	}
+	t.idle.Store(false)
+	close(t.wake)
+	t.wake = nil
+	return true
+}
+
+// enter makes the database idle if it has not been written to for the idle timeout as of `now`.
+// It returns true if the database went idle.
+func (t *idleTracker) enter(now time.Time) bool {
+	if t.timeout <= 0 {
+		return false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.idle.Load() || !t.expired(now) {
+		return false
+	}
+	t.wake = make(chan struct{})
+	t.idle.Store(true)
+	if !t.expired(now) {
+		// a write raced with the transition, and did not see the database as idle
+		t.idle.Store(false)
+		t.wake = nil
+		return false
+	}
+	return true
+}
+
+func (t *idleTracker) expired(now time.Time) bool {
+	return now.Sub(time.Unix(0, t.lastWrite.Load())) >= t.timeout
+}
+
+// woken returns a channel which is closed when the database is next written to, or nil if
+// the database is not idle
+func (t *idleTracker) woken() <-chan struct{} {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.wake
+}
+
+// ------------------------------------------------
+// idleTicker
+// ------------------------------------------------
+
+// idleTicker is the ticker of a background task which is stopped while the database is idle.
+// The task calls pauseIfIdle after handling each tick, and resume once Woken is closed.
+type idleTicker struct {
+	clock    config.Clock
+	interval time.Duration
+	tracker  *idleTracker
+	ticker   config.Ticker
+	wake     <-chan struct{}
+}
+
+func (db *DB) newIdleTicker(interval time.Duration) *idleTicker {
+	return &idleTicker{
+		clock:    db.opts.Clock,
+		interval: interval,
+		tracker:  db.idle,
+		ticker:   db.opts.Clock.NewTicker(interval),
+	}
+}
+
+// C returns the channel of ticks, which never delivers a tick while the ticker is paused.
+// A nil idleTicker never ticks.
+func (t *idleTicker) C() <-chan time.Time {
+	if t == nil || t.ticker == nil {
+		return nil
+	}
+	return t.ticker.C()
+}
+
+// Woken returns a channel which is closed when a paused ticker should be resumed, or nil
+// if the ticker is not paused
+func (t *idleTicker) Woken() <-chan struct{} {
+	if t == nil {
+		return nil
+	}
+	return t.wake
+}
+
+// pauseIfIdle stops the ticker if the database is idle
+func (t *idleTicker) pauseIfIdle() {
+	wake := t.tracker.woken()
+	if wake == nil {
+		return
+	}
+	t.ticker.Stop()
+	t.ticker = nil
+	t.wake = wake
+}
+
+// resume restarts the ticker once the database has been woken
+func (t *idleTicker) resume() {
+	t.wake = nil
+	t.ticker = t.clock.NewTicker(t.interval)
+}
+
+func (t *idleTicker) Stop() {
+	if t != nil && t.ticker != nil {
+		t.ticker.Stop()
+	}
+}
+
+// ------------------------------------------------
+// DB
+// ------------------------------------------------
+
+// Idle returns true if the database has not been written to for DBOptions.IdleTimeout, such
+// that its background tasks are paused and its caches trimmed until the next write
+func (db *DB) Idle() bool {
+	return db.idle.idle.Load()
+}
+
+// markActive records a write, waking the database if it is idle. It must be called before
+// the write is added to the WAL, such that a write which awaits durability does not wait on
+// a paused WAL flush.
+func (db *DB) markActive() {
+	if db.idle.active(db.opts.Clock.Now()) {
+		db.opts.Log.Debug("database woken by write")
+	}
+}
+
+// maybeEnterIdle makes the database idle if it has not been written to for
+// DBOptions.IdleTimeout, and trims its caches
+func (db *DB) maybeEnterIdle() {
+	if !db.idle.enter(db.opts.Clock.Now()) {
+		return
+	}
+	db.tableStore.TrimCaches(db.opts.IdleBlockCacheSize)
+	db.opts.Log.Debug("database is idle", "idle_timeout", db.opts.IdleTimeout)
+}
@@ -0,0 +1,258 @@
+package slatedb
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"slices"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/samber/mo"
+	"github.com/slatedb/slatedb-go/internal"
+	"github.com/slatedb/slatedb-go/internal/sstable"
+	"github.com/slatedb/slatedb-go/internal/types"
+	"github.com/slatedb/slatedb-go/slatedb/audit"
+	"github.com/slatedb/slatedb-go/slatedb/config"
+	"github.com/slatedb/slatedb-go/slatedb/store"
+)
+
+// IngestSST is an SST written by an IngestWriter which has not yet been made part of the
+// database. See DB.ReplaceRange
+type IngestSST struct {
+	handle  sstable.Handle
+	lastKey []byte
+
+	// audited holds the key hash and op of each entry of the SST if an AuditSink is configured,
+	// the rest of each record is filled in once the SST is added to L0
+	audited []audit.Record
+}
+
+// FirstKey returns the first key of the SST
+func (s IngestSST) FirstKey() []byte {
+	return s.handle.Info.FirstKey
+}
+
+// LastKey returns the last key of the SST
+func (s IngestSST) LastKey() []byte {
+	return s.lastKey
+}
+
+// IngestWriter writes key-value pairs to SSTs in object storage, which are made part of the
+// database by DB.ReplaceRange. A new SST is started each time DBOptions.L0SSTSizeBytes is reached,
+// and for the keys of each keyspace of DBOptions.Keyspaces, which are compressed with the codec
+// of the keyspace and split at its KeyspaceOptions.L0SSTSizeBytes.
+type IngestWriter struct {
+	db       *DB
+	writer   *store.EncodedSSTableWriter
+	keyspace string
+	size     uint64
+	lastKey  []byte
+	audited  []audit.Record
+	ssts     []IngestSST
+}
+
+// NewIngestWriter returns an IngestWriter which writes SSTs to the object storage of the database
+func (db *DB) NewIngestWriter() *IngestWriter {
+	return &IngestWriter{db: db}
+}
+
+// Add adds the key-value pair to the current SST. Keys must be added in ascending order.
+func (w *IngestWriter) Add(ctx context.Context, key []byte, value []byte) error {
+	if len(key) == 0 {
+		return internal.ErrInvalidArgument("argument 'key' cannot be empty or nil")
+	}
+	return w.addEntry(ctx, types.RowEntry{
+		Key:   key,
+		Value: types.Value{Kind: types.KindKeyValue, Value: value},
+	})
+}
+
+// addEntry adds the entry, which may be a tombstone, to the current SST, see Add
+func (w *IngestWriter) addEntry(ctx context.Context, entry types.RowEntry) error {
+	if w.lastKey != nil && bytes.Compare(entry.Key, w.lastKey) <= 0 {
+		return internal.ErrInvalidArgument("keys must be added in ascending order; '%s' after '%s'", entry.Key, w.lastKey)
+	}
+
+	keyspace, _ := config.KeyspaceOf(w.db.opts.Keyspaces, entry.Key)
+	if w.writer != nil && keyspace != w.keyspace {
+		if err := w.finishSST(ctx); err != nil {
+			return err
+		}
+	}
+	if w.writer == nil {
+		id := sstable.NewIDCompacted(ulid.Make())
+		w.writer = w.db.tableStore.TableWriterForKeyspace(id, store.SSTLevelL0, keyspace)
+		w.keyspace = keyspace
+	}
+	// The sequence number of the entries is assigned when the SSTs are added to L0
+	if w.db.opts.ValueChecksums && !entry.Value.IsTombstone() && entry.Checksum.IsAbsent() {
+		entry.Checksum = mo.Some(types.ValueChecksum(entry.Value.Value))
+	}
+	err := w.writer.AddEntry(entry)
+	if err != nil {
+		w.Abort()
+		return err
+	}
+	w.lastKey = bytes.Clone(entry.Key)
+	w.size += uint64(len(entry.Key) + len(entry.Value.Value))
+	if w.db.opts.AuditSink != nil {
+		op := audit.OpPut
+		if entry.Value.IsTombstone() {
+			op = audit.OpDelete
+		}
+		w.audited = append(w.audited, audit.Record{KeyHash: audit.HashKey(entry.Key), Op: op})
+	}
+
+	if w.size >= w.db.l0SSTSize(keyspace, w.db.opts.L0SSTSizeBytes) {
+		return w.finishSST(ctx)
+	}
+	return nil
+}
+
+// Finish writes the current SST and returns every SST written by the IngestWriter in key order
+func (w *IngestWriter) Finish(ctx context.Context) ([]IngestSST, error) {
+	if err := w.finishSST(ctx); err != nil {
+		return nil, err
+	}
+	return w.ssts, nil
+}
+
+// Abort discards the SST being written, such that it is not uploaded. An IngestWriter which is
+// not finished should be aborted. The SSTs already written are not deleted.
+func (w *IngestWriter) Abort() {
+	if w.writer != nil {
+		w.writer.Abort()
+		w.writer = nil
+		w.size = 0
+		w.audited = nil
+	}
+}
+
+func (w *IngestWriter) finishSST(ctx context.Context) error {
+	if w.writer == nil {
+		return nil
+	}
+	handle, err := w.writer.Close(ctx)
+	if err != nil {
+		return err
+	}
+	w.ssts = append(w.ssts, IngestSST{handle: *handle, lastKey: w.lastKey, audited: w.audited})
+	w.writer = nil
+	w.size = 0
+	w.audited = nil
+	return nil
+}
+
+// ReplaceRange atomically replaces the contents of the range [start, end) with the contents of
+// the SSTs written by an IngestWriter. Every key in the range written before the call is deleted,
+// and the SSTs are added to L0 with a single manifest update, such that reads observe either the
+// prior contents of the range or the ingested contents, but never a mix of both.
+//
+// Writes are blocked until ReplaceRange returns, as the WAL and memtable are flushed to L0 before
+// the SSTs are added. Every key of the SSTs must be within the range.
+func (db *DB) ReplaceRange(ctx context.Context, start []byte, end []byte, ssts []IngestSST) error {
+	if len(start) == 0 || len(end) == 0 {
+		return internal.ErrInvalidArgument("arguments 'start' and 'end' cannot be empty or nil")
+	}
+	if bytes.Compare(start, end) >= 0 {
+		return internal.ErrInvalidArgument("argument 'start' must be less than 'end'")
+	}
+	ssts = slices.Clone(ssts)
+	slices.SortFunc(ssts, func(a, b IngestSST) int {
+		return bytes.Compare(a.FirstKey(), b.FirstKey())
+	})
+	for i, sst := range ssts {
+		if bytes.Compare(sst.FirstKey(), start) < 0 || bytes.Compare(sst.LastKey(), end) >= 0 {
+			return internal.ErrInvalidArgument("SST '%s' contains keys outside of the range", sst.handle.Id.String())
+		}
+		if i > 0 && bytes.Compare(sst.FirstKey(), ssts[i-1].LastKey()) <= 0 {
+			return internal.ErrInvalidArgument("SST '%s' overlaps SST '%s'",
+				sst.handle.Id.String(), ssts[i-1].handle.Id.String())
+		}
+	}
+	if db.opts.ReadOnly {
+		return ErrReadOnly
+	}
+	if err := db.writeErr(); err != nil {
+		return err
+	}
+	if err := db.enableRangeTombstones(); err != nil {
+		return err
+	}
+
+	// The range tombstone is held by an SST, so an empty SST is written when no SSTs are provided
+	if len(ssts) == 0 {
+		handle, err := db.tableStore.TableWriterAtLevel(sstable.NewIDCompacted(ulid.Make()), store.SSTLevelL0).Close(ctx)
+		if err != nil {
+			return err
+		}
+		ssts = append(ssts, IngestSST{handle: *handle})
+	}
+
+	db.markActive()
+	log := db.opts.Log.With("replace_id", ulid.Make().String())
+
+	// The entries of the SSTs and the range tombstone are assigned the same sequence number,
+	// such that the range tombstone deletes every prior write but not the ingested entries.
+	var ingestSeq uint64
+	err := db.addToL0(ctx, log, func(seq uint64) []sstable.Handle {
+		ingestSeq = seq
+		handles := make([]sstable.Handle, 0, len(ssts))
+		for i := len(ssts) - 1; i >= 0; i-- {
+			handle := ssts[i].handle.Clone()
+			handle.Info.IngestSeq = seq
+			if i == 0 {
+				handle.Info.RangeTombstones = append(handle.Info.RangeTombstones,
+					types.RangeTombstone{Start: bytes.Clone(start), End: bytes.Clone(end), Seq: seq})
+			}
+			handles = append(handles, *handle)
+		}
+		return handles
+	})
+	if err != nil {
+		return err
+	}
+	// The hash of the range tombstone is the hash of the start of the range
+	db.auditL0(ingestAudit(ssts, audit.Record{KeyHash: audit.HashKey(start), Op: audit.OpDeleteRange}), ingestSeq)
+	log.Info("replaced range with ingested SSTs", "ssts", len(ssts))
+	return nil
+}
+
+// ingestAudit returns the audit records of the entries of `ssts`, preceded by `records`
+func ingestAudit(ssts []IngestSST, records ...audit.Record) []audit.Record {
+	for _, sst := range ssts {
+		records = append(records, sst.audited...)
+	}
+	return records
+}
+
+// addToL0 adds the SSTs returned by `prepare`, whose entries are assigned the sequence number
+// `seq`, to L0 with a single manifest update. Every prior write is flushed to L0 first, such
+// that the SSTs are newer than each SST which holds a prior write, and older than the memtable
+// which holds later writes. Writes are blocked until addToL0 returns.
+func (db *DB) addToL0(ctx context.Context, log *slog.Logger, prepare func(seq uint64) []sstable.Handle) error {
+	db.writeMu.Lock()
+	defer db.writeMu.Unlock()
+
+	if err := db.FlushWAL(ctx); err != nil {
+		return err
+	}
+	flusher := MemtableFlusher{
+		db:       db,
+		manifest: db.manifest,
+		log:      db.opts.Log,
+	}
+	if lastWalID, ok := db.state.Memtable().LastWalID().Get(); ok && db.state.Memtable().Size() > 0 {
+		db.state.FreezeMemtable(lastWalID)
+	}
+	if err := flusher.flushImmMemtablesToL0(); err != nil {
+		return err
+	}
+
+	db.state.IngestL0(prepare)
+	if err := flusher.writeManifestSafely(log); err != nil {
+		log.Error("failed to write manifest", "error", err)
+		return err
+	}
+	return nil
+}
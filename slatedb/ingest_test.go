@@ -0,0 +1,71 @@
+package slatedb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore"
+)
+
+func TestReplaceRange(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	bucket := objstore.NewInMemBucket()
+	db, err := OpenWithOptions(ctx, "/tmp/test_kv_store", bucket, testDBOptions(0, 1024))
+	require.NoError(t, err)
+
+	require.NoError(t, db.Put(ctx, []byte("key1"), []byte("l0")))
+	require.NoError(t, db.Put(ctx, []byte("key2"), []byte("l0")))
+	require.NoError(t, db.Put(ctx, []byte("key6"), []byte("l0")))
+	require.NoError(t, db.FlushMemtableToL0())
+	require.NoError(t, db.Put(ctx, []byte("key4"), []byte("memtable")))
+
+	writer := db.NewIngestWriter()
+	require.NoError(t, writer.Add(ctx, []byte("key2"), []byte("ingested")))
+	require.NoError(t, writer.Add(ctx, []byte("key3"), []byte("ingested")))
+	assert.Error(t, writer.Add(ctx, []byte("key3"), []byte("ingested")))
+	ssts, err := writer.Finish(ctx)
+	require.NoError(t, err)
+	require.Len(t, ssts, 1)
+
+	// every key of the SSTs must be within the range
+	assert.Error(t, db.ReplaceRange(ctx, []byte("key3"), []byte("key5"), ssts))
+	assert.Error(t, db.ReplaceRange(ctx, []byte("key1"), []byte("key3"), ssts))
+
+	require.NoError(t, db.ReplaceRange(ctx, []byte("key1"), []byte("key5"), ssts))
+	assertKeys := func(expected map[string]string) {
+		t.Helper()
+		for _, key := range []string{"key1", "key2", "key3", "key4", "key5", "key6"} {
+			value, err := db.Get(ctx, []byte(key))
+			if v, ok := expected[key]; ok {
+				require.NoError(t, err, key)
+				assert.Equal(t, []byte(v), value)
+			} else {
+				assert.ErrorIs(t, err, ErrKeyNotFound, key)
+			}
+		}
+		it, err := db.Scan(ctx, nil, nil)
+		require.NoError(t, err)
+		assert.Equal(t, expected, scanAll(t, it))
+	}
+	assertKeys(map[string]string{"key2": "ingested", "key3": "ingested", "key6": "l0"})
+
+	// a key written after the range is replaced shadows the ingested key
+	require.NoError(t, db.Put(ctx, []byte("key3"), []byte("after")))
+	assertKeys(map[string]string{"key2": "ingested", "key3": "after", "key6": "l0"})
+
+	// replacing the range with no SSTs deletes every key in the range
+	require.NoError(t, db.ReplaceRange(ctx, []byte("key6"), []byte("key7"), nil))
+	assertKeys(map[string]string{"key2": "ingested", "key3": "after"})
+
+	// the ingested SSTs are recorded in the manifest
+	require.NoError(t, db.Close(ctx))
+	db, err = OpenWithOptions(ctx, "/tmp/test_kv_store", bucket, testDBOptions(0, 1024))
+	require.NoError(t, err)
+	defer func() { _ = db.Close(ctx) }()
+	assertKeys(map[string]string{"key2": "ingested", "key3": "after"})
+}
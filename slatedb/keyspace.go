@@ -0,0 +1,175 @@
+package slatedb
+
+import (
+	"bytes"
+	"context"
+	"time"
+
+	"github.com/slatedb/slatedb-go/internal"
+	"github.com/slatedb/slatedb-go/internal/compress"
+	"github.com/slatedb/slatedb-go/internal/types"
+	"github.com/slatedb/slatedb-go/slatedb/config"
+)
+
+// keyspaceSeparator ends the name of a keyspace in the keys of the keyspace. As names do not hold
+// the separator, the keys of one keyspace never begin with the prefix of another.
+const keyspaceSeparator = 0x00
+
+// Keyspace is a named set of keys within a database, such as the records of an application and
+// an index of them, which are read and written independently of the keys of other keyspaces.
+// The writes of several keyspaces can be committed atomically with a WriteBatch.
+//
+// The keys of a keyspace are stored with the name of the keyspace and a zero byte as a prefix,
+// so keys written directly to the DB which begin with that prefix are seen by the keyspace.
+// Applications which use keyspaces should write every key through a Keyspace.
+type Keyspace struct {
+	db      *DB
+	name    string
+	prefix  []byte
+	options config.KeyspaceOptions
+}
+
+// Keyspace returns the keyspace `name`, with its options of config.DBOptions.Keyspaces if it is
+// registered there. Keyspaces do not need to be created; a keyspace holds no keys until they are
+// written to it.
+func (db *DB) Keyspace(name string) (*Keyspace, error) {
+	options, ok := db.opts.Keyspaces[name]
+	if !ok {
+		options = config.DefaultKeyspaceOptions()
+	}
+	return db.KeyspaceWithOptions(name, options)
+}
+
+// KeyspaceWithOptions returns the keyspace `name`, whose writes are made with `options`. The
+// options are not stored, each Keyspace of the same name applies the TTL it was returned with.
+// The options which determine how the SSTs of a keyspace are written are those of
+// config.DBOptions.Keyspaces, as such `options` must not set them unless they are registered.
+func (db *DB) KeyspaceWithOptions(name string, options config.KeyspaceOptions) (*Keyspace, error) {
+	if err := validateKeyspace(name, options); err != nil {
+		return nil, err
+	}
+	registered := db.opts.Keyspaces[name]
+	if options.L0SSTSizeBytes != registered.L0SSTSizeBytes || options.CompressionCodec != registered.CompressionCodec ||
+		!equalPtr(options.Compaction, registered.Compaction) {
+		return nil, internal.ErrInvalidArgument("the SST options of keyspace '%s' differ from those of "+
+			"DBOptions.Keyspaces", name)
+	}
+	prefix := append([]byte(name), keyspaceSeparator)
+	return &Keyspace{db: db, name: name, prefix: prefix, options: options}, nil
+}
+
+// validateKeyspace returns an error if `name` is not a valid keyspace name or `options` are invalid
+func validateKeyspace(name string, options config.KeyspaceOptions) error {
+	if options.TTL < 0 {
+		return internal.ErrInvalidArgument("keyspace TTL must not be negative; got %s", options.TTL)
+	}
+	if name == "" {
+		return internal.ErrInvalidArgument("keyspace name must not be empty")
+	}
+	if bytes.IndexByte([]byte(name), keyspaceSeparator) >= 0 {
+		return internal.ErrInvalidArgument("keyspace name '%s' must not contain a zero byte", name)
+	}
+	if c, ok := options.CompressionCodec.Get(); ok && (c < compress.CodecNone || c > compress.CodecZstd) {
+		return internal.ErrInvalidArgument("invalid CompressionCodec %d of keyspace '%s'", c, name)
+	}
+	if options.Compaction != nil && options.Compaction.MinL0CompactionSSTs < 0 {
+		return internal.ErrInvalidArgument("MinL0CompactionSSTs of keyspace '%s' must not be negative", name)
+	}
+	return nil
+}
+
+// equalPtr returns true if `a` and `b` are both nil or point to equal values
+func equalPtr[T comparable](a, b *T) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// l0SSTSize returns the size at which the keys of `keyspace` are split into another L0 SST, which
+// is config.KeyspaceOptions.L0SSTSizeBytes if the keyspace sets it and `fallback` otherwise. Zero
+// means the keys are not split.
+func (db *DB) l0SSTSize(keyspace string, fallback uint64) uint64 {
+	if ks, ok := db.opts.Keyspaces[keyspace]; ok && ks.L0SSTSizeBytes > 0 {
+		return ks.L0SSTSizeBytes
+	}
+	return fallback
+}
+
+// Name returns the name of the keyspace
+func (ks *Keyspace) Name() string {
+	return ks.name
+}
+
+// key returns the key of the database which holds `key` of the keyspace, or nil if `key` is
+// empty such that the key is rejected like an empty key of the default keyspace
+func (ks *Keyspace) key(key []byte) []byte {
+	if len(key) == 0 {
+		return nil
+	}
+	return append(bytes.Clone(ks.prefix), key...)
+}
+
+// expireAt returns the time at which a value put through the keyspace now expires, or zero if
+// the keyspace has no TTL
+func (ks *Keyspace) expireAt() time.Time {
+	if ks.options.TTL == 0 {
+		return time.Time{}
+	}
+	return ks.db.opts.Clock.Now().Add(ks.options.TTL).Truncate(time.Millisecond)
+}
+
+func (ks *Keyspace) Put(ctx context.Context, key []byte, value []byte) error {
+	return ks.PutWithOptions(ctx, key, value, config.DefaultWriteOptions())
+}
+
+func (ks *Keyspace) PutWithOptions(ctx context.Context, key []byte, value []byte, options config.WriteOptions) error {
+	return ks.db.putEntry(ctx, types.RowEntry{
+		Value: types.Value{
+			Kind:  types.KindKeyValue,
+			Value: value,
+		},
+		Key:      ks.key(key),
+		ExpireAt: ks.expireAt(),
+	}, options)
+}
+
+func (ks *Keyspace) Get(ctx context.Context, key []byte) ([]byte, error) {
+	return ks.db.Get(ctx, ks.key(key))
+}
+
+func (ks *Keyspace) GetWithOptions(ctx context.Context, key []byte, options config.ReadOptions) ([]byte, error) {
+	return ks.db.GetWithOptions(ctx, ks.key(key), options)
+}
+
+func (ks *Keyspace) Delete(ctx context.Context, key []byte) error {
+	return ks.db.Delete(ctx, ks.key(key))
+}
+
+func (ks *Keyspace) DeleteWithOptions(ctx context.Context, key []byte, options config.WriteOptions) error {
+	return ks.db.DeleteWithOptions(ctx, ks.key(key), options)
+}
+
+func (ks *Keyspace) Scan(ctx context.Context, start []byte, end []byte) (*DBIterator, error) {
+	return ks.ScanWithIteratorOptions(ctx, start, end, config.DefaultIteratorOptions())
+}
+
+// ScanWithIteratorOptions returns a DBIterator over the keys of the keyspace in the range
+// [start, end), which are returned without the prefix of the keyspace. If start or end is empty,
+// the range is unbounded on that side within the keyspace. See DB.ScanWithIteratorOptions
+func (ks *Keyspace) ScanWithIteratorOptions(ctx context.Context, start []byte, end []byte,
+	options config.IteratorOptions) (*DBIterator, error) {
+	dbStart, dbEnd := ks.prefix, prefixEnd(ks.prefix)
+	if len(start) != 0 {
+		dbStart = ks.key(start)
+	}
+	if len(end) != 0 {
+		dbEnd = ks.key(end)
+	}
+	iter, err := ks.db.ScanWithIteratorOptions(ctx, dbStart, dbEnd, options)
+	if err != nil {
+		return nil, err
+	}
+	iter.keyPrefix = ks.prefix
+	return iter, nil
+}
@@ -0,0 +1,234 @@
+package slatedb
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/samber/mo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore"
+
+	"github.com/slatedb/slatedb-go/internal/compress"
+	"github.com/slatedb/slatedb-go/internal/sstable"
+	"github.com/slatedb/slatedb-go/slatedb/config"
+)
+
+func TestKeyspace(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	db, err := OpenWithOptions(ctx, "/tmp/test_kv_store", objstore.NewInMemBucket(), testDBOptions(0, 1024))
+	require.NoError(t, err)
+	defer func() { _ = db.Close(ctx) }()
+
+	_, err = db.Keyspace("")
+	assert.ErrorContains(t, err, "keyspace name must not be empty")
+	_, err = db.Keyspace("a\x00b")
+	assert.ErrorContains(t, err, "must not contain a zero byte")
+
+	a, err := db.Keyspace("a")
+	require.NoError(t, err)
+	ab, err := db.Keyspace("ab")
+	require.NoError(t, err)
+	assert.Equal(t, "a", a.Name())
+	assert.ErrorContains(t, a.Put(ctx, nil, []byte("value")), "argument 'key' cannot be empty or nil")
+
+	for _, key := range []string{"key1", "key2", "key3"} {
+		require.NoError(t, a.Put(ctx, []byte(key), []byte("a-"+key)))
+		require.NoError(t, ab.Put(ctx, []byte(key), []byte("ab-"+key)))
+	}
+	require.NoError(t, db.Put(ctx, []byte("key1"), []byte("default")))
+	require.NoError(t, a.Delete(ctx, []byte("key2")))
+
+	// keys of a keyspace are isolated from the keys of other keyspaces
+	val, err := a.Get(ctx, []byte("key1"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("a-key1"), val)
+	val, err = ab.Get(ctx, []byte("key2"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("ab-key2"), val)
+	_, err = a.Get(ctx, []byte("key2"))
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+	val, err = db.Get(ctx, []byte("key1"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("default"), val)
+
+	scan := func(ks *Keyspace, start, end string, options config.IteratorOptions) []string {
+		iter, err := ks.ScanWithIteratorOptions(ctx, []byte(start), []byte(end), options)
+		require.NoError(t, err)
+		var keys []string
+		for {
+			kv, ok := iter.Next(ctx)
+			if !ok {
+				break
+			}
+			keys = append(keys, string(kv.Key)+"="+string(kv.Value))
+		}
+		require.NoError(t, iter.Err())
+		return keys
+	}
+	options := config.DefaultIteratorOptions()
+	assert.Equal(t, []string{"key1=a-key1", "key3=a-key3"}, scan(a, "", "", options))
+	assert.Equal(t, []string{"key2=ab-key2", "key3=ab-key3"}, scan(ab, "key2", "", options))
+	assert.Equal(t, []string{"key1=ab-key1"}, scan(ab, "", "key2", options))
+	options.Reverse = true
+	assert.Equal(t, []string{"key3=ab-key3", "key2=ab-key2", "key1=ab-key1"}, scan(ab, "", "", options))
+}
+
+func TestKeyspaceTTL(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	clock := config.NewManualClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	options := testDBOptions(0, 1024)
+	options.Clock = clock
+	db, err := OpenInMemory(ctx, options)
+	require.NoError(t, err)
+	defer func() { _ = db.Close(ctx) }()
+
+	_, err = db.KeyspaceWithOptions("metrics", config.KeyspaceOptions{TTL: -time.Minute})
+	assert.ErrorContains(t, err, "keyspace TTL must not be negative")
+
+	metrics, err := db.KeyspaceWithOptions("metrics", config.KeyspaceOptions{TTL: time.Minute})
+	require.NoError(t, err)
+	profiles, err := db.Keyspace("profiles")
+	require.NoError(t, err)
+
+	require.NoError(t, metrics.Put(ctx, []byte("key1"), []byte("value1")))
+	require.NoError(t, profiles.Put(ctx, []byte("key1"), []byte("value1")))
+	batch := NewWriteBatch()
+	batch.PutKeyspace(metrics, []byte("key2"), []byte("value2"))
+	batch.PutKeyspace(profiles, []byte("key2"), []byte("value2"))
+	require.NoError(t, db.Write(ctx, batch))
+
+	_, err = metrics.Get(ctx, []byte("key1"))
+	require.NoError(t, err)
+
+	// only the values of the keyspace with a TTL expire
+	clock.Advance(time.Minute)
+	for _, key := range []string{"key1", "key2"} {
+		_, err = metrics.Get(ctx, []byte(key))
+		assert.ErrorIs(t, err, ErrKeyNotFound)
+		_, err = profiles.Get(ctx, []byte(key))
+		assert.NoError(t, err)
+	}
+}
+
+// keyspaceSSTOptions registers a keyspace of large values, flushed to small Snappy SSTs and
+// compacted early to small SSTs, and a keyspace of small values compressed with Zstd
+func keyspaceSSTOptions() map[string]config.KeyspaceOptions {
+	return map[string]config.KeyspaceOptions{
+		"blobs": {
+			L0SSTSizeBytes:   1024,
+			CompressionCodec: mo.Some(compress.CodecSnappy),
+			Compaction:       &config.KeyspaceCompactionOptions{MinL0CompactionSSTs: 2, MaxSSTSize: 1024},
+		},
+		"index": {CompressionCodec: mo.Some(compress.CodecZstd)},
+	}
+}
+
+// writeKeyspaceSSTs writes large values to the keyspace "blobs", small values to the keyspace
+// "index" and a key to no keyspace with a single batch
+func writeKeyspaceSSTs(t *testing.T, ctx context.Context, db *DB) {
+	blobs, err := db.Keyspace("blobs")
+	require.NoError(t, err)
+	index, err := db.Keyspace("index")
+	require.NoError(t, err)
+	batch := NewWriteBatch()
+	for i := 0; i < 8; i++ {
+		batch.PutKeyspace(blobs, []byte(fmt.Sprintf("blob%d", i)), repeatedChar(rune('a'+i), 400))
+		batch.PutKeyspace(index, []byte(fmt.Sprintf("key%d", i)), []byte(fmt.Sprintf("blob%d", i)))
+	}
+	batch.Put([]byte("default"), []byte("value"))
+	require.NoError(t, db.Write(ctx, batch))
+}
+
+// sstsByKeyspace groups `ssts` by the keyspace of config.DBOptions.Keyspaces of their first key
+func sstsByKeyspace(db *DB, ssts []sstable.Handle) map[string][]sstable.Handle {
+	result := make(map[string][]sstable.Handle)
+	for _, sst := range ssts {
+		keyspace, _ := config.KeyspaceOf(db.opts.Keyspaces, sst.Info.FirstKey)
+		result[keyspace] = append(result[keyspace], sst)
+	}
+	return result
+}
+
+func TestKeyspaceSSTOptions(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		directBytes uint64
+	}{
+		{name: "memtable flush"},
+		// the batch is written directly to L0 SSTs, see config.DBOptions.DirectL0BatchBytes
+		{name: "batch written to L0", directBytes: 1},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+			defer cancel()
+
+			options := testDBOptions(0, 64*1024)
+			options.Keyspaces = keyspaceSSTOptions()
+			options.DirectL0BatchBytes = tc.directBytes
+			db, err := OpenWithOptions(ctx, "/tmp/test_kv_store", objstore.NewInMemBucket(), options)
+			require.NoError(t, err)
+			defer func() { _ = db.Close(ctx) }()
+
+			writeKeyspaceSSTs(t, ctx, db)
+			if tc.directBytes == 0 {
+				require.NoError(t, db.FlushMemtableToL0())
+			}
+
+			// each keyspace is written to SSTs of its own, of its size and codec
+			l0 := sstsByKeyspace(db, db.state.CoreStateSnapshot().L0)
+			require.Len(t, l0, 3)
+			assert.GreaterOrEqual(t, len(l0["blobs"]), 3)
+			for _, sst := range l0["blobs"] {
+				assert.Equal(t, compress.CodecSnappy, sst.Info.CompressionCodec)
+				assert.Less(t, sst.Info.EncodedSize(), uint64(2*1024))
+			}
+			require.Len(t, l0["index"], 1)
+			assert.Equal(t, compress.CodecZstd, l0["index"][0].Info.CompressionCodec)
+			require.Len(t, l0[""], 1)
+			assert.Equal(t, compress.CodecNone, l0[""][0].Info.CompressionCodec)
+
+			blobs, err := db.Keyspace("blobs")
+			require.NoError(t, err)
+			for i := 0; i < 8; i++ {
+				value, err := blobs.Get(ctx, []byte(fmt.Sprintf("blob%d", i)))
+				require.NoError(t, err)
+				assert.Equal(t, repeatedChar(rune('a'+i), 400), value)
+			}
+			value, err := db.Get(ctx, []byte("default"))
+			require.NoError(t, err)
+			assert.Equal(t, []byte("value"), value)
+		})
+	}
+}
+
+func TestKeyspaceRegisteredOptions(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	options := testDBOptions(0, 1024)
+	options.Keyspaces = keyspaceSSTOptions()
+	db, err := OpenInMemory(ctx, options)
+	require.NoError(t, err)
+	defer func() { _ = db.Close(ctx) }()
+
+	// the SST options of a keyspace are those it is registered with
+	_, err = db.KeyspaceWithOptions("index", config.KeyspaceOptions{TTL: time.Minute})
+	assert.ErrorContains(t, err, "differ from those of DBOptions.Keyspaces")
+	_, err = db.KeyspaceWithOptions("other", config.KeyspaceOptions{L0SSTSizeBytes: 1024})
+	assert.ErrorContains(t, err, "differ from those of DBOptions.Keyspaces")
+	registered := options.Keyspaces["index"]
+	registered.TTL = time.Minute
+	_, err = db.KeyspaceWithOptions("index", registered)
+	assert.NoError(t, err)
+
+	options.Keyspaces = map[string]config.KeyspaceOptions{"a\x00b": {}}
+	_, err = OpenInMemory(ctx, options)
+	assert.ErrorContains(t, err, "must not contain a zero byte")
+}
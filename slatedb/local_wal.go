@@ -0,0 +1,91 @@
+package slatedb
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/slatedb/slatedb-go/slatedb/store"
+	"github.com/slatedb/slatedb-go/slatedb/table"
+)
+
+// openLocalWAL opens the local WAL in config.DBOptions.LocalWALDir and replays the writes it holds
+// which are not in object storage, which are those allocated a sequence number after the last
+// write replayed from the WAL SSTs. Only the writes of the writer this writer fenced are replayed,
+// those of an older writer were superseded by the writes of the writer which fenced it. The
+// replayed writes are flushed to L0 before the local WAL segments are removed, after which each
+// write to the WAL is appended to the local WAL.
+func (db *DB) openLocalWAL() error {
+	localWAL, err := store.OpenLocalWAL(db.opts.LocalWALDir, db.manifest.Epoch())
+	if err != nil {
+		return err
+	}
+	records, err := localWAL.Replay(db.opts.Log)
+	if err != nil {
+		return err
+	}
+
+	lastSeq := db.state.LastSeq()
+	replayed := 0
+	for _, record := range records {
+		for _, entry := range record.Entries {
+			if entry.Seq > lastSeq {
+				db.state.MemTablePut(entry)
+				replayed++
+			}
+		}
+		for _, t := range record.Tombstones {
+			if t.Seq > lastSeq {
+				db.state.MemTableDeleteRange(t)
+				replayed++
+			}
+		}
+	}
+	if replayed > 0 {
+		db.opts.Log.Info("replayed writes from the local WAL", "writes", replayed)
+		if err := db.flushReplayedWAL(); err != nil {
+			return fmt.Errorf("while flushing the replayed writes: %w", err)
+		}
+	}
+	// Every write of the replayed segments is now in object storage
+	if err := localWAL.RemoveThrough(math.MaxUint64); err != nil {
+		return fmt.Errorf("while removing replayed segments: %w", err)
+	}
+
+	db.localWAL = localWAL
+	db.state.SetWALObserver(localWAL.Append)
+	return nil
+}
+
+// awaitWALDurable waits for a write to `wal` to be durable, which is once the local WAL is synced
+// if config.DBOptions.LocalWALDir is set, and otherwise once the WAL is flushed to object storage.
+func (db *DB) awaitWALDurable(ctx context.Context, wal *table.WAL) error {
+	if db.localWAL != nil {
+		if err := db.localWAL.Sync(); err != nil {
+			return err
+		}
+		// The local WAL of a fenced writer is discarded by the next writer, see openLocalWAL
+		if db.fenced.Load() {
+			return ErrFenced
+		}
+		return nil
+	}
+	if db.inMemory {
+		db.requestWALFlush()
+	}
+	// we wait for WAL to be flushed to memtable and then we send a notification
+	// to goroutine to flush memtable to L0. we do not wait till its flushed to L0
+	// because client can read the key from memtable
+	// The WAL is no longer flushed once the writer is fenced, which fails the wait with ErrFenced
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stop := context.AfterFunc(db.fencedCtx, cancel)
+	defer stop()
+	if err := wal.Table().AwaitWALFlush(ctx); err != nil {
+		if db.fenced.Load() {
+			return ErrFenced
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,190 @@
+package slatedb
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/slatedb/slatedb-go/internal"
+)
+
+// RangeLock is an advisory lock on a range of keys, see DB.LockRange. The lock does not prevent
+// reads or writes of the range; it only prevents other calls to LockRange from locking an
+// overlapping range until it is unlocked or expires.
+type RangeLock struct {
+	db    *DB
+	id    string
+	lease rangeLease
+}
+
+// rangeLease is the lease object of a RangeLock persisted to object storage
+type rangeLease struct {
+	Start     []byte    `json:"start"`
+	End       []byte    `json:"end"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func (l rangeLease) overlaps(start, end []byte) bool {
+	return bytes.Compare(l.Start, end) < 0 && bytes.Compare(start, l.End) < 0
+}
+
+// LockRange acquires an advisory lock on the keys from `start` inclusive to `end` exclusive, which
+// expires after `ttl` unless it is renewed. The lock is persisted to object storage, such that
+// processes which open the same database, including read-only processes, can coordinate bulk
+// operations such as ingests and purges so their work does not overlap. ErrRangeLocked is returned
+// if an unexpired lock overlaps the range.
+//
+// Locks expire by the clock of the process which checks them, so the clocks of the processes
+// must be reasonably synchronized relative to `ttl`. When two processes lock overlapping ranges
+// concurrently, both may fail with ErrRangeLocked, but they never both succeed.
+func (db *DB) LockRange(ctx context.Context, start, end []byte, ttl time.Duration) (*RangeLock, error) {
+	if len(start) == 0 || len(end) == 0 {
+		return nil, internal.ErrInvalidArgument("arguments 'start' and 'end' cannot be empty or nil")
+	}
+	if bytes.Compare(start, end) >= 0 {
+		return nil, internal.ErrInvalidArgument("argument 'start' must be less than 'end'")
+	}
+	if ttl <= 0 {
+		return nil, internal.ErrInvalidArgument("argument 'ttl' must be greater than zero")
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := db.checkRangeUnlocked(start, end, ""); err != nil {
+		return nil, err
+	}
+	lock := &RangeLock{
+		db: db,
+		id: ulid.Make().String(),
+		lease: rangeLease{
+			Start:     bytes.Clone(start),
+			End:       bytes.Clone(end),
+			ExpiresAt: db.opts.Clock.Now().Add(ttl).UTC(),
+		},
+	}
+	data, err := json.Marshal(lock.lease)
+	if err != nil {
+		return nil, internal.Err("while encoding range lock: %s", err)
+	}
+	if err := db.lockStore.Create(lock.id, data); err != nil {
+		return nil, fmt.Errorf("while writing range lock: %w", err)
+	}
+	// Another process may have written an overlapping lock between the check and the write. Both
+	// processes then find the lock of the other and remove their own, such that at most one holds
+	// the range.
+	if err := db.checkRangeUnlocked(start, end, lock.id); err != nil {
+		_ = db.lockStore.Delete(lock.id)
+		return nil, err
+	}
+	db.opts.Log.Debug("locked range", "lock_id", lock.id, "expires_at", lock.lease.ExpiresAt)
+	return lock, nil
+}
+
+// checkRangeUnlocked returns ErrRangeLocked if an unexpired lock other than `self` overlaps the
+// range. The lease objects of expired locks are removed.
+func (db *DB) checkRangeUnlocked(start, end []byte, self string) error {
+	names, err := db.lockStore.List()
+	if err != nil {
+		return fmt.Errorf("while listing range locks: %w", err)
+	}
+	now := db.opts.Clock.Now()
+	for _, name := range names {
+		if name == self {
+			continue
+		}
+		data, err := db.lockStore.Read(name)
+		if err != nil {
+			return fmt.Errorf("while reading range lock '%s': %w", name, err)
+		}
+		d, ok := data.Get()
+		if !ok {
+			// the lock was unlocked since it was listed
+			continue
+		}
+		var lease rangeLease
+		if err := json.Unmarshal(d, &lease); err != nil {
+			db.opts.Log.Warn("ignoring undecodable range lock", "lock_id", name, "error", err)
+			continue
+		}
+		if !now.Before(lease.ExpiresAt) {
+			if err := db.lockStore.Delete(name); err != nil {
+				db.opts.Log.Warn("failed to remove expired range lock", "lock_id", name, "error", err)
+			}
+			continue
+		}
+		if lease.overlaps(start, end) {
+			return fmt.Errorf("%w; lock '%s' holds the range until %s", ErrRangeLocked, name,
+				lease.ExpiresAt.Format(time.RFC3339))
+		}
+	}
+	return nil
+}
+
+// ID returns the ID of the lock, which names its lease object in object storage
+func (l *RangeLock) ID() string {
+	return l.id
+}
+
+// Start and End return the range of keys held by the lock
+func (l *RangeLock) Start() []byte {
+	return l.lease.Start
+}
+
+func (l *RangeLock) End() []byte {
+	return l.lease.End
+}
+
+// ExpiresAt returns the time at which the lock expires unless it is renewed
+func (l *RangeLock) ExpiresAt() time.Time {
+	return l.lease.ExpiresAt
+}
+
+// Renew extends the lock such that it expires `ttl` from now. ErrLockLost is returned if the lock
+// has expired or was removed, in which case another process may have locked the range.
+func (l *RangeLock) Renew(ctx context.Context, ttl time.Duration) error {
+	if ttl <= 0 {
+		return internal.ErrInvalidArgument("argument 'ttl' must be greater than zero")
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	now := l.db.opts.Clock.Now()
+	if !now.Before(l.lease.ExpiresAt) {
+		return fmt.Errorf("%w; lock '%s' expired at %s", ErrLockLost, l.id, l.lease.ExpiresAt.Format(time.RFC3339))
+	}
+	data, err := l.db.lockStore.Read(l.id)
+	if err != nil {
+		return fmt.Errorf("while reading range lock: %w", err)
+	}
+	if data.IsAbsent() {
+		return fmt.Errorf("%w; lock '%s' was removed", ErrLockLost, l.id)
+	}
+
+	lease := l.lease
+	lease.ExpiresAt = now.Add(ttl).UTC()
+	encoded, err := json.Marshal(lease)
+	if err != nil {
+		return internal.Err("while encoding range lock: %s", err)
+	}
+	if err := l.db.lockStore.Replace(l.id, encoded); err != nil {
+		return fmt.Errorf("while writing range lock: %w", err)
+	}
+	l.lease = lease
+	return nil
+}
+
+// Unlock releases the lock, such that other processes may lock an overlapping range. Unlocking
+// a lock which has expired or has already been unlocked is not an error.
+func (l *RangeLock) Unlock(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := l.db.lockStore.Delete(l.id); err != nil {
+		return fmt.Errorf("while removing range lock: %w", err)
+	}
+	return nil
+}
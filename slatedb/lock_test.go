@@ -0,0 +1,68 @@
+package slatedb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore"
+
+	"github.com/slatedb/slatedb-go/slatedb/config"
+)
+
+func TestLockRange(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	clock := config.NewManualClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	bucket := objstore.NewInMemBucket()
+	options := testDBOptions(0, 1024*1024)
+	options.Clock = clock
+	db, err := OpenWithOptions(ctx, "/tmp/test_kv_store", bucket, options)
+	require.NoError(t, err)
+	defer func() { _ = db.Close(ctx) }()
+
+	options.ReadOnly = true
+	reader, err := OpenWithOptions(ctx, "/tmp/test_kv_store", bucket, options)
+	require.NoError(t, err)
+	defer func() { _ = reader.Close(ctx) }()
+
+	lock, err := db.LockRange(ctx, []byte("a"), []byte("m"), time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, clock.Now().Add(time.Minute), lock.ExpiresAt())
+
+	// overlapping ranges cannot be locked by this or another process, while adjacent ranges can
+	_, err = db.LockRange(ctx, []byte("k"), []byte("z"), time.Minute)
+	assert.ErrorIs(t, err, ErrRangeLocked)
+	_, err = reader.LockRange(ctx, []byte("0"), []byte("b"), time.Minute)
+	assert.ErrorIs(t, err, ErrRangeLocked)
+	adjacent, err := reader.LockRange(ctx, []byte("m"), []byte("z"), time.Minute)
+	require.NoError(t, err)
+	require.NoError(t, adjacent.Unlock(ctx))
+
+	// a renewed lock outlives its original ttl
+	clock.Advance(50 * time.Second)
+	require.NoError(t, lock.Renew(ctx, time.Minute))
+	clock.Advance(50 * time.Second)
+	_, err = reader.LockRange(ctx, []byte("k"), []byte("z"), time.Minute)
+	assert.ErrorIs(t, err, ErrRangeLocked)
+
+	// once expired the range may be locked by another process, and the lock cannot be renewed
+	clock.Advance(time.Minute)
+	other, err := reader.LockRange(ctx, []byte("k"), []byte("z"), time.Minute)
+	require.NoError(t, err)
+	assert.ErrorIs(t, lock.Renew(ctx, time.Minute), ErrLockLost)
+	require.NoError(t, lock.Unlock(ctx))
+
+	// an unlocked range may be locked again
+	require.NoError(t, other.Unlock(ctx))
+	_, err = db.LockRange(ctx, []byte("a"), []byte("z"), time.Minute)
+	require.NoError(t, err)
+
+	_, err = db.LockRange(ctx, []byte("z"), []byte("a"), time.Minute)
+	assert.Error(t, err)
+	_, err = db.LockRange(ctx, []byte("a"), []byte("z"), 0)
+	assert.Error(t, err)
+}
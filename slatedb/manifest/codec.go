@@ -3,15 +3,20 @@ package manifest
 import (
 	"bytes"
 	"encoding/binary"
+	"fmt"
+	"time"
 
 	flatbuffers "github.com/google/flatbuffers/go"
 	"github.com/oklog/ulid/v2"
 	"github.com/samber/mo"
 
+	"github.com/slatedb/slatedb-go/internal"
 	"github.com/slatedb/slatedb-go/internal/assert"
 	"github.com/slatedb/slatedb-go/internal/compress"
 	"github.com/slatedb/slatedb-go/internal/flatbuf"
 	"github.com/slatedb/slatedb-go/internal/sstable"
+	"github.com/slatedb/slatedb-go/internal/sstable/block"
+	"github.com/slatedb/slatedb-go/slatedb/common"
 	"github.com/slatedb/slatedb-go/slatedb/compacted"
 	"github.com/slatedb/slatedb-go/slatedb/state"
 )
@@ -22,19 +27,81 @@ import (
 
 // FlatBufferManifestCodec implements Codec and defines how we
 // Encode Manifest to byte slice and Decode byte slice back to Manifest
-type FlatBufferManifestCodec struct{}
+type FlatBufferManifestCodec struct {
+	// Compression, if not compress.CodecNone, compresses each manifest encoded. A compressed
+	// manifest is a ManifestV1 header which holds only FeatureCompressedManifest, followed by the
+	// compressed manifest and a trailer, such that a version of slatedb which does not support
+	// compressed manifests fails to decode it as requiring an unsupported feature:
+	//
+	//	|---------------------------------------------------------|
+	//	|  []byte  |  []byte              |  uint32     |  uint8  |
+	//	|----------|----------------------|-------------|---------|
+	//	|  header  |  compressed manifest |  length     |  codec  |
+	//	|---------------------------------------------------------|
+	//
+	// Manifests are decoded whether or not they are compressed.
+	Compression compress.Codec
+}
+
+// compressedManifestTrailerSize is the size of the length and codec which follow a compressed manifest
+const compressedManifestTrailerSize = common.SizeOfUint32 + 1
 
 func (f FlatBufferManifestCodec) Encode(manifest *Manifest) []byte {
 	builder := flatbuffers.NewBuilder(0)
 	dbFlatBufBuilder := newDBFlatBufferBuilder(builder)
-	return dbFlatBufBuilder.createManifest(manifest)
+	encoded := dbFlatBufBuilder.createManifest(manifest)
+	if f.Compression == compress.CodecNone {
+		return encoded
+	}
+
+	compressed, err := compress.Encode(encoded, f.Compression)
+	assert.True(err == nil, "failed to compress manifest: %v", err)
+	header := flatbuf.ManifestV1T{Features: uint64(FeatureCompressedManifest)}
+	builder = flatbuffers.NewBuilder(0)
+	builder.Finish(header.Pack(builder))
+	buf := append(builder.FinishedBytes(), compressed...)
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(compressed)))
+	return append(buf, byte(f.Compression))
 }
 
 func (f FlatBufferManifestCodec) Decode(data []byte) (*Manifest, error) {
 	manifestV1 := flatbuf.GetRootAsManifestV1(data, 0)
+	features := Features(manifestV1.Features())
+	if unsupported := features.Unsupported(); unsupported != 0 {
+		return nil, internal.Err("manifest requires on-disk features '%s' which are not supported "+
+			"by this version of slatedb; upgrade to open this database", unsupported)
+	}
+	if features.Has(FeatureCompressedManifest) {
+		decompressed, err := f.decompress(data)
+		if err != nil {
+			return nil, err
+		}
+		return f.Decode(decompressed)
+	}
 	return f.manifest(manifestV1.UnPack()), nil
 }
 
+// decompress returns the manifest held by a compressed manifest, see Compression
+func (f FlatBufferManifestCodec) decompress(data []byte) ([]byte, error) {
+	if len(data) < compressedManifestTrailerSize {
+		return nil, internal.Err("corrupted compressed manifest; too short for trailer")
+	}
+	trailer := len(data) - compressedManifestTrailerSize
+	codec := compress.Codec(data[len(data)-1])
+	length := int(binary.BigEndian.Uint32(data[trailer:]))
+	if length > trailer {
+		return nil, internal.Err("corrupted compressed manifest; length %d exceeds manifest", length)
+	}
+	decompressed, err := compress.Decode(data[trailer-length:trailer], codec)
+	if err != nil {
+		return nil, fmt.Errorf("while decompressing manifest: %w", err)
+	}
+	if Features(flatbuf.GetRootAsManifestV1(decompressed, 0).Features()).Has(FeatureCompressedManifest) {
+		return nil, internal.Err("corrupted compressed manifest; manifest is compressed twice")
+	}
+	return decompressed, nil
+}
+
 func (f FlatBufferManifestCodec) manifest(manifest *flatbuf.ManifestV1T) *Manifest {
 	core := &state.CoreStateSnapshot{
 		L0:        f.parseFlatBufSSTList(manifest.L0),
@@ -42,6 +109,7 @@ func (f FlatBufferManifestCodec) manifest(manifest *flatbuf.ManifestV1T) *Manife
 	}
 	core.NextWalSstID.Store(manifest.WalIdLastSeen + 1)
 	core.LastCompactedWalSSTID.Store(manifest.WalIdLastCompacted)
+	core.LastSeq.Store(manifest.LastSeq)
 
 	l0LastCompacted := f.parseFlatBufSSTId(manifest.L0LastCompacted)
 	if l0LastCompacted == ulid.Zero {
@@ -54,9 +122,88 @@ func (f FlatBufferManifestCodec) manifest(manifest *flatbuf.ManifestV1T) *Manife
 	m.Core = core.ToCoreState()
 	m.WriterEpoch.Store(manifest.WriterEpoch)
 	m.CompactorEpoch.Store(manifest.CompactorEpoch)
+	m.Features = Features(manifest.Features)
+	m.Checkpoints = f.parseFlatBufCheckpoints(manifest.Snapshots)
+	m.ExternalDBs = f.parseFlatBufExternalDBs(manifest.ExternalDbs)
+	m.FilterSidecars = f.parseFlatBufSSTIds(manifest.FilterSidecars)
+	m.Quarantined = f.parseFlatBufQuarantined(manifest.Quarantined)
+	m.Leases = f.parseFlatBufLeases(manifest.Leases)
 	return m
 }
 
+func (f FlatBufferManifestCodec) parseFlatBufSSTIds(ids []*flatbuf.CompactedSstIdT) []ulid.ULID {
+	if len(ids) == 0 {
+		return nil
+	}
+	result := make([]ulid.ULID, 0, len(ids))
+	for _, id := range ids {
+		result = append(result, f.parseFlatBufSSTId(id))
+	}
+	return result
+}
+
+func (f FlatBufferManifestCodec) parseFlatBufLeases(leases []*flatbuf.SstLeaseT) []Lease {
+	if len(leases) == 0 {
+		return nil
+	}
+	result := make([]Lease, 0, len(leases))
+	for _, l := range leases {
+		result = append(result, Lease{
+			ID:         f.parseFlatBufSSTId(l.Id),
+			SSTIDs:     f.parseFlatBufSSTIds(l.SstIds),
+			ExpireTime: time.UnixMilli(int64(l.ExpireTimeMs)).UTC(),
+		})
+	}
+	return result
+}
+
+func (f FlatBufferManifestCodec) parseFlatBufQuarantined(quarantined []*flatbuf.QuarantinedSstT) []QuarantinedSST {
+	if len(quarantined) == 0 {
+		return nil
+	}
+	result := make([]QuarantinedSST, 0, len(quarantined))
+	for _, q := range quarantined {
+		sst := QuarantinedSST{Reason: q.Reason, Time: time.Unix(int64(q.QuarantineTimeS), 0).UTC()}
+		if q.SstId != nil {
+			sst.ID = sstable.NewIDCompacted(f.parseFlatBufSSTId(q.SstId))
+		} else {
+			sst.ID = sstable.NewIDWal(q.WalId)
+		}
+		result = append(result, sst)
+	}
+	return result
+}
+
+func (f FlatBufferManifestCodec) parseFlatBufExternalDBs(externalDBs []*flatbuf.ExternalDbT) []ExternalDB {
+	if len(externalDBs) == 0 {
+		return nil
+	}
+	result := make([]ExternalDB, 0, len(externalDBs))
+	for _, db := range externalDBs {
+		ids := make([]ulid.ULID, 0, len(db.SstIds))
+		for _, id := range db.SstIds {
+			ids = append(ids, f.parseFlatBufSSTId(id))
+		}
+		result = append(result, ExternalDB{Path: db.Path, CheckpointID: db.CheckpointId, SSTIDs: ids})
+	}
+	return result
+}
+
+func (f FlatBufferManifestCodec) parseFlatBufCheckpoints(snapshots []*flatbuf.SnapshotT) []Checkpoint {
+	if len(snapshots) == 0 {
+		return nil
+	}
+	checkpoints := make([]Checkpoint, 0, len(snapshots))
+	for _, s := range snapshots {
+		checkpoint := Checkpoint{ID: s.Id, ManifestID: s.ManifestId}
+		if s.SnapshotExpireTimeS != 0 {
+			checkpoint.ExpireTime = time.Unix(int64(s.SnapshotExpireTimeS), 0).UTC()
+		}
+		checkpoints = append(checkpoints, checkpoint)
+	}
+	return checkpoints
+}
+
 func (f FlatBufferManifestCodec) parseFlatBufSSTId(sstID *flatbuf.CompactedSstIdT) ulid.ULID {
 	if sstID == nil || (sstID.High == 0 && sstID.Low == 0) {
 		return ulid.Zero
@@ -91,6 +238,21 @@ func (f FlatBufferManifestCodec) parseFlatBufSSTInfo(info *flatbuf.SsTableInfoT)
 		FilterOffset:     info.FilterOffset,
 		FilterLen:        info.FilterLen,
 		CompressionCodec: compress.CodecFromFlatBuf(info.CompressionFormat),
+
+		BlockCompressionFlags: info.BlockCompressionFlags,
+		RangeTombstones:       sstable.RangeTombstonesFromFlatBuf(info.RangeTombstones),
+		IngestSeq:             info.IngestSeq,
+		BlockChecksum:         block.Checksum(info.BlockChecksum),
+		FilterIndexLen:        info.FilterIndexLen,
+		FilterBitsPerKey:      info.FilterBitsPerKey,
+		FilterPrefixExtractor: info.FilterPrefixExtractor,
+		BlockRestartInterval:  info.BlockRestartInterval,
+		LastKey:               bytes.Clone(info.LastKey),
+		NumEntries:            info.NumEntries,
+		NumTombstones:         info.NumTombstones,
+		RawKeySize:            info.RawKeySize,
+		RawValueSize:          info.RawValueSize,
+		CreatedAt:             sstable.CreationTimeFromFlatBuf(info.CreationTimeMs),
 	}
 }
 
@@ -136,13 +298,98 @@ func (fb *DBFlatBufferBuilder) createManifest(manifest *Manifest) []byte {
 		L0LastCompacted:    l0LastCompacted,
 		L0:                 l0,
 		Compacted:          compacted,
-		Snapshots:          nil,
+		Snapshots:          fb.checkpointsToFlatBuf(manifest.Checkpoints),
+		Features:           uint64(manifest.Features),
+		LastSeq:            core.LastSeq.Load(),
+		ExternalDbs:        fb.externalDBsToFlatBuf(manifest.ExternalDBs),
+		FilterSidecars:     fb.sstIDsToFlatBuf(manifest.FilterSidecars),
+		Quarantined:        fb.quarantinedToFlatBuf(manifest.Quarantined),
+		Leases:             fb.leasesToFlatBuf(manifest.Leases),
 	}
 	manifestOffset := manifestV1.Pack(fb.builder)
 	fb.builder.Finish(manifestOffset)
 	return fb.builder.FinishedBytes()
 }
 
+// checkpointsToFlatBuf encodes the checkpoints as the snapshots of the manifest
+func (fb *DBFlatBufferBuilder) checkpointsToFlatBuf(checkpoints []Checkpoint) []*flatbuf.SnapshotT {
+	if len(checkpoints) == 0 {
+		return nil
+	}
+	snapshots := make([]*flatbuf.SnapshotT, 0, len(checkpoints))
+	for _, c := range checkpoints {
+		snapshot := &flatbuf.SnapshotT{Id: c.ID, ManifestId: c.ManifestID}
+		if !c.ExpireTime.IsZero() {
+			snapshot.SnapshotExpireTimeS = uint32(c.ExpireTime.Unix())
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	return snapshots
+}
+
+func (fb *DBFlatBufferBuilder) externalDBsToFlatBuf(externalDBs []ExternalDB) []*flatbuf.ExternalDbT {
+	if len(externalDBs) == 0 {
+		return nil
+	}
+	result := make([]*flatbuf.ExternalDbT, 0, len(externalDBs))
+	for _, db := range externalDBs {
+		ids := make([]*flatbuf.CompactedSstIdT, 0, len(db.SSTIDs))
+		for _, id := range db.SSTIDs {
+			ids = append(ids, fb.compactedSSTID(id))
+		}
+		result = append(result, &flatbuf.ExternalDbT{Path: db.Path, CheckpointId: db.CheckpointID, SstIds: ids})
+	}
+	return result
+}
+
+func (fb *DBFlatBufferBuilder) quarantinedToFlatBuf(quarantined []QuarantinedSST) []*flatbuf.QuarantinedSstT {
+	if len(quarantined) == 0 {
+		return nil
+	}
+	result := make([]*flatbuf.QuarantinedSstT, 0, len(quarantined))
+	for _, q := range quarantined {
+		sst := &flatbuf.QuarantinedSstT{Reason: q.Reason, QuarantineTimeS: uint32(q.Time.Unix())}
+		if id, ok := q.ID.CompactedID().Get(); ok {
+			sst.SstId = fb.compactedSSTID(id)
+		} else {
+			sst.WalId = q.ID.WalID().OrEmpty()
+		}
+		result = append(result, sst)
+	}
+	return result
+}
+
+func (fb *DBFlatBufferBuilder) leasesToFlatBuf(leases []Lease) []*flatbuf.SstLeaseT {
+	if len(leases) == 0 {
+		return nil
+	}
+	result := make([]*flatbuf.SstLeaseT, 0, len(leases))
+	for _, l := range leases {
+		// sst_ids is required, as such it is encoded even if the lease retains no SSTs
+		ids := make([]*flatbuf.CompactedSstIdT, 0, len(l.SSTIDs))
+		for _, id := range l.SSTIDs {
+			ids = append(ids, fb.compactedSSTID(id))
+		}
+		result = append(result, &flatbuf.SstLeaseT{
+			Id:           fb.compactedSSTID(l.ID),
+			SstIds:       ids,
+			ExpireTimeMs: uint64(l.ExpireTime.UnixMilli()),
+		})
+	}
+	return result
+}
+
+func (fb *DBFlatBufferBuilder) sstIDsToFlatBuf(ids []ulid.ULID) []*flatbuf.CompactedSstIdT {
+	if len(ids) == 0 {
+		return nil
+	}
+	result := make([]*flatbuf.CompactedSstIdT, 0, len(ids))
+	for _, id := range ids {
+		result = append(result, fb.compactedSSTID(id))
+	}
+	return result
+}
+
 func (fb *DBFlatBufferBuilder) sstListToFlatBuf(sstList []sstable.Handle) []*flatbuf.CompactedSsTableT {
 	compactedSSTs := make([]*flatbuf.CompactedSsTableT, 0)
 	for _, sst := range sstList {
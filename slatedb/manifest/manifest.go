@@ -1,8 +1,13 @@
 package manifest
 
 import (
+	"strconv"
+	"strings"
 	"sync/atomic"
+	"time"
 
+	"github.com/oklog/ulid/v2"
+	"github.com/slatedb/slatedb-go/internal/sstable"
 	"github.com/slatedb/slatedb-go/slatedb/state"
 )
 
@@ -10,9 +15,207 @@ type Manifest struct {
 	Core           *state.CoreDBState
 	WriterEpoch    atomic.Uint64
 	CompactorEpoch atomic.Uint64
+
+	// Features is the set of on-disk format features used by the database
+	Features Features
+
+	// Checkpoints is the set of checkpoints which pin the state described by an older manifest
+	Checkpoints []Checkpoint
+
+	// ExternalDBs is the set of databases whose SSTs are referenced by this database
+	ExternalDBs []ExternalDB
+
+	// FilterSidecars are the IDs of the compacted SSTs whose bloom filter is read from a
+	// sidecar filter object rather than from the SST, see DB.RebuildFilters
+	FilterSidecars []ulid.ULID
+
+	// Quarantined is the set of SSTs in which corruption was detected. Each remains in the
+	// list, and its object is retained, until it is removed by an operator
+	Quarantined []QuarantinedSST
+
+	// Leases is the set of leases which retain the SSTs read by readers in other processes
+	Leases []Lease
+}
+
+// QuarantinedSST is an SST in which corruption was detected. A quarantined SST may remain in the
+// state of the database, in which case the blocks which are not corrupted continue to be read.
+type QuarantinedSST struct {
+	// ID is the ID of the SST, which is either a compacted SST or a WAL SST
+	ID sstable.ID
+
+	// Reason describes the corruption which was detected
+	Reason string
+
+	// Time is the time the SST was quarantined. Time is stored with second precision.
+	Time time.Time
+}
+
+// ExternalDB is a database whose SSTs are referenced by another database, such as the source
+// of a clone. The SSTs are read from the path of the external database rather than copied.
+type ExternalDB struct {
+	// Path is the path of the database which holds the SSTs
+	Path string
+
+	// CheckpointID is the checkpoint of the external database from which the SSTs were referenced
+	CheckpointID uint64
+
+	// SSTIDs are the IDs of the compacted SSTs which are read from Path
+	SSTIDs []ulid.ULID
 }
 
 type Codec interface {
 	Encode(manifest *Manifest) []byte
 	Decode(data []byte) (*Manifest, error)
 }
+
+// ------------------------------------------------
+// Checkpoint
+// ------------------------------------------------
+
+// Checkpoint references the manifest which describes the state of the database at the time the
+// checkpoint was created. The manifest, and the SSTs it references, are retained until the
+// checkpoint is deleted or expires.
+type Checkpoint struct {
+	// ID uniquely identifies the checkpoint among the checkpoints of the database
+	ID uint64
+
+	// ManifestID is the ID of the manifest referenced by the checkpoint
+	ManifestID uint64
+
+	// ExpireTime is the time after which the checkpoint no longer pins the manifest. If
+	// zero, the checkpoint never expires. ExpireTime is stored with second precision.
+	ExpireTime time.Time
+}
+
+// Expired returns true if the checkpoint has an ExpireTime which is before `now`
+func (c Checkpoint) Expired(now time.Time) bool {
+	return !c.ExpireTime.IsZero() && c.ExpireTime.Before(now)
+}
+
+// ------------------------------------------------
+// Lease
+// ------------------------------------------------
+
+// Lease retains compacted SSTs from garbage collection on behalf of a reader which does not
+// write manifests, such as an iterator of a read-only database in another process. The holder
+// renews the lease before it expires, and removes it once it no longer reads the SSTs.
+type Lease struct {
+	// ID uniquely identifies the lease among the leases of the database
+	ID ulid.ULID
+
+	// SSTIDs are the IDs of the compacted SSTs retained by the lease
+	SSTIDs []ulid.ULID
+
+	// ExpireTime is the time after which the lease no longer retains the SSTs. ExpireTime
+	// is stored with millisecond precision.
+	ExpireTime time.Time
+}
+
+// Expired returns true if the lease has an ExpireTime which is before `now`
+func (l Lease) Expired(now time.Time) bool {
+	return l.ExpireTime.Before(now)
+}
+
+// ------------------------------------------------
+// Features
+// ------------------------------------------------
+
+// Features is a bit set of on-disk format features. Once data has been written using a
+// feature, the feature is recorded in the manifest and a binary which does not support it
+// refuses to open the database rather than misreading the data written with it.
+type Features uint64
+
+const (
+	// FeatureBlockCompressionFlags indicates SST blocks may carry a flag byte recording
+	// whether the block is compressed. See config.DBOptions.AutoCompression
+	FeatureBlockCompressionFlags Features = 1 << iota
+
+	// FeatureFilterHash indicates bloom filters may be built with a hash function
+	// or seed other than the default. See config.DBOptions.FilterHash
+	FeatureFilterHash
+
+	// FeatureRangeTombstones indicates SSTs may hold range tombstones written by DB.DeleteRange
+	FeatureRangeTombstones
+
+	// FeatureValueChecksums indicates SST rows may carry a checksum of the value.
+	// See config.DBOptions.ValueChecksums
+	FeatureValueChecksums
+
+	// FeatureBlockCRC32C indicates SST blocks may carry a CRC32C checksum rather than a
+	// CRC32 (IEEE) checksum. See sstable.Info.BlockChecksum
+	FeatureBlockCRC32C
+
+	// FeaturePartitionedFilters indicates the bloom filter of an SST may be partitioned.
+	// See sstable.Info.FilterIndexLen
+	FeaturePartitionedFilters
+
+	// FeatureBlockRestarts indicates the keys of SST blocks may be stored relative to the previous
+	// key with restart points. See sstable.Info.BlockRestartInterval
+	FeatureBlockRestarts
+
+	// FeatureWALEntryChecksums indicates the rows of WAL SSTs may carry a checksum of the entire
+	// row. See sstable.Config.EntryChecksums
+	FeatureWALEntryChecksums
+
+	// FeatureCompressedManifest indicates the manifest object is compressed. It is only set in the
+	// header of a compressed manifest object, such that versions of slatedb which cannot decompress
+	// the manifest reject it as unsupported. See FlatBufferManifestCodec.Compression
+	FeatureCompressedManifest
+)
+
+// FormatVersion is the version of the manifest format, see flatbuf.ManifestV1. Additions to
+// the format which older binaries cannot read are recorded as Features rather than a new version.
+const FormatVersion = 1
+
+// SupportedFeatures is the set of features this binary can read and write
+const SupportedFeatures = FeatureBlockCompressionFlags | FeatureFilterHash | FeatureRangeTombstones |
+	FeatureValueChecksums | FeatureBlockCRC32C | FeaturePartitionedFilters | FeatureBlockRestarts |
+	FeatureWALEntryChecksums | FeatureCompressedManifest
+
+var featureNames = []struct {
+	feature Features
+	name    string
+}{
+	{FeatureBlockCompressionFlags, "block_compression_flags"},
+	{FeatureFilterHash, "filter_hash"},
+	{FeatureRangeTombstones, "range_tombstones"},
+	{FeatureValueChecksums, "value_checksums"},
+	{FeatureBlockCRC32C, "block_crc32c"},
+	{FeaturePartitionedFilters, "partitioned_filters"},
+	{FeatureBlockRestarts, "block_restarts"},
+	{FeatureWALEntryChecksums, "wal_entry_checksums"},
+	{FeatureCompressedManifest, "compressed_manifest"},
+}
+
+// Names returns the names of the features which are set. Features unknown to this
+// binary are named by bit, for example "unknown_63"
+func (f Features) Names() []string {
+	names := make([]string, 0)
+	for _, fn := range featureNames {
+		if f.Has(fn.feature) {
+			names = append(names, fn.name)
+			f &^= fn.feature
+		}
+	}
+	for bit := 0; f != 0; bit++ {
+		if f&1 != 0 {
+			names = append(names, "unknown_"+strconv.Itoa(bit))
+		}
+		f >>= 1
+	}
+	return names
+}
+
+// Has returns true if every feature in `features` is set
+func (f Features) Has(features Features) bool {
+	return f&features == features
+}
+
+// Unsupported returns the features which this binary does not support
+func (f Features) Unsupported() Features {
+	return f &^ SupportedFeatures
+}
+
+func (f Features) String() string {
+	return strings.Join(f.Names(), ",")
+}
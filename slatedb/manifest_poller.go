@@ -0,0 +1,163 @@
+package slatedb
+
+import (
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/samber/mo"
+	"github.com/thanos-io/objstore"
+
+	"github.com/slatedb/slatedb-go/slatedb/config"
+	"github.com/slatedb/slatedb-go/slatedb/store"
+)
+
+// ------------------------------------------------
+// manifestPoller
+// ------------------------------------------------
+
+// manifestPollers holds the manifestPoller of each database polled by the DBReaders of this process,
+// such that DBReaders of the same database read its manifest once per poll interval rather than once each
+var manifestPollers = struct {
+	sync.Mutex
+	m map[manifestPollerKey]*manifestPoller
+}{m: make(map[manifestPollerKey]*manifestPoller)}
+
+// manifestPollerKey identifies the DBReaders which share a manifestPoller. DBReaders share a
+// poller only if they poll the same database at the same interval of the same clock.
+type manifestPollerKey struct {
+	bucket   objstore.Bucket
+	path     string
+	interval time.Duration
+	clock    config.Clock
+}
+
+// shareable returns true if the key may be used as a map key, which requires the bucket and
+// clock to be comparable
+func (k manifestPollerKey) shareable() bool {
+	return reflect.TypeOf(k.bucket).Comparable() && reflect.TypeOf(k.clock).Comparable()
+}
+
+// manifestPoll is the result of a read of the latest manifest by a manifestPoller
+type manifestPoll struct {
+	manifest mo.Option[*store.StoredManifest]
+	err      error
+}
+
+// manifestPoller reads the latest manifest of a database each interval and fans it out to every
+// subscription. The manifest is shared between subscribers, which must not modify it.
+type manifestPoller struct {
+	key           manifestPollerKey
+	manifestStore *store.ManifestStore
+
+	// refs is the number of subscriptions, guarded by manifestPollers
+	refs int
+
+	mu          sync.Mutex
+	subscribers map[*manifestSubscription]struct{}
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// manifestSubscription receives the manifests read by a manifestPoller. Only the latest manifest
+// read is held, such that a subscriber which falls behind skips to the latest manifest.
+type manifestSubscription struct {
+	poller *manifestPoller
+	ch     chan manifestPoll
+}
+
+// subscribeManifest returns a subscription to the manifest of the database at `path`, which is read
+// from `manifestStore` each `interval`. The poller is shared with the other subscriptions of the
+// process to the same database, interval and clock.
+func subscribeManifest(manifestStore *store.ManifestStore, bucket objstore.Bucket, path string,
+	interval time.Duration, clock config.Clock) *manifestSubscription {
+	key := manifestPollerKey{bucket: bucket, path: path, interval: interval, clock: clock}
+	sub := &manifestSubscription{ch: make(chan manifestPoll, 1)}
+
+	manifestPollers.Lock()
+	defer manifestPollers.Unlock()
+	poller, ok := manifestPollers.m[key]
+	if !ok || !key.shareable() {
+		poller = newManifestPoller(key, manifestStore)
+		if key.shareable() {
+			manifestPollers.m[key] = poller
+		}
+	}
+	poller.refs++
+	sub.poller = poller
+
+	poller.mu.Lock()
+	poller.subscribers[sub] = struct{}{}
+	poller.mu.Unlock()
+	return sub
+}
+
+func newManifestPoller(key manifestPollerKey, manifestStore *store.ManifestStore) *manifestPoller {
+	p := &manifestPoller{
+		key:           key,
+		manifestStore: manifestStore,
+		subscribers:   make(map[*manifestSubscription]struct{}),
+		stop:          make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+	// The ticker is created before the poller is returned, such that a tick of a manual clock
+	// which follows the subscription is not missed
+	ticker := key.clock.NewTicker(key.interval)
+	go func() {
+		defer close(p.done)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C():
+				p.poll()
+			case <-p.stop:
+				return
+			}
+		}
+	}()
+	return p
+}
+
+// poll reads the latest manifest and delivers it to every subscription
+func (p *manifestPoller) poll() {
+	stored, err := store.LoadStoredManifest(p.manifestStore)
+	result := manifestPoll{manifest: stored, err: err}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for sub := range p.subscribers {
+		// Replace the manifest the subscriber has not received yet
+		select {
+		case <-sub.ch:
+		default:
+		}
+		sub.ch <- result
+	}
+}
+
+// C returns the channel which receives the manifests read by the poller
+func (s *manifestSubscription) C() <-chan manifestPoll {
+	return s.ch
+}
+
+// close removes the subscription, and stops the poller once it has no subscriptions
+func (s *manifestSubscription) close() {
+	p := s.poller
+	p.mu.Lock()
+	delete(p.subscribers, s)
+	p.mu.Unlock()
+
+	manifestPollers.Lock()
+	p.refs--
+	last := p.refs == 0
+	if last && manifestPollers.m[p.key] == p {
+		delete(manifestPollers.m, p.key)
+	}
+	manifestPollers.Unlock()
+
+	if last {
+		close(p.stop)
+		<-p.done
+	}
+}
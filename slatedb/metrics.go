@@ -0,0 +1,210 @@
+package slatedb
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// ------------------------------------------------
+// Metrics
+// ------------------------------------------------
+
+// metricsSampleInterval is how often the counters of the database are sampled. The windows
+// returned by DB.Metrics start at the most recent sample taken at least the window ago.
+const metricsSampleInterval = 10 * time.Second
+
+// metricsRetention is the longest window retained by DB.Metrics
+const metricsRetention = time.Hour
+
+// Metrics reports the activity of the database over the last minute, five minutes and hour,
+// such that a process without external monitoring retains enough data to investigate an incident.
+type Metrics struct {
+	OneMinute   MetricsWindow
+	FiveMinutes MetricsWindow
+	OneHour     MetricsWindow
+}
+
+// MetricsWindow reports the activity of the database within a window of time
+type MetricsWindow struct {
+	// Duration is the length of time covered by the window. It is less than the length of
+	// the window if the database was opened more recently.
+	Duration time.Duration
+
+	// Gets, Writes and Scans are the number of calls to Get, to Put or Delete, and to Scan
+	Gets   uint64
+	Writes uint64
+	Scans  uint64
+
+	// GetsPerSecond, WritesPerSecond and ScansPerSecond are the average rates of each operation
+	GetsPerSecond   float64
+	WritesPerSecond float64
+	ScansPerSecond  float64
+
+	// ScanSSTsSkipped is the number of SSTs skipped by scans because their bloom filter excluded
+	// the prefix of the range, see config.DBOptions.PrefixExtractor
+	ScanSSTsSkipped uint64
+
+	// GetLatency and WriteLatency are the mean latencies of Get and of Put or Delete. The
+	// latency of a write includes waiting for the write to be durable if it was requested.
+	GetLatency   time.Duration
+	WriteLatency time.Duration
+
+	// BlockCacheHits and BlockCacheMisses are the number of blocks read from and missing from the block cache
+	BlockCacheHits   uint64
+	BlockCacheMisses uint64
+
+	// MemtableFlushes is the number of memtables flushed to L0
+	MemtableFlushes uint64
+
+	// Compactions is the number of compactions completed by the compactor of this process
+	Compactions uint64
+}
+
+// metricCounters is a sample of the cumulative counters the metrics windows are computed from
+type metricCounters struct {
+	gets             uint64
+	getNanos         uint64
+	writes           uint64
+	writeNanos       uint64
+	scans            uint64
+	scanSSTsSkipped  uint64
+	blockCacheHits   uint64
+	blockCacheMisses uint64
+	memtableFlushes  uint64
+	compactions      uint64
+}
+
+type metricsSample struct {
+	at       time.Time
+	counters metricCounters
+}
+
+// metricsHistory retains the samples of the counters taken within the last metricsRetention
+type metricsHistory struct {
+	mu      sync.Mutex
+	samples []metricsSample
+}
+
+// add records a sample, discarding samples which are no longer needed to compute the longest window
+func (h *metricsHistory) add(at time.Time, counters metricCounters) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.samples = append(h.samples, metricsSample{at: at, counters: counters})
+	// Keep the newest sample older than the retention, as it is the start of the longest window
+	drop := 0
+	for drop+1 < len(h.samples) && !h.samples[drop+1].at.After(at.Add(-metricsRetention)) {
+		drop++
+	}
+	h.samples = h.samples[drop:]
+}
+
+// window returns the activity between the start of the window of length `d` ending `now`
+// and `current`, the counters at `now`
+func (h *metricsHistory) window(now time.Time, d time.Duration, current metricCounters) MetricsWindow {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.samples) == 0 {
+		return MetricsWindow{}
+	}
+	// The window starts at the newest sample taken at least `d` ago, or the oldest
+	// sample if the database was opened less than `d` ago
+	start := h.samples[0]
+	for _, s := range h.samples[1:] {
+		if s.at.After(now.Add(-d)) {
+			break
+		}
+		start = s
+	}
+
+	w := MetricsWindow{
+		Duration:         now.Sub(start.at),
+		Gets:             current.gets - start.counters.gets,
+		Writes:           current.writes - start.counters.writes,
+		Scans:            current.scans - start.counters.scans,
+		ScanSSTsSkipped:  current.scanSSTsSkipped - start.counters.scanSSTsSkipped,
+		BlockCacheHits:   current.blockCacheHits - start.counters.blockCacheHits,
+		BlockCacheMisses: current.blockCacheMisses - start.counters.blockCacheMisses,
+		MemtableFlushes:  current.memtableFlushes - start.counters.memtableFlushes,
+		Compactions:      current.compactions - start.counters.compactions,
+	}
+	if seconds := w.Duration.Seconds(); seconds > 0 {
+		w.GetsPerSecond = float64(w.Gets) / seconds
+		w.WritesPerSecond = float64(w.Writes) / seconds
+		w.ScansPerSecond = float64(w.Scans) / seconds
+	}
+	if w.Gets > 0 {
+		w.GetLatency = time.Duration((current.getNanos - start.counters.getNanos) / w.Gets)
+	}
+	if w.Writes > 0 {
+		w.WriteLatency = time.Duration((current.writeNanos - start.counters.writeNanos) / w.Writes)
+	}
+	return w
+}
+
+// Metrics returns the activity of the database over the last minute, five minutes and hour.
+// The windows are sampled every 10 seconds, such that each window may include up to 10
+// seconds more than its length.
+func (db *DB) Metrics() Metrics {
+	now := db.opts.Clock.Now()
+	current := db.metricCounters()
+	return Metrics{
+		OneMinute:   db.metrics.window(now, time.Minute, current),
+		FiveMinutes: db.metrics.window(now, 5*time.Minute, current),
+		OneHour:     db.metrics.window(now, time.Hour, current),
+	}
+}
+
+func (db *DB) metricCounters() metricCounters {
+	cache := db.tableStore.BlockCacheStats()
+	counters := metricCounters{
+		gets:             db.stats.gets.Load(),
+		getNanos:         db.stats.getNanos.Load(),
+		writes:           db.stats.writes.Load(),
+		writeNanos:       db.stats.writeNanos.Load(),
+		scans:            db.stats.scans.Load(),
+		scanSSTsSkipped:  db.stats.scanSSTsSkipped.Load(),
+		blockCacheHits:   cache.Hits,
+		blockCacheMisses: cache.Misses,
+		memtableFlushes:  db.stats.memtableFlushes.Load(),
+	}
+	if db.compactor != nil {
+		counters.compactions = db.compactor.CompactionsCompleted()
+	}
+	return counters
+}
+
+// recordGet records a call to Get which started at `start`
+func (db *DB) recordGet(start time.Time) {
+	db.stats.gets.Add(1)
+	db.stats.getNanos.Add(uint64(max(db.opts.Clock.Now().Sub(start), 0)))
+	observeSince(db.prom.getLatency, db.opts.Clock, start)
+}
+
+// recordWrite records a call to Put or Delete which started at `start`
+func (db *DB) recordWrite(start time.Time) {
+	db.stats.writes.Add(1)
+	db.stats.writeNanos.Add(uint64(max(db.opts.Clock.Now().Sub(start), 0)))
+	observeSince(db.prom.writeLatency, db.opts.Clock, start)
+}
+
+// spawnMetricsTask samples the counters of the database every metricsSampleInterval.
+// It must be called once the DB is fully initialized, as the sample includes the compactor.
+func (db *DB) spawnMetricsTask() {
+	db.metrics.add(db.opts.Clock.Now(), db.metricCounters())
+	db.tasks.Go("metrics_sample", func(ctx context.Context) error {
+		ticker := db.newIdleTicker(metricsSampleInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case now := <-ticker.C():
+				db.metrics.add(now, db.metricCounters())
+				ticker.pauseIfIdle()
+			case <-ticker.Woken():
+				ticker.resume()
+			case <-ctx.Done():
+				return nil
+			}
+		}
+	})
+}
@@ -0,0 +1,153 @@
+package slatedb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore"
+)
+
+func TestMetrics(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	bucket := objstore.NewInMemBucket()
+	options := testDBOptions(0, 1024)
+	options.BlockCacheSize = 1024 * 1024
+	db, err := OpenWithOptions(ctx, "/tmp/test_kv_store", bucket, options)
+	require.NoError(t, err)
+	defer func() { _ = db.Close(ctx) }()
+
+	require.NoError(t, db.Put(ctx, []byte("key1"), []byte("value1")))
+	require.NoError(t, db.Delete(ctx, []byte("key2")))
+	require.NoError(t, db.FlushMemtableToL0())
+	_, err = db.Get(ctx, []byte("key1"))
+	require.NoError(t, err)
+	_, err = db.Scan(ctx, nil, nil)
+	require.NoError(t, err)
+
+	for _, w := range []MetricsWindow{db.Metrics().OneMinute, db.Metrics().OneHour} {
+		assert.Equal(t, uint64(1), w.Gets)
+		assert.Equal(t, uint64(2), w.Writes)
+		assert.Equal(t, uint64(1), w.Scans)
+		assert.Equal(t, uint64(1), w.MemtableFlushes)
+		assert.Equal(t, uint64(1), w.BlockCacheMisses)
+		assert.Positive(t, w.Duration)
+		assert.Positive(t, w.WritesPerSecond)
+	}
+}
+
+func TestWriteStageTimings(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	bucket := objstore.NewInMemBucket()
+	options := testDBOptions(0, 1024)
+	options.ProfileLabels = true
+	db, err := OpenWithOptions(ctx, "/tmp/test_kv_store", bucket, options)
+	require.NoError(t, err)
+	defer func() { _ = db.Close(ctx) }()
+
+	require.NoError(t, db.Put(ctx, []byte("key1"), []byte("value1")))
+	require.NoError(t, db.FlushMemtableToL0())
+
+	timings := db.WriteStageTimings()
+	for _, stage := range []StageTimings{timings.WALFlush, timings.MemtableFlush} {
+		assert.Positive(t, stage.Encode)
+		assert.Positive(t, stage.Compress)
+		assert.Positive(t, stage.Upload)
+		assert.Zero(t, stage.Read)
+	}
+	assert.Zero(t, timings.WALFlush.Manifest)
+	assert.Positive(t, timings.MemtableFlush.Manifest)
+}
+
+func TestMetricsHistoryWindows(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var history metricsHistory
+	assert.Equal(t, MetricsWindow{}, history.window(start, time.Minute, metricCounters{}))
+
+	// sample one get per second for two hours
+	for i := 0; i <= 720; i++ {
+		history.add(start.Add(time.Duration(i)*metricsSampleInterval),
+			metricCounters{gets: uint64(i * 10), getNanos: uint64(i * 10 * 1000)})
+	}
+	now := start.Add(2 * time.Hour)
+	current := metricCounters{gets: 7200, getNanos: 7200 * 1000}
+
+	minute := history.window(now, time.Minute, current)
+	assert.Equal(t, time.Minute, minute.Duration)
+	assert.Equal(t, uint64(60), minute.Gets)
+	assert.Equal(t, 1.0, minute.GetsPerSecond)
+	assert.Equal(t, time.Microsecond, minute.GetLatency)
+
+	assert.Equal(t, uint64(300), history.window(now, 5*time.Minute, current).Gets)
+	assert.Equal(t, uint64(3600), history.window(now, time.Hour, current).Gets)
+
+	// samples older than the longest window are discarded
+	assert.Equal(t, metricsRetention, history.window(now, 2*time.Hour, current).Duration)
+
+	// a window longer than the history covers the history
+	var recent metricsHistory
+	recent.add(start, metricCounters{})
+	w := recent.window(start.Add(30*time.Second), time.Hour, metricCounters{gets: 30})
+	assert.Equal(t, 30*time.Second, w.Duration)
+	assert.Equal(t, 1.0, w.GetsPerSecond)
+}
+
+func TestPrometheusMetrics(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	bucket := objstore.NewInMemBucket()
+	registry := prometheus.NewRegistry()
+	options := testDBOptions(0, 1024)
+	options.BlockCacheSize = 1024 * 1024
+	options.MetricsRegisterer = registry
+	db, err := OpenWithOptions(ctx, "/tmp/test_kv_store", bucket, options)
+	require.NoError(t, err)
+
+	// a second database cannot register the same metrics with the registry
+	_, err = OpenWithOptions(ctx, "/tmp/test_kv_store_2", bucket, options)
+	require.Error(t, err)
+
+	require.NoError(t, db.Put(ctx, []byte("key1"), []byte("value1")))
+	require.NoError(t, db.FlushMemtableToL0())
+	_, err = db.Get(ctx, []byte("key1"))
+	require.NoError(t, err)
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	metrics := make(map[string]*dto.MetricFamily)
+	for _, f := range families {
+		metrics[f.GetName()] = f
+	}
+	assert.Equal(t, uint64(1), metrics["slatedb_get_duration_seconds"].Metric[0].GetHistogram().GetSampleCount())
+	assert.Equal(t, uint64(1), metrics["slatedb_write_duration_seconds"].Metric[0].GetHistogram().GetSampleCount())
+	assert.Positive(t, metrics["slatedb_wal_flush_duration_seconds"].Metric[0].GetHistogram().GetSampleCount())
+	assert.Positive(t, metrics["slatedb_wal_flush_bytes_total"].Metric[0].GetCounter().GetValue())
+	assert.Equal(t, 1.0, metrics["slatedb_memtable_flushes_total"].Metric[0].GetCounter().GetValue())
+	assert.Equal(t, 1.0, metrics["slatedb_block_cache_misses_total"].Metric[0].GetCounter().GetValue())
+	var uploads float64
+	for _, m := range metrics["slatedb_object_store_requests_total"].Metric {
+		if m.GetLabel()[0].GetValue() == "upload" {
+			uploads = m.GetCounter().GetValue()
+		}
+	}
+	assert.Positive(t, uploads)
+	conflicts := metrics["slatedb_manifest_conflicts_total"].Metric
+	require.Len(t, conflicts, 1)
+	assert.Equal(t, "writer", conflicts[0].GetLabel()[0].GetValue())
+	assert.Equal(t, 0.0, conflicts[0].GetCounter().GetValue())
+
+	// the metrics are unregistered by Close, such that the database may be opened again
+	require.NoError(t, db.Close(ctx))
+	db, err = OpenWithOptions(ctx, "/tmp/test_kv_store", bucket, options)
+	require.NoError(t, err)
+	require.NoError(t, db.Close(ctx))
+}
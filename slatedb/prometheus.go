@@ -0,0 +1,293 @@
+package slatedb
+
+import (
+	"context"
+	"io"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/thanos-io/objstore"
+
+	"github.com/slatedb/slatedb-go/slatedb/compaction"
+	"github.com/slatedb/slatedb-go/slatedb/config"
+)
+
+// ------------------------------------------------
+// Prometheus Metrics
+// ------------------------------------------------
+
+// promNamespace is the prefix of the name of every metric, see config.DBOptions.MetricsRegisterer
+const promNamespace = "slatedb"
+
+// promMetrics is the prometheus.Collector of the metrics of a DB or Compactor. Latencies and the
+// requests made to object storage are observed as they happen, while the counters the database
+// already maintains, such as those of the block cache and of compaction, are read when collected.
+type promMetrics struct {
+	getLatency   prometheus.Histogram
+	writeLatency prometheus.Histogram
+
+	walFlushDuration prometheus.Histogram
+	walFlushBytes    prometheus.Counter
+
+	objectStoreRequests *prometheus.CounterVec
+	objectStoreErrors   *prometheus.CounterVec
+	objectStoreLatency  *prometheus.HistogramVec
+
+	memtableFlushes        *prometheus.Desc
+	blockCacheHits         *prometheus.Desc
+	blockCacheMisses       *prometheus.Desc
+	compactions            *prometheus.Desc
+	compactionBytesRead    *prometheus.Desc
+	compactionBytesWritten *prometheus.Desc
+
+	// manifestConflicts is labelled with the process whose manifest writes conflicted, the
+	// writer or the compactor
+	manifestConflicts *prometheus.Desc
+
+	// db and compactor are the sources of the counters read when the metrics are collected.
+	// They are set once the DB or Compactor is open, see bind
+	db        atomic.Pointer[DB]
+	compactor atomic.Pointer[compaction.Compactor]
+}
+
+func newPromMetrics() *promMetrics {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(promNamespace, "", name), help, nil, nil)
+	}
+	return &promMetrics{
+		getLatency: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: promNamespace,
+			Name:      "get_duration_seconds",
+			Help:      "Latency of calls to Get.",
+			Buckets:   prometheus.ExponentialBuckets(0.0001, 4, 10),
+		}),
+		writeLatency: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: promNamespace,
+			Name:      "write_duration_seconds",
+			Help:      "Latency of calls to Put, Delete and Write, including waiting for durability.",
+			Buckets:   prometheus.ExponentialBuckets(0.0001, 4, 10),
+		}),
+		walFlushDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: promNamespace,
+			Name:      "wal_flush_duration_seconds",
+			Help:      "Duration of the writes of WAL SSTs to object storage.",
+			Buckets:   prometheus.ExponentialBuckets(0.001, 2, 14),
+		}),
+		walFlushBytes: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: promNamespace,
+			Name:      "wal_flush_bytes_total",
+			Help:      "Bytes of the WAL SSTs written to object storage.",
+		}),
+		objectStoreRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: promNamespace,
+			Name:      "object_store_requests_total",
+			Help:      "Requests made to object storage, by operation.",
+		}, []string{"operation"}),
+		objectStoreErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: promNamespace,
+			Name:      "object_store_request_errors_total",
+			Help:      "Requests made to object storage which failed, other than reads of missing objects, by operation.",
+		}, []string{"operation"}),
+		objectStoreLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: promNamespace,
+			Name:      "object_store_request_duration_seconds",
+			Help:      "Latency of requests made to object storage, by operation. Reads are timed to the first byte.",
+			Buckets:   prometheus.ExponentialBuckets(0.001, 2, 14),
+		}, []string{"operation"}),
+		memtableFlushes:        desc("memtable_flushes_total", "Memtables flushed to L0."),
+		blockCacheHits:         desc("block_cache_hits_total", "Blocks read from the block cache."),
+		blockCacheMisses:       desc("block_cache_misses_total", "Blocks missing from the block cache."),
+		compactions:            desc("compactions_total", "Compactions committed to the manifest."),
+		compactionBytesRead:    desc("compaction_read_bytes_total", "Bytes of the SSTs read by compactions."),
+		compactionBytesWritten: desc("compaction_written_bytes_total", "Bytes of the SSTs written by compactions."),
+		manifestConflicts: prometheus.NewDesc(prometheus.BuildFQName(promNamespace, "", "manifest_conflicts_total"),
+			"Manifest writes which conflicted with a manifest written by another process, by process.",
+			[]string{"process"}, nil),
+	}
+}
+
+// bind sets the DB whose counters are collected, along with the counters of its compactor
+func (m *promMetrics) bind(db *DB) {
+	if db.compactor != nil {
+		m.compactor.Store(db.compactor)
+	}
+	m.db.Store(db)
+}
+
+func (m *promMetrics) Describe(ch chan<- *prometheus.Desc) {
+	m.getLatency.Describe(ch)
+	m.writeLatency.Describe(ch)
+	m.walFlushDuration.Describe(ch)
+	m.walFlushBytes.Describe(ch)
+	m.objectStoreRequests.Describe(ch)
+	m.objectStoreErrors.Describe(ch)
+	m.objectStoreLatency.Describe(ch)
+	ch <- m.memtableFlushes
+	ch <- m.blockCacheHits
+	ch <- m.blockCacheMisses
+	ch <- m.compactions
+	ch <- m.compactionBytesRead
+	ch <- m.compactionBytesWritten
+	ch <- m.manifestConflicts
+}
+
+func (m *promMetrics) Collect(ch chan<- prometheus.Metric) {
+	counter := func(desc *prometheus.Desc, value uint64, labels ...string) {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.CounterValue, float64(value), labels...)
+	}
+
+	m.getLatency.Collect(ch)
+	m.writeLatency.Collect(ch)
+	m.walFlushDuration.Collect(ch)
+	m.walFlushBytes.Collect(ch)
+	m.objectStoreRequests.Collect(ch)
+	m.objectStoreErrors.Collect(ch)
+	m.objectStoreLatency.Collect(ch)
+	if db := m.db.Load(); db != nil {
+		cache := db.tableStore.BlockCacheStats()
+		counter(m.memtableFlushes, db.stats.memtableFlushes.Load())
+		counter(m.blockCacheHits, cache.Hits)
+		counter(m.blockCacheMisses, cache.Misses)
+		if db.manifest != nil {
+			counter(m.manifestConflicts, db.manifest.Conflicts(), "writer")
+		}
+	}
+	if c := m.compactor.Load(); c != nil {
+		counter(m.compactions, c.CompactionsCompleted())
+		counter(m.compactionBytesRead, c.BytesRead())
+		counter(m.compactionBytesWritten, c.BytesWritten())
+		counter(m.manifestConflicts, c.ManifestConflicts(), "compactor")
+	}
+}
+
+// register registers the metrics with `reg`, if it is not nil
+func (m *promMetrics) register(reg prometheus.Registerer) error {
+	if reg == nil {
+		return nil
+	}
+	return reg.Register(m)
+}
+
+// unregister unregisters the metrics from `reg`, if it is not nil
+func (m *promMetrics) unregister(reg prometheus.Registerer) {
+	if reg != nil {
+		reg.Unregister(m)
+	}
+}
+
+// observeSince observes the seconds elapsed between `start` and now according to `clock`
+func observeSince(o prometheus.Observer, clock config.Clock, start time.Time) {
+	o.Observe(max(clock.Now().Sub(start), 0).Seconds())
+}
+
+// ------------------------------------------------
+// Object Store Metrics
+// ------------------------------------------------
+
+// metricsBucket wraps an objstore.Bucket and counts and times the requests made to it
+type metricsBucket struct {
+	objstore.Bucket
+	metrics *promMetrics
+}
+
+// instrumentBucket returns `bucket` wrapped such that its requests are recorded by `m`
+func (m *promMetrics) instrumentBucket(bucket objstore.Bucket) objstore.Bucket {
+	for _, op := range []string{"get", "get_range", "upload", "delete", "exists", "attributes", "iter"} {
+		// Initialize each operation such that rates of zero are exported before the first request
+		m.objectStoreRequests.WithLabelValues(op)
+		m.objectStoreErrors.WithLabelValues(op)
+	}
+	return &metricsBucket{Bucket: bucket, metrics: m}
+}
+
+// record records a request of `op` which started at `start` and failed with `err`, if not nil
+func (b *metricsBucket) record(op string, start time.Time, err error) {
+	b.metrics.objectStoreRequests.WithLabelValues(op).Inc()
+	b.metrics.objectStoreLatency.WithLabelValues(op).Observe(time.Since(start).Seconds())
+	if err != nil && !b.IsObjNotFoundErr(err) {
+		b.metrics.objectStoreErrors.WithLabelValues(op).Inc()
+	}
+}
+
+// Unwrap returns the bucket whose requests are recorded
+func (b *metricsBucket) Unwrap() objstore.Bucket {
+	return b.Bucket
+}
+
+func (b *metricsBucket) Get(ctx context.Context, name string) (io.ReadCloser, error) {
+	start := time.Now()
+	rc, err := b.Bucket.Get(ctx, name)
+	b.record("get", start, err)
+	return rc, err
+}
+
+func (b *metricsBucket) GetRange(ctx context.Context, name string, off, length int64) (io.ReadCloser, error) {
+	start := time.Now()
+	rc, err := b.Bucket.GetRange(ctx, name, off, length)
+	b.record("get_range", start, err)
+	return rc, err
+}
+
+func (b *metricsBucket) Upload(ctx context.Context, name string, r io.Reader) error {
+	start := time.Now()
+	err := b.Bucket.Upload(ctx, name, r)
+	b.record("upload", start, err)
+	return err
+}
+
+// UploadIfNotExists implements store.ConditionalBucket, and is recorded as an upload. If the
+// wrapped bucket is not a store.ConditionalBucket, the existence of the object is checked with
+// Exists before it is uploaded, as it is for a bucket which is not a store.ConditionalBucket.
+func (b *metricsBucket) UploadIfNotExists(ctx context.Context, name string, r io.Reader) (bool, error) {
+	cb, ok := b.Bucket.(interface {
+		UploadIfNotExists(ctx context.Context, name string, r io.Reader) (bool, error)
+	})
+	if !ok {
+		exists, err := b.Exists(ctx, name)
+		if err != nil || exists {
+			return false, err
+		}
+		return true, b.Upload(ctx, name, r)
+	}
+	start := time.Now()
+	uploaded, err := cb.UploadIfNotExists(ctx, name, r)
+	b.record("upload", start, err)
+	return uploaded, err
+}
+
+func (b *metricsBucket) Delete(ctx context.Context, name string) error {
+	start := time.Now()
+	err := b.Bucket.Delete(ctx, name)
+	b.record("delete", start, err)
+	return err
+}
+
+func (b *metricsBucket) Exists(ctx context.Context, name string) (bool, error) {
+	start := time.Now()
+	exists, err := b.Bucket.Exists(ctx, name)
+	b.record("exists", start, err)
+	return exists, err
+}
+
+func (b *metricsBucket) Attributes(ctx context.Context, name string) (objstore.ObjectAttributes, error) {
+	start := time.Now()
+	attrs, err := b.Bucket.Attributes(ctx, name)
+	b.record("attributes", start, err)
+	return attrs, err
+}
+
+func (b *metricsBucket) Iter(ctx context.Context, dir string, f func(string) error, options ...objstore.IterOption) error {
+	start := time.Now()
+	err := b.Bucket.Iter(ctx, dir, f, options...)
+	b.record("iter", start, err)
+	return err
+}
+
+func (b *metricsBucket) IterWithAttributes(ctx context.Context, dir string, f func(objstore.IterObjectAttributes) error,
+	options ...objstore.IterOption) error {
+	start := time.Now()
+	err := b.Bucket.IterWithAttributes(ctx, dir, f, options...)
+	b.record("iter", start, err)
+	return err
+}
@@ -0,0 +1,224 @@
+package slatedb
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/slatedb/slatedb-go/internal/sstable"
+	"github.com/slatedb/slatedb-go/slatedb/manifest"
+	"github.com/slatedb/slatedb-go/slatedb/state"
+	"github.com/slatedb/slatedb-go/slatedb/store"
+)
+
+// QuarantinedSST describes an SST which was quarantined because corruption was detected in it,
+// see config.DBOptions.QuarantineCorruptSSTs
+type QuarantinedSST struct {
+	// SSTID is the ID of the SST, which is the ULID of an L0 or compacted SST, or the zero
+	// padded ID of a WAL SST
+	SSTID string
+
+	// WAL is true if the SST is a WAL SST
+	WAL bool
+
+	// Reason describes the corruption which was detected
+	Reason string
+
+	// Time is the time the SST was quarantined, with second precision once it is recorded in
+	// the manifest
+	Time time.Time
+}
+
+// Quarantined returns the SSTs which are quarantined, including the SSTs quarantined by this
+// process which have not yet been recorded in the manifest. A quarantined SST remains in the
+// state of the database until it is compacted, and its object is retained until it is removed
+// from the quarantine by ClearQuarantine, such that an operator may inspect it or restore it
+// from a backup.
+func (db *DB) Quarantined() ([]QuarantinedSST, error) {
+	var recorded []manifest.QuarantinedSST
+	if db.opts.ReadOnly {
+		stored, err := store.LoadStoredManifest(db.manifestStore)
+		if err != nil {
+			return nil, fmt.Errorf("while loading manifest: %w", err)
+		}
+		if sm, ok := stored.Get(); ok {
+			recorded = sm.Quarantined()
+		}
+	} else {
+		recorded = db.manifest.Quarantined()
+	}
+
+	db.quarantine.mu.Lock()
+	ssts := mergeQuarantined(recorded, db.quarantine.pending)
+	db.quarantine.mu.Unlock()
+
+	result := make([]QuarantinedSST, 0, len(ssts))
+	for _, sst := range ssts {
+		result = append(result, QuarantinedSST{
+			SSTID:  sst.ID.String(),
+			WAL:    sst.ID.Type == sstable.WAL,
+			Reason: sst.Reason,
+			Time:   sst.Time,
+		})
+	}
+	return result, nil
+}
+
+// ClearQuarantine removes the SSTs with `ids` from the quarantine, once an operator has inspected
+// them and restored or discarded their data. The objects of the SSTs which are no longer in the
+// state of the database may then be deleted. An SST which remains in the state, and is still
+// corrupted, is quarantined again when a corrupted block is next read from it.
+func (db *DB) ClearQuarantine(ids ...string) error {
+	if db.opts.ReadOnly {
+		return ErrReadOnly
+	}
+	if err := db.writeErr(); err != nil {
+		return err
+	}
+	cleared := func(sst manifest.QuarantinedSST) bool {
+		return slices.Contains(ids, sst.ID.String())
+	}
+
+	db.quarantine.mu.Lock()
+	db.quarantine.pending = slices.DeleteFunc(db.quarantine.pending, cleared)
+	db.quarantine.mu.Unlock()
+
+	flusher := MemtableFlusher{
+		db:       db,
+		manifest: db.manifest,
+		log:      db.opts.Log,
+	}
+	err := db.manifest.UpdateQuarantine(db.opts.Log, func(quarantined []manifest.QuarantinedSST) []manifest.QuarantinedSST {
+		return slices.DeleteFunc(quarantined, cleared)
+	}, func() (*state.CoreStateSnapshot, error) {
+		if err := flusher.loadManifest(); err != nil {
+			return nil, err
+		}
+		return db.state.CoreStateSnapshot(), nil
+	})
+	if err != nil {
+		return fmt.Errorf("while clearing quarantined SSTs: %w", err)
+	}
+
+	db.quarantine.mu.Lock()
+	db.tableStore.SetQuarantined(quarantinedIDs(mergeQuarantined(db.manifest.Quarantined(), db.quarantine.pending)))
+	db.quarantine.mu.Unlock()
+	db.opts.Log.Info("cleared quarantined ssts", "ssts", ids)
+	return nil
+}
+
+// ------------------------------------------------
+// quarantineQueue
+// ------------------------------------------------
+
+// quarantineQueue holds the SSTs which have been quarantined but not yet recorded in the manifest
+// by the quarantine task. A read-only DB does not write the manifest, so its SSTs remain pending.
+type quarantineQueue struct {
+	mu      sync.Mutex
+	pending []manifest.QuarantinedSST
+
+	// notify wakes the quarantine task once an SST has been quarantined
+	notify chan struct{}
+}
+
+// quarantineSST quarantines the SST with `id`, in which the corruption `reason` was detected, and
+// returns true if config.DBOptions.QuarantineCorruptSSTs is set. See store.TableStore.SetCorruptionHandler
+func (db *DB) quarantineSST(id sstable.ID, reason error) bool {
+	if !db.opts.QuarantineCorruptSSTs {
+		return false
+	}
+	db.tableStore.Quarantine(id)
+
+	q := &db.quarantine
+	q.mu.Lock()
+	if slices.ContainsFunc(q.pending, func(sst manifest.QuarantinedSST) bool { return sst.ID == id }) {
+		q.mu.Unlock()
+		return true
+	}
+	q.pending = append(q.pending, manifest.QuarantinedSST{ID: id, Reason: reason.Error(), Time: db.opts.Clock.Now()})
+	q.mu.Unlock()
+
+	db.opts.Log.Error("quarantined corrupted sst", "sst", id.String(), "error", reason)
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+	return true
+}
+
+// spawnQuarantineTask records the SSTs quarantined by this process in the manifest
+func (db *DB) spawnQuarantineTask() {
+	if !db.opts.QuarantineCorruptSSTs {
+		return
+	}
+	db.tasks.Go("quarantine", func(ctx context.Context) error {
+		for {
+			// The SSTs which remain pending after a failed write are written once the task is restarted
+			if err := db.writeQuarantine(); err != nil {
+				return err
+			}
+			select {
+			case <-db.quarantine.notify:
+			case <-ctx.Done():
+				return nil
+			}
+		}
+	})
+}
+
+// writeQuarantine adds the pending quarantined SSTs to the manifest
+func (db *DB) writeQuarantine() error {
+	q := &db.quarantine
+	q.mu.Lock()
+	pending := slices.Clone(q.pending)
+	q.mu.Unlock()
+	if len(pending) == 0 {
+		return nil
+	}
+
+	flusher := MemtableFlusher{
+		db:       db,
+		manifest: db.manifest,
+		log:      db.opts.Log,
+	}
+	err := db.manifest.UpdateQuarantine(db.opts.Log, func(quarantined []manifest.QuarantinedSST) []manifest.QuarantinedSST {
+		return mergeQuarantined(quarantined, pending)
+	}, func() (*state.CoreStateSnapshot, error) {
+		if err := flusher.loadManifest(); err != nil {
+			return nil, err
+		}
+		return db.state.CoreStateSnapshot(), nil
+	})
+	if err != nil {
+		return fmt.Errorf("while recording quarantined SSTs: %w", err)
+	}
+
+	q.mu.Lock()
+	q.pending = slices.DeleteFunc(q.pending, func(sst manifest.QuarantinedSST) bool {
+		return slices.ContainsFunc(pending, func(p manifest.QuarantinedSST) bool { return p.ID == sst.ID })
+	})
+	q.mu.Unlock()
+	return nil
+}
+
+// mergeQuarantined returns the SSTs of `recorded` followed by the SSTs of `pending` which are not recorded
+func mergeQuarantined(recorded []manifest.QuarantinedSST, pending []manifest.QuarantinedSST) []manifest.QuarantinedSST {
+	result := slices.Clone(recorded)
+	for _, sst := range pending {
+		if !slices.ContainsFunc(result, func(r manifest.QuarantinedSST) bool { return r.ID == sst.ID }) {
+			result = append(result, sst)
+		}
+	}
+	return result
+}
+
+// quarantinedIDs returns the IDs of the quarantined SSTs
+func quarantinedIDs(ssts []manifest.QuarantinedSST) []sstable.ID {
+	ids := make([]sstable.ID, 0, len(ssts))
+	for _, sst := range ssts {
+		ids = append(ids, sst.ID)
+	}
+	return ids
+}
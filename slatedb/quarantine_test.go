@@ -0,0 +1,72 @@
+package slatedb
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore"
+
+	"github.com/slatedb/slatedb-go/slatedb/config"
+)
+
+func TestQuarantineCorruptSST(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	bucket := objstore.NewInMemBucket()
+	options := testDBOptions(0, 1024)
+	options.QuarantineCorruptSSTs = true
+	db, err := OpenWithOptions(ctx, "/tmp/test_kv_store", bucket, options)
+	require.NoError(t, err)
+	require.NoError(t, db.PutWithOptions(ctx, []byte("key1"), []byte("value1"), config.WriteOptions{AwaitDurable: false}))
+	require.NoError(t, db.FlushWAL(ctx))
+	require.NoError(t, db.FlushMemtableToL0())
+	require.NoError(t, db.Close(ctx))
+
+	// Corrupt the first block of the L0 SST
+	var path string
+	require.NoError(t, bucket.Iter(ctx, "", func(name string) error {
+		if strings.HasSuffix(name, ".sst") && strings.Contains(name, "compacted") {
+			path = name
+		}
+		return nil
+	}, objstore.WithRecursiveIter()))
+	require.NotEmpty(t, path)
+	r, err := bucket.Get(ctx, path)
+	require.NoError(t, err)
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+	data[0]++
+	require.NoError(t, bucket.Upload(ctx, path, bytes.NewReader(data)))
+
+	db, err = OpenWithOptions(ctx, "/tmp/test_kv_store", bucket, options)
+	require.NoError(t, err)
+	defer func() { _ = db.Close(ctx) }()
+	require.NoError(t, db.HealthCheck())
+
+	// The corrupted block is skipped instead of failing the read
+	_, err = db.Get(ctx, []byte("key1"))
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+	assert.ErrorIs(t, db.HealthCheck(), ErrQuarantined)
+
+	require.Eventually(t, func() bool {
+		quarantined, err := db.Quarantined()
+		return err == nil && len(quarantined) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	quarantined, err := db.Quarantined()
+	require.NoError(t, err)
+	assert.False(t, quarantined[0].WAL)
+	assert.NotEmpty(t, quarantined[0].Reason)
+
+	require.NoError(t, db.ClearQuarantine(quarantined[0].SSTID))
+	quarantined, err = db.Quarantined()
+	require.NoError(t, err)
+	assert.Empty(t, quarantined)
+	assert.NoError(t, db.HealthCheck())
+}
@@ -0,0 +1,125 @@
+package slatedb
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/kapetan-io/tackle/set"
+	"github.com/thanos-io/objstore"
+
+	"github.com/slatedb/slatedb-go/slatedb/config"
+)
+
+// DBReader serves reads of a database which is written by another process. Opening a DBReader
+// does not fence the writer, and the view of the database is refreshed in the background each
+// config.ReaderOptions.ManifestPollInterval. A replica also applies the WAL SSTs to its view as
+// they are written, see config.ReaderOptions.WALPollInterval. See OpenReader
+type DBReader struct {
+	db *DB
+
+	// sub receives the manifests polled for the reader, or is nil if the manifest is not polled
+	sub *manifestSubscription
+}
+
+// OpenReader opens the database at `path` for reading. Unlike a DB opened with
+// config.DBOptions.ReadOnly, whose view is only refreshed when a read finds it stale, the view
+// of a DBReader is refreshed by a background task such that reads are never delayed by a refresh.
+func OpenReader(ctx context.Context, path string, bucket objstore.Bucket, options config.ReaderOptions) (*DBReader, error) {
+	set.Default(&options.Log, slog.Default())
+	if options.Clock == nil {
+		options.Clock = config.SystemClock{}
+	}
+
+	dbOptions := config.DefaultDBOptions()
+	dbOptions.ReadOnly = true
+	dbOptions.CompactorOptions = nil
+	dbOptions.Log = options.Log
+	dbOptions.Clock = options.Clock
+	dbOptions.MaxStaleness = options.MaxStaleness
+	dbOptions.ScanLeaseDuration = options.ScanLeaseDuration
+	replica := options.WALPollInterval > 0
+	db, err := openWithOptions(ctx, path, bucket, dbOptions, !options.TailWAL && !replica)
+	if err != nil {
+		return nil, err
+	}
+	db.replica = replica
+
+	reader := &DBReader{db: db}
+	if options.ManifestPollInterval > 0 {
+		// The manifest is read by a poller shared with the other DBReaders of the database
+		reader.sub = subscribeManifest(db.manifestStore, bucket, path, options.ManifestPollInterval, options.Clock)
+		db.tasks.Go("manifest_poll", func(ctx context.Context) error {
+			for {
+				select {
+				case poll := <-reader.sub.C():
+					err := poll.err
+					if err == nil {
+						db.refreshMu.Lock()
+						err = db.refreshFrom(ctx, poll.manifest)
+						db.refreshMu.Unlock()
+					}
+					if err != nil {
+						db.opts.Log.Error("error refreshing reader", "error", err)
+					}
+				case <-ctx.Done():
+					return nil
+				}
+			}
+		})
+	}
+	if replica {
+		ticker := options.Clock.NewTicker(options.WALPollInterval)
+		db.tasks.Go("wal_poll", func(ctx context.Context) error {
+			for {
+				select {
+				case <-ticker.C():
+					db.refreshMu.Lock()
+					err := db.applyWAL(ctx)
+					db.refreshMu.Unlock()
+					if err != nil {
+						db.opts.Log.Error("error applying WAL to replica", "error", err)
+					}
+				case <-ctx.Done():
+					ticker.Stop()
+					return nil
+				}
+			}
+		})
+	}
+	return reader, nil
+}
+
+func (r *DBReader) Get(ctx context.Context, key []byte) ([]byte, error) {
+	return r.db.Get(ctx, key)
+}
+
+// GetWithOptions returns the value of `key`, see DB.GetWithOptions
+func (r *DBReader) GetWithOptions(ctx context.Context, key []byte, options config.ReadOptions) ([]byte, error) {
+	return r.db.GetWithOptions(ctx, key, options)
+}
+
+func (r *DBReader) Scan(ctx context.Context, start []byte, end []byte) (*DBIterator, error) {
+	return r.db.Scan(ctx, start, end)
+}
+
+// ScanWithIteratorOptions returns a DBIterator over the keys in the range [start, end),
+// see DB.ScanWithIteratorOptions
+func (r *DBReader) ScanWithIteratorOptions(ctx context.Context, start []byte, end []byte,
+	options config.IteratorOptions) (*DBIterator, error) {
+	return r.db.ScanWithIteratorOptions(ctx, start, end, options)
+}
+
+// HealthCheck returns an error if the background refresh task has failed
+func (r *DBReader) HealthCheck() error {
+	return r.db.HealthCheck()
+}
+
+// Close stops the background refresh task
+func (r *DBReader) Close(ctx context.Context) error {
+	err := r.db.Close(ctx)
+	if r.sub != nil {
+		r.sub.close()
+		r.sub = nil
+	}
+	return err
+}
@@ -0,0 +1,162 @@
+package slatedb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore"
+
+	"github.com/slatedb/slatedb-go/slatedb/config"
+)
+
+func TestDBReader(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	bucket := objstore.NewInMemBucket()
+	dbPath := "/tmp/test_kv_store"
+	db, err := OpenWithOptions(ctx, dbPath, bucket, testDBOptions(0, 1024))
+	require.NoError(t, err)
+	defer func() { _ = db.Close(ctx) }()
+	require.NoError(t, db.Put(ctx, []byte("key1"), []byte("value1")))
+	require.NoError(t, db.FlushMemtableToL0())
+	require.NoError(t, db.Put(ctx, []byte("key2"), []byte("value2")))
+
+	options := config.DefaultReaderOptions()
+	options.ManifestPollInterval = 10 * time.Millisecond
+	reader, err := OpenReader(ctx, dbPath, bucket, options)
+	require.NoError(t, err)
+	defer func() { _ = reader.Close(ctx) }()
+	options.TailWAL = true
+	tailing, err := OpenReader(ctx, dbPath, bucket, options)
+	require.NoError(t, err)
+	defer func() { _ = tailing.Close(ctx) }()
+
+	// only a reader which tails the WAL sees writes which have not been flushed to L0
+	value, err := reader.Get(ctx, []byte("key1"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("value1"), value)
+	_, err = reader.Get(ctx, []byte("key2"))
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+	value, err = tailing.Get(ctx, []byte("key2"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("value2"), value)
+
+	// the readers see new writes once the manifest is polled
+	require.NoError(t, db.Put(ctx, []byte("key3"), []byte("value3")))
+	assert.Eventually(t, func() bool {
+		_, err := tailing.Get(ctx, []byte("key3"))
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, db.FlushMemtableToL0())
+	assert.Eventually(t, func() bool {
+		_, err := reader.Get(ctx, []byte("key3"))
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+	it, err := reader.Scan(ctx, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"key1": "value1", "key2": "value2", "key3": "value3"}, scanAll(t, it))
+
+	// the writer is not fenced by the readers
+	require.NoError(t, db.Put(ctx, []byte("key4"), []byte("value4")))
+	require.NoError(t, reader.HealthCheck())
+}
+
+func TestDBReadersShareManifestPoller(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	bucket := objstore.NewInMemBucket()
+	dbPath := "/tmp/test_kv_store"
+	db, err := OpenWithOptions(ctx, dbPath, bucket, testDBOptions(0, 1024))
+	require.NoError(t, err)
+	defer func() { _ = db.Close(ctx) }()
+
+	clock := config.NewManualClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	options := config.DefaultReaderOptions()
+	options.Clock = clock
+	first, err := OpenReader(ctx, dbPath, bucket, options)
+	require.NoError(t, err)
+	second, err := OpenReader(ctx, dbPath, bucket, options)
+	require.NoError(t, err)
+	require.Same(t, first.sub.poller, second.sub.poller)
+
+	// both readers see the writes once the shared poller reads the manifest
+	require.NoError(t, db.Put(ctx, []byte("key1"), []byte("value1")))
+	require.NoError(t, db.FlushMemtableToL0())
+	clock.Advance(options.ManifestPollInterval)
+	for _, reader := range []*DBReader{first, second} {
+		assert.Eventually(t, func() bool {
+			_, err := reader.Get(ctx, []byte("key1"))
+			return err == nil
+		}, 5*time.Second, 10*time.Millisecond)
+	}
+
+	// the poller is stopped once every reader is closed
+	require.NoError(t, first.Close(ctx))
+	manifestPollers.Lock()
+	assert.Len(t, manifestPollers.m, 1)
+	manifestPollers.Unlock()
+	require.NoError(t, second.Close(ctx))
+	manifestPollers.Lock()
+	assert.Empty(t, manifestPollers.m)
+	manifestPollers.Unlock()
+}
+
+func TestDBReaderReplica(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	bucket := objstore.NewInMemBucket()
+	dbPath := "/tmp/test_kv_store"
+	dbOptions := testDBOptions(0, 1024)
+	dbOptions.GCMinAge = time.Millisecond
+	db, err := OpenWithOptions(ctx, dbPath, bucket, dbOptions)
+	require.NoError(t, err)
+	defer func() { _ = db.Close(ctx) }()
+	require.NoError(t, db.Put(ctx, []byte("key1"), []byte("value1")))
+
+	// the manifest is never polled, so writes are only seen once the WAL is applied
+	options := config.DefaultReaderOptions()
+	options.ManifestPollInterval = 0
+	options.WALPollInterval = 10 * time.Millisecond
+	replica, err := OpenReader(ctx, dbPath, bucket, options)
+	require.NoError(t, err)
+	defer func() { _ = replica.Close(ctx) }()
+	value, err := replica.Get(ctx, []byte("key1"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("value1"), value)
+
+	require.NoError(t, db.Put(ctx, []byte("key2"), []byte("value2")))
+	assert.Eventually(t, func() bool {
+		_, err := replica.Get(ctx, []byte("key2"))
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// a replica continues to apply the WAL once the WAL SSTs it applied are flushed to L0 and deleted
+	require.NoError(t, db.FlushMemtableToL0())
+	time.Sleep(10 * time.Millisecond)
+	_, err = db.CollectGarbage(ctx)
+	require.NoError(t, err)
+	require.NoError(t, db.Put(ctx, []byte("key3"), []byte("value3")))
+	assert.Eventually(t, func() bool {
+		_, err := replica.Get(ctx, []byte("key3"))
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, replica.HealthCheck())
+
+	// a read of a replica whose view is older than MaxStaleness applies the WAL before it is served
+	options.WALPollInterval = time.Hour
+	options.MaxStaleness = time.Millisecond
+	stale, err := OpenReader(ctx, dbPath, bucket, options)
+	require.NoError(t, err)
+	defer func() { _ = stale.Close(ctx) }()
+	require.NoError(t, db.Put(ctx, []byte("key4"), []byte("value4")))
+	time.Sleep(5 * time.Millisecond)
+	value, err = stale.Get(ctx, []byte("key4"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("value4"), value)
+}
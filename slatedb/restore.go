@@ -0,0 +1,137 @@
+package slatedb
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/thanos-io/objstore"
+
+	"github.com/slatedb/slatedb-go/slatedb/config"
+)
+
+// Backup identifies a copy of a database, such as one made by copying the
+// objects of the database to another bucket or path
+type Backup struct {
+	Bucket objstore.Bucket
+	Path   string
+}
+
+// RestoreReport summarizes the changes made by RestoreRange
+type RestoreReport struct {
+	// The number of keys which were written to the destination because they were
+	// absent from the destination or had a different value than in the backup
+	Written int
+
+	// The number of keys which were deleted from the destination because they
+	// were absent from the backup
+	Deleted int
+}
+
+// RestoreRange restores the keys in the range [start, end) of `dst` to their state in
+// `backup`. Keys in the range are written to `dst` when their value differs from the backup
+// and deleted when they are absent from the backup; keys outside the range are not modified.
+// If start or end is empty, the range is unbounded on that side.
+//
+// The backup is opened read-only and only the SSTs and blocks which overlap the range are
+// read, such that the data of a single tenant can be recovered without restoring the entire
+// database. Changes are streamed to `dst` as the backup is read and are durable once
+// RestoreRange returns without error. Writes made to the range of `dst` while RestoreRange
+// is running may be overwritten.
+func RestoreRange(ctx context.Context, backup Backup, dst *DB, start []byte, end []byte) (RestoreReport, error) {
+	var report RestoreReport
+	if dst.opts.ReadOnly {
+		return report, ErrReadOnly
+	}
+
+	opts := config.DefaultDBOptions()
+	opts.ReadOnly = true
+	opts.CompactorOptions = nil
+	opts.Log = dst.opts.Log
+	src, err := OpenWithOptions(ctx, backup.Path, backup.Bucket, opts)
+	if err != nil {
+		return report, fmt.Errorf("while opening backup: %w", err)
+	}
+	defer func() { _ = src.Close(ctx) }()
+
+	srcIter, err := src.Scan(ctx, start, end)
+	if err != nil {
+		return report, fmt.Errorf("while scanning backup: %w", err)
+	}
+	defer srcIter.Close()
+	// Uncommitted writes to the destination are included, such that keys
+	// absent from the backup are deleted even if not yet durable
+	dstIter, err := dst.ScanWithOptions(ctx, start, end, config.ReadOptions{ReadLevel: config.Uncommitted})
+	if err != nil {
+		return report, fmt.Errorf("while scanning destination: %w", err)
+	}
+	defer dstIter.Close()
+
+	var srcKV, dstKV KeyValue
+	var srcOK, dstOK bool
+	// nextSrc checks for errors as soon as the backup is exhausted, such that keys
+	// are not deleted from the destination because the backup could not be read
+	nextSrc := func() error {
+		if srcKV, srcOK = srcIter.Next(ctx); !srcOK {
+			if err := srcIter.Err(); err != nil {
+				return fmt.Errorf("while reading backup: %w", err)
+			}
+		}
+		return nil
+	}
+
+	writeOpts := config.WriteOptions{AwaitDurable: false}
+	if err := nextSrc(); err != nil {
+		return report, err
+	}
+	dstKV, dstOK = dstIter.Next(ctx)
+	for srcOK || dstOK {
+		var order int
+		switch {
+		case !srcOK:
+			order = 1
+		case !dstOK:
+			order = -1
+		default:
+			order = bytes.Compare(srcKV.Key, dstKV.Key)
+		}
+
+		switch {
+		case order > 0:
+			// the key is absent from the backup
+			if err := dst.DeleteWithOptions(ctx, dstKV.Key, writeOpts); err != nil {
+				return report, err
+			}
+			report.Deleted++
+			dstKV, dstOK = dstIter.Next(ctx)
+		case order < 0:
+			// the key is absent from the destination
+			if err := dst.PutWithOptions(ctx, srcKV.Key, srcKV.Value, writeOpts); err != nil {
+				return report, err
+			}
+			report.Written++
+			if err := nextSrc(); err != nil {
+				return report, err
+			}
+		default:
+			if !bytes.Equal(srcKV.Value, dstKV.Value) {
+				if err := dst.PutWithOptions(ctx, srcKV.Key, srcKV.Value, writeOpts); err != nil {
+					return report, err
+				}
+				report.Written++
+			}
+			if err := nextSrc(); err != nil {
+				return report, err
+			}
+			dstKV, dstOK = dstIter.Next(ctx)
+		}
+	}
+
+	if err := dstIter.Err(); err != nil {
+		return report, fmt.Errorf("while reading destination: %w", err)
+	}
+	if err := dst.FlushWAL(ctx); err != nil {
+		return report, fmt.Errorf("while flushing restored keys: %w", err)
+	}
+	return report, nil
+}
@@ -0,0 +1,58 @@
+package slatedb
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore"
+)
+
+func TestRestoreRange(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	bucket := objstore.NewInMemBucket()
+	dbPath := "/tmp/test_kv_store"
+	db, err := OpenWithOptions(ctx, dbPath, bucket, testDBOptions(0, 1024))
+	require.NoError(t, err)
+	require.NoError(t, db.Put(ctx, []byte("a/1"), []byte("backup")))
+	require.NoError(t, db.Put(ctx, []byte("a/2"), []byte("backup")))
+	require.NoError(t, db.Put(ctx, []byte("b/1"), []byte("backup")))
+	require.NoError(t, db.FlushMemtableToL0())
+	require.NoError(t, db.Close(ctx))
+
+	// backup the database by copying its objects to another path
+	backupPath := "/tmp/test_kv_store_backup"
+	require.NoError(t, bucket.Iter(ctx, dbPath, func(name string) error {
+		rc, err := bucket.Get(ctx, name)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = rc.Close() }()
+		return bucket.Upload(ctx, backupPath+strings.TrimPrefix(name, dbPath), rc)
+	}, objstore.WithRecursiveIter()))
+
+	db, err = OpenWithOptions(ctx, dbPath, bucket, testDBOptions(0, 1024))
+	require.NoError(t, err)
+	defer func() { _ = db.Close(ctx) }()
+	require.NoError(t, db.Put(ctx, []byte("a/1"), []byte("changed")))
+	require.NoError(t, db.Delete(ctx, []byte("a/2")))
+	require.NoError(t, db.Put(ctx, []byte("a/3"), []byte("added")))
+	require.NoError(t, db.Put(ctx, []byte("b/1"), []byte("changed")))
+
+	// only the keys of tenant 'a' are restored
+	report, err := RestoreRange(ctx, Backup{Bucket: bucket, Path: backupPath}, db, []byte("a/"), []byte("a0"))
+	require.NoError(t, err)
+	assert.Equal(t, RestoreReport{Written: 2, Deleted: 1}, report)
+
+	it, err := db.Scan(ctx, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"a/1": "backup", "a/2": "backup", "b/1": "changed"}, scanAll(t, it))
+
+	_, err = RestoreRange(ctx, Backup{Bucket: bucket, Path: "/tmp/no_such_backup"}, db, nil, nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,202 @@
+// Package s3bucket provides an objstore.Bucket decorator which implements store.ConditionalBucket
+// for S3 and S3 compatible object storage, such as MinIO, by uploading an object with a
+// PutObject request carrying the `If-None-Match: *` header, which S3 rejects if the object exists.
+//
+// The objstore S3 provider does not expose conditional uploads, as such the conditional PutObject
+// request is signed and sent by this package, while every other operation is delegated to the
+// wrapped bucket.
+package s3bucket
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/thanos-io/objstore"
+)
+
+// Config is the location of the S3 bucket and the credentials used to sign the conditional
+// uploads, which are usually those the wrapped bucket was configured with
+type Config struct {
+	// Endpoint is the host, and optionally the port, of the S3 API, such as
+	// `s3.us-east-1.amazonaws.com` or `localhost:9000`
+	Endpoint string
+
+	// Bucket is the name of the bucket, which is addressed by path rather than by virtual host
+	Bucket string
+
+	// Region is the region of the bucket, `us-east-1` if empty
+	Region string
+
+	AccessKey    string
+	SecretKey    string
+	SessionToken string
+
+	// Insecure sends the requests over HTTP rather than HTTPS
+	Insecure bool
+
+	// HTTPClient sends the requests, http.DefaultClient if nil
+	HTTPClient *http.Client
+}
+
+// Bucket is an objstore.Bucket which uploads objects conditionally with `If-None-Match: *`
+type Bucket struct {
+	objstore.Bucket
+	config Config
+
+	// now returns the time a request is signed at
+	now func() time.Time
+}
+
+// New returns a Bucket which delegates to `bucket`, which must be the S3 bucket described by
+// `config`, and uploads objects conditionally to the bucket described by `config`
+func New(bucket objstore.Bucket, config Config) *Bucket {
+	if config.Region == "" {
+		config.Region = "us-east-1"
+	}
+	if config.HTTPClient == nil {
+		config.HTTPClient = http.DefaultClient
+	}
+	return &Bucket{Bucket: bucket, config: config, now: time.Now}
+}
+
+// UploadIfNotExists implements store.ConditionalBucket. It returns false if S3 rejects the upload
+// with 412 Precondition Failed, as an object named `name` exists. A conditional upload which
+// conflicts with a concurrent upload of the same object is rejected with 409 Conflict, and is
+// returned as an error such that it is retried.
+func (b *Bucket) UploadIfNotExists(ctx context.Context, name string, r io.Reader) (bool, error) {
+	// The payload is hashed before it is sent, as such it is read into memory. Conditional uploads
+	// are used for manifests, which are small.
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return false, fmt.Errorf("while reading object: %w", err)
+	}
+
+	scheme := "https"
+	if b.config.Insecure {
+		scheme = "http"
+	}
+	objectPath := "/" + b.config.Bucket + "/" + name
+	u := url.URL{
+		Scheme:  scheme,
+		Host:    b.config.Endpoint,
+		Path:    objectPath,
+		RawPath: escapePath(objectPath),
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, u.String(), bytes.NewReader(data))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("If-None-Match", "*")
+	if b.config.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", b.config.SessionToken)
+	}
+	b.sign(req, data)
+
+	resp, err := b.config.HTTPClient.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("while uploading object: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusPreconditionFailed:
+		return false, nil
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return false, fmt.Errorf("conditional upload of '%s' failed with status %d: %s", name, resp.StatusCode, body)
+}
+
+// ------------------------------------------------
+// Signature Version 4
+// ------------------------------------------------
+
+const (
+	signingAlgorithm = "AWS4-HMAC-SHA256"
+	amzDateFormat    = "20060102T150405Z"
+)
+
+// sign adds the Authorization header of AWS Signature Version 4 to `req`, signing its host and
+// every header it has been given, along with the hash of `payload`
+func (b *Bucket) sign(req *http.Request, payload []byte) {
+	now := b.now().UTC()
+	payloadHash := sha256.Sum256(payload)
+	req.Header.Set("X-Amz-Date", now.Format(amzDateFormat))
+	req.Header.Set("X-Amz-Content-Sha256", hex.EncodeToString(payloadHash[:]))
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.Join(values, ",")
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(headers[name]) + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		// A PutObject request has no query
+		"",
+		canonicalHeaders.String(),
+		signedHeaders,
+		hex.EncodeToString(payloadHash[:]),
+	}, "\n")
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+
+	date := now.Format("20060102")
+	scope := date + "/" + b.config.Region + "/s3/aws4_request"
+	stringToSign := strings.Join([]string{
+		signingAlgorithm,
+		now.Format(amzDateFormat),
+		scope,
+		hex.EncodeToString(requestHash[:]),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+b.config.SecretKey), date)
+	key = hmacSHA256(key, b.config.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		signingAlgorithm, b.config.AccessKey, scope, signedHeaders, signature))
+}
+
+// escapePath percent-encodes every byte of `p` other than the unreserved characters and `/`, as
+// the canonical request of Signature Version 4 requires
+func escapePath(p string) string {
+	var b strings.Builder
+	for i := 0; i < len(p); i++ {
+		c := p[i]
+		if ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9') ||
+			strings.IndexByte("-._~/", c) >= 0 {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
@@ -0,0 +1,422 @@
+package slatedb
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/slatedb/slatedb-go/internal"
+	"github.com/slatedb/slatedb-go/internal/iter"
+	"github.com/slatedb/slatedb-go/internal/sstable"
+	"github.com/slatedb/slatedb-go/internal/tracing"
+	"github.com/slatedb/slatedb-go/internal/types"
+	"github.com/slatedb/slatedb-go/slatedb/compacted"
+	"github.com/slatedb/slatedb-go/slatedb/config"
+	"github.com/slatedb/slatedb-go/slatedb/state"
+	"github.com/slatedb/slatedb-go/slatedb/store"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// KeyValue is a key and its value returned by a DBIterator
+type KeyValue = types.KeyValue
+
+// DBIterator iterates in ascending key order, or descending key order if IteratorOptions.Reverse
+// was set, over the keys of the range provided to DB.Scan. Keys which have been deleted or have
+// expired are skipped.
+//
+// The SSTs of the iterator are retained by DB.CollectGarbage until Next returns false, as such
+// callers which stop iterating before then should Close the iterator.
+type DBIterator struct {
+	iter    iter.KVIterator
+	start   []byte
+	end     []byte
+	reverse bool
+	done    bool
+
+	// now is the time as of which expired keys are skipped
+	now time.Time
+
+	// rangeTombstones are the range tombstones which overlap the range of the iterator
+	rangeTombstones []types.RangeTombstone
+
+	// keyPrefix is stripped from each key returned, such as the prefix of a Keyspace
+	keyPrefix []byte
+
+	// transform, if not nil, transforms each value into buf, see config.TransformFunc
+	transform config.TransformFunc
+	buf       []byte
+	err       error
+
+	// unpin releases the SSTs of the iterator to the garbage collector, see DB.CollectGarbage
+	unpin func()
+
+	// deadline, if not zero, is the time after which the iteration is stopped, see
+	// config.IteratorOptions.MaxDuration. visited counts the entries visited, including
+	// those skipped, between checks of the deadline.
+	clock       config.Clock
+	deadline    time.Time
+	maxDuration time.Duration
+	visited     int
+}
+
+// deadlineInterval is the number of entries a DBIterator visits between checks of its deadline
+const deadlineInterval = 64
+
+// Next returns the next key-value pair in the range, or false if the range is exhausted.
+// Callers should check Err once Next returns false. Next returns false once `ctx` is done,
+// such that a scan made on behalf of a request which has gone away stops promptly rather
+// than fetching the rest of the range.
+func (d *DBIterator) Next(ctx context.Context) (KeyValue, bool) {
+	for !d.done {
+		if err := d.checkCancelled(ctx); err != nil {
+			d.err = err
+			d.done = true
+			break
+		}
+		entry, ok := d.iter.NextEntry(ctx)
+		if !ok {
+			// The sources of the iterator stop at the block they were fetching when ctx is done
+			d.err = ctx.Err()
+			d.done = true
+			break
+		}
+		if d.pastRange(entry.Key) {
+			d.done = true
+			break
+		}
+		if entry.Value.IsTombstone() || entry.IsExpired(d.now) || types.IsCovered(d.rangeTombstones, entry) {
+			continue
+		}
+		// A reverse iteration begins at the end of the range, which is excluded
+		if d.reverse && len(d.end) != 0 && bytes.Compare(entry.Key, d.end) >= 0 {
+			continue
+		}
+		key := entry.Key[len(d.keyPrefix):]
+		if d.transform != nil {
+			buf, err := d.transform(d.buf[:0], key, entry.Value.Value)
+			if err != nil {
+				d.err = fmt.Errorf("while transforming value of key '%s': %w", key, err)
+				d.done = true
+				break
+			}
+			d.buf = buf
+			return KeyValue{Key: key, Value: buf}, true
+		}
+		return KeyValue{Key: key, Value: entry.Value.Value}, true
+	}
+	d.Close()
+	return KeyValue{}, false
+}
+
+// Close stops the iteration, after which Next returns false, and releases the SSTs of the
+// iterator. It is not necessary to Close an iterator once Next has returned false.
+func (d *DBIterator) Close() {
+	d.done = true
+	if d.unpin != nil {
+		d.unpin()
+		d.unpin = nil
+	}
+}
+
+// checkCancelled returns an error if `ctx` is done or the iterator has run for longer than
+// config.IteratorOptions.MaxDuration
+func (d *DBIterator) checkCancelled(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if d.deadline.IsZero() {
+		return nil
+	}
+	d.visited++
+	if d.visited%deadlineInterval == 0 && !d.clock.Now().Before(d.deadline) {
+		return fmt.Errorf("scan exceeded IteratorOptions.MaxDuration of %s: %w",
+			d.maxDuration, context.DeadlineExceeded)
+	}
+	return nil
+}
+
+// pastRange returns true if the key is beyond the range in the direction of iteration
+func (d *DBIterator) pastRange(key []byte) bool {
+	if d.reverse {
+		return len(d.start) != 0 && bytes.Compare(key, d.start) < 0
+	}
+	return len(d.end) != 0 && bytes.Compare(key, d.end) >= 0
+}
+
+// Err returns an error if any part of the range could not be read, in which case
+// keys within the range may have been omitted from the iteration, or if
+// IteratorOptions.Transform returned an error.
+func (d *DBIterator) Err() error {
+	if d.err != nil {
+		return d.err
+	}
+	if w := d.iter.Warnings(); w != nil {
+		return w.If()
+	}
+	return nil
+}
+
+// ScanPrefix returns a DBIterator over the keys which begin with `prefix`. If `prefix` is a prefix
+// extracted by config.DBOptions.PrefixExtractor, the SSTs whose filter excludes the prefix are
+// skipped. See ScanWithOptions
+func (db *DB) ScanPrefix(ctx context.Context, prefix []byte) (*DBIterator, error) {
+	if len(prefix) == 0 {
+		return nil, internal.ErrInvalidArgument("argument 'prefix' must not be empty")
+	}
+	return db.Scan(ctx, prefix, prefixEnd(prefix))
+}
+
+func (db *DB) Scan(ctx context.Context, start []byte, end []byte) (*DBIterator, error) {
+	return db.ScanWithOptions(ctx, start, end, config.DefaultReadOptions())
+}
+
+// ScanWithOptions returns a DBIterator over the keys in the range [start, end). If start or end
+// is empty, the range is unbounded on that side. The iterator merges the memtables, L0 SSTs and
+// compacted sorted runs such that only the most recent value of each key is returned, skipping
+// keys deleted by Delete or DeleteRange. Like GetWithOptions, the WAL is included only when the
+// ReadLevel is Uncommitted.
+//
+// The iterator reflects the state of the database when ScanWithOptions was called; writes
+// made afterward are not returned, and keys which expire afterward are still returned.
+func (db *DB) ScanWithOptions(ctx context.Context, start []byte, end []byte,
+	options config.ReadOptions) (*DBIterator, error) {
+	return db.ScanWithIteratorOptions(ctx, start, end, config.IteratorOptions{ReadLevel: options.ReadLevel})
+}
+
+// ScanWithIteratorOptions is like ScanWithOptions, but allows the keys of the range to be
+// returned in descending order by setting IteratorOptions.Reverse, and the values to be
+// transformed as they are iterated by setting IteratorOptions.Transform.
+//
+// The span of the scan, see config.DBOptions.TracerProvider, covers the creation of the iterator,
+// which reads the first blocks of the SSTs of the range, rather than the iteration of the keys.
+func (db *DB) ScanWithIteratorOptions(ctx context.Context, start []byte, end []byte,
+	options config.IteratorOptions) (_ *DBIterator, err error) {
+	ctx, span := db.tracer.Start(ctx, "slatedb.Scan")
+	defer func() { tracing.End(span, err) }()
+	if err := db.maybeRefresh(ctx); err != nil {
+		return nil, fmt.Errorf("while refreshing read-only view: %w", err)
+	}
+	return db.scanState(ctx, db.state.Snapshot(), start, end, options)
+}
+
+// scanState returns a DBIterator over the keys of the range in the provided state, see ScanWithIteratorOptions
+func (db *DB) scanState(ctx context.Context, snapshot *state.DBStateSnapshot, start []byte, end []byte,
+	options config.IteratorOptions) (*DBIterator, error) {
+	if len(start) != 0 && len(end) != 0 && bytes.Compare(start, end) >= 0 {
+		return nil, internal.ErrInvalidArgument("argument 'start' must be less than 'end'")
+	}
+	db.stats.scans.Add(1)
+
+	// entries returns an iterator over the entries of a memtable or WAL in the direction of the scan
+	entries := func(rowEntries []types.RowEntry) *iter.EntryIterator {
+		if options.Reverse {
+			slices.Reverse(rowEntries)
+		}
+		return iter.NewEntryIterator(rowEntries...)
+	}
+
+	sources := make([]iter.Source, 0)
+	if options.ReadLevel == config.Uncommitted {
+		sources = append(sources, iter.Source{Layer: iter.LayerWAL,
+			Iter: entries(snapshot.Wal.Range(start, end))})
+		for i := 0; i < snapshot.ImmWALs.Len(); i++ {
+			sources = append(sources, iter.Source{Layer: iter.LayerImmWAL, Age: i,
+				Iter: entries(snapshot.ImmWALs.At(i).Range(start, end))})
+		}
+	}
+
+	sources = append(sources, iter.Source{Layer: iter.LayerMemtable,
+		Iter: entries(snapshot.Memtable.Range(start, end))})
+	for i := 0; i < snapshot.ImmMemtables.Len(); i++ {
+		sources = append(sources, iter.Source{Layer: iter.LayerImmMemtable, Age: i,
+			Iter: entries(snapshot.ImmMemtables.At(i).Range(start, end))})
+	}
+
+	tableStore := db.tableStore
+	if options.Throughput {
+		tableStore = db.tableStore.Throughput()
+	}
+	prefix, hasPrefix := db.scanPrefix(start, end)
+	sstIDs := make([]string, 0, len(snapshot.Core.L0))
+	for i, sst := range snapshot.Core.L0 {
+		// SSTs which begin at or after the end of the range contain no keys in the range
+		if len(end) != 0 && bytes.Compare(sst.Info.FirstKey, end) >= 0 {
+			continue
+		}
+		if hasPrefix && !db.prefixFilterMayInclude(ctx, sst, prefix) {
+			continue
+		}
+		sstIDs = append(sstIDs, sst.Id.String())
+		var it *sstable.Iterator
+		var err error
+		if options.Reverse {
+			it, err = db.sstReverseIteratorFrom(ctx, tableStore, sst, end)
+		} else {
+			it, err = db.sstIteratorFrom(ctx, tableStore, sst, start)
+		}
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, iter.Source{Layer: iter.LayerL0, Age: i, Iter: it})
+	}
+
+	for i, sr := range snapshot.Core.Compacted {
+		if hasPrefix {
+			sr = db.prefixFilterSortedRun(ctx, sr, start, end, prefix)
+		}
+		var it *compacted.SortedRunIterator
+		var err error
+		if options.Reverse {
+			it, err = db.sortedRunReverseIteratorFrom(ctx, tableStore, sr, end)
+		} else {
+			it, err = db.sortedRunIteratorFrom(ctx, tableStore, sr, start)
+		}
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, iter.Source{Layer: iter.LayerSortedRun, Age: i, Iter: it})
+	}
+	trace.SpanFromContext(ctx).SetAttributes(
+		tracing.KeySSTIDs.StringSlice(sstIDs),
+		tracing.KeySortedRuns.Int(len(snapshot.Core.Compacted)),
+	)
+
+	// The SSTs of an iterator of a read-only DB are also retained from the garbage collector of
+	// the writer, which runs in another process
+	unpin := db.pins.pin(snapshot.Core)
+	if db.opts.ReadOnly && db.opts.ScanLeaseDuration > 0 {
+		release, err := db.lease(snapshot.Core)
+		if err != nil {
+			unpin()
+			return nil, err
+		}
+		unpinned := unpin
+		unpin = func() {
+			unpinned()
+			release()
+		}
+	}
+
+	merged := iter.NewLSMMerge
+	if options.Reverse {
+		merged = iter.NewReverseLSMMerge
+	}
+	it := &DBIterator{
+		iter:    merged(ctx, sources...),
+		start:   bytes.Clone(start),
+		end:     bytes.Clone(end),
+		reverse: options.Reverse,
+		now:     db.opts.Clock.Now(),
+
+		rangeTombstones: snapshot.RangeTombstones(start, end, options.ReadLevel == config.Uncommitted),
+		transform:       options.Transform,
+		clock:           db.opts.Clock,
+		maxDuration:     options.MaxDuration,
+		unpin:           unpin,
+	}
+	if options.MaxDuration > 0 {
+		it.deadline = it.now.Add(options.MaxDuration)
+	}
+	return it, nil
+}
+
+func (db *DB) sstIteratorFrom(ctx context.Context, ts *store.TableStore, sst sstable.Handle,
+	start []byte) (*sstable.Iterator, error) {
+	if len(start) == 0 {
+		return sstable.NewIterator(ctx, &sst, ts.Clone())
+	}
+	return sstable.NewIteratorAtKey(ctx, &sst, start, ts.Clone())
+}
+
+func (db *DB) sortedRunIteratorFrom(ctx context.Context, ts *store.TableStore, sr compacted.SortedRun,
+	start []byte) (*compacted.SortedRunIterator, error) {
+	if len(start) == 0 {
+		return compacted.NewSortedRunIterator(ctx, sr, ts.Clone())
+	}
+	return compacted.NewSortedRunIteratorFromKey(ctx, sr, start, ts.Clone())
+}
+
+// sstReverseIteratorFrom returns an iterator over the keys of the SST in descending
+// order, beginning at the key `end` or the last key less than it
+func (db *DB) sstReverseIteratorFrom(ctx context.Context, ts *store.TableStore, sst sstable.Handle,
+	end []byte) (*sstable.Iterator, error) {
+	if len(end) == 0 {
+		return sstable.NewReverseIterator(ctx, &sst, ts.Clone())
+	}
+	return sstable.NewReverseIteratorAtKey(ctx, &sst, end, ts.Clone())
+}
+
+func (db *DB) sortedRunReverseIteratorFrom(ctx context.Context, ts *store.TableStore, sr compacted.SortedRun,
+	end []byte) (*compacted.SortedRunIterator, error) {
+	if len(end) == 0 {
+		return compacted.NewReverseSortedRunIterator(ctx, sr, ts.Clone())
+	}
+	return compacted.NewReverseSortedRunIteratorFromKey(ctx, sr, end, ts.Clone())
+}
+
+// ------------------------------------------------
+// Prefix Filters
+// ------------------------------------------------
+
+// scanPrefix returns the prefix extracted by DBOptions.PrefixExtractor which every key of the
+// range [start, end) begins with, or false if the keys of the range do not share a prefix
+func (db *DB) scanPrefix(start []byte, end []byte) ([]byte, bool) {
+	if db.opts.PrefixExtractor == nil || len(start) == 0 {
+		return nil, false
+	}
+	prefix, ok := db.opts.PrefixExtractor.Prefix(start)
+	if !ok {
+		return nil, false
+	}
+	// Every key from the start of the range up to the end of the prefix begins with the prefix
+	if limit := prefixEnd(prefix); limit != nil && (len(end) == 0 || bytes.Compare(end, limit) > 0) {
+		return nil, false
+	}
+	return prefix, true
+}
+
+// prefixFilterMayInclude returns false if the filter of the SST excludes `prefix`. If the filter
+// does not hold prefixes, could not be read, or was not fetched, the SST may include the prefix.
+func (db *DB) prefixFilterMayInclude(ctx context.Context, sst sstable.Handle, prefix []byte) bool {
+	filter, _, err := db.tableStore.TryReadPrefixFilter(ctx, &sst)
+	if f, ok := filter.Get(); err == nil && ok && !f.HasKey(prefix) {
+		db.stats.scanSSTsSkipped.Add(1)
+		return false
+	}
+	return true
+}
+
+// prefixFilterSortedRun returns the sorted run of the SSTs of `sr` which overlap the range
+// [start, end) and whose filter may include `prefix`
+func (db *DB) prefixFilterSortedRun(ctx context.Context, sr compacted.SortedRun, start []byte, end []byte,
+	prefix []byte) compacted.SortedRun {
+	filtered := compacted.SortedRun{ID: sr.ID}
+	for i, sst := range sr.SSTList {
+		// The SST ends before the range if the next SST begins at or before the start of the range
+		if i+1 < len(sr.SSTList) && bytes.Compare(sr.SSTList[i+1].Info.FirstKey, start) <= 0 {
+			continue
+		}
+		if len(end) != 0 && bytes.Compare(sst.Info.FirstKey, end) >= 0 {
+			break
+		}
+		if db.prefixFilterMayInclude(ctx, sst, prefix) {
+			filtered.SSTList = append(filtered.SSTList, sst)
+		}
+	}
+	return filtered
+}
+
+// prefixEnd returns the smallest key greater than every key which begins with `prefix`, or nil
+// if there is no such key as the prefix is only 0xff bytes
+func prefixEnd(prefix []byte) []byte {
+	end := bytes.Clone(prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+	return nil
+}
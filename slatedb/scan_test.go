@@ -0,0 +1,338 @@
+package slatedb
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore"
+
+	"github.com/slatedb/slatedb-go/internal/sstable"
+	"github.com/slatedb/slatedb-go/internal/sstable/bloom"
+	"github.com/slatedb/slatedb-go/slatedb/compacted"
+	"github.com/slatedb/slatedb-go/slatedb/config"
+)
+
+func scanAll(t *testing.T, it *DBIterator) map[string]string {
+	t.Helper()
+	result := make(map[string]string)
+	var keys []string
+	for {
+		kv, ok := it.Next(context.Background())
+		if !ok {
+			break
+		}
+		keys = append(keys, string(kv.Key))
+		result[string(kv.Key)] = string(kv.Value)
+	}
+	require.NoError(t, it.Err())
+	assert.IsIncreasing(t, keys)
+	return result
+}
+
+func TestScan(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	options := testDBOptionsCompactor(0, 1024, &config.CompactorOptions{
+		PollInterval:        100 * time.Millisecond,
+		MaxSSTSize:          1024 * 1024 * 1024,
+		MinL0CompactionSSTs: 2,
+	})
+	db, err := OpenWithOptions(ctx, "/tmp/test_kv_store", objstore.NewInMemBucket(), options)
+	require.NoError(t, err)
+	defer func() { _ = db.Close(ctx) }()
+
+	// the oldest values are compacted into a sorted run
+	require.NoError(t, db.Put(ctx, []byte("key1"), []byte("sr")))
+	require.NoError(t, db.Put(ctx, []byte("key2"), []byte("sr")))
+	require.NoError(t, db.Put(ctx, []byte("key3"), []byte("sr")))
+	require.NoError(t, db.FlushMemtableToL0())
+	require.NoError(t, db.Put(ctx, []byte("key4"), []byte("sr")))
+	require.NoError(t, db.FlushMemtableToL0())
+	require.Eventually(t, func() bool {
+		return len(db.state.CoreStateSnapshot().Compacted) == 1
+	}, 10*time.Second, 50*time.Millisecond)
+
+	// newer values in L0 shadow the sorted run
+	require.NoError(t, db.Put(ctx, []byte("key2"), []byte("l0")))
+	require.NoError(t, db.Delete(ctx, []byte("key3")))
+	require.NoError(t, db.FlushMemtableToL0())
+
+	// newer values in the memtable shadow L0
+	require.NoError(t, db.Put(ctx, []byte("key2"), []byte("memtable")))
+	require.NoError(t, db.Put(ctx, []byte("key5"), []byte("memtable")))
+	require.NoError(t, db.Delete(ctx, []byte("key4")))
+
+	// a write which is not yet durable is only seen by Uncommitted scans
+	require.NoError(t, db.PutWithOptions(ctx, []byte("key6"), []byte("wal"),
+		config.WriteOptions{AwaitDurable: false}))
+
+	it, err := db.Scan(ctx, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"key1": "sr", "key2": "memtable", "key5": "memtable"}, scanAll(t, it))
+
+	it, err = db.ScanWithOptions(ctx, nil, nil, config.ReadOptions{ReadLevel: config.Uncommitted})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"key1": "sr", "key2": "memtable", "key5": "memtable", "key6": "wal"},
+		scanAll(t, it))
+
+	// the start of the range is inclusive and the end exclusive
+	it, err = db.Scan(ctx, []byte("key2"), []byte("key5"))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"key2": "memtable"}, scanAll(t, it))
+
+	it, err = db.Scan(ctx, []byte("key2"), nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"key2": "memtable", "key5": "memtable"}, scanAll(t, it))
+
+	_, err = db.Scan(ctx, []byte("key5"), []byte("key2"))
+	assert.Error(t, err)
+
+	// a reverse scan returns the same keys in descending order
+	reverse := func(start, end []byte, level config.ReadLevel) []KeyValue {
+		t.Helper()
+		it, err := db.ScanWithIteratorOptions(ctx, start, end,
+			config.IteratorOptions{ReadLevel: level, Reverse: true})
+		require.NoError(t, err)
+		var kvs []KeyValue
+		for {
+			kv, ok := it.Next(ctx)
+			if !ok {
+				break
+			}
+			kvs = append(kvs, kv)
+		}
+		require.NoError(t, it.Err())
+		return kvs
+	}
+	kv := func(key, value string) KeyValue {
+		return KeyValue{Key: []byte(key), Value: []byte(value)}
+	}
+	assert.Equal(t, []KeyValue{kv("key5", "memtable"), kv("key2", "memtable"), kv("key1", "sr")},
+		reverse(nil, nil, config.Committed))
+	assert.Equal(t, []KeyValue{kv("key6", "wal"), kv("key5", "memtable"), kv("key2", "memtable"), kv("key1", "sr")},
+		reverse(nil, nil, config.Uncommitted))
+	assert.Equal(t, []KeyValue{kv("key2", "memtable")}, reverse([]byte("key2"), []byte("key5"), config.Committed))
+	assert.Equal(t, []KeyValue{kv("key2", "memtable"), kv("key1", "sr")}, reverse(nil, []byte("key3"), config.Committed))
+}
+
+func TestScanTransform(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	db, err := OpenInMemory(ctx, testDBOptions(0, 1024))
+	require.NoError(t, err)
+	defer func() { _ = db.Close(ctx) }()
+
+	require.NoError(t, db.Put(ctx, []byte("key1"), []byte("first:one")))
+	require.NoError(t, db.Put(ctx, []byte("key2"), []byte("second:two")))
+	require.NoError(t, db.Put(ctx, []byte("key3"), []byte("third")))
+
+	// the transform projects the value up to the first ':'
+	project := func(dst []byte, key []byte, value []byte) ([]byte, error) {
+		field, _, ok := bytes.Cut(value, []byte(":"))
+		if !ok {
+			return nil, errors.New("no field separator")
+		}
+		return append(dst, field...), nil
+	}
+
+	it, err := db.ScanWithIteratorOptions(ctx, nil, []byte("key3"), config.IteratorOptions{Transform: project})
+	require.NoError(t, err)
+	kv, ok := it.Next(ctx)
+	require.True(t, ok)
+	assert.Equal(t, "first", string(kv.Value))
+	kv, ok = it.Next(ctx)
+	require.True(t, ok)
+	assert.Equal(t, "second", string(kv.Value))
+	_, ok = it.Next(ctx)
+	assert.False(t, ok)
+	require.NoError(t, it.Err())
+
+	// an error returned by the transform stops the iteration
+	it, err = db.ScanWithIteratorOptions(ctx, []byte("key2"), nil, config.IteratorOptions{Transform: project})
+	require.NoError(t, err)
+	_, ok = it.Next(ctx)
+	require.True(t, ok)
+	_, ok = it.Next(ctx)
+	assert.False(t, ok)
+	assert.ErrorContains(t, it.Err(), "no field separator")
+}
+
+func TestScanThroughput(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	options := testDBOptions(0, 1024*1024)
+	options.BlockCacheSize = 1024 * 1024
+	db, err := OpenWithOptions(ctx, "/tmp/test_kv_store", objstore.NewInMemBucket(), options)
+	require.NoError(t, err)
+	defer func() { _ = db.Close(ctx) }()
+
+	noWait := config.WriteOptions{AwaitDurable: false}
+	expected := make(map[string]string)
+	for i := 0; i < 300; i++ {
+		key, value := fmt.Sprintf("key%03d", i), fmt.Sprintf("value%03d-%s", i, repeatedChar('a', 64))
+		require.NoError(t, db.PutWithOptions(ctx, []byte(key), []byte(value), noWait))
+		expected[key] = value
+	}
+	require.NoError(t, db.FlushWAL(ctx))
+	require.NoError(t, db.FlushMemtableToL0())
+	for i := 0; i < 300; i += 10 {
+		key, value := fmt.Sprintf("key%03d", i), fmt.Sprintf("updated%03d", i)
+		require.NoError(t, db.PutWithOptions(ctx, []byte(key), []byte(value), noWait))
+		expected[key] = value
+	}
+	require.NoError(t, db.FlushWAL(ctx))
+	require.NoError(t, db.FlushMemtableToL0())
+	require.Len(t, db.state.L0(), 2)
+
+	// the blocks read by a throughput scan are not cached
+	throughput := config.IteratorOptions{Throughput: true}
+	it, err := db.ScanWithIteratorOptions(ctx, nil, nil, throughput)
+	require.NoError(t, err)
+	assert.Equal(t, expected, scanAll(t, it))
+	assert.Zero(t, db.tableStore.BlockCacheStats().Size)
+
+	it, err = db.ScanWithIteratorOptions(ctx, []byte("key100"), []byte("key200"), throughput)
+	require.NoError(t, err)
+	assert.Len(t, scanAll(t, it), 100)
+
+	throughput.Reverse = true
+	it, err = db.ScanWithIteratorOptions(ctx, nil, nil, throughput)
+	require.NoError(t, err)
+	count := 0
+	for {
+		kv, ok := it.Next(ctx)
+		if !ok {
+			break
+		}
+		assert.Equal(t, expected[string(kv.Key)], string(kv.Value))
+		count++
+	}
+	require.NoError(t, it.Err())
+	assert.Equal(t, len(expected), count)
+	assert.Zero(t, db.tableStore.BlockCacheStats().Size)
+
+	it, err = db.Scan(ctx, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, expected, scanAll(t, it))
+	assert.Positive(t, db.tableStore.BlockCacheStats().Size)
+}
+
+func TestScanCancellation(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	clock := config.NewManualClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	options := testDBOptions(0, 1024*1024)
+	options.Clock = clock
+	db, err := OpenInMemory(ctx, options)
+	require.NoError(t, err)
+	defer func() { _ = db.Close(ctx) }()
+
+	for i := 0; i < 200; i++ {
+		require.NoError(t, db.PutWithOptions(ctx, []byte(fmt.Sprintf("key%03d", i)), []byte("value"),
+			config.WriteOptions{AwaitDurable: false}))
+	}
+	require.NoError(t, db.FlushWAL(ctx))
+	require.NoError(t, db.FlushMemtableToL0())
+
+	// the iteration stops once the context passed to Next is done
+	scanCtx, cancelScan := context.WithCancel(ctx)
+	it, err := db.Scan(scanCtx, nil, nil)
+	require.NoError(t, err)
+	_, ok := it.Next(scanCtx)
+	require.True(t, ok)
+	cancelScan()
+	_, ok = it.Next(scanCtx)
+	assert.False(t, ok)
+	assert.ErrorIs(t, it.Err(), context.Canceled)
+
+	// the iteration stops once it has run for longer than MaxDuration
+	it, err = db.ScanWithIteratorOptions(ctx, nil, nil, config.IteratorOptions{MaxDuration: time.Second})
+	require.NoError(t, err)
+	for i := 0; i < 10; i++ {
+		_, ok = it.Next(ctx)
+		require.True(t, ok)
+	}
+	clock.Advance(2 * time.Second)
+	count := 10
+	for {
+		if _, ok := it.Next(ctx); !ok {
+			break
+		}
+		count++
+	}
+	assert.Less(t, count, 10+deadlineInterval)
+	assert.ErrorIs(t, it.Err(), context.DeadlineExceeded)
+	assert.ErrorContains(t, it.Err(), "MaxDuration")
+
+	// a scan which completes within MaxDuration is unaffected
+	it, err = db.ScanWithIteratorOptions(ctx, nil, nil, config.IteratorOptions{MaxDuration: time.Minute})
+	require.NoError(t, err)
+	assert.Len(t, scanAll(t, it), 200)
+}
+
+func TestScanPrefix(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	options := testDBOptions(0, 1024)
+	options.PrefixExtractor = bloom.DelimitedPrefix('/')
+	db, err := OpenWithOptions(ctx, "/tmp/test_kv_store", objstore.NewInMemBucket(), options)
+	require.NoError(t, err)
+	defer func() { _ = db.Close(ctx) }()
+
+	// each tenant is flushed to its own L0 SST
+	for _, tenant := range []string{"a", "b", "c"} {
+		for _, key := range []string{"k1", "k2", "k3"} {
+			require.NoError(t, db.Put(ctx, []byte(tenant+"/"+key), []byte(tenant)))
+		}
+		require.NoError(t, db.FlushMemtableToL0())
+	}
+	l0 := db.state.CoreStateSnapshot().L0
+	require.Len(t, l0, 3)
+	for _, sst := range l0 {
+		assert.Equal(t, "delimited:47", sst.Info.FilterPrefixExtractor)
+	}
+
+	it, err := db.ScanPrefix(ctx, []byte("b/"))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"b/k1": "b", "b/k2": "b", "b/k3": "b"}, scanAll(t, it))
+	// the SST of tenant a is excluded by its filter, and the SST of tenant c by its first key
+	assert.Equal(t, uint64(1), db.stats.scanSSTsSkipped.Load())
+
+	// a range within a prefix uses the filters, a range across prefixes does not
+	it, err = db.Scan(ctx, []byte("b/k2"), []byte("b/k9"))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"b/k2": "b", "b/k3": "b"}, scanAll(t, it))
+	assert.Equal(t, uint64(2), db.stats.scanSSTsSkipped.Load())
+	it, err = db.Scan(ctx, []byte("a/k3"), []byte("b/k2"))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"a/k3": "a", "b/k1": "b"}, scanAll(t, it))
+	assert.Equal(t, uint64(2), db.stats.scanSSTsSkipped.Load())
+
+	// a prefix which no SST holds
+	it, err = db.ScanPrefix(ctx, []byte("bb/"))
+	require.NoError(t, err)
+	assert.Empty(t, scanAll(t, it))
+
+	// the SSTs of a sorted run which exclude the prefix are skipped
+	sr := compacted.SortedRun{ID: 1, SSTList: []sstable.Handle{l0[2], l0[1], l0[0]}}
+	filtered := db.prefixFilterSortedRun(ctx, sr, []byte("b/"), []byte("b0"), []byte("b/"))
+	require.Len(t, filtered.SSTList, 1)
+	assert.Equal(t, l0[1].Id, filtered.SSTList[0].Id)
+}
+
+func TestPrefixEnd(t *testing.T) {
+	assert.Equal(t, []byte("b0"), prefixEnd([]byte("b/")))
+	assert.Equal(t, []byte("b"), prefixEnd([]byte("a\xff")))
+	assert.Nil(t, prefixEnd([]byte("\xff\xff")))
+}
@@ -0,0 +1,150 @@
+package slatedb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/samber/mo"
+
+	"github.com/slatedb/slatedb-go/internal/sstable"
+	"github.com/slatedb/slatedb-go/slatedb/store"
+)
+
+// SSTCorruption describes a corrupted part of an SST found by DB.VerifyChecksums
+type SSTCorruption = store.SSTCorruption
+
+// CorruptionPart is the part of an SST which is corrupted, see SSTCorruption
+type CorruptionPart = store.CorruptionPart
+
+const (
+	CorruptionMissing = store.CorruptionMissing
+	CorruptionInfo    = store.CorruptionInfo
+	CorruptionIndex   = store.CorruptionIndex
+	CorruptionFilter  = store.CorruptionFilter
+	CorruptionBlock   = store.CorruptionBlock
+)
+
+// ChecksumReport summarizes the SSTs checked by VerifyChecksums
+type ChecksumReport struct {
+	// The time the verification started
+	Started time.Time
+
+	// The number of L0 and compacted SSTs which were verified
+	SSTs int
+
+	// The number of blocks which were decoded and had their checksums validated
+	Blocks int
+
+	// Every corruption which was found, in the order the SSTs were verified
+	Corruptions []SSTCorruption
+}
+
+// VerifyChecksums reads every L0 and compacted SST referenced by the manifest from object
+// storage and validates the checksums of its blocks, index and filter, along with the consistency
+// of its index with its blocks. Unlike Verify, which fails on the first corruption, every corrupted
+// part of every SST is included in the report, such that corruption can be found and repaired
+// before it is read. The SSTs are streamed a few blocks at a time and bypass the caches.
+//
+// An error is only returned if object storage could not be read. An SST which was removed from
+// the manifest, such as by a compaction, while it was being verified is not reported as missing.
+// A corrupted SST is quarantined if config.DBOptions.QuarantineCorruptSSTs is set.
+func (db *DB) VerifyChecksums(ctx context.Context) (ChecksumReport, error) {
+	report := ChecksumReport{Started: db.opts.Clock.Now()}
+	core := db.state.CoreStateSnapshot()
+	ssts := append([]sstable.Handle{}, core.L0...)
+	for _, sr := range core.Compacted {
+		ssts = append(ssts, sr.SSTList...)
+	}
+
+	for _, sst := range ssts {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+		blocks, corruptions, err := db.tableStore.VerifySST(ctx, &sst)
+		if err != nil {
+			return report, fmt.Errorf("while verifying sst '%s': %w", sst.Id.String(), err)
+		}
+		if len(corruptions) == 1 && corruptions[0].Part == CorruptionMissing && !db.isSSTReferenced(sst.Id) {
+			continue
+		}
+		report.SSTs++
+		report.Blocks += blocks
+		report.Corruptions = append(report.Corruptions, corruptions...)
+		if len(corruptions) > 0 && !db.tableStore.IsQuarantined(sst.Id) {
+			db.quarantineSST(sst.Id, errors.New(corruptions[0].String()))
+		}
+	}
+	return report, nil
+}
+
+// ScrubReport returns the report of the most recent background scrub, or None if no scrub has
+// completed. See config.DBOptions.ScrubInterval
+func (db *DB) ScrubReport() mo.Option[ChecksumReport] {
+	db.scrubMu.Lock()
+	defer db.scrubMu.Unlock()
+	return db.scrubReport
+}
+
+// isSSTReferenced returns true if the current state of the database references the SST `id`
+func (db *DB) isSSTReferenced(id sstable.ID) bool {
+	core := db.state.CoreStateSnapshot()
+	for _, sst := range core.L0 {
+		if sst.Id == id {
+			return true
+		}
+	}
+	for _, sr := range core.Compacted {
+		for _, sst := range sr.SSTList {
+			if sst.Id == id {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// spawnScrubTask verifies the checksums of every SST every DBOptions.ScrubInterval
+func (db *DB) spawnScrubTask() {
+	if db.opts.ScrubInterval <= 0 {
+		return
+	}
+	// The ticker is created before the task is started, such that the first scrub is
+	// ScrubInterval after the database is opened
+	ticker := db.newIdleTicker(db.opts.ScrubInterval)
+	db.tasks.Go("scrub", func(ctx context.Context) error {
+		for {
+			select {
+			case <-ticker.C():
+				db.scrub(ctx)
+				ticker.pauseIfIdle()
+			case <-ticker.Woken():
+				ticker.resume()
+			case <-ctx.Done():
+				ticker.Stop()
+				return nil
+			}
+		}
+	})
+}
+
+func (db *DB) scrub(ctx context.Context) {
+	report, err := db.VerifyChecksums(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			db.opts.Log.Warn("scrub failed", "error", err)
+		}
+		return
+	}
+	for _, c := range report.Corruptions {
+		db.opts.Log.Error("corrupted sst", "sst", c.SSTID, "part", c.Part.String(),
+			"block", c.Block, "error", c.Err)
+	}
+	db.opts.Log.Info("scrubbed ssts", "ssts", report.SSTs, "blocks", report.Blocks,
+		"corruptions", len(report.Corruptions))
+
+	db.scrubMu.Lock()
+	db.scrubReport = mo.Some(report)
+	db.scrubMu.Unlock()
+}
@@ -0,0 +1,73 @@
+package slatedb
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore"
+
+	"github.com/slatedb/slatedb-go/slatedb/config"
+)
+
+func TestVerifyChecksums(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	bucket := objstore.NewInMemBucket()
+	clock := config.NewManualClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	options := testDBOptions(0, 1024)
+	options.Clock = clock
+	options.ScrubInterval = time.Hour
+	db, err := OpenWithOptions(ctx, "/tmp/test_kv_store", bucket, options)
+	require.NoError(t, err)
+	defer func() { _ = db.Close(ctx) }()
+
+	require.NoError(t, db.PutWithOptions(ctx, []byte("key1"), []byte("value1"), config.WriteOptions{AwaitDurable: false}))
+	require.NoError(t, db.FlushWAL(ctx))
+	require.NoError(t, db.FlushMemtableToL0())
+	report, err := db.VerifyChecksums(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.SSTs)
+	assert.Equal(t, 1, report.Blocks)
+	assert.Empty(t, report.Corruptions)
+	assert.True(t, db.ScrubReport().IsAbsent())
+
+	// Corrupt the first block of the L0 SST
+	var path string
+	require.NoError(t, bucket.Iter(ctx, "", func(name string) error {
+		if strings.HasSuffix(name, ".sst") && strings.Contains(name, "compacted") {
+			path = name
+		}
+		return nil
+	}, objstore.WithRecursiveIter()))
+	require.NotEmpty(t, path)
+	r, err := bucket.Get(ctx, path)
+	require.NoError(t, err)
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+	data[0]++
+	require.NoError(t, bucket.Upload(ctx, path, bytes.NewReader(data)))
+
+	report, err = db.VerifyChecksums(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.SSTs)
+	assert.Equal(t, 0, report.Blocks)
+	require.Len(t, report.Corruptions, 1)
+	assert.Equal(t, CorruptionBlock, report.Corruptions[0].Part)
+	assert.Equal(t, uint64(0), report.Corruptions[0].Block)
+
+	// The background scrubber reports the same corruption
+	clock.Advance(time.Hour)
+	require.Eventually(t, func() bool {
+		return db.ScrubReport().IsPresent()
+	}, 5*time.Second, 10*time.Millisecond)
+	scrubbed := db.ScrubReport().MustGet()
+	require.Len(t, scrubbed.Corruptions, 1)
+	assert.Equal(t, report.Corruptions[0].SSTID, scrubbed.Corruptions[0].SSTID)
+}
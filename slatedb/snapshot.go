@@ -0,0 +1,79 @@
+package slatedb
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/slatedb/slatedb-go/slatedb/config"
+	"github.com/slatedb/slatedb-go/slatedb/state"
+)
+
+// Snapshot is a point-in-time view of the database returned by DB.Snapshot. Reads
+// from a Snapshot see the WAL, memtables, L0 SSTs and sorted runs as they were when
+// the Snapshot was taken, and are unaffected by subsequent writes, flushes and compactions.
+type Snapshot struct {
+	db       *DB
+	state    *state.DBStateSnapshot
+	released atomic.Bool
+
+	// unpin releases the SSTs of the Snapshot to the garbage collector, see DB.CollectGarbage
+	unpin func()
+}
+
+// Snapshot pins the current view of the database. The Snapshot holds a copy of the
+// unflushed WAL and memtable, and its SSTs are retained by DB.CollectGarbage, as such
+// callers should Release the Snapshot once it is no longer needed.
+func (db *DB) Snapshot(ctx context.Context) (*Snapshot, error) {
+	if err := db.maybeRefresh(ctx); err != nil {
+		return nil, fmt.Errorf("while refreshing read-only view: %w", err)
+	}
+	snapshot := db.state.Snapshot()
+	return &Snapshot{
+		db:    db,
+		state: snapshot,
+		unpin: db.pins.pin(snapshot.Core),
+	}, nil
+}
+
+func (s *Snapshot) Get(ctx context.Context, key []byte) ([]byte, error) {
+	return s.GetWithOptions(ctx, key, config.DefaultReadOptions())
+}
+
+// GetWithOptions returns the value of the key when the Snapshot was taken. See DB.GetWithOptions.
+func (s *Snapshot) GetWithOptions(ctx context.Context, key []byte, options config.ReadOptions) ([]byte, error) {
+	if s.released.Load() {
+		return nil, ErrSnapshotReleased
+	}
+	defer s.db.recordGet(s.db.opts.Clock.Now())
+	return s.db.getFromState(ctx, s.state, key, options)
+}
+
+func (s *Snapshot) Scan(ctx context.Context, start []byte, end []byte) (*DBIterator, error) {
+	return s.ScanWithOptions(ctx, start, end, config.DefaultReadOptions())
+}
+
+// ScanWithOptions returns a DBIterator over the keys in the range [start, end) when the
+// Snapshot was taken. See DB.ScanWithOptions.
+func (s *Snapshot) ScanWithOptions(ctx context.Context, start []byte, end []byte,
+	options config.ReadOptions) (*DBIterator, error) {
+	return s.ScanWithIteratorOptions(ctx, start, end, config.IteratorOptions{ReadLevel: options.ReadLevel})
+}
+
+// ScanWithIteratorOptions returns a DBIterator over the keys in the range [start, end) when the
+// Snapshot was taken. See DB.ScanWithIteratorOptions.
+func (s *Snapshot) ScanWithIteratorOptions(ctx context.Context, start []byte, end []byte,
+	options config.IteratorOptions) (*DBIterator, error) {
+	if s.released.Load() {
+		return nil, ErrSnapshotReleased
+	}
+	return s.db.scanState(ctx, s.state, start, end, options)
+}
+
+// Release unpins the view held by the Snapshot. Reads made after Release
+// return ErrSnapshotReleased. Iterators returned by Scan before Release remain usable.
+func (s *Snapshot) Release() {
+	if !s.released.Swap(true) {
+		s.unpin()
+	}
+}
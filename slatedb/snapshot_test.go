@@ -0,0 +1,68 @@
+package slatedb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore"
+
+	"github.com/slatedb/slatedb-go/slatedb/config"
+)
+
+func TestSnapshot(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	options := testDBOptionsCompactor(0, 1024, &config.CompactorOptions{
+		PollInterval:        100 * time.Millisecond,
+		MaxSSTSize:          1024 * 1024 * 1024,
+		MinL0CompactionSSTs: 2,
+	})
+	db, err := OpenWithOptions(ctx, "/tmp/test_kv_store", objstore.NewInMemBucket(), options)
+	require.NoError(t, err)
+	defer func() { _ = db.Close(ctx) }()
+
+	require.NoError(t, db.Put(ctx, []byte("key1"), []byte("value1")))
+	require.NoError(t, db.Put(ctx, []byte("key2"), []byte("value2")))
+	require.NoError(t, db.FlushMemtableToL0())
+	require.NoError(t, db.Put(ctx, []byte("key3"), []byte("value3")))
+
+	snapshot, err := db.Snapshot(ctx)
+	require.NoError(t, err)
+
+	// writes, flushes and compactions made after the snapshot are not visible to it
+	require.NoError(t, db.Put(ctx, []byte("key1"), []byte("updated")))
+	require.NoError(t, db.Delete(ctx, []byte("key3")))
+	require.NoError(t, db.Put(ctx, []byte("key4"), []byte("value4")))
+	require.NoError(t, db.FlushMemtableToL0())
+	require.Eventually(t, func() bool {
+		return len(db.state.CoreStateSnapshot().Compacted) == 1
+	}, 10*time.Second, 50*time.Millisecond)
+
+	value, err := snapshot.Get(ctx, []byte("key1"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("value1"), value)
+	value, err = snapshot.Get(ctx, []byte("key3"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("value3"), value)
+	_, err = snapshot.Get(ctx, []byte("key4"))
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+
+	it, err := snapshot.Scan(ctx, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"key1": "value1", "key2": "value2", "key3": "value3"}, scanAll(t, it))
+
+	// the database itself sees the latest writes
+	value, err = db.Get(ctx, []byte("key1"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("updated"), value)
+
+	snapshot.Release()
+	_, err = snapshot.Get(ctx, []byte("key1"))
+	assert.ErrorIs(t, err, ErrSnapshotReleased)
+	_, err = snapshot.Scan(ctx, nil, nil)
+	assert.ErrorIs(t, err, ErrSnapshotReleased)
+}
@@ -2,6 +2,7 @@ package slatedb
 
 import (
 	"context"
+	"slices"
 	"testing"
 	"time"
 
@@ -203,3 +204,109 @@ func TestSRIterFromKeyHigherThanRange(t *testing.T) {
 	assert.False(t, ok)
 	assert.Equal(t, types.RowEntry{}, next)
 }
+
+func TestSRReverseIterFromKey(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*20)
+	defer cancel()
+	bucket := objstore.NewInMemBucket()
+	conf := sstable.DefaultConfig()
+	conf.MinFilterKeys = 3
+	tableStore := store.NewTableStore(bucket, conf, "")
+
+	firstKey := []byte("bbbbbbbbbbbbbbbb")
+	keyGen := common.NewOrderedBytesGeneratorWithByteRange(firstKey, byte('a'), byte('y'))
+	testCaseKeyGen := keyGen.Clone()
+
+	firstVal := []byte("1111111111111111")
+	valGen := common.NewOrderedBytesGeneratorWithByteRange(firstVal, byte(1), byte(26))
+
+	sr, err := buildSRWithSSTs(3, 10, tableStore, keyGen, valGen)
+	require.NoError(t, err)
+
+	keys := make([][]byte, 0, 30)
+	for i := 0; i < 30; i++ {
+		keys = append(keys, testCaseKeyGen.Next())
+	}
+	collect := func(iter *compacted.SortedRunIterator) [][]byte {
+		var result [][]byte
+		for {
+			e, ok := iter.NextEntry(ctx)
+			if !ok {
+				return result
+			}
+			result = append(result, e.Key)
+		}
+	}
+	reversed := slices.Clone(keys)
+	slices.Reverse(reversed)
+
+	kvIter, err := compacted.NewReverseSortedRunIterator(ctx, sr, tableStore)
+	require.NoError(t, err)
+	assert.Equal(t, reversed, collect(kvIter))
+
+	for i, key := range keys {
+		kvIter, err := compacted.NewReverseSortedRunIteratorFromKey(ctx, sr, key, tableStore)
+		require.NoError(t, err)
+		assert.Equal(t, reversed[len(keys)-1-i:], collect(kvIter))
+	}
+
+	kvIter, err = compacted.NewReverseSortedRunIteratorFromKey(ctx, sr, []byte("aaaaaaaaaa"), tableStore)
+	require.NoError(t, err)
+	assert.Empty(t, collect(kvIter))
+	kvIter, err = compacted.NewReverseSortedRunIteratorFromKey(ctx, sr, []byte("zzzzzzzzzz"), tableStore)
+	require.NoError(t, err)
+	assert.Equal(t, reversed, collect(kvIter))
+}
+
+func TestSRIterPrefetches(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*20)
+	defer cancel()
+	bucket := objstore.NewInMemBucket()
+	conf := sstable.DefaultConfig()
+	conf.BlockSize = 256
+	tableStore := store.NewTableStore(bucket, conf, "")
+	tableStore.SetPrefetchConcurrency(2)
+
+	firstKey := []byte("bbbbbbbbbbbbbbbb")
+	keyGen := common.NewOrderedBytesGeneratorWithByteRange(firstKey, byte('a'), byte('y'))
+	testCaseKeyGen := keyGen.Clone()
+
+	firstVal := []byte("1111111111111111")
+	valGen := common.NewOrderedBytesGeneratorWithByteRange(firstVal, byte(1), byte(26))
+
+	sr, err := buildSRWithSSTs(4, 50, tableStore, keyGen, valGen)
+	require.NoError(t, err)
+
+	keys := make([][]byte, 0, 200)
+	for i := 0; i < 200; i++ {
+		keys = append(keys, testCaseKeyGen.Next())
+	}
+	// collect cancels the ctx of each call once it returns, as compactions do
+	collect := func(iter *compacted.SortedRunIterator) [][]byte {
+		var result [][]byte
+		for {
+			callCtx, cancel := context.WithTimeout(ctx, time.Second*10)
+			e, ok := iter.NextEntry(callCtx)
+			cancel()
+			if !ok {
+				assert.True(t, iter.Warnings().Empty())
+				return result
+			}
+			result = append(result, e.Key)
+		}
+	}
+
+	kvIter, err := compacted.NewSortedRunIterator(ctx, sr, tableStore)
+	require.NoError(t, err)
+	assert.Equal(t, keys, collect(kvIter))
+
+	reversed := slices.Clone(keys)
+	slices.Reverse(reversed)
+	kvIter, err = compacted.NewReverseSortedRunIterator(ctx, sr, tableStore)
+	require.NoError(t, err)
+	assert.Equal(t, reversed, collect(kvIter))
+
+	kvIter, err = compacted.NewSortedRunIteratorFromKey(ctx, sr, keys[120], tableStore)
+	require.NoError(t, err)
+	assert.Equal(t, keys[120:], collect(kvIter))
+}
@@ -36,6 +36,9 @@ type CoreDBState struct {
 	// This value is updated when Memtable is flushed to Level0 of object store.
 	// It is later used during crash recovery to recover only those WALs that have not yet been flushed to Level0.
 	lastCompactedWalSSTID atomic.Uint64
+
+	// lastSeq is the last sequence number allocated to a write. The next write is allocated lastSeq + 1
+	lastSeq atomic.Uint64
 }
 
 type CoreStateSnapshot struct {
@@ -44,6 +47,7 @@ type CoreStateSnapshot struct {
 	Compacted             []compacted.SortedRun
 	NextWalSstID          atomic.Uint64
 	LastCompactedWalSSTID atomic.Uint64
+	LastSeq               atomic.Uint64
 }
 
 func (s *CoreStateSnapshot) ToCoreState() *CoreDBState {
@@ -55,6 +59,7 @@ func (s *CoreStateSnapshot) ToCoreState() *CoreDBState {
 	}
 	coreState.nextWalSstID.Store(s.NextWalSstID.Load())
 	coreState.lastCompactedWalSSTID.Store(s.LastCompactedWalSSTID.Load())
+	coreState.lastSeq.Store(s.LastSeq.Load())
 	return coreState
 }
 
@@ -74,6 +79,7 @@ func (s *CoreStateSnapshot) Clone() *CoreStateSnapshot {
 	}
 	snapshot.NextWalSstID.Store(s.NextWalSstID.Load())
 	snapshot.LastCompactedWalSSTID.Store(s.LastCompactedWalSSTID.Load())
+	snapshot.LastSeq.Store(s.LastSeq.Load())
 	return snapshot
 }
 
@@ -104,6 +110,7 @@ func (c *CoreDBState) Snapshot() *CoreStateSnapshot {
 	}
 	coreState.NextWalSstID.Store(c.nextWalSstID.Load())
 	coreState.LastCompactedWalSSTID.Store(c.lastCompactedWalSSTID.Load())
+	coreState.LastSeq.Store(c.lastSeq.Load())
 	return coreState
 }
 
@@ -124,6 +131,40 @@ type DBStateSnapshot struct {
 	Core         *CoreStateSnapshot
 }
 
+// RangeTombstones returns the range tombstones of every layer of the snapshot which overlap the
+// range [start, end). If start or end is empty, the range is unbounded on that side. The range
+// tombstones of the WAL are only included if includeWAL is true.
+func (s *DBStateSnapshot) RangeTombstones(start []byte, end []byte, includeWAL bool) []types.RangeTombstone {
+	result := make([]types.RangeTombstone, 0)
+	if includeWAL {
+		result = append(result, s.Wal.RangeTombstones(start, end)...)
+		for i := 0; i < s.ImmWALs.Len(); i++ {
+			result = append(result, s.ImmWALs.At(i).RangeTombstones(start, end)...)
+		}
+	}
+	result = append(result, s.Memtable.RangeTombstones(start, end)...)
+	for i := 0; i < s.ImmMemtables.Len(); i++ {
+		result = append(result, s.ImmMemtables.At(i).RangeTombstones(start, end)...)
+	}
+
+	sstTombstones := func(sst sstable.Handle) {
+		for _, t := range sst.Info.RangeTombstones {
+			if t.Overlaps(start, end) {
+				result = append(result, t)
+			}
+		}
+	}
+	for _, sst := range s.Core.L0 {
+		sstTombstones(sst)
+	}
+	for _, sr := range s.Core.Compacted {
+		for _, sst := range sr.SSTList {
+			sstTombstones(sst)
+		}
+	}
+	return result
+}
+
 type DBState struct {
 	sync.RWMutex
 	wal          *table.WAL
@@ -131,8 +172,17 @@ type DBState struct {
 	immWALs      *deque.Deque[*table.ImmutableWAL]
 	immMemtables *deque.Deque[*table.ImmutableMemtable]
 	core         *CoreDBState
+
+	// walObserver, if not nil, is called with each write to the WAL, see SetWALObserver
+	walObserver WALObserver
 }
 
+// WALObserver is called with the entries and range tombstones of a write to the WAL once their
+// sequence numbers are allocated, along with the ID of the WAL SST the write is flushed to. It is
+// called while the lock is held, such that it observes the writes in the order they are added to
+// the WAL, and must not call the DBState.
+type WALObserver func(walID uint64, entries []types.RowEntry, tombstones []types.RangeTombstone)
+
 func NewDBState(coreDBState *CoreDBState) *DBState {
 	return &DBState{
 		wal:          table.NewWAL(),
@@ -143,6 +193,19 @@ func NewDBState(coreDBState *CoreDBState) *DBState {
 	}
 }
 
+// ReplaceWith replaces the in memory tables and core state with those of `other`
+func (s *DBState) ReplaceWith(other *DBState) {
+	other.RLock()
+	defer other.RUnlock()
+	s.Lock()
+	defer s.Unlock()
+	s.wal = other.wal
+	s.memtable = other.memtable
+	s.immWALs = other.immWALs
+	s.immMemtables = other.immMemtables
+	s.core = other.core
+}
+
 func (s *DBState) WAL() *table.WAL {
 	s.RLock()
 	defer s.RUnlock()
@@ -181,20 +244,136 @@ func (s *DBState) LastCompactedWALID() uint64 {
 	return s.core.lastCompactedWalSSTID.Load()
 }
 
+func (s *DBState) LastSeq() uint64 {
+	return s.core.lastSeq.Load()
+}
+
+// SetWALObserver sets the WALObserver which is called with each write to the WAL
+func (s *DBState) SetWALObserver(o WALObserver) {
+	s.Lock()
+	defer s.Unlock()
+	s.walObserver = o
+}
+
+// WalPut allocates the next sequence number to the entry and adds it to the WAL. Sequence
+// numbers are allocated while holding the lock, such that they are ordered the same as the writes.
 func (s *DBState) WalPut(entry types.RowEntry) *table.WAL {
 	s.Lock()
 	defer s.Unlock()
+	entry.Seq = s.core.lastSeq.Add(1)
 	s.wal.Put(entry)
+	if s.walObserver != nil {
+		s.walObserver(s.core.nextWalSstID.Load(), []types.RowEntry{entry}, nil)
+	}
+	return s.wal
+}
+
+// WalPutBatch allocates consecutive sequence numbers to the entries and adds them to the WAL
+// while holding the lock, such that the entries are flushed in the same WAL SST and a snapshot
+// holds either all of the entries or none of them. See WalPut
+func (s *DBState) WalPutBatch(entries []types.RowEntry) *table.WAL {
+	s.Lock()
+	defer s.Unlock()
+	for i := range entries {
+		entries[i].Seq = s.core.lastSeq.Add(1)
+		s.wal.Put(entries[i])
+	}
+	if s.walObserver != nil {
+		s.walObserver(s.core.nextWalSstID.Load(), entries, nil)
+	}
+	return s.wal
+}
+
+// WalDeleteRange allocates the next sequence number to a range tombstone for the range
+// [start, end) and adds it to the WAL. See WalPut
+func (s *DBState) WalDeleteRange(start []byte, end []byte) *table.WAL {
+	s.Lock()
+	defer s.Unlock()
+	tombstone := types.RangeTombstone{Start: start, End: end, Seq: s.core.lastSeq.Add(1)}
+	s.wal.DeleteRange(tombstone)
+	if s.walObserver != nil {
+		s.walObserver(s.core.nextWalSstID.Load(), nil, []types.RangeTombstone{tombstone})
+	}
 	return s.wal
 }
 
+// MemtableWrite allocates consecutive sequence numbers to the entries and adds them directly to
+// the memtable of a database whose WAL is disabled. See WalPutBatch
+func (s *DBState) MemtableWrite(entries []types.RowEntry) *table.Memtable {
+	s.Lock()
+	defer s.Unlock()
+	for _, entry := range entries {
+		entry.Seq = s.core.lastSeq.Add(1)
+		s.memtable.Put(entry)
+	}
+	return s.memtable
+}
+
+// MemtableWriteDeleteRange allocates the next sequence number to a range tombstone for the range
+// [start, end) and adds it directly to the memtable of a database whose WAL is disabled. See WalDeleteRange
+func (s *DBState) MemtableWriteDeleteRange(start []byte, end []byte) *table.Memtable {
+	s.Lock()
+	defer s.Unlock()
+	s.memtable.DeleteRange(types.RangeTombstone{Start: start, End: end, Seq: s.core.lastSeq.Add(1)})
+	return s.memtable
+}
+
+// MemTablePut adds an entry replayed from the WAL to the memtable. The entry retains the
+// sequence number it was allocated, and sequence numbers allocated by WalPut resume after it.
 func (s *DBState) MemTablePut(entry types.RowEntry) *table.Memtable {
 	s.Lock()
 	defer s.Unlock()
+	if entry.Seq > s.core.lastSeq.Load() {
+		s.core.lastSeq.Store(entry.Seq)
+	}
 	s.memtable.Put(entry)
 	return s.memtable
 }
 
+// MemTableDeleteRange adds a range tombstone replayed from the WAL to the memtable. See MemTablePut
+func (s *DBState) MemTableDeleteRange(tombstone types.RangeTombstone) *table.Memtable {
+	s.Lock()
+	defer s.Unlock()
+	if tombstone.Seq > s.core.lastSeq.Load() {
+		s.core.lastSeq.Store(tombstone.Seq)
+	}
+	s.memtable.DeleteRange(tombstone)
+	return s.memtable
+}
+
+// TableSizes reports the sizes of the in-memory tables of a DBState, see DBState.TableSizes
+type TableSizes struct {
+	WALBytes      int64
+	MemtableBytes int64
+
+	// ImmWALs and ImmMemtables are the number of immutable WALs and memtables, and ImmWALBytes
+	// and ImmMemtableBytes their total size
+	ImmWALs          int
+	ImmWALBytes      int64
+	ImmMemtables     int
+	ImmMemtableBytes int64
+}
+
+// TableSizes returns the sizes of the WAL, the memtable and the immutable WALs and memtables,
+// without copying the tables as Snapshot does
+func (s *DBState) TableSizes() TableSizes {
+	s.RLock()
+	defer s.RUnlock()
+	sizes := TableSizes{
+		WALBytes:      s.wal.Size(),
+		MemtableBytes: s.memtable.Size(),
+		ImmWALs:       s.immWALs.Len(),
+		ImmMemtables:  s.immMemtables.Len(),
+	}
+	for i := 0; i < s.immWALs.Len(); i++ {
+		sizes.ImmWALBytes += s.immWALs.At(i).Table().Size()
+	}
+	for i := 0; i < s.immMemtables.Len(); i++ {
+		sizes.ImmMemtableBytes += s.immMemtables.At(i).Table().Size()
+	}
+	return sizes
+}
+
 func (s *DBState) CoreStateSnapshot() *CoreStateSnapshot {
 	s.RLock()
 	defer s.RUnlock()
@@ -242,10 +421,26 @@ func (s *DBState) FreezeWAL() mo.Option[uint64] {
 	return mo.Some(immWAL.ID())
 }
 
-func (s *DBState) PopImmWAL() {
+// MoveImmWALToMemtable adds the entries and range tombstones of the oldest immutable WAL, which
+// must be `immWAL`, to the memtable and removes the WAL from the immutable WALs. The lock is held
+// throughout, such that a snapshot holds every write of the WAL, such as every write of a
+// WriteBatch, in either the WAL or the memtable.
+func (s *DBState) MoveImmWALToMemtable(immWAL *table.ImmutableWAL) {
 	s.Lock()
 	defer s.Unlock()
 
+	iter := immWAL.Iter()
+	for {
+		entry, err := iter.NextEntry()
+		if err != nil || entry.IsAbsent() {
+			break
+		}
+		s.memtable.Put(entry.MustGet())
+	}
+	for _, t := range immWAL.RangeTombstones(nil, nil) {
+		s.memtable.DeleteRange(t)
+	}
+	s.memtable.SetLastWalID(immWAL.ID())
 	s.immWALs.PopBack()
 }
 
@@ -269,21 +464,50 @@ func (s *DBState) OldestImmMemtable() mo.Option[*table.ImmutableMemtable] {
 	return mo.Some(s.immMemtables.Back())
 }
 
-func (s *DBState) MoveImmMemtableToL0(immMemtable *table.ImmutableMemtable, sstHandle *sstable.Handle) {
+func (s *DBState) MoveImmMemtableToL0(immMemtable *table.ImmutableMemtable, ssts []sstable.Handle) {
 	s.Lock()
 	defer s.Unlock()
 
 	popped := s.immMemtables.PopBack()
 	assert.True(popped.LastWalID() == immMemtable.LastWalID(), "")
 
-	s.core.l0 = append([]sstable.Handle{*sstHandle}, s.core.l0...)
+	// L0 is ordered from newest to oldest, the SSTs of the memtable hold distinct keys and are
+	// added in reverse key order, as the SSTs of a WriteBatch written directly to L0 are
+	l0 := make([]sstable.Handle, 0, len(ssts)+len(s.core.l0))
+	for i := len(ssts) - 1; i >= 0; i-- {
+		l0 = append(l0, ssts[i])
+	}
+	s.core.l0 = append(l0, s.core.l0...)
 	s.core.lastCompactedWalSSTID.Store(immMemtable.LastWalID())
 }
 
+// IngestL0 allocates the next sequence number and adds the SSTs as the newest SSTs of L0.
+// `prepare` is called with the sequence number and returns the handles of the SSTs to add.
+func (s *DBState) IngestL0(prepare func(seq uint64) []sstable.Handle) {
+	s.Lock()
+	defer s.Unlock()
+
+	ssts := prepare(s.core.lastSeq.Add(1))
+	s.core.l0 = append(ssts, s.core.l0...)
+}
+
 func (s *DBState) IncrementNextWALID() {
 	s.core.nextWalSstID.Add(1)
 }
 
+// SetNextWALID sets the ID of the next WAL SST, see IncrementNextWALID
+func (s *DBState) SetNextWALID(id uint64) {
+	s.core.nextWalSstID.Store(id)
+}
+
+// SetLastCompactedWALID marks the WAL SSTs up to and including `id` as flushed to L0, such that
+// they are not replayed, without flushing a memtable
+func (s *DBState) SetLastCompactedWALID(id uint64) {
+	s.Lock()
+	defer s.Unlock()
+	s.core.lastCompactedWalSSTID.Store(id)
+}
+
 func (s *DBState) RefreshDBState(compactorState *CoreStateSnapshot) {
 	s.Lock()
 	defer s.Unlock()
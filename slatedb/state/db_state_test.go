@@ -27,7 +27,7 @@ func addL0sToDBState(dbState *DBState, n uint32) {
 			break
 		}
 		sst := sstable.NewHandle(sstable.NewIDCompacted(ulid.Make()), sstInfo)
-		dbState.MoveImmMemtableToL0(immMemtable.MustGet(), sst)
+		dbState.MoveImmMemtableToL0(immMemtable.MustGet(), []sstable.Handle{*sst})
 	}
 }
 
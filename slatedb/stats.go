@@ -0,0 +1,464 @@
+package slatedb
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/slatedb/slatedb-go/internal/profile"
+	"github.com/slatedb/slatedb-go/internal/sstable"
+	"github.com/slatedb/slatedb-go/slatedb/store"
+)
+
+// Amplification reports the write and read amplification of the database since it was opened.
+type Amplification struct {
+	// Write is the number of bytes written to object storage (WAL, L0 and compacted SSTs)
+	// divided by the number of logical bytes (keys and values) written by the client.
+	Write float64
+
+	// Read is the average number of SSTables probed per call to Get. An SSTable is
+	// probed when its blocks must be read because the bloom filter could not rule it out.
+	Read float64
+}
+
+// dbStats holds counters used to compute database statistics
+type dbStats struct {
+	// bytesIngested is the number of logical bytes (keys and values) written by the client
+	bytesIngested atomic.Uint64
+
+	// gets is the number of calls to Get
+	gets atomic.Uint64
+
+	// sstProbes is the number of SSTables which were read from to serve a Get
+	sstProbes atomic.Uint64
+
+	// getNanos is the total latency of the calls to Get
+	getNanos atomic.Uint64
+
+	// writes is the number of calls to Put or Delete, and writeNanos is their total latency
+	writes     atomic.Uint64
+	writeNanos atomic.Uint64
+
+	// scans is the number of calls to Scan
+	scans atomic.Uint64
+
+	// scanSSTsSkipped is the number of SSTs skipped by scans because their filter excluded the
+	// prefix of the range, see config.DBOptions.PrefixExtractor
+	scanSSTsSkipped atomic.Uint64
+
+	// memtableFlushes is the number of memtables flushed to L0
+	memtableFlushes atomic.Uint64
+}
+
+// Amplification returns the current write and read amplification of the database.
+// These are the two numbers most LSM tuning decisions are made against; a high write
+// amplification indicates compaction is rewriting data too often, while a high read
+// amplification indicates there are too many L0 SSTs or sorted runs.
+func (db *DB) Amplification() Amplification {
+	var amp Amplification
+	if ingested := db.stats.bytesIngested.Load(); ingested > 0 {
+		amp.Write = float64(db.tableStore.BytesWritten()) / float64(ingested)
+	}
+	if gets := db.stats.gets.Load(); gets > 0 {
+		amp.Read = float64(db.stats.sstProbes.Load()) / float64(gets)
+	}
+	return amp
+}
+
+// BlockCacheStats reports the hits and misses of the block cache, see config.DBOptions.BlockCacheSize
+type BlockCacheStats = store.BlockCacheStats
+
+// BlockCacheStats returns the hits, misses and size of the block cache. A low ratio of hits
+// to misses indicates the cache is too small for the working set of the database.
+func (db *DB) BlockCacheStats() BlockCacheStats {
+	return db.tableStore.BlockCacheStats()
+}
+
+// HedgedReadStats reports the reads made by Get which were hedged, see config.DBOptions.HedgedReads
+type HedgedReadStats = store.HedgeStats
+
+// HedgedReadStats returns the number of reads made by Get, the number which were hedged, and
+// the number which were served by the hedge. A high ratio of hedges to reads indicates the
+// hedge delay is too short, which adds load to object storage.
+func (db *DB) HedgedReadStats() HedgedReadStats {
+	return db.tableStore.HedgeStats()
+}
+
+// ------------------------------------------------
+// Stats
+// ------------------------------------------------
+
+// LevelStats reports the live SSTs of L0 or of a sorted run
+type LevelStats struct {
+	SSTs int
+
+	// Bytes is the encoded size of the SSTs, see sstable.Info.EncodedSize
+	Bytes uint64
+}
+
+// SortedRunStats reports the live SSTs of a sorted run
+type SortedRunStats struct {
+	ID uint32
+	LevelStats
+}
+
+// Stats is a snapshot of the shape of the database, see DB.Stats
+type Stats struct {
+	// L0 reports the L0 SSTs, and SortedRuns the SSTs of each sorted run from newest to oldest
+	L0         LevelStats
+	SortedRuns []SortedRunStats
+
+	// Live reports every SST of L0 and the sorted runs
+	Live LevelStats
+
+	// WALBytes is the size of the writes of the WAL and the immutable WALs which are not yet
+	// written to object storage, and ImmutableWALs the number of immutable WALs
+	WALBytes      int64
+	ImmutableWALs int
+
+	// UncompactedWALSSTs is the number of WAL SSTs in object storage whose writes are not yet
+	// in L0, which are replayed when the database is opened
+	UncompactedWALSSTs uint64
+
+	// MemtableBytes is the size of the memtable, and ImmutableMemtables and
+	// ImmutableMemtableBytes the number and size of the memtables awaiting a flush to L0
+	MemtableBytes          int64
+	ImmutableMemtables     int
+	ImmutableMemtableBytes int64
+
+	// PendingCompactionBytes is the size of the L0 SSTs, each of which is yet to be compacted
+	// into a sorted run. A backlog which keeps growing indicates compaction cannot keep up.
+	PendingCompactionBytes uint64
+
+	// Amplification is the write and read amplification measured since the database was opened
+	Amplification Amplification
+
+	// MaxReadAmplification is the number of SSTs a Get may have to probe, one per L0 SST and
+	// one per sorted run, if the filters of the SSTs do not rule out the key
+	MaxReadAmplification int
+
+	// SpaceAmplification is the size of the live SSTs divided by the size of the oldest sorted
+	// run, which estimates the size of the database once fully compacted. It is zero if there
+	// are no sorted runs.
+	SpaceAmplification float64
+
+	// ManifestConflicts is the number of manifest writes of the writer which conflicted with a
+	// manifest written by another process, such as the compactor, since the database was opened
+	ManifestConflicts uint64
+}
+
+// Stats returns a snapshot of the shape of the database: the SSTs of each level, the writes
+// yet to be written to object storage or flushed to L0, the compaction backlog and estimates of
+// amplification. See DB.Property to read a single statistic by name.
+func (db *DB) Stats() Stats {
+	core := db.state.CoreStateSnapshot()
+	sizes := db.state.TableSizes()
+	stats := Stats{
+		Amplification:          db.Amplification(),
+		MaxReadAmplification:   len(core.L0) + len(core.Compacted),
+		WALBytes:               sizes.WALBytes + sizes.ImmWALBytes,
+		ImmutableWALs:          sizes.ImmWALs,
+		MemtableBytes:          sizes.MemtableBytes,
+		ImmutableMemtables:     sizes.ImmMemtables,
+		ImmutableMemtableBytes: sizes.ImmMemtableBytes,
+	}
+	levelStats := func(ssts []sstable.Handle) LevelStats {
+		level := LevelStats{SSTs: len(ssts)}
+		for _, sst := range ssts {
+			level.Bytes += sst.Info.EncodedSize()
+		}
+		return level
+	}
+
+	if db.manifest != nil {
+		stats.ManifestConflicts = db.manifest.Conflicts()
+	}
+
+	stats.L0 = levelStats(core.L0)
+	stats.Live = stats.L0
+	for _, sr := range core.Compacted {
+		level := levelStats(sr.SSTList)
+		stats.SortedRuns = append(stats.SortedRuns, SortedRunStats{ID: sr.ID, LevelStats: level})
+		stats.Live.SSTs += level.SSTs
+		stats.Live.Bytes += level.Bytes
+	}
+	stats.PendingCompactionBytes = stats.L0.Bytes
+	if n := len(stats.SortedRuns); n > 0 && stats.SortedRuns[n-1].Bytes > 0 {
+		stats.SpaceAmplification = float64(stats.Live.Bytes) / float64(stats.SortedRuns[n-1].Bytes)
+	}
+
+	// The IDs of the immutable WALs are assigned before they are written to object storage
+	written := int64(core.NextWalSstID.Load()) - 1 - int64(stats.ImmutableWALs)
+	stats.UncompactedWALSSTs = uint64(max(written-int64(core.LastCompactedWalSSTID.Load()), 0))
+	return stats
+}
+
+// properties are the statistics returned by DB.Property, by name
+var properties = map[string]func(Stats) any{
+	"slatedb.l0-ssts":                  func(s Stats) any { return s.L0.SSTs },
+	"slatedb.l0-bytes":                 func(s Stats) any { return s.L0.Bytes },
+	"slatedb.sorted-runs":              func(s Stats) any { return len(s.SortedRuns) },
+	"slatedb.live-ssts":                func(s Stats) any { return s.Live.SSTs },
+	"slatedb.live-sst-bytes":           func(s Stats) any { return s.Live.Bytes },
+	"slatedb.wal-bytes":                func(s Stats) any { return s.WALBytes },
+	"slatedb.immutable-wals":           func(s Stats) any { return s.ImmutableWALs },
+	"slatedb.uncompacted-wal-ssts":     func(s Stats) any { return s.UncompactedWALSSTs },
+	"slatedb.memtable-bytes":           func(s Stats) any { return s.MemtableBytes },
+	"slatedb.immutable-memtables":      func(s Stats) any { return s.ImmutableMemtables },
+	"slatedb.immutable-memtable-bytes": func(s Stats) any { return s.ImmutableMemtableBytes },
+	"slatedb.pending-compaction-bytes": func(s Stats) any { return s.PendingCompactionBytes },
+	"slatedb.write-amplification":      func(s Stats) any { return s.Amplification.Write },
+	"slatedb.read-amplification":       func(s Stats) any { return s.Amplification.Read },
+	"slatedb.max-read-amplification":   func(s Stats) any { return s.MaxReadAmplification },
+	"slatedb.space-amplification":      func(s Stats) any { return s.SpaceAmplification },
+	"slatedb.manifest-conflicts":       func(s Stats) any { return s.ManifestConflicts },
+}
+
+// PropertyNames returns the names of the statistics returned by DB.Property, in order
+func PropertyNames() []string {
+	names := []string{"slatedb.stats"}
+	for name := range properties {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Property returns the statistic of DB.Stats named `name`, such as "slatedb.l0-ssts" or
+// "slatedb.pending-compaction-bytes", formatted as a string, and false if there is no statistic
+// of that name. "slatedb.stats" returns every statistic encoded as JSON. See PropertyNames
+func (db *DB) Property(name string) (string, bool) {
+	if name == "slatedb.stats" {
+		data, err := json.Marshal(db.Stats())
+		if err != nil {
+			return "", false
+		}
+		return string(data), true
+	}
+	property, ok := properties[name]
+	if !ok {
+		return "", false
+	}
+	return fmt.Sprint(property(db.Stats())), true
+}
+
+// ------------------------------------------------
+// Write Stage Timings
+// ------------------------------------------------
+
+// StageTimings reports the time spent in each stage of a kind of write path work. The time
+// spent in a stage is wall time, which includes waiting on object storage and the scheduler.
+type StageTimings struct {
+	// Read is the time spent reading and merging the input SSTs of compactions
+	Read time.Duration
+
+	// Encode is the time spent encoding entries into blocks, and building the index and filter of SSTs
+	Encode time.Duration
+
+	// Compress is the time spent compressing and checksumming blocks, indexes and filters
+	Compress time.Duration
+
+	// Upload is the time spent writing SSTs to object storage
+	Upload time.Duration
+
+	// Manifest is the time spent writing the manifest which records the SSTs
+	Manifest time.Duration
+}
+
+// WriteStageTimings reports the time spent in each stage of the flushes and compactions of the
+// database since it was opened. See config.DBOptions.ProfileLabels to attribute the samples of
+// a CPU profile to the same stages.
+type WriteStageTimings struct {
+	WALFlush      StageTimings
+	MemtableFlush StageTimings
+
+	// Compaction is only reported for the compactions run by the compactor of this process
+	Compaction StageTimings
+}
+
+// WriteStageTimings returns the time spent in each stage of the flushes and compactions of the database
+func (db *DB) WriteStageTimings() WriteStageTimings {
+	timings := func(duration func(profile.Stage) time.Duration) StageTimings {
+		return StageTimings{
+			Read:     duration(profile.StageRead),
+			Encode:   duration(profile.StageEncode),
+			Compress: duration(profile.StageCompress),
+			Upload:   duration(profile.StageUpload),
+			Manifest: duration(profile.StageManifest),
+		}
+	}
+	result := WriteStageTimings{
+		WALFlush: timings(func(s profile.Stage) time.Duration {
+			return db.profiler.Duration(profile.WorkWALFlush, s)
+		}),
+		MemtableFlush: timings(func(s profile.Stage) time.Duration {
+			return db.profiler.Duration(profile.WorkMemtableFlush, s)
+		}),
+	}
+	if db.compactor != nil {
+		result.Compaction = timings(db.compactor.StageDuration)
+	}
+	return result
+}
+
+// ------------------------------------------------
+// SST Access Statistics
+// ------------------------------------------------
+
+// sstAccessStatsName is the name of the stats object the SST access statistics are persisted to
+const sstAccessStatsName = "sst_access.json"
+
+// SSTAccess reports how often an SSTable has been read to serve calls to Get. SSTables
+// which are rarely or never read are candidates for cheaper (colder) storage classes.
+type SSTAccess struct {
+	// ID is the ID of the SSTable
+	ID string
+
+	// L0 is true if the SSTable is in L0, otherwise the SSTable belongs to the sorted run SortedRunID
+	L0          bool
+	SortedRunID uint32
+
+	// Reads is the number of times the SSTable was probed to serve a Get
+	Reads uint64
+
+	// LastAccess is the time the SSTable was last probed, or the zero time if it was never read
+	LastAccess time.Time
+}
+
+// sstAccessRecord is the persisted form of the access statistics of a single SSTable
+type sstAccessRecord struct {
+	Reads      uint64 `json:"reads"`
+	LastAccess int64  `json:"last_access"`
+}
+
+// sstAccessStats tracks the number of reads and the last access time of each SSTable.
+// The statistics survive restarts as they are periodically persisted by the memtable flush task.
+type sstAccessStats struct {
+	mu    sync.Mutex
+	ssts  map[string]sstAccessRecord
+	dirty bool
+}
+
+func newSSTAccessStats() *sstAccessStats {
+	return &sstAccessStats{ssts: make(map[string]sstAccessRecord)}
+}
+
+func (s *sstAccessStats) record(id sstable.ID, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rec := s.ssts[id.String()]
+	rec.Reads++
+	rec.LastAccess = now.UnixNano()
+	s.ssts[id.String()] = rec
+	s.dirty = true
+}
+
+func (s *sstAccessStats) get(id sstable.ID) (sstAccessRecord, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rec, ok := s.ssts[id.String()]
+	return rec, ok
+}
+
+// encode returns the encoded statistics of the SSTables in `live` and false if nothing
+// changed since the last call. Statistics of SSTables no longer in `live` are discarded.
+func (s *sstAccessStats) encode(live map[string]struct{}) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id := range s.ssts {
+		if _, ok := live[id]; !ok {
+			delete(s.ssts, id)
+		}
+	}
+	if !s.dirty {
+		return nil, false, nil
+	}
+	data, err := json.Marshal(s.ssts)
+	if err != nil {
+		return nil, false, err
+	}
+	s.dirty = false
+	return data, true, nil
+}
+
+func (s *sstAccessStats) decode(data []byte) error {
+	ssts := make(map[string]sstAccessRecord)
+	if err := json.Unmarshal(data, &ssts); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ssts = ssts
+	return nil
+}
+
+// SSTAccessStats returns the access statistics of every SSTable in L0 and the
+// compacted sorted runs, sorted by the time of last access (least recent first).
+func (db *DB) SSTAccessStats() []SSTAccess {
+	core := db.state.Snapshot().Core
+	result := make([]SSTAccess, 0, len(core.L0))
+	add := func(sst sstable.Handle, l0 bool, srID uint32) {
+		access := SSTAccess{ID: sst.Id.String(), L0: l0, SortedRunID: srID}
+		if rec, ok := db.sstAccess.get(sst.Id); ok {
+			access.Reads = rec.Reads
+			access.LastAccess = time.Unix(0, rec.LastAccess)
+		}
+		result = append(result, access)
+	}
+
+	for _, sst := range core.L0 {
+		add(sst, true, 0)
+	}
+	for _, sr := range core.Compacted {
+		for _, sst := range sr.SSTList {
+			add(sst, false, sr.ID)
+		}
+	}
+
+	slices.SortStableFunc(result, func(a, b SSTAccess) int {
+		if c := a.LastAccess.Compare(b.LastAccess); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	return result
+}
+
+// loadSSTAccessStats loads the SST access statistics persisted by a previous DB instance
+func (db *DB) loadSSTAccessStats() {
+	data, err := db.statsStore.Read(sstAccessStatsName)
+	if err != nil {
+		db.opts.Log.Warn("failed to read SST access stats", "error", err)
+		return
+	}
+	if b, ok := data.Get(); ok {
+		if err := db.sstAccess.decode(b); err != nil {
+			// Statistics are advisory; start over rather than failing to open
+			db.opts.Log.Warn("failed to decode SST access stats", "error", err)
+		}
+	}
+}
+
+// persistSSTAccessStats writes the SST access statistics to object storage if they changed
+func (db *DB) persistSSTAccessStats() error {
+	core := db.state.Snapshot().Core
+	live := make(map[string]struct{})
+	for _, sst := range core.L0 {
+		live[sst.Id.String()] = struct{}{}
+	}
+	for _, sr := range core.Compacted {
+		for _, sst := range sr.SSTList {
+			live[sst.Id.String()] = struct{}{}
+		}
+	}
+
+	data, changed, err := db.sstAccess.encode(live)
+	if err != nil || !changed {
+		return err
+	}
+	return db.statsStore.Write(sstAccessStatsName, data)
+}
@@ -0,0 +1,168 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/maypok86/otter"
+
+	"github.com/slatedb/slatedb-go/internal/assert"
+	"github.com/slatedb/slatedb-go/internal/sstable"
+	"github.com/slatedb/slatedb-go/internal/sstable/block"
+	"github.com/slatedb/slatedb-go/slatedb/common"
+)
+
+// ------------------------------------------------
+// BlockCache
+// ------------------------------------------------
+
+// BlockCacheStats reports the effectiveness of the block cache of a TableStore
+type BlockCacheStats struct {
+	// Hits is the number of blocks read from the cache
+	Hits uint64
+
+	// Misses is the number of blocks which were not cached and were read from object storage
+	Misses uint64
+
+	// Size is the number of bytes of blocks held by the cache
+	Size uint64
+}
+
+type blockKey struct {
+	sstID sstable.ID
+	index uint64
+}
+
+// cachedBlock holds either the decoded block, or the encoded (compressed) block if the
+// cache was created to hold compressed blocks
+type cachedBlock struct {
+	decoded block.Block
+	encoded []byte
+}
+
+func (b cachedBlock) size() uint32 {
+	if b.encoded != nil {
+		return uint32(min(len(b.encoded), math.MaxUint32))
+	}
+	size := len(b.decoded.FirstKey) + len(b.decoded.Data) + len(b.decoded.Offsets)*2
+	return uint32(min(size, math.MaxUint32))
+}
+
+// blockCache caches the data blocks of SSTs, such that a block which is read repeatedly is
+// fetched from object storage and decoded once.
+type blockCache struct {
+	cache otter.Cache[blockKey, cachedBlock]
+
+	// compressed is true if blocks are cached as they are stored, which fits more blocks in the
+	// cache at the cost of decompressing a block each time it is read from the cache
+	compressed bool
+}
+
+func newBlockCache(sizeBytes uint64, compressed bool) *blockCache {
+	cache, err := otter.MustBuilder[blockKey, cachedBlock](int(min(sizeBytes, math.MaxInt))).
+		Cost(func(_ blockKey, b cachedBlock) uint32 { return b.size() }).
+		CollectStats().
+		Build()
+	assert.True(err == nil, "")
+	return &blockCache{cache: cache, compressed: compressed}
+}
+
+// readBlocks returns the blocks within blocksRange, reading the blocks which are not cached
+// from `obj` with a single range request and adding them to the cache
+func (c *blockCache) readBlocks(
+	ctx context.Context,
+	handle *sstable.Handle,
+	blocksRange common.Range,
+	index *sstable.Index,
+	obj ReadOnlyObject,
+) ([]block.Block, error) {
+	if blocksRange.Start >= blocksRange.End {
+		return nil, fmt.Errorf("block start '%d' range cannot be greater than end range '%d'",
+			blocksRange.Start, blocksRange.End)
+	}
+
+	blocks := make([]block.Block, blocksRange.End-blocksRange.Start)
+	found := make([]bool, len(blocks))
+	// The range [missStart, missEnd) holds every block which is not cached
+	missStart, missEnd := blocksRange.End, blocksRange.Start
+	for i := blocksRange.Start; i < blocksRange.End; i++ {
+		cached, ok := c.cache.Get(blockKey{sstID: handle.Id, index: i})
+		if !ok {
+			missStart = min(missStart, i)
+			missEnd = i + 1
+			continue
+		}
+		b, err := c.decode(cached, handle, i)
+		if err != nil {
+			return nil, err
+		}
+		blocks[i-blocksRange.Start] = b
+		found[i-blocksRange.Start] = true
+	}
+	if missStart >= missEnd {
+		return blocks, nil
+	}
+
+	missRange := common.Range{Start: missStart, End: missEnd}
+	encodedBlocks, err := sstable.ReadEncodedBlocks(ctx, handle.Info, index, missRange, obj)
+	if err != nil {
+		return nil, err
+	}
+	traceBlockRead(ctx, handle.Id, missStart, encodedBlocks)
+	for j, encoded := range encodedBlocks {
+		i := missStart + uint64(j)
+		if found[i-blocksRange.Start] {
+			continue
+		}
+		var b block.Block
+		if err := decodeBlock(&b, encoded, handle, i); err != nil {
+			return nil, err
+		}
+		blocks[i-blocksRange.Start] = b
+		if c.compressed {
+			c.cache.Set(blockKey{sstID: handle.Id, index: i}, cachedBlock{encoded: encoded})
+		} else {
+			c.cache.Set(blockKey{sstID: handle.Id, index: i}, cachedBlock{decoded: b})
+		}
+	}
+	return blocks, nil
+}
+
+func (c *blockCache) decode(cached cachedBlock, handle *sstable.Handle, index uint64) (block.Block, error) {
+	if cached.encoded == nil {
+		return cached.decoded, nil
+	}
+	var b block.Block
+	err := decodeBlock(&b, cached.encoded, handle, index)
+	return b, err
+}
+
+// trim evicts blocks until the cache holds at most floorBytes of blocks
+func (c *blockCache) trim(floorBytes uint64) {
+	size := c.stats().Size
+	if size <= floorBytes {
+		return
+	}
+	c.cache.DeleteByFunc(func(_ blockKey, b cachedBlock) bool {
+		if size <= floorBytes {
+			return false
+		}
+		size -= min(uint64(b.size()), size)
+		return true
+	})
+}
+
+func (c *blockCache) stats() BlockCacheStats {
+	stats := c.cache.Stats()
+	var size uint64
+	c.cache.Range(func(_ blockKey, b cachedBlock) bool {
+		size += uint64(b.size())
+		return true
+	})
+	return BlockCacheStats{
+		Hits:   uint64(stats.Hits()),
+		Misses: uint64(stats.Misses()),
+		Size:   size,
+	}
+}
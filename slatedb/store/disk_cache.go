@@ -0,0 +1,266 @@
+package store
+
+import (
+	"container/list"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"sync"
+
+	"github.com/slatedb/slatedb-go/slatedb/common"
+)
+
+// ------------------------------------------------
+// DiskCache
+// ------------------------------------------------
+
+// diskCacheChunkSize is the size of the byte ranges of an object which are cached. Reads are
+// rounded out to whole chunks, such that overlapping reads of an object share cached chunks.
+const diskCacheChunkSize = 64 * 1024
+
+// diskCacheSizeFile is the name of the file which holds the length of a cached object
+const diskCacheSizeFile = "size"
+
+// DiskCache persists byte ranges of immutable objects, such as SSTs, to a local directory such
+// that reads of the ranges are served from disk, including after a restart. Each object is cached
+// in a directory of fixed size chunks, and the least recently used chunks are evicted once the
+// cached chunks exceed the size budget.
+type DiskCache struct {
+	dir       string
+	sizeBytes uint64
+
+	mu      sync.Mutex
+	used    uint64
+	lru     *list.List
+	entries map[string]*list.Element
+}
+
+type diskCacheEntry struct {
+	path string
+	size uint64
+}
+
+// NewDiskCache opens the cache in `dir`, creating the directory if it does not exist. Chunks
+// cached by a previous DiskCache in `dir` are retained, such that a restarted process reads them
+// from disk. If the cached chunks exceed sizeBytes, chunks are evicted until they fit.
+func NewDiskCache(dir string, sizeBytes uint64) (*DiskCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("while creating disk cache directory: %w", err)
+	}
+	c := &DiskCache{
+		dir:       dir,
+		sizeBytes: sizeBytes,
+		lru:       list.New(),
+		entries:   make(map[string]*list.Element),
+	}
+
+	type chunk struct {
+		path string
+		info fs.FileInfo
+	}
+	var chunks []chunk
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || d.Name() == diskCacheSizeFile {
+			return err
+		}
+		if filepath.Ext(path) == ".tmp" {
+			// A chunk which was being written when the process stopped
+			return os.Remove(path)
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		chunks = append(chunks, chunk{path: path, info: info})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("while loading disk cache: %w", err)
+	}
+
+	// The most recently modified chunks are considered the most recently used
+	slices.SortFunc(chunks, func(a, b chunk) int {
+		return a.info.ModTime().Compare(b.info.ModTime())
+	})
+	for _, ch := range chunks {
+		c.add(ch.path, uint64(ch.info.Size()))
+	}
+	c.evict()
+	return c, nil
+}
+
+// Object returns a blob which reads `obj` through the cache. `key` identifies the object in
+// the cache, and the object must never change once it is written.
+func (c *DiskCache) Object(key string, obj ReadOnlyObject) ReadOnlyObject {
+	return cachedObject{cache: c, dir: filepath.Join(c.dir, url.PathEscape(key)), obj: obj}
+}
+
+// Size returns the number of bytes of chunks held by the cache
+func (c *DiskCache) Size() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.used
+}
+
+func (c *DiskCache) read(path string) ([]byte, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	if el, ok := c.entries[path]; ok {
+		c.lru.MoveToFront(el)
+	}
+	c.mu.Unlock()
+	return data, true
+}
+
+// write caches `data` at `path`. Failures are ignored, as the data is always available from
+// object storage.
+func (c *DiskCache) write(path string, data []byte) {
+	if uint64(len(data)) > c.sizeBytes {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		_ = os.Remove(tmp)
+		return
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return
+	}
+	c.add(path, uint64(len(data)))
+	c.evict()
+}
+
+func (c *DiskCache) add(path string, size uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[path]; ok {
+		c.lru.MoveToFront(el)
+		return
+	}
+	c.entries[path] = c.lru.PushFront(&diskCacheEntry{path: path, size: size})
+	c.used += size
+}
+
+// evict removes the least recently used chunks until the cache fits within its size budget
+func (c *DiskCache) evict() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for c.used > c.sizeBytes {
+		el := c.lru.Back()
+		entry := c.lru.Remove(el).(*diskCacheEntry)
+		delete(c.entries, entry.path)
+		c.used -= entry.size
+		_ = os.Remove(entry.path)
+	}
+}
+
+// ------------------------------------------------
+// cachedObject
+// ------------------------------------------------
+
+// cachedObject is a ReadOnlyObject which reads the chunks of an object from the DiskCache,
+// and fetches the chunks which are not cached from the object with a single range request.
+type cachedObject struct {
+	cache *DiskCache
+	dir   string
+	obj   ReadOnlyObject
+}
+
+func (o cachedObject) Len(ctx context.Context) (int, error) {
+	path := filepath.Join(o.dir, diskCacheSizeFile)
+	if data, err := os.ReadFile(path); err == nil && len(data) == 8 {
+		return int(binary.BigEndian.Uint64(data)), nil
+	}
+	size, err := o.obj.Len(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if err := os.MkdirAll(o.dir, 0o755); err == nil {
+		_ = os.WriteFile(path, binary.BigEndian.AppendUint64(nil, uint64(size)), 0o644)
+	}
+	return size, nil
+}
+
+func (o cachedObject) ReadRange(ctx context.Context, rng common.Range) ([]byte, error) {
+	if rng.Start >= rng.End {
+		return []byte{}, nil
+	}
+	size, err := o.Len(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if rng.End > uint64(size) {
+		return nil, fmt.Errorf("range [%d:%d] is beyond the end of the object '%d'", rng.Start, rng.End, size)
+	}
+
+	first, last := rng.Start/diskCacheChunkSize, (rng.End-1)/diskCacheChunkSize
+	chunks := make([][]byte, last-first+1)
+	// The range [missFirst, missLast] holds every chunk which is not cached
+	missFirst, missLast := last+1, first
+	for i := first; i <= last; i++ {
+		data, ok := o.cache.read(o.chunkPath(i))
+		if !ok || uint64(len(data)) != o.chunkLen(i, uint64(size)) {
+			missFirst = min(missFirst, i)
+			missLast = i
+			continue
+		}
+		chunks[i-first] = data
+	}
+
+	if missFirst <= missLast {
+		start := missFirst * diskCacheChunkSize
+		end := min((missLast+1)*diskCacheChunkSize, uint64(size))
+		data, err := o.obj.ReadRange(ctx, common.Range{Start: start, End: end})
+		if err != nil {
+			return nil, err
+		}
+		if uint64(len(data)) != end-start {
+			return nil, fmt.Errorf("read %d bytes of object range [%d:%d]", len(data), start, end)
+		}
+		for i := missFirst; i <= missLast; i++ {
+			offset := (i - missFirst) * diskCacheChunkSize
+			chunk := data[offset : offset+o.chunkLen(i, uint64(size))]
+			if chunks[i-first] == nil {
+				chunks[i-first] = chunk
+				o.cache.write(o.chunkPath(i), chunk)
+			}
+		}
+	}
+
+	result := make([]byte, 0, rng.End-rng.Start)
+	for i, chunk := range chunks {
+		chunkStart := (first + uint64(i)) * diskCacheChunkSize
+		lo := max(rng.Start, chunkStart) - chunkStart
+		hi := min(rng.End, chunkStart+uint64(len(chunk))) - chunkStart
+		result = append(result, chunk[lo:hi]...)
+	}
+	return result, nil
+}
+
+// Read returns the whole object. Whole objects are only read to copy or verify them, so
+// they are read from the object rather than the cache.
+func (o cachedObject) Read(ctx context.Context) ([]byte, error) {
+	return o.obj.Read(ctx)
+}
+
+func (o cachedObject) chunkPath(i uint64) string {
+	return filepath.Join(o.dir, strconv.FormatUint(i, 10))
+}
+
+// chunkLen returns the length of chunk `i` of an object of length `size`
+func (o cachedObject) chunkLen(i uint64, size uint64) uint64 {
+	return min(diskCacheChunkSize, size-i*diskCacheChunkSize)
+}
@@ -0,0 +1,241 @@
+package store
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/slatedb/slatedb-go/slatedb/common"
+)
+
+const (
+	// hedgeLatencySamples is the number of the most recent read latencies from which the hedge
+	// delay is computed
+	hedgeLatencySamples = 1000
+
+	// hedgeMinSamples is the number of reads which must be observed before the hedge delay is
+	// computed from their latency. Until then, reads are hedged after the minimum delay, or are
+	// not hedged if the minimum delay is zero.
+	hedgeMinSamples = 20
+
+	// hedgeRecomputeEvery is the number of reads after which the hedge delay is recomputed
+	hedgeRecomputeEvery = 50
+)
+
+// HedgeStats reports the reads hedged by a TableStore, see SetHedgedReads
+type HedgeStats struct {
+	// Reads is the number of range reads of SSTs from object storage
+	Reads uint64
+
+	// Hedged is the number of reads for which a second request was sent, as the first did
+	// not complete within the hedge delay
+	Hedged uint64
+
+	// HedgeWins is the number of hedged reads which were served by the second request
+	HedgeWins uint64
+
+	// Delay is the current delay after which a read is hedged
+	Delay time.Duration
+}
+
+// hedgedReads sends a second request for a range read of an object when the first request has
+// not completed after a percentile of the latency of recent reads, and limits the number of
+// reads in flight. It is shared by the TableStore clones returned by PointReads.
+type hedgedReads struct {
+	percentile float64
+	minDelay   time.Duration
+
+	// inFlight holds a token for each read in flight, or is nil if reads are not limited
+	inFlight chan struct{}
+
+	mu        sync.Mutex
+	latencies []time.Duration
+	next      int
+	observed  int
+	delay     time.Duration
+
+	reads     atomic.Uint64
+	hedged    atomic.Uint64
+	hedgeWins atomic.Uint64
+}
+
+func newHedgedReads(percentile float64, minDelay time.Duration, maxInFlight int) *hedgedReads {
+	h := &hedgedReads{
+		percentile: percentile,
+		minDelay:   minDelay,
+		latencies:  make([]time.Duration, 0, hedgeLatencySamples),
+	}
+	if percentile > 0 {
+		h.delay = minDelay
+	}
+	if maxInFlight > 0 {
+		h.inFlight = make(chan struct{}, maxInFlight)
+	}
+	return h
+}
+
+// object returns `obj`, whose range reads are hedged and limited
+func (h *hedgedReads) object(obj ReadOnlyObject) ReadOnlyObject {
+	return hedgedObject{obj: obj, hedge: h}
+}
+
+func (h *hedgedReads) stats() HedgeStats {
+	h.mu.Lock()
+	delay := h.delay
+	h.mu.Unlock()
+	return HedgeStats{
+		Reads:     h.reads.Load(),
+		Hedged:    h.hedged.Load(),
+		HedgeWins: h.hedgeWins.Load(),
+		Delay:     delay,
+	}
+}
+
+// acquire waits for a read to be allowed in flight. The returned func must be called once the
+// read completes.
+func (h *hedgedReads) acquire(ctx context.Context) (func(), error) {
+	if h.inFlight == nil {
+		return func() {}, nil
+	}
+	select {
+	case h.inFlight <- struct{}{}:
+		return func() { <-h.inFlight }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// tryAcquire is like acquire, but returns false rather than waiting if the limit of reads in
+// flight has been reached, such that hedges never wait for or delay other reads
+func (h *hedgedReads) tryAcquire() (func(), bool) {
+	if h.inFlight == nil {
+		return func() {}, true
+	}
+	select {
+	case h.inFlight <- struct{}{}:
+		return func() { <-h.inFlight }, true
+	default:
+		return nil, false
+	}
+}
+
+// hedgeDelay returns the delay after which a read is hedged, or zero if reads are not hedged
+func (h *hedgedReads) hedgeDelay() time.Duration {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.delay
+}
+
+// observe records the latency of a read. The latency of a read served by its hedge is the time
+// until the hedge completed, which is less than the latency of the first request.
+func (h *hedgedReads) observe(latency time.Duration) {
+	if h.percentile <= 0 {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.latencies) < hedgeLatencySamples {
+		h.latencies = append(h.latencies, latency)
+	} else {
+		h.latencies[h.next] = latency
+		h.next = (h.next + 1) % hedgeLatencySamples
+	}
+	h.observed++
+	if len(h.latencies) < hedgeMinSamples {
+		return
+	}
+	if h.observed != hedgeMinSamples && h.observed%hedgeRecomputeEvery != 0 {
+		return
+	}
+	sorted := slices.Clone(h.latencies)
+	slices.Sort(sorted)
+	i := min(int(float64(len(sorted))*h.percentile), len(sorted)-1)
+	h.delay = max(sorted[i], h.minDelay)
+}
+
+type hedgeResult struct {
+	data   []byte
+	err    error
+	hedged bool
+}
+
+// hedgedObject is a ReadOnlyObject whose range reads are hedged, see hedgedReads
+type hedgedObject struct {
+	obj   ReadOnlyObject
+	hedge *hedgedReads
+}
+
+func (o hedgedObject) Len(ctx context.Context) (int, error) {
+	return o.obj.Len(ctx)
+}
+
+func (o hedgedObject) Read(ctx context.Context) ([]byte, error) {
+	release, err := o.hedge.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return o.obj.Read(ctx)
+}
+
+func (o hedgedObject) ReadRange(ctx context.Context, rng common.Range) ([]byte, error) {
+	release, err := o.hedge.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	o.hedge.reads.Add(1)
+	delay := o.hedge.hedgeDelay()
+	if delay <= 0 {
+		defer release()
+		start := time.Now()
+		data, err := o.obj.ReadRange(ctx, rng)
+		if err == nil {
+			o.hedge.observe(time.Since(start))
+		}
+		return data, err
+	}
+
+	// The request which completes last is canceled once the read returns
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	results := make(chan hedgeResult, 2)
+	read := func(hedged bool, release func()) {
+		defer release()
+		data, err := o.obj.ReadRange(ctx, rng)
+		results <- hedgeResult{data: data, err: err, hedged: hedged}
+	}
+	start := time.Now()
+	go read(false, release)
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	pending := 1
+	var firstErr error
+	for {
+		select {
+		case <-timer.C:
+			if hedgeRelease, ok := o.hedge.tryAcquire(); ok {
+				o.hedge.hedged.Add(1)
+				pending++
+				go read(true, hedgeRelease)
+			}
+		case r := <-results:
+			pending--
+			if r.err == nil {
+				if r.hedged {
+					o.hedge.hedgeWins.Add(1)
+				}
+				o.hedge.observe(time.Since(start))
+				return r.data, nil
+			}
+			if firstErr == nil || !r.hedged {
+				firstErr = r.err
+			}
+			if pending == 0 {
+				return nil, firstErr
+			}
+		}
+	}
+}
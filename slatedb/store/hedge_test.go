@@ -0,0 +1,89 @@
+package store
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slatedb/slatedb-go/slatedb/common"
+)
+
+// stallingObject is a ReadOnlyObject whose first range read stalls until it is canceled
+type stallingObject struct {
+	BytesObject
+	reads *atomic.Int32
+}
+
+func (o stallingObject) ReadRange(ctx context.Context, rng common.Range) ([]byte, error) {
+	if o.reads.Add(1) == 1 {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return o.BytesObject.ReadRange(ctx, rng)
+}
+
+func TestHedgedReads(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	data := []byte("0123456789")
+
+	t.Run("HedgeWins", func(t *testing.T) {
+		hedge := newHedgedReads(0.9, 10*time.Millisecond, 0)
+		obj := hedge.object(stallingObject{BytesObject: NewBytesObject(data), reads: &atomic.Int32{}})
+
+		read, err := obj.ReadRange(ctx, common.Range{Start: 2, End: 5})
+		require.NoError(t, err)
+		assert.Equal(t, []byte("234"), read)
+		stats := hedge.stats()
+		assert.Equal(t, uint64(1), stats.Reads)
+		assert.Equal(t, uint64(1), stats.Hedged)
+		assert.Equal(t, uint64(1), stats.HedgeWins)
+	})
+
+	t.Run("NoHedgeAtLimit", func(t *testing.T) {
+		hedge := newHedgedReads(0.9, 10*time.Millisecond, 1)
+		obj := hedge.object(stallingObject{BytesObject: NewBytesObject(data), reads: &atomic.Int32{}})
+
+		// The stalled read holds the only slot, so it is not hedged and fails once canceled
+		readCtx, readCancel := context.WithTimeout(ctx, 100*time.Millisecond)
+		defer readCancel()
+		_, err := obj.ReadRange(readCtx, common.Range{Start: 2, End: 5})
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Equal(t, uint64(0), hedge.stats().Hedged)
+
+		// Once the slot is released, reads are made
+		read, err := obj.ReadRange(ctx, common.Range{Start: 0, End: 1})
+		require.NoError(t, err)
+		assert.Equal(t, []byte("0"), read)
+	})
+
+	t.Run("InFlightLimit", func(t *testing.T) {
+		hedge := newHedgedReads(0, 0, 1)
+		obj := hedge.object(NewBytesObject(data))
+		release, err := hedge.acquire(ctx)
+		require.NoError(t, err)
+
+		readCtx, readCancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer readCancel()
+		_, err = obj.ReadRange(readCtx, common.Range{Start: 0, End: 1})
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+		release()
+		_, err = obj.ReadRange(ctx, common.Range{Start: 0, End: 1})
+		require.NoError(t, err)
+		assert.Equal(t, time.Duration(0), hedge.stats().Delay)
+	})
+
+	t.Run("DelayIsPercentile", func(t *testing.T) {
+		hedge := newHedgedReads(0.9, time.Millisecond, 0)
+		assert.Equal(t, time.Millisecond, hedge.hedgeDelay())
+		for i := 1; i <= 100; i++ {
+			hedge.observe(time.Duration(i) * time.Millisecond)
+		}
+		assert.Equal(t, 91*time.Millisecond, hedge.hedgeDelay())
+	})
+}
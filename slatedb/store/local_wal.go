@@ -0,0 +1,413 @@
+package store
+
+import (
+	"cmp"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/slatedb/slatedb-go/internal"
+	"github.com/slatedb/slatedb-go/internal/sstable/block"
+	"github.com/slatedb/slatedb-go/internal/types"
+	"github.com/slatedb/slatedb-go/slatedb/common"
+)
+
+// ------------------------------------------------
+// LocalWAL
+// ------------------------------------------------
+
+// localWALSegmentExt is the extension of the segment files of a LocalWAL
+const localWALSegmentExt = ".wal"
+
+// localWALHeaderSize is the size of the length and checksum which precede each record
+const localWALHeaderSize = 2 * common.SizeOfUint32
+
+// LocalWAL appends the writes of the WAL to files on local disk, such that a write is durable
+// once it is synced to local disk rather than once the WAL SST holding it is written to object
+// storage. The writes of each WAL SST are appended to a segment named after the epoch of the
+// writer and the ID of the SST, which is removed once the SST is written to object storage.
+//
+// Each write is a record of the segment, which holds the entries and range tombstones of the
+// write such that a write is replayed entirely or not at all:
+//
+//	|---------------------------------------------------------------------------|
+//	|  uint32  |  uint32  |  uvarint  |  []entry  |  uvarint    |  []tombstone  |
+//	|----------|----------|-----------|-----------|-------------|---------------|
+//	|  length  |  crc32   |  entries  |  entries  |  tombstones |  tombstones   |
+//	|---------------------------------------------------------------------------|
+//
+// where each entry is a uvarint length followed by the entry encoded by block.EncodeInlineRow, and
+// each tombstone is the uvarint length prefixed start and end of the range followed by the uint64
+// sequence number.
+type LocalWAL struct {
+	dir string
+
+	// epoch is the epoch of the writer which appends to the WAL
+	epoch uint64
+
+	// mu guards the open segments, the segments appended to since the last sync, and err, the
+	// first error of an append, which is returned by every sync that follows it
+	mu       sync.Mutex
+	segments map[uint64]*os.File
+	unsynced map[uint64]struct{}
+	created  bool
+	appended uint64
+	err      error
+
+	// syncMu serializes the syncs and the removal of segments, and synced is the number of
+	// records appended before the last sync
+	syncMu sync.Mutex
+	synced uint64
+}
+
+// LocalWALRecord is a write replayed from a LocalWAL
+type LocalWALRecord struct {
+	Entries    []types.RowEntry
+	Tombstones []types.RangeTombstone
+}
+
+// OpenLocalWAL opens the local WAL in `dir` for the writer of `epoch`, creating the directory if it
+// does not exist. The segments written by a previous LocalWAL in `dir` are retained until they are
+// replayed.
+func OpenLocalWAL(dir string, epoch uint64) (*LocalWAL, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("while creating local WAL directory: %w", err)
+	}
+	return &LocalWAL{
+		dir:      dir,
+		epoch:    epoch,
+		segments: make(map[uint64]*os.File),
+		unsynced: make(map[uint64]struct{}),
+	}, nil
+}
+
+// Replay returns the records of the segments written by the previous writer, whose epoch is the one
+// before the epoch of the LocalWAL, in the order they were appended. A segment whose last record was
+// not completely written before the process stopped is replayed up to that record.
+//
+// The segments of an older epoch were written by a writer which was fenced by another writer, whose
+// writes would be overwritten by the replay, and are discarded.
+func (w *LocalWAL) Replay(log *slog.Logger) ([]LocalWALRecord, error) {
+	segments, err := w.listSegments()
+	if err != nil {
+		return nil, err
+	}
+	var records []LocalWALRecord
+	for _, s := range segments {
+		if s.epoch+1 != w.epoch {
+			log.Warn("discarding local WAL segment of a fenced writer", "segment", s.walID,
+				"epoch", s.epoch, "writer_epoch", w.epoch)
+			continue
+		}
+		data, err := os.ReadFile(w.segmentPath(s.epoch, s.walID))
+		if err != nil {
+			return nil, fmt.Errorf("while reading local WAL segment %d: %w", s.walID, err)
+		}
+		for len(data) > 0 {
+			record, n, err := decodeLocalWALRecord(data)
+			if err != nil {
+				log.Warn("ignoring the end of local WAL segment", "segment", s.walID,
+					"bytes", len(data), "error", err)
+				break
+			}
+			records = append(records, record)
+			data = data[n:]
+		}
+	}
+	return records, nil
+}
+
+// Append appends a write to the segment of the WAL SST `walID` without syncing it, see Sync
+func (w *LocalWAL) Append(walID uint64, entries []types.RowEntry, tombstones []types.RangeTombstone) {
+	record := encodeLocalWALRecord(entries, tombstones)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.err != nil {
+		return
+	}
+	f, ok := w.segments[walID]
+	if !ok {
+		var err error
+		f, err = os.OpenFile(w.segmentPath(w.epoch, walID), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			w.err = fmt.Errorf("while creating local WAL segment %d: %w", walID, err)
+			return
+		}
+		w.segments[walID] = f
+		w.created = true
+	}
+	if _, err := f.Write(record); err != nil {
+		w.err = fmt.Errorf("while appending to local WAL segment %d: %w", walID, err)
+		return
+	}
+	w.unsynced[walID] = struct{}{}
+	w.appended++
+}
+
+// Sync syncs the records appended before it was called to disk. Concurrent calls share a sync.
+func (w *LocalWAL) Sync() error {
+	w.mu.Lock()
+	target := w.appended
+	w.mu.Unlock()
+
+	w.syncMu.Lock()
+	defer w.syncMu.Unlock()
+	if w.synced >= target {
+		return w.failed()
+	}
+
+	w.mu.Lock()
+	appended := w.appended
+	created := w.created
+	files := make([]*os.File, 0, len(w.unsynced))
+	for id := range w.unsynced {
+		files = append(files, w.segments[id])
+	}
+	clear(w.unsynced)
+	w.created = false
+	w.mu.Unlock()
+
+	for _, f := range files {
+		if err := f.Sync(); err != nil {
+			return w.fail(fmt.Errorf("while syncing local WAL segment: %w", err))
+		}
+	}
+	// A new segment is only durable once the directory which holds it is synced
+	if created {
+		if err := syncDir(w.dir); err != nil {
+			return w.fail(fmt.Errorf("while syncing local WAL directory: %w", err))
+		}
+	}
+	w.synced = appended
+	return w.failed()
+}
+
+// RemoveThrough removes the segments of the WAL SSTs up to and including `walID`, which have been
+// written to object storage, and the segments of older epochs up to `walID`
+func (w *LocalWAL) RemoveThrough(walID uint64) error {
+	w.syncMu.Lock()
+	defer w.syncMu.Unlock()
+
+	segments, err := w.listSegments()
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, s := range segments {
+		if s.walID > walID {
+			continue
+		}
+		if s.epoch == w.epoch {
+			w.mu.Lock()
+			if f, ok := w.segments[s.walID]; ok {
+				_ = f.Close()
+				delete(w.segments, s.walID)
+				delete(w.unsynced, s.walID)
+			}
+			w.mu.Unlock()
+		}
+		if err := os.Remove(w.segmentPath(s.epoch, s.walID)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close closes the open segments. The segments are retained, such that the writes they hold
+// which have not been written to object storage are replayed by the next LocalWAL in the directory.
+func (w *LocalWAL) Close() error {
+	w.syncMu.Lock()
+	defer w.syncMu.Unlock()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var errs []error
+	for id, f := range w.segments {
+		if err := f.Close(); err != nil {
+			errs = append(errs, err)
+		}
+		delete(w.segments, id)
+	}
+	return errors.Join(errs...)
+}
+
+func (w *LocalWAL) fail(err error) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.err == nil {
+		w.err = err
+	}
+	return w.err
+}
+
+func (w *LocalWAL) failed() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.err
+}
+
+func (w *LocalWAL) segmentPath(epoch uint64, walID uint64) string {
+	return filepath.Join(w.dir, fmt.Sprintf("%020d-%020d%s", epoch, walID, localWALSegmentExt))
+}
+
+// localWALSegment identifies a segment by the epoch of the writer which wrote it and the ID of its
+// WAL SST
+type localWALSegment struct {
+	epoch uint64
+	walID uint64
+}
+
+// listSegments returns the segments in the directory in the order of their epoch and WAL SST ID
+func (w *LocalWAL) listSegments() ([]localWALSegment, error) {
+	dirEntries, err := os.ReadDir(w.dir)
+	if err != nil {
+		return nil, fmt.Errorf("while listing local WAL segments: %w", err)
+	}
+	var segments []localWALSegment
+	for _, e := range dirEntries {
+		name, ok := strings.CutSuffix(e.Name(), localWALSegmentExt)
+		if !ok || e.IsDir() {
+			continue
+		}
+		epochStr, walIDStr, ok := strings.Cut(name, "-")
+		if !ok {
+			continue
+		}
+		epoch, err := strconv.ParseUint(epochStr, 10, 64)
+		if err != nil {
+			continue
+		}
+		walID, err := strconv.ParseUint(walIDStr, 10, 64)
+		if err != nil {
+			continue
+		}
+		segments = append(segments, localWALSegment{epoch: epoch, walID: walID})
+	}
+	slices.SortFunc(segments, func(a, b localWALSegment) int {
+		if c := cmp.Compare(a.epoch, b.epoch); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.walID, b.walID)
+	})
+	return segments, nil
+}
+
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
+
+func encodeLocalWALRecord(entries []types.RowEntry, tombstones []types.RangeTombstone) []byte {
+	buf := make([]byte, localWALHeaderSize)
+	buf = binary.AppendUvarint(buf, uint64(len(entries)))
+	for _, entry := range entries {
+		row := block.EncodeInlineRow(entry)
+		buf = binary.AppendUvarint(buf, uint64(len(row)))
+		buf = append(buf, row...)
+	}
+	buf = binary.AppendUvarint(buf, uint64(len(tombstones)))
+	for _, t := range tombstones {
+		buf = binary.AppendUvarint(buf, uint64(len(t.Start)))
+		buf = append(buf, t.Start...)
+		buf = binary.AppendUvarint(buf, uint64(len(t.End)))
+		buf = append(buf, t.End...)
+		buf = binary.BigEndian.AppendUint64(buf, t.Seq)
+	}
+	payload := buf[localWALHeaderSize:]
+	binary.BigEndian.PutUint32(buf, uint32(len(payload)))
+	binary.BigEndian.PutUint32(buf[common.SizeOfUint32:], crc32.ChecksumIEEE(payload))
+	return buf
+}
+
+// decodeLocalWALRecord decodes the record at the start of `data` and returns the number of bytes it occupies
+func decodeLocalWALRecord(data []byte) (LocalWALRecord, int, error) {
+	var record LocalWALRecord
+	if len(data) < localWALHeaderSize {
+		return record, 0, internal.Err("corrupt local WAL record; too short")
+	}
+	length := int(binary.BigEndian.Uint32(data))
+	if len(data)-localWALHeaderSize < length {
+		return record, 0, internal.Err("corrupt local WAL record; length %d exceeds segment", length)
+	}
+	payload := data[localWALHeaderSize : localWALHeaderSize+length]
+	if crc32.ChecksumIEEE(payload) != binary.BigEndian.Uint32(data[common.SizeOfUint32:]) {
+		return record, 0, internal.Err("corrupt local WAL record; checksum mismatch")
+	}
+
+	r := localWALReader{data: payload}
+	for n := r.uvarint(); n > 0 && r.err == nil; n-- {
+		entry, err := block.DecodeInlineRow(r.bytes())
+		if err != nil && r.err == nil {
+			r.err = err
+		}
+		record.Entries = append(record.Entries, entry)
+	}
+	for n := r.uvarint(); n > 0 && r.err == nil; n-- {
+		t := types.RangeTombstone{Start: r.bytes(), End: r.bytes()}
+		t.Seq = r.uint64()
+		record.Tombstones = append(record.Tombstones, t)
+	}
+	if r.err != nil {
+		return LocalWALRecord{}, 0, fmt.Errorf("corrupt local WAL record; %w", r.err)
+	}
+	return record, localWALHeaderSize + length, nil
+}
+
+// localWALReader reads the fields of a record, recording the first error
+type localWALReader struct {
+	data []byte
+	err  error
+}
+
+func (r *localWALReader) uvarint() uint64 {
+	if r.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(r.data)
+	if n <= 0 {
+		r.err = internal.Err("invalid length")
+		return 0
+	}
+	r.data = r.data[n:]
+	return v
+}
+
+func (r *localWALReader) bytes() []byte {
+	n := r.uvarint()
+	if r.err != nil {
+		return nil
+	}
+	if uint64(len(r.data)) < n {
+		r.err = internal.Err("length %d exceeds record", n)
+		return nil
+	}
+	b := slices.Clone(r.data[:n])
+	r.data = r.data[n:]
+	return b
+}
+
+func (r *localWALReader) uint64() uint64 {
+	if r.err != nil {
+		return 0
+	}
+	if len(r.data) < common.SizeOfUint64 {
+		r.err = internal.Err("too short for sequence number")
+		return 0
+	}
+	v := binary.BigEndian.Uint64(r.data)
+	r.data = r.data[common.SizeOfUint64:]
+	return v
+}
@@ -0,0 +1,68 @@
+package store
+
+import (
+	"log/slog"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/samber/mo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/slatedb/slatedb-go/internal/types"
+)
+
+func TestLocalWAL(t *testing.T) {
+	dir := t.TempDir()
+	w, err := OpenLocalWAL(dir, 1)
+	require.NoError(t, err)
+
+	put := types.RowEntry{Key: []byte("key1"), Value: types.Value{Value: []byte("value1")}, Seq: 1,
+		Checksum: mo.Some(types.ValueChecksum([]byte("value1")))}
+	del := types.RowEntry{Key: []byte("key2"), Value: types.Value{Kind: types.KindTombStone}, Seq: 2}
+	tombstone := types.RangeTombstone{Start: []byte("a"), End: []byte("b"), Seq: 3}
+	w.Append(1, []types.RowEntry{put, del}, nil)
+	w.Append(2, nil, []types.RangeTombstone{tombstone})
+	require.NoError(t, w.Sync())
+	require.NoError(t, w.Close())
+
+	// The end of a segment which was not completely written is ignored
+	f, err := os.OpenFile(filepath.Join(dir, "00000000000000000001-00000000000000000002.wal"), os.O_WRONLY|os.O_APPEND, 0)
+	require.NoError(t, err)
+	_, err = f.Write([]byte{0, 0, 1, 0, 1, 2})
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	w, err = OpenLocalWAL(dir, 2)
+	require.NoError(t, err)
+	records, err := w.Replay(slog.Default())
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	require.Len(t, records[0].Entries, 2)
+	assert.Equal(t, put.Key, records[0].Entries[0].Key)
+	assert.Equal(t, put.Value.Value, records[0].Entries[0].Value.Value)
+	assert.Equal(t, put.Checksum, records[0].Entries[0].Checksum)
+	assert.Equal(t, uint64(1), records[0].Entries[0].Seq)
+	assert.True(t, records[0].Entries[1].Value.IsTombstone())
+	assert.Equal(t, []types.RangeTombstone{tombstone}, records[1].Tombstones)
+
+	require.NoError(t, w.RemoveThrough(1))
+	segments, err := w.listSegments()
+	require.NoError(t, err)
+	assert.Equal(t, []localWALSegment{{epoch: 1, walID: 2}}, segments)
+	require.NoError(t, w.Close())
+
+	// The segments of a writer older than the previous writer are discarded
+	w, err = OpenLocalWAL(dir, 3)
+	require.NoError(t, err)
+	records, err = w.Replay(slog.Default())
+	require.NoError(t, err)
+	assert.Empty(t, records)
+	require.NoError(t, w.RemoveThrough(math.MaxUint64))
+	segments, err = w.listSegments()
+	require.NoError(t, err)
+	assert.Empty(t, segments)
+	require.NoError(t, w.Close())
+}
@@ -0,0 +1,66 @@
+package store
+
+import (
+	"errors"
+	"path"
+
+	"github.com/samber/mo"
+	"github.com/thanos-io/objstore"
+)
+
+const locksDir = "locks"
+
+// LockStore persists the lease objects of advisory range locks to object storage. Each lock is
+// a separate object, which is created only if it does not exist such that two processes never
+// hold the same lease object.
+type LockStore struct {
+	objectStore ObjectStore
+}
+
+func NewLockStore(rootPath string, bucket objstore.Bucket) *LockStore {
+	return &LockStore{
+		objectStore: newDelegatingObjectStore(rootPath, bucket),
+	}
+}
+
+// Create writes the lease object with the provided name, or returns internal.ErrAlreadyExists
+// if it exists
+func (s *LockStore) Create(name string, data []byte) error {
+	return s.objectStore.putIfNotExists(path.Join(locksDir, name), data)
+}
+
+// Replace overwrites the lease object with the provided name
+func (s *LockStore) Replace(name string, data []byte) error {
+	return s.objectStore.put(path.Join(locksDir, name), data)
+}
+
+// Read returns the lease object with the provided name, or mo.None if it does not exist
+func (s *LockStore) Read(name string) (mo.Option[[]byte], error) {
+	data, err := s.objectStore.get(path.Join(locksDir, name))
+	if err != nil {
+		if errors.Is(err, errObjectNotFound) {
+			return mo.None[[]byte](), nil
+		}
+		return mo.None[[]byte](), err
+	}
+	return mo.Some(data), nil
+}
+
+// List returns the names of the lease objects
+func (s *LockStore) List() ([]string, error) {
+	objects, err := s.objectStore.list(mo.Some(locksDir))
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(objects))
+	for _, obj := range objects {
+		names = append(names, path.Base(obj.Location))
+	}
+	return names, nil
+}
+
+// Delete removes the lease object with the provided name. Deleting a lease object which does
+// not exist is not an error.
+func (s *LockStore) Delete(name string) error {
+	return s.objectStore.delete(path.Join(locksDir, name))
+}
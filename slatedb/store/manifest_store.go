@@ -2,16 +2,22 @@ package store
 
 import (
 	"cmp"
+	"errors"
 	"fmt"
+	"log/slog"
+	"math/rand"
 	"path"
 	"slices"
 	"strconv"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/oklog/ulid/v2"
 	"github.com/samber/mo"
 	"github.com/slatedb/slatedb-go/internal"
+	"github.com/slatedb/slatedb-go/internal/compress"
 	"github.com/slatedb/slatedb-go/slatedb/manifest"
 	"github.com/slatedb/slatedb-go/slatedb/state"
 	"github.com/thanos-io/objstore"
@@ -19,6 +25,21 @@ import (
 
 const manifestDir = "manifest"
 
+const (
+	// conflictBackoffBase is the backoff used after the first manifest write conflict. The
+	// backoff doubles with each consecutive conflict until it reaches conflictBackoffMax.
+	conflictBackoffBase = 10 * time.Millisecond
+	conflictBackoffMax  = time.Second
+
+	// maxManifestReadAttempts is the number of times the latest manifest is listed and read
+	// when the latest manifest is pruned between listing and reading it.
+	maxManifestReadAttempts = 5
+
+	// defaultMinManifestPruneAge is the minimum age of a manifest before it is pruned, unless
+	// set with ManifestStore.SetMinPruneAge
+	defaultMinManifestPruneAge = time.Minute
+)
+
 type EpochType int
 
 const (
@@ -37,12 +58,14 @@ type FenceableManifest struct {
 	storedManifest *StoredManifest
 	localEpoch     atomic.Uint64
 	epochType      EpochType
+
+	// conflicts is the total number of manifest writes which failed because another
+	// writer updated the manifest first.
+	conflicts atomic.Uint64
 }
 
 func NewWriterFenceableManifest(storedManifest *StoredManifest) (*FenceableManifest, error) {
-	manifest := storedManifest.manifest
-	manifest.WriterEpoch.Add(1)
-	err := storedManifest.updateManifest(manifest)
+	manifest, err := storedManifest.incrementEpoch(WriterEpoch)
 	if err != nil {
 		return nil, err
 	}
@@ -56,9 +79,7 @@ func NewWriterFenceableManifest(storedManifest *StoredManifest) (*FenceableManif
 }
 
 func NewCompactorFenceableManifest(storedManifest *StoredManifest) (*FenceableManifest, error) {
-	manifest := storedManifest.manifest
-	manifest.CompactorEpoch.Add(1)
-	err := storedManifest.updateManifest(manifest)
+	manifest, err := storedManifest.incrementEpoch(CompactorEpoch)
 	if err != nil {
 		return nil, err
 	}
@@ -87,6 +108,231 @@ func (f *FenceableManifest) UpdateDBState(dbState *state.CoreStateSnapshot) erro
 	return f.storedManifest.updateDBState(dbState)
 }
 
+// UpdateDBStateWithRetry calls prepare to refresh the manifest and compute the state to write, then
+// writes the returned state to the manifest. If the write conflicts with a manifest written by another
+// process, UpdateDBStateWithRetry waits for a jittered exponential backoff and tries again. This keeps
+// the retry loop from spinning hot against a busy writer or compactor.
+func (f *FenceableManifest) UpdateDBStateWithRetry(log *slog.Logger, prepare func() (*state.CoreStateSnapshot, error)) error {
+	for attempt := 0; ; attempt++ {
+		core, err := prepare()
+		if err != nil {
+			return err
+		}
+
+		err = f.UpdateDBState(core)
+		if !errors.Is(err, internal.ErrAlreadyExists) {
+			return err
+		}
+
+		f.conflicts.Add(1)
+		backoff := conflictBackoff(attempt)
+		log.Warn("conflicting manifest version. retry write",
+			"error", err, "attempt", attempt+1, "backoff", backoff)
+		time.Sleep(backoff)
+	}
+}
+
+// EnableFeatures records `features` in the manifest if they are not already recorded. This
+// must be called before any data is written using the features so binaries which do not
+// support them refuse to open the database. Features are never removed from the manifest,
+// as data written using a feature may remain in the database indefinitely.
+func (f *FenceableManifest) EnableFeatures(features manifest.Features) error {
+	for {
+		if err := f.checkEpoch(); err != nil {
+			return err
+		}
+
+		err := f.storedManifest.enableFeatures(features)
+		if !errors.Is(err, internal.ErrAlreadyExists) {
+			return err
+		}
+
+		f.conflicts.Add(1)
+		if _, err := f.storedManifest.Refresh(); err != nil {
+			return err
+		}
+	}
+}
+
+// CreateCheckpoint calls prepare to refresh the manifest and compute the state to write, then writes
+// the returned state to the manifest along with a checkpoint which references the new manifest.
+// Expired checkpoints are removed from the manifest. See UpdateDBStateWithRetry
+func (f *FenceableManifest) CreateCheckpoint(log *slog.Logger, now time.Time, expireTime time.Time,
+	prepare func() (*state.CoreStateSnapshot, error)) (manifest.Checkpoint, error) {
+	var checkpoint manifest.Checkpoint
+	for attempt := 0; ; attempt++ {
+		core, err := prepare()
+		if err != nil {
+			return manifest.Checkpoint{}, err
+		}
+		if err := f.checkEpoch(); err != nil {
+			return manifest.Checkpoint{}, err
+		}
+
+		err = f.storedManifest.updateCheckpoints(core, func(id uint64, checkpoints []manifest.Checkpoint) []manifest.Checkpoint {
+			checkpoints = slices.DeleteFunc(checkpoints, func(c manifest.Checkpoint) bool {
+				return c.Expired(now)
+			})
+			// Each checkpoint is created by writing a new manifest, so the ID of
+			// the manifest is never reused as the ID of another checkpoint
+			checkpoint = manifest.Checkpoint{
+				ID:         id,
+				ManifestID: id,
+				ExpireTime: expireTime,
+			}
+			return append(checkpoints, checkpoint)
+		})
+		if !errors.Is(err, internal.ErrAlreadyExists) {
+			return checkpoint, err
+		}
+
+		f.conflicts.Add(1)
+		backoff := conflictBackoff(attempt)
+		log.Warn("conflicting manifest version. retry write",
+			"error", err, "attempt", attempt+1, "backoff", backoff)
+		time.Sleep(backoff)
+	}
+}
+
+// AddFilterSidecars calls prepare to refresh the manifest and compute the state to write, then
+// writes the returned state to the manifest along with `ids`, the IDs of the SSTs whose filter is
+// read from a sidecar object. IDs of SSTs which are no longer in the state are discarded. See
+// UpdateDBStateWithRetry
+func (f *FenceableManifest) AddFilterSidecars(log *slog.Logger, ids []ulid.ULID,
+	prepare func() (*state.CoreStateSnapshot, error)) error {
+	for attempt := 0; ; attempt++ {
+		core, err := prepare()
+		if err != nil {
+			return err
+		}
+		if err := f.checkEpoch(); err != nil {
+			return err
+		}
+
+		err = f.storedManifest.addFilterSidecars(core, ids)
+		if !errors.Is(err, internal.ErrAlreadyExists) {
+			return err
+		}
+
+		f.conflicts.Add(1)
+		backoff := conflictBackoff(attempt)
+		log.Warn("conflicting manifest version. retry write",
+			"error", err, "attempt", attempt+1, "backoff", backoff)
+		time.Sleep(backoff)
+	}
+}
+
+// UpdateQuarantine calls prepare to refresh the manifest and compute the state to write, then writes
+// the returned state to the manifest along with the quarantined SSTs returned by `update`, which is
+// passed the quarantined SSTs of the current manifest. See UpdateDBStateWithRetry
+func (f *FenceableManifest) UpdateQuarantine(log *slog.Logger,
+	update func(quarantined []manifest.QuarantinedSST) []manifest.QuarantinedSST,
+	prepare func() (*state.CoreStateSnapshot, error)) error {
+	for attempt := 0; ; attempt++ {
+		core, err := prepare()
+		if err != nil {
+			return err
+		}
+		if err := f.checkEpoch(); err != nil {
+			return err
+		}
+
+		err = f.storedManifest.updateQuarantine(core, update)
+		if !errors.Is(err, internal.ErrAlreadyExists) {
+			return err
+		}
+
+		f.conflicts.Add(1)
+		backoff := conflictBackoff(attempt)
+		log.Warn("conflicting manifest version. retry write",
+			"error", err, "attempt", attempt+1, "backoff", backoff)
+		time.Sleep(backoff)
+	}
+}
+
+// DeleteCheckpoint removes the checkpoint with `id` from the manifest, such that the manifest
+// it references may be pruned. Deleting a checkpoint which does not exist is not an error.
+func (f *FenceableManifest) DeleteCheckpoint(id uint64) error {
+	for {
+		if err := f.checkEpoch(); err != nil {
+			return err
+		}
+		if !slices.ContainsFunc(f.storedManifest.Checkpoints(), func(c manifest.Checkpoint) bool {
+			return c.ID == id
+		}) {
+			return nil
+		}
+
+		err := f.storedManifest.updateCheckpoints(f.storedManifest.DbState(),
+			func(_ uint64, checkpoints []manifest.Checkpoint) []manifest.Checkpoint {
+				return slices.DeleteFunc(checkpoints, func(c manifest.Checkpoint) bool {
+					return c.ID == id
+				})
+			})
+		if !errors.Is(err, internal.ErrAlreadyExists) {
+			return err
+		}
+
+		f.conflicts.Add(1)
+		if _, err := f.storedManifest.Refresh(); err != nil {
+			return err
+		}
+	}
+}
+
+// Checkpoints returns the checkpoints recorded in the manifest when it was last loaded
+func (f *FenceableManifest) Checkpoints() []manifest.Checkpoint {
+	return f.storedManifest.Checkpoints()
+}
+
+// ExternalDBs returns the external databases recorded in the manifest when it was last loaded
+func (f *FenceableManifest) ExternalDBs() []manifest.ExternalDB {
+	return f.storedManifest.ExternalDBs()
+}
+
+// FilterSidecars returns the IDs of the SSTs whose filter is read from a sidecar object, as
+// recorded in the manifest when it was last loaded
+func (f *FenceableManifest) FilterSidecars() []ulid.ULID {
+	return f.storedManifest.FilterSidecars()
+}
+
+// Quarantined returns the quarantined SSTs recorded in the manifest when it was last loaded
+func (f *FenceableManifest) Quarantined() []manifest.QuarantinedSST {
+	return f.storedManifest.Quarantined()
+}
+
+// Features returns the on-disk format features recorded in the manifest when it was last loaded
+func (f *FenceableManifest) Features() manifest.Features {
+	return f.storedManifest.Features()
+}
+
+// Conflicts returns the total number of manifest writes which conflicted with another writer
+func (f *FenceableManifest) Conflicts() uint64 {
+	return f.conflicts.Load()
+}
+
+// ID returns the ID of the manifest most recently written or read
+func (f *FenceableManifest) ID() uint64 {
+	return f.storedManifest.ID()
+}
+
+// Epoch returns the epoch of the writer or compactor which holds this manifest
+func (f *FenceableManifest) Epoch() uint64 {
+	return f.localEpoch.Load()
+}
+
+// PruneManifests deletes all but the `retain` most recent manifest versions.
+// See ManifestStore.PruneManifests
+func (f *FenceableManifest) PruneManifests(retain int) (int, error) {
+	return f.storedManifest.manifestStore.PruneManifests(retain)
+}
+
+// PruneManifestsBefore deletes the manifest versions which are not among the `retain` most recent
+// and were written before `before`. See ManifestStore.PruneManifestsBefore
+func (f *FenceableManifest) PruneManifestsBefore(retain int, before time.Time) (int, error) {
+	return f.storedManifest.manifestStore.PruneManifestsBefore(retain, before)
+}
+
 func (f *FenceableManifest) Refresh() (*state.CoreStateSnapshot, error) {
 	_, err := f.storedManifest.Refresh()
 	if err != nil {
@@ -96,6 +342,8 @@ func (f *FenceableManifest) Refresh() (*state.CoreStateSnapshot, error) {
 }
 
 func (f *FenceableManifest) storedEpoch() uint64 {
+	f.storedManifest.mu.Lock()
+	defer f.storedManifest.mu.Unlock()
 	if f.epochType == WriterEpoch {
 		return f.storedManifest.manifest.WriterEpoch.Load()
 	} else {
@@ -113,6 +361,16 @@ func (f *FenceableManifest) checkEpoch() error {
 	return nil
 }
 
+// conflictBackoff returns a random duration between zero and an exponentially increasing
+// upper bound ("full jitter"), so competing writers do not retry in lockstep.
+func conflictBackoff(attempt int) time.Duration {
+	upper := conflictBackoffMax
+	if attempt < 32 {
+		upper = min(conflictBackoffBase<<attempt, conflictBackoffMax)
+	}
+	return time.Duration(rand.Int63n(int64(upper)) + 1)
+}
+
 // ------------------------------------------------
 // StoredManifest
 // ------------------------------------------------
@@ -124,7 +382,12 @@ func (f *FenceableManifest) checkEpoch() error {
 // no other writer having made an update to the manifest using that id. Finally, callers
 // can use the `refresh` method to refresh the locally stored manifest+id with the latest
 // manifest stored in the object store.
+//
+// A StoredManifest may be used by multiple goroutines, such as the writer's flush task and the
+// callers which record checkpoints, features and quarantined SSTs. Its updates are serialized.
 type StoredManifest struct {
+	// mu guards id and manifest, and is held for the duration of each update
+	mu            sync.Mutex
 	id            uint64
 	manifest      *manifest.Manifest
 	manifestStore *ManifestStore
@@ -146,17 +409,38 @@ func NewStoredManifest(store *ManifestStore, core *state.CoreDBState) (*StoredMa
 	}, nil
 }
 
-func LoadStoredManifest(store *ManifestStore) (mo.Option[StoredManifest], error) {
+// NewStoredManifestFrom writes `manifest` as the first manifest of a new database, such as a clone
+// of another database. It fails with internal.ErrAlreadyExists if the database already exists.
+func NewStoredManifestFrom(store *ManifestStore, manifest *manifest.Manifest) (*StoredManifest, error) {
 	stored, err := store.readLatestManifest()
 	if err != nil {
-		return mo.None[StoredManifest](), err
+		return nil, err
+	}
+	if stored.IsPresent() {
+		return nil, internal.ErrAlreadyExists
+	}
+	if err := store.writeManifest(1, manifest); err != nil {
+		return nil, err
+	}
+
+	return &StoredManifest{
+		id:            1,
+		manifest:      manifest,
+		manifestStore: store,
+	}, nil
+}
+
+func LoadStoredManifest(store *ManifestStore) (mo.Option[*StoredManifest], error) {
+	stored, err := store.readLatestManifest()
+	if err != nil {
+		return mo.None[*StoredManifest](), err
 	}
 	if stored.IsAbsent() {
-		return mo.None[StoredManifest](), nil
+		return mo.None[*StoredManifest](), nil
 	}
 
 	storedInfo, _ := stored.Get()
-	return mo.Some(StoredManifest{
+	return mo.Some(&StoredManifest{
 		id:            storedInfo.id,
 		manifest:      storedInfo.manifest,
 		manifestStore: store,
@@ -164,20 +448,245 @@ func LoadStoredManifest(store *ManifestStore) (mo.Option[StoredManifest], error)
 }
 
 func (s *StoredManifest) DbState() *state.CoreStateSnapshot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	return s.manifest.Core.Snapshot()
 }
 
+// ID returns the ID of the manifest most recently written or read
+func (s *StoredManifest) ID() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.id
+}
+
+// write Manifest with the epoch of `epochType` incremented, which fences the previous writer
+// or compactor, and return the new manifest
+func (s *StoredManifest) incrementEpoch(epochType EpochType) (*manifest.Manifest, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	manifest := s.manifest
+	if epochType == WriterEpoch {
+		manifest.WriterEpoch.Add(1)
+	} else {
+		manifest.CompactorEpoch.Add(1)
+	}
+	if err := s.updateManifest(manifest); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
 // write Manifest with updated DB state to object store and update StoredManifest with the new manifest
 func (s *StoredManifest) updateDBState(coreSnapshot *state.CoreStateSnapshot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	manifest := &manifest.Manifest{
-		Core: coreSnapshot.ToCoreState(),
+		Core:           coreSnapshot.ToCoreState(),
+		Features:       s.manifest.Features,
+		Checkpoints:    s.manifest.Checkpoints,
+		ExternalDBs:    s.manifest.ExternalDBs,
+		FilterSidecars: liveFilterSidecars(coreSnapshot, s.manifest.FilterSidecars),
+		Quarantined:    s.manifest.Quarantined,
+		Leases:         s.manifest.Leases,
 	}
 	manifest.WriterEpoch.Store(s.manifest.WriterEpoch.Load())
 	manifest.CompactorEpoch.Store(s.manifest.CompactorEpoch.Load())
 	return s.updateManifest(manifest)
 }
 
-// write given manifest to object store and update StoredManifest with given manifest
+// write Manifest with `features` added to the features of the current manifest, if any are missing
+func (s *StoredManifest) enableFeatures(features manifest.Features) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.manifest.Features.Has(features) {
+		return nil
+	}
+
+	manifest := &manifest.Manifest{
+		Core:           s.manifest.Core.Snapshot().ToCoreState(),
+		Features:       s.manifest.Features | features,
+		Checkpoints:    s.manifest.Checkpoints,
+		ExternalDBs:    s.manifest.ExternalDBs,
+		FilterSidecars: s.manifest.FilterSidecars,
+		Quarantined:    s.manifest.Quarantined,
+		Leases:         s.manifest.Leases,
+	}
+	manifest.WriterEpoch.Store(s.manifest.WriterEpoch.Load())
+	manifest.CompactorEpoch.Store(s.manifest.CompactorEpoch.Load())
+	return s.updateManifest(manifest)
+}
+
+// write Manifest with the DB state and the checkpoints returned by `update`, which is passed the
+// ID of the new manifest and the checkpoints of the current manifest
+func (s *StoredManifest) updateCheckpoints(coreSnapshot *state.CoreStateSnapshot,
+	update func(id uint64, checkpoints []manifest.Checkpoint) []manifest.Checkpoint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	manifest := &manifest.Manifest{
+		Core:           coreSnapshot.ToCoreState(),
+		Features:       s.manifest.Features,
+		Checkpoints:    update(s.id+1, slices.Clone(s.manifest.Checkpoints)),
+		ExternalDBs:    s.manifest.ExternalDBs,
+		FilterSidecars: liveFilterSidecars(coreSnapshot, s.manifest.FilterSidecars),
+		Quarantined:    s.manifest.Quarantined,
+		Leases:         s.manifest.Leases,
+	}
+	manifest.WriterEpoch.Store(s.manifest.WriterEpoch.Load())
+	manifest.CompactorEpoch.Store(s.manifest.CompactorEpoch.Load())
+	return s.updateManifest(manifest)
+}
+
+// write Manifest with the DB state and `ids` added to the filter sidecars of the current manifest
+func (s *StoredManifest) addFilterSidecars(coreSnapshot *state.CoreStateSnapshot, ids []ulid.ULID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sidecars := slices.Clone(s.manifest.FilterSidecars)
+	for _, id := range ids {
+		if !slices.Contains(sidecars, id) {
+			sidecars = append(sidecars, id)
+		}
+	}
+	manifest := &manifest.Manifest{
+		Core:           coreSnapshot.ToCoreState(),
+		Features:       s.manifest.Features,
+		Checkpoints:    s.manifest.Checkpoints,
+		ExternalDBs:    s.manifest.ExternalDBs,
+		FilterSidecars: liveFilterSidecars(coreSnapshot, sidecars),
+		Quarantined:    s.manifest.Quarantined,
+		Leases:         s.manifest.Leases,
+	}
+	manifest.WriterEpoch.Store(s.manifest.WriterEpoch.Load())
+	manifest.CompactorEpoch.Store(s.manifest.CompactorEpoch.Load())
+	return s.updateManifest(manifest)
+}
+
+// write Manifest with the DB state and the quarantined SSTs returned by `update`, which is passed
+// the quarantined SSTs of the current manifest
+func (s *StoredManifest) updateQuarantine(coreSnapshot *state.CoreStateSnapshot,
+	update func(quarantined []manifest.QuarantinedSST) []manifest.QuarantinedSST) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	manifest := &manifest.Manifest{
+		Core:           coreSnapshot.ToCoreState(),
+		Features:       s.manifest.Features,
+		Checkpoints:    s.manifest.Checkpoints,
+		ExternalDBs:    s.manifest.ExternalDBs,
+		FilterSidecars: liveFilterSidecars(coreSnapshot, s.manifest.FilterSidecars),
+		Quarantined:    update(slices.Clone(s.manifest.Quarantined)),
+		Leases:         s.manifest.Leases,
+	}
+	manifest.WriterEpoch.Store(s.manifest.WriterEpoch.Load())
+	manifest.CompactorEpoch.Store(s.manifest.CompactorEpoch.Load())
+	return s.updateManifest(manifest)
+}
+
+// write Manifest with the leases returned by `update`, which is passed the leases of the current
+// manifest. The DB state is that of the current manifest.
+func (s *StoredManifest) updateLeases(update func(leases []manifest.Lease) []manifest.Lease) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	manifest := &manifest.Manifest{
+		Core:           s.manifest.Core.Snapshot().ToCoreState(),
+		Features:       s.manifest.Features,
+		Checkpoints:    s.manifest.Checkpoints,
+		ExternalDBs:    s.manifest.ExternalDBs,
+		FilterSidecars: s.manifest.FilterSidecars,
+		Quarantined:    s.manifest.Quarantined,
+		Leases:         update(slices.Clone(s.manifest.Leases)),
+	}
+	manifest.WriterEpoch.Store(s.manifest.WriterEpoch.Load())
+	manifest.CompactorEpoch.Store(s.manifest.CompactorEpoch.Load())
+	return s.updateManifest(manifest)
+}
+
+// UpdateLeases writes the leases returned by `update`, which is passed the leases of the current
+// manifest less those which expired before `now`, refreshing the manifest and retrying if another
+// process wrote a manifest first. Unlike the updates of FenceableManifest, the epochs are not
+// checked, such that the leases may be written by a read-only process without fencing the writer.
+func (s *StoredManifest) UpdateLeases(now time.Time, update func(leases []manifest.Lease) []manifest.Lease) error {
+	for {
+		err := s.updateLeases(func(leases []manifest.Lease) []manifest.Lease {
+			return update(slices.DeleteFunc(leases, func(l manifest.Lease) bool {
+				return l.Expired(now)
+			}))
+		})
+		if !errors.Is(err, internal.ErrAlreadyExists) {
+			return err
+		}
+		if _, err := s.Refresh(); err != nil {
+			return err
+		}
+	}
+}
+
+// Leases returns the leases recorded in the manifest
+func (s *StoredManifest) Leases() []manifest.Lease {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.manifest.Leases)
+}
+
+// liveFilterSidecars returns the IDs in `sidecars` of the SSTs which remain in `core`, such that
+// the sidecars of SSTs which have been compacted are removed from the manifest
+func liveFilterSidecars(core *state.CoreStateSnapshot, sidecars []ulid.ULID) []ulid.ULID {
+	if len(sidecars) == 0 {
+		return nil
+	}
+	live := make(map[string]bool)
+	for _, handle := range core.L0 {
+		live[handle.Id.Value] = true
+	}
+	for _, sr := range core.Compacted {
+		for _, handle := range sr.SSTList {
+			live[handle.Id.Value] = true
+		}
+	}
+	result := make([]ulid.ULID, 0, len(sidecars))
+	for _, id := range sidecars {
+		if live[id.String()] {
+			result = append(result, id)
+		}
+	}
+	return result
+}
+
+// Features returns the on-disk format features recorded in the manifest
+func (s *StoredManifest) Features() manifest.Features {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.manifest.Features
+}
+
+// Checkpoints returns the checkpoints recorded in the manifest
+func (s *StoredManifest) Checkpoints() []manifest.Checkpoint {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.manifest.Checkpoints)
+}
+
+// ExternalDBs returns the external databases recorded in the manifest
+func (s *StoredManifest) ExternalDBs() []manifest.ExternalDB {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.manifest.ExternalDBs)
+}
+
+// FilterSidecars returns the IDs of the SSTs whose filter is read from a sidecar object
+func (s *StoredManifest) FilterSidecars() []ulid.ULID {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.manifest.FilterSidecars)
+}
+
+// Quarantined returns the quarantined SSTs recorded in the manifest
+func (s *StoredManifest) Quarantined() []manifest.QuarantinedSST {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.manifest.Quarantined)
+}
+
+// write given manifest to object store and update StoredManifest with given manifest. s.mu must be held
 func (s *StoredManifest) updateManifest(manifest *manifest.Manifest) error {
 	newID := s.id + 1
 	err := s.manifestStore.writeManifest(newID, manifest)
@@ -200,9 +709,14 @@ func (s *StoredManifest) Refresh() (*state.CoreStateSnapshot, error) {
 	}
 
 	storedInfo, _ := stored.Get()
-	s.manifest = storedInfo.manifest
-	s.id = storedInfo.id
-	return s.DbState(), nil
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// A manifest may have been written by an update since the latest manifest was read
+	if storedInfo.id > s.id {
+		s.manifest = storedInfo.manifest
+		s.id = storedInfo.id
+	}
+	return s.manifest.Core.Snapshot(), nil
 }
 
 // ------------------------------------------------
@@ -229,6 +743,16 @@ type ManifestStore struct {
 	objectStore    ObjectStore
 	codec          manifest.Codec
 	manifestSuffix string
+
+	// onWrite, if not nil, is called with each manifest written, see OnWrite
+	onWrite func(id uint64, manifest *manifest.Manifest)
+
+	// minPruneAge is the minimum age of a manifest before it is pruned, see SetMinPruneAge
+	minPruneAge time.Duration
+
+	// latestID is the latest manifest ID known to exist, as written by this ManifestStore or
+	// listed when the manifests were last listed, see writeManifest
+	latestID atomic.Uint64
 }
 
 func NewManifestStore(rootPath string, bucket objstore.Bucket) *ManifestStore {
@@ -236,23 +760,80 @@ func NewManifestStore(rootPath string, bucket objstore.Bucket) *ManifestStore {
 		objectStore:    newDelegatingObjectStore(rootPath, bucket),
 		codec:          manifest.FlatBufferManifestCodec{},
 		manifestSuffix: "manifest",
+		minPruneAge:    defaultMinManifestPruneAge,
 	}
 }
 
+// SetCompression compresses the manifests written with `codec`, see
+// manifest.FlatBufferManifestCodec.Compression. It must be called before the ManifestStore is used.
+func (s *ManifestStore) SetCompression(codec compress.Codec) {
+	s.codec = manifest.FlatBufferManifestCodec{Compression: codec}
+}
+
+// OnWrite sets `fn` to be called with the ID and contents of each manifest once it is written. It
+// must be called before the ManifestStore is used.
+func (s *ManifestStore) OnWrite(fn func(id uint64, manifest *manifest.Manifest)) {
+	s.onWrite = fn
+}
+
+// SetMinPruneAge sets the minimum age of a manifest before it is pruned, which must exceed the
+// longest time a writer may hold a manifest without refreshing it. It must be called before the
+// ManifestStore is used.
+func (s *ManifestStore) SetMinPruneAge(age time.Duration) {
+	s.minPruneAge = age
+}
+
 func (s *ManifestStore) manifestPath(filename string) string {
 	return path.Join(manifestDir, filename)
 }
 
+// writeManifest writes the manifest with `id`, or fails with internal.ErrAlreadyExists if a manifest
+// with `id` or a later ID is known to exist. The manifest with `id` may have been pruned, which would
+// otherwise let a stale writer fill the gap it left. The manifests are not listed before each write,
+// instead the latest ID is tracked as manifests are written and listed, which happens when the
+// manifest is loaded and refreshed after a conflict. A stale writer in another process is refused
+// by the conditional write, as manifests younger than the minimum prune age are not pruned.
 func (s *ManifestStore) writeManifest(id uint64, manifest *manifest.Manifest) error {
+	if id <= s.latestID.Load() {
+		return internal.ErrAlreadyExists
+	}
+
 	filepath := s.manifestPath(fmt.Sprintf("%020d.%s", id, s.manifestSuffix))
 	err := s.objectStore.putIfNotExists(filepath, s.codec.Encode(manifest))
 	if err != nil {
 		return err
 	}
+	s.observeID(id)
+	if s.onWrite != nil {
+		s.onWrite(id, manifest)
+	}
 	return nil
 }
 
-func (s *ManifestStore) listManifests() ([]ManifestFileMetadata, error) {
+// observeID records that the manifest with `id` exists
+func (s *ManifestStore) observeID(id uint64) {
+	for {
+		latest := s.latestID.Load()
+		if id <= latest || s.latestID.CompareAndSwap(latest, id) {
+			return
+		}
+	}
+}
+
+// ReadManifest reads the manifest with `id`, such as the manifest referenced by a checkpoint
+func (s *ManifestStore) ReadManifest(id uint64) (*manifest.Manifest, error) {
+	manifestBytes, err := s.objectStore.get(s.manifestPath(fmt.Sprintf("%020d.%s", id, s.manifestSuffix)))
+	if err != nil {
+		if errors.Is(err, errObjectNotFound) {
+			return nil, internal.Err("manifest '%d' not found", id)
+		}
+		return nil, err
+	}
+	return s.codec.Decode(manifestBytes)
+}
+
+// ListManifests lists the stored manifests in the order of their IDs
+func (s *ManifestStore) ListManifests() ([]ManifestFileMetadata, error) {
 	objMetaList, err := s.objectStore.list(mo.Some(manifestDir))
 	if err != nil {
 		return nil, err
@@ -275,32 +856,114 @@ func (s *ManifestStore) listManifests() ([]ManifestFileMetadata, error) {
 	slices.SortFunc(manifests, func(a, b ManifestFileMetadata) int {
 		return cmp.Compare(a.ID, b.ID)
 	})
+	if len(manifests) > 0 {
+		s.observeID(manifests[len(manifests)-1].ID)
+	}
 	return manifests, nil
 }
 
 func (s *ManifestStore) readLatestManifest() (mo.Option[manifestInfo], error) {
-	manifestList, err := s.listManifests()
-	if err != nil || len(manifestList) == 0 {
-		return mo.None[manifestInfo](), err
+	for attempt := 0; ; attempt++ {
+		manifestList, err := s.ListManifests()
+		if err != nil || len(manifestList) == 0 {
+			return mo.None[manifestInfo](), err
+		}
+
+		latestManifest := manifestList[len(manifestList)-1]
+		if latestManifest.Location == "" {
+			return mo.None[manifestInfo](), nil
+		}
+
+		// read the latest manifest from object store and return the manifest
+		filename := path.Base(latestManifest.Location)
+		manifestBytes, err := s.objectStore.get(s.manifestPath(filename))
+		if err != nil {
+			// The manifest may have been pruned after a newer manifest was written
+			// between listing and reading it. List the manifests again.
+			if errors.Is(err, errObjectNotFound) && attempt < maxManifestReadAttempts {
+				continue
+			}
+			return mo.None[manifestInfo](), err
+		}
+
+		manifest, err := s.codec.Decode(manifestBytes)
+		if err != nil {
+			return mo.None[manifestInfo](), err
+		}
+		return mo.Some(manifestInfo{latestManifest.ID, manifest}), nil
 	}
+}
+
+// PruneManifests deletes all but the `retain` most recent manifest versions, and returns
+// the number of manifests deleted. Retaining older versions gives readers which loaded
+// a slightly older manifest a grace period in which the state it references is still
+// described by a stored manifest. The latest manifest and the manifests referenced by
+// the checkpoints of the latest manifest are never deleted.
+func (s *ManifestStore) PruneManifests(retain int) (int, error) {
+	return s.pruneManifests(retain, mo.None[time.Time]())
+}
+
+// PruneManifestsBefore is PruneManifests, but also retains the manifest versions which were
+// written at or after `before`, such that manifests are retained for a minimum duration
+// regardless of how frequently the manifest is updated
+//
+// Either way, the manifests younger than the minimum prune age are retained, see SetMinPruneAge
+func (s *ManifestStore) PruneManifestsBefore(retain int, before time.Time) (int, error) {
+	return s.pruneManifests(retain, mo.Some(before))
+}
 
-	latestManifest := manifestList[len(manifestList)-1]
-	if latestManifest.Location == "" {
-		return mo.None[manifestInfo](), nil
+func (s *ManifestStore) pruneManifests(retain int, before mo.Option[time.Time]) (int, error) {
+	if retain < 1 {
+		return 0, internal.ErrInvalidArgument("must retain at least one manifest; got %d", retain)
 	}
 
-	// read the latest manifest from object store and return the manifest
-	filename := path.Base(latestManifest.Location)
-	manifestBytes, err := s.objectStore.get(s.manifestPath(filename))
+	manifestList, err := s.ListManifests()
 	if err != nil {
-		return mo.None[manifestInfo](), err
+		return 0, err
+	}
+	if len(manifestList) <= retain {
+		return 0, nil
 	}
 
-	manifest, err := s.codec.Decode(manifestBytes)
+	latest, err := s.readLatestManifest()
 	if err != nil {
-		return mo.None[manifestInfo](), err
+		return 0, err
+	}
+	pinned := make(map[uint64]bool)
+	if info, ok := latest.Get(); ok {
+		for _, c := range info.manifest.Checkpoints {
+			pinned[c.ManifestID] = true
+		}
 	}
-	return mo.Some(manifestInfo{latestManifest.ID, manifest}), nil
+
+	// Object storage records the wall clock time a manifest was written
+	cutoff := time.Now().Add(-s.minPruneAge)
+	if b, ok := before.Get(); ok && b.Before(cutoff) {
+		cutoff = b
+	}
+
+	deleted := 0
+	for _, m := range manifestList[:len(manifestList)-retain] {
+		if pinned[m.ID] {
+			continue
+		}
+		if !m.LastModified.Before(cutoff) {
+			// The manifests are ordered by ID, so those which follow were written later
+			break
+		}
+		if err := s.objectStore.delete(s.manifestPath(path.Base(m.Location))); err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+// DeleteManifest deletes the manifest with `id`. A manifest which no longer exists is not an error.
+// The caller must ensure that neither the latest manifest nor a manifest pinned by a checkpoint
+// is deleted, see PruneManifests
+func (s *ManifestStore) DeleteManifest(id uint64) error {
+	return s.objectStore.delete(s.manifestPath(fmt.Sprintf("%020d.%s", id, s.manifestSuffix)))
 }
 
 func (s *ManifestStore) parseID(filepath string, expectedExt string) (uint64, error) {
@@ -1,9 +1,18 @@
 package store
 
 import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/oklog/ulid/v2"
 	"github.com/slatedb/slatedb-go/internal"
+	"github.com/slatedb/slatedb-go/internal/compress"
+	"github.com/slatedb/slatedb-go/internal/flatbuf"
+	"github.com/slatedb/slatedb-go/slatedb/manifest"
 	"github.com/slatedb/slatedb-go/slatedb/state"
 	"github.com/stretchr/testify/assert"
 	"github.com/thanos-io/objstore"
@@ -87,6 +96,41 @@ func TestShouldRefresh(t *testing.T) {
 	assert.Equal(t, uint64(123), sm.DbState().NextWalSstID.Load())
 }
 
+func TestShouldRefreshWhileUpdating(t *testing.T) {
+	bucket := objstore.NewInMemBucket()
+	manifestStore := NewManifestStore(rootPath, bucket)
+	coreState := state.NewCoreDBState()
+
+	sm, err := NewStoredManifest(manifestStore, coreState)
+	assert.NoError(t, err)
+	fm, err := NewWriterFenceableManifest(sm)
+	assert.NoError(t, err)
+
+	// the manifest is refreshed by one goroutine while others update it, as the writer's
+	// flush task does while callers record checkpoints and features
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				if i == 0 {
+					_, err := fm.Refresh()
+					assert.NoError(t, err)
+					continue
+				}
+				err := fm.UpdateDBStateWithRetry(slog.Default(), func() (*state.CoreStateSnapshot, error) {
+					return fm.Refresh()
+				})
+				assert.NoError(t, err)
+				fm.Checkpoints()
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, uint64(32), fm.ID())
+}
+
 func TestShouldBumpWriterEpoch(t *testing.T) {
 	bucket := objstore.NewInMemBucket()
 	manifestStore := NewManifestStore(rootPath, bucket)
@@ -101,7 +145,7 @@ func TestShouldBumpWriterEpoch(t *testing.T) {
 		sm, ok := storedManifest.Get()
 		assert.True(t, ok)
 
-		_, err = NewWriterFenceableManifest(&sm)
+		_, err = NewWriterFenceableManifest(sm)
 		assert.NoError(t, err)
 
 		info, err := manifestStore.readLatestManifest()
@@ -126,7 +170,7 @@ func TestShouldFailOnWriterFenced(t *testing.T) {
 	assert.NoError(t, err)
 	sm2, ok := storedManifest.Get()
 	assert.True(t, ok)
-	writer2, err := NewWriterFenceableManifest(&sm2)
+	writer2, err := NewWriterFenceableManifest(sm2)
 	assert.NoError(t, err)
 
 	_, err = writer1.Refresh()
@@ -155,7 +199,7 @@ func TestShouldBumpCompactorEpoch(t *testing.T) {
 		sm, ok := storedManifest.Get()
 		assert.True(t, ok)
 
-		_, err = NewCompactorFenceableManifest(&sm)
+		_, err = NewCompactorFenceableManifest(sm)
 		assert.NoError(t, err)
 
 		info, err := manifestStore.readLatestManifest()
@@ -180,7 +224,7 @@ func TestShouldFailOnCompactorFenced(t *testing.T) {
 	assert.NoError(t, err)
 	sm2, ok := storedManifest.Get()
 	assert.True(t, ok)
-	compactor2, err := NewCompactorFenceableManifest(&sm2)
+	compactor2, err := NewCompactorFenceableManifest(sm2)
 	assert.NoError(t, err)
 
 	_, err = compactor1.Refresh()
@@ -194,3 +238,376 @@ func TestShouldFailOnCompactorFenced(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, uint64(1), refreshed.NextWalSstID.Load())
 }
+
+func TestShouldRetryWriteOnVersionConflict(t *testing.T) {
+	bucket := objstore.NewInMemBucket()
+	manifestStore := NewManifestStore(rootPath, bucket)
+	coreState := state.NewCoreDBState()
+
+	sm, err := NewStoredManifest(manifestStore, coreState)
+	assert.NoError(t, err)
+	writer, err := NewWriterFenceableManifest(sm)
+	assert.NoError(t, err)
+
+	// the compactor bumps its epoch, which leaves the writer with a stale manifest version
+	storedManifest, err := LoadStoredManifest(manifestStore)
+	assert.NoError(t, err)
+	sm2, ok := storedManifest.Get()
+	assert.True(t, ok)
+	_, err = NewCompactorFenceableManifest(sm2)
+	assert.NoError(t, err)
+
+	attempts := 0
+	err = writer.UpdateDBStateWithRetry(slog.Default(), func() (*state.CoreStateSnapshot, error) {
+		attempts++
+		// only refresh on retry, so the first write conflicts
+		if attempts > 1 {
+			if _, err := writer.Refresh(); err != nil {
+				return nil, err
+			}
+		}
+		core := coreState.Snapshot()
+		core.NextWalSstID.Store(123)
+		return core, nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, attempts)
+	assert.Equal(t, uint64(1), writer.Conflicts())
+
+	refreshed, err := sm2.Refresh()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(123), refreshed.NextWalSstID.Load())
+}
+
+func TestConflictBackoff(t *testing.T) {
+	for attempt := 0; attempt < 100; attempt++ {
+		backoff := conflictBackoff(attempt)
+		assert.Greater(t, backoff, time.Duration(0))
+		assert.LessOrEqual(t, backoff, conflictBackoffMax)
+		if attempt == 0 {
+			assert.LessOrEqual(t, backoff, conflictBackoffBase)
+		}
+	}
+}
+
+func TestShouldPruneOldManifests(t *testing.T) {
+	bucket := objstore.NewInMemBucket()
+	manifestStore := NewManifestStore(rootPath, bucket)
+	manifestStore.SetMinPruneAge(0)
+	coreState := state.NewCoreDBState()
+
+	sm, err := NewStoredManifest(manifestStore, coreState)
+	assert.NoError(t, err)
+	for i := 0; i < 4; i++ {
+		assert.NoError(t, sm.updateDBState(coreState.Snapshot()))
+	}
+
+	_, err = manifestStore.PruneManifests(0)
+	assert.Error(t, err)
+
+	deleted, err := manifestStore.PruneManifests(2)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, deleted)
+
+	manifests, err := manifestStore.ListManifests()
+	assert.NoError(t, err)
+	assert.Len(t, manifests, 2)
+	assert.Equal(t, uint64(4), manifests[0].ID)
+	assert.Equal(t, uint64(5), manifests[1].ID)
+
+	// Pruning is idempotent, and writes continue with the next version
+	deleted, err = manifestStore.PruneManifests(2)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, deleted)
+	assert.NoError(t, sm.updateDBState(coreState.Snapshot()))
+
+	info, err := manifestStore.readLatestManifest()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(6), info.MustGet().id)
+}
+
+func TestShouldFailStaleWriteAfterPrune(t *testing.T) {
+	bucket := objstore.NewInMemBucket()
+	manifestStore := NewManifestStore(rootPath, bucket)
+	manifestStore.SetMinPruneAge(0)
+	coreState := state.NewCoreDBState()
+
+	sm, err := NewStoredManifest(manifestStore, coreState)
+	assert.NoError(t, err)
+	stale, err := LoadStoredManifest(manifestStore)
+	assert.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, sm.updateDBState(coreState.Snapshot()))
+	}
+	_, err = manifestStore.PruneManifests(1)
+	assert.NoError(t, err)
+
+	// the manifest following the stale manifest was pruned, yet later manifests exist
+	staleManifest := stale.MustGet()
+	err = staleManifest.updateDBState(coreState.Snapshot())
+	assert.ErrorIs(t, err, internal.ErrAlreadyExists)
+	manifests, err := manifestStore.ListManifests()
+	assert.NoError(t, err)
+	assert.Len(t, manifests, 1)
+	assert.Equal(t, uint64(4), manifests[0].ID)
+}
+
+// listCountingBucket counts the requests which list the objects of the bucket
+type listCountingBucket struct {
+	objstore.Bucket
+	lists int
+}
+
+func (b *listCountingBucket) Iter(ctx context.Context, dir string, f func(string) error, options ...objstore.IterOption) error {
+	b.lists++
+	return b.Bucket.Iter(ctx, dir, f, options...)
+}
+
+func (b *listCountingBucket) IterWithAttributes(ctx context.Context, dir string, f func(objstore.IterObjectAttributes) error,
+	options ...objstore.IterOption) error {
+	b.lists++
+	return b.Bucket.IterWithAttributes(ctx, dir, f, options...)
+}
+
+func TestShouldNotListManifestsBeforeWrite(t *testing.T) {
+	bucket := &listCountingBucket{Bucket: objstore.NewInMemBucket()}
+	manifestStore := NewManifestStore(rootPath, bucket)
+	coreState := state.NewCoreDBState()
+
+	sm, err := NewStoredManifest(manifestStore, coreState)
+	assert.NoError(t, err)
+	stored, err := LoadStoredManifest(NewManifestStore(rootPath, bucket))
+	assert.NoError(t, err)
+	other := stored.MustGet()
+
+	bucket.lists = 0
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, sm.updateDBState(coreState.Snapshot()))
+	}
+	assert.Zero(t, bucket.lists)
+
+	// the conflicting write is refused by the conditional write, and the manifests are
+	// listed once, when the manifest is refreshed after the conflict
+	err = other.updateDBState(coreState.Snapshot())
+	assert.ErrorIs(t, err, internal.ErrAlreadyExists)
+	assert.Zero(t, bucket.lists)
+	_, err = other.Refresh()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, bucket.lists)
+	assert.NoError(t, other.updateDBState(coreState.Snapshot()))
+	assert.Equal(t, 1, bucket.lists)
+	assert.Equal(t, uint64(5), other.ID())
+}
+
+func TestShouldRetainYoungManifests(t *testing.T) {
+	bucket := objstore.NewInMemBucket()
+	manifestStore := NewManifestStore(rootPath, bucket)
+	coreState := state.NewCoreDBState()
+
+	sm, err := NewStoredManifest(manifestStore, coreState)
+	assert.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, sm.updateDBState(coreState.Snapshot()))
+	}
+
+	// the manifests are younger than the default minimum prune age
+	deleted, err := manifestStore.PruneManifestsBefore(1, time.Now().Add(time.Second))
+	assert.NoError(t, err)
+	assert.Zero(t, deleted)
+}
+
+func TestShouldRetainRecentManifests(t *testing.T) {
+	bucket := objstore.NewInMemBucket()
+	manifestStore := NewManifestStore(rootPath, bucket)
+	manifestStore.SetMinPruneAge(0)
+	coreState := state.NewCoreDBState()
+
+	sm, err := NewStoredManifest(manifestStore, coreState)
+	assert.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		assert.NoError(t, sm.updateDBState(coreState.Snapshot()))
+	}
+	time.Sleep(10 * time.Millisecond)
+	before := time.Now()
+	for i := 0; i < 2; i++ {
+		assert.NoError(t, sm.updateDBState(coreState.Snapshot()))
+	}
+
+	// the manifests written at or after `before` are retained along with the most recent one
+	deleted, err := manifestStore.PruneManifestsBefore(1, before)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, deleted)
+	manifests, err := manifestStore.ListManifests()
+	assert.NoError(t, err)
+	assert.Len(t, manifests, 2)
+	assert.Equal(t, uint64(4), manifests[0].ID)
+
+	deleted, err = manifestStore.PruneManifestsBefore(1, time.Now().Add(time.Second))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+}
+
+func TestShouldRetainCheckpointManifests(t *testing.T) {
+	bucket := objstore.NewInMemBucket()
+	manifestStore := NewManifestStore(rootPath, bucket)
+	manifestStore.SetMinPruneAge(0)
+	coreState := state.NewCoreDBState()
+
+	sm, err := NewStoredManifest(manifestStore, coreState)
+	assert.NoError(t, err)
+	fm, err := NewWriterFenceableManifest(sm)
+	assert.NoError(t, err)
+
+	now := time.Now()
+	prepare := func() (*state.CoreStateSnapshot, error) { return coreState.Snapshot(), nil }
+	checkpoint, err := fm.CreateCheckpoint(slog.Default(), now, time.Time{}, prepare)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(3), checkpoint.ManifestID)
+	expiring, err := fm.CreateCheckpoint(slog.Default(), now, now.Add(time.Minute), prepare)
+	assert.NoError(t, err)
+	// checkpoints are retained when the DB state is updated
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, fm.UpdateDBState(coreState.Snapshot()))
+	}
+	assert.Equal(t, []manifest.Checkpoint{checkpoint, expiring}, fm.Checkpoints())
+
+	// the manifests referenced by checkpoints are not pruned
+	_, err = manifestStore.PruneManifests(1)
+	assert.NoError(t, err)
+	manifests, err := manifestStore.ListManifests()
+	assert.NoError(t, err)
+	assert.Len(t, manifests, 3)
+	assert.Equal(t, checkpoint.ManifestID, manifests[0].ID)
+	assert.Equal(t, expiring.ManifestID, manifests[1].ID)
+
+	// an expired checkpoint is removed when a checkpoint is created
+	later, err := fm.CreateCheckpoint(slog.Default(), now.Add(time.Hour), time.Time{}, prepare)
+	assert.NoError(t, err)
+	assert.Equal(t, []manifest.Checkpoint{checkpoint, later}, fm.Checkpoints())
+
+	assert.NoError(t, fm.DeleteCheckpoint(checkpoint.ID))
+	assert.NoError(t, fm.DeleteCheckpoint(checkpoint.ID))
+	assert.Equal(t, []manifest.Checkpoint{later}, fm.Checkpoints())
+	_, err = manifestStore.PruneManifests(1)
+	assert.NoError(t, err)
+	manifests, err = manifestStore.ListManifests()
+	assert.NoError(t, err)
+	assert.Len(t, manifests, 2)
+	assert.Equal(t, later.ManifestID, manifests[0].ID)
+}
+
+func TestShouldPersistFeatures(t *testing.T) {
+	bucket := objstore.NewInMemBucket()
+	manifestStore := NewManifestStore(rootPath, bucket)
+	coreState := state.NewCoreDBState()
+
+	sm, err := NewStoredManifest(manifestStore, coreState)
+	assert.NoError(t, err)
+	fm, err := NewWriterFenceableManifest(sm)
+	assert.NoError(t, err)
+
+	assert.NoError(t, fm.EnableFeatures(manifest.FeatureFilterHash))
+	// features are retained when the DB state is updated
+	assert.NoError(t, fm.UpdateDBState(coreState.Snapshot()))
+	// enabling a feature which is already enabled does not write a new manifest
+	assert.NoError(t, fm.EnableFeatures(manifest.FeatureFilterHash))
+
+	stored, err := LoadStoredManifest(manifestStore)
+	assert.NoError(t, err)
+	loaded, ok := stored.Get()
+	assert.True(t, ok)
+	assert.Equal(t, uint64(4), loaded.id)
+	assert.Equal(t, manifest.FeatureFilterHash, loaded.Features())
+}
+
+func TestShouldUpdateLeases(t *testing.T) {
+	bucket := objstore.NewInMemBucket()
+	manifestStore := NewManifestStore(rootPath, bucket)
+	coreState := state.NewCoreDBState()
+
+	sm, err := NewStoredManifest(manifestStore, coreState)
+	assert.NoError(t, err)
+	writer, err := NewWriterFenceableManifest(sm)
+	assert.NoError(t, err)
+
+	// a reader in another process records a lease without fencing the writer
+	stored, err := LoadStoredManifest(manifestStore)
+	assert.NoError(t, err)
+	reader := stored.MustGet()
+	now := time.UnixMilli(time.Now().UnixMilli()).UTC()
+	lease := manifest.Lease{ID: ulid.Make(), SSTIDs: []ulid.ULID{ulid.Make()}, ExpireTime: now.Add(time.Minute)}
+	expired := manifest.Lease{ID: ulid.Make(), SSTIDs: []ulid.ULID{}, ExpireTime: now.Add(-time.Minute)}
+	assert.NoError(t, reader.UpdateLeases(now, func(leases []manifest.Lease) []manifest.Lease {
+		return append(leases, lease, expired)
+	}))
+	assert.Equal(t, []manifest.Lease{lease, expired}, reader.Leases())
+
+	// the lease is retained by the writer, which refreshes the manifest once its write conflicts
+	assert.NoError(t, writer.UpdateDBStateWithRetry(slog.Default(), func() (*state.CoreStateSnapshot, error) {
+		return writer.Refresh()
+	}))
+
+	// a lease update which conflicts is retried, and the expired lease is removed
+	assert.NoError(t, reader.UpdateLeases(now, func(leases []manifest.Lease) []manifest.Lease {
+		return leases
+	}))
+	stored, err = LoadStoredManifest(manifestStore)
+	assert.NoError(t, err)
+	loaded := stored.MustGet()
+	assert.Equal(t, uint64(5), loaded.ID())
+	assert.Equal(t, []manifest.Lease{lease}, loaded.Leases())
+}
+
+func TestShouldFailToLoadUnsupportedFeatures(t *testing.T) {
+	bucket := objstore.NewInMemBucket()
+	manifestStore := NewManifestStore(rootPath, bucket)
+
+	// a manifest written by a newer binary using a feature this binary does not know
+	m := &manifest.Manifest{
+		Core:     state.NewCoreDBState(),
+		Features: manifest.FeatureBlockCompressionFlags | 1<<40,
+	}
+	assert.NoError(t, manifestStore.writeManifest(1, m))
+
+	_, err := LoadStoredManifest(manifestStore)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown_40")
+	assert.NotContains(t, err.Error(), "block_compression_flags")
+}
+
+func TestShouldReadCompressedManifests(t *testing.T) {
+	bucket := objstore.NewInMemBucket()
+	manifestStore := NewManifestStore(rootPath, bucket)
+	manifestStore.SetCompression(compress.CodecZstd)
+	coreState := state.NewCoreDBState()
+
+	sm, err := NewStoredManifest(manifestStore, coreState)
+	assert.NoError(t, err)
+	fm, err := NewWriterFenceableManifest(sm)
+	assert.NoError(t, err)
+	assert.NoError(t, fm.EnableFeatures(manifest.FeatureFilterHash))
+
+	// the stored object is a header which versions without compressed manifests reject
+	data, err := manifestStore.objectStore.get(manifestStore.manifestPath(fmt.Sprintf("%020d.manifest", 3)))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(manifest.FeatureCompressedManifest), flatbuf.GetRootAsManifestV1(data, 0).Features())
+
+	// compressed and uncompressed manifests are read by any ManifestStore
+	for _, codec := range []compress.Codec{compress.CodecNone, compress.CodecSnappy} {
+		reader := NewManifestStore(rootPath, bucket)
+		reader.SetCompression(codec)
+		stored, err := LoadStoredManifest(reader)
+		assert.NoError(t, err)
+		loaded, ok := stored.Get()
+		assert.True(t, ok)
+		assert.Equal(t, manifest.FeatureFilterHash, loaded.Features())
+
+		// the compression of the manifest changes with the next manifest written
+		fm, err := NewWriterFenceableManifest(loaded)
+		assert.NoError(t, err)
+		assert.NoError(t, fm.UpdateDBState(coreState.Snapshot()))
+	}
+	stored, err := LoadStoredManifest(NewManifestStore(rootPath, bucket))
+	assert.NoError(t, err)
+	assert.True(t, stored.IsPresent())
+}
@@ -0,0 +1,21 @@
+//go:build unix
+
+package store
+
+import (
+	"os"
+	"syscall"
+)
+
+// mmapFile maps the first `size` bytes of `f` into memory. The mapping remains valid after
+// `f` is closed, until the returned unmap is called.
+func mmapFile(f *os.File, size int) ([]byte, func() error, error) {
+	if size == 0 {
+		return []byte{}, nil, nil
+	}
+	data, err := syscall.Mmap(int(f.Fd()), 0, size, syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, func() error { return syscall.Munmap(data) }, nil
+}
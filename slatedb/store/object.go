@@ -0,0 +1,190 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/slatedb/slatedb-go/slatedb/common"
+	"github.com/thanos-io/objstore"
+)
+
+// ReadOnlyObject is an immutable object, such as an SST, which can be read whole or by byte
+// range. SSTs are read through a ReadOnlyObject, such that they can be read from sources other
+// than object storage, for example from the files of a local snapshot directory.
+//
+// The implementations provided are
+//   - BucketObject, which reads an object from an objstore.Bucket
+//   - FileObject, which reads a memory mapped local file
+//   - BytesObject, which reads an in-memory byte slice
+//   - DiskCache.Object, which caches the byte ranges read from another ReadOnlyObject on local disk
+//   - uncachedObject, which fails every read, such that only the data held by a cache is read
+type ReadOnlyObject = common.ReadOnlyBlob
+
+// checkRange returns an error if `rng` is not within an object of length `size`
+func checkRange(rng common.Range, size int) error {
+	if rng.Start > rng.End || rng.End > uint64(size) {
+		return fmt.Errorf("range [%d:%d] is beyond the end of the object '%d'", rng.Start, rng.End, size)
+	}
+	return nil
+}
+
+// ------------------------------------------------
+// BucketObject
+// ------------------------------------------------
+
+// BucketObject is a ReadOnlyObject which reads the object at `path` in an objstore.Bucket
+type BucketObject struct {
+	bucket objstore.Bucket
+	path   string
+}
+
+func NewBucketObject(bucket objstore.Bucket, path string) BucketObject {
+	return BucketObject{bucket: bucket, path: path}
+}
+
+func (r BucketObject) Len(ctx context.Context) (int, error) {
+	attr, err := r.bucket.Attributes(ctx, r.path)
+	if err != nil {
+		return 0, fmt.Errorf("while fetching object attributes: %w", err)
+	}
+	return int(attr.Size), nil
+}
+
+func (r BucketObject) ReadRange(ctx context.Context, rng common.Range) ([]byte, error) {
+	read, err := r.bucket.GetRange(ctx, r.path, int64(rng.Start), int64(rng.End-rng.Start))
+	if err != nil {
+		return nil, fmt.Errorf("while fetching object range [%d:%d]: %w", rng.Start, rng.End-rng.Start, err)
+	}
+
+	data, err := io.ReadAll(read)
+	if err != nil {
+		return nil, fmt.Errorf("while reading object [%d:%d]: %w", rng.Start, rng.End, err)
+	}
+
+	return data, nil
+}
+
+func (r BucketObject) Read(ctx context.Context) ([]byte, error) {
+	read, err := r.bucket.Get(ctx, r.path)
+	if err != nil {
+		return nil, fmt.Errorf("while fetching object '%s': %w", r.path, err)
+	}
+
+	data, err := io.ReadAll(read)
+	if err != nil {
+		return nil, fmt.Errorf("while reading object '%s': %w", r.path, err)
+	}
+
+	return data, nil
+}
+
+// ------------------------------------------------
+// FileObject
+// ------------------------------------------------
+
+// FileObject is a ReadOnlyObject which reads a local file. The file is memory mapped where the
+// platform supports it, and read into memory otherwise. The file must not be modified while it
+// is open, and the slices returned by ReadRange and Read must not be used after Close.
+type FileObject struct {
+	data  []byte
+	unmap func() error
+}
+
+// OpenFileObject opens the file at `path` for reading. The caller must Close the FileObject once
+// it is no longer read.
+func OpenFileObject(path string) (*FileObject, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("while opening file '%s': %w", path, err)
+	}
+	defer func() { _ = f.Close() }()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("while reading attributes of file '%s': %w", path, err)
+	}
+	data, unmap, err := mmapFile(f, int(info.Size()))
+	if err != nil {
+		return nil, fmt.Errorf("while mapping file '%s': %w", path, err)
+	}
+	return &FileObject{data: data, unmap: unmap}, nil
+}
+
+func (f *FileObject) Len(_ context.Context) (int, error) {
+	return len(f.data), nil
+}
+
+func (f *FileObject) ReadRange(_ context.Context, rng common.Range) ([]byte, error) {
+	if err := checkRange(rng, len(f.data)); err != nil {
+		return nil, err
+	}
+	return f.data[rng.Start:rng.End], nil
+}
+
+func (f *FileObject) Read(_ context.Context) ([]byte, error) {
+	return f.data, nil
+}
+
+// Close unmaps the file
+func (f *FileObject) Close() error {
+	if f.unmap == nil {
+		return nil
+	}
+	err := f.unmap()
+	f.data, f.unmap = nil, nil
+	return err
+}
+
+// ------------------------------------------------
+// BytesObject
+// ------------------------------------------------
+
+// BytesObject is a ReadOnlyObject which reads an in-memory byte slice
+type BytesObject struct {
+	data []byte
+}
+
+func NewBytesObject(data []byte) BytesObject {
+	return BytesObject{data: data}
+}
+
+func (b BytesObject) Len(_ context.Context) (int, error) {
+	return len(b.data), nil
+}
+
+func (b BytesObject) ReadRange(_ context.Context, rng common.Range) ([]byte, error) {
+	if err := checkRange(rng, len(b.data)); err != nil {
+		return nil, err
+	}
+	return b.data[rng.Start:rng.End], nil
+}
+
+func (b BytesObject) Read(_ context.Context) ([]byte, error) {
+	return b.data, nil
+}
+
+// ------------------------------------------------
+// uncachedObject
+// ------------------------------------------------
+
+// errNotCached is returned by the reads of a TableStore returned by CachedOnly which are not held by a cache
+var errNotCached = errors.New("not cached")
+
+// uncachedObject is a ReadOnlyObject which fails every read with errNotCached. It is read in place
+// of object storage by a TableStore returned by CachedOnly, such that reads are only served by the caches.
+type uncachedObject struct{}
+
+func (uncachedObject) Len(_ context.Context) (int, error) {
+	return 0, errNotCached
+}
+
+func (uncachedObject) ReadRange(_ context.Context, _ common.Range) ([]byte, error) {
+	return nil, errNotCached
+}
+
+func (uncachedObject) Read(_ context.Context) ([]byte, error) {
+	return nil, errNotCached
+}
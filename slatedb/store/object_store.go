@@ -3,6 +3,7 @@ package store
 import (
 	"bytes"
 	"context"
+	"errors"
 	"io"
 	"path"
 	"slices"
@@ -22,11 +23,38 @@ type ObjectMeta struct {
 }
 
 type ObjectStore interface {
+	put(path string, data []byte) error
+
 	putIfNotExists(path string, data []byte) error
 
 	get(path string) ([]byte, error)
 
 	list(path mo.Option[string]) ([]ObjectMeta, error)
+
+	delete(path string) error
+}
+
+// errObjectNotFound is returned by ObjectStore.get when the object does not exist
+var errObjectNotFound = errors.New("object not found in store")
+
+// ConditionalBucket is an objstore.Bucket which can upload an object only if no object with the
+// same name exists, as a single atomic request. Such as a PutObject with `If-None-Match: *` on S3,
+// a write with the `ifGenerationMatch=0` precondition on GCS, or an upload with `If-None-Match: *`
+// on Azure Blob Storage.
+//
+// A manifest version, or a lease, is written only if it does not already exist. If the bucket
+// is a ConditionalBucket the write is a single conditional upload. Otherwise, the existence of
+// the object is checked before it is uploaded, and two writers which race to write the same
+// manifest version may both succeed, with the second overwriting the first.
+//
+// The s3bucket package adapts an S3 bucket to a ConditionalBucket. The buckets of other providers
+// must be adapted by the caller.
+type ConditionalBucket interface {
+	objstore.Bucket
+
+	// UploadIfNotExists uploads the contents of `r` as the object `name`, and returns true, if
+	// no object named `name` exists. If the object exists, it is not modified and false is returned.
+	UploadIfNotExists(ctx context.Context, name string, r io.Reader) (bool, error)
 }
 
 type DelegatingObjectStore struct {
@@ -38,9 +66,28 @@ func newDelegatingObjectStore(rootPath string, bucket objstore.Bucket) *Delegati
 	return &DelegatingObjectStore{rootPath, bucket}
 }
 
-// TODO: We should make this atomic
+func (d *DelegatingObjectStore) put(objPath string, data []byte) error {
+	fullPath := path.Join(d.rootPath, objPath)
+	err := d.bucket.Upload(context.Background(), fullPath, bytes.NewReader(data))
+	if err != nil {
+		return internal.ErrRetryable("during bucket upload: %s", err)
+	}
+	return nil
+}
+
 func (d *DelegatingObjectStore) putIfNotExists(objPath string, data []byte) error {
 	fullPath := path.Join(d.rootPath, objPath)
+	if cb, ok := d.bucket.(ConditionalBucket); ok {
+		uploaded, err := cb.UploadIfNotExists(context.Background(), fullPath, bytes.NewReader(data))
+		if err != nil {
+			return internal.ErrRetryable("during bucket conditional upload: %s", err)
+		}
+		if !uploaded {
+			return internal.ErrAlreadyExists
+		}
+		return nil
+	}
+
 	exists, err := d.bucket.Exists(context.Background(), fullPath)
 	if err != nil {
 		return internal.ErrRetryable("during bucket exists check: %s", err)
@@ -60,6 +107,9 @@ func (d *DelegatingObjectStore) get(objPath string) ([]byte, error) {
 	fullPath := path.Join(d.rootPath, objPath)
 	reader, err := d.bucket.Get(context.Background(), fullPath)
 	if err != nil {
+		if d.bucket.IsObjNotFoundErr(err) {
+			return nil, errObjectNotFound
+		}
 		return nil, internal.ErrRetryable("during bucket get: %s", err)
 	}
 
@@ -78,8 +128,14 @@ func (d *DelegatingObjectStore) list(objPath mo.Option[string]) ([]ObjectMeta, e
 	}
 
 	objMetaList := make([]ObjectMeta, 0)
+	// The indexes of the objects whose LastModified was not listed, such as those of an
+	// in-memory bucket, which is read from the attributes of each object instead
+	var unlisted []int
 	iterFn := func(attrs objstore.IterObjectAttributes) error {
-		lastModified, _ := attrs.LastModified()
+		lastModified, ok := attrs.LastModified()
+		if !ok {
+			unlisted = append(unlisted, len(objMetaList))
+		}
 		objMetaList = append(objMetaList, ObjectMeta{lastModified, attrs.Name})
 		return nil
 	}
@@ -88,9 +144,29 @@ func (d *DelegatingObjectStore) list(objPath mo.Option[string]) ([]ObjectMeta, e
 		return nil, internal.ErrRetryable("during bucket listing: %s", err)
 	}
 
+	for _, i := range unlisted {
+		attrs, err := d.bucket.Attributes(context.Background(), objMetaList[i].Location)
+		if err != nil {
+			// An object which was deleted after it was listed keeps a zero LastModified
+			if d.bucket.IsObjNotFoundErr(err) {
+				continue
+			}
+			return nil, internal.ErrRetryable("while reading object attributes: %s", err)
+		}
+		objMetaList[i].LastModified = attrs.LastModified
+	}
 	return objMetaList, nil
 }
 
+func (d *DelegatingObjectStore) delete(objPath string) error {
+	fullPath := path.Join(d.rootPath, objPath)
+	err := d.bucket.Delete(context.Background(), fullPath)
+	if err != nil && !d.bucket.IsObjNotFoundErr(err) {
+		return internal.ErrRetryable("during bucket delete: %s", err)
+	}
+	return nil
+}
+
 // objStoreIterOptions gets IterOptions supported by the storage provider
 func objStoreIterOptions(bucket objstore.Bucket) []objstore.IterOption {
 	iterOptions := make([]objstore.IterOption, 0)
@@ -3,9 +3,12 @@ package store
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"io"
 	"path"
 	"sort"
+	"sync"
+	"sync/atomic"
 	"testing"
 
 	"github.com/samber/mo"
@@ -43,6 +46,20 @@ func TestDelegatingShouldGetPut(t *testing.T) {
 	assert.True(t, bytes.Equal([]byte("data1"), data))
 }
 
+func TestDelegatingShouldOverwritePut(t *testing.T) {
+	bucket := objstore.NewInMemBucket()
+	store := newDelegatingObjectStore(rootPath, bucket)
+
+	err := store.put("obj", []byte("data1"))
+	assert.NoError(t, err)
+	err = store.put("obj", []byte("data2"))
+	assert.NoError(t, err)
+
+	data, err := store.get("obj")
+	assert.NoError(t, err)
+	assert.True(t, bytes.Equal([]byte("data2"), data))
+}
+
 func TestDelegatingShouldList(t *testing.T) {
 	bucket := objstore.NewInMemBucket()
 	store := newDelegatingObjectStore(rootPath, bucket)
@@ -80,3 +97,63 @@ func TestDelegatingShouldPutWithPrefix(t *testing.T) {
 	data, _ := io.ReadAll(result)
 	assert.Equal(t, []byte("data1"), data)
 }
+
+func TestDelegatingShouldDelete(t *testing.T) {
+	bucket := objstore.NewInMemBucket()
+	store := newDelegatingObjectStore(rootPath, bucket)
+
+	err := store.putIfNotExists("obj", []byte("data1"))
+	assert.NoError(t, err)
+
+	assert.NoError(t, store.delete("obj"))
+	_, err = store.get("obj")
+	assert.ErrorIs(t, err, errObjectNotFound)
+
+	// deleting an object which does not exist is not an error
+	assert.NoError(t, store.delete("obj"))
+}
+
+// conditionalBucket is an in-memory ConditionalBucket which counts the existence checks
+type conditionalBucket struct {
+	*objstore.InMemBucket
+	mu     sync.Mutex
+	exists atomic.Int64
+}
+
+func (b *conditionalBucket) Exists(ctx context.Context, name string) (bool, error) {
+	b.exists.Add(1)
+	return b.InMemBucket.Exists(ctx, name)
+}
+
+func (b *conditionalBucket) UploadIfNotExists(ctx context.Context, name string, r io.Reader) (bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, err := b.InMemBucket.Attributes(ctx, name); err == nil {
+		return false, nil
+	}
+	return true, b.InMemBucket.Upload(ctx, name, r)
+}
+
+func TestDelegatingShouldPutIfNotExistsWithConditionalUpload(t *testing.T) {
+	bucket := &conditionalBucket{InMemBucket: objstore.NewInMemBucket()}
+	store := newDelegatingObjectStore(rootPath, bucket)
+
+	// of the writers racing to write the same object, exactly one succeeds
+	var wg sync.WaitGroup
+	var written atomic.Int64
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := store.putIfNotExists("obj", []byte(fmt.Sprintf("data%d", i)))
+			if err == nil {
+				written.Add(1)
+			} else {
+				assert.ErrorIs(t, err, internal.ErrAlreadyExists)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int64(1), written.Load())
+	assert.Zero(t, bucket.exists.Load())
+}
@@ -0,0 +1,40 @@
+package store
+
+import (
+	"errors"
+	"path"
+
+	"github.com/samber/mo"
+	"github.com/thanos-io/objstore"
+)
+
+const statsDir = "stats"
+
+// StatsStore persists database statistics to object storage. Statistics are advisory,
+// so unlike manifests each write replaces the previous version of the object.
+type StatsStore struct {
+	objectStore ObjectStore
+}
+
+func NewStatsStore(rootPath string, bucket objstore.Bucket) *StatsStore {
+	return &StatsStore{
+		objectStore: newDelegatingObjectStore(rootPath, bucket),
+	}
+}
+
+// Write replaces the statistics object with the provided name
+func (s *StatsStore) Write(name string, data []byte) error {
+	return s.objectStore.put(path.Join(statsDir, name), data)
+}
+
+// Read returns the statistics object with the provided name, or mo.None if it has never been written
+func (s *StatsStore) Read(name string) (mo.Option[[]byte], error) {
+	data, err := s.objectStore.get(path.Join(statsDir, name))
+	if err != nil {
+		if errors.Is(err, errObjectNotFound) {
+			return mo.None[[]byte](), nil
+		}
+		return mo.None[[]byte](), err
+	}
+	return mo.Some(data), nil
+}
@@ -2,24 +2,36 @@ package store
 
 import (
 	"bytes"
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"math"
 	"path"
 	"slices"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/maypok86/otter"
+	"github.com/oklog/ulid/v2"
 	"github.com/samber/mo"
 	"github.com/slatedb/slatedb-go/internal"
 	"github.com/slatedb/slatedb-go/internal/assert"
+	"github.com/slatedb/slatedb-go/internal/compress"
+	"github.com/slatedb/slatedb-go/internal/profile"
 	"github.com/slatedb/slatedb-go/internal/sstable"
 	"github.com/slatedb/slatedb-go/internal/sstable/block"
 	"github.com/slatedb/slatedb-go/internal/sstable/bloom"
+	"github.com/slatedb/slatedb-go/internal/tracing"
+	"github.com/slatedb/slatedb-go/internal/types"
 	"github.com/slatedb/slatedb-go/slatedb/common"
+	"github.com/slatedb/slatedb-go/slatedb/manifest"
 	"github.com/thanos-io/objstore"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // ------------------------------------------------
@@ -35,19 +47,273 @@ type TableStore struct {
 	walPath       string
 	compactedPath string
 	filterCache   otter.Cache[sstable.ID, mo.Option[bloom.Filter]]
+
+	// bytesWritten is the total number of SST bytes uploaded to object storage.
+	// It is shared with any clones of this TableStore.
+	bytesWritten *atomic.Uint64
+
+	// filterFetches limits the number of concurrent filter fetches made by TryReadFilter.
+	// It is nil if fetches are not limited, and is shared with any clones of this TableStore.
+	filterFetches chan struct{}
+
+	// externalPaths maps the ID of each compacted SST held by an external database, such as
+	// the source of a clone, to the root path of that database. See SetExternalDBs
+	externalPaths map[string]string
+
+	// blockCache caches the data blocks read by ReadBlocks and ReadBlocksUsingIndex. It is
+	// nil if blocks are not cached, and is shared with any clones of this TableStore.
+	blockCache *blockCache
+
+	// diskCache caches the byte ranges read from compacted SSTs on local disk. It is nil if
+	// ranges are not cached, and is shared with any clones of this TableStore.
+	diskCache *DiskCache
+
+	// filterSidecars holds the IDs of the compacted SSTs whose filter is read from a sidecar
+	// filter object rather than from the SST. It is shared with any clones of this TableStore.
+	filterSidecars *sync.Map
+
+	// quarantine holds the IDs of the quarantined SSTs. It is shared with any clones of this
+	// TableStore. See SetQuarantined
+	quarantine *quarantineSet
+
+	// levelCodecs overrides the compression codec of sstConfig for the SSTs written at a level.
+	// See SetLevelCompression
+	levelCodecs map[SSTLevel]compress.Codec
+
+	// keyspaceCodecs overrides the codec of the level for the L0 and compacted SSTs of a keyspace.
+	// See SetKeyspaceCompression
+	keyspaceCodecs map[string]compress.Codec
+
+	// filterPartitionBlocks is the sstable.Config.FilterPartitionBlocks of the SSTs written by
+	// compaction. See SetFilterPartitions
+	filterPartitionBlocks uint32
+
+	// inlineValueBytes is the sstable.Config.InlineValueBytes of the SSTs written at L0 and by
+	// compaction. See SetInlineValues
+	inlineValueBytes uint32
+
+	// walEntryChecksums is the sstable.Config.EntryChecksums of the SSTs of the WAL. See
+	// SetWALEntryChecksums
+	walEntryChecksums bool
+
+	// filterIndexCache and filterPartitionCache cache the filter indexes and filter partitions
+	// of SSTs with a partitioned filter. See TryReadFilterForKey
+	filterIndexCache     otter.Cache[sstable.ID, *sstable.FilterIndex]
+	filterPartitionCache otter.Cache[filterPartitionKey, bloom.Filter]
+
+	// hedge, if not nil, hedges and limits the range reads of SSTs from object storage, and
+	// pointHedge is the hedge of the clones returned by PointReads. See SetHedgedReads
+	hedge      *hedgedReads
+	pointHedge *hedgedReads
+
+	// writeBufferSize is the number of bytes of blocks an EncodedSSTableWriter buffers before
+	// they are streamed to object storage. See SetWriteBufferSize
+	writeBufferSize uint64
+	// streamUploads is false if the bucket holds a lock while it reads an upload, see locksDuringUpload
+	streamUploads bool
+
+	// readAheadBytes is the number of bytes of adjacent blocks an sstable.Iterator reads with a
+	// single request. It is zero for the clones returned by PointReads. See SetReadAhead
+	readAheadBytes uint64
+
+	// prefetchConcurrency is the number of requests an sstable.Iterator has in flight for the
+	// blocks after those being iterated, see SetPrefetchConcurrency
+	prefetchConcurrency int
+
+	// cachedOnly is true if reads are only served by the caches, see CachedOnly
+	cachedOnly bool
+
+	// throughput is true if blocks are streamed from object storage, see Throughput
+	throughput bool
+}
+
+// DefaultWriteBufferSize is the number of bytes of blocks an EncodedSSTableWriter buffers by default
+const DefaultWriteBufferSize = 8 * 1024 * 1024
+
+// ThroughputReadAheadBytes is the number of bytes of blocks read with each request by the clones
+// returned by Throughput, such that most SSTs are read with a single request
+const ThroughputReadAheadBytes = 64 * 1024 * 1024
+
+type filterPartitionKey struct {
+	sstID     sstable.ID
+	partition int
 }
 
+// filterPartitionCacheSize is the number of filter partitions cached by a TableStore
+const filterPartitionCacheSize = 10000
+
+func newFilterCaches() (otter.Cache[sstable.ID, *sstable.FilterIndex], otter.Cache[filterPartitionKey, bloom.Filter]) {
+	indexCache, err := otter.MustBuilder[sstable.ID, *sstable.FilterIndex](1000).Build()
+	assert.True(err == nil, "")
+	partitionCache, err := otter.MustBuilder[filterPartitionKey, bloom.Filter](filterPartitionCacheSize).Build()
+	assert.True(err == nil, "")
+	return indexCache, partitionCache
+}
+
+// SSTLevel is the part of the database an SST is written to
+type SSTLevel int
+
+const (
+	// SSTLevelWAL is the level of the SSTs of the WAL
+	SSTLevelWAL SSTLevel = iota
+
+	// SSTLevelL0 is the level of the SSTs flushed from the memtable or ingested to L0
+	SSTLevelL0
+
+	// SSTLevelCompacted is the level of the SSTs of the sorted runs written by compaction
+	SSTLevelCompacted
+)
+
 func NewTableStore(bucket objstore.Bucket, sstConfig sstable.Config, rootPath string) *TableStore {
 	cache, err := otter.MustBuilder[sstable.ID, mo.Option[bloom.Filter]](1000).Build()
 	assert.True(err == nil, "")
+	indexCache, partitionCache := newFilterCaches()
 	return &TableStore{
-		bucket:        bucket,
-		sstConfig:     sstConfig,
-		rootPath:      rootPath,
-		walPath:       "wal",
-		compactedPath: "compacted",
-		filterCache:   cache,
+		bucket:               bucket,
+		sstConfig:            sstConfig,
+		rootPath:             rootPath,
+		walPath:              "wal",
+		compactedPath:        "compacted",
+		filterCache:          cache,
+		bytesWritten:         &atomic.Uint64{},
+		filterSidecars:       &sync.Map{},
+		quarantine:           &quarantineSet{},
+		filterIndexCache:     indexCache,
+		filterPartitionCache: partitionCache,
+		writeBufferSize:      DefaultWriteBufferSize,
+		streamUploads:        !locksDuringUpload(bucket),
+	}
+}
+
+// locksDuringUpload returns true if the bucket, such as the in-memory bucket, holds a lock which
+// its other operations acquire while it reads an upload. An SST is not streamed to such a bucket,
+// as the writer of the SST, such as a compaction, may read from the bucket while the SST is written.
+func locksDuringUpload(bucket objstore.Bucket) bool {
+	for {
+		switch b := bucket.(type) {
+		case *objstore.InMemBucket:
+			return true
+		case interface{ Unwrap() objstore.Bucket }:
+			bucket = b.Unwrap()
+		default:
+			return false
+		}
+	}
+}
+
+// SetReadAhead sets the number of bytes of adjacent blocks read with a single request by the
+// iterators of SSTs, such as those of scans and compactions. If sizeBytes is zero, iterators
+// read one block per request.
+func (ts *TableStore) SetReadAhead(sizeBytes uint64) {
+	ts.readAheadBytes = sizeBytes
+}
+
+// ReadAheadBytes returns the number of bytes of adjacent blocks read with a single request, see SetReadAhead
+func (ts *TableStore) ReadAheadBytes() uint64 {
+	return ts.readAheadBytes
+}
+
+// SetPrefetchConcurrency sets the number of requests the iterators of SSTs have in flight for
+// the blocks after the blocks being iterated, such that the latency of object storage overlaps
+// with the processing of the blocks. Each request reads up to ReadAheadBytes of adjacent blocks.
+// If concurrency is zero, blocks are read when the iteration reaches them.
+func (ts *TableStore) SetPrefetchConcurrency(concurrency int) {
+	ts.prefetchConcurrency = max(concurrency, 0)
+}
+
+// PrefetchConcurrency returns the number of block requests iterators have in flight, see SetPrefetchConcurrency
+func (ts *TableStore) PrefetchConcurrency() int {
+	return ts.prefetchConcurrency
+}
+
+// SetWriteBufferSize sets the number of bytes of blocks an EncodedSSTableWriter buffers in
+// memory. Once the buffer is full, the blocks of the SST are streamed to object storage. If
+// sizeBytes is zero, DefaultWriteBufferSize is used.
+func (ts *TableStore) SetWriteBufferSize(sizeBytes uint64) {
+	if sizeBytes == 0 {
+		sizeBytes = DefaultWriteBufferSize
+	}
+	ts.writeBufferSize = sizeBytes
+}
+
+// LimitFilterFetches caps the number of filters TryReadFilter fetches from object storage
+// at once. If max is zero or less, filter fetches are not limited.
+func (ts *TableStore) LimitFilterFetches(max int) {
+	if max <= 0 {
+		ts.filterFetches = nil
+		return
+	}
+	ts.filterFetches = make(chan struct{}, max)
+}
+
+// SetBlockCache caches up to sizeBytes of SST data blocks. If compressed is true, blocks
+// are cached as they are stored in object storage and decoded on every read. If sizeBytes
+// is zero, blocks are not cached.
+func (ts *TableStore) SetBlockCache(sizeBytes uint64, compressed bool) {
+	if sizeBytes == 0 {
+		ts.blockCache = nil
+		return
+	}
+	ts.blockCache = newBlockCache(sizeBytes, compressed)
+}
+
+// SetDiskCache caches the byte ranges read from compacted (L0 and sorted run) SSTs in `dir`,
+// evicting the least recently used ranges once more than sizeBytes are cached. Ranges cached by
+// a previous process are reused, such that reads after a restart are served from disk. WAL SSTs
+// are not cached as they are only read to replay the WAL. If `dir` is empty, ranges are not cached.
+func (ts *TableStore) SetDiskCache(dir string, sizeBytes uint64) error {
+	if dir == "" {
+		ts.diskCache = nil
+		return nil
+	}
+	cache, err := NewDiskCache(dir, sizeBytes)
+	if err != nil {
+		return err
+	}
+	ts.diskCache = cache
+	return nil
+}
+
+// object returns the object of the SST `id`, which is read through the disk cache if it is set
+func (ts *TableStore) object(id sstable.ID) ReadOnlyObject {
+	path := ts.sstPath(id)
+	var obj ReadOnlyObject = NewBucketObject(ts.bucket, path)
+	if ts.cachedOnly {
+		obj = uncachedObject{}
+	} else if ts.hedge != nil {
+		obj = ts.hedge.object(obj)
+	}
+	if ts.diskCache == nil || id.Type != sstable.Compacted {
+		return obj
+	}
+	return ts.diskCache.Object(path, obj)
+}
+
+// BlockCacheStats returns the hits and misses of the block cache. The stats are zero
+// if the block cache is disabled.
+func (ts *TableStore) BlockCacheStats() BlockCacheStats {
+	if ts.blockCache == nil {
+		return BlockCacheStats{}
 	}
+	return ts.blockCache.stats()
+}
+
+// TrimCaches evicts blocks from the block cache until it holds at most floorBytes of blocks,
+// and releases the cached filters, filter indexes and filter partitions. Evicted blocks and
+// filters are read again from object storage when they are next needed.
+func (ts *TableStore) TrimCaches(floorBytes uint64) {
+	if ts.blockCache != nil {
+		ts.blockCache.trim(floorBytes)
+	}
+	ts.filterCache.Clear()
+	ts.filterIndexCache.Clear()
+	ts.filterPartitionCache.Clear()
+}
+
+// BytesWritten returns the total number of SST bytes (WAL, L0 and compacted) this
+// TableStore has uploaded to object storage.
+func (ts *TableStore) BytesWritten() uint64 {
+	return ts.bytesWritten.Load()
 }
 
 // Get list of WALs from object store that are not compacted (walID greater than walIDLastCompacted)
@@ -72,11 +338,153 @@ func (ts *TableStore) GetWalSSTList(walIDLastCompacted uint64) ([]uint64, error)
 	return walList, nil
 }
 
+// TableObject is a WAL SST, compacted SST or filter sidecar stored under the root path of a
+// TableStore, see ListObjects
+type TableObject struct {
+	// ID is the ID of the SST, or of the compacted SST the filter sidecar belongs to
+	ID sstable.ID
+
+	// Sidecar is true if the object is the filter sidecar of the compacted SST ID
+	Sidecar bool
+
+	// LastModified is the time the object was written
+	LastModified time.Time
+}
+
+// ListObjects lists the WAL SSTs, compacted SSTs and filter sidecars stored under the root path
+// of the TableStore. The SSTs of external databases are not listed, and objects which are not
+// named like an SST or a filter sidecar are skipped.
+func (ts *TableStore) ListObjects(ctx context.Context) ([]TableObject, error) {
+	wal, err := ts.listObjects(ctx, sstable.WAL)
+	if err != nil {
+		return nil, fmt.Errorf("while listing wal ssts: %w", err)
+	}
+	compacted, err := ts.listObjects(ctx, sstable.Compacted)
+	if err != nil {
+		return nil, fmt.Errorf("while listing compacted ssts: %w", err)
+	}
+	return append(wal, compacted...), nil
+}
+
+// ListWALObjects lists the WAL SSTs with an ID greater than `afterID`, in the order of their IDs
+func (ts *TableStore) ListWALObjects(ctx context.Context, afterID uint64) ([]TableObject, error) {
+	objects, err := ts.listObjects(ctx, sstable.WAL)
+	if err != nil {
+		return nil, fmt.Errorf("while listing wal ssts: %w", err)
+	}
+	objects = slices.DeleteFunc(objects, func(obj TableObject) bool {
+		return obj.ID.WalID().MustGet() <= afterID
+	})
+	slices.SortFunc(objects, func(a, b TableObject) int {
+		return cmp.Compare(a.ID.WalID().MustGet(), b.ID.WalID().MustGet())
+	})
+	return objects, nil
+}
+
+// listObjects lists the objects of the SSTs of `idType` along with their filter sidecars
+func (ts *TableStore) listObjects(ctx context.Context, idType sstable.IDType) ([]TableObject, error) {
+	objects := make([]TableObject, 0)
+	// The names of the objects whose LastModified was not listed, such as those of an in-memory
+	// bucket, which are read from the attributes of each object once the listing is complete
+	unlisted := make(map[int]string)
+	dir := ts.compactedPath
+	if idType == sstable.WAL {
+		dir = ts.walPath
+	}
+	err := ts.bucket.IterWithAttributes(ctx, path.Join(ts.rootPath, dir),
+		func(attrs objstore.IterObjectAttributes) error {
+			base := path.Base(attrs.Name)
+			ext := path.Ext(base)
+			obj := TableObject{Sidecar: ext == ".filter"}
+			if ext != ".sst" && !(obj.Sidecar && idType == sstable.Compacted) {
+				return nil
+			}
+			name := strings.TrimSuffix(base, ext)
+			if idType == sstable.WAL {
+				id, err := strconv.ParseUint(name, 10, 64)
+				if err != nil {
+					return nil
+				}
+				obj.ID = sstable.NewIDWal(id)
+			} else {
+				id, err := ulid.ParseStrict(name)
+				if err != nil {
+					return nil
+				}
+				obj.ID = sstable.NewIDCompacted(id)
+			}
+			lastModified, ok := attrs.LastModified()
+			if ok {
+				obj.LastModified = lastModified
+			} else {
+				unlisted[len(objects)] = attrs.Name
+			}
+			objects = append(objects, obj)
+			return nil
+		}, objStoreIterOptions(ts.bucket)...)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, name := range unlisted {
+		attrs, err := ts.bucket.Attributes(ctx, name)
+		if err != nil {
+			if ts.bucket.IsObjNotFoundErr(err) {
+				// The object was deleted after it was listed. It is listed with a zero
+				// LastModified, and deleting it again is not an error
+				continue
+			}
+			return nil, fmt.Errorf("while reading attributes of '%s': %w", name, err)
+		}
+		objects[i].LastModified = attrs.LastModified
+	}
+	return objects, nil
+}
+
+// DeleteObject deletes `obj` listed by ListObjects. An object which no longer exists is not an error
+func (ts *TableStore) DeleteObject(ctx context.Context, obj TableObject) error {
+	dir, ext := ts.compactedPath, ".sst"
+	if obj.ID.Type == sstable.WAL {
+		dir = ts.walPath
+	}
+	if obj.Sidecar {
+		ext = ".filter"
+	}
+	// The path is not resolved with sstPath, such that an SST of an external database which
+	// has the same ID is never deleted
+	err := ts.bucket.Delete(ctx, path.Join(ts.rootPath, dir, obj.ID.Value+ext))
+	if err != nil && !ts.bucket.IsObjNotFoundErr(err) {
+		return fmt.Errorf("while deleting '%s': %w", obj.ID.Value+ext, err)
+	}
+	return nil
+}
+
 func (ts *TableStore) TableWriter(sstID sstable.ID) *EncodedSSTableWriter {
+	return ts.newTableWriter(sstID, ts.sstConfig)
+}
+
+// TableWriterAtLevel returns a writer of an SST which is compressed with the codec of `level`.
+// See SetLevelCompression
+func (ts *TableStore) TableWriterAtLevel(sstID sstable.ID, level SSTLevel) *EncodedSSTableWriter {
+	return ts.newTableWriter(sstID, ts.levelConfig(level))
+}
+
+// TableWriterForKeyspace returns a writer of an SST of the keys of `keyspace` at `level`, which is
+// compressed with the codec of the keyspace if one is set. See SetKeyspaceCompression
+func (ts *TableStore) TableWriterForKeyspace(sstID sstable.ID, level SSTLevel, keyspace string) *EncodedSSTableWriter {
+	return ts.newTableWriter(sstID, ts.keyspaceConfig(level, keyspace))
+}
+
+func (ts *TableStore) newTableWriter(sstID sstable.ID, conf sstable.Config) *EncodedSSTableWriter {
+	bufferSize := ts.writeBufferSize
+	if !ts.streamUploads {
+		bufferSize = math.MaxUint64
+	}
 	return &EncodedSSTableWriter{
-		builder:       sstable.NewBuilder(ts.sstConfig),
+		builder:       sstable.NewBuilder(conf),
 		sstID:         sstID,
 		tableStore:    ts,
+		bufferSize:    bufferSize,
 		blocksWritten: 0,
 	}
 }
@@ -85,6 +493,91 @@ func (ts *TableStore) TableBuilder() *sstable.Builder {
 	return sstable.NewBuilder(ts.sstConfig)
 }
 
+// TableBuilderAtLevel returns a builder of an SST which is compressed with the codec of `level`.
+// See SetLevelCompression
+func (ts *TableStore) TableBuilderAtLevel(level SSTLevel) *sstable.Builder {
+	return sstable.NewBuilder(ts.levelConfig(level))
+}
+
+// TableBuilderForKeyspace returns a builder of an SST of the keys of `keyspace` at `level`, which
+// is compressed with the codec of the keyspace if one is set. See SetKeyspaceCompression
+func (ts *TableStore) TableBuilderForKeyspace(level SSTLevel, keyspace string) *sstable.Builder {
+	return sstable.NewBuilder(ts.keyspaceConfig(level, keyspace))
+}
+
+// SetLevelCompression compresses the SSTs written at `level` with `codec` rather than the
+// codec of the sstable.Config. The compression level of the sstable.Config is used if the
+// codec supports it. It must be called before the TableStore or any of its clones are used.
+func (ts *TableStore) SetLevelCompression(level SSTLevel, codec compress.Codec) {
+	if ts.levelCodecs == nil {
+		ts.levelCodecs = make(map[SSTLevel]compress.Codec)
+	}
+	ts.levelCodecs[level] = codec
+}
+
+// SetKeyspaceCompression compresses the L0 and compacted SSTs of `keyspace` with `codec` rather
+// than the codec of their level, see SetLevelCompression. The SSTs of the WAL hold the keys of
+// every keyspace and are compressed with the codec of SSTLevelWAL. It must be called before the
+// TableStore or any of its clones are used.
+func (ts *TableStore) SetKeyspaceCompression(keyspace string, codec compress.Codec) {
+	if ts.keyspaceCodecs == nil {
+		ts.keyspaceCodecs = make(map[string]compress.Codec)
+	}
+	ts.keyspaceCodecs[keyspace] = codec
+}
+
+// SetFilterPartitions partitions the filters of the SSTs written at SSTLevelCompacted into a filter
+// of each `blocks` data blocks, see sstable.Config.FilterPartitionBlocks. The filters of the SSTs of
+// the WAL and L0 are not partitioned. It must be called before the TableStore or any of its clones
+// are used.
+func (ts *TableStore) SetFilterPartitions(blocks uint32) {
+	ts.filterPartitionBlocks = blocks
+}
+
+// SetInlineValues inlines the values of at most `bytes` into the index of the SSTs written at
+// SSTLevelL0 and SSTLevelCompacted, see sstable.Config.InlineValueBytes. The SSTs of the WAL are
+// not read by point reads, so their values are not inlined. It must be called before the
+// TableStore or any of its clones are used.
+func (ts *TableStore) SetInlineValues(bytes uint32) {
+	ts.inlineValueBytes = bytes
+}
+
+// SetWALEntryChecksums encodes each entry of the SSTs written at SSTLevelWAL with a checksum of
+// the entry, see sstable.Config.EntryChecksums. It must be called before the TableStore or any of
+// its clones are used.
+func (ts *TableStore) SetWALEntryChecksums(enabled bool) {
+	ts.walEntryChecksums = enabled
+}
+
+// levelConfig returns the sstable.Config of the SSTs written at `level`
+func (ts *TableStore) levelConfig(level SSTLevel) sstable.Config {
+	return ts.keyspaceConfig(level, "")
+}
+
+// keyspaceConfig returns the sstable.Config of the SSTs of `keyspace` written at `level`, which
+// is the config of the level if the keyspace has no codec of its own
+func (ts *TableStore) keyspaceConfig(level SSTLevel, keyspace string) sstable.Config {
+	conf := ts.sstConfig
+	if level == SSTLevelCompacted {
+		conf.FilterPartitionBlocks = ts.filterPartitionBlocks
+	}
+	if level != SSTLevelWAL {
+		conf.InlineValueBytes = ts.inlineValueBytes
+	} else {
+		conf.EntryChecksums = ts.walEntryChecksums
+	}
+	if codec, ok := ts.levelCodecs[level]; ok {
+		conf.Compression = codec
+	}
+	if codec, ok := ts.keyspaceCodecs[keyspace]; ok && level != SSTLevelWAL {
+		conf.Compression = codec
+	}
+	if !compress.ValidLevel(conf.Compression, conf.CompressionLevel) {
+		conf.CompressionLevel = 0
+	}
+	return conf
+}
+
 func (ts *TableStore) WriteSST(ctx context.Context, id sstable.ID, encodedSST *sstable.Table) (*sstable.Handle, error) {
 	sstPath := ts.sstPath(id)
 
@@ -97,13 +590,14 @@ func (ts *TableStore) WriteSST(ctx context.Context, id sstable.ID, encodedSST *s
 	if err != nil {
 		return nil, fmt.Errorf("during object write: %w", err)
 	}
+	ts.bytesWritten.Add(uint64(len(blocksData)))
 
 	ts.cacheFilter(id, encodedSST.Bloom)
 	return sstable.NewHandle(id, encodedSST.Info), nil
 }
 
 func (ts *TableStore) OpenSST(ctx context.Context, id sstable.ID) (*sstable.Handle, error) {
-	obj := ReadOnlyObject{ts.bucket, ts.sstPath(id)}
+	obj := ts.object(id)
 	sstInfo, err := sstable.ReadInfo(ctx, obj)
 	if err != nil {
 		return nil, fmt.Errorf("while reading sst info: %w", err)
@@ -113,23 +607,130 @@ func (ts *TableStore) OpenSST(ctx context.Context, id sstable.ID) (*sstable.Hand
 }
 
 func (ts *TableStore) ReadBlocks(ctx context.Context, sstHandle *sstable.Handle, blocksRange common.Range) ([]block.Block, error) {
-	obj := ReadOnlyObject{ts.bucket, ts.sstPath(sstHandle.Id)}
+	obj := ts.object(sstHandle.Id)
 	index, err := sstable.ReadIndex(ctx, sstHandle.Info, obj)
 	if err != nil {
 		return nil, err
 	}
-	return sstable.ReadBlocks(ctx, sstHandle.Info, index, blocksRange, obj)
+	if ts.blockCache != nil {
+		return ts.blockCache.readBlocks(ctx, sstHandle, blocksRange, index, obj)
+	}
+	return readBlocks(ctx, sstHandle, blocksRange, index, obj)
 }
 
-// Reads specified blocks from an SSTable using the provided index.
+// Reads specified blocks from an SSTable using the provided index. If the SST is a quarantined
+// compacted SST, each corrupted block is returned as an empty block, see SetQuarantined
 func (ts *TableStore) ReadBlocksUsingIndex(
 	ctx context.Context,
 	sstHandle *sstable.Handle,
 	blocksRange common.Range,
 	index *sstable.Index,
 ) ([]block.Block, error) {
-	obj := ReadOnlyObject{ts.bucket, ts.sstPath(sstHandle.Id)}
-	return sstable.ReadBlocks(ctx, sstHandle.Info, index, blocksRange, obj)
+	blocks, err := ts.readBlocksUsingIndex(ctx, sstHandle, blocksRange, index)
+	var corruption *internal.ExportedBlockCorruption
+	if sstHandle.Id.Type != sstable.Compacted || !errors.As(err, &corruption) ||
+		!ts.quarantine.quarantine(sstHandle.Id, err) {
+		return blocks, err
+	}
+
+	// The blocks are read one at a time, such that only the corrupted blocks are omitted
+	blocks = make([]block.Block, 0, blocksRange.End-blocksRange.Start)
+	for i := blocksRange.Start; i < blocksRange.End; i++ {
+		b, err := ts.readBlocksUsingIndex(ctx, sstHandle, common.Range{Start: i, End: i + 1}, index)
+		if errors.As(err, &corruption) {
+			blocks = append(blocks, block.Block{})
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, b...)
+	}
+	return blocks, nil
+}
+
+func (ts *TableStore) readBlocksUsingIndex(
+	ctx context.Context,
+	sstHandle *sstable.Handle,
+	blocksRange common.Range,
+	index *sstable.Index,
+) ([]block.Block, error) {
+	if ts.throughput {
+		return ts.streamBlocks(ctx, sstHandle, blocksRange, index)
+	}
+	obj := ts.object(sstHandle.Id)
+	if ts.blockCache != nil {
+		return ts.blockCache.readBlocks(ctx, sstHandle, blocksRange, index, obj)
+	}
+	return readBlocks(ctx, sstHandle, blocksRange, index, obj)
+}
+
+// readBlocks reads the blocks within blocksRange from `obj` with a single range request
+func readBlocks(
+	ctx context.Context,
+	handle *sstable.Handle,
+	blocksRange common.Range,
+	index *sstable.Index,
+	obj ReadOnlyObject,
+) ([]block.Block, error) {
+	encodedBlocks, err := sstable.ReadEncodedBlocks(ctx, handle.Info, index, blocksRange, obj)
+	if err != nil {
+		return nil, err
+	}
+	traceBlockRead(ctx, handle.Id, blocksRange.Start, encodedBlocks)
+	blocks := make([]block.Block, len(encodedBlocks))
+	for j, encoded := range encodedBlocks {
+		if err := decodeBlock(&blocks[j], encoded, handle, blocksRange.Start+uint64(j)); err != nil {
+			return nil, err
+		}
+	}
+	return blocks, nil
+}
+
+// traceBlockRead records the read of the encoded blocks of the SST `id` starting at the block `start`
+// as an event of the span of ctx, such that the blocks read by an operation are attributed to it
+func traceBlockRead(ctx context.Context, id sstable.ID, start uint64, encoded [][]byte) {
+	span := trace.SpanFromContext(ctx)
+	if !span.IsRecording() {
+		return
+	}
+	size := 0
+	for _, b := range encoded {
+		size += len(b)
+	}
+	span.AddEvent("read_blocks", trace.WithAttributes(
+		tracing.KeySSTID.String(id.String()),
+		tracing.KeyFirstBlock.Int64(int64(start)),
+		tracing.KeyBlocks.Int(len(encoded)),
+		tracing.KeyBytes.Int(size),
+	))
+}
+
+// streamBlocks reads the blocks within blocksRange from object storage with a single range
+// request, decoding each block as it is received. See Throughput
+func (ts *TableStore) streamBlocks(
+	ctx context.Context,
+	handle *sstable.Handle,
+	blocksRange common.Range,
+	index *sstable.Index,
+) ([]block.Block, error) {
+	path := ts.sstPath(handle.Id)
+	open := func(ctx context.Context, rng common.Range) (io.ReadCloser, error) {
+		return ts.bucket.GetRange(ctx, path, int64(rng.Start), int64(rng.End-rng.Start))
+	}
+	return sstable.StreamBlocks(ctx, handle.Info, index, blocksRange, open,
+		func(b *block.Block, encoded []byte, blockIndex uint64) error {
+			return decodeBlock(b, encoded, handle, blockIndex)
+		})
+}
+
+// decodeBlock decodes the encoded block at `index` within the SST, returning an
+// ErrBlockCorruption if the block is corrupted
+func decodeBlock(b *block.Block, encoded []byte, handle *sstable.Handle, index uint64) error {
+	if err := sstable.DecodeBlock(b, encoded, handle.Info); err != nil {
+		return internal.ErrBlockCorruption(handle.Id.String(), index, err)
+	}
+	return nil
 }
 
 func (ts *TableStore) cacheFilter(sstID sstable.ID, filter mo.Option[bloom.Filter]) {
@@ -146,7 +747,16 @@ func (ts *TableStore) ReadFilter(ctx context.Context, sstHandle *sstable.Handle)
 		return val, nil
 	}
 
-	obj := ReadOnlyObject{ts.bucket, ts.sstPath(sstHandle.Id)}
+	if _, ok := ts.filterSidecars.Load(sstHandle.Id.Value); ok {
+		filtr, err := ts.readFilterSidecar(ctx, sstHandle.Id)
+		if err != nil {
+			return mo.None[bloom.Filter](), err
+		}
+		ts.cacheFilter(sstHandle.Id, filtr)
+		return filtr, nil
+	}
+
+	obj := ts.object(sstHandle.Id)
 	filtr, err := sstable.ReadFilter(ctx, sstHandle.Info, obj)
 	if err != nil {
 		return mo.None[bloom.Filter](), err
@@ -156,8 +766,117 @@ func (ts *TableStore) ReadFilter(ctx context.Context, sstHandle *sstable.Handle)
 	return filtr, nil
 }
 
+// TryReadFilter returns the filter of the SST like ReadFilter, except if the filter is not cached
+// and the limit set by LimitFilterFetches has been reached, the filter is not fetched and false
+// is returned. Callers should then read the SST without the filter, which avoids a storm of
+// small filter requests when the cache is cold, such as after a restart.
+func (ts *TableStore) TryReadFilter(ctx context.Context, sstHandle *sstable.Handle) (mo.Option[bloom.Filter], bool, error) {
+	ts.mu.RLock()
+	val, ok := ts.filterCache.Get(sstHandle.Id)
+	ts.mu.RUnlock()
+	if ok {
+		return val, true, nil
+	}
+
+	release, ok := ts.acquireFilterFetch()
+	if !ok {
+		return mo.None[bloom.Filter](), false, nil
+	}
+	defer release()
+	filter, err := ts.ReadFilter(ctx, sstHandle)
+	return filter, true, err
+}
+
+// TryReadFilterForKey returns the filter which holds `key` if the SST holds it. If the filter of the
+// SST is partitioned, only the filter index and the partition which may hold the key are read, see
+// sstable.FilterIndex. Otherwise, the filter of the SST is returned like TryReadFilter. False is
+// returned if the filter is not cached and the limit set by LimitFilterFetches has been reached.
+func (ts *TableStore) TryReadFilterForKey(ctx context.Context, sstHandle *sstable.Handle, key []byte) (mo.Option[bloom.Filter], bool, error) {
+	if sstHandle.Info.FilterIndexLen == 0 {
+		return ts.TryReadFilter(ctx, sstHandle)
+	}
+	// A rebuilt filter of the SST is never partitioned
+	if _, ok := ts.filterSidecars.Load(sstHandle.Id.Value); ok {
+		return ts.TryReadFilter(ctx, sstHandle)
+	}
+
+	index, ok := ts.filterIndexCache.Get(sstHandle.Id)
+	if !ok {
+		release, ok := ts.acquireFilterFetch()
+		if !ok {
+			return mo.None[bloom.Filter](), false, nil
+		}
+		var err error
+		index, err = sstable.ReadFilterIndex(ctx, sstHandle.Info, ts.object(sstHandle.Id))
+		release()
+		if err != nil {
+			return mo.None[bloom.Filter](), true, err
+		}
+		ts.filterIndexCache.Set(sstHandle.Id, index)
+	}
+
+	partition, ok := index.PartitionForKey(key)
+	if !ok {
+		// The key is before the first key of the SST, and an empty filter holds no keys
+		return mo.Some(bloom.Filter{}), true, nil
+	}
+	cacheKey := filterPartitionKey{sstID: sstHandle.Id, partition: partition}
+	if filter, ok := ts.filterPartitionCache.Get(cacheKey); ok {
+		return mo.Some(filter), true, nil
+	}
+	release, ok := ts.acquireFilterFetch()
+	if !ok {
+		return mo.None[bloom.Filter](), false, nil
+	}
+	defer release()
+	filter, err := sstable.ReadFilterPartition(ctx, sstHandle.Info, index.Partitions[partition], ts.object(sstHandle.Id))
+	if err != nil {
+		return mo.None[bloom.Filter](), true, err
+	}
+	ts.filterPartitionCache.Set(cacheKey, filter)
+	return mo.Some(filter), true, nil
+}
+
+// TryReadPrefixFilter returns the filter of the SST like TryReadFilter if the filter holds the
+// prefixes of the keys extracted by the PrefixExtractor of the config of the TableStore, such
+// that the filter excludes the SST from a scan of the keys of a prefix. None is returned if the
+// filter does not hold the prefixes, such as when the SST was written with another extractor, is
+// partitioned, or was rebuilt, as a rebuilt filter only holds the keys.
+func (ts *TableStore) TryReadPrefixFilter(ctx context.Context, sstHandle *sstable.Handle) (mo.Option[bloom.Filter], bool, error) {
+	extractor := ts.sstConfig.PrefixExtractor
+	if extractor == nil || sstHandle.Info.FilterPrefixExtractor != extractor.Name() ||
+		sstHandle.Info.FilterIndexLen > 0 {
+		return mo.None[bloom.Filter](), true, nil
+	}
+	if _, ok := ts.filterSidecars.Load(sstHandle.Id.Value); ok {
+		return mo.None[bloom.Filter](), true, nil
+	}
+	return ts.TryReadFilter(ctx, sstHandle)
+}
+
+// ReadFilterPartitions reads every partition of the partitioned filter of the SST, such as to
+// verify the filter. The partitions are not cached.
+func (ts *TableStore) ReadFilterPartitions(ctx context.Context, sstHandle *sstable.Handle) ([]bloom.Filter, error) {
+	_, filters, err := sstable.ReadFilterPartitions(ctx, sstHandle.Info, ts.object(sstHandle.Id))
+	return filters, err
+}
+
+// acquireFilterFetch returns false if the limit set by LimitFilterFetches has been reached.
+// Otherwise, the returned func must be called once the filter has been fetched.
+func (ts *TableStore) acquireFilterFetch() (func(), bool) {
+	if ts.filterFetches == nil {
+		return func() {}, true
+	}
+	select {
+	case ts.filterFetches <- struct{}{}:
+		return func() { <-ts.filterFetches }, true
+	default:
+		return nil, false
+	}
+}
+
 func (ts *TableStore) ReadIndex(ctx context.Context, sstHandle *sstable.Handle) (*sstable.Index, error) {
-	obj := ReadOnlyObject{ts.bucket, ts.sstPath(sstHandle.Id)}
+	obj := ts.object(sstHandle.Id)
 	index, err := sstable.ReadIndex(ctx, sstHandle.Info, obj)
 	if err != nil {
 		return nil, err
@@ -165,10 +884,157 @@ func (ts *TableStore) ReadIndex(ctx context.Context, sstHandle *sstable.Handle)
 	return index, nil
 }
 
+// SetExternalDBs configures the TableStore to read the compacted SSTs of `externalDBs` from
+// the path of the external database which holds them. It must be called before the TableStore
+// or any of its clones are used.
+func (ts *TableStore) SetExternalDBs(externalDBs []manifest.ExternalDB) {
+	ts.externalPaths = make(map[string]string)
+	for _, db := range externalDBs {
+		for _, id := range db.SSTIDs {
+			ts.externalPaths[id.String()] = db.Path
+		}
+	}
+}
+
+// SetFilterSidecars configures the TableStore to read the filters of the compacted SSTs with
+// `ids` from their sidecar filter objects rather than from the SSTs. See WriteFilterSidecar
+func (ts *TableStore) SetFilterSidecars(ids []ulid.ULID) {
+	ts.filterSidecars.Clear()
+	for _, id := range ids {
+		ts.filterSidecars.Store(id.String(), true)
+	}
+}
+
+// SetQuarantined marks the SSTs with `ids` as quarantined, replacing the SSTs which were
+// quarantined. The corrupted blocks of a quarantined compacted SST are skipped rather than
+// failing the reads which include them, such that the blocks which are intact are still read.
+func (ts *TableStore) SetQuarantined(ids []sstable.ID) {
+	ts.quarantine.ids.Clear()
+	for _, id := range ids {
+		ts.quarantine.ids.Store(id.Value, true)
+	}
+}
+
+// Quarantine marks the SST with `id` as quarantined, see SetQuarantined
+func (ts *TableStore) Quarantine(id sstable.ID) {
+	ts.quarantine.ids.Store(id.Value, true)
+}
+
+// NumQuarantined returns the number of quarantined SSTs
+func (ts *TableStore) NumQuarantined() int {
+	n := 0
+	ts.quarantine.ids.Range(func(_, _ any) bool {
+		n++
+		return true
+	})
+	return n
+}
+
+// SetCorruptionHandler sets the function called when a corrupted block is read from a compacted
+// SST which is not quarantined. The SST is quarantined if `onCorruption` returns true, in which
+// case the read which found the corrupted block skips it. See SetQuarantined
+func (ts *TableStore) SetCorruptionHandler(onCorruption func(id sstable.ID, err error) bool) {
+	ts.quarantine.mu.Lock()
+	defer ts.quarantine.mu.Unlock()
+	ts.quarantine.onCorruption = onCorruption
+}
+
+// IsQuarantined returns true if the SST with `id` is quarantined
+func (ts *TableStore) IsQuarantined(id sstable.ID) bool {
+	_, ok := ts.quarantine.ids.Load(id.Value)
+	return ok
+}
+
+// RebuildFilter builds a filter of the keys of the SST using the filter options of the
+// TableStore, as though the SST were written with them. None is returned if the SST has
+// fewer keys than sstable.Config.MinFilterKeys.
+func (ts *TableStore) RebuildFilter(ctx context.Context, sstHandle *sstable.Handle) (mo.Option[bloom.Filter], error) {
+	iter, err := sstable.NewIterator(ctx, sstHandle, ts)
+	if err != nil {
+		return mo.None[bloom.Filter](), err
+	}
+	builder := bloom.NewBuilderWithHash(ts.sstConfig.FilterBitsPerKey, ts.sstConfig.FilterHash, ts.sstConfig.FilterSeed)
+	var numKeys uint32
+	for {
+		entry, ok := iter.NextEntry(ctx)
+		if !ok {
+			break
+		}
+		builder.Add(entry.Key)
+		numKeys++
+	}
+	if w := iter.Warnings(); w != nil && !w.Empty() {
+		return mo.None[bloom.Filter](), fmt.Errorf("while reading sst '%s': %w", sstHandle.Id.String(), w)
+	}
+	if numKeys < ts.sstConfig.MinFilterKeys {
+		return mo.None[bloom.Filter](), nil
+	}
+	return mo.Some(builder.Build()), nil
+}
+
+// WriteFilterSidecar writes `filter` to the sidecar filter object of the compacted SST `id`.
+// The sidecar is not read until UseFilterSidecar is called, which must be done only once
+// the sidecar is recorded in the manifest.
+func (ts *TableStore) WriteFilterSidecar(ctx context.Context, id sstable.ID, filter bloom.Filter) error {
+	data, err := bloom.Encode(filter, compress.CodecNone)
+	if err != nil {
+		return err
+	}
+	if err := ts.bucket.Upload(ctx, ts.filterSidecarPath(id), bytes.NewReader(data)); err != nil {
+		return internal.ErrRetryable("during bucket upload: %s", err)
+	}
+	ts.bytesWritten.Add(uint64(len(data)))
+	return nil
+}
+
+// UseFilterSidecar reads the filter of the SST `id` from the sidecar written by WriteFilterSidecar,
+// and caches `filter` as the filter of the SST.
+func (ts *TableStore) UseFilterSidecar(id sstable.ID, filter bloom.Filter) {
+	ts.filterSidecars.Store(id.Value, true)
+	ts.cacheFilter(id, mo.Some(filter))
+}
+
+func (ts *TableStore) readFilterSidecar(ctx context.Context, id sstable.ID) (mo.Option[bloom.Filter], error) {
+	if ts.cachedOnly {
+		return mo.None[bloom.Filter](), errNotCached
+	}
+	data, err := NewBucketObject(ts.bucket, ts.filterSidecarPath(id)).Read(ctx)
+	if err != nil {
+		return mo.None[bloom.Filter](), fmt.Errorf("while reading filter sidecar of sst '%s': %w", id.String(), err)
+	}
+	filter, err := bloom.Decode(data, compress.CodecNone)
+	if err != nil {
+		return mo.None[bloom.Filter](), err
+	}
+	return mo.Some(filter), nil
+}
+
+// filterSidecarPath returns the path of the sidecar filter object of the compacted SST `id`,
+// which is stored alongside the SST
+func (ts *TableStore) filterSidecarPath(id sstable.ID) string {
+	return strings.TrimSuffix(ts.sstPath(id), ".sst") + ".filter"
+}
+
+// CopySST copies the SST with `id` from this TableStore to `dst`
+func (ts *TableStore) CopySST(ctx context.Context, id sstable.ID, dst *TableStore) error {
+	data, err := NewBucketObject(ts.bucket, ts.sstPath(id)).Read(ctx)
+	if err != nil {
+		return fmt.Errorf("while reading sst '%s': %w", id.Value, err)
+	}
+	if err := dst.bucket.Upload(ctx, dst.sstPath(id), bytes.NewReader(data)); err != nil {
+		return fmt.Errorf("during object write: %w", err)
+	}
+	dst.bytesWritten.Add(uint64(len(data)))
+	return nil
+}
+
 func (ts *TableStore) sstPath(id sstable.ID) string {
 	if id.Type == sstable.WAL {
 		return path.Join(ts.rootPath, ts.walPath, id.Value+".sst")
 	} else if id.Type == sstable.Compacted {
+		if rootPath, ok := ts.externalPaths[id.Value]; ok {
+			return path.Join(rootPath, ts.compactedPath, id.Value+".sst")
+		}
 		return path.Join(ts.rootPath, ts.compactedPath, id.Value+".sst")
 	}
 	return ""
@@ -190,15 +1056,112 @@ func (ts *TableStore) parseID(filepath string, expectedExt string) (uint64, erro
 func (ts *TableStore) Clone() *TableStore {
 	cache, err := otter.MustBuilder[sstable.ID, mo.Option[bloom.Filter]](1000).Build()
 	assert.True(err == nil, "")
+	indexCache, partitionCache := newFilterCaches()
 	return &TableStore{
-		mu:            sync.RWMutex{},
-		bucket:        ts.bucket,
-		sstConfig:     ts.sstConfig,
-		rootPath:      ts.rootPath,
-		walPath:       ts.walPath,
-		compactedPath: ts.compactedPath,
-		filterCache:   cache,
+		mu:                    sync.RWMutex{},
+		bucket:                ts.bucket,
+		sstConfig:             ts.sstConfig,
+		rootPath:              ts.rootPath,
+		walPath:               ts.walPath,
+		compactedPath:         ts.compactedPath,
+		filterCache:           cache,
+		bytesWritten:          ts.bytesWritten,
+		filterFetches:         ts.filterFetches,
+		externalPaths:         ts.externalPaths,
+		blockCache:            ts.blockCache,
+		diskCache:             ts.diskCache,
+		filterSidecars:        ts.filterSidecars,
+		quarantine:            ts.quarantine,
+		levelCodecs:           ts.levelCodecs,
+		keyspaceCodecs:        ts.keyspaceCodecs,
+		filterPartitionBlocks: ts.filterPartitionBlocks,
+		inlineValueBytes:      ts.inlineValueBytes,
+		walEntryChecksums:     ts.walEntryChecksums,
+		filterIndexCache:      indexCache,
+		filterPartitionCache:  partitionCache,
+		hedge:                 ts.hedge,
+		pointHedge:            ts.pointHedge,
+		writeBufferSize:       ts.writeBufferSize,
+		streamUploads:         ts.streamUploads,
+		readAheadBytes:        ts.readAheadBytes,
+		prefetchConcurrency:   ts.prefetchConcurrency,
+		cachedOnly:            ts.cachedOnly,
+		throughput:            ts.throughput,
+	}
+}
+
+// WithBlockCache returns a clone of this TableStore which shares its filter cache, but caches up
+// to sizeBytes of data blocks in a block cache of its own. If sizeBytes is zero, the clone does not
+// cache blocks. See SetBlockCache
+func (ts *TableStore) WithBlockCache(sizeBytes uint64, compressed bool) *TableStore {
+	clone := ts.Clone()
+	clone.filterCache = ts.filterCache
+	clone.filterIndexCache = ts.filterIndexCache
+	clone.filterPartitionCache = ts.filterPartitionCache
+	clone.SetBlockCache(sizeBytes, compressed)
+	return clone
+}
+
+// SetHedgedReads hedges the range reads of SSTs from object storage made through the clones
+// returned by PointReads: if a read has not completed after the `percentile` (between 0 and 1)
+// of the latency of recent reads, or after minDelay if it is greater, a second request for the
+// same range is sent and the first response is used. At most maxInFlight reads, including hedges,
+// are sent by those clones at once, and a hedge is not sent once the limit has been reached. If
+// percentile is zero, reads are not hedged, and if maxInFlight is zero, reads are not limited.
+func (ts *TableStore) SetHedgedReads(percentile float64, minDelay time.Duration, maxInFlight int) {
+	if percentile <= 0 && maxInFlight <= 0 {
+		ts.pointHedge = nil
+		return
 	}
+	ts.pointHedge = newHedgedReads(percentile, minDelay, maxInFlight)
+}
+
+// PointReads returns a clone of this TableStore for the reads of a point lookup, which shares
+// its filter caches and whose reads are hedged as configured by SetHedgedReads
+func (ts *TableStore) PointReads() *TableStore {
+	clone := ts.Clone()
+	clone.filterCache = ts.filterCache
+	clone.filterIndexCache = ts.filterIndexCache
+	clone.filterPartitionCache = ts.filterPartitionCache
+	clone.hedge = ts.pointHedge
+	// A point read only needs the block which may hold the key
+	clone.readAheadBytes = 0
+	clone.prefetchConcurrency = 0
+	return clone
+}
+
+// CachedOnly returns a clone of this TableStore for the reads of a point lookup like PointReads,
+// except that reads are only served by the block cache, the filter caches and the disk cache.
+// A read of data which is not cached fails rather than reading object storage.
+func (ts *TableStore) CachedOnly() *TableStore {
+	clone := ts.PointReads()
+	clone.cachedOnly = true
+	return clone
+}
+
+// Throughput returns a clone of this TableStore for the reads of a scan which favours bandwidth
+// over latency, such as a full export. Each request reads up to ThroughputReadAheadBytes of blocks,
+// which are decoded as they are received rather than once the whole range is read, and the next
+// range is requested while the blocks of a range are iterated. Blocks are neither read from nor
+// added to the block cache or the disk cache, such that the scan does not evict the blocks of
+// other reads, and requests are not hedged.
+func (ts *TableStore) Throughput() *TableStore {
+	clone := ts.Clone()
+	clone.readAheadBytes = max(ts.readAheadBytes, ThroughputReadAheadBytes)
+	clone.prefetchConcurrency = max(ts.prefetchConcurrency, 1)
+	clone.blockCache = nil
+	clone.diskCache = nil
+	clone.hedge = nil
+	clone.throughput = true
+	return clone
+}
+
+// HedgeStats returns the reads hedged by the clones returned by PointReads, see SetHedgedReads
+func (ts *TableStore) HedgeStats() HedgeStats {
+	if ts.pointHedge == nil {
+		return HedgeStats{}
+	}
+	return ts.pointHedge.stats()
 }
 
 // ------------------------------------------------
@@ -211,11 +1174,37 @@ type EncodedSSTableWriter struct {
 	builder    *sstable.Builder
 	tableStore *TableStore
 
-	// TODO: we are using an unbounded slice of byte as buffer.
-	//  Add a capacity for buffer and when buffer reaches the capacity
-	//  it should be written to object storage
+	// buffer holds the encoded blocks which have not yet been written to object storage. Once it
+	// holds bufferSize bytes, the blocks are streamed to object storage by upload, such that the
+	// memory used to write an SST does not grow with the size of the SST.
 	buffer        []byte
+	bufferSize    uint64
 	blocksWritten uint64
+
+	// upload, if not nil, is the upload of the SST to which the buffered blocks are written
+	upload *sstUpload
+
+	// prof, if not nil, records the time spent encoding, compressing and uploading the SSTable
+	prof *profile.Profile
+}
+
+// sstUpload streams an SST to object storage through a pipe. Object storage providers such as S3
+// and GCS upload a reader whose size is unknown in parts, such that only a part is held in memory.
+type sstUpload struct {
+	pipe   *io.PipeWriter
+	cancel context.CancelFunc
+	// done receives the result of the upload
+	done chan error
+}
+
+// errUploadAborted fails the upload of an SST whose writer was aborted
+var errUploadAborted = errors.New("SST writer aborted")
+
+// SetProfile sets the Profile which records the time spent encoding, compressing and
+// uploading the SSTable
+func (w *EncodedSSTableWriter) SetProfile(p *profile.Profile) {
+	w.prof = p
+	w.builder.SetProfile(p)
 }
 
 func (w *EncodedSSTableWriter) Add(key []byte, value mo.Option[[]byte]) error {
@@ -224,7 +1213,23 @@ func (w *EncodedSSTableWriter) Add(key []byte, value mo.Option[[]byte]) error {
 	if err != nil {
 		return fmt.Errorf("builder failed to add key value: %w", err)
 	}
+	return w.bufferBlocks()
+}
+
+// AddEntry adds the entry to the SSTable, retaining its kind and sequence number
+func (w *EncodedSSTableWriter) AddEntry(entry types.RowEntry) error {
+	if err := w.builder.Add(entry.Key, entry); err != nil {
+		return fmt.Errorf("builder failed to add key value: %w", err)
+	}
+	return w.bufferBlocks()
+}
+
+// AddRangeTombstone adds the range tombstone to the SSTable, see sstable.Builder.AddRangeTombstone
+func (w *EncodedSSTableWriter) AddRangeTombstone(tombstone types.RangeTombstone) {
+	w.builder.AddRangeTombstone(tombstone)
+}
 
+func (w *EncodedSSTableWriter) bufferBlocks() error {
 	for {
 		blk, ok := w.builder.NextBlock().Get()
 		if !ok {
@@ -233,10 +1238,64 @@ func (w *EncodedSSTableWriter) Add(key []byte, value mo.Option[[]byte]) error {
 		w.buffer = append(w.buffer, blk...)
 		w.blocksWritten += 1
 	}
+	if uint64(len(w.buffer)) < w.bufferSize {
+		return nil
+	}
+	return w.flushBuffer()
+}
 
+// flushBuffer writes the buffered blocks to the upload of the SST, starting the upload if it
+// has not started. It blocks until the upload has consumed the blocks.
+func (w *EncodedSSTableWriter) flushBuffer() error {
+	if w.upload == nil {
+		w.startUpload()
+	}
+	prev := w.prof.Enter(profile.StageUpload)
+	_, err := w.upload.pipe.Write(w.buffer)
+	w.prof.Exit(prev)
+	if err != nil {
+		w.Abort()
+		return internal.ErrRetryable("during bucket upload: %s", err)
+	}
+	w.tableStore.bytesWritten.Add(uint64(len(w.buffer)))
+	w.buffer = w.buffer[:0]
 	return nil
 }
 
+func (w *EncodedSSTableWriter) startUpload() {
+	// The upload outlives the context of any one call to the writer, and is cancelled by Abort
+	ctx, cancel := context.WithCancel(context.Background())
+	reader, writer := io.Pipe()
+	upload := &sstUpload{pipe: writer, cancel: cancel, done: make(chan error, 1)}
+	sstPath := w.tableStore.sstPath(w.sstID)
+	go func() {
+		err := w.tableStore.bucket.Upload(ctx, sstPath, reader)
+		// Unblock the writer if the upload failed before it read every block
+		_ = reader.CloseWithError(err)
+		if err != nil {
+			// Buckets such as the filesystem bucket write an upload as it is read, and leave
+			// the blocks read before the upload failed or was aborted. The SST is not yet in
+			// the manifest, as such it is deleted rather than left for the garbage collector.
+			_ = w.tableStore.bucket.Delete(context.Background(), sstPath)
+		}
+		upload.done <- err
+	}()
+	w.upload = upload
+}
+
+// Abort stops the upload of the SST if it has started, such that the SST is not written. It
+// must be called if the writer is discarded without being closed. Abort has no effect once
+// the writer is closed.
+func (w *EncodedSSTableWriter) Abort() {
+	if w.upload == nil {
+		return
+	}
+	_ = w.upload.pipe.CloseWithError(errUploadAborted)
+	w.upload.cancel()
+	<-w.upload.done
+	w.upload = nil
+}
+
 func (w *EncodedSSTableWriter) Written() uint64 {
 	return w.blocksWritten
 }
@@ -244,68 +1303,81 @@ func (w *EncodedSSTableWriter) Written() uint64 {
 func (w *EncodedSSTableWriter) Close(ctx context.Context) (*sstable.Handle, error) {
 	encodedSST, err := w.builder.Build()
 	if err != nil {
+		w.Abort()
 		return nil, fmt.Errorf("SST build failed: %w", err)
 	}
 
-	blocksData := w.buffer
 	for {
 		if encodedSST.Blocks.Len() == 0 {
 			break
 		}
-		blocksData = append(blocksData, encodedSST.Blocks.PopFront()...)
+		w.buffer = append(w.buffer, encodedSST.Blocks.PopFront()...)
 	}
 
-	sstPath := w.tableStore.sstPath(w.sstID)
-	err = w.tableStore.bucket.Upload(ctx, sstPath, bytes.NewReader(blocksData))
-	if err != nil {
-		return nil, internal.ErrRetryable("during bucket upload: %s", err)
+	if w.upload == nil {
+		// The SST fit within the buffer, and is uploaded with a single request
+		sstPath := w.tableStore.sstPath(w.sstID)
+		prev := w.prof.Enter(profile.StageUpload)
+		err = w.tableStore.bucket.Upload(ctx, sstPath, bytes.NewReader(w.buffer))
+		w.prof.Exit(prev)
+		if err != nil {
+			return nil, internal.ErrRetryable("during bucket upload: %s", err)
+		}
+		w.tableStore.bytesWritten.Add(uint64(len(w.buffer)))
+	} else if err := w.finishUpload(ctx); err != nil {
+		return nil, err
 	}
 
 	w.tableStore.cacheFilter(w.sstID, encodedSST.Bloom)
 	return sstable.NewHandle(w.sstID, encodedSST.Info), nil
 }
 
-// ------------------------------------------------
-// ReadOnlyObject
-// ------------------------------------------------
-
-type ReadOnlyObject struct {
-	bucket objstore.Bucket
-	path   string
-}
-
-func (r ReadOnlyObject) Len(ctx context.Context) (int, error) {
-	attr, err := r.bucket.Attributes(ctx, r.path)
-	if err != nil {
-		return 0, fmt.Errorf("while fetching object attributes: %w", err)
+// finishUpload writes the remaining blocks to the upload of the SST and waits for the upload to complete
+func (w *EncodedSSTableWriter) finishUpload(ctx context.Context) error {
+	if err := w.flushBuffer(); err != nil {
+		return err
+	}
+	prev := w.prof.Enter(profile.StageUpload)
+	defer w.prof.Exit(prev)
+	_ = w.upload.pipe.Close()
+	select {
+	case err := <-w.upload.done:
+		w.upload.cancel()
+		w.upload = nil
+		if err != nil {
+			return internal.ErrRetryable("during bucket upload: %s", err)
+		}
+		return nil
+	case <-ctx.Done():
+		w.Abort()
+		return internal.ErrRetryable("during bucket upload: %s", ctx.Err())
 	}
-	return int(attr.Size), nil
 }
 
-func (r ReadOnlyObject) ReadRange(ctx context.Context, rng common.Range) ([]byte, error) {
-	read, err := r.bucket.GetRange(ctx, r.path, int64(rng.Start), int64(rng.End-rng.Start))
-	if err != nil {
-		return nil, fmt.Errorf("while fetching object range [%d:%d]: %w", rng.Start, rng.End-rng.Start, err)
-	}
+// ------------------------------------------------
+// quarantineSet
+// ------------------------------------------------
 
-	data, err := io.ReadAll(read)
-	if err != nil {
-		return nil, fmt.Errorf("while reading object [%d:%d]: %w", rng.Start, rng.End, err)
-	}
+// quarantineSet holds the IDs of the quarantined SSTs, see TableStore.SetQuarantined
+type quarantineSet struct {
+	ids sync.Map
 
-	return data, nil
+	mu           sync.RWMutex
+	onCorruption func(id sstable.ID, err error) bool
 }
 
-func (r ReadOnlyObject) Read(ctx context.Context) ([]byte, error) {
-	read, err := r.bucket.Get(ctx, r.path)
-	if err != nil {
-		return nil, fmt.Errorf("while fetching object '%s': %w", r.path, err)
+// quarantine returns true if the SST with `id` is quarantined, calling onCorruption with `err` to
+// decide whether to quarantine it if it is not
+func (q *quarantineSet) quarantine(id sstable.ID, err error) bool {
+	if _, ok := q.ids.Load(id.Value); ok {
+		return true
 	}
-
-	data, err := io.ReadAll(read)
-	if err != nil {
-		return nil, fmt.Errorf("while reading object '%s': %w", r.path, err)
+	q.mu.RLock()
+	onCorruption := q.onCorruption
+	q.mu.RUnlock()
+	if onCorruption == nil || !onCorruption(id, err) {
+		return false
 	}
-
-	return data, nil
+	q.ids.Store(id.Value, true)
+	return true
 }
@@ -5,11 +5,18 @@ import (
 	"context"
 	"encoding/binary"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
 	"strconv"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/oklog/ulid/v2"
 	"github.com/samber/mo"
+	"github.com/slatedb/slatedb-go/internal"
 	assert2 "github.com/slatedb/slatedb-go/internal/assert"
 	"github.com/slatedb/slatedb-go/internal/compress"
 	"github.com/slatedb/slatedb-go/internal/sstable"
@@ -20,30 +27,16 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/thanos-io/objstore"
+	"github.com/thanos-io/objstore/providers/filesystem"
 )
 
-type BytesBlob struct {
-	data []byte
-}
-
-func (b BytesBlob) Len(_ context.Context) (int, error) {
-	return len(b.data), nil
-}
-
-func (b BytesBlob) ReadRange(_ context.Context, r common.Range) ([]byte, error) {
-	return b.data[r.Start:r.End], nil
-}
-
-func (b BytesBlob) Read(_ context.Context) ([]byte, error) {
-	return b.data, nil
-}
-
 func nextBlockToIter(t *testing.T, builder *sstable.Builder, codec compress.Codec) *block.Iterator {
 	blockBytes, ok := builder.NextBlock().Get()
 	assert2.True(ok, "Block should not be empty")
 	var decoded block.Block
 
-	require.NoError(t, block.Decode(&decoded, blockBytes, codec))
+	require.NoError(t, block.DecodeWithOptions(&decoded, blockBytes,
+		block.Options{Codec: codec, Checksum: block.ChecksumCRC32C}))
 	return block.NewIterator(&decoded)
 }
 
@@ -199,6 +192,42 @@ func TestSSTableNoFilter(t *testing.T) {
 	assert.Equal(t, uint64(0), sstHandle.Info.FilterLen)
 }
 
+func TestTryReadFilterLimitsConcurrentFetches(t *testing.T) {
+	bucket := objstore.NewInMemBucket()
+	conf := sstable.DefaultConfig()
+	conf.MinFilterKeys = 0
+	tableStore := NewTableStore(bucket, conf, "")
+	tableStore.LimitFilterFetches(1)
+	builder := tableStore.TableBuilder()
+	require.NoError(t, builder.AddValue([]byte("key1"), []byte("value1")))
+	encodedSST, err := builder.Build()
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	sstHandle, err := tableStore.WriteSST(ctx, sstable.NewIDWal(0), encodedSST)
+	require.NoError(t, err)
+
+	// while the only fetch slot is in use, the filter is not fetched
+	reader := tableStore.Clone()
+	tableStore.filterFetches <- struct{}{}
+	filter, fetched, err := reader.TryReadFilter(ctx, sstHandle)
+	require.NoError(t, err)
+	assert.False(t, fetched)
+	assert.True(t, filter.IsAbsent())
+	<-tableStore.filterFetches
+
+	filter, fetched, err = reader.TryReadFilter(ctx, sstHandle)
+	require.NoError(t, err)
+	assert.True(t, fetched)
+	assert.True(t, filter.IsPresent())
+
+	// cached filters are returned regardless of the limit
+	tableStore.filterFetches <- struct{}{}
+	_, fetched, err = reader.TryReadFilter(ctx, sstHandle)
+	require.NoError(t, err)
+	assert.True(t, fetched)
+}
+
 func TestSSTableBuildsFilterWithCorrectBitsPerKey(t *testing.T) {
 	filterBits := []uint32{10, 20}
 	for _, filterBitsPerKey := range filterBits {
@@ -275,7 +304,7 @@ func TestReadBlocks(t *testing.T) {
 		data = append(data, encodedSST.Blocks.At(i)...)
 	}
 
-	blob := BytesBlob{data}
+	blob := NewBytesObject(data)
 	index, err := sstable.ReadIndexRaw(encodedInfo, data)
 	assert.NoError(t, err)
 	blocks, err := sstable.ReadBlocks(context.Background(), encodedInfo, index, common.Range{Start: 0, End: 2}, blob)
@@ -322,7 +351,7 @@ func TestReadAllBlocks(t *testing.T) {
 		data = append(data, encodedSST.Blocks.At(i)...)
 	}
 
-	blob := BytesBlob{data}
+	blob := NewBytesObject(data)
 	index, err := sstable.ReadIndexRaw(encodedInfo, data)
 	assert.NoError(t, err)
 	blocks, err := sstable.ReadBlocks(context.Background(), encodedInfo, index, common.Range{Start: 0, End: 3}, blob)
@@ -346,6 +375,223 @@ func TestReadAllBlocks(t *testing.T) {
 	assert.False(t, ok)
 }
 
+func TestReadBlocksUsesBlockCache(t *testing.T) {
+	for _, compressed := range []bool{false, true} {
+		t.Run(fmt.Sprintf("compressed=%v", compressed), func(t *testing.T) {
+			ctx := context.Background()
+			bucket := objstore.NewInMemBucket()
+			conf := sstable.DefaultConfig()
+			conf.BlockSize = 128
+			conf.Compression = compress.CodecSnappy
+			tableStore := NewTableStore(bucket, conf, "")
+			tableStore.SetBlockCache(1024*1024, compressed)
+			keyGen := common.NewOrderedBytesGeneratorWithByteRange([]byte("aaaaaaaaaaaaaaaa"), byte('a'), byte('z'))
+			valGen := common.NewOrderedBytesGeneratorWithByteRange([]byte("1111111111111111"), byte(1), byte(26))
+			sst, _, err := buildSSTWithNBlocks(ctx, 4, tableStore, keyGen, valGen)
+			require.NoError(t, err)
+
+			uncached, err := sstable.ReadBlocks(ctx, sst.Info, mustReadIndex(t, tableStore, sst),
+				common.Range{Start: 0, End: 4}, NewBucketObject(bucket, tableStore.sstPath(sst.Id)))
+			require.NoError(t, err)
+
+			blocks, err := tableStore.ReadBlocks(ctx, sst, common.Range{Start: 1, End: 3})
+			require.NoError(t, err)
+			assert.Equal(t, uncached[1:3], blocks)
+			assert.Equal(t, uint64(0), tableStore.BlockCacheStats().Hits)
+			assert.Equal(t, uint64(2), tableStore.BlockCacheStats().Misses)
+
+			// a clone shares the cache, and only the blocks which are not cached are read
+			blocks, err = tableStore.Clone().ReadBlocks(ctx, sst, common.Range{Start: 0, End: 4})
+			require.NoError(t, err)
+			assert.Equal(t, uncached, blocks)
+			stats := tableStore.BlockCacheStats()
+			assert.Equal(t, uint64(2), stats.Hits)
+			assert.Equal(t, uint64(4), stats.Misses)
+			assert.Positive(t, stats.Size)
+		})
+	}
+}
+
+func TestTrimCaches(t *testing.T) {
+	ctx := context.Background()
+	conf := sstable.DefaultConfig()
+	conf.BlockSize = 128
+	conf.MinFilterKeys = 1
+	tableStore := NewTableStore(objstore.NewInMemBucket(), conf, "")
+	tableStore.SetBlockCache(1024*1024, false)
+	keyGen := common.NewOrderedBytesGeneratorWithByteRange([]byte("aaaaaaaaaaaaaaaa"), byte('a'), byte('z'))
+	valGen := common.NewOrderedBytesGeneratorWithByteRange([]byte("1111111111111111"), byte(1), byte(26))
+	sst, _, err := buildSSTWithNBlocks(ctx, 4, tableStore, keyGen, valGen)
+	require.NoError(t, err)
+	_, err = tableStore.ReadBlocks(ctx, sst, common.Range{Start: 0, End: 4})
+	require.NoError(t, err)
+	filter, _, err := tableStore.TryReadFilter(ctx, sst)
+	require.NoError(t, err)
+	require.True(t, filter.IsPresent())
+	size := tableStore.BlockCacheStats().Size
+
+	// blocks are evicted until the cache is within the floor
+	tableStore.TrimCaches(size / 2)
+	trimmed := tableStore.BlockCacheStats().Size
+	assert.LessOrEqual(t, trimmed, size/2)
+	assert.Positive(t, trimmed)
+	_, ok := tableStore.filterCache.Get(sst.Id)
+	assert.False(t, ok)
+
+	tableStore.TrimCaches(0)
+	assert.Equal(t, uint64(0), tableStore.BlockCacheStats().Size)
+	blocks, err := tableStore.ReadBlocks(ctx, sst, common.Range{Start: 0, End: 4})
+	require.NoError(t, err)
+	assert.Len(t, blocks, 4)
+}
+
+func mustReadIndex(t *testing.T, tableStore *TableStore, sst *sstable.Handle) *sstable.Index {
+	index, err := tableStore.ReadIndex(context.Background(), sst)
+	require.NoError(t, err)
+	return index
+}
+
+func TestReadsUseDiskCache(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	bucket := objstore.NewInMemBucket()
+	conf := sstable.DefaultConfig()
+	conf.BlockSize = 128
+	tableStore := NewTableStore(bucket, conf, "/root")
+	require.NoError(t, tableStore.SetDiskCache(dir, 1024*1024))
+
+	id := sstable.NewIDCompacted(ulid.Make())
+	writer := tableStore.TableWriter(id)
+	keyGen := common.NewOrderedBytesGeneratorWithByteRange([]byte("aaaaaaaaaaaaaaaa"), byte('a'), byte('z'))
+	valGen := common.NewOrderedBytesGeneratorWithByteRange([]byte("1111111111111111"), byte(1), byte(26))
+	for writer.blocksWritten < 4 {
+		require.NoError(t, writer.Add(keyGen.Next(), mo.Some(valGen.Next())))
+	}
+	_, err := writer.Close(ctx)
+	require.NoError(t, err)
+
+	sst, err := tableStore.OpenSST(ctx, id)
+	require.NoError(t, err)
+	expected, err := tableStore.ReadBlocks(ctx, sst, common.Range{Start: 0, End: 4})
+	require.NoError(t, err)
+	assert.Positive(t, tableStore.diskCache.Size())
+
+	// after a restart, the SST is read from the disk cache without object storage
+	require.NoError(t, bucket.Delete(ctx, tableStore.sstPath(id)))
+	restarted := NewTableStore(bucket, conf, "/root")
+	require.NoError(t, restarted.SetDiskCache(dir, 1024*1024))
+	sst, err = restarted.OpenSST(ctx, id)
+	require.NoError(t, err)
+	blocks, err := restarted.ReadBlocks(ctx, sst, common.Range{Start: 0, End: 4})
+	require.NoError(t, err)
+	assert.Equal(t, expected, blocks)
+
+	// once the cache exceeds its size, the cached ranges are evicted
+	evicted := NewTableStore(bucket, conf, "/root")
+	require.NoError(t, evicted.SetDiskCache(dir, 1))
+	assert.Equal(t, uint64(0), evicted.diskCache.Size())
+	_, err = evicted.OpenSST(ctx, id)
+	assert.Error(t, err)
+}
+
+func TestReadSSTFromObjects(t *testing.T) {
+	ctx := context.Background()
+	bucket := objstore.NewInMemBucket()
+	conf := sstable.DefaultConfig()
+	conf.BlockSize = 128
+	tableStore := NewTableStore(bucket, conf, "")
+	keyGen := common.NewOrderedBytesGeneratorWithByteRange([]byte("aaaaaaaaaaaaaaaa"), byte('a'), byte('z'))
+	valGen := common.NewOrderedBytesGeneratorWithByteRange([]byte("1111111111111111"), byte(1), byte(26))
+	sst, _, err := buildSSTWithNBlocks(ctx, 3, tableStore, keyGen, valGen)
+	require.NoError(t, err)
+	expected, err := tableStore.ReadBlocks(ctx, sst, common.Range{Start: 0, End: 3})
+	require.NoError(t, err)
+
+	// copy the SST to a local file, as in a snapshot directory
+	data, err := NewBucketObject(bucket, tableStore.sstPath(sst.Id)).Read(ctx)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), sst.Id.String())
+	require.NoError(t, os.WriteFile(path, data, 0o644))
+	file, err := OpenFileObject(path)
+	require.NoError(t, err)
+	defer func() { assert.NoError(t, file.Close()) }()
+
+	for name, obj := range map[string]ReadOnlyObject{"file": file, "bytes": NewBytesObject(data)} {
+		t.Run(name, func(t *testing.T) {
+			info, err := sstable.ReadInfo(ctx, obj)
+			require.NoError(t, err)
+			assert.Equal(t, sst.Info, info)
+			index, err := sstable.ReadIndex(ctx, info, obj)
+			require.NoError(t, err)
+			blocks, err := sstable.ReadBlocks(ctx, info, index, common.Range{Start: 0, End: 3}, obj)
+			require.NoError(t, err)
+			assert.Equal(t, expected, blocks)
+
+			_, err = obj.ReadRange(ctx, common.Range{Start: 0, End: uint64(len(data) + 1)})
+			assert.Error(t, err)
+		})
+	}
+}
+
+// truncatedObject is a ReadOnlyObject which returns at most `limit` bytes from ReadRange,
+// as an object store may for a truncated object
+type truncatedObject struct {
+	ReadOnlyObject
+	limit int
+}
+
+func (o truncatedObject) ReadRange(ctx context.Context, rng common.Range) ([]byte, error) {
+	data, err := o.ReadOnlyObject.ReadRange(ctx, rng)
+	if err != nil {
+		return nil, err
+	}
+	return data[:min(len(data), o.limit)], nil
+}
+
+func TestReadCorruptedBlocks(t *testing.T) {
+	ctx := context.Background()
+	bucket := objstore.NewInMemBucket()
+	conf := sstable.DefaultConfig()
+	conf.BlockSize = 128
+	tableStore := NewTableStore(bucket, conf, "")
+	keyGen := common.NewOrderedBytesGeneratorWithByteRange([]byte("aaaaaaaaaaaaaaaa"), byte('a'), byte('z'))
+	valGen := common.NewOrderedBytesGeneratorWithByteRange([]byte("1111111111111111"), byte(1), byte(26))
+	sst, _, err := buildSSTWithNBlocks(ctx, 3, tableStore, keyGen, valGen)
+	require.NoError(t, err)
+	assert.Equal(t, block.ChecksumCRC32C, sst.Info.BlockChecksum)
+	index, err := tableStore.ReadIndex(ctx, sst)
+	require.NoError(t, err)
+
+	path := tableStore.sstPath(sst.Id)
+	data, err := NewBucketObject(bucket, path).Read(ctx)
+	require.NoError(t, err)
+
+	t.Run("Corrupted", func(t *testing.T) {
+		corrupted := bytes.Clone(data)
+		corrupted[index.BlockMeta()[1].Offset]++
+		require.NoError(t, bucket.Upload(ctx, path, bytes.NewReader(corrupted)))
+
+		_, err := tableStore.ReadBlocks(ctx, sst, common.Range{Start: 0, End: 3})
+		var corruption *internal.ExportedBlockCorruption
+		require.ErrorAs(t, err, &corruption)
+		assert.Equal(t, sst.Id.String(), corruption.SSTID)
+		assert.Equal(t, uint64(1), corruption.Block)
+		assert.ErrorContains(t, err, "crc32c checksum mismatch")
+
+		blocks, err := tableStore.ReadBlocks(ctx, sst, common.Range{Start: 0, End: 1})
+		require.NoError(t, err)
+		assert.Len(t, blocks, 1)
+	})
+
+	t.Run("Truncated", func(t *testing.T) {
+		obj := truncatedObject{ReadOnlyObject: NewBytesObject(data), limit: int(index.BlockMeta()[2].Offset) - 3}
+		_, err := readBlocks(ctx, sst, common.Range{Start: 0, End: 3}, index, obj)
+		var corruption *internal.ExportedBlockCorruption
+		require.ErrorAs(t, err, &corruption)
+		assert.Equal(t, uint64(1), corruption.Block)
+	})
+}
+
 // Iterator tests
 
 func TestOneBlockSSTIter(t *testing.T) {
@@ -420,6 +666,186 @@ func TestManyBlockSSTIter(t *testing.T) {
 	assert.False(t, ok)
 }
 
+// rangeReadCounter counts the range reads made from a bucket
+type rangeReadCounter struct {
+	objstore.Bucket
+	reads atomic.Int32
+}
+
+func (b *rangeReadCounter) GetRange(ctx context.Context, name string, off, length int64) (io.ReadCloser, error) {
+	b.reads.Add(1)
+	return b.Bucket.GetRange(ctx, name, off, length)
+}
+
+func TestIterReadsAhead(t *testing.T) {
+	ctx := context.Background()
+	bucket := &rangeReadCounter{Bucket: objstore.NewInMemBucket()}
+	conf := sstable.DefaultConfig()
+	conf.BlockSize = 256
+	tableStore := NewTableStore(bucket, conf, "")
+	builder := tableStore.TableBuilder()
+	for i := 0; i < 1000; i++ {
+		require.NoError(t, builder.AddValue([]byte(fmt.Sprintf("key%04d", i)), []byte(fmt.Sprintf("value%d", i))))
+	}
+	encodedSST, err := builder.Build()
+	require.NoError(t, err)
+	_, err = tableStore.WriteSST(ctx, sstable.NewIDWal(0), encodedSST)
+	require.NoError(t, err)
+	sst, err := tableStore.OpenSST(ctx, sstable.NewIDWal(0))
+	require.NoError(t, err)
+	index, err := tableStore.ReadIndex(ctx, sst)
+	require.NoError(t, err)
+	blocks := index.BlockMetaLength()
+	require.Greater(t, blocks, 20)
+
+	// iterate returns the number of range reads made to iterate the SST from `from`
+	iterate := func(reverse bool, from []byte, expected []int) int32 {
+		bucket.reads.Store(0)
+		var it *sstable.Iterator
+		switch {
+		case reverse && from != nil:
+			it, err = sstable.NewReverseIteratorAtKey(ctx, sst, from, tableStore)
+		case reverse:
+			it, err = sstable.NewReverseIterator(ctx, sst, tableStore)
+		case from != nil:
+			it, err = sstable.NewIteratorAtKey(ctx, sst, from, tableStore)
+		default:
+			it, err = sstable.NewIterator(ctx, sst, tableStore)
+		}
+		require.NoError(t, err)
+		for _, i := range expected {
+			if !assert2.Next(t, it, []byte(fmt.Sprintf("key%04d", i)), []byte(fmt.Sprintf("value%d", i))) {
+				t.FailNow()
+			}
+		}
+		_, ok := it.NextEntry(ctx)
+		assert.False(t, ok)
+		assert.True(t, it.Warnings().Empty())
+		return bucket.reads.Load()
+	}
+	keys := func(from, to, step int) []int {
+		var result []int
+		for i := from; i != to; i += step {
+			result = append(result, i)
+		}
+		return result
+	}
+
+	for _, tt := range []struct {
+		name     string
+		reverse  bool
+		from     []byte
+		expected []int
+	}{
+		{name: "Forward", expected: keys(0, 1000, 1)},
+		{name: "ForwardFromKey", from: []byte("key0500"), expected: keys(500, 1000, 1)},
+		{name: "Reverse", reverse: true, expected: keys(999, -1, -1)},
+		{name: "ReverseFromKey", reverse: true, from: []byte("key0500"), expected: keys(500, -1, -1)},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			// every block is read with its own request
+			tableStore.SetReadAhead(0)
+			perBlock := iterate(tt.reverse, tt.from, tt.expected)
+
+			// adjacent blocks are read with a single request
+			tableStore.SetReadAhead(8 * 1024)
+			readAhead := iterate(tt.reverse, tt.from, tt.expected)
+			assert.Less(t, readAhead*4, perBlock)
+
+			// the blocks of the SST are read with a single request, after the index
+			tableStore.SetReadAhead(1024 * 1024)
+			assert.Equal(t, int32(2), iterate(tt.reverse, tt.from, tt.expected))
+
+			// prefetched blocks are iterated in order, with a request per block
+			tableStore.SetReadAhead(0)
+			tableStore.SetPrefetchConcurrency(3)
+			defer tableStore.SetPrefetchConcurrency(0)
+			assert.Equal(t, perBlock, iterate(tt.reverse, tt.from, tt.expected))
+		})
+	}
+
+	// point reads read a single block
+	assert.Equal(t, uint64(0), tableStore.PointReads().ReadAheadBytes())
+}
+
+func TestIterPrefetches(t *testing.T) {
+	ctx := context.Background()
+	bucket := &rangeReadCounter{Bucket: objstore.NewInMemBucket()}
+	conf := sstable.DefaultConfig()
+	conf.BlockSize = 256
+	tableStore := NewTableStore(bucket, conf, "")
+	builder := tableStore.TableBuilder()
+	for i := 0; i < 1000; i++ {
+		require.NoError(t, builder.AddValue([]byte(fmt.Sprintf("key%04d", i)), []byte(fmt.Sprintf("value%d", i))))
+	}
+	encodedSST, err := builder.Build()
+	require.NoError(t, err)
+	_, err = tableStore.WriteSST(ctx, sstable.NewIDWal(0), encodedSST)
+	require.NoError(t, err)
+	sst, err := tableStore.OpenSST(ctx, sstable.NewIDWal(0))
+	require.NoError(t, err)
+
+	tableStore.SetPrefetchConcurrency(3)
+	assert.Equal(t, 0, tableStore.PointReads().PrefetchConcurrency())
+	bucket.reads.Store(0)
+	it, err := sstable.NewIterator(ctx, sst, tableStore)
+	require.NoError(t, err)
+	assert2.Next(t, it, []byte("key0000"), []byte("value0"))
+
+	// the index, the first block and the 3 blocks after it are requested
+	require.Eventually(t, func() bool { return bucket.reads.Load() == 5 }, time.Second, time.Millisecond)
+
+	// the requests are not cancelled when the ctx of the call which started them is done,
+	// as with compactions which cancel the ctx of each call once it returns
+	for i := 1; i < 1000; i++ {
+		callCtx, cancel := context.WithTimeout(ctx, time.Minute)
+		kv, ok := it.NextEntry(callCtx)
+		cancel()
+		require.True(t, ok)
+		assert.Equal(t, []byte(fmt.Sprintf("key%04d", i)), kv.Key)
+	}
+	_, ok := it.NextEntry(ctx)
+	assert.False(t, ok)
+	assert.True(t, it.Warnings().Empty())
+}
+
+func TestCachedOnly(t *testing.T) {
+	ctx := context.Background()
+	bucket := &rangeReadCounter{Bucket: objstore.NewInMemBucket()}
+	conf := sstable.DefaultConfig()
+	conf.BlockSize = 256
+	tableStore := NewTableStore(bucket, conf, "")
+	require.NoError(t, tableStore.SetDiskCache(t.TempDir(), 1024*1024))
+	builder := tableStore.TableBuilder()
+	for i := 0; i < 100; i++ {
+		require.NoError(t, builder.AddValue([]byte(fmt.Sprintf("key%04d", i)), []byte(fmt.Sprintf("value%d", i))))
+	}
+	encodedSST, err := builder.Build()
+	require.NoError(t, err)
+	id := sstable.NewIDCompacted(ulid.Make())
+	sst, err := tableStore.WriteSST(ctx, id, encodedSST)
+	require.NoError(t, err)
+
+	// nothing has been cached, such that object storage is not read
+	bucket.reads.Store(0)
+	_, err = sstable.NewIteratorAtKey(ctx, sst, []byte("key0050"), tableStore.CachedOnly())
+	assert.ErrorIs(t, err, errNotCached)
+	assert.Equal(t, int32(0), bucket.reads.Load())
+
+	// once the SST has been read, it is served by the disk cache
+	it, err := sstable.NewIterator(ctx, sst, tableStore)
+	require.NoError(t, err)
+	for i := 0; i < 100; i++ {
+		assert2.Next(t, it, []byte(fmt.Sprintf("key%04d", i)), []byte(fmt.Sprintf("value%d", i)))
+	}
+	bucket.reads.Store(0)
+	it, err = sstable.NewIteratorAtKey(ctx, sst, []byte("key0050"), tableStore.CachedOnly())
+	require.NoError(t, err)
+	assert2.Next(t, it, []byte("key0050"), []byte("value50"))
+	assert.True(t, it.Warnings().Empty())
+	assert.Equal(t, int32(0), bucket.reads.Load())
+}
+
 func TestIterFromKey(t *testing.T) {
 	bucket := objstore.NewInMemBucket()
 	conf := sstable.DefaultConfig()
@@ -456,6 +882,58 @@ func TestIterFromKey(t *testing.T) {
 	}
 }
 
+func TestReverseIterFromKey(t *testing.T) {
+	bucket := objstore.NewInMemBucket()
+	conf := sstable.DefaultConfig()
+	conf.MinFilterKeys = 1
+	tableStore := NewTableStore(bucket, conf, "")
+
+	keyGen := common.NewOrderedBytesGeneratorWithByteRange([]byte("bbbbbbbbbbbbbbbb"), byte('a'), byte('y'))
+	valGen := common.NewOrderedBytesGeneratorWithByteRange([]byte("1111111111111111"), byte(1), byte(26))
+
+	ctx := context.Background()
+	sst, nKeys, err := buildSSTWithNBlocks(ctx, 3, tableStore, keyGen, valGen)
+	require.NoError(t, err)
+
+	collect := func(iter *sstable.Iterator) [][]byte {
+		var keys [][]byte
+		for {
+			e, ok := iter.NextEntry(ctx)
+			if !ok {
+				return keys
+			}
+			keys = append(keys, e.Key)
+		}
+	}
+
+	forward, err := sstable.NewIterator(ctx, sst, tableStore)
+	require.NoError(t, err)
+	keys := collect(forward)
+	require.Len(t, keys, nKeys)
+	reversed := slices.Clone(keys)
+	slices.Reverse(reversed)
+
+	reverse, err := sstable.NewReverseIterator(ctx, sst, tableStore)
+	require.NoError(t, err)
+	assert.Equal(t, reversed, collect(reverse))
+
+	for i, key := range keys {
+		// starting at a key includes the key, and starting between keys begins at the lesser key
+		for _, from := range [][]byte{key, append(bytes.Clone(key), 0)} {
+			reverse, err := sstable.NewReverseIteratorAtKey(ctx, sst, from, tableStore)
+			require.NoError(t, err)
+			assert.Equal(t, reversed[nKeys-1-i:], collect(reverse))
+		}
+	}
+
+	reverse, err = sstable.NewReverseIteratorAtKey(ctx, sst, []byte("a"), tableStore)
+	require.NoError(t, err)
+	assert.Empty(t, collect(reverse))
+	reverse, err = sstable.NewReverseIteratorAtKey(ctx, sst, []byte("z"), tableStore)
+	require.NoError(t, err)
+	assert.Equal(t, reversed, collect(reverse))
+}
+
 func TestIterFromKeySmallerThanFirst(t *testing.T) {
 	bucket := objstore.NewInMemBucket()
 	conf := sstable.DefaultConfig()
@@ -575,3 +1053,209 @@ func TestSSTWriter(t *testing.T) {
 	_, ok := iterator.NextEntry(context.Background())
 	assert.False(t, ok)
 }
+
+// uploadRecorder records the uploads made to a bucket and the bytes they have read. It does not
+// unwrap to the bucket it records, such that SSTs are streamed to it. As the in-memory bucket is
+// locked while it reads an upload, no other operation may be made while an SST is streamed.
+type uploadRecorder struct {
+	objstore.Bucket
+	uploads atomic.Int32
+	read    atomic.Int64
+}
+
+func (b *uploadRecorder) Upload(ctx context.Context, name string, r io.Reader) error {
+	b.uploads.Add(1)
+	return b.Bucket.Upload(ctx, name, readFunc(func(p []byte) (int, error) {
+		n, err := r.Read(p)
+		b.read.Add(int64(n))
+		return n, err
+	}))
+}
+
+type readFunc func(p []byte) (int, error)
+
+func (f readFunc) Read(p []byte) (int, error) { return f(p) }
+
+func TestSSTWriterStreamsBlocks(t *testing.T) {
+	ctx := context.Background()
+	bucket := &uploadRecorder{Bucket: objstore.NewInMemBucket()}
+	conf := sstable.DefaultConfig()
+	conf.BlockSize = 256
+	tableStore := NewTableStore(bucket, conf, "")
+	tableStore.SetWriteBufferSize(1024)
+
+	add := func(writer *EncodedSSTableWriter, n int) {
+		for i := 0; i < n; i++ {
+			key := []byte(fmt.Sprintf("key%05d", i))
+			require.NoError(t, writer.Add(key, mo.Some(bytes.Repeat([]byte{'v'}, 32))))
+		}
+	}
+
+	// the blocks of the SST are uploaded before the writer is closed
+	sstID := sstable.NewIDCompacted(ulid.Make())
+	writer := tableStore.TableWriter(sstID)
+	add(writer, 1000)
+	assert.Equal(t, int32(1), bucket.uploads.Load())
+	assert.Greater(t, bucket.read.Load(), int64(0))
+	assert.LessOrEqual(t, len(writer.buffer), 1024)
+
+	sst, err := writer.Close(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), bucket.uploads.Load())
+	attrs, err := bucket.Attributes(ctx, tableStore.sstPath(sstID))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(attrs.Size), tableStore.BytesWritten())
+
+	iterator, err := sstable.NewIterator(ctx, sst, tableStore)
+	require.NoError(t, err)
+	for i := 0; i < 1000; i++ {
+		assert2.NextEntry(t, iterator, []byte(fmt.Sprintf("key%05d", i)), bytes.Repeat([]byte{'v'}, 32))
+	}
+	_, ok := iterator.NextEntry(ctx)
+	assert.False(t, ok)
+
+	// an SST which fits within the buffer is uploaded when the writer is closed
+	bucket.uploads.Store(0)
+	writer = tableStore.TableWriter(sstable.NewIDCompacted(ulid.Make()))
+	add(writer, 10)
+	assert.Equal(t, int32(0), bucket.uploads.Load())
+	_, err = writer.Close(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), bucket.uploads.Load())
+
+	// an aborted SST is not written
+	sstID = sstable.NewIDCompacted(ulid.Make())
+	writer = tableStore.TableWriter(sstID)
+	add(writer, 1000)
+	writer.Abort()
+	exists, err := bucket.Exists(ctx, tableStore.sstPath(sstID))
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	// SSTs are not streamed to the in-memory bucket, which is locked while it reads an upload
+	tableStore = NewTableStore(objstore.NewInMemBucket(), conf, "")
+	tableStore.SetWriteBufferSize(1024)
+	writer = tableStore.TableWriter(sstable.NewIDCompacted(ulid.Make()))
+	add(writer, 1000)
+	assert.Nil(t, writer.upload)
+	_, err = writer.Close(ctx)
+	require.NoError(t, err)
+}
+
+// TestSSTWriterStreamsToFilesystem streams SSTs to the filesystem bucket, which, unlike the
+// in-memory bucket, may be read while it reads an upload
+func TestSSTWriterStreamsToFilesystem(t *testing.T) {
+	ctx := context.Background()
+	fsBucket, err := filesystem.NewBucket(t.TempDir())
+	require.NoError(t, err)
+	bucket := &uploadRecorder{Bucket: fsBucket}
+	conf := sstable.DefaultConfig()
+	conf.BlockSize = 256
+	tableStore := NewTableStore(bucket, conf, "")
+	tableStore.SetWriteBufferSize(1024)
+	require.True(t, tableStore.streamUploads)
+
+	value := bytes.Repeat([]byte{'v'}, 32)
+	other, err := tableStore.WriteSST(ctx, sstable.NewIDCompacted(ulid.Make()), buildSST(t, tableStore, 10))
+	require.NoError(t, err)
+	otherSize := tableStore.BytesWritten()
+
+	// the memory buffered by the writer is bounded across the flushes of the buffer, as each
+	// flush is read by the upload before the writer continues
+	sstID := sstable.NewIDCompacted(ulid.Make())
+	writer := tableStore.TableWriter(sstID)
+	flushes := 0
+	for i := 0; i < 1000; i++ {
+		written := tableStore.BytesWritten()
+		require.NoError(t, writer.Add([]byte(fmt.Sprintf("key%05d", i)), mo.Some(value)))
+		assert.Less(t, len(writer.buffer), 1024)
+		if tableStore.BytesWritten() > written {
+			flushes++
+		}
+	}
+	assert.Greater(t, flushes, 10)
+	assert.Equal(t, int32(2), bucket.uploads.Load())
+	// the pipe of the upload holds no blocks of its own, every flushed block has been read
+	require.Eventually(t, func() bool {
+		return uint64(bucket.read.Load()) == tableStore.BytesWritten()
+	}, time.Second, time.Millisecond)
+
+	// other SSTs are read while the upload is in progress
+	iterator, err := sstable.NewIterator(ctx, other, tableStore)
+	require.NoError(t, err)
+	for i := 0; i < 10; i++ {
+		assert2.NextEntry(t, iterator, []byte(fmt.Sprintf("key%05d", i)), value)
+	}
+
+	_, err = writer.Close(ctx)
+	require.NoError(t, err)
+	attrs, err := bucket.Attributes(ctx, tableStore.sstPath(sstID))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(attrs.Size), tableStore.BytesWritten()-otherSize)
+
+	// an SST aborted in the middle of its upload leaves no object behind, although the
+	// filesystem bucket has written the blocks read by the upload
+	sstID = sstable.NewIDCompacted(ulid.Make())
+	writer = tableStore.TableWriter(sstID)
+	read := bucket.read.Load()
+	for i := 0; i < 1000; i++ {
+		require.NoError(t, writer.Add([]byte(fmt.Sprintf("key%05d", i)), mo.Some(value)))
+	}
+	require.Greater(t, bucket.read.Load(), read)
+	writer.Abort()
+	exists, err := bucket.Exists(ctx, tableStore.sstPath(sstID))
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
+
+// buildSST builds an SST of `n` keys, each with a value of 32 bytes
+func buildSST(t *testing.T, tableStore *TableStore, n int) *sstable.Table {
+	builder := tableStore.TableBuilder()
+	for i := 0; i < n; i++ {
+		require.NoError(t, builder.AddValue([]byte(fmt.Sprintf("key%05d", i)), bytes.Repeat([]byte{'v'}, 32)))
+	}
+	encodedSST, err := builder.Build()
+	require.NoError(t, err)
+	return encodedSST
+}
+
+func TestTryReadFilterForKeyReadsPartition(t *testing.T) {
+	bucket := objstore.NewInMemBucket()
+	conf := sstable.DefaultConfig()
+	conf.BlockSize = 128
+	conf.MinFilterKeys = 0
+	tableStore := NewTableStore(bucket, conf, "")
+	tableStore.SetFilterPartitions(2)
+	builder := tableStore.TableBuilderAtLevel(SSTLevelCompacted)
+	var keys [][]byte
+	for i := 0; i < 100; i++ {
+		key := []byte(fmt.Sprintf("key%03d", i))
+		keys = append(keys, key)
+		require.NoError(t, builder.AddValue(key, []byte(fmt.Sprintf("value%03d", i))))
+	}
+	encodedSST, err := builder.Build()
+	require.NoError(t, err)
+	require.True(t, encodedSST.Info.FilterIndexLen > 0)
+
+	ctx := context.Background()
+	sstHandle, err := tableStore.WriteSST(ctx, sstable.NewIDCompacted(ulid.Make()), encodedSST)
+	require.NoError(t, err)
+
+	for _, key := range keys {
+		filter, fetched, err := tableStore.TryReadFilterForKey(ctx, sstHandle, key)
+		require.NoError(t, err)
+		require.True(t, fetched)
+		f, ok := filter.Get()
+		require.True(t, ok)
+		assert.True(t, f.HasKey(key))
+	}
+
+	// The monolithic filter of a partitioned filter is absent
+	filter, err := tableStore.ReadFilter(ctx, sstHandle)
+	require.NoError(t, err)
+	assert.True(t, filter.IsAbsent())
+
+	filters, err := tableStore.ReadFilterPartitions(ctx, sstHandle)
+	require.NoError(t, err)
+	assert.True(t, len(filters) > 1)
+}
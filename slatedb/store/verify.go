@@ -0,0 +1,274 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/slatedb/slatedb-go/internal"
+	"github.com/slatedb/slatedb-go/internal/compress"
+	"github.com/slatedb/slatedb-go/internal/sstable"
+	"github.com/slatedb/slatedb-go/internal/sstable/block"
+	"github.com/slatedb/slatedb-go/internal/sstable/bloom"
+	"github.com/slatedb/slatedb-go/slatedb/common"
+)
+
+// verifyBatchBlocks is the number of blocks VerifySST reads with a single range request
+const verifyBatchBlocks = 64
+
+// CorruptionPart is the part of an SST which is corrupted
+type CorruptionPart int
+
+const (
+	// CorruptionMissing indicates the SST does not exist in object storage
+	CorruptionMissing CorruptionPart = iota + 1
+	// CorruptionInfo indicates the info of the SST cannot be decoded, or does not match the manifest
+	CorruptionInfo
+	// CorruptionIndex indicates the index of the SST cannot be decoded, or does not describe its blocks
+	CorruptionIndex
+	// CorruptionFilter indicates the filter of the SST, or its sidecar filter, cannot be decoded
+	CorruptionFilter
+	// CorruptionBlock indicates a block of the SST cannot be decoded, such as when its checksum does not match
+	CorruptionBlock
+)
+
+// String returns the part as a string
+func (p CorruptionPart) String() string {
+	switch p {
+	case CorruptionMissing:
+		return "Missing"
+	case CorruptionInfo:
+		return "Info"
+	case CorruptionIndex:
+		return "Index"
+	case CorruptionFilter:
+		return "Filter"
+	case CorruptionBlock:
+		return "Block"
+	default:
+		return "Unknown"
+	}
+}
+
+// SSTCorruption describes a corrupted part of an SST found by VerifySST
+type SSTCorruption struct {
+	// SSTID is the ID of the corrupted SST
+	SSTID string
+
+	// Part is the part of the SST which is corrupted
+	Part CorruptionPart
+
+	// Block is the index of the corrupted block within the SST if Part is CorruptionBlock
+	Block uint64
+
+	// Err describes the corruption
+	Err error
+}
+
+// String returns a string including all the details of the corruption
+func (c SSTCorruption) String() string {
+	if c.Part == CorruptionBlock {
+		return fmt.Sprintf("Corruption detected: SST=%s, Part=%s, Block=%d, Error=%s", c.SSTID, c.Part, c.Block, c.Err)
+	}
+	return fmt.Sprintf("Corruption detected: SST=%s, Part=%s, Error=%s", c.SSTID, c.Part, c.Err)
+}
+
+// VerifySST reads every part of the SST from object storage, bypassing the block, filter and disk
+// caches, and validates the checksums of its info, index, filter and blocks along with the
+// consistency of the index with the blocks. If the manifest's info of the SST is not nil, it must
+// match the info stored in the SST. The blocks are read `verifyBatchBlocks` at a time, such that
+// the SST is never held in memory.
+//
+// VerifySST returns the number of blocks which were verified and every corruption found. An
+// error is only returned if object storage could not be read, in which case the SST may be
+// partially verified.
+func (ts *TableStore) VerifySST(ctx context.Context, handle *sstable.Handle) (int, []SSTCorruption, error) {
+	v := sstVerifier{handle: handle}
+	obj := NewBucketObject(ts.bucket, ts.sstPath(handle.Id))
+	size, err := obj.Len(ctx)
+	if err != nil {
+		if ts.bucket.IsObjNotFoundErr(err) {
+			v.corrupted(CorruptionMissing, 0, err)
+			return 0, v.corruptions, nil
+		}
+		return 0, nil, err
+	}
+
+	info, err := v.readInfo(ctx, obj, size)
+	if err != nil || info == nil {
+		return 0, v.corruptions, err
+	}
+	if err := v.verifyFilter(ctx, ts, obj, info); err != nil {
+		return 0, v.corruptions, err
+	}
+	index, err := v.readIndex(ctx, obj, info)
+	if err != nil || index == nil {
+		return 0, v.corruptions, err
+	}
+
+	verified := 0
+	numBlocks := uint64(len(index.BlockMeta()))
+	for start := uint64(0); start < numBlocks; start += verifyBatchBlocks {
+		rng := common.Range{Start: start, End: min(start+verifyBatchBlocks, numBlocks)}
+		encodedBlocks, err := sstable.ReadEncodedBlocks(ctx, info, index, rng, obj)
+		if err != nil {
+			return verified, v.corruptions, err
+		}
+		for j, encoded := range encodedBlocks {
+			i := rng.Start + uint64(j)
+			var b block.Block
+			if err := sstable.DecodeBlock(&b, encoded, info); err != nil {
+				v.corrupted(CorruptionBlock, i, err)
+				continue
+			}
+			if err := verifyRows(ctx, &b, index.BlockMeta()[i].FirstKey); err != nil {
+				v.corrupted(CorruptionBlock, i, err)
+				continue
+			}
+			verified++
+		}
+	}
+	return verified, v.corruptions, nil
+}
+
+// verifyRows decodes every row of the block, and validates that the first key of the block
+// is `firstKey` and that the keys are in order
+func verifyRows(ctx context.Context, b *block.Block, firstKey []byte) error {
+	iter := block.NewIterator(b)
+	var prev []byte
+	for {
+		entry, ok := iter.NextEntry(ctx)
+		if !ok {
+			break
+		}
+		if prev == nil && !bytes.Equal(entry.Key, firstKey) {
+			return internal.Err("first key of block does not match the index")
+		}
+		if prev != nil && bytes.Compare(entry.Key, prev) <= 0 {
+			return internal.Err("keys of block are out of order")
+		}
+		prev = entry.Key
+	}
+	if err := iter.Warnings().If(); err != nil {
+		return fmt.Errorf("corrupted block: %w", err)
+	}
+	return nil
+}
+
+// sstVerifier accumulates the corruptions found by VerifySST
+type sstVerifier struct {
+	handle      *sstable.Handle
+	corruptions []SSTCorruption
+}
+
+func (v *sstVerifier) corrupted(part CorruptionPart, block uint64, err error) {
+	v.corruptions = append(v.corruptions, SSTCorruption{SSTID: v.handle.Id.String(), Part: part, Block: block, Err: err})
+}
+
+// readInfo returns the info of the SST, or nil if the info is corrupted
+func (v *sstVerifier) readInfo(ctx context.Context, obj ReadOnlyObject, size int) (*sstable.Info, error) {
+	if size <= common.SizeOfUint32 {
+		v.corrupted(CorruptionInfo, 0, internal.Err("sst is too short; %d bytes", size))
+		return nil, nil
+	}
+	offsetIndex := uint64(size - common.SizeOfUint32)
+	offsetBytes, err := obj.ReadRange(ctx, common.Range{Start: offsetIndex, End: uint64(size)})
+	if err != nil {
+		return nil, err
+	}
+	metadataOffset := uint64(binary.BigEndian.Uint32(offsetBytes))
+	if metadataOffset >= offsetIndex {
+		v.corrupted(CorruptionInfo, 0, internal.Err("info offset '%d' is beyond the end of the sst", metadataOffset))
+		return nil, nil
+	}
+	metadataBytes, err := obj.ReadRange(ctx, common.Range{Start: metadataOffset, End: offsetIndex})
+	if err != nil {
+		return nil, err
+	}
+	info, err := sstable.DecodeInfo(metadataBytes)
+	if err != nil {
+		v.corrupted(CorruptionInfo, 0, err)
+		return nil, nil
+	}
+
+	if expected := v.handle.Info; expected != nil {
+		if !bytes.Equal(expected.FirstKey, info.FirstKey) || expected.IndexOffset != info.IndexOffset ||
+			expected.IndexLen != info.IndexLen || expected.FilterOffset != info.FilterOffset ||
+			expected.FilterLen != info.FilterLen || expected.CompressionCodec != info.CompressionCodec ||
+			expected.BlockChecksum != info.BlockChecksum {
+			v.corrupted(CorruptionInfo, 0, internal.Err("info does not match the manifest"))
+			return nil, nil
+		}
+	}
+	if info.IndexOffset+info.IndexLen > metadataOffset || info.FilterOffset+info.FilterLen > info.IndexOffset {
+		v.corrupted(CorruptionInfo, 0, internal.Err("index or filter is beyond the end of the sst"))
+		return nil, nil
+	}
+	return info, nil
+}
+
+// verifyFilter validates the filter of the SST and its sidecar filter, if any
+func (v *sstVerifier) verifyFilter(ctx context.Context, ts *TableStore, obj ReadOnlyObject, info *sstable.Info) error {
+	if info.FilterLen > 0 {
+		filterBytes, err := obj.ReadRange(ctx, common.Range{Start: info.FilterOffset, End: info.FilterOffset + info.FilterLen})
+		if err != nil {
+			return err
+		}
+		if info.FilterIndexLen > 0 {
+			_, _, err = sstable.DecodeFilterPartitions(filterBytes, info)
+		} else {
+			_, err = bloom.Decode(filterBytes, info.CompressionCodec)
+		}
+		if err != nil {
+			v.corrupted(CorruptionFilter, 0, err)
+		}
+	}
+
+	if _, sidecar := ts.filterSidecars.Load(v.handle.Id.Value); !sidecar {
+		return nil
+	}
+	data, err := NewBucketObject(ts.bucket, ts.filterSidecarPath(v.handle.Id)).Read(ctx)
+	if err != nil {
+		if ts.bucket.IsObjNotFoundErr(err) {
+			v.corrupted(CorruptionFilter, 0, fmt.Errorf("sidecar filter is missing: %w", err))
+			return nil
+		}
+		return err
+	}
+	if _, err := bloom.Decode(data, compress.CodecNone); err != nil {
+		v.corrupted(CorruptionFilter, 0, fmt.Errorf("sidecar filter is corrupted: %w", err))
+	}
+	return nil
+}
+
+// readIndex returns the index of the SST, or nil if the index is corrupted
+func (v *sstVerifier) readIndex(ctx context.Context, obj ReadOnlyObject, info *sstable.Info) (*sstable.Index, error) {
+	indexBytes, err := obj.ReadRange(ctx, common.Range{Start: info.IndexOffset, End: info.IndexOffset + info.IndexLen})
+	if err != nil {
+		return nil, err
+	}
+	index, err := sstable.DecodeIndex(indexBytes, info.CompressionCodec)
+	if err != nil {
+		v.corrupted(CorruptionIndex, 0, err)
+		return nil, nil
+	}
+
+	// The blocks are stored in order from the start of the SST up to the filter
+	metas := index.BlockMeta()
+	for i, meta := range metas {
+		if i == 0 && (meta.Offset != 0 || !bytes.Equal(meta.FirstKey, info.FirstKey)) {
+			v.corrupted(CorruptionIndex, 0, internal.Err("first block does not start the sst"))
+			return nil, nil
+		}
+		if i > 0 && (meta.Offset <= metas[i-1].Offset || bytes.Compare(meta.FirstKey, metas[i-1].FirstKey) <= 0) {
+			v.corrupted(CorruptionIndex, 0, internal.Err("block '%d' is out of order", i))
+			return nil, nil
+		}
+		if meta.Offset >= info.FilterOffset {
+			v.corrupted(CorruptionIndex, 0, internal.Err("block '%d' is beyond the end of the blocks", i))
+			return nil, nil
+		}
+	}
+	return index, nil
+}
@@ -0,0 +1,79 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore"
+
+	"github.com/slatedb/slatedb-go/internal/sstable"
+	"github.com/slatedb/slatedb-go/slatedb/common"
+)
+
+func TestVerifySST(t *testing.T) {
+	ctx := context.Background()
+	bucket := objstore.NewInMemBucket()
+	conf := sstable.DefaultConfig()
+	conf.BlockSize = 128
+	tableStore := NewTableStore(bucket, conf, "")
+	keyGen := common.NewOrderedBytesGeneratorWithByteRange([]byte("aaaaaaaaaaaaaaaa"), byte('a'), byte('z'))
+	valGen := common.NewOrderedBytesGeneratorWithByteRange([]byte("1111111111111111"), byte(1), byte(26))
+
+	// More blocks than are read at once
+	sst, _, err := buildSSTWithNBlocks(ctx, verifyBatchBlocks+6, tableStore, keyGen, valGen)
+	require.NoError(t, err)
+	index, err := tableStore.ReadIndex(ctx, sst)
+	require.NoError(t, err)
+	numBlocks := len(index.BlockMeta())
+	path := tableStore.sstPath(sst.Id)
+	data, err := NewBucketObject(bucket, path).Read(ctx)
+	require.NoError(t, err)
+
+	t.Run("Valid", func(t *testing.T) {
+		blocks, corruptions, err := tableStore.VerifySST(ctx, sst)
+		require.NoError(t, err)
+		assert.Equal(t, numBlocks, blocks)
+		assert.Empty(t, corruptions)
+	})
+
+	t.Run("CorruptedBlocks", func(t *testing.T) {
+		corrupted := bytes.Clone(data)
+		corrupted[index.BlockMeta()[1].Offset]++
+		corrupted[index.BlockMeta()[verifyBatchBlocks+2].Offset]++
+		require.NoError(t, bucket.Upload(ctx, path, bytes.NewReader(corrupted)))
+
+		blocks, corruptions, err := tableStore.VerifySST(ctx, sst)
+		require.NoError(t, err)
+		assert.Equal(t, numBlocks-2, blocks)
+		require.Len(t, corruptions, 2)
+		assert.Equal(t, CorruptionBlock, corruptions[0].Part)
+		assert.Equal(t, uint64(1), corruptions[0].Block)
+		assert.Equal(t, sst.Id.String(), corruptions[0].SSTID)
+		assert.ErrorContains(t, corruptions[0].Err, "crc32c checksum mismatch")
+		assert.Equal(t, uint64(verifyBatchBlocks+2), corruptions[1].Block)
+	})
+
+	t.Run("CorruptedIndex", func(t *testing.T) {
+		corrupted := bytes.Clone(data)
+		corrupted[sst.Info.IndexOffset+1]++
+		require.NoError(t, bucket.Upload(ctx, path, bytes.NewReader(corrupted)))
+
+		blocks, corruptions, err := tableStore.VerifySST(ctx, sst)
+		require.NoError(t, err)
+		assert.Equal(t, 0, blocks)
+		require.Len(t, corruptions, 1)
+		assert.Equal(t, CorruptionIndex, corruptions[0].Part)
+	})
+
+	t.Run("Missing", func(t *testing.T) {
+		require.NoError(t, bucket.Delete(ctx, path))
+
+		_, corruptions, err := tableStore.VerifySST(ctx, sst)
+		require.NoError(t, err)
+		require.Len(t, corruptions, 1)
+		assert.Equal(t, CorruptionMissing, corruptions[0].Part)
+	})
+}
@@ -1,8 +1,11 @@
 package table
 
 import (
+	"bytes"
 	"context"
+	"slices"
 	"sync/atomic"
+	"time"
 
 	"github.com/huandu/skiplist"
 	"github.com/samber/mo"
@@ -15,9 +18,12 @@ import (
 // ------------------------------------------------
 
 type KVTable struct {
-	// skl skipList stores key ([]byte), value (Value) pairs
+	// skl skipList stores key ([]byte), tableEntry pairs
 	skl *skiplist.SkipList
 
+	// rangeTombstones are the range tombstones written to the KVTable, in the order they were written
+	rangeTombstones []types.RangeTombstone
+
 	// size of KVTable changes when we put/delete a key
 	size atomic.Int64
 
@@ -29,6 +35,25 @@ type KVTable struct {
 	isDurableCh chan bool
 }
 
+// tableEntry is the value stored in the skipList for each key
+type tableEntry struct {
+	// value is the encoded types.Value, see types.Value.ToBytes
+	value    []byte
+	seq      uint64
+	expireAt time.Time
+	checksum mo.Option[uint32]
+}
+
+func (te tableEntry) toRowEntry(key []byte) types.RowEntry {
+	return types.RowEntry{
+		Key:      key,
+		Value:    types.ValueFromBytes(te.value),
+		Seq:      te.seq,
+		ExpireAt: te.expireAt,
+		Checksum: te.checksum,
+	}
+}
+
 func newKVTable() *KVTable {
 	return &KVTable{
 		skl:         skiplist.New(skiplist.Bytes),
@@ -36,26 +61,58 @@ func newKVTable() *KVTable {
 	}
 }
 
+// Size returns the size in bytes of the keys and values of the KVTable
+func (t *KVTable) Size() int64 {
+	return t.size.Load()
+}
+
 func (t *KVTable) get(key []byte) mo.Option[types.Value] {
 	elem := t.skl.Get(key)
 	if elem == nil {
 		return mo.None[types.Value]()
 	}
 
-	val := elem.Value.([]byte)
+	val := elem.Value.(tableEntry).value
 	return mo.Some(types.ValueFromBytes(val))
 }
 
+func (t *KVTable) getEntry(key []byte) mo.Option[types.RowEntry] {
+	elem := t.skl.Get(key)
+	if elem == nil {
+		return mo.None[types.RowEntry]()
+	}
+	return mo.Some(elem.Value.(tableEntry).toRowEntry(key))
+}
+
 func (t *KVTable) put(entry types.RowEntry) int64 {
 	oldSize := t.existingKVSize(entry.Key)
 	valueBytes := entry.Value.ToBytes()
-	t.skl.Set(entry.Key, valueBytes)
+	t.skl.Set(entry.Key, tableEntry{value: valueBytes, seq: entry.Seq, expireAt: entry.ExpireAt, checksum: entry.Checksum})
 
 	newSize := int64(len(entry.Key) + len(valueBytes))
 	t.size.Add(newSize - oldSize)
 	return newSize
 }
 
+func (t *KVTable) deleteRange(tombstone types.RangeTombstone) int64 {
+	t.rangeTombstones = append(t.rangeTombstones, tombstone)
+	size := int64(len(tombstone.Start) + len(tombstone.End))
+	t.size.Add(size)
+	return size
+}
+
+// rangeTombstonesOverlapping returns a copy of the range tombstones which overlap the range [start, end).
+// If start or end is empty, the range is unbounded on that side.
+func (t *KVTable) rangeTombstonesOverlapping(start []byte, end []byte) []types.RangeTombstone {
+	result := make([]types.RangeTombstone, 0)
+	for _, tombstone := range t.rangeTombstones {
+		if tombstone.Overlaps(start, end) {
+			result = append(result, tombstone)
+		}
+	}
+	return result
+}
+
 func (t *KVTable) iter() *KVTableIterator {
 	return newKVTableIterator(t.skl.Front())
 }
@@ -65,6 +122,25 @@ func (t *KVTable) rangeFrom(start []byte) *KVTableIterator {
 	return newKVTableIterator(elem)
 }
 
+// rangeEntries returns the entries with keys in the range [start, end). If start
+// or end is empty, the range is unbounded on that side.
+func (t *KVTable) rangeEntries(start []byte, end []byte) []types.RowEntry {
+	elem := t.skl.Front()
+	if len(start) != 0 {
+		elem = t.skl.Find(start)
+	}
+
+	entries := make([]types.RowEntry, 0)
+	for ; elem != nil; elem = elem.Next() {
+		key := elem.Key().([]byte)
+		if len(end) != 0 && bytes.Compare(key, end) >= 0 {
+			break
+		}
+		entries = append(entries, elem.Value.(tableEntry).toRowEntry(key))
+	}
+	return entries
+}
+
 func (t *KVTable) existingKVSize(key []byte) int64 {
 	value := t.get(key)
 	if value.IsPresent() {
@@ -96,7 +172,7 @@ func (t *KVTable) toBytes() []byte {
 	for current != nil {
 		elem := current.Element()
 		resBytes = append(resBytes, elem.Key().([]byte)...)
-		resBytes = append(resBytes, elem.Value.([]byte)...)
+		resBytes = append(resBytes, elem.Value.(tableEntry).value...)
 		current = current.Next()
 	}
 	return resBytes
@@ -107,14 +183,14 @@ func (t *KVTable) clone() *KVTable {
 	current := t.skl.Front()
 	for current != nil {
 		key := current.Key().([]byte)
-		val := current.Value.([]byte)
-		skl.Set(key, val)
+		skl.Set(key, current.Value.(tableEntry))
 		current = current.Next()
 	}
 
 	return &KVTable{
-		isDurableCh: make(chan bool),
-		skl:         skl,
+		isDurableCh:     make(chan bool),
+		skl:             skl,
+		rangeTombstones: slices.Clone(t.rangeTombstones),
 	}
 }
 
@@ -140,9 +216,5 @@ func (iter *KVTableIterator) NextEntry() (mo.Option[types.RowEntry], error) {
 
 	iter.element = iter.element.Next()
 
-	valueBytes := elem.Value.([]byte)
-	return mo.Some(types.RowEntry{
-		Key:   elem.Key().([]byte),
-		Value: types.ValueFromBytes(valueBytes),
-	}), nil
+	return mo.Some(elem.Value.(tableEntry).toRowEntry(elem.Key().([]byte))), nil
 }
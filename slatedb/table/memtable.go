@@ -34,18 +34,40 @@ func (m *Memtable) Put(entry types.RowEntry) int64 {
 	return m.table.put(entry)
 }
 
+// DeleteRange adds the range tombstone and returns the size in bytes of the range tombstone added
+func (m *Memtable) DeleteRange(tombstone types.RangeTombstone) int64 {
+	m.Lock()
+	defer m.Unlock()
+	return m.table.deleteRange(tombstone)
+}
+
 func (m *Memtable) Get(key []byte) mo.Option[types.Value] {
 	m.RLock()
 	defer m.RUnlock()
 	return m.table.get(key)
 }
 
+// GetEntry returns the entry of the key, including its sequence number and expiry
+func (m *Memtable) GetEntry(key []byte) mo.Option[types.RowEntry] {
+	m.RLock()
+	defer m.RUnlock()
+	return m.table.getEntry(key)
+}
+
 func (m *Memtable) Size() int64 {
 	m.RLock()
 	defer m.RUnlock()
 	return m.table.size.Load()
 }
 
+// Table returns the KVTable of the memtable, whose AwaitWALFlush returns once the memtable is
+// flushed to L0 when the WAL is disabled
+func (m *Memtable) Table() *KVTable {
+	m.RLock()
+	defer m.RUnlock()
+	return m.table
+}
+
 func (m *Memtable) LastWalID() mo.Option[uint64] {
 	m.RLock()
 	defer m.RUnlock()
@@ -66,6 +88,22 @@ func (m *Memtable) RangeFrom(startKey []byte) *KVTableIterator {
 	return m.table.rangeFrom(startKey)
 }
 
+// Range returns a copy of the entries with keys in the range [start, end). If start
+// or end is empty, the range is unbounded on that side.
+func (m *Memtable) Range(start []byte, end []byte) []types.RowEntry {
+	m.RLock()
+	defer m.RUnlock()
+	return m.table.rangeEntries(start, end)
+}
+
+// RangeTombstones returns a copy of the range tombstones which overlap the range [start, end). If start
+// or end is empty, the range is unbounded on that side.
+func (m *Memtable) RangeTombstones(start []byte, end []byte) []types.RangeTombstone {
+	m.RLock()
+	defer m.RUnlock()
+	return m.table.rangeTombstonesOverlapping(start, end)
+}
+
 func (m *Memtable) Iter() *KVTableIterator {
 	m.RLock()
 	defer m.RUnlock()
@@ -105,6 +143,19 @@ func (im *ImmutableMemtable) Get(key []byte) mo.Option[types.Value] {
 	return im.table.get(key)
 }
 
+// GetEntry returns the entry of the key, including its sequence number and expiry
+func (im *ImmutableMemtable) GetEntry(key []byte) mo.Option[types.RowEntry] {
+	im.RLock()
+	defer im.RUnlock()
+	return im.table.getEntry(key)
+}
+
+func (im *ImmutableMemtable) Table() *KVTable {
+	im.RLock()
+	defer im.RUnlock()
+	return im.table
+}
+
 func (im *ImmutableMemtable) LastWalID() uint64 {
 	im.RLock()
 	defer im.RUnlock()
@@ -117,6 +168,20 @@ func (im *ImmutableMemtable) Iter() *KVTableIterator {
 	return im.table.iter()
 }
 
+// Range returns the entries with keys in the range [start, end). See Memtable.Range
+func (im *ImmutableMemtable) Range(start []byte, end []byte) []types.RowEntry {
+	im.RLock()
+	defer im.RUnlock()
+	return im.table.rangeEntries(start, end)
+}
+
+// RangeTombstones returns the range tombstones which overlap the range [start, end). See Memtable.RangeTombstones
+func (im *ImmutableMemtable) RangeTombstones(start []byte, end []byte) []types.RangeTombstone {
+	im.RLock()
+	defer im.RUnlock()
+	return im.table.rangeTombstonesOverlapping(start, end)
+}
+
 func (im *ImmutableMemtable) Clone() *ImmutableMemtable {
 	im.RLock()
 	defer im.RUnlock()
@@ -221,3 +221,24 @@ func TestMemtableClone(t *testing.T) {
 	assert.Equal(t, immMemtable.LastWalID(), clonedImmMemtable.LastWalID())
 	assert.True(t, bytes.Equal(immMemtable.table.toBytes(), clonedImmMemtable.table.toBytes()))
 }
+
+func TestMemtableRange(t *testing.T) {
+	memtable := NewMemtable()
+	for _, key := range []string{"abc333", "abc111", "abc555", "abc222", "abc444"} {
+		memtable.Put(types.RowEntry{Key: []byte(key), Value: types.Value{Value: []byte(key), Kind: types.KindKeyValue}})
+	}
+
+	keys := func(entries []types.RowEntry) []string {
+		result := make([]string, 0, len(entries))
+		for _, e := range entries {
+			result = append(result, string(e.Key))
+		}
+		return result
+	}
+
+	assert.Equal(t, []string{"abc222", "abc333"}, keys(memtable.Range([]byte("abc200"), []byte("abc444"))))
+	assert.Equal(t, []string{"abc111", "abc222"}, keys(memtable.Range(nil, []byte("abc333"))))
+	assert.Equal(t, []string{"abc444", "abc555"}, keys(memtable.Range([]byte("abc444"), nil)))
+	assert.Len(t, memtable.Range(nil, nil), 5)
+	assert.Empty(t, memtable.Range([]byte("abc600"), nil))
+}
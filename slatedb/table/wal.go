@@ -29,12 +29,26 @@ func (w *WAL) Put(entry types.RowEntry) int64 {
 	return w.table.put(entry)
 }
 
+// DeleteRange adds the range tombstone and returns the size in bytes of the range tombstone added
+func (w *WAL) DeleteRange(tombstone types.RangeTombstone) int64 {
+	w.Lock()
+	defer w.Unlock()
+	return w.table.deleteRange(tombstone)
+}
+
 func (w *WAL) Get(key []byte) mo.Option[types.Value] {
 	w.RLock()
 	defer w.RUnlock()
 	return w.table.get(key)
 }
 
+// GetEntry returns the entry of the key, including its sequence number and expiry
+func (w *WAL) GetEntry(key []byte) mo.Option[types.RowEntry] {
+	w.RLock()
+	defer w.RUnlock()
+	return w.table.getEntry(key)
+}
+
 func (w *WAL) Table() *KVTable {
 	w.RLock()
 	defer w.RUnlock()
@@ -53,6 +67,22 @@ func (w *WAL) Iter() *KVTableIterator {
 	return w.table.iter()
 }
 
+// Range returns a copy of the entries with keys in the range [start, end). If start
+// or end is empty, the range is unbounded on that side.
+func (w *WAL) Range(start []byte, end []byte) []types.RowEntry {
+	w.RLock()
+	defer w.RUnlock()
+	return w.table.rangeEntries(start, end)
+}
+
+// RangeTombstones returns a copy of the range tombstones which overlap the range [start, end). If start
+// or end is empty, the range is unbounded on that side.
+func (w *WAL) RangeTombstones(start []byte, end []byte) []types.RangeTombstone {
+	w.RLock()
+	defer w.RUnlock()
+	return w.table.rangeTombstonesOverlapping(start, end)
+}
+
 func (w *WAL) Clone() *WAL {
 	w.RLock()
 	defer w.RUnlock()
@@ -85,6 +115,13 @@ func (iw *ImmutableWAL) Get(key []byte) mo.Option[types.Value] {
 	return iw.table.get(key)
 }
 
+// GetEntry returns the entry of the key, including its sequence number and expiry
+func (iw *ImmutableWAL) GetEntry(key []byte) mo.Option[types.RowEntry] {
+	iw.RLock()
+	defer iw.RUnlock()
+	return iw.table.getEntry(key)
+}
+
 func (iw *ImmutableWAL) ID() uint64 {
 	iw.RLock()
 	defer iw.RUnlock()
@@ -103,6 +140,20 @@ func (iw *ImmutableWAL) Iter() *KVTableIterator {
 	return iw.table.iter()
 }
 
+// Range returns the entries with keys in the range [start, end). See WAL.Range
+func (iw *ImmutableWAL) Range(start []byte, end []byte) []types.RowEntry {
+	iw.RLock()
+	defer iw.RUnlock()
+	return iw.table.rangeEntries(start, end)
+}
+
+// RangeTombstones returns the range tombstones which overlap the range [start, end). See WAL.RangeTombstones
+func (iw *ImmutableWAL) RangeTombstones(start []byte, end []byte) []types.RangeTombstone {
+	iw.RLock()
+	defer iw.RUnlock()
+	return iw.table.rangeTombstonesOverlapping(start, end)
+}
+
 func (iw *ImmutableWAL) Clone() *ImmutableWAL {
 	iw.RLock()
 	defer iw.RUnlock()
@@ -0,0 +1,35 @@
+package slatedb
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/slatedb/slatedb-go/internal/sstable"
+	"github.com/slatedb/slatedb-go/internal/tracing"
+)
+
+// ------------------------------------------------
+// Tracing
+// ------------------------------------------------
+
+// endReadSpan ends the span of a read, which fails with `err` unless the key was not found
+func endReadSpan(span trace.Span, err error) {
+	found := err == nil
+	if errors.Is(err, ErrKeyNotFound) && !errors.Is(err, ErrStaleRead) {
+		err = nil
+	}
+	if err == nil {
+		span.SetAttributes(tracing.KeyFound.Bool(found))
+	}
+	tracing.End(span, err)
+}
+
+// traceProbe records the SST `id` as probed by the read of the span of ctx
+func traceProbe(ctx context.Context, id sstable.ID) {
+	span := trace.SpanFromContext(ctx)
+	if span.IsRecording() {
+		span.AddEvent("probe_sst", trace.WithAttributes(tracing.KeySSTID.String(id.String())))
+	}
+}
@@ -0,0 +1,64 @@
+package slatedb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracing(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	recorder := tracetest.NewSpanRecorder()
+	options := testDBOptions(0, 1024)
+	options.TracerProvider = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	db, err := OpenWithOptions(ctx, "/tmp/test_kv_store", objstore.NewInMemBucket(), options)
+	require.NoError(t, err)
+	defer func() { _ = db.Close(ctx) }()
+
+	require.NoError(t, db.Put(ctx, []byte("key1"), []byte("value1")))
+	require.NoError(t, db.FlushMemtableToL0())
+	_, err = db.Get(ctx, []byte("key1"))
+	require.NoError(t, err)
+	_, err = db.Get(ctx, []byte("key2"))
+	require.ErrorIs(t, err, ErrKeyNotFound)
+
+	spans := make(map[string][]sdktrace.ReadOnlySpan)
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = append(spans[span.Name()], span)
+	}
+	require.Len(t, spans["slatedb.Put"], 1)
+	require.Len(t, spans["slatedb.Get"], 2)
+	require.NotEmpty(t, spans["slatedb.WALFlush"])
+	require.Len(t, spans["slatedb.MemtableFlush"], 1)
+
+	// the upload of the WAL SST is a child of the WAL flush
+	walFlush := spans["slatedb.WALFlush"][0]
+	var uploaded bool
+	for _, span := range spans["objstore.Upload"] {
+		if span.Parent().SpanID() == walFlush.SpanContext().SpanID() {
+			uploaded = true
+		}
+	}
+	assert.True(t, uploaded)
+
+	// the Get of key1 probed the L0 SST and read its block, and the key which is not found is not an error
+	get := spans["slatedb.Get"][0]
+	events := make(map[string]bool)
+	for _, e := range get.Events() {
+		events[e.Name] = true
+	}
+	assert.True(t, events["probe_sst"])
+	assert.True(t, events["read_blocks"])
+	for _, span := range spans["slatedb.Get"] {
+		assert.Equal(t, codes.Unset, span.Status().Code)
+	}
+}
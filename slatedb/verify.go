@@ -0,0 +1,211 @@
+package slatedb
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+
+	"github.com/thanos-io/objstore"
+
+	"github.com/slatedb/slatedb-go/internal"
+	"github.com/slatedb/slatedb-go/internal/sstable"
+	"github.com/slatedb/slatedb-go/slatedb/config"
+	"github.com/slatedb/slatedb-go/slatedb/manifest"
+	"github.com/slatedb/slatedb-go/slatedb/state"
+	"github.com/slatedb/slatedb-go/slatedb/store"
+)
+
+// VerifyReport summarizes the objects checked by Verify
+type VerifyReport struct {
+	// The number of WAL SSTables which were verified
+	WALSSTs int
+
+	// The number of L0 and compacted SSTables which were verified
+	SSTs int
+
+	// The number of blocks which were decoded and had their checksums validated
+	Blocks int
+
+	// Every corruption which was found, see DB.VerifyChecksums
+	Corruptions []SSTCorruption
+
+	// Sampled is the number of keys of the backup compared with the source, and Mismatches the
+	// sampled keys which are absent from the source or whose value differs. See config.VerifyOptions
+	Sampled    int
+	Mismatches [][]byte
+}
+
+// Verify opens the database at `path` read-only and validates every object referenced
+// by the latest manifest, along with any WAL SSTables which have not yet been compacted.
+// Each SSTable must exist and have a valid info, filter, index and block checksum.
+//
+// Verify does not fence writers or compactors, and can be used to confirm that a
+// copy (backup) of a database is restorable.
+func Verify(ctx context.Context, path string, bucket objstore.Bucket) (VerifyReport, error) {
+	return VerifyWithOptions(ctx, path, bucket, config.VerifyOptions{})
+}
+
+// VerifyWithOptions is like Verify, but also compares keys sampled from the backup with the
+// database it was copied from if config.VerifyOptions.SourcePath is set. The SSTs are verified
+// as DB.VerifyChecksums verifies them, and an error is returned if any is corrupted or if any
+// sampled key differs from the source.
+func VerifyWithOptions(ctx context.Context, path string, bucket objstore.Bucket,
+	options config.VerifyOptions) (VerifyReport, error) {
+	var report VerifyReport
+	sm, err := store.LoadStoredManifest(store.NewManifestStore(path, bucket))
+	if err != nil {
+		return report, fmt.Errorf("while loading manifest: %w", err)
+	}
+	if sm.IsAbsent() {
+		return report, internal.Err("no manifest found at path '%s'", path)
+	}
+	// The WAL SSTs are verified rather than replayed
+	db, err := openWithOptions(ctx, path, bucket, readOnlyOptions(), true)
+	if err != nil {
+		return report, fmt.Errorf("while opening database: %w", err)
+	}
+	defer func() { _ = db.Close(ctx) }()
+
+	walIDs, err := db.tableStore.GetWalSSTList(db.state.LastCompactedWALID())
+	if err != nil {
+		return report, err
+	}
+	for _, walID := range walIDs {
+		blocks, corruptions, err := db.tableStore.VerifySST(ctx, &sstable.Handle{Id: sstable.NewIDWal(walID)})
+		if err != nil {
+			return report, fmt.Errorf("while verifying WAL sst '%d': %w", walID, err)
+		}
+		report.WALSSTs++
+		report.Blocks += blocks
+		report.Corruptions = append(report.Corruptions, corruptions...)
+	}
+
+	checksums, err := db.VerifyChecksums(ctx)
+	if err != nil {
+		return report, err
+	}
+	report.SSTs = checksums.SSTs
+	report.Blocks += checksums.Blocks
+	report.Corruptions = append(report.Corruptions, checksums.Corruptions...)
+	if len(report.Corruptions) > 0 {
+		return report, internal.Err("%d corruptions found, the first: %s",
+			len(report.Corruptions), report.Corruptions[0].String())
+	}
+
+	if options.SourcePath == "" {
+		return report, nil
+	}
+	if err := verifySamples(ctx, db, options, &report); err != nil {
+		return report, err
+	}
+	if len(report.Mismatches) > 0 {
+		return report, internal.Err("%d of %d sampled keys differ from the source, the first: '%s'",
+			len(report.Mismatches), report.Sampled, report.Mismatches[0])
+	}
+	return report, nil
+}
+
+// verifySamples compares keys chosen at random from the backup `db` with the source at the
+// checkpoint of config.VerifyOptions
+func verifySamples(ctx context.Context, db *DB, options config.VerifyOptions, report *VerifyReport) error {
+	if options.SourceBucket == nil {
+		return internal.ErrInvalidArgument("argument 'SourceBucket' is required with 'SourcePath'")
+	}
+	if options.Samples <= 0 {
+		options.Samples = config.DefaultVerifyOptions().Samples
+	}
+	source, err := openCheckpoint(ctx, options.SourcePath, options.SourceBucket, options.CheckpointID)
+	if err != nil {
+		return fmt.Errorf("while opening source: %w", err)
+	}
+	defer func() { _ = source.Close(ctx) }()
+
+	// The WAL SSTs of the backup, which were verified rather than replayed, hold writes which
+	// are sampled along with those of L0 and the sorted runs
+	if err := db.replayWALAfter(ctx, db.state, db.state.LastCompactedWALID()); err != nil {
+		return fmt.Errorf("while replaying WAL of backup: %w", err)
+	}
+
+	// The keys are sampled with a reservoir, such that every key of the backup is equally
+	// likely to be sampled with a single scan
+	iter, err := db.Scan(ctx, nil, nil)
+	if err != nil {
+		return fmt.Errorf("while scanning backup: %w", err)
+	}
+	defer iter.Close()
+	var samples []KeyValue
+	for seen := 0; ; seen++ {
+		kv, ok := iter.Next(ctx)
+		if !ok {
+			break
+		}
+		if len(samples) < options.Samples {
+			samples = append(samples, kv)
+		} else if i := rand.IntN(seen + 1); i < options.Samples {
+			samples[i] = kv
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return fmt.Errorf("while scanning backup: %w", err)
+	}
+
+	for _, sample := range samples {
+		value, err := source.Get(ctx, sample.Key)
+		if err != nil && !errors.Is(err, ErrKeyNotFound) {
+			return fmt.Errorf("while reading source: %w", err)
+		}
+		report.Sampled++
+		if err != nil || !bytes.Equal(value, sample.Value) {
+			report.Mismatches = append(report.Mismatches, sample.Key)
+		}
+	}
+	return nil
+}
+
+// openCheckpoint opens the database at `path` read-only at the state recorded by the checkpoint
+// `checkpointID`, such that the writes made after the checkpoint are not visible
+func openCheckpoint(ctx context.Context, path string, bucket objstore.Bucket, checkpointID uint64) (*DB, error) {
+	db, err := openWithOptions(ctx, path, bucket, readOnlyOptions(), true)
+	if err != nil {
+		return nil, err
+	}
+	checkpoints, err := db.ListCheckpoints()
+	if err != nil {
+		_ = db.Close(ctx)
+		return nil, err
+	}
+	idx := slices.IndexFunc(checkpoints, func(c manifest.Checkpoint) bool { return c.ID == checkpointID })
+	if idx < 0 {
+		_ = db.Close(ctx)
+		return nil, internal.ErrInvalidArgument("checkpoint '%d' not found in database at path '%s'", checkpointID, path)
+	}
+	m, err := db.manifestStore.ReadManifest(checkpoints[idx].ManifestID)
+	if err != nil {
+		_ = db.Close(ctx)
+		return nil, fmt.Errorf("while reading manifest of checkpoint '%d': %w", checkpointID, err)
+	}
+
+	// The WAL SSTs which were not flushed to L0 at the checkpoint are replayed, those written
+	// after the checkpoint are not
+	db.tableStore.SetExternalDBs(m.ExternalDBs)
+	db.tableStore.SetFilterSidecars(m.FilterSidecars)
+	dbState := state.NewDBState(m.Core)
+	db.walEnd = m.Core.Snapshot().NextWalSstID.Load()
+	if err := db.replayWALAfter(ctx, dbState, dbState.LastCompactedWALID()); err != nil {
+		_ = db.Close(ctx)
+		return nil, err
+	}
+	db.state.ReplaceWith(dbState)
+	return db, nil
+}
+
+// readOnlyOptions are the options of a database opened read-only to be verified
+func readOnlyOptions() config.DBOptions {
+	options := config.DefaultDBOptions()
+	options.ReadOnly = true
+	options.CompactorOptions = nil
+	return options
+}
@@ -0,0 +1,111 @@
+package slatedb
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore"
+
+	"github.com/slatedb/slatedb-go/slatedb/config"
+)
+
+func TestVerify(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	bucket := objstore.NewInMemBucket()
+	dbPath := "/tmp/test_kv_store"
+	db, err := OpenWithOptions(ctx, dbPath, bucket, config.DefaultDBOptions())
+	require.NoError(t, err)
+
+	require.NoError(t, db.Put(ctx, []byte("key1"), []byte("value1")))
+	require.NoError(t, db.FlushWAL(ctx))
+	require.NoError(t, db.FlushMemtableToL0())
+	require.NoError(t, db.Put(ctx, []byte("key2"), []byte("value2")))
+	require.NoError(t, db.Close(ctx))
+
+	report, err := Verify(ctx, dbPath, bucket)
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.SSTs)
+	assert.GreaterOrEqual(t, report.WALSSTs, 1)
+	assert.GreaterOrEqual(t, report.Blocks, 2)
+
+	// Corrupt the L0 SST and verify again
+	var l0Path string
+	require.NoError(t, bucket.Iter(ctx, dbPath, func(name string) error {
+		if strings.Contains(name, "compacted") && strings.HasSuffix(name, ".sst") {
+			l0Path = name
+		}
+		return nil
+	}, objstore.WithRecursiveIter()))
+	require.NotEmpty(t, l0Path)
+	require.NoError(t, bucket.Upload(ctx, l0Path, bytes.NewReader([]byte("corrupt"))))
+
+	_, err = Verify(ctx, dbPath, bucket)
+	assert.Error(t, err)
+}
+
+func TestVerifyNoManifest(t *testing.T) {
+	_, err := Verify(context.Background(), "/tmp/test_kv_store", objstore.NewInMemBucket())
+	assert.ErrorContains(t, err, "no manifest found")
+}
+
+func TestVerifyWithSource(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	bucket := objstore.NewInMemBucket()
+	dbPath := "/tmp/test_kv_store"
+	db, err := OpenWithOptions(ctx, dbPath, bucket, testDBOptions(0, 1024))
+	require.NoError(t, err)
+	defer func() { _ = db.Close(ctx) }()
+	require.NoError(t, db.Put(ctx, []byte("key1"), []byte("value1")))
+	require.NoError(t, db.FlushMemtableToL0())
+	// key2 is in a WAL SST at the checkpoint
+	require.NoError(t, db.Put(ctx, []byte("key2"), []byte("value2")))
+	checkpoint, err := db.CreateCheckpoint(ctx, config.DefaultCheckpointOptions())
+	require.NoError(t, err)
+
+	backupPath := "/tmp/test_kv_store_backup"
+	require.NoError(t, bucket.Iter(ctx, dbPath, func(name string) error {
+		rc, err := bucket.Get(ctx, name)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = rc.Close() }()
+		return bucket.Upload(ctx, backupPath+strings.TrimPrefix(name, dbPath), rc)
+	}, objstore.WithRecursiveIter()))
+
+	// the writes made to the source after the checkpoint are not compared with the backup
+	require.NoError(t, db.Put(ctx, []byte("key1"), []byte("changed")))
+	require.NoError(t, db.Delete(ctx, []byte("key2")))
+
+	options := config.VerifyOptions{
+		SourcePath:   dbPath,
+		SourceBucket: bucket,
+		CheckpointID: checkpoint.ID,
+		Samples:      10,
+	}
+	report, err := VerifyWithOptions(ctx, backupPath, bucket, options)
+	require.NoError(t, err)
+	assert.Equal(t, 2, report.Sampled)
+	assert.Empty(t, report.Mismatches)
+
+	// a key of the backup which differs from the source at the checkpoint is reported
+	backup, err := OpenWithOptions(ctx, backupPath, bucket, testDBOptions(0, 1024))
+	require.NoError(t, err)
+	require.NoError(t, backup.Put(ctx, []byte("key3"), []byte("value3")))
+	require.NoError(t, backup.Close(ctx))
+	report, err = VerifyWithOptions(ctx, backupPath, bucket, options)
+	assert.ErrorContains(t, err, "1 of 3 sampled keys differ from the source")
+	assert.Equal(t, [][]byte{[]byte("key3")}, report.Mismatches)
+
+	options.CheckpointID = checkpoint.ID + 1
+	_, err = VerifyWithOptions(ctx, backupPath, bucket, options)
+	assert.ErrorContains(t, err, "not found")
+}
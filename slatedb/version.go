@@ -0,0 +1,80 @@
+package slatedb
+
+import (
+	"runtime"
+	"runtime/debug"
+
+	"github.com/slatedb/slatedb-go/internal/sstable"
+	"github.com/slatedb/slatedb-go/slatedb/manifest"
+)
+
+// modulePath is the path of the slatedb-go module, used to find its version in the build info
+const modulePath = "github.com/slatedb/slatedb-go"
+
+// VersionInfo reports the version of the engine and the on-disk formats it can read and write
+type VersionInfo struct {
+	// Version is the version of the slatedb-go module the binary was built with, or "(devel)"
+	// if it was built from a working tree and the version is not known
+	Version string
+
+	// GoVersion is the version of Go the binary was built with
+	GoVersion string
+
+	// ManifestFormatVersion is the version of the manifest format, see manifest.FormatVersion
+	ManifestFormatVersion int
+
+	// SSTFormatVersion is the version of the SSTable format, see sstable.FormatVersion
+	SSTFormatVersion int
+
+	// SupportedFeatures are the names of the on-disk format features this binary can read and
+	// write. A database which uses a feature not listed here cannot be opened by this binary.
+	SupportedFeatures []string
+}
+
+// FormatInfo reports the on-disk format features used by an opened database
+type FormatInfo struct {
+	VersionInfo
+
+	// Features are the names of the on-disk format features recorded in the manifest of the
+	// database. Once recorded a feature is never removed, so binaries which do not support
+	// every feature listed here can no longer open the database.
+	Features []string
+}
+
+// BuildInfo returns the version of the engine and the on-disk formats it supports. Operators
+// can compare it with DB.FormatInfo to confirm a binary can open a database before upgrading
+// or downgrading.
+func BuildInfo() VersionInfo {
+	info := VersionInfo{
+		Version:               "(devel)",
+		GoVersion:             runtime.Version(),
+		ManifestFormatVersion: manifest.FormatVersion,
+		SSTFormatVersion:      sstable.FormatVersion,
+		SupportedFeatures:     manifest.SupportedFeatures.Names(),
+	}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		if bi.Main.Path == modulePath && bi.Main.Version != "" {
+			info.Version = bi.Main.Version
+		}
+		for _, dep := range bi.Deps {
+			if dep.Path == modulePath && dep.Version != "" {
+				info.Version = dep.Version
+			}
+		}
+	}
+	return info
+}
+
+// FormatInfo returns the on-disk format features used by the database, as recorded in its
+// manifest when it was last loaded, along with the BuildInfo of this binary
+func (db *DB) FormatInfo() FormatInfo {
+	info := FormatInfo{VersionInfo: BuildInfo()}
+	if db.opts.ReadOnly {
+		db.refreshMu.Lock()
+		info.Features = db.features.Names()
+		db.refreshMu.Unlock()
+		return info
+	}
+	info.Features = db.manifest.Features().Names()
+	return info
+}
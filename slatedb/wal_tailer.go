@@ -0,0 +1,139 @@
+package slatedb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/thanos-io/objstore"
+
+	"github.com/slatedb/slatedb-go/internal/sstable"
+	"github.com/slatedb/slatedb-go/slatedb/store"
+)
+
+// WALEntry is a mutation committed to the WAL by a writer
+type WALEntry struct {
+	// WALID is the id of the WAL SSTable the entry was committed in
+	WALID uint64
+	Key   []byte
+	// Value is nil if the entry is a tombstone
+	Value     []byte
+	Tombstone bool
+
+	// RangeEnd is not nil if the entry is a range tombstone written by DB.DeleteRange, which
+	// deletes the keys in the range [Key, RangeEnd) written before the range tombstone
+	RangeEnd []byte
+
+	// Seq is the sequence number of the write which produced the entry, see DB.DeleteRange
+	Seq uint64
+
+	// ExpireAt is the time after which the value is treated as not found, see DB.PutWithTTL.
+	// It is zero if the value never expires.
+	ExpireAt time.Time
+
+	// Time is the time the WAL SSTable was written to object storage, after which the entry
+	// was durable
+	Time time.Time
+}
+
+// WALTailer reads the WAL SSTables committed by the writer of the database at a path,
+// in the order they were committed. A WALTailer never fences the writer and does not
+// require the writer to be running.
+type WALTailer struct {
+	tableStore *store.TableStore
+	lastWALID  uint64
+}
+
+// NewWALTailer returns a WALTailer which returns entries from WAL SSTables with
+// an id greater than `afterWALID`. Use LatestWALID to begin tailing from the
+// most recently committed WAL.
+func NewWALTailer(path string, bucket objstore.Bucket, afterWALID uint64) *WALTailer {
+	return &WALTailer{
+		tableStore: store.NewTableStore(bucket, sstable.DefaultConfig(), path),
+		lastWALID:  afterWALID,
+	}
+}
+
+// LatestWALID returns the id of the most recently committed WAL SSTable,
+// or zero if no WAL SSTables exist.
+func (t *WALTailer) LatestWALID() (uint64, error) {
+	walIDs, err := t.tableStore.GetWalSSTList(0)
+	if err != nil {
+		return 0, err
+	}
+	if len(walIDs) == 0 {
+		return 0, nil
+	}
+	return walIDs[len(walIDs)-1], nil
+}
+
+// Poll returns the entries of all WAL SSTables committed since the last call to Poll.
+// Entries are returned in commit order, entries within a single WAL are ordered by key, followed by
+// the range tombstones of the WAL. Use WALEntry.Seq to order the entries within a single WAL.
+// If no new WAL SSTables have been committed, Poll returns an empty slice. If a WAL SSTable cannot
+// be read, Poll returns the entries of the WAL SSTables read before it along with the error, and
+// the next call to Poll begins with the WAL SSTable which could not be read.
+func (t *WALTailer) Poll(ctx context.Context) ([]WALEntry, error) {
+	wals, err := t.tableStore.ListWALObjects(ctx, t.lastWALID)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]WALEntry, 0)
+	for _, wal := range wals {
+		walEntries, err := t.readWAL(ctx, wal)
+		if err != nil {
+			return entries, err
+		}
+		entries = append(entries, walEntries...)
+		t.lastWALID = wal.ID.WalID().MustGet()
+	}
+	return entries, nil
+}
+
+// readWAL returns the entries of the WAL SSTable `wal`, ordered by key, followed by its range tombstones
+func (t *WALTailer) readWAL(ctx context.Context, wal store.TableObject) ([]WALEntry, error) {
+	walID := wal.ID.WalID().MustGet()
+	handle, err := t.tableStore.OpenSST(ctx, wal.ID)
+	if err != nil {
+		return nil, fmt.Errorf("while opening wal '%d': %w", walID, err)
+	}
+
+	iter, err := sstable.NewIterator(ctx, handle, t.tableStore)
+	if err != nil {
+		return nil, fmt.Errorf("while reading wal '%d': %w", walID, err)
+	}
+	entries := make([]WALEntry, 0)
+	for {
+		entry, ok := iter.NextEntry(ctx)
+		if !ok {
+			break
+		}
+		walEntry := WALEntry{
+			WALID:     walID,
+			Key:       entry.Key,
+			Tombstone: entry.Value.IsTombstone(),
+			Seq:       entry.Seq,
+			ExpireAt:  entry.ExpireAt,
+			Time:      wal.LastModified,
+		}
+		if !walEntry.Tombstone {
+			walEntry.Value = entry.Value.Value
+		}
+		entries = append(entries, walEntry)
+	}
+	if w := iter.Warnings(); w != nil && !w.Empty() {
+		return nil, fmt.Errorf("while reading wal '%d': %w", walID, w)
+	}
+	for _, t := range handle.Info.RangeTombstones {
+		entries = append(entries, WALEntry{
+			WALID:     walID,
+			Key:       t.Start,
+			Tombstone: true,
+			RangeEnd:  t.End,
+			Seq:       t.Seq,
+			Time:      wal.LastModified,
+		})
+	}
+	return entries, nil
+}
@@ -0,0 +1,95 @@
+package slatedb
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore"
+)
+
+func TestWALTailer(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	bucket := objstore.NewInMemBucket()
+	dbPath := "/tmp/test_kv_store"
+	db, err := OpenWithOptions(ctx, dbPath, bucket, testDBOptions(0, 1024))
+	require.NoError(t, err)
+	defer func() { _ = db.Close(ctx) }()
+
+	require.NoError(t, db.Put(ctx, []byte("key1"), []byte("value1")))
+
+	tailer := NewWALTailer(dbPath, bucket, 0)
+	entries, err := tailer.Poll(ctx)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, []byte("key1"), entries[0].Key)
+	assert.Equal(t, []byte("value1"), entries[0].Value)
+	assert.False(t, entries[0].Tombstone)
+
+	latest, err := tailer.LatestWALID()
+	require.NoError(t, err)
+	assert.Equal(t, entries[0].WALID, latest)
+
+	// only entries committed since the last poll are returned
+	entries, err = tailer.Poll(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	require.NoError(t, db.Delete(ctx, []byte("key1")))
+	require.NoError(t, db.Put(ctx, []byte("key2"), []byte("value2")))
+
+	entries, err = tailer.Poll(ctx)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, []byte("key1"), entries[0].Key)
+	assert.True(t, entries[0].Tombstone)
+	assert.Nil(t, entries[0].Value)
+	assert.Equal(t, []byte("key2"), entries[1].Key)
+	assert.Less(t, latest, entries[0].WALID)
+	assert.Less(t, entries[0].WALID, entries[1].WALID)
+}
+
+func TestWALTailerPartialPoll(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	bucket := objstore.NewInMemBucket()
+	dbPath := "/tmp/test_kv_store"
+	db, err := OpenWithOptions(ctx, dbPath, bucket, testDBOptions(0, 1024))
+	require.NoError(t, err)
+	defer func() { _ = db.Close(ctx) }()
+
+	tailer := NewWALTailer(dbPath, bucket, 0)
+	require.NoError(t, db.Put(ctx, []byte("key1"), []byte("value1")))
+	first, err := tailer.LatestWALID()
+	require.NoError(t, err)
+	require.NoError(t, db.Put(ctx, []byte("key2"), []byte("value2")))
+
+	// the second WAL cannot be read
+	path := fmt.Sprintf("%s/wal/%020d.sst", dbPath, first+1)
+	reader, err := bucket.Get(ctx, path)
+	require.NoError(t, err)
+	data, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	require.NoError(t, bucket.Upload(ctx, path, bytes.NewReader([]byte("corrupt"))))
+
+	// the entries of the first WAL are returned with the error
+	entries, err := tailer.Poll(ctx)
+	require.Error(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, []byte("key1"), entries[0].Key)
+
+	// and the second WAL is read once it can be
+	require.NoError(t, bucket.Upload(ctx, path, bytes.NewReader(data)))
+	entries, err = tailer.Poll(ctx)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, []byte("key2"), entries[0].Key)
+}